@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrNotLoggedIn is returned when a session has no refresh token to renew it with
+var ErrNotLoggedIn = errors.New("internal api: not logged in")
+
+// Login authenticates a user and keeps the issued tokens for later calls
+func (c *Client) Login(ctx context.Context, username, password string) (*LoginResponse, error) {
+	var resp LoginResponse
+	body := LoginRequest{Username: username, Password: password}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/auth/login", body: body}, &resp); err != nil {
+		return nil, err
+	}
+	c.setTokens(&resp)
+	return &resp, nil
+}
+
+// Refresh renews the access token with the refresh token. Calls refresh
+// automatically, so this is only needed to renew a session ahead of time.
+func (c *Client) Refresh(ctx context.Context) error {
+	return c.refresh(ctx)
+}
+
+// refresh renews the session once for all goroutines that find it expired.
+// The gateway rotates refresh tokens and treats reuse as theft, so a second
+// caller waiting on the lock uses the tokens the first one obtained.
+func (c *Client) refresh(ctx context.Context) error {
+	stale := c.Tokens()
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	current := c.Tokens()
+	if current.AccessToken != stale.AccessToken && !c.needsRefresh() {
+		return nil
+	}
+	if current.RefreshToken == "" {
+		return ErrNotLoggedIn
+	}
+
+	var resp LoginResponse
+	body := RefreshTokenRequest{RefreshToken: current.RefreshToken}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/auth/refresh", body: body}, &resp); err != nil {
+		return err
+	}
+	c.setTokens(&resp)
+	return nil
+}
+
+// needsRefresh reports whether the access token is missing or about to
+// expire while a refresh token is available
+func (c *Client) needsRefresh() bool {
+	tokens := c.Tokens()
+	if tokens.RefreshToken == "" {
+		return false
+	}
+	return tokens.AccessToken == "" || time.Until(tokens.ExpiresAt) < refreshLeeway
+}
+
+// Logout revokes the session and forgets its tokens
+func (c *Client) Logout(ctx context.Context) error {
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/auth/logout", auth: true}, nil); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.tokens = Tokens{}
+	c.mu.Unlock()
+	return nil
+}
+
+// Me returns the authenticated user
+func (c *Client) Me(ctx context.Context) (*UserInfo, error) {
+	var user UserInfo
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/auth/me", auth: true}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,322 @@
+// Package client is a typed Go client for the hotel Internal API gateway.
+// It logs in and refreshes access tokens automatically, retries transient
+// failures with exponential backoff and honours Retry-After, and takes a
+// context on every call.
+//
+//	c := client.New("https://api.hotel.internal")
+//	if _, err := c.Login(ctx, "frontdesk", password); err != nil { ... }
+//	booking, err := c.CreateBooking(ctx, client.CreateBookingRequest{...})
+//
+// Services authenticate with an API key instead:
+//
+//	c := client.New(baseURL, client.WithAPIKey(key))
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKeyHeader carries a service API key
+const APIKeyHeader = "X-Internal-API-Key"
+
+// refreshLeeway is how long before expiry an access token is renewed
+const refreshLeeway = 30 * time.Second
+
+// RetryPolicy controls retries of transient failures
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first; 1 disables retries
+	BaseDelay   time.Duration // First backoff; doubled per attempt with jitter
+	MaxDelay    time.Duration // Longest wait, including a Retry-After the gateway asks for
+}
+
+// DefaultRetryPolicy retries up to three times within a few seconds
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 4, BaseDelay: 200 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+// Tokens are the credentials of a logged-in user
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// Client calls the gateway. It is safe for concurrent use.
+type Client struct {
+	baseURL   string
+	http      *http.Client
+	apiKey    string
+	userAgent string
+	retry     RetryPolicy
+
+	mu        sync.Mutex
+	tokens    Tokens
+	refreshMu sync.Mutex // Serializes refreshes so a rotated token is used once
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client, e.g. for timeouts or mTLS
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithAPIKey authenticates every request with a service API key
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithRetry sets the retry policy
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) { c.retry = policy }
+}
+
+// WithUserAgent identifies the calling service in gateway logs
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// WithTokens resumes a session from stored tokens
+func WithTokens(tokens Tokens) Option {
+	return func(c *Client) { c.tokens = tokens }
+}
+
+// New creates a client for the gateway at baseURL
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		http:      &http.Client{Timeout: 30 * time.Second},
+		userAgent: "internalapi-go-client",
+		retry:     DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.retry.MaxAttempts < 1 {
+		c.retry.MaxAttempts = 1
+	}
+	return c
+}
+
+// Tokens returns the current session tokens, e.g. to store them
+func (c *Client) Tokens() Tokens {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens
+}
+
+// setTokens stores tokens issued by login or refresh
+func (c *Client) setTokens(resp *LoginResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens = Tokens{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second),
+	}
+}
+
+// APIError is an error response from the gateway
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    string
+	Violations []Violation
+	RequestID  string
+	RetryAfter time.Duration
+}
+
+// Error formats the gateway's error code and message
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("internal api: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("internal api: %s (%d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the gateway
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// request describes one API call
+type request struct {
+	method  string
+	path    string // Including the query string
+	body    interface{}
+	headers map[string]string
+	auth    bool // Send credentials and refresh them when needed
+}
+
+// do performs a request with authentication, refresh and retries and
+// decodes a JSON response into out when it is non-nil
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	var payload []byte
+	if req.body != nil {
+		var err error
+		if payload, err = json.Marshal(req.body); err != nil {
+			return fmt.Errorf("internal api: encode request: %w", err)
+		}
+	}
+
+	refreshed := false
+	for attempt := 1; ; attempt++ {
+		if req.auth && c.apiKey == "" && !refreshed && c.needsRefresh() {
+			if err := c.refresh(ctx); err != nil {
+				return err
+			}
+			refreshed = true
+		}
+
+		resp, err := c.send(ctx, req, payload)
+		if err != nil {
+			if ctx.Err() != nil || !c.retryable(req.method, nil) || !c.wait(ctx, attempt, 0) {
+				return err
+			}
+			continue
+		}
+
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if readErr != nil {
+			return fmt.Errorf("internal api: read response: %w", readErr)
+		}
+
+		if resp.StatusCode < 300 {
+			if out == nil || len(body) == 0 || resp.StatusCode == http.StatusNoContent {
+				return nil
+			}
+			if err := json.Unmarshal(body, out); err != nil {
+				return fmt.Errorf("internal api: decode response: %w", err)
+			}
+			return nil
+		}
+
+		apiErr := decodeError(resp, body)
+
+		// An expired or revoked session gets one refresh before giving up
+		if resp.StatusCode == http.StatusUnauthorized && req.auth && c.apiKey == "" && !refreshed && c.Tokens().RefreshToken != "" {
+			if err := c.refresh(ctx); err != nil {
+				return apiErr
+			}
+			refreshed = true
+			continue
+		}
+
+		if !c.retryable(req.method, resp) || !c.wait(ctx, attempt, apiErr.RetryAfter) {
+			return apiErr
+		}
+	}
+}
+
+// send makes one HTTP attempt
+func (c *Client) send(ctx context.Context, req request, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, c.baseURL+req.path, body)
+	if err != nil {
+		return nil, fmt.Errorf("internal api: build request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range req.headers {
+		httpReq.Header.Set(key, value)
+	}
+	if req.auth {
+		if c.apiKey != "" {
+			httpReq.Header.Set(APIKeyHeader, c.apiKey)
+		} else if token := c.Tokens().AccessToken; token != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("internal api: %s %s: %w", req.method, req.path, err)
+	}
+	return resp, nil
+}
+
+// retryable reports whether a failed attempt may be repeated. Requests that
+// change data are only retried when the gateway turned them away before
+// they reached a backend: rate limits, and 503s with Retry-After such as an
+// open circuit breaker or a maintenance window.
+func (c *Client) retryable(method string, resp *http.Response) bool {
+	idempotent := method == http.MethodGet || method == http.MethodHead || method == http.MethodPut || method == http.MethodDelete
+	if resp == nil {
+		return idempotent
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return idempotent || resp.Header.Get("Retry-After") != ""
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// wait sleeps before the next attempt and reports whether there is one.
+// retryAfter, when set, replaces the backoff; waits longer than MaxDelay
+// are not attempted.
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter time.Duration) bool {
+	if attempt >= c.retry.MaxAttempts {
+		return false
+	}
+	delay := retryAfter
+	if delay == 0 {
+		backoff := c.retry.BaseDelay << (attempt - 1)
+		if backoff <= 0 || backoff > c.retry.MaxDelay {
+			backoff = c.retry.MaxDelay
+		}
+		delay = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	}
+	if c.retry.MaxDelay > 0 && delay > c.retry.MaxDelay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// decodeError reads the gateway's error response
+func decodeError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode, RequestID: resp.Header.Get("X-Request-ID")}
+	var payload struct {
+		Code       string      `json:"code"`
+		Message    string      `json:"message"`
+		Details    string      `json:"details"`
+		Violations []Violation `json:"violations"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		apiErr.Code, apiErr.Message, apiErr.Details, apiErr.Violations = payload.Code, payload.Message, payload.Details, payload.Violations
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
@@ -0,0 +1,297 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"InternalAPI/internal/changelog"
+	"InternalAPI/internal/models"
+)
+
+// Request and response models of the gateway
+type (
+	Album                = models.Album
+	Booking              = models.Booking
+	CreateBookingRequest = models.CreateBookingRequest
+	UpdateBookingRequest = models.UpdateBookingRequest
+	Room                 = models.Room
+	CreateRoomRequest    = models.CreateRoomRequest
+	UpdateRoomRequest    = models.UpdateRoomRequest
+	RoomStatusRequest    = models.RoomStatusRequest
+	AvailabilityQuery    = models.AvailabilityQuery
+	AvailabilityResponse = models.AvailabilityResponse
+	LoginRequest         = models.LoginRequest
+	LoginResponse        = models.LoginResponse
+	RefreshTokenRequest  = models.RefreshTokenRequest
+	UserInfo             = models.UserInfo
+	Violation            = models.Violation
+	Change               = changelog.Change
+)
+
+// ListOptions selects a page of a list. Filters are passed as query
+// parameters, e.g. {"status": "confirmed"}. Lists that support it are
+// ordered by Sort, e.g. "-price,title", and keep only the items matching
+// every field:operator:value expression in Where, e.g. "price:lt:20".
+type ListOptions struct {
+	Cursor  string
+	Limit   int
+	Filters map[string]string
+	Sort    string
+	Where   []string
+}
+
+// query encodes the options as a query string
+func (o *ListOptions) query() string {
+	if o == nil {
+		return ""
+	}
+	params := url.Values{}
+	for key, value := range o.Filters {
+		params.Set(key, value)
+	}
+	if o.Cursor != "" {
+		params.Set("cursor", o.Cursor)
+	}
+	if o.Limit > 0 {
+		params.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Sort != "" {
+		params.Set("sort", o.Sort)
+	}
+	for _, expression := range o.Where {
+		params.Add("filter", expression)
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + params.Encode()
+}
+
+// Page holds one page of a list; pass NextCursor in ListOptions.Cursor to
+// fetch the next one while HasMore is set
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+	HasMore    bool
+	TotalItems int // Zero when the list does not report totals
+}
+
+// getRecord fetches a single resource, which the gateway wraps under its
+// name, e.g. {"booking": {...}}
+func getRecord(ctx context.Context, c *Client, req request, key string, out interface{}) error {
+	var raw json.RawMessage
+	if err := c.do(ctx, req, &raw); err != nil {
+		return err
+	}
+	return unwrap(raw, key, out)
+}
+
+// unwrap decodes the value under key, or the whole body when it is not wrapped
+func unwrap(raw json.RawMessage, key string, out interface{}) error {
+	var wrapped map[string]json.RawMessage
+	if json.Unmarshal(raw, &wrapped) == nil {
+		if inner, ok := wrapped[key]; ok {
+			raw = inner
+		}
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("internal api: decode %s: %w", key, err)
+	}
+	return nil
+}
+
+// list fetches a page of a resource list, whose items are under the
+// resource name or "data"
+func list[T any](ctx context.Context, c *Client, path, key string, opts *ListOptions) (*Page[T], error) {
+	var body map[string]json.RawMessage
+	if err := c.do(ctx, request{method: http.MethodGet, path: path + opts.query(), auth: true}, &body); err != nil {
+		return nil, err
+	}
+
+	page := &Page[T]{Items: []T{}}
+	items, ok := body[key]
+	if !ok {
+		items = body["data"]
+	}
+	if len(items) > 0 && string(items) != "null" {
+		if err := json.Unmarshal(items, &page.Items); err != nil {
+			return nil, fmt.Errorf("internal api: decode %s: %w", key, err)
+		}
+	}
+	_ = json.Unmarshal(body["next_cursor"], &page.NextCursor)
+	_ = json.Unmarshal(body["has_more"], &page.HasMore)
+	_ = json.Unmarshal(body["total_items"], &page.TotalItems)
+	return page, nil
+}
+
+// GetAlbums lists albums
+func (c *Client) GetAlbums(ctx context.Context, opts *ListOptions) (*Page[Album], error) {
+	return list[Album](ctx, c, "/api/v1/albums", "albums", opts)
+}
+
+// GetAlbum fetches an album
+func (c *Client) GetAlbum(ctx context.Context, id string) (*Album, error) {
+	var album Album
+	err := getRecord(ctx, c, request{method: http.MethodGet, path: "/api/v1/albums/" + url.PathEscape(id), auth: true}, "album", &album)
+	if err != nil {
+		return nil, err
+	}
+	return &album, nil
+}
+
+// CreateAlbum adds an album
+func (c *Client) CreateAlbum(ctx context.Context, album Album) (*Album, error) {
+	var created Album
+	if err := getRecord(ctx, c, request{method: http.MethodPost, path: "/api/v1/albums", body: album, auth: true}, "album", &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateAlbum replaces an album
+func (c *Client) UpdateAlbum(ctx context.Context, id string, album Album) (*Album, error) {
+	var updated Album
+	err := getRecord(ctx, c, request{method: http.MethodPut, path: "/api/v1/albums/" + url.PathEscape(id), body: album, auth: true}, "album", &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteAlbum removes an album
+func (c *Client) DeleteAlbum(ctx context.Context, id string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/api/v1/albums/" + url.PathEscape(id), auth: true}, nil)
+}
+
+// GetBookings lists bookings
+func (c *Client) GetBookings(ctx context.Context, opts *ListOptions) (*Page[Booking], error) {
+	return list[Booking](ctx, c, "/api/v1/bookings", "bookings", opts)
+}
+
+// GetBooking fetches a booking
+func (c *Client) GetBooking(ctx context.Context, id string) (*Booking, error) {
+	var booking Booking
+	err := getRecord(ctx, c, request{method: http.MethodGet, path: "/api/v1/bookings/" + url.PathEscape(id), auth: true}, "booking", &booking)
+	if err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// CreateBooking books a room type after the gateway's availability check.
+// It is not retried once the request may have reached the PMS.
+func (c *Client) CreateBooking(ctx context.Context, booking CreateBookingRequest) (*Booking, error) {
+	var created Booking
+	if err := getRecord(ctx, c, request{method: http.MethodPost, path: "/api/v1/bookings", body: booking, auth: true}, "booking", &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateBooking changes the given fields of a booking
+func (c *Client) UpdateBooking(ctx context.Context, id string, update UpdateBookingRequest) (*Booking, error) {
+	var updated Booking
+	err := getRecord(ctx, c, request{method: http.MethodPut, path: "/api/v1/bookings/" + url.PathEscape(id), body: update, auth: true}, "booking", &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteBooking removes a booking
+func (c *Client) DeleteBooking(ctx context.Context, id string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/api/v1/bookings/" + url.PathEscape(id), auth: true}, nil)
+}
+
+// GetRooms lists rooms
+func (c *Client) GetRooms(ctx context.Context, opts *ListOptions) (*Page[Room], error) {
+	return list[Room](ctx, c, "/api/v1/rooms", "rooms", opts)
+}
+
+// GetRoom fetches a room
+func (c *Client) GetRoom(ctx context.Context, id string) (*Room, error) {
+	var room Room
+	err := getRecord(ctx, c, request{method: http.MethodGet, path: "/api/v1/rooms/" + url.PathEscape(id), auth: true}, "room", &room)
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// CreateRoom adds a room
+func (c *Client) CreateRoom(ctx context.Context, room CreateRoomRequest) (*Room, error) {
+	var created Room
+	if err := getRecord(ctx, c, request{method: http.MethodPost, path: "/api/v1/rooms", body: room, auth: true}, "room", &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateRoom replaces a room's details
+func (c *Client) UpdateRoom(ctx context.Context, id string, room UpdateRoomRequest) (*Room, error) {
+	var updated Room
+	err := getRecord(ctx, c, request{method: http.MethodPut, path: "/api/v1/rooms/" + url.PathEscape(id), body: room, auth: true}, "room", &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// UpdateRoomStatus moves a room to a new status; the gateway rejects
+// transitions its state machine forbids with 409
+func (c *Client) UpdateRoomStatus(ctx context.Context, id string, status RoomStatusRequest) (*Room, error) {
+	var updated Room
+	err := getRecord(ctx, c, request{method: http.MethodPatch, path: "/api/v1/rooms/" + url.PathEscape(id) + "/status", body: status, auth: true}, "room", &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// SearchAvailability searches room availability for a stay
+func (c *Client) SearchAvailability(ctx context.Context, q AvailabilityQuery) (*AvailabilityResponse, error) {
+	params := url.Values{}
+	params.Set("check_in", q.CheckIn)
+	params.Set("check_out", q.CheckOut)
+	if q.HotelID != "" {
+		params.Set("hotel_id", q.HotelID)
+	}
+	if q.Guests > 0 {
+		params.Set("guests", strconv.Itoa(q.Guests))
+	}
+	if q.RoomType != "" {
+		params.Set("room_type", q.RoomType)
+	}
+	if q.MaxPrice > 0 {
+		params.Set("max_price", strconv.FormatFloat(q.MaxPrice, 'f', -1, 64))
+	}
+	if q.AvailableOnly {
+		params.Set("available_only", "true")
+	}
+
+	var resp AvailabilityResponse
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/availability?" + params.Encode(), auth: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetChangelog returns the API changes since a gateway version, or all
+// changes when since is empty, to detect what a newer gateway offers
+func (c *Client) GetChangelog(ctx context.Context, since string) ([]Change, error) {
+	path := "/api/v1/changelog"
+	if since != "" {
+		path += "?since=" + url.QueryEscape(since)
+	}
+	var resp struct {
+		Changes []Change `json:"changes"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, auth: true}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Changes, nil
+}
@@ -0,0 +1,173 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+var log = logging.Logger()
+
+// Parameter types
+const (
+	TypeString = "string"
+	TypeBool   = "bool"
+	TypeEnum   = "enum"
+)
+
+var (
+	// ErrUnknownAction is returned for action names that are not registered
+	ErrUnknownAction = errors.New("unknown action")
+	// ErrInvalidParams is wrapped by parameter validation errors
+	ErrInvalidParams = errors.New("invalid action parameters")
+)
+
+// Param describes an action parameter
+type Param struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Required    bool     `json:"required"`
+	Values      []string `json:"values,omitempty"` // Allowed values for enum parameters
+	Description string   `json:"description"`
+}
+
+// Result is the typed outcome of running an action
+type Result struct {
+	Action     string                 `json:"action"`
+	DryRun     bool                   `json:"dry_run"`
+	Changed    bool                   `json:"changed"`
+	Message    string                 `json:"message"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+}
+
+// RunFunc performs an action. When dryRun is true it must only report what
+// it would do.
+type RunFunc func(params map[string]string, dryRun bool) (Result, error)
+
+// Action is a safe, parameterized operational task
+type Action struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Roles       []string `json:"roles"` // Any of these roles may run the action
+	Params      []Param  `json:"params"`
+	Run         RunFunc  `json:"-"`
+}
+
+var (
+	registry   = make(map[string]Action)
+	registryMu sync.RWMutex
+)
+
+// Register adds an action to the registry, replacing any with the same name
+func Register(action Action) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[action.Name] = action
+}
+
+// Get returns a registered action
+func Get(name string) (Action, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	action, ok := registry[name]
+	return action, ok
+}
+
+// List returns all registered actions sorted by name
+func List() []Action {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	list := make([]Action, 0, len(registry))
+	for _, action := range registry {
+		list = append(list, action)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Execute validates parameters and runs an action, logging who ran it and
+// what happened
+func Execute(name string, params map[string]string, dryRun bool, userID string) (Result, error) {
+	action, ok := Get(name)
+	if !ok {
+		return Result{}, ErrUnknownAction
+	}
+
+	if err := validate(action, params); err != nil {
+		return Result{}, err
+	}
+
+	start := time.Now()
+	result, err := action.Run(params, dryRun)
+	result.Action = name
+	result.DryRun = dryRun
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	entry := log.WithFields(logrus.Fields{
+		"action":      name,
+		"user_id":     userID,
+		"params":      params,
+		"dry_run":     dryRun,
+		"changed":     result.Changed,
+		"duration_ms": result.DurationMs,
+	})
+	if err != nil {
+		entry.WithError(err).Error("Operational action failed")
+		return result, err
+	}
+	entry.Info("Operational action executed")
+
+	return result, nil
+}
+
+// validate checks params against the action's parameter definitions
+func validate(action Action, params map[string]string) error {
+	known := make(map[string]bool, len(action.Params))
+	for _, p := range action.Params {
+		known[p.Name] = true
+
+		value, present := params[p.Name]
+		if !present || value == "" {
+			if p.Required {
+				return fmt.Errorf("%w: %s is required", ErrInvalidParams, p.Name)
+			}
+			continue
+		}
+
+		switch p.Type {
+		case TypeBool:
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("%w: %s must be true or false", ErrInvalidParams, p.Name)
+			}
+		case TypeEnum:
+			allowed := false
+			for _, v := range p.Values {
+				if v == value {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return fmt.Errorf("%w: %s must be one of %v", ErrInvalidParams, p.Name, p.Values)
+			}
+		}
+	}
+
+	for name := range params {
+		if !known[name] {
+			return fmt.Errorf("%w: unknown parameter %s", ErrInvalidParams, name)
+		}
+	}
+	return nil
+}
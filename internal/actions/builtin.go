@@ -0,0 +1,137 @@
+package actions
+
+import (
+	"fmt"
+	"strconv"
+
+	"InternalAPI/internal/broker"
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/logfile"
+	"InternalAPI/internal/middleware"
+	"InternalAPI/internal/permissions"
+	"InternalAPI/internal/services"
+)
+
+// RegisterBuiltins registers the standard runbook actions. logFile may be nil
+// when the gateway logs to stdout only.
+func RegisterBuiltins(cfg *config.Config, logFile *logfile.File) {
+	Register(Action{
+		Name:        "flush-cache",
+		Description: "Drop all entries from a gateway cache",
+		Roles:       []string{"admin"},
+		Params: []Param{
+			{Name: "cache", Type: TypeEnum, Required: true, Values: []string{"permissions", "reference"}, Description: "Cache to flush"},
+		},
+		Run: func(params map[string]string, dryRun bool) (Result, error) {
+			switch params["cache"] {
+			case "permissions":
+				if dryRun {
+					return Result{Message: "Would flush the permission cache", Data: map[string]interface{}{"entries": permissions.CacheSize()}}, nil
+				}
+				removed := permissions.InvalidateAll()
+				return Result{Changed: removed > 0, Message: "Permission cache flushed", Data: map[string]interface{}{"removed": removed}}, nil
+			default:
+				if dryRun {
+					return Result{Message: "Would flush the reference data cache"}, nil
+				}
+				removed := services.FlushReferenceCache()
+				return Result{Changed: removed > 0, Message: "Reference data cache flushed", Data: map[string]interface{}{"removed": removed}}, nil
+			}
+		},
+	})
+
+	Register(Action{
+		Name:        "rotate-log",
+		Description: "Archive the current log file and start a new one",
+		Roles:       []string{"admin"},
+		Run: func(params map[string]string, dryRun bool) (Result, error) {
+			if logFile == nil {
+				return Result{}, fmt.Errorf("no log file configured, set LOG_FILE")
+			}
+			size, err := logFile.Size()
+			if err != nil {
+				return Result{}, err
+			}
+			if dryRun {
+				return Result{Message: "Would rotate " + logFile.Path(), Data: map[string]interface{}{"size_bytes": size}}, nil
+			}
+			archived, err := logFile.Rotate()
+			if err != nil {
+				return Result{}, err
+			}
+			return Result{Changed: true, Message: "Log file rotated", Data: map[string]interface{}{"archived": archived, "size_bytes": size}}, nil
+		},
+	})
+
+	Register(Action{
+		Name:        "resync-business-rules",
+		Description: "Reload reference data from the backends, bypassing the cache",
+		Roles:       []string{"admin"},
+		Params: []Param{
+			{Name: "dataset", Type: TypeString, Description: "Reference dataset to reload (default business-rules)"},
+		},
+		Run: func(params map[string]string, dryRun bool) (Result, error) {
+			name := params["dataset"]
+			if name == "" {
+				name = "business-rules"
+			}
+
+			var selected []services.ReferenceDataset
+			for _, ds := range services.ParseReferenceDatasets(cfg.ReferenceDatasets) {
+				if ds.Name == name {
+					selected = append(selected, ds)
+				}
+			}
+			if len(selected) == 0 {
+				return Result{}, fmt.Errorf("%w: unknown reference dataset %s", ErrInvalidParams, name)
+			}
+
+			if dryRun {
+				return Result{Message: "Would reload " + name + " from " + selected[0].Service + ":" + selected[0].Endpoint}, nil
+			}
+			status := services.New(cfg).RefreshReferenceData(selected, cfg.WarmTimeout)
+			dataset := status.Datasets[name]
+			if dataset.Status != "loaded" {
+				return Result{Data: map[string]interface{}{"dataset": dataset}}, fmt.Errorf("reloading %s %s: %s", name, dataset.Status, dataset.Error)
+			}
+			return Result{Changed: true, Message: "Reference dataset " + name + " reloaded", Data: map[string]interface{}{"dataset": dataset}}, nil
+		},
+	})
+
+	Register(Action{
+		Name:        "reregister-broker",
+		Description: "Register this gateway with the broker again",
+		Roles:       []string{"admin"},
+		Run: func(params map[string]string, dryRun bool) (Result, error) {
+			if dryRun {
+				return Result{Message: "Would register with the broker at " + broker.BrokerURL()}, nil
+			}
+			brokerURL, err := broker.Reregister(cfg.Host, cfg.Port)
+			if err != nil {
+				return Result{}, err
+			}
+			return Result{Changed: true, Message: "Registered with the broker", Data: map[string]interface{}{"broker_url": brokerURL}}, nil
+		},
+	})
+
+	Register(Action{
+		Name:        "toggle-read-only",
+		Description: "Switch read-only mode, which rejects all writes on /api/v1",
+		Roles:       []string{"super_admin"},
+		Params: []Param{
+			{Name: "enabled", Type: TypeBool, Required: true, Description: "Whether read-only mode should be on"},
+			{Name: "reason", Type: TypeString, Description: "Shown to clients while read-only mode is on"},
+		},
+		Run: func(params map[string]string, dryRun bool) (Result, error) {
+			enabled, _ := strconv.ParseBool(params["enabled"])
+			current := middleware.GetReadOnly()
+			changed := current.Enabled != enabled || (enabled && current.Reason != params["reason"])
+
+			if dryRun {
+				return Result{Changed: changed, Message: fmt.Sprintf("Would set read-only mode to %v", enabled), Data: map[string]interface{}{"current": current}}, nil
+			}
+			middleware.SetReadOnly(enabled, params["reason"])
+			return Result{Changed: changed, Message: fmt.Sprintf("Read-only mode set to %v", enabled), Data: map[string]interface{}{"state": middleware.GetReadOnly(), "previous": current}}, nil
+		},
+	})
+}
@@ -0,0 +1,226 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// maxAnchors caps the anchors kept for verification and awaiting delivery
+const maxAnchors = 100
+
+var auditAnchors = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "audit_anchors_total",
+	Help:      "Audit chain anchors sent to Central Management by result (ok, failed)",
+}, []string{"result"})
+
+// Anchor pins the head of the audit chain at a point in time. Anchors held
+// by Central Management prove the chain up to them was not rewritten later.
+type Anchor struct {
+	Sequence  uint64    `json:"sequence"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+	Source    string    `json:"source"`
+	Delivered bool      `json:"delivered"`
+}
+
+// AnchorSender persists one anchor outside the gateway
+type AnchorSender func(ctx context.Context, anchor Anchor) error
+
+// ChainBreak describes an entry that does not fit the chain
+type ChainBreak struct {
+	Sequence uint64 `json:"sequence"`
+	ID       string `json:"id"`
+	Reason   string `json:"reason"`
+}
+
+// Verification is the result of checking the retained audit chain
+type Verification struct {
+	Verified        bool         `json:"verified"`
+	Entries         int          `json:"entries"`
+	FirstSequence   uint64       `json:"first_sequence,omitempty"`
+	LastSequence    uint64       `json:"last_sequence,omitempty"`
+	HeadHash        string       `json:"head_hash,omitempty"`
+	AnchorsChecked  int          `json:"anchors_checked"`
+	Breaks          []ChainBreak `json:"breaks"`
+	TruncatedBefore bool         `json:"truncated_before"` // Older entries were evicted; the first entry's link is not checked
+	VerifiedAt      time.Time    `json:"verified_at"`
+}
+
+var (
+	chainMu      sync.Mutex
+	lastSequence uint64
+	lastHash     string
+
+	anchorsMu   sync.Mutex
+	anchors     []Anchor
+	stopAnchors chan struct{}
+)
+
+// chain links an entry to the previous one: it gets the next sequence
+// number, the previous entry's hash and its own hash. Callers hold chainMu.
+func chain(entry Entry) Entry {
+	lastSequence++
+	entry.Sequence = lastSequence
+	entry.PrevHash = lastHash
+	entry.Hash = entryHash(entry)
+	lastHash = entry.Hash
+	return entry
+}
+
+// entryHash is the hex SHA-256 of the previous hash followed by the
+// entry's JSON without its own hash
+func entryHash(entry Entry) string {
+	entry.Hash = ""
+	encoded, _ := json.Marshal(entry)
+	sum := sha256.Sum256(append([]byte(entry.PrevHash), encoded...))
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify recomputes the hash of every entry this process still retains,
+// checks each links to its predecessor without gaps in the sequence, and
+// checks the anchors taken within the retained range. The chain is per
+// process, so entries other replicas wrote to a shared store are not
+// checked.
+func Verify() Verification {
+	entries := recentEntries()
+	result := Verification{Entries: len(entries), Breaks: []ChainBreak{}, VerifiedAt: time.Now()}
+	if len(entries) == 0 {
+		result.Verified = true
+		return result
+	}
+
+	first, last := entries[0], entries[len(entries)-1]
+	result.FirstSequence, result.LastSequence, result.HeadHash = first.Sequence, last.Sequence, last.Hash
+	result.TruncatedBefore = first.Sequence > 1
+
+	hashes := make(map[uint64]string, len(entries))
+	for i, entry := range entries {
+		hashes[entry.Sequence] = entry.Hash
+		if entryHash(entry) != entry.Hash {
+			result.Breaks = append(result.Breaks, ChainBreak{Sequence: entry.Sequence, ID: entry.ID, Reason: "hash does not match the entry"})
+		}
+		if i == 0 {
+			continue
+		}
+		previous := entries[i-1]
+		if entry.Sequence != previous.Sequence+1 {
+			result.Breaks = append(result.Breaks, ChainBreak{Sequence: entry.Sequence, ID: entry.ID, Reason: fmt.Sprintf("sequence follows %d", previous.Sequence)})
+		}
+		if entry.PrevHash != previous.Hash {
+			result.Breaks = append(result.Breaks, ChainBreak{Sequence: entry.Sequence, ID: entry.ID, Reason: "prev_hash does not match the previous entry"})
+		}
+	}
+
+	for _, anchor := range Anchors() {
+		hash, ok := hashes[anchor.Sequence]
+		if !ok {
+			continue
+		}
+		result.AnchorsChecked++
+		if hash != anchor.Hash {
+			result.Breaks = append(result.Breaks, ChainBreak{Sequence: anchor.Sequence, Reason: "hash does not match the anchor taken " + anchor.CreatedAt.UTC().Format(time.RFC3339)})
+		}
+	}
+
+	result.Verified = len(result.Breaks) == 0
+	return result
+}
+
+// Anchors returns the anchors kept for verification, oldest first
+func Anchors() []Anchor {
+	anchorsMu.Lock()
+	defer anchorsMu.Unlock()
+	return append([]Anchor(nil), anchors...)
+}
+
+// StartAnchoring takes an anchor of the chain head every interval and
+// hands it to send. Anchors that fail to send are retried with the next
+// one, until Stop is called.
+func StartAnchoring(interval time.Duration, send AnchorSender) {
+	done := make(chan struct{})
+	anchorsMu.Lock()
+	stopAnchors = done
+	anchorsMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				takeAnchor()
+				deliverAnchors(send)
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// StopAnchoring ends periodic anchoring
+func StopAnchoring() {
+	anchorsMu.Lock()
+	defer anchorsMu.Unlock()
+	if stopAnchors != nil {
+		close(stopAnchors)
+		stopAnchors = nil
+	}
+}
+
+// takeAnchor records the chain head, unless nothing was recorded since the
+// last anchor
+func takeAnchor() {
+	chainMu.Lock()
+	sequence, hash := lastSequence, lastHash
+	chainMu.Unlock()
+	if sequence == 0 {
+		return
+	}
+
+	anchorsMu.Lock()
+	defer anchorsMu.Unlock()
+	if len(anchors) > 0 && anchors[len(anchors)-1].Sequence == sequence {
+		return
+	}
+	anchors = append(anchors, Anchor{Sequence: sequence, Hash: hash, CreatedAt: time.Now(), Source: "internal-api"})
+	if len(anchors) > maxAnchors {
+		anchors = anchors[len(anchors)-maxAnchors:]
+	}
+}
+
+// deliverAnchors sends every anchor not delivered yet, oldest first,
+// stopping at the first failure
+func deliverAnchors(send AnchorSender) {
+	for _, anchor := range Anchors() {
+		if anchor.Delivered {
+			continue
+		}
+		err := send(context.Background(), anchor)
+		if err != nil {
+			auditAnchors.WithLabelValues("failed").Inc()
+			log.WithError(err).WithField("sequence", anchor.Sequence).Warn("Failed to send audit chain anchor")
+			return
+		}
+		auditAnchors.WithLabelValues("ok").Inc()
+		log.WithFields(logrus.Fields{"sequence": anchor.Sequence, "hash": anchor.Hash}).Info("Audit chain anchored")
+
+		anchorsMu.Lock()
+		for i := range anchors {
+			if anchors[i].Sequence == anchor.Sequence {
+				anchors[i].Delivered = true
+			}
+		}
+		anchorsMu.Unlock()
+	}
+}
@@ -0,0 +1,334 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logging.Logger()
+
+// maxRetryDelay caps the backoff between sends of a batch
+const maxRetryDelay = 30 * time.Second
+
+// Overflow policies for a full shipper buffer
+const (
+	OverflowDropOldest = "drop_oldest"
+	OverflowDropNewest = "drop_newest"
+)
+
+var (
+	auditShipped = metrics.Factory.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "audit_shipped_total",
+		Help:      "Audit entries accepted by Central Management",
+	})
+
+	auditShipFailed = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "audit_ship_failed_total",
+		Help:      "Audit entries not shipped by reason (buffer_full, send_failed, rejected, shutdown) and outcome (spilled, dropped)",
+	}, []string{"reason", "outcome"})
+
+	auditShipRetries = metrics.Factory.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "audit_ship_retries_total",
+		Help:      "Repeated sends of audit batches to Central Management",
+	})
+
+	auditShipBuffered = metrics.Factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "audit_ship_buffered",
+		Help:      "Audit entries waiting to be shipped to Central Management",
+	})
+)
+
+// Batch is the body POSTed to Central Management. BatchID stays the same
+// across retries, so the receiver can discard a batch it already stored.
+type Batch struct {
+	BatchID string    `json:"batch_id"`
+	Source  string    `json:"source"`
+	SentAt  time.Time `json:"sent_at"`
+	Count   int       `json:"count"`
+	Events  []Entry   `json:"events"`
+}
+
+// Sender delivers one batch. An error with a ClientError method reporting
+// true means the batch was rejected and is not sent again.
+type Sender func(ctx context.Context, batch Batch) error
+
+// ShipperConfig configures the audit shipper
+type ShipperConfig struct {
+	BatchSize     int           // Entries per batch
+	FlushInterval time.Duration // Longest an entry waits for its batch to fill
+	BufferSize    int           // Entries held while Central Management is slow or down
+	MaxAttempts   int           // Sends of a batch before it is given up
+	RetryDelay    time.Duration // Backoff after the first failed send; doubles per attempt
+	Overflow      string        // OverflowDropOldest or OverflowDropNewest
+	// SpillFile receives entries that cannot be shipped, one JSON object per
+	// line, for replay once Central Management is back. Empty drops them.
+	SpillFile string
+	Send      Sender
+}
+
+// Shipper forwards audit entries to Central Management in batches. Entries
+// are buffered and sent by one background goroutine, so requests never wait
+// for Central Management. A batch that keeps failing, and entries that do
+// not fit the buffer, go to the spill file or are dropped, and are counted.
+type Shipper struct {
+	config ShipperConfig
+	queue  chan Entry
+	done   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.RWMutex
+	stopped bool
+	spillMu sync.Mutex
+}
+
+// NewShipper creates a shipper. Call Start to begin sending.
+func NewShipper(config ShipperConfig) *Shipper {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	if config.BufferSize < config.BatchSize {
+		config.BufferSize = config.BatchSize
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 1
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = time.Second
+	}
+	if config.Overflow != OverflowDropNewest {
+		config.Overflow = OverflowDropOldest
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Shipper{
+		config: config,
+		queue:  make(chan Entry, config.BufferSize),
+		done:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Enqueue buffers an entry for shipping without blocking. When the buffer
+// is full, the oldest or the new entry is given up, by the overflow policy.
+func (s *Shipper) Enqueue(entry Entry) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.stopped {
+		s.giveUp([]Entry{entry}, "shutdown")
+		return
+	}
+
+	select {
+	case s.queue <- entry:
+		auditShipBuffered.Inc()
+		return
+	default:
+	}
+
+	if s.config.Overflow == OverflowDropOldest {
+		select {
+		case oldest := <-s.queue:
+			auditShipBuffered.Dec()
+			s.giveUp([]Entry{oldest}, "buffer_full")
+		default:
+		}
+		select {
+		case s.queue <- entry:
+			auditShipBuffered.Inc()
+			return
+		default:
+		}
+	}
+	s.giveUp([]Entry{entry}, "buffer_full")
+}
+
+// Start sends buffered entries until Stop is called. A batch goes out when
+// it is full or when the flush interval passes.
+func (s *Shipper) Start() {
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.config.FlushInterval)
+		defer ticker.Stop()
+
+		batch := make([]Entry, 0, s.config.BatchSize)
+		for {
+			select {
+			case entry, open := <-s.queue:
+				if !open {
+					s.send(batch)
+					return
+				}
+				auditShipBuffered.Dec()
+				batch = append(batch, entry)
+				if len(batch) >= s.config.BatchSize {
+					s.send(batch)
+					batch = make([]Entry, 0, s.config.BatchSize)
+				}
+			case <-ticker.C:
+				s.send(batch)
+				batch = make([]Entry, 0, s.config.BatchSize)
+			}
+		}
+	}()
+}
+
+// send delivers one batch, retrying with exponential backoff. Rejected
+// batches are not retried.
+func (s *Shipper) send(entries []Entry) {
+	if len(entries) == 0 {
+		return
+	}
+	batch := Batch{
+		BatchID: uuid.New().String(),
+		Source:  "internal-api",
+		Count:   len(entries),
+		Events:  entries,
+	}
+
+	delay := s.config.RetryDelay
+	var err error
+	for attempt := 1; attempt <= s.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			auditShipRetries.Inc()
+			select {
+			case <-time.After(delay):
+			case <-s.ctx.Done():
+			}
+			delay = min(delay*2, maxRetryDelay)
+		}
+		if s.ctx.Err() != nil {
+			s.giveUp(entries, "shutdown")
+			return
+		}
+
+		batch.SentAt = time.Now()
+		err = s.config.Send(s.ctx, batch)
+		if err == nil {
+			auditShipped.Add(float64(len(entries)))
+			return
+		}
+		var clientErr interface{ ClientError() bool }
+		if errors.As(err, &clientErr) && clientErr.ClientError() {
+			log.WithError(err).WithField("entries", len(entries)).Error("Central Management rejected audit batch")
+			s.giveUp(entries, "rejected")
+			return
+		}
+	}
+
+	log.WithError(err).WithFields(logrus.Fields{
+		"entries":  len(entries),
+		"attempts": s.config.MaxAttempts,
+	}).Warn("Failed to ship audit batch to Central Management")
+	s.giveUp(entries, "send_failed")
+}
+
+// giveUp writes entries that will not be shipped to the spill file, or
+// drops them when there is none or it cannot be written
+func (s *Shipper) giveUp(entries []Entry, reason string) {
+	if s.config.SpillFile != "" {
+		err := s.spill(entries)
+		if err == nil {
+			auditShipFailed.WithLabelValues(reason, "spilled").Add(float64(len(entries)))
+			return
+		}
+		log.WithError(err).WithField("file", s.config.SpillFile).Error("Failed to spill audit entries")
+	}
+	auditShipFailed.WithLabelValues(reason, "dropped").Add(float64(len(entries)))
+}
+
+// spill appends entries to the spill file as JSON lines
+func (s *Shipper) spill(entries []Entry) error {
+	s.spillMu.Lock()
+	defer s.spillMu.Unlock()
+
+	file, err := os.OpenFile(s.config.SpillFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write audit entry %s: %v", entry.ID, err)
+		}
+	}
+	return file.Close()
+}
+
+// Stop stops accepting entries and ships what is buffered until ctx ends.
+// Entries not shipped by then, including a batch waiting for a retry, are
+// given up.
+func (s *Shipper) Stop(ctx context.Context) {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.stopped = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		s.cancel()
+		<-s.done
+	}
+	s.cancel()
+}
+
+var (
+	shipper   *Shipper
+	shipperMu sync.RWMutex
+)
+
+// StartShipper ships every entry recorded from now on through a global
+// shipper, alongside the local audit store
+func StartShipper(config ShipperConfig) {
+	s := NewShipper(config)
+	s.Start()
+
+	shipperMu.Lock()
+	defer shipperMu.Unlock()
+	shipper = s
+}
+
+// StopShipper flushes the global shipper, if started
+func StopShipper(ctx context.Context) {
+	shipperMu.RLock()
+	s := shipper
+	shipperMu.RUnlock()
+	if s != nil {
+		s.Stop(ctx)
+	}
+}
+
+// ship hands an entry to the global shipper, if started
+func ship(entry Entry) {
+	shipperMu.RLock()
+	s := shipper
+	shipperMu.RUnlock()
+	if s != nil {
+		s.Enqueue(entry)
+	}
+}
@@ -0,0 +1,653 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/events"
+	"InternalAPI/internal/metrics"
+
+	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxListedIDs caps the resource IDs recorded for one list response
+const maxListedIDs = 1000
+
+var auditStoreFailed = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "audit_store_failed_total",
+	Help:      "Audit store operations that failed by operation (append, query, prune)",
+}, []string{"operation"})
+
+// Entry is a structured audit record for a single request
+type Entry struct {
+	ID            string    `json:"id"`
+	RequestID     string    `json:"request_id"`
+	Timestamp     time.Time `json:"timestamp"`
+	Method        string    `json:"method"`
+	Path          string    `json:"path"`
+	Route         string    `json:"route"`
+	Query         string    `json:"query,omitempty"`
+	IP            string    `json:"ip"`
+	UserAgent     string    `json:"user_agent"`
+	UserID        string    `json:"user_id"`
+	Action        string    `json:"action"`
+	ResourceType  string    `json:"resource_type,omitempty"`
+	ResourceID    string    `json:"resource_id,omitempty"`
+	ResourceIDs   []string  `json:"resource_ids,omitempty"` // Resources a list response returned
+	Status        int       `json:"status"`
+	DurationMs    int64     `json:"duration_ms"`
+	CapturePolicy string    `json:"capture_policy,omitempty"`
+	Backend       string    `json:"backend,omitempty"` // PMS adapter that served a booking or room call
+
+	// Hash chain, set by Record; see Verify
+	Sequence uint64 `json:"sequence"`
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+}
+
+// Filter selects audit entries; zero values match everything
+type Filter struct {
+	UserID       string
+	ResourceType string
+	ResourceID   string
+	Action       string
+	Since        time.Time
+	Until        time.Time
+}
+
+// Matches reports whether an entry satisfies the filter
+func (f Filter) Matches(e Entry) bool {
+	if f.UserID != "" && e.UserID != f.UserID {
+		return false
+	}
+	if f.ResourceType != "" && !strings.EqualFold(e.ResourceType, f.ResourceType) {
+		return false
+	}
+	if f.ResourceID != "" && !e.covers(f.ResourceID) {
+		return false
+	}
+	if f.Action != "" && e.Action != f.Action {
+		return false
+	}
+	if !f.Since.IsZero() && e.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Timestamp.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// covers reports whether the entry accessed the resource with this ID,
+// on its own or in a list
+func (e Entry) covers(id string) bool {
+	if e.ResourceID == id {
+		return true
+	}
+	for _, listed := range e.ResourceIDs {
+		if listed == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Store persists audit entries
+type Store interface {
+	// Backend names the store: memory, redis or postgres
+	Backend() string
+	// Append stores an entry
+	Append(ctx context.Context, entry Entry) error
+	// Query returns matching entries, oldest first
+	Query(ctx context.Context, filter Filter) ([]Entry, error)
+	// Prune removes entries recorded before cutoff and returns how many
+	// were removed
+	Prune(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// NewStore creates the audit store for the configured backend. The memory
+// backend is recent itself; the others keep entries for retention.
+func NewStore(backend string, recent *MemoryStore, redisURL, databaseURL string, retention time.Duration) (Store, error) {
+	switch backend {
+	case "", "memory":
+		return recent, nil
+	case "redis":
+		return NewRedisStore(redisURL, retention)
+	case "postgres":
+		return NewPostgresStore(databaseURL)
+	default:
+		return nil, fmt.Errorf("unknown audit store backend: %s", backend)
+	}
+}
+
+// MemoryStore keeps the most recent audit entries in a fixed-size ring buffer
+type MemoryStore struct {
+	entries []Entry
+	next    int
+	full    bool
+	mu      sync.RWMutex
+}
+
+// NewMemoryStore creates a ring buffer store holding up to capacity entries
+func NewMemoryStore(capacity int) *MemoryStore {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &MemoryStore{
+		entries: make([]Entry, capacity),
+	}
+}
+
+// Backend names the store
+func (s *MemoryStore) Backend() string {
+	return "memory"
+}
+
+// Append adds an entry, overwriting the oldest one when full
+func (s *MemoryStore) Append(ctx context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[s.next] = entry
+	s.next = (s.next + 1) % len(s.entries)
+	if s.next == 0 {
+		s.full = true
+	}
+	return nil
+}
+
+// Query returns matching entries, oldest first
+func (s *MemoryStore) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []Entry{}
+	start, count := 0, s.next
+	if s.full {
+		start, count = s.next, len(s.entries)
+	}
+
+	for i := 0; i < count; i++ {
+		entry := s.entries[(start+i)%len(s.entries)]
+		if filter.Matches(entry) {
+			result = append(result, entry)
+		}
+	}
+
+	return result, nil
+}
+
+// Prune is a no-op because the ring buffer evicts the oldest entries itself
+func (s *MemoryStore) Prune(ctx context.Context, cutoff time.Time) (int, error) {
+	return 0, nil
+}
+
+// RedisStore keeps audit entries in Redis, shared between replicas. Each
+// entry is stored once and expires after the retention period; sorted sets
+// scored by timestamp index all entries and the entries of each resource.
+type RedisStore struct {
+	client    *redis.Client
+	prefix    string
+	retention time.Duration
+}
+
+// NewRedisStore connects to Redis using a redis:// URL
+func NewRedisStore(redisURL string, retention time.Duration) (*RedisStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &RedisStore{client: client, prefix: "internal-api:audit:", retention: retention}, nil
+}
+
+// Backend names the store
+func (s *RedisStore) Backend() string {
+	return "redis"
+}
+
+// indexKey is the sorted set of entries of one resource, or of all entries
+// when resourceType is empty
+func (s *RedisStore) indexKey(resourceType, resourceID string) string {
+	if resourceType == "" {
+		return s.prefix + "index"
+	}
+	return s.prefix + "resource:" + strings.ToLower(resourceType) + ":" + resourceID
+}
+
+// Append stores an entry and adds it to the indexes, dropping index members
+// older than the retention period
+func (s *RedisStore) Append(ctx context.Context, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	indexes := []string{s.indexKey("", "")}
+	if entry.ResourceType != "" {
+		if entry.ResourceID != "" {
+			indexes = append(indexes, s.indexKey(entry.ResourceType, entry.ResourceID))
+		}
+		for _, id := range entry.ResourceIDs {
+			indexes = append(indexes, s.indexKey(entry.ResourceType, id))
+		}
+	}
+
+	expired := strconv.FormatInt(time.Now().Add(-s.retention).UnixMilli(), 10)
+	member := redis.Z{Score: float64(entry.Timestamp.UnixMilli()), Member: entry.ID}
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, s.prefix+"entry:"+entry.ID, data, s.retention)
+	for _, key := range indexes {
+		pipe.ZAdd(ctx, key, member)
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+expired)
+		pipe.Expire(ctx, key, s.retention)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Query returns matching entries, oldest first. A filter on a resource ID
+// reads that resource's index, any other filter the index of all entries.
+func (s *RedisStore) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	key := s.indexKey("", "")
+	if filter.ResourceType != "" && filter.ResourceID != "" {
+		key = s.indexKey(filter.ResourceType, filter.ResourceID)
+	}
+	rangeBy := &redis.ZRangeBy{Min: "-inf", Max: "+inf"}
+	if !filter.Since.IsZero() {
+		rangeBy.Min = strconv.FormatInt(filter.Since.UnixMilli(), 10)
+	}
+	if !filter.Until.IsZero() {
+		rangeBy.Max = strconv.FormatInt(filter.Until.UnixMilli(), 10)
+	}
+	ids, err := s.client.ZRangeByScore(ctx, key, rangeBy).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Entry{}
+	for start := 0; start < len(ids); start += 500 {
+		end := min(start+500, len(ids))
+		keys := make([]string, 0, end-start)
+		for _, id := range ids[start:end] {
+			keys = append(keys, s.prefix+"entry:"+id)
+		}
+		values, err := s.client.MGet(ctx, keys...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			data, ok := value.(string)
+			if !ok {
+				continue // Expired since it was indexed
+			}
+			var entry Entry
+			if json.Unmarshal([]byte(data), &entry) == nil && filter.Matches(entry) {
+				result = append(result, entry)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Prune is a no-op because Redis expires entries itself
+func (s *RedisStore) Prune(ctx context.Context, cutoff time.Time) (int, error) {
+	return 0, nil
+}
+
+// PostgresStore keeps audit entries in Postgres, shared between replicas.
+// The resources an entry accessed, including those of a list response, are
+// indexed in audit_resources.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore connects to Postgres and ensures the audit tables exist
+func NewPostgresStore(databaseURL string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS audit_entries (
+		id          TEXT PRIMARY KEY,
+		recorded_at TIMESTAMPTZ NOT NULL,
+		user_id     TEXT NOT NULL,
+		action      TEXT NOT NULL,
+		entry       JSONB NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS audit_entries_recorded_at ON audit_entries (recorded_at);
+	CREATE TABLE IF NOT EXISTS audit_resources (
+		entry_id      TEXT NOT NULL REFERENCES audit_entries (id) ON DELETE CASCADE,
+		resource_type TEXT NOT NULL,
+		resource_id   TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS audit_resources_resource ON audit_resources (resource_type, resource_id);
+	CREATE INDEX IF NOT EXISTS audit_resources_entry ON audit_resources (entry_id)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create audit tables: %w", err)
+	}
+
+	return &PostgresStore{db: db}, nil
+}
+
+// Backend names the store
+func (s *PostgresStore) Backend() string {
+	return "postgres"
+}
+
+// Append stores an entry with the resources it accessed
+func (s *PostgresStore) Append(ctx context.Context, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO audit_entries (id, recorded_at, user_id, action, entry) VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (id) DO NOTHING`,
+		entry.ID, entry.Timestamp, entry.UserID, entry.Action, data)
+	if err != nil {
+		return err
+	}
+
+	resourceType := strings.ToLower(entry.ResourceType)
+	ids := entry.ResourceIDs
+	if entry.ResourceID != "" {
+		ids = append([]string{entry.ResourceID}, ids...)
+	}
+	for _, id := range ids {
+		if resourceType == "" {
+			break
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO audit_resources (entry_id, resource_type, resource_id) VALUES ($1, $2, $3)`,
+			entry.ID, resourceType, id)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Query returns matching entries, oldest first
+func (s *PostgresStore) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	query := `SELECT e.entry FROM audit_entries e WHERE TRUE`
+	args := []interface{}{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.ResourceID != "" {
+		query += ` AND EXISTS (SELECT 1 FROM audit_resources r WHERE r.entry_id = e.id AND r.resource_id = ` + arg(filter.ResourceID)
+		if filter.ResourceType != "" {
+			query += ` AND r.resource_type = ` + arg(strings.ToLower(filter.ResourceType))
+		}
+		query += `)`
+	}
+	if filter.UserID != "" {
+		query += ` AND e.user_id = ` + arg(filter.UserID)
+	}
+	if filter.Action != "" {
+		query += ` AND e.action = ` + arg(filter.Action)
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND e.recorded_at >= ` + arg(filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query += ` AND e.recorded_at <= ` + arg(filter.Until)
+	}
+	query += ` ORDER BY e.recorded_at, e.id`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []Entry{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var entry Entry
+		if json.Unmarshal(data, &entry) == nil && filter.Matches(entry) {
+			result = append(result, entry)
+		}
+	}
+	return result, rows.Err()
+}
+
+// Prune deletes entries recorded before cutoff, with their resources
+func (s *PostgresStore) Prune(ctx context.Context, cutoff time.Time) (int, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM audit_entries WHERE recorded_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+var (
+	// recent holds the entries this process recorded, which is the part of
+	// the hash chain Verify can check
+	recent *MemoryStore = NewMemoryStore(10000)
+	// store answers access reports; it is recent unless a shared backend
+	// is configured
+	store   Store = recent
+	storeMu sync.RWMutex
+)
+
+// Init sets the ring of recently recorded entries and the store access
+// reports are read from. A shared store is pruned of entries older than
+// retention every hour.
+func Init(recentEntries *MemoryStore, s Store, retention time.Duration) {
+	storeMu.Lock()
+	recent, store = recentEntries, s
+	storeMu.Unlock()
+
+	if s != Store(recentEntries) && retention > 0 {
+		go prune(s, retention)
+	}
+}
+
+// prune removes entries older than retention from s every hour
+func prune(s Store, retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		removed, err := s.Prune(ctx, time.Now().Add(-retention))
+		cancel()
+		if err != nil {
+			auditStoreFailed.WithLabelValues("prune").Inc()
+			log.WithError(err).Warn("Failed to prune audit entries")
+		} else if removed > 0 {
+			log.WithField("removed", removed).Info("Pruned audit entries")
+		}
+	}
+}
+
+// Record links an entry into the hash chain, appends it to the recent
+// entries and the audit store, hands it to the shipper and announces it on
+// the admin event bus. A failed store write is logged and counted; the
+// entry is still logged and shipped.
+func Record(entry Entry) {
+	chainMu.Lock()
+	entry = chain(entry)
+	storeMu.RLock()
+	recentEntries, s := recent, store
+	storeMu.RUnlock()
+	recentEntries.Append(context.Background(), entry)
+	ship(entry)
+	chainMu.Unlock()
+
+	if s != Store(recentEntries) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := s.Append(ctx, entry)
+		cancel()
+		if err != nil {
+			auditStoreFailed.WithLabelValues("append").Inc()
+			log.WithError(err).WithField("id", entry.ID).Error("Failed to store audit entry")
+		}
+	}
+
+	events.PublishAdmin(events.Event{
+		ID:         entry.ID,
+		Type:       "audit.recorded",
+		Source:     "internal-api",
+		OccurredAt: time.Now(), // Timestamp is when a long request started
+		Data:       entry.fields(),
+	})
+}
+
+// fields returns the entry as event data, keyed like its JSON form
+func (e Entry) fields() map[string]interface{} {
+	data := map[string]interface{}{}
+	if raw, err := json.Marshal(e); err == nil {
+		json.Unmarshal(raw, &data)
+	}
+	return data
+}
+
+// Query returns entries from the audit store matching the filter
+func Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	storeMu.RLock()
+	s := store
+	storeMu.RUnlock()
+
+	entries, err := s.Query(ctx, filter)
+	if err != nil {
+		auditStoreFailed.WithLabelValues("query").Inc()
+	}
+	return entries, err
+}
+
+// recentEntries returns the entries this process recorded that are still
+// in the ring buffer, oldest first
+func recentEntries() []Entry {
+	storeMu.RLock()
+	r := recent
+	storeMu.RUnlock()
+	entries, _ := r.Query(context.Background(), Filter{})
+	return entries
+}
+
+// Coverage describes which accesses an access report can include
+type Coverage struct {
+	Store      string   `json:"store"`       // memory: this replica since it started, up to AUDIT_STORE_CAPACITY entries
+	Shared     bool     `json:"shared"`      // Entries of every replica, kept across restarts
+	NotCovered []string `json:"not_covered"` // Accesses that are never recorded per resource
+}
+
+// ReportCoverage describes the coverage of access reports from the current
+// store
+func ReportCoverage() Coverage {
+	storeMu.RLock()
+	backend := store.Backend()
+	storeMu.RUnlock()
+	return Coverage{
+		Store:  backend,
+		Shared: backend != "memory",
+		NotCovered: []string{
+			"GraphQL queries",
+			"streamed responses",
+			fmt.Sprintf("list responses beyond their first %d items", maxListedIDs),
+			"list responses answered 304 Not Modified",
+		},
+	}
+}
+
+// ListedIDs returns the IDs of the items in a JSON list response of
+// resourceType: a top-level array, or an array under resourceType or data.
+// At most maxListedIDs are returned.
+func ListedIDs(resourceType string, body []byte) []string {
+	var decoded interface{}
+	if json.Unmarshal(body, &decoded) != nil {
+		return nil
+	}
+	items, ok := decoded.([]interface{})
+	if object, isObject := decoded.(map[string]interface{}); isObject {
+		if items, ok = object[resourceType].([]interface{}); !ok {
+			items, ok = object["data"].([]interface{})
+		}
+	}
+	if !ok {
+		return nil
+	}
+
+	ids := []string{}
+	for _, item := range items {
+		object, _ := item.(map[string]interface{})
+		var id string
+		switch v := object["id"].(type) {
+		case string:
+			id = v
+		case float64:
+			id = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		if id == "" {
+			continue
+		}
+		ids = append(ids, id)
+		if len(ids) == maxListedIDs {
+			break
+		}
+	}
+	return ids
+}
+
+// ActionForMethod classifies an HTTP method as a read or modify action
+func ActionForMethod(method string) string {
+	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		return "read"
+	default:
+		return "modify"
+	}
+}
+
+// ResourceFromRoute extracts the resource type and ID from a route pattern
+// such as /api/v1/guests/:id, where the type is the segment before the ID
+func ResourceFromRoute(route string, params map[string]string) (string, string) {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+
+	for i := len(segments) - 1; i > 0; i-- {
+		if strings.HasPrefix(segments[i], ":") && !strings.HasPrefix(segments[i-1], ":") {
+			return segments[i-1], params[strings.TrimPrefix(segments[i], ":")]
+		}
+	}
+
+	if len(segments) > 0 && !strings.HasPrefix(segments[len(segments)-1], ":") {
+		return segments[len(segments)-1], ""
+	}
+	return "", ""
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/middleware"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/roles"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Token renewal recommendations returned by TokenStatus
+const (
+	RenewNone    = "none"    // The token has plenty of time left
+	RenewExtend  = "extend"  // Call POST /auth/extend now
+	RenewRefresh = "refresh" // Extension is not possible; use the refresh token
+)
+
+var (
+	// ErrExtensionNotAllowed is returned when the user's roles may not extend sessions
+	ErrExtensionNotAllowed = errors.New("session extension is not allowed for this user")
+	// ErrExtensionLimit is returned once a session was extended the maximum number of times
+	ErrExtensionLimit = errors.New("session extension limit reached")
+)
+
+// ExtensionPolicy controls which sessions may be extended without a refresh
+// token, such as kiosks that must not expire mid-interaction
+type ExtensionPolicy struct {
+	Roles         []string      // Roles allowed to extend; none disables extension
+	MaxExtensions int           // Extensions allowed per session
+	RenewBefore   time.Duration // Recommend renewal once less than this remains
+}
+
+// extensionPolicy is the policy applied by TokenStatus and Extend
+var extensionPolicy ExtensionPolicy
+
+// InitExtensions sets the session extension policy. Blank role names are ignored.
+func InitExtensions(policy ExtensionPolicy) {
+	allowed := make([]string, 0, len(policy.Roles))
+	for _, role := range policy.Roles {
+		if role = strings.TrimSpace(role); role != "" {
+			allowed = append(allowed, role)
+		}
+	}
+	policy.Roles = allowed
+	extensionPolicy = policy
+}
+
+// canExtend reports whether the policy lets these claims be extended again
+func canExtend(claims *middleware.Claims) error {
+	if len(extensionPolicy.Roles) == 0 || !roles.Satisfies(claims.Roles, extensionPolicy.Roles...) {
+		return ErrExtensionNotAllowed
+	}
+	if claims.Extensions >= extensionPolicy.MaxExtensions {
+		return ErrExtensionLimit
+	}
+	return nil
+}
+
+// TokenStatus reports how long an access token remains valid and whether
+// the client should extend or refresh it now
+func TokenStatus(tokenString string) (*models.TokenStatus, error) {
+	claims, err := middleware.ValidateJWT(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := claims.ExpiresAt.Time
+	renewAt := expiresAt.Add(-extensionPolicy.RenewBefore)
+	extendable := canExtend(claims) == nil
+
+	status := &models.TokenStatus{
+		ExpiresAt:        expiresAt.Unix(),
+		RemainingSeconds: int(expiresAt.Sub(now).Seconds()),
+		RenewAt:          renewAt.Unix(),
+		Recommendation:   RenewNone,
+		CanExtend:        extendable,
+		Extensions:       claims.Extensions,
+		MaxExtensions:    extensionPolicy.MaxExtensions,
+	}
+	if claims.IssuedAt != nil {
+		status.IssuedAt = claims.IssuedAt.Unix()
+	}
+	if !now.Before(renewAt) {
+		status.Recommendation = RenewRefresh
+		if extendable {
+			status.Recommendation = RenewExtend
+		}
+	}
+	return status, nil
+}
+
+// Extend replaces a valid access token with one carrying a fresh lifetime.
+// The old token is revoked so only one copy of the session stays usable.
+func (ts *TokenService) Extend(tokenString string) (*models.TokenExtensionResponse, error) {
+	claims, err := middleware.ValidateJWT(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if err := canExtend(claims); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	extended := &middleware.Claims{
+		UserID:     claims.UserID,
+		Username:   claims.Username,
+		Email:      claims.Email,
+		Roles:      claims.Roles,
+		Extensions: claims.Extensions + 1,
+		FamilyID:   claims.FamilyID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   claims.Subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ts.accessTTL)),
+		},
+	}
+
+	accessToken, err := middleware.SignJWT(extended)
+	if err != nil {
+		return nil, err
+	}
+	if err := middleware.BlacklistToken(tokenString, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+	middleware.TrackSession(extended, accessToken)
+
+	return &models.TokenExtensionResponse{
+		AccessToken:         accessToken,
+		ExpiresIn:           int(ts.accessTTL.Seconds()),
+		TokenType:           "Bearer",
+		Extensions:          extended.Extensions,
+		ExtensionsRemaining: extensionPolicy.MaxExtensions - extended.Extensions,
+	}, nil
+}
+
+// ExtendAccessToken extends an access token using the global token service
+func ExtendAccessToken(tokenString string) (*models.TokenExtensionResponse, error) {
+	if tokenService == nil {
+		return nil, ErrNotInitialized
+	}
+	return tokenService.Extend(tokenString)
+}
@@ -0,0 +1,265 @@
+package lockout
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/audit"
+	"InternalAPI/internal/logging"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logging.Logger()
+
+// ErrNotLocked is returned when unlocking an account that is not locked
+var ErrNotLocked = errors.New("account is not locked")
+
+// Config holds the lockout policy
+type Config struct {
+	MaxFailures  int           // Failed logins that lock the account
+	Window       time.Duration // Failures older than this no longer count
+	LockDuration time.Duration // How long a locked account stays locked
+}
+
+// Tracker counts failed logins per username and locks accounts that exceed
+// the policy
+type Tracker struct {
+	config   Config
+	accounts map[string]*account
+	mu       sync.Mutex
+}
+
+// account is the lockout state of a single username
+type account struct {
+	failures     int
+	firstFailure time.Time
+	lastFailure  time.Time
+	lastIP       string
+	lockedUntil  time.Time
+}
+
+// Status is the public view of a tracked account
+type Status struct {
+	Username    string `json:"username"`
+	Failures    int    `json:"failures"`
+	Locked      bool   `json:"locked"`
+	LockedUntil int64  `json:"locked_until,omitempty"`
+	LastFailure int64  `json:"last_failure"`
+	LastIP      string `json:"last_ip"`
+}
+
+// New creates a tracker for the given policy
+func New(config Config) *Tracker {
+	if config.MaxFailures <= 0 {
+		config.MaxFailures = 5
+	}
+	if config.Window <= 0 {
+		config.Window = 15 * time.Minute
+	}
+	if config.LockDuration <= 0 {
+		config.LockDuration = 15 * time.Minute
+	}
+
+	return &Tracker{
+		config:   config,
+		accounts: make(map[string]*account),
+	}
+}
+
+// Check reports whether a username is locked and until when
+func (t *Tracker) Check(username string) (bool, time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	acct, exists := t.accounts[normalize(username)]
+	if !exists || !time.Now().Before(acct.lockedUntil) {
+		return false, time.Time{}
+	}
+	return true, acct.lockedUntil
+}
+
+// RecordFailure counts a failed login and locks the account once the policy
+// is exceeded. It reports whether the account is now locked.
+func (t *Tracker) RecordFailure(username, ip string) (bool, time.Time) {
+	key := normalize(username)
+	now := time.Now()
+
+	t.mu.Lock()
+	acct, exists := t.accounts[key]
+	if !exists || now.Sub(acct.firstFailure) > t.config.Window {
+		acct = &account{firstFailure: now}
+		t.accounts[key] = acct
+	}
+	acct.failures++
+	acct.lastFailure = now
+	acct.lastIP = ip
+
+	locked := acct.failures >= t.config.MaxFailures && !now.Before(acct.lockedUntil)
+	if locked {
+		acct.lockedUntil = now.Add(t.config.LockDuration)
+	}
+	failures, until := acct.failures, acct.lockedUntil
+	t.mu.Unlock()
+
+	if locked {
+		log.WithFields(logrus.Fields{
+			"username":     key,
+			"failures":     failures,
+			"ip":           ip,
+			"locked_until": until,
+		}).Warn("Account locked after repeated failed logins")
+		recordAudit("account_locked", key, ip, "")
+	}
+	return now.Before(until), until
+}
+
+// RecordSuccess clears the failure count after a successful login
+func (t *Tracker) RecordSuccess(username string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.accounts, normalize(username))
+}
+
+// Unlock lifts a lock before it expires
+func (t *Tracker) Unlock(username, adminID string) error {
+	key := normalize(username)
+
+	t.mu.Lock()
+	acct, exists := t.accounts[key]
+	if !exists || !time.Now().Before(acct.lockedUntil) {
+		t.mu.Unlock()
+		return ErrNotLocked
+	}
+	delete(t.accounts, key)
+	t.mu.Unlock()
+
+	log.WithFields(logrus.Fields{
+		"username":    key,
+		"unlocked_by": adminID,
+	}).Info("Account unlocked by admin")
+	recordAudit("account_unlocked", key, "", adminID)
+	return nil
+}
+
+// Status returns every tracked account, locked accounts first
+func (t *Tracker) Status() []Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	list := make([]Status, 0, len(t.accounts))
+	for username, acct := range t.accounts {
+		status := Status{
+			Username:    username,
+			Failures:    acct.failures,
+			Locked:      now.Before(acct.lockedUntil),
+			LastFailure: acct.lastFailure.Unix(),
+			LastIP:      acct.lastIP,
+		}
+		if status.Locked {
+			status.LockedUntil = acct.lockedUntil.Unix()
+		}
+		list = append(list, status)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Locked != list[j].Locked {
+			return list[i].Locked
+		}
+		return list[i].LastFailure > list[j].LastFailure
+	})
+	return list
+}
+
+// cleanup drops accounts whose failures and locks have both expired
+func (t *Tracker) cleanup() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		t.mu.Lock()
+		now := time.Now()
+		for username, acct := range t.accounts {
+			if now.Sub(acct.firstFailure) > t.config.Window && !now.Before(acct.lockedUntil) {
+				delete(t.accounts, username)
+			}
+		}
+		t.mu.Unlock()
+	}
+}
+
+// normalize makes usernames case-insensitive so "Alice" and "alice" share a counter
+func normalize(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// recordAudit writes a lockout event to the audit store
+func recordAudit(action, username, ip, userID string) {
+	audit.Record(audit.Entry{
+		ID:           uuid.New().String(),
+		Timestamp:    time.Now(),
+		IP:           ip,
+		UserID:       userID,
+		Action:       action,
+		ResourceType: "account",
+		ResourceID:   username,
+	})
+}
+
+// Global lockout tracker
+var tracker *Tracker
+
+// Init enables account lockout with the given policy
+func Init(config Config) {
+	tracker = New(config)
+	go tracker.cleanup()
+}
+
+// Enabled reports whether account lockout is active
+func Enabled() bool {
+	return tracker != nil
+}
+
+// Check reports whether a username is locked using the global tracker
+func Check(username string) (bool, time.Time) {
+	if tracker == nil {
+		return false, time.Time{}
+	}
+	return tracker.Check(username)
+}
+
+// RecordFailure counts a failed login using the global tracker
+func RecordFailure(username, ip string) (bool, time.Time) {
+	if tracker == nil {
+		return false, time.Time{}
+	}
+	return tracker.RecordFailure(username, ip)
+}
+
+// RecordSuccess clears failures using the global tracker
+func RecordSuccess(username string) {
+	if tracker != nil {
+		tracker.RecordSuccess(username)
+	}
+}
+
+// Unlock lifts a lock using the global tracker
+func Unlock(username, adminID string) error {
+	if tracker == nil {
+		return ErrNotLocked
+	}
+	return tracker.Unlock(username, adminID)
+}
+
+// List returns all tracked accounts using the global tracker
+func List() []Status {
+	if tracker == nil {
+		return []Status{}
+	}
+	return tracker.Status()
+}
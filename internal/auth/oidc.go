@@ -0,0 +1,415 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/middleware"
+	"InternalAPI/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidOIDCState is returned when a callback carries an unknown or expired state
+var ErrInvalidOIDCState = errors.New("invalid or expired OIDC state")
+
+// OIDCStateCookie carries an in-flight login from the login redirect to the
+// callback, so the callback may be served by any replica
+const OIDCStateCookie = "oidc_login"
+
+// OIDCLoginTTL is how long a user has to complete a login at the provider
+const OIDCLoginTTL = 10 * time.Minute
+
+// OIDCConfig configures the OpenID Connect provider
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	RolesClaim   string
+}
+
+var (
+	// oidcStateSecret signs login state cookies; empty derives keys from the
+	// JWT keys
+	oidcStateSecret   string
+	oidcStateSecretMu sync.RWMutex
+)
+
+// SetOIDCStateSecret sets the secret login state cookies are signed with.
+// An empty secret derives the key from the current JWT keys, so it rotates
+// with them.
+func SetOIDCStateSecret(secret string) {
+	oidcStateSecretMu.Lock()
+	defer oidcStateSecretMu.Unlock()
+	oidcStateSecret = secret
+}
+
+// stateKeys returns the keys accepted for login state cookies, the signing
+// key first
+func stateKeys() [][]byte {
+	oidcStateSecretMu.RLock()
+	secret := oidcStateSecret
+	oidcStateSecretMu.RUnlock()
+	if secret != "" {
+		return [][]byte{[]byte(secret)}
+	}
+	return middleware.DeriveJWTKeys("oidc-state")
+}
+
+// oidcDiscovery holds the fields we use from the provider's discovery document
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcLoginState is an in-flight authorization request, kept in the signed
+// state cookie
+type oidcLoginState struct {
+	State        string `json:"s"`
+	Nonce        string `json:"n"`
+	CodeVerifier string `json:"v"`
+	ExpiresAt    int64  `json:"e"`
+}
+
+// OIDCProvider implements the authorization code flow with PKCE
+type OIDCProvider struct {
+	config OIDCConfig
+	client *http.Client
+
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+	keysAt    time.Time
+	mu        sync.Mutex
+}
+
+// NewOIDCProvider creates a provider; discovery happens lazily on first use
+func NewOIDCProvider(config OIDCConfig) *OIDCProvider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email"}
+	}
+	if config.RolesClaim == "" {
+		config.RolesClaim = "roles"
+	}
+
+	return &OIDCProvider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// AuthCodeURL starts a login. It returns the provider URL to redirect the
+// user to and the value of the state cookie to set for the callback.
+func (p *OIDCProvider) AuthCodeURL() (string, string, error) {
+	disc, err := p.discover()
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomString(24)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString(24)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomString(48)
+	if err != nil {
+		return "", "", err
+	}
+
+	cookie, err := p.encodeState(oidcLoginState{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(OIDCLoginTTL).Unix(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.config.ClientID)
+	params.Set("redirect_uri", p.config.RedirectURL)
+	params.Set("scope", strings.Join(p.config.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	params.Set("code_challenge_method", "S256")
+
+	return disc.AuthorizationEndpoint + "?" + params.Encode(), cookie, nil
+}
+
+// Exchange completes a login: it checks the state against the state cookie
+// set by AuthCodeURL, redeems the code, verifies the ID token and returns the
+// user it describes
+func (p *OIDCProvider) Exchange(code, state, cookie string) (models.UserInfo, error) {
+	login, err := p.decodeState(cookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(login.State), []byte(state)) != 1 {
+		return models.UserInfo{}, ErrInvalidOIDCState
+	}
+
+	disc, err := p.discover()
+	if err != nil {
+		return models.UserInfo{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("client_secret", p.config.ClientSecret)
+	form.Set("code_verifier", login.CodeVerifier)
+
+	resp, err := p.client.PostForm(disc.TokenEndpoint, form)
+	if err != nil {
+		return models.UserInfo{}, fmt.Errorf("token exchange failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokenResp struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return models.UserInfo{}, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if resp.StatusCode >= 400 || tokenResp.IDToken == "" {
+		return models.UserInfo{}, fmt.Errorf("token exchange rejected: %s", tokenResp.Error)
+	}
+
+	return p.verifyIDToken(tokenResp.IDToken, login.Nonce)
+}
+
+// encodeState serializes and signs a login state into a cookie value
+func (p *OIDCProvider) encodeState(login oidcLoginState) (string, error) {
+	payload, err := json.Marshal(login)
+	if err != nil {
+		return "", err
+	}
+	keys := stateKeys()
+	if len(keys) == 0 {
+		return "", errors.New("no OIDC state key configured")
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signState(keys[0], encoded)), nil
+}
+
+// decodeState verifies a state cookie value and returns the unexpired login
+// it carries
+func (p *OIDCProvider) decodeState(cookie string) (oidcLoginState, error) {
+	var login oidcLoginState
+
+	encoded, sig, ok := strings.Cut(cookie, ".")
+	if !ok {
+		return login, ErrInvalidOIDCState
+	}
+	expected, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return login, ErrInvalidOIDCState
+	}
+	valid := false
+	for _, key := range stateKeys() {
+		if hmac.Equal(expected, signState(key, encoded)) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return login, ErrInvalidOIDCState
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(payload, &login) != nil {
+		return login, ErrInvalidOIDCState
+	}
+	if login.State == "" || time.Now().Unix() > login.ExpiresAt {
+		return login, ErrInvalidOIDCState
+	}
+	return login, nil
+}
+
+// signState returns the HMAC-SHA256 of an encoded login state under key
+func signState(key []byte, encoded string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("oidc-state:" + encoded))
+	return mac.Sum(nil)
+}
+
+// verifyIDToken validates the ID token signature and standard claims
+func (p *OIDCProvider) verifyIDToken(idToken, nonce string) (models.UserInfo, error) {
+	disc, err := p.discover()
+	if err != nil {
+		return models.UserInfo{}, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(kid)
+	},
+		jwt.WithIssuer(disc.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return models.UserInfo{}, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if claims["nonce"] != nonce {
+		return models.UserInfo{}, errors.New("invalid ID token: nonce mismatch")
+	}
+
+	user := models.UserInfo{}
+	user.UserID, _ = claims["sub"].(string)
+	user.Email, _ = claims["email"].(string)
+	user.Username, _ = claims["preferred_username"].(string)
+	if user.Username == "" {
+		user.Username = user.Email
+	}
+	if roles, ok := claims[p.config.RolesClaim].([]interface{}); ok {
+		for _, r := range roles {
+			if role, ok := r.(string); ok {
+				user.Roles = append(user.Roles, role)
+			}
+		}
+	}
+	if len(user.Roles) == 0 {
+		user.Roles = []string{"user"}
+	}
+
+	return user, nil
+}
+
+// discover fetches and caches the provider discovery document
+func (p *OIDCProvider) discover() (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	resp, err := p.client.Get(wellKnown)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OIDC discovery returned status %d", resp.StatusCode)
+	}
+
+	var disc oidcDiscovery
+	if err := json.NewDecoder(resp.Body).Decode(&disc); err != nil {
+		return nil, fmt.Errorf("failed to decode OIDC discovery document: %w", err)
+	}
+
+	p.discovery = &disc
+	return p.discovery, nil
+}
+
+// publicKey returns the signing key for a key ID, refreshing the JWKS when unknown
+func (p *OIDCProvider) publicKey(kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	key, exists := p.keys[kid]
+	stale := time.Since(p.keysAt) > time.Hour
+	jwksURI := p.discovery.JWKSURI
+	p.mu.Unlock()
+
+	if exists && !stale {
+		return key, nil
+	}
+
+	keys, err := p.fetchJWKS(jwksURI)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.keysAt = time.Now()
+	p.mu.Unlock()
+
+	key, exists = keys[kid]
+	if !exists {
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+	return key, nil
+}
+
+// fetchJWKS downloads the provider's RSA signing keys
+func (p *OIDCProvider) fetchJWKS(jwksURI string) (map[string]*rsa.PublicKey, error) {
+	resp, err := p.client.Get(jwksURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	return keys, nil
+}
+
+// randomString returns a URL-safe random string from n random bytes
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,430 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/models"
+
+	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+)
+
+// RefreshToken is the server-side record of an issued refresh token
+type RefreshToken struct {
+	FamilyID  string          `json:"family_id"`
+	User      models.UserInfo `json:"user"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	Used      bool            `json:"-"` // Rotated already
+	Revoked   bool            `json:"-"` // The family was revoked
+}
+
+// RefreshTokenStore persists refresh tokens and their families so rotation,
+// reuse detection and revocation hold across replicas and restarts. Tokens
+// are stored as SHA-256 hashes.
+type RefreshTokenStore interface {
+	// Save stores a newly issued token; its family lives as long as its
+	// newest token
+	Save(ctx context.Context, tokenHash string, token RefreshToken) error
+	// Get returns a token with the revocation state of its family
+	Get(ctx context.Context, tokenHash string) (RefreshToken, bool, error)
+	// Use marks a token as used and returns it as it was before, so of two
+	// concurrent rotations only one sees it unused
+	Use(ctx context.Context, tokenHash string) (RefreshToken, bool, error)
+	// RevokeFamily revokes every token of a family
+	RevokeFamily(ctx context.Context, familyID string) error
+	// UserFamilies returns the unexpired families issued to a user
+	UserFamilies(ctx context.Context, userID string) ([]string, error)
+	// Cleanup removes expired tokens and families and returns how many
+	// tokens were removed
+	Cleanup(ctx context.Context) (int, error)
+}
+
+// NewRefreshTokenStore creates a refresh token store for the configured
+// backend, the same one as the token blacklist
+func NewRefreshTokenStore(backend, redisURL, databaseURL string) (RefreshTokenStore, error) {
+	switch backend {
+	case "", "memory":
+		return NewMemoryRefreshTokenStore(), nil
+	case "redis":
+		return NewRedisRefreshTokenStore(redisURL)
+	case "postgres":
+		return NewPostgresRefreshTokenStore(databaseURL)
+	default:
+		return nil, fmt.Errorf("unknown refresh token backend: %s", backend)
+	}
+}
+
+// refreshFamily is a token family as the memory store keeps it
+type refreshFamily struct {
+	userID    string
+	revoked   bool
+	expiresAt time.Time
+}
+
+// MemoryRefreshTokenStore keeps refresh tokens in process memory
+type MemoryRefreshTokenStore struct {
+	tokens   map[string]RefreshToken
+	families map[string]*refreshFamily
+	mu       sync.Mutex
+}
+
+// NewMemoryRefreshTokenStore creates an in-memory refresh token store
+func NewMemoryRefreshTokenStore() *MemoryRefreshTokenStore {
+	return &MemoryRefreshTokenStore{
+		tokens:   make(map[string]RefreshToken),
+		families: make(map[string]*refreshFamily),
+	}
+}
+
+// Save stores a newly issued token
+func (s *MemoryRefreshTokenStore) Save(ctx context.Context, tokenHash string, token RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	family, exists := s.families[token.FamilyID]
+	if !exists {
+		family = &refreshFamily{userID: token.User.UserID}
+		s.families[token.FamilyID] = family
+	}
+	if token.ExpiresAt.After(family.expiresAt) {
+		family.expiresAt = token.ExpiresAt
+	}
+	s.tokens[tokenHash] = token
+	return nil
+}
+
+// Get returns a token with the revocation state of its family
+func (s *MemoryRefreshTokenStore) Get(ctx context.Context, tokenHash string) (RefreshToken, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(tokenHash)
+}
+
+// get looks a token up. Callers must hold s.mu.
+func (s *MemoryRefreshTokenStore) get(tokenHash string) (RefreshToken, bool, error) {
+	token, exists := s.tokens[tokenHash]
+	if !exists {
+		return RefreshToken{}, false, nil
+	}
+	if family := s.families[token.FamilyID]; family != nil {
+		token.Revoked = family.revoked
+	}
+	return token, true, nil
+}
+
+// Use marks a token as used and returns it as it was before
+func (s *MemoryRefreshTokenStore) Use(ctx context.Context, tokenHash string) (RefreshToken, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, exists, err := s.get(tokenHash)
+	if exists {
+		stored := s.tokens[tokenHash]
+		stored.Used = true
+		s.tokens[tokenHash] = stored
+	}
+	return token, exists, err
+}
+
+// RevokeFamily revokes every token of a family
+func (s *MemoryRefreshTokenStore) RevokeFamily(ctx context.Context, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if family, exists := s.families[familyID]; exists {
+		family.revoked = true
+	}
+	return nil
+}
+
+// UserFamilies returns the unexpired families issued to a user
+func (s *MemoryRefreshTokenStore) UserFamilies(ctx context.Context, userID string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var families []string
+	for familyID, family := range s.families {
+		if family.userID == userID && now.Before(family.expiresAt) {
+			families = append(families, familyID)
+		}
+	}
+	return families, nil
+}
+
+// Cleanup drops expired tokens and families whose tokens have all expired
+func (s *MemoryRefreshTokenStore) Cleanup(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	now := time.Now()
+	for hash, token := range s.tokens {
+		if now.After(token.ExpiresAt) {
+			delete(s.tokens, hash)
+			removed++
+		}
+	}
+	for familyID, family := range s.families {
+		if now.After(family.expiresAt) {
+			delete(s.families, familyID)
+		}
+	}
+	return removed, nil
+}
+
+// RedisRefreshTokenStore keeps refresh tokens in Redis. Tokens, their used
+// markers, families, revocations and each user's family set expire with
+// the newest token they cover.
+type RedisRefreshTokenStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisRefreshTokenStore connects to Redis using a redis:// URL
+func NewRedisRefreshTokenStore(redisURL string) (*RedisRefreshTokenStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &RedisRefreshTokenStore{client: client, prefix: "internal-api:refresh:"}, nil
+}
+
+// Save stores a newly issued token
+func (s *RedisRefreshTokenStore) Save(ctx context.Context, tokenHash string, token RefreshToken) error {
+	ttl := time.Until(token.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	userKey := s.prefix + "user:" + token.User.UserID
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.prefix+"token:"+tokenHash, data, ttl)
+		pipe.Set(ctx, s.prefix+"family:"+token.FamilyID, token.User.UserID, ttl)
+		pipe.SAdd(ctx, userKey, token.FamilyID)
+		pipe.Expire(ctx, userKey, ttl)
+		return nil
+	})
+	return err
+}
+
+// Get returns a token with the revocation state of its family
+func (s *RedisRefreshTokenStore) Get(ctx context.Context, tokenHash string) (RefreshToken, bool, error) {
+	data, err := s.client.Get(ctx, s.prefix+"token:"+tokenHash).Bytes()
+	if err == redis.Nil {
+		return RefreshToken{}, false, nil
+	}
+	if err != nil {
+		return RefreshToken{}, false, err
+	}
+
+	var token RefreshToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return RefreshToken{}, false, err
+	}
+	revoked, err := s.client.Exists(ctx, s.prefix+"revoked:"+token.FamilyID).Result()
+	if err != nil {
+		return RefreshToken{}, false, err
+	}
+	used, err := s.client.Exists(ctx, s.prefix+"used:"+tokenHash).Result()
+	if err != nil {
+		return RefreshToken{}, false, err
+	}
+	token.Revoked, token.Used = revoked > 0, used > 0
+	return token, true, nil
+}
+
+// Use marks a token as used with SETNX, so only one rotation wins
+func (s *RedisRefreshTokenStore) Use(ctx context.Context, tokenHash string) (RefreshToken, bool, error) {
+	token, exists, err := s.Get(ctx, tokenHash)
+	if err != nil || !exists {
+		return token, exists, err
+	}
+
+	first, err := s.client.SetNX(ctx, s.prefix+"used:"+tokenHash, 1, time.Until(token.ExpiresAt)).Result()
+	if err != nil {
+		return RefreshToken{}, false, err
+	}
+	token.Used = !first
+	return token, true, nil
+}
+
+// RevokeFamily revokes every token of a family until its newest token
+// expires
+func (s *RedisRefreshTokenStore) RevokeFamily(ctx context.Context, familyID string) error {
+	ttl, err := s.client.PTTL(ctx, s.prefix+"family:"+familyID).Result()
+	if err != nil || ttl <= 0 {
+		return err // Expired or unknown; no token of it is left
+	}
+	return s.client.Set(ctx, s.prefix+"revoked:"+familyID, 1, ttl).Err()
+}
+
+// UserFamilies returns the families issued to a user
+func (s *RedisRefreshTokenStore) UserFamilies(ctx context.Context, userID string) ([]string, error) {
+	return s.client.SMembers(ctx, s.prefix+"user:"+userID).Result()
+}
+
+// Cleanup is a no-op because Redis expires entries itself
+func (s *RedisRefreshTokenStore) Cleanup(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// PostgresRefreshTokenStore keeps refresh tokens and families in Postgres
+type PostgresRefreshTokenStore struct {
+	db *sql.DB
+}
+
+// NewPostgresRefreshTokenStore connects to Postgres and ensures the refresh
+// token tables exist
+func NewPostgresRefreshTokenStore(databaseURL string) (*PostgresRefreshTokenStore, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS refresh_families (
+		family_id  TEXT PRIMARY KEY,
+		user_id    TEXT NOT NULL,
+		revoked    BOOLEAN NOT NULL DEFAULT FALSE,
+		expires_at TIMESTAMPTZ NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS refresh_families_user_id ON refresh_families (user_id);
+	CREATE TABLE IF NOT EXISTS refresh_tokens (
+		token_hash TEXT PRIMARY KEY,
+		family_id  TEXT NOT NULL,
+		user_info  JSONB NOT NULL,
+		used       BOOLEAN NOT NULL DEFAULT FALSE,
+		expires_at TIMESTAMPTZ NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create refresh token tables: %w", err)
+	}
+
+	return &PostgresRefreshTokenStore{db: db}, nil
+}
+
+// Save stores a newly issued token and extends its family
+func (s *PostgresRefreshTokenStore) Save(ctx context.Context, tokenHash string, token RefreshToken) error {
+	user, err := json.Marshal(token.User)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO refresh_families (family_id, user_id, expires_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (family_id) DO UPDATE SET expires_at = GREATEST(refresh_families.expires_at, EXCLUDED.expires_at)`,
+		token.FamilyID, token.User.UserID, token.ExpiresAt); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO refresh_tokens (token_hash, family_id, user_info, expires_at) VALUES ($1, $2, $3, $4)`,
+		tokenHash, token.FamilyID, user, token.ExpiresAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Get returns a token with the revocation state of its family
+func (s *PostgresRefreshTokenStore) Get(ctx context.Context, tokenHash string) (RefreshToken, bool, error) {
+	return s.scan(s.db.QueryRowContext(ctx,
+		`SELECT t.family_id, t.user_info, t.expires_at, t.used, COALESCE(f.revoked, FALSE)
+		 FROM refresh_tokens t LEFT JOIN refresh_families f ON f.family_id = t.family_id
+		 WHERE t.token_hash = $1`,
+		tokenHash))
+}
+
+// Use marks a token as used in one statement and returns it as it was
+// before; the row lock makes concurrent rotations take turns
+func (s *PostgresRefreshTokenStore) Use(ctx context.Context, tokenHash string) (RefreshToken, bool, error) {
+	return s.scan(s.db.QueryRowContext(ctx,
+		`UPDATE refresh_tokens t SET used = TRUE
+		 FROM (SELECT token_hash, used FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE) previous
+		 WHERE t.token_hash = previous.token_hash
+		 RETURNING t.family_id, t.user_info, t.expires_at, previous.used,
+		 	COALESCE((SELECT revoked FROM refresh_families f WHERE f.family_id = t.family_id), FALSE)`,
+		tokenHash))
+}
+
+// scan reads a token row
+func (s *PostgresRefreshTokenStore) scan(row *sql.Row) (RefreshToken, bool, error) {
+	var (
+		token RefreshToken
+		user  []byte
+	)
+	err := row.Scan(&token.FamilyID, &user, &token.ExpiresAt, &token.Used, &token.Revoked)
+	if err == sql.ErrNoRows {
+		return RefreshToken{}, false, nil
+	}
+	if err != nil {
+		return RefreshToken{}, false, err
+	}
+	if err := json.Unmarshal(user, &token.User); err != nil {
+		return RefreshToken{}, false, err
+	}
+	return token, true, nil
+}
+
+// RevokeFamily revokes every token of a family
+func (s *PostgresRefreshTokenStore) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE refresh_families SET revoked = TRUE WHERE family_id = $1`, familyID)
+	return err
+}
+
+// UserFamilies returns the unexpired families issued to a user
+func (s *PostgresRefreshTokenStore) UserFamilies(ctx context.Context, userID string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT family_id FROM refresh_families WHERE user_id = $1 AND expires_at > NOW()`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var families []string
+	for rows.Next() {
+		var familyID string
+		if err := rows.Scan(&familyID); err != nil {
+			return nil, err
+		}
+		families = append(families, familyID)
+	}
+	return families, rows.Err()
+}
+
+// Cleanup deletes expired tokens and families
+func (s *PostgresRefreshTokenStore) Cleanup(ctx context.Context) (int, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM refresh_families WHERE expires_at < NOW()`); err != nil {
+		return 0, err
+	}
+	removed, err := result.RowsAffected()
+	return int(removed), err
+}
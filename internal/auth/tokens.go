@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/middleware"
+	"InternalAPI/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown or revoked refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenExpired is returned when a refresh token has passed its expiry
+	ErrRefreshTokenExpired = errors.New("refresh token has expired")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is
+	// presented again; the whole token family is revoked when this happens
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+	// ErrNotInitialized is returned when the token service has not been set up
+	ErrNotInitialized = errors.New("token service not initialized")
+)
+
+// storeTimeout bounds every refresh token store call
+const storeTimeout = 5 * time.Second
+
+// TokenService issues access tokens and rotating refresh tokens
+type TokenService struct {
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	store      RefreshTokenStore
+}
+
+// NewTokenService creates a token service backed by a refresh token store.
+// Access tokens are signed with the current JWT key, see middleware.SignJWT.
+func NewTokenService(store RefreshTokenStore, accessTTL, refreshTTL time.Duration) *TokenService {
+	return &TokenService{
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		store:      store,
+	}
+}
+
+// Issue creates a new access token and starts a new refresh token family
+func (ts *TokenService) Issue(user models.UserInfo) (*models.LoginResponse, error) {
+	return ts.issue(user, uuid.New().String())
+}
+
+// Rotate exchanges a refresh token for a new token pair. The presented token
+// is invalidated; presenting it again revokes the whole family.
+func (ts *TokenService) Rotate(presented string) (*models.LoginResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	record, exists, err := ts.store.Use(ctx, hashRefreshToken(presented))
+	if err != nil {
+		return nil, err
+	}
+	if !exists || record.Revoked {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if record.Used {
+		if err := ts.store.RevokeFamily(ctx, record.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(record.ExpiresAt) {
+		return nil, ErrRefreshTokenExpired
+	}
+
+	return ts.issue(record.User, record.FamilyID)
+}
+
+// RevokeFamily revokes the family of a refresh token, ending the session it
+// belongs to. Unknown tokens are ignored.
+func (ts *TokenService) RevokeFamily(presented string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	record, exists, err := ts.store.Get(ctx, hashRefreshToken(presented))
+	if err != nil || !exists {
+		return err
+	}
+	return ts.store.RevokeFamily(ctx, record.FamilyID)
+}
+
+// RevokeFamilyID revokes a refresh token family by its ID, as carried in
+// the fid claim of its access tokens
+func (ts *TokenService) RevokeFamilyID(familyID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	return ts.store.RevokeFamily(ctx, familyID)
+}
+
+// RevokeUser revokes every refresh token issued to a user and returns how many families were revoked
+func (ts *TokenService) RevokeUser(userID string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	families, err := ts.store.UserFamilies(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	for i, familyID := range families {
+		if err := ts.store.RevokeFamily(ctx, familyID); err != nil {
+			return i, err
+		}
+	}
+	return len(families), nil
+}
+
+// issue signs an access token and stores a new refresh token in the family
+func (ts *TokenService) issue(user models.UserInfo, familyID string) (*models.LoginResponse, error) {
+	now := time.Now()
+	accessExpiry := now.Add(ts.accessTTL)
+
+	claims := &middleware.Claims{
+		UserID:   user.UserID,
+		Username: user.Username,
+		Email:    user.Email,
+		Roles:    user.Roles,
+		FamilyID: familyID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   user.UserID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(accessExpiry),
+		},
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	refresh := base64.RawURLEncoding.EncodeToString(raw)
+
+	// Store the refresh token before handing out the pair, so a store
+	// failure issues nothing
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	user.Exp = accessExpiry.Unix()
+	if err := ts.store.Save(ctx, hashRefreshToken(refresh), RefreshToken{
+		FamilyID:  familyID,
+		User:      user,
+		ExpiresAt: now.Add(ts.refreshTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := middleware.SignJWT(claims)
+	if err != nil {
+		return nil, err
+	}
+	middleware.TrackSession(claims, accessToken)
+
+	return &models.LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refresh,
+		ExpiresIn:    int(ts.accessTTL.Seconds()),
+		TokenType:    "Bearer",
+	}, nil
+}
+
+// cleanup periodically drops expired refresh tokens and families
+func (ts *TokenService) cleanup() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		if _, err := ts.store.Cleanup(ctx); err != nil {
+			logging.Logger().WithError(err).Warn("Failed to clean up refresh tokens")
+		}
+		cancel()
+	}
+}
+
+// hashRefreshToken returns the storage key for a refresh token
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Global token service
+var tokenService *TokenService
+
+// Init initializes the global token service
+func Init(store RefreshTokenStore, accessTTL, refreshTTL time.Duration) {
+	tokenService = NewTokenService(store, accessTTL, refreshTTL)
+	go tokenService.cleanup()
+}
+
+// IssueTokens issues a new token pair using the global token service
+func IssueTokens(user models.UserInfo) (*models.LoginResponse, error) {
+	if tokenService == nil {
+		return nil, ErrNotInitialized
+	}
+	return tokenService.Issue(user)
+}
+
+// RotateRefreshToken rotates a refresh token using the global token service
+func RotateRefreshToken(refreshToken string) (*models.LoginResponse, error) {
+	if tokenService == nil {
+		return nil, ErrNotInitialized
+	}
+	return tokenService.Rotate(refreshToken)
+}
+
+// RevokeRefreshFamily revokes the family of a refresh token using the global token service
+func RevokeRefreshFamily(refreshToken string) error {
+	if tokenService == nil {
+		return ErrNotInitialized
+	}
+	return tokenService.RevokeFamily(refreshToken)
+}
+
+// RevokeRefreshFamilyID revokes a refresh token family by ID using the global token service
+func RevokeRefreshFamilyID(familyID string) error {
+	if tokenService == nil {
+		return ErrNotInitialized
+	}
+	return tokenService.RevokeFamilyID(familyID)
+}
+
+// RevokeUserTokens revokes all refresh tokens for a user using the global token service
+func RevokeUserTokens(userID string) (int, error) {
+	if tokenService == nil {
+		return 0, nil
+	}
+	return tokenService.RevokeUser(userID)
+}
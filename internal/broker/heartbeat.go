@@ -0,0 +1,143 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// HeartbeatSettings control how the registration is kept alive
+type HeartbeatSettings struct {
+	Interval   time.Duration // Time between re-registrations while registered; 0 registers once
+	MaxBackoff time.Duration // Longest wait between failed attempts
+	Deregister bool          // Remove the registration on shutdown
+}
+
+// minBackoff is the wait after the first failed attempt; it doubles up
+// to HeartbeatSettings.MaxBackoff
+const minBackoff = time.Second
+
+var registeredGauge = metrics.Factory.NewGauge(prometheus.GaugeOpts{
+	Namespace: metrics.Namespace,
+	Name:      "broker_registered",
+	Help:      "1 while the latest broker registration succeeded, 0 otherwise",
+})
+
+var (
+	loopMu        sync.Mutex
+	stopHeartbeat context.CancelFunc
+	heartbeatDone chan struct{}
+	deregister    bool
+	slug          string // Slug of the running registration
+)
+
+// heartbeat registers with the broker and keeps registering again every
+// interval, so a broker that restarted learns the route again. Failed
+// attempts are retried with exponential backoff until ctx is cancelled.
+func heartbeat(ctx context.Context, brokerURL, authToken string, registration PluginRegistration, settings HeartbeatSettings) {
+	// Wait a moment for InternalAPI to be fully ready
+	wait := 2 * time.Second
+	backoff := minBackoff
+	registered := false
+	for {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return
+		}
+
+		err := attemptRegistration(ctx, brokerURL, authToken, registration)
+		if ctx.Err() != nil {
+			return
+		}
+		recordAttempt(err)
+		if err == nil {
+			if !registered {
+				log.WithFields(logrus.Fields{
+					"broker_url":  brokerURL,
+					"plugin_slug": registration.Slug,
+					"host":        registration.Host,
+				}).Info("✓ Successfully registered with broker")
+			}
+			registered = true
+			backoff = minBackoff
+			if settings.Interval <= 0 {
+				return
+			}
+			wait = settings.Interval
+			continue
+		}
+
+		wait = backoff/2 + rand.N(backoff/2+1)
+		backoff = min(backoff*2, settings.MaxBackoff)
+		entry := log.WithError(err).WithField("retry_in", wait.Round(time.Millisecond).String())
+		if registered {
+			entry.Error("Lost broker registration - no proxied traffic until registering again succeeds")
+		} else {
+			entry.Warn("Failed to register with broker - service will continue running but won't receive proxied traffic")
+		}
+		registered = false
+	}
+}
+
+// Stop ends the heartbeat and, unless disabled, removes the registration
+// so the broker stops routing to this instance
+func Stop(ctx context.Context) error {
+	loopMu.Lock()
+	cancel, done, remove, registered := stopHeartbeat, heartbeatDone, deregister, slug
+	stopHeartbeat, heartbeatDone = nil, nil
+	loopMu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if !remove || !Status().Registered {
+		return nil
+	}
+
+	brokerURL, authToken := brokerSettings()
+	err := deregisterRoute(ctx, brokerURL, authToken, registered)
+	if err == nil {
+		recordDeregistration()
+		log.WithField("broker_url", brokerURL).Info("Deregistered from broker")
+	}
+	return err
+}
+
+// deregisterRoute removes the route registered under slug
+func deregisterRoute(ctx context.Context, brokerURL, authToken, slug string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, brokerURL+"/api/v1/route/"+url.PathEscape(slug), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create deregistration request: %w", err)
+	}
+	if authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+authToken)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send deregistration request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return fmt.Errorf("deregistration failed with status %d", resp.StatusCode)
+}
@@ -0,0 +1,56 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Lookup returns the address the plugin registered under slug gave the
+// broker, e.g. http://10.0.3.7:8080
+func Lookup(ctx context.Context, slug string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, BrokerURL()+"/api/v1/route/"+url.PathEscape(slug), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create lookup request: %w", err)
+	}
+	if authToken := os.Getenv("BROKER_AUTH_TOKEN"); authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+authToken)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send lookup request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", fmt.Errorf("%s is not registered with the broker", slug)
+	default:
+		return "", fmt.Errorf("lookup of %s failed with status %d", slug, resp.StatusCode)
+	}
+
+	var route struct {
+		Host    string `json:"host"`
+		Enabled *bool  `json:"enabled"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&route); err != nil {
+		return "", fmt.Errorf("failed to decode lookup response: %w", err)
+	}
+	if route.Enabled != nil && !*route.Enabled {
+		return "", fmt.Errorf("%s is disabled in the broker", slug)
+	}
+	address, err := url.Parse(route.Host)
+	if err != nil || (address.Scheme != "http" && address.Scheme != "https") || address.Host == "" {
+		return "", fmt.Errorf("broker has no valid address for %s, got %q", slug, route.Host)
+	}
+	return strings.TrimSuffix(route.Host, "/"), nil
+}
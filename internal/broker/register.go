@@ -2,34 +2,144 @@ package broker
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"sort"
+	"sync"
 	"time"
 
-	"github.com/sirupsen/logrus"
+	"InternalAPI/internal/logging"
 )
 
-var log = logrus.New()
+var log = logging.Logger()
+
+// RegistrationStatus is the outcome of the latest broker registration
+type RegistrationStatus struct {
+	Registered          bool       `json:"registered"`
+	LastAttempt         *time.Time `json:"last_attempt,omitempty"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures,omitempty"`
+}
+
+var (
+	statusMu sync.RWMutex
+	status   RegistrationStatus
+
+	routesMu sync.RWMutex
+	routes   []Endpoint
+)
+
+// Endpoint is a route the gateway serves
+type Endpoint struct {
+	Method string `json:"method"`
+	Path   string `json:"path"` // Gin pattern, e.g. /api/v1/rooms/:id
+}
 
 // PluginRegistration represents the registration payload sent to the broker
 type PluginRegistration struct {
-	Description   string   `json:"description"`
-	Version       string   `json:"version"`
-	Slug          string   `json:"slug"`
-	Name          string   `json:"name"`
-	Category      string   `json:"category,omitempty"`
-	Host          string   `json:"host"`
-	BaseAPIRoute  string   `json:"base-api-route"`
-	SettingsRoute string   `json:"settings-route,omitempty"`
-	APIRoutes     []string `json:"api-routes,omitempty"`
-	Enabled       bool     `json:"enabled"`
+	Description   string     `json:"description"`
+	Version       string     `json:"version"`
+	Slug          string     `json:"slug"`
+	Name          string     `json:"name"`
+	Category      string     `json:"category,omitempty"`
+	Host          string     `json:"host"`
+	BaseAPIRoute  string     `json:"base-api-route"`
+	SettingsRoute string     `json:"settings-route,omitempty"`
+	APIRoutes     []string   `json:"api-routes,omitempty"`
+	APIEndpoints  []Endpoint `json:"api-endpoints,omitempty"` // The methods served on each of APIRoutes
+	Enabled       bool       `json:"enabled"`
+}
+
+// SetRoutes sets the routes registration advertises. It is called once the
+// router is set up, so the broker learns exactly the routes served.
+func SetRoutes(endpoints []Endpoint) {
+	sorted := append([]Endpoint(nil), endpoints...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].Method < sorted[j].Method
+	})
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	routes = sorted
 }
 
-// RegisterWithBroker registers InternalAPI with the broker on startup
+// RegisterWithBroker registers InternalAPI with the broker on startup and
+// keeps the registration alive, see HeartbeatSettings, until Stop is called.
 // This is non-blocking and won't fail the application if broker is unavailable
-func RegisterWithBroker(host, port string) {
+func RegisterWithBroker(host, port string, settings HeartbeatSettings) {
+	brokerURL, brokerAuthToken := brokerSettings()
+	registration := newRegistration(host, port)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	loopMu.Lock()
+	stopHeartbeat, heartbeatDone, deregister, slug = cancel, done, settings.Deregister, registration.Slug
+	loopMu.Unlock()
+
+	// Run registration in background to not block startup
+	go func() {
+		defer close(done)
+		heartbeat(ctx, brokerURL, brokerAuthToken, registration, settings)
+	}()
+}
+
+// Reregister registers with the broker again and waits for the result. It
+// returns the broker URL that was used.
+func Reregister(host, port string) (string, error) {
+	brokerURL, brokerAuthToken := brokerSettings()
+	err := attemptRegistration(context.Background(), brokerURL, brokerAuthToken, newRegistration(host, port))
+	recordAttempt(err)
+	return brokerURL, err
+}
+
+// Status returns the outcome of the latest registration. Registered is
+// false until the first registration succeeds, and after one fails.
+func Status() RegistrationStatus {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	return status
+}
+
+// recordAttempt stores the outcome of a registration for Status
+func recordAttempt(err error) {
+	now := time.Now()
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	status.Registered, status.LastAttempt = err == nil, &now
+	if err != nil {
+		status.LastError = err.Error()
+		status.ConsecutiveFailures++
+		registeredGauge.Set(0)
+		return
+	}
+	status.LastSuccess, status.LastError, status.ConsecutiveFailures = &now, "", 0
+	registeredGauge.Set(1)
+}
+
+// recordDeregistration marks the instance as no longer registered
+func recordDeregistration() {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	status.Registered = false
+	registeredGauge.Set(0)
+}
+
+// BrokerURL returns the broker URL registration will use
+func BrokerURL() string {
+	if brokerURL := os.Getenv("BROKER_URL"); brokerURL != "" {
+		return brokerURL
+	}
+	return "http://localhost:8081"
+}
+
+// brokerSettings reads the broker URL and auth token from the environment
+func brokerSettings() (string, string) {
 	brokerURL := os.Getenv("BROKER_URL")
 	if brokerURL == "" {
 		brokerURL = "http://localhost:8081" // Default broker URL
@@ -42,10 +152,25 @@ func RegisterWithBroker(host, port string) {
 		// Don't return - attempt registration anyway in case broker allows unauthenticated registration
 	}
 
+	return brokerURL, brokerAuthToken
+}
+
+// newRegistration builds the registration payload for this instance
+func newRegistration(host, port string) PluginRegistration {
 	// Construct the full host URL
 	serviceHost := fmt.Sprintf("http://%s:%s", host, port)
 
-	registration := PluginRegistration{
+	routesMu.RLock()
+	endpoints := routes
+	routesMu.RUnlock()
+	var paths []string
+	for i, endpoint := range endpoints {
+		if i == 0 || endpoint.Path != endpoints[i-1].Path {
+			paths = append(paths, endpoint.Path)
+		}
+	}
+
+	return PluginRegistration{
 		Description:   "Hotel Internal API - Gateway for user portal and admin services",
 		Version:       "2.0.0",
 		Slug:          "internal-api",
@@ -54,45 +179,20 @@ func RegisterWithBroker(host, port string) {
 		Host:          serviceHost,
 		BaseAPIRoute:  "/api/v1",
 		SettingsRoute: "/admin/system/stats",
-		APIRoutes: []string{
-			"/api/v1/albums",
-			"/api/v1/guests",
-			"/api/v1/reservations",
-			"/api/auth/login",
-			"/api/auth/logout",
-			"/api/auth/refresh",
-			"/admin/users",
-			"/admin/roles",
-			"/health",
-		},
-		Enabled: true,
+		APIRoutes:     paths,
+		APIEndpoints:  endpoints,
+		Enabled:       true,
 	}
-
-	// Run registration in background to not block startup
-	go func() {
-		// Wait a moment for InternalAPI to be fully ready
-		time.Sleep(2 * time.Second)
-
-		if err := attemptRegistration(brokerURL, brokerAuthToken, registration); err != nil {
-			log.WithError(err).Error("Failed to register with broker - service will continue running but won't receive proxied traffic")
-		} else {
-			log.WithFields(logrus.Fields{
-				"broker_url":  brokerURL,
-				"plugin_slug": registration.Slug,
-				"host":        registration.Host,
-			}).Info("✓ Successfully registered with broker")
-		}
-	}()
 }
 
 // attemptRegistration performs the actual HTTP request to register with the broker
-func attemptRegistration(brokerURL, authToken string, registration PluginRegistration) error {
+func attemptRegistration(ctx context.Context, brokerURL, authToken string, registration PluginRegistration) error {
 	payload, err := json.Marshal(registration)
 	if err != nil {
 		return fmt.Errorf("failed to marshal registration payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", brokerURL+"/api/v1/route", bytes.NewBuffer(payload))
+	req, err := http.NewRequestWithContext(ctx, "POST", brokerURL+"/api/v1/route", bytes.NewBuffer(payload))
 	if err != nil {
 		return fmt.Errorf("failed to create registration request: %w", err)
 	}
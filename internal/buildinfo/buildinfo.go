@@ -0,0 +1,63 @@
+// Package buildinfo describes the running binary. Version, Commit and Date
+// are injected at compile time:
+//
+//	go build -ldflags "-X InternalAPI/internal/buildinfo.Version=1.3.0 \
+//	  -X InternalAPI/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X InternalAPI/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Without them, the VCS details the Go toolchain embeds are used.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set with -ldflags -X at compile time
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info identifies the binary and the toolchain that built it
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"build_date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // Built from a tree with uncommitted changes
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get returns the build information. Commit and Date fall back to the VCS
+// revision and commit time embedded by the Go toolchain.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// item is a cached value with its expiry
+type item struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// Cache is a concurrency-safe in-memory cache with per-entry TTLs
+type Cache struct {
+	items map[string]item
+	mu    sync.RWMutex
+}
+
+// New creates an empty cache and starts its expiry sweeper
+func New() *Cache {
+	c := &Cache{
+		items: make(map[string]item),
+	}
+
+	go c.cleanup()
+
+	return c
+}
+
+// Get returns a cached value if present and not expired
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	it, exists := c.items[key]
+	if !exists || time.Now().After(it.expiresAt) {
+		return nil, false
+	}
+	return it.value, true
+}
+
+// Set stores a value for the given TTL
+func (c *Cache) Set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[key] = item{
+		value:     value,
+		expiresAt: time.Now().Add(ttl),
+	}
+}
+
+// Delete removes a value
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+// DeletePrefix removes every value whose key starts with prefix and returns
+// how many were removed; an empty prefix clears the cache
+func (c *Cache) DeletePrefix(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.items, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Len returns the number of entries, including expired ones not yet swept
+func (c *Cache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// cleanup periodically removes expired entries
+func (c *Cache) cleanup() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		c.mu.Lock()
+		now := time.Now()
+		for key, it := range c.items {
+			if now.After(it.expiresAt) {
+				delete(c.items, key)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
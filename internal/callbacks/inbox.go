@@ -0,0 +1,185 @@
+package callbacks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Inbox record statuses
+const (
+	StatusPending   = "pending"
+	StatusProcessed = "processed"
+	StatusFailed    = "failed"
+)
+
+// Record is an upstream callback persisted before it is processed
+type Record struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	ReceivedAt  time.Time       `json:"received_at"`
+	ProcessedAt *time.Time      `json:"processed_at,omitempty"`
+}
+
+// Inbox durably stores callbacks as one JSON file per event ID. The files
+// double as the idempotency record: an ID that already exists is a duplicate.
+type Inbox struct {
+	dir     string
+	records map[string]*Record
+	mu      sync.Mutex
+}
+
+// NewInbox opens (or creates) an inbox directory and loads its records
+func NewInbox(dir string) (*Inbox, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create inbox directory: %w", err)
+	}
+
+	inbox := &Inbox{dir: dir, records: make(map[string]*Record)}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read inbox record %s: %w", file, err)
+		}
+		var rec Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse inbox record %s: %w", file, err)
+		}
+		inbox.records[rec.ID] = &rec
+	}
+
+	return inbox, nil
+}
+
+// Accept persists a new callback. It returns false without error when the
+// event ID has been seen before.
+func (in *Inbox) Accept(id, eventType string, payload []byte) (bool, error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if _, exists := in.records[id]; exists {
+		return false, nil
+	}
+
+	now := time.Now()
+	rec := &Record{
+		ID:          id,
+		Type:        eventType,
+		Payload:     json.RawMessage(payload),
+		Status:      StatusPending,
+		NextAttempt: now,
+		ReceivedAt:  now,
+	}
+	if err := in.write(rec); err != nil {
+		return false, err
+	}
+
+	in.records[id] = rec
+	return true, nil
+}
+
+// Due returns copies of pending records whose next attempt has arrived
+func (in *Inbox) Due(now time.Time) []Record {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	var due []Record
+	for _, rec := range in.records {
+		if rec.Status == StatusPending && !rec.NextAttempt.After(now) {
+			due = append(due, *rec)
+		}
+	}
+	return due
+}
+
+// Update persists a changed record
+func (in *Inbox) Update(rec Record) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if err := in.write(&rec); err != nil {
+		return err
+	}
+	in.records[rec.ID] = &rec
+	return nil
+}
+
+// Counts returns the number of records per status
+func (in *Inbox) Counts() map[string]int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	counts := map[string]int{StatusPending: 0, StatusProcessed: 0, StatusFailed: 0}
+	for _, rec := range in.records {
+		counts[rec.Status]++
+	}
+	return counts
+}
+
+// Prune removes processed records received before cutoff. Pending and failed
+// records are kept until they are handled.
+func (in *Inbox) Prune(cutoff time.Time) int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	removed := 0
+	for id, rec := range in.records {
+		if rec.Status == StatusProcessed && rec.ReceivedAt.Before(cutoff) {
+			if err := os.Remove(in.path(id)); err != nil && !os.IsNotExist(err) {
+				continue
+			}
+			delete(in.records, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// write stores a record atomically so a crash never leaves a torn file
+func (in *Inbox) write(rec *Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(in.dir, ".record-*")
+	if err != nil {
+		return fmt.Errorf("failed to buffer callback: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to buffer callback: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to buffer callback: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to buffer callback: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), in.path(rec.ID))
+}
+
+// path maps an event ID to a file name that is safe whatever the ID contains
+func (in *Inbox) path(id string) string {
+	hash := sha256.Sum256([]byte(id))
+	return filepath.Join(in.dir, hex.EncodeToString(hash[:])+".json")
+}
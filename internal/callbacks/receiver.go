@@ -0,0 +1,251 @@
+package callbacks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/events"
+	"InternalAPI/internal/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Signature headers sent by API Beheerder
+const (
+	SignatureHeader = "X-Beheerder-Signature"
+	TimestampHeader = "X-Beheerder-Timestamp"
+)
+
+const (
+	maxAttempts  = 8
+	pollInterval = 15 * time.Second
+)
+
+var (
+	// ErrInvalidSignature is returned when a callback is unsigned, stale or forged
+	ErrInvalidSignature = errors.New("invalid callback signature")
+	// ErrInvalidPayload is returned when a callback body is not a valid event envelope
+	ErrInvalidPayload = errors.New("invalid callback payload")
+)
+
+var log = logging.Logger()
+
+// upstreamTypes translates API Beheerder event types to internal event types
+var upstreamTypes = map[string]string{
+	"booking.created":     "reservation.created",
+	"booking.updated":     "reservation.updated",
+	"booking.cancelled":   "reservation.cancelled",
+	"booking.checked_out": "reservation.checked_out",
+	"room.updated":        "room.updated",
+}
+
+// envelope is the API Beheerder callback body
+type envelope struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// Receiver verifies, buffers and processes API Beheerder callbacks
+type Receiver struct {
+	secret    []byte
+	tolerance time.Duration
+	retention time.Duration
+	inbox     *Inbox
+	wake      chan struct{}
+}
+
+// NewReceiver creates a receiver that signs with secret and rejects
+// timestamps older than tolerance. Processed IDs are remembered for retention.
+func NewReceiver(secret string, tolerance, retention time.Duration, inbox *Inbox) *Receiver {
+	return &Receiver{
+		secret:    []byte(secret),
+		tolerance: tolerance,
+		retention: retention,
+		inbox:     inbox,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Verify checks the HMAC-SHA256 signature over "<timestamp>.<body>"
+func (r *Receiver) Verify(timestamp, signature string, body []byte) error {
+	if timestamp == "" || signature == "" {
+		return ErrInvalidSignature
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	age := time.Since(time.Unix(ts, 0))
+	if age > r.tolerance || age < -r.tolerance {
+		return ErrInvalidSignature
+	}
+
+	presented, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, r.secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	if !hmac.Equal(presented, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Receive buffers a verified callback. It returns the event ID and whether
+// it was new; duplicates are acknowledged without being processed again.
+func (r *Receiver) Receive(body []byte) (string, bool, error) {
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil || env.ID == "" || env.Type == "" {
+		return "", false, ErrInvalidPayload
+	}
+
+	accepted, err := r.inbox.Accept(env.ID, env.Type, body)
+	if err != nil {
+		return env.ID, false, err
+	}
+
+	if accepted {
+		select {
+		case r.wake <- struct{}{}:
+		default:
+		}
+	}
+	return env.ID, accepted, nil
+}
+
+// Stats returns the number of buffered callbacks per status
+func (r *Receiver) Stats() map[string]int {
+	return r.inbox.Counts()
+}
+
+// Start processes buffered callbacks in the background, including any left
+// pending by a previous run
+func (r *Receiver) Start() {
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			r.processDue()
+			if r.retention > 0 {
+				r.inbox.Prune(time.Now().Add(-r.retention))
+			}
+
+			select {
+			case <-r.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// processDue publishes every pending callback whose next attempt has arrived
+func (r *Receiver) processDue() {
+	for _, rec := range r.inbox.Due(time.Now()) {
+		err := r.publish(rec)
+
+		rec.Attempts++
+		if err == nil {
+			now := time.Now()
+			rec.Status = StatusProcessed
+			rec.ProcessedAt = &now
+			rec.LastError = ""
+		} else {
+			rec.LastError = err.Error()
+			if rec.Attempts >= maxAttempts {
+				rec.Status = StatusFailed
+			} else {
+				// Exponential backoff: 2s, 4s, 8s, ...
+				rec.NextAttempt = time.Now().Add(time.Duration(1<<rec.Attempts) * time.Second)
+			}
+		}
+
+		if updateErr := r.inbox.Update(rec); updateErr != nil {
+			log.WithError(updateErr).WithField("event_id", rec.ID).Error("Failed to persist callback status")
+		}
+
+		log.WithFields(logrus.Fields{
+			"event_id":   rec.ID,
+			"event_type": rec.Type,
+			"status":     rec.Status,
+			"attempts":   rec.Attempts,
+			"error":      rec.LastError,
+		}).Info("Processed upstream callback")
+	}
+}
+
+// publish translates a buffered callback onto the internal event bus
+func (r *Receiver) publish(rec Record) error {
+	var env envelope
+	if err := json.Unmarshal(rec.Payload, &env); err != nil {
+		return fmt.Errorf("corrupt buffered payload: %w", err)
+	}
+
+	eventType, known := upstreamTypes[env.Type]
+	if !known {
+		eventType = "beheerder." + env.Type
+	}
+
+	occurredAt := env.OccurredAt
+	if occurredAt.IsZero() {
+		occurredAt = rec.ReceivedAt
+	}
+
+	return events.Publish(events.Event{
+		ID:         env.ID,
+		Type:       eventType,
+		Source:     "api-beheerder",
+		OccurredAt: occurredAt,
+		Data:       env.Data,
+	})
+}
+
+var receiver *Receiver
+
+// Init opens the inbox and starts the global API Beheerder receiver
+func Init(secret string, tolerance, retention time.Duration, dir string) error {
+	inbox, err := NewInbox(dir)
+	if err != nil {
+		return err
+	}
+
+	receiver = NewReceiver(secret, tolerance, retention, inbox)
+	receiver.Start()
+	return nil
+}
+
+// Enabled reports whether the global receiver has been initialized
+func Enabled() bool {
+	return receiver != nil
+}
+
+// Verify checks a callback signature with the global receiver
+func Verify(timestamp, signature string, body []byte) error {
+	return receiver.Verify(timestamp, signature, body)
+}
+
+// Receive buffers a callback with the global receiver
+func Receive(body []byte) (string, bool, error) {
+	return receiver.Receive(body)
+}
+
+// Stats returns buffered callback counts from the global receiver
+func Stats() map[string]int {
+	if receiver == nil {
+		return map[string]int{}
+	}
+	return receiver.Stats()
+}
@@ -0,0 +1,159 @@
+package changelog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Change types
+const (
+	Added      = "added"      // A new route
+	Changed    = "changed"    // Fields of an existing route changed
+	Deprecated = "deprecated" // The route still works but will be removed
+	Removed    = "removed"    // The route no longer exists
+)
+
+// Change is one API change, flattened with its release so clients can diff
+// between gateway versions without walking releases
+type Change struct {
+	Version     string   `json:"version"`
+	Date        string   `json:"date"`
+	Type        string   `json:"type"`
+	Method      string   `json:"method"`
+	Route       string   `json:"route"` // Gin route, e.g. /api/v1/rooms/:id
+	Fields      []string `json:"fields,omitempty"`
+	Description string   `json:"description"`
+	Sunset      string   `json:"sunset,omitempty"`      // Removal date of a deprecated route
+	Replacement string   `json:"replacement,omitempty"` // Route to move to, as "METHOD /route"
+	Feature     string   `json:"feature,omitempty"`     // Setting the route depends on, if it is optional
+}
+
+// Release is a gateway version and the API changes it shipped
+type Release struct {
+	Version string
+	Date    string
+	Changes []Change
+}
+
+// Filter selects changes; zero values match everything
+type Filter struct {
+	Since string // Exclusive lower bound version
+	Until string // Inclusive upper bound version
+	Type  string
+	Route string // Route prefix
+}
+
+// Matches reports whether a change satisfies the filter
+func (f Filter) Matches(c Change) bool {
+	if f.Since != "" && Compare(c.Version, f.Since) <= 0 {
+		return false
+	}
+	if f.Until != "" && Compare(c.Version, f.Until) > 0 {
+		return false
+	}
+	if f.Type != "" && c.Type != f.Type {
+		return false
+	}
+	return f.Route == "" || strings.HasPrefix(c.Route, f.Route)
+}
+
+// Current returns the newest gateway version in the changelog
+func Current() string {
+	return releases[0].Version
+}
+
+// Changes returns the changes matching f, newest release first
+func Changes(f Filter) []Change {
+	changes := []Change{}
+	for _, release := range releases {
+		for _, change := range release.Changes {
+			change.Version, change.Date = release.Version, release.Date
+			if f.Matches(change) {
+				changes = append(changes, change)
+			}
+		}
+	}
+	return changes
+}
+
+// Deprecation returns the deprecation entry of a route, if it has one and
+// was not removed since
+func Deprecation(method, route string) (Change, bool) {
+	for _, release := range releases {
+		for _, change := range release.Changes {
+			if change.Method != method || change.Route != route {
+				continue
+			}
+			switch change.Type {
+			case Removed, Added:
+				return Change{}, false
+			case Deprecated:
+				change.Version, change.Date = release.Version, release.Date
+				return change, true
+			}
+		}
+	}
+	return Change{}, false
+}
+
+// Verify checks the changelog against the registered routes, given as
+// "METHOD /route", and returns every inconsistency: a route's newest entry
+// says it exists but it is not registered (unless it depends on a feature
+// setting), or says it was removed but it still is.
+func Verify(registered []string) []string {
+	exists := make(map[string]bool, len(registered))
+	for _, route := range registered {
+		exists[route] = true
+	}
+
+	var problems []string
+	seen := make(map[string]bool)
+	for _, release := range releases {
+		for _, change := range release.Changes {
+			key := change.Method + " " + change.Route
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if change.Type == Removed && exists[key] {
+				problems = append(problems, fmt.Sprintf("%s is still registered but was removed in %s", key, release.Version))
+			}
+			if change.Type != Removed && change.Feature == "" && !exists[key] {
+				problems = append(problems, fmt.Sprintf("%s is listed as %s in %s but is not registered", key, change.Type, release.Version))
+			}
+		}
+	}
+	return problems
+}
+
+// Compare orders dotted numeric versions such as 1.4.0, returning -1, 0 or 1
+func Compare(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// ValidVersion reports whether v is a dotted numeric version
+func ValidVersion(v string) bool {
+	for _, part := range strings.Split(v, ".") {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
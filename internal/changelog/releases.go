@@ -0,0 +1,87 @@
+package changelog
+
+// releases is the API changelog, newest release first. Add an entry for
+// every route that is added, removed or deprecated and for every change to
+// the fields of a request or response; Verify checks the entries against
+// the registered routes at startup.
+var releases = []Release{
+	{
+		Version: "1.3.0",
+		Date:    "2026-10-15",
+		Changes: []Change{
+			{Type: Added, Method: "GET", Route: "/api/v1/jobs/:id", Description: "Status and result of a proxied API Beheerder write queued with Prefer: respond-async"},
+			{Type: Added, Method: "POST", Route: "/admin/webhooks", Description: "Register outbound webhooks for booking, circuit breaker and other events", Feature: "OUTBOUND_WEBHOOKS_ENABLED"},
+			{Type: Added, Method: "GET", Route: "/admin/webhooks/dead-letters", Description: "Webhook deliveries that exhausted their retries", Feature: "OUTBOUND_WEBHOOKS_ENABLED"},
+			{Type: Added, Method: "POST", Route: "/admin/webhooks/dead-letters/:id/retry", Description: "Retry a dead-lettered webhook delivery", Feature: "OUTBOUND_WEBHOOKS_ENABLED"},
+			{Type: Added, Method: "GET", Route: "/admin/events", Description: "Server-sent audit entries, circuit breaker transitions and upstream health changes"},
+			{Type: Added, Method: "GET", Route: "/ws", Description: "WebSocket push of room status changes, new bookings and other bus events", Feature: "FEATURE_WEBSOCKETS"},
+			{Type: Added, Method: "POST", Route: "/api/v1/graphql", Description: "GraphQL queries over albums, bookings and users with batched user lookups", Feature: "GRAPHQL_ENABLED"},
+			{Type: Added, Method: "GET", Route: "/api/v1/changelog", Description: "Machine-readable list of API changes, filterable by version range, type and route"},
+			{Type: Added, Method: "GET", Route: "/openapi.json", Description: "OpenAPI 3 document generated from the registered routes", Feature: "DOCS_ENABLED"},
+			{Type: Added, Method: "GET", Route: "/docs", Description: "Swagger UI for the OpenAPI document", Feature: "DOCS_ENABLED"},
+			{Type: Changed, Method: "POST", Route: "/api/v1/albums", Fields: []string{"genre", "violations"}, Description: "Albums take an optional genre; writes breaking business rules fail with 422 and a violations list"},
+			{Type: Changed, Method: "PUT", Route: "/api/v1/albums/:id", Fields: []string{"genre", "violations"}, Description: "Albums take an optional genre; writes breaking business rules fail with 422 and a violations list"},
+			{Type: Changed, Method: "POST", Route: "/api/v1/bookings", Fields: []string{"violations"}, Description: "Bodies that do not match the API schema fail with 400 and a violations list with JSON pointers"},
+			{Type: Changed, Method: "POST", Route: "/api/v1/guests", Fields: []string{"violations"}, Description: "Bodies that do not match the API schema fail with 400 and a violations list with JSON pointers"},
+			{Type: Added, Method: "GET", Route: "/admin/security/blacklist", Description: "Token blacklist size, limits and last purge"},
+			{Type: Added, Method: "POST", Route: "/admin/security/blacklist/purge", Description: "Purge expired token revocations"},
+			{Type: Added, Method: "GET", Route: "/public/v1/availability", Description: "Anonymous availability search for the website widget", Feature: "PUBLIC_AVAILABILITY_ENABLED"},
+			{Type: Added, Method: "GET", Route: "/api/v1/proxy/stream/beheerder/*path", Description: "Allow-listed API Beheerder downloads streamed without buffering"},
+			{Type: Added, Method: "PUT", Route: "/admin/circuit-breakers/:service/config", Description: "Tune a circuit breaker at runtime"},
+			{Type: Added, Method: "GET", Route: "/admin/slo", Description: "Error budget and burn rates of each route group's availability and latency objectives"},
+			{Type: Added, Method: "GET", Route: "/debug/pprof/*profile", Description: "Admin-only pprof profiles for diagnosing a live instance", Feature: "DEBUG_ENDPOINTS_ENABLED"},
+			{Type: Added, Method: "GET", Route: "/debug/goroutines", Description: "Admin-only stack dump of every goroutine", Feature: "DEBUG_ENDPOINTS_ENABLED"},
+			{Type: Added, Method: "GET", Route: "/debug/build", Description: "Version, git commit and build date of the running binary", Feature: "DEBUG_ENDPOINTS_ENABLED"},
+			{Type: Changed, Method: "GET", Route: "/admin/audit-logs", Fields: []string{"since", "until", "user_id", "action", "resource_type", "resource_id", "data", "total_items"}, Description: "Audit logs filter by time range, user, action and resource, and are returned as a paginated list of normalized entries"},
+			{Type: Added, Method: "GET", Route: "/admin/audit-logs/verify", Description: "Verify the audit hash chain and its anchors in Central Management"},
+			{Type: Changed, Method: "GET", Route: "/admin/audit-logs/resource/:type/:id", Fields: []string{"coverage", "accesses.resource_ids"}, Description: "Access reports include list responses that returned the resource, can be read from a Redis or Postgres audit store shared by all replicas, and state what they do not cover"},
+			{Type: Added, Method: "GET", Route: "/admin/cors", Description: "CORS policy in effect, and whether an origin is allowed"},
+			{Type: Added, Method: "GET", Route: "/admin/config", Description: "Settings in effect with secrets masked, the reloadable ones and the latest reload"},
+			{Type: Added, Method: "POST", Route: "/admin/config/reload", Description: "Reload rate limits, circuit breakers, CORS and the log level without a restart"},
+			{Type: Added, Method: "GET", Route: "/admin/jwt-keys", Description: "JWT keys accepted for access tokens, by key id, and when retired keys stop being accepted"},
+			{Type: Added, Method: "POST", Route: "/admin/jwt-keys/rotate", Description: "Sign new access tokens with a new key while tokens signed with the old one stay valid for a grace period"},
+			{Type: Added, Method: "GET", Route: "/api/v1/proxy/:service/*path", Description: "Requests to a backend listed in PASSTHROUGH_SERVICES forwarded as they are, also with HEAD, POST, PUT, PATCH and DELETE", Feature: "PASSTHROUGH_SERVICES"},
+			{Type: Added, Method: "GET", Route: "/api/v1/dashboard", Description: "Portal start page with album, booking and room counts, the user's filters and backend status, marking sections a failing backend feeds as unavailable"},
+			{Type: Changed, Method: "GET", Route: "/api/v1/albums", Fields: []string{"page", "page_size", "cursor", "limit", "sort", "filter", "data", "total_items", "total_pages"}, Description: "Albums are paginated, sorted and filtered by the backend and returned as a paginated list"},
+			{Type: Changed, Method: "GET", Route: "/api/v1/albums", Fields: []string{"data", "count", "page", "page_size", "total_pages", "total_items", "next_cursor", "has_more"}, Description: "gRPC AlbumService.ListAlbums returns the paginated list: albums moved to data (field 1, renamed from albums), count was dropped, and paging, sort and filter are passed on"},
+			{Type: Changed, Method: "GET", Route: "/admin/users", Fields: []string{"sort", "filter", "data", "total_items", "total_pages"}, Description: "Users are sorted and filtered by the backend and returned as a paginated list"},
+			{Type: Changed, Method: "POST", Route: "/api/v1/auth/logout", Fields: []string{"refresh_token"}, Description: "Logout revokes only the refresh token family of the current session instead of every session of the user"},
+			{Type: Changed, Method: "GET", Route: "/api/v1/me/usage", Description: "Service API keys are refused with 403 API_KEY_NOT_ALLOWED, like on every route that does not declare a scope"},
+			{Type: Added, Method: "GET", Route: "/health/live", Description: "Liveness probe that checks no dependencies"},
+			{Type: Changed, Method: "GET", Route: "/health/ready", Fields: []string{"checks"}, Description: "Readiness also fails while a checked upstream's circuit breaker is open or broker registration has not succeeded"},
+			{Type: Changed, Method: "GET", Route: "/health", Fields: []string{"ready", "checks"}, Description: "Aggregates liveness and readiness; status is degraded when a readiness check fails"},
+		},
+	},
+	{
+		Version: "1.2.0",
+		Date:    "2026-09-22",
+		Changes: []Change{
+			{Type: Added, Method: "PATCH", Route: "/api/v1/albums/:id", Description: "JSON Merge Patch or JSON Patch of an album, guarded by If-Match"},
+			{Type: Added, Method: "PATCH", Route: "/api/v1/bookings/:id", Description: "JSON Merge Patch or JSON Patch of a booking, guarded by If-Match"},
+			{Type: Added, Method: "PATCH", Route: "/api/v1/rooms/:id", Description: "JSON Merge Patch or JSON Patch of a room, guarded by If-Match"},
+			{Type: Added, Method: "PATCH", Route: "/api/v1/guests/:id", Description: "JSON Merge Patch or JSON Patch of a guest profile, guarded by If-Match"},
+			{Type: Added, Method: "GET", Route: "/api/v1/events/stream", Description: "Server-sent event stream of domain events"},
+			{Type: Added, Method: "GET", Route: "/api/v1/housekeeping/tasks", Description: "Housekeeping task queue"},
+			{Type: Added, Method: "POST", Route: "/api/v1/housekeeping/tasks/claim", Description: "Claim the next housekeeping task"},
+			{Type: Added, Method: "GET", Route: "/api/v1/proxy/beheerder/*path", Description: "Allow-listed API Beheerder endpoints without a dedicated route"},
+			{Type: Changed, Method: "GET", Route: "/api/v1/availability", Fields: []string{"partial", "unavailable_sources"}, Description: "Searches answer from inventory when bookings or restrictions cannot be fetched"},
+		},
+	},
+	{
+		Version: "1.1.0",
+		Date:    "2026-08-18",
+		Changes: []Change{
+			{Type: Added, Method: "GET", Route: "/api/v1/bookings", Description: "Booking list with filters and cursor pagination"},
+			{Type: Added, Method: "POST", Route: "/api/v1/bookings", Description: "Create a booking after an availability check"},
+			{Type: Added, Method: "GET", Route: "/api/v1/rooms", Description: "Room list with filters and cursor pagination"},
+			{Type: Added, Method: "PATCH", Route: "/api/v1/rooms/:id/status", Description: "Room status transitions checked against the state machine"},
+			{Type: Added, Method: "GET", Route: "/api/v1/guests", Description: "Guest profiles with per-user field filtering"},
+			{Type: Added, Method: "GET", Route: "/api/v1/guests/:id/export", Description: "Data subject access export of a guest"},
+			{Type: Added, Method: "DELETE", Route: "/api/v1/guests/:id/personal-data", Description: "Erase a guest's personal data"},
+			{Type: Added, Method: "GET", Route: "/api/v1/availability", Description: "Availability search across inventory and rate restrictions"},
+			{Type: Added, Method: "GET", Route: "/api/v1/capabilities", Description: "Features enabled in this deployment"},
+			{Type: Added, Method: "GET", Route: "/api/v1/auth/token-status", Description: "Remaining token lifetime and renewal advice"},
+			{Type: Added, Method: "POST", Route: "/api/v1/auth/extend", Description: "Policy-limited access token extension"},
+		},
+	},
+}
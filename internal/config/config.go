@@ -3,17 +3,96 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds all configuration for the application
 type Config struct {
 	// Server settings
-	Host string
-	Port string
+	Host        string
+	Port        string
+	Environment string // development, staging or production
+	GinMode     string // release also selects production mode, see ProductionMode
+	Hardened    bool   // Refuse to start unless production security requirements are met
+
+	// Configuration reload settings
+	ConfigFile          string        // KEY=VALUE file applied over the environment, re-read on reload
+	ConfigWatchInterval time.Duration // How often the config file is checked for changes; 0 reloads on SIGHUP only
+
+	// Settings synced from Central Management's GET /config/:service
+	ConfigSyncEnabled    bool
+	ConfigSyncService    string        // Service name the settings are kept under
+	ConfigSyncInterval   time.Duration // How often the settings are fetched again; 0 fetches at startup only
+	ConfigSyncTimeout    time.Duration // Timeout of one fetch
+	ConfigSyncPrecedence string        // remote: Central Management wins over the environment; local: it fills in unset settings
+
+	// TLS termination settings
+	TLSMode           string        // off, files or acme
+	TLSCertFile       string        // Server certificate (PEM) for files mode
+	TLSKeyFile        string        // Server private key (PEM) for files mode
+	TLSAutoReload     bool          // Reload the certificate when the files change
+	TLSReloadInterval time.Duration // How often certificate files are checked for changes
+	ACMEDomains       string        // Comma-separated domains to obtain certificates for
+	ACMEEmail         string        // Contact address registered with the ACME CA
+	ACMECacheDir      string        // Where issued certificates and account keys are stored
+	ACMEHTTPPort      string        // Port answering HTTP-01 challenges and redirecting to HTTPS
+	HSTSMaxAge        time.Duration // Strict-Transport-Security max-age when TLS is on
 
 	// JWT settings for User Portal authentication
-	JWTSecret string
+	JWTSecret          string
+	JWTPreviousSecrets string        // Comma-separated retired secrets still accepted during a rotation
+	CursorSecret       string        // Signs pagination cursors; defaults to JWTSecret
+	AccessTokenTTL     time.Duration // Lifetime of issued access tokens
+	RefreshTokenTTL    time.Duration // Lifetime of issued refresh tokens
+
+	// Secrets backend providing JWT_SECRET, service keys and other secrets
+	SecretsProvider        string        // vault, aws, or empty to take secrets from the environment
+	SecretsPath            string        // Vault KV path or AWS secret name/ARN holding the secrets as JSON
+	SecretsRefreshInterval time.Duration // How often secrets are fetched again to pick up rotation; 0 disables
+	SecretsTimeout         time.Duration // Timeout of one fetch
+	VaultAddr              string
+	VaultToken             string
+	VaultNamespace         string // Vault Enterprise namespace
+	VaultMount             string // KV version 2 engine mount
+	AWSRegion              string
+	AWSAccessKeyID         string
+	AWSSecretAccessKey     string
+	AWSSessionToken        string // Set with temporary credentials
+	AWSSecretsEndpoint     string // Overrides the regional Secrets Manager endpoint, e.g. a VPC endpoint
+
+	// Session extension for kiosks via POST /api/v1/auth/extend
+	TokenExtensionRoles string        // Comma-separated roles allowed to extend; empty disables extension
+	TokenMaxExtensions  int           // Extensions allowed per session
+	TokenRenewBefore    time.Duration // token-status recommends renewal once less than this remains
+
+	// Cookie-based auth for the User Portal
+	EnableCookieAuth bool   // Issue httpOnly auth cookies at login and require CSRF tokens
+	CookieDomain     string // Domain attribute of auth cookies; empty means the API host
+	CookieSecure     bool   // Only send auth cookies over HTTPS
+	CookieSameSite   string // strict, lax or none
+
+	// OIDC single sign-on settings
+	OIDCEnabled           bool
+	OIDCIssuerURL         string
+	OIDCClientID          string
+	OIDCClientSecret      string
+	OIDCRedirectURL       string // Our callback URL registered with the provider
+	OIDCScopes            string // Space-separated scopes
+	OIDCRolesClaim        string // ID token claim holding the user's roles
+	OIDCPostLoginRedirect string // Portal URL receiving tokens in the fragment; empty returns JSON
+	OIDCStateSecret       string // Signs the login state cookie; empty derives a key from the JWT keys
+
+	// Service-to-service API keys (name:key:scope1|scope2, comma separated)
+	InternalAPIKeys string
+
+	// Token blacklist settings
+	BlacklistBackend          string        // memory, redis or postgres
+	BlacklistCleanupInterval  time.Duration // How often expired revocations are purged
+	BlacklistMaxEntries       int           // Size cap; expired entries are force-evicted when reached
+	BlacklistCleanupThreshold int           // Size at which a revocation triggers a cleanup
+	RedisURL                  string
+	DatabaseURL               string
 
 	// External services
 	APIBeheerderURL string
@@ -21,15 +100,223 @@ type Config struct {
 	CentralMgmtURL  string
 	CentralMgmtKey  string
 
-	// CORS settings
-	UserPortalURL  string
-	AllowedOrigins string
+	// Third-party property management systems for bookings and rooms
+	PMSAdapters    string // Comma-separated name=url entries, each reached as upstream pms-<name>
+	PMSAdapterKeys string // Comma-separated name=key entries sent as X-Service-Key
+	PMSTenants     string // Comma-separated tenant=adapter entries; other tenants use API Beheerder
+
+	// Further backends, each an upstream called by its name
+	UpstreamServices         string // Comma-separated name=url entries, e.g. billing=https://billing.internal
+	UpstreamServiceKeys      string // Comma-separated name=key entries sent as X-Service-Key
+	UpstreamServiceSPIFFEIDs string // Comma-separated name=SPIFFE ID entries checked with mTLS
+
+	// Mutual TLS towards the backend services
+	MTLSEnabled          bool   // Present a client certificate and verify backend certificates
+	MTLSCertFile         string // Client certificate (PEM)
+	MTLSKeyFile          string // Client private key (PEM)
+	MTLSCAFile           string // CA bundle trusted for backend server certificates
+	APIBeheerderSPIFFEID string // Expected SPIFFE ID of API Beheerder; empty checks the hostname instead
+	CentralMgmtSPIFFEID  string // Expected SPIFFE ID of Central Management; empty checks the hostname instead
+
+	// Connection pools towards the backend services, one per service
+	UpstreamMaxConnsPerHost     int           // Connections per service, 0 for no limit
+	UpstreamMaxIdleConnsPerHost int           // Idle connections kept for reuse per service
+	UpstreamIdleConnTimeout     time.Duration // How long an idle connection is kept
+	UpstreamTLSHandshakeTimeout time.Duration
+	UpstreamKeepAlive           time.Duration // TCP keep-alive probe interval
+	UpstreamHTTP2               bool          // Negotiate HTTP/2 with https backends
+	UpstreamTimeout             time.Duration // Call timeout of services without their own
+	UpstreamServiceTimeouts     string        // Per-service call timeouts in seconds, e.g. central-mgmt=5,pms-opera=60
+	UpstreamMaxConcurrency      int           // Calls in progress per service before new ones get 503, 0 for no limit
+	UpstreamServiceConcurrency  string        // Per-service limits, e.g. central-mgmt=20,pms-opera=5
+	BeheerderHedgeEnabled       bool          // Send slow API Beheerder GETs a second time
+	BeheerderHedgeDelay         time.Duration // Wait before the second attempt
+
+	// Reference data cache settings
+	ReferenceDatasets string        // name=service:endpoint pairs preloaded at startup
+	ReferenceCacheTTL time.Duration // How long reference data stays cached
+	WarmTimeout       time.Duration // Time box for the startup warming phase
+
+	// Role hierarchy settings
+	RoleHierarchy       string        // "role:grant1|grant2" entries; grants may be roles or wildcard permissions
+	RoleHierarchySource string        // "config" or "central" to fetch from Central Management
+	RoleRefreshInterval time.Duration // How often the hierarchy is refetched from Central Management
+
+	// Permission check settings
+	PermissionCacheTTL time.Duration // How long Central Management permission decisions are cached
+
+	// Business rule settings
+	BusinessRulesEnabled bool // Validate album payloads against Central Management business rules
+
+	// Guest messaging settings
+	MessageRetention    time.Duration // How long guest/front-desk messages are kept
+	MessageBlockedWords string        // Comma-separated words masked by the profanity filter
+
+	// Localized enum labels
+	LabelsDir string // Directory with default.json and <tenant>.json label catalogs
+
+	// Availability search settings
+	AvailabilityCacheTTL time.Duration // How long merged inventory and restrictions are cached per hotel and date range
+
+	// Housekeeping stream settings
+	HousekeepingStreamConcurrency int           // Upstream updates applied in parallel per stream
+	HousekeepingStreamIdleTimeout time.Duration // Streams without a new line for this long are closed
+	HousekeepingTaskRetention     time.Duration // Completed and reported tasks are dropped after this long
+
+	// Server-sent event stream settings
+	StreamQueueSize  int           // Events buffered per connection
+	StreamMaxDropped int           // Consecutive dropped events before a slow consumer is evicted; 0 disables
+	StreamMaxLag     time.Duration // Delivery lag before a slow consumer is evicted; 0 disables
+	StreamHeartbeat  time.Duration // Interval of keep-alive comments on idle streams
+
+	// Wildcard proxy to API Beheerder (/api/v1/proxy/beheerder/*path)
+	BeheerderProxyRules       string // Allow-list: "METHODS /path[=action:resource]" entries separated by ;
+	BeheerderProxyStripFields string // Comma-separated fields removed from every proxied response
+
+	// Transparent passthrough to other backends (/api/v1/proxy/<service>/*path)
+	PassthroughServices     string // Comma-separated upstream names forwarded as they are
+	PassthroughRoles        string // Comma-separated roles allowed to use the passthrough
+	PassthroughStripHeaders string // Comma-separated headers removed from passthrough requests and responses
+
+	// Adapting backend calls to changed backend APIs
+	TransformRulesFile string // JSON list of path rewrite, header and field mapping rules per backend route
+
+	// Streaming proxy to API Beheerder (/api/v1/proxy/stream/beheerder/*path)
+	BeheerderStreamRules       string        // Allow-list of GET endpoints whose responses are streamed unmodified
+	BeheerderStreamIdleTimeout time.Duration // Downloads that make no progress for this long are closed
+
+	// Maintenance window settings
+	MaintenanceCacheTTL time.Duration // How long successful reads are kept for cached-mode maintenance
+
+	// Upstream callback settings
+	BeheerderWebhookSecret string        // Shared HMAC secret for API Beheerder callbacks; empty disables them
+	WebhookSignatureMaxAge time.Duration // Maximum age of a signed callback timestamp
+	WebhookInboxDir        string        // Directory where callbacks are buffered before processing
+	WebhookDedupRetention  time.Duration // How long processed event IDs are remembered
+
+	// Outbound webhook settings
+	OutboundWebhooksEnabled    bool
+	OutboundWebhookDir         string        // Directory holding subscriptions and queued deliveries
+	OutboundWebhookTimeout     time.Duration // Per-attempt request timeout
+	OutboundWebhookMaxAttempts int           // Attempts before a delivery becomes a dead letter
+	OutboundWebhookRetention   time.Duration // How long delivered events are kept
+
+	// Message broker publishing for downstream consumers
+	EventBrokerDriver     string        // nats or kafka; empty disables publishing
+	EventBrokerURLs       string        // Comma-separated server URLs (NATS) or host:port bootstrap brokers (Kafka)
+	EventBrokerTopic      string        // Topic (Kafka) or subject prefix (NATS) for domain events
+	EventBrokerAuditTopic string        // Topic or subject prefix for audit entries
+	EventBrokerBufferSize int           // Events buffered before new ones are dropped
+	EventBrokerTimeout    time.Duration // Connect, write and acknowledgement timeout
+
+	// OpenTelemetry tracing of requests and backend calls
+	TracingEnabled       bool
+	TracingEndpoint      string // OTLP/HTTP collector base URL
+	TracingHeaders       string // Export headers, e.g. x-api-key=secret
+	TracingServiceName   string
+	TracingSamplePercent int // Share of new traces recorded
+
+	// Protection of the Prometheus endpoint; empty leaves /metrics open
+	MetricsBasicAuthUser     string
+	MetricsBasicAuthPassword string
+	MetricsAPIKey            string // Accepted as "Authorization: Bearer <key>"
+
+	// Service level objectives per route group
+	SLOObjectives        string        // name=/prefix:availability:latency:latency_target, comma separated; empty disables tracking
+	SLOWindow            time.Duration // Period the error budget covers
+	SLOFastBurnThreshold float64       // 1h burn rate that warns
+	SLOSlowBurnThreshold float64       // 6h burn rate that warns
+
+	// Admin-only /debug endpoints: pprof, goroutine dump and build info
+	DebugEndpointsEnabled bool
+
+	// Readiness probe (/health/ready) settings
+	ReadinessUpstreams     string // Upstreams whose open breaker makes the instance unready; empty means all
+	ReadinessRequireBroker bool   // Stay unready until registered with the broker
+
+	// Broker registration heartbeat
+	BrokerHeartbeatInterval    time.Duration // How often the registration is renewed; 0 registers once
+	BrokerRetryMax             time.Duration // Longest wait between failed registration attempts
+	BrokerDeregisterOnShutdown bool          // Remove the route from the broker on graceful shutdown
+
+	// Backend addresses: static uses API_BEHEERDER_URL and CENTRAL_MGMT_URL,
+	// broker looks them up with the broker and falls back to those URLs
+	ServiceDiscovery        string        // static or broker
+	DiscoveryServices       string        // Broker slug by upstream, e.g. api-beheerder=api-beheerder,central-mgmt=central-mgmt
+	DiscoveryRefresh        time.Duration // Time between lookups; 0 looks up at startup and after failures only
+	DiscoveryFailureRefresh time.Duration // Least time between lookups of an upstream after failed calls
+
+	// Background dependency probes served by /health
+	HealthProbeEnabled          bool
+	HealthProbeInterval         time.Duration
+	HealthProbeTimeout          time.Duration
+	HealthProbeFailureThreshold int    // Failed probes in a row before a dependency is down
+	HealthProbePaths            string // Per-upstream probe paths (service=/path); others use /health
+
+	// Response caching for album, booking and room reads
+	ResponseCacheEnabled   bool
+	ResponseCacheBackend   string        // memory or redis (uses REDIS_URL)
+	ResponseCacheTTL       time.Duration // TTL of cached routes without their own
+	ResponseCacheRouteTTLs string        // Per-route TTLs in seconds, e.g. /api/v1/albums=60,/api/v1/rooms/:id=10
+
+	// Background jobs for long-running upstream requests (Prefer: respond-async)
+	JobsEnabled   bool
+	JobsStore     string        // memory, file or redis (uses REDIS_URL)
+	JobsDir       string        // Directory for the file store
+	JobsWorkers   int           // Jobs running at the same time
+	JobsQueueSize int           // Jobs waiting for a worker before new ones are refused
+	JobsTimeout   time.Duration // How long one job may run
+	JobsRetention time.Duration // How long finished jobs can be polled
+
+	// Negotiated gzip/Brotli response compression
+	CompressionEnabled       bool
+	CompressionMinSize       int    // Bytes below which responses are sent as they are
+	CompressionLevel         int    // gzip level, 1 (fastest) to 9 (smallest)
+	CompressionTypes         string // Comma-separated compressible content types; type/* matches a whole type
+	CompressionDisabledPaths string // Comma-separated route group prefixes that are never compressed, e.g. /api/v1/stream
+
+	// Field-level encryption for sensitive upstream payload fields
+	FieldEncryptionEnabled   bool
+	FieldEncryptionKeys      string // id:base64key pairs, comma separated
+	FieldEncryptionActiveKey string // Key ID used for new encryptions
+	FieldEncryptionSchema    string // resource=field|nested.field pairs, semicolon separated
+
+	// Optional feature flags reported to the portal
+	FeatureWebSockets bool
+	FeatureTwoFactor  bool
+	FeaturePayments   bool
+	FeaturePartnerAPI bool
+	FeatureFlags      string // Additional comma-separated feature flags
+	SupportedLocales  string // Comma-separated locale codes
+
+	// CORS settings; each may be set per APP_ENV with a suffix, e.g. CORS_MAX_AGE_SECONDS_PRODUCTION
+	UserPortalURL        string
+	AllowedOrigins       string        // Exact origins, "*", or subdomain patterns such as https://*.hotel.com
+	CORSAllowedMethods   string        // Comma-separated methods allowed in cross-origin requests
+	CORSAllowedHeaders   string        // Comma-separated request headers allowed in cross-origin requests
+	CORSExposedHeaders   string        // Comma-separated response headers readable by browser scripts
+	CORSMaxAge           time.Duration // How long browsers cache a preflight answer
+	CORSAllowCredentials bool          // Allow cookies and Authorization headers in cross-origin requests
 
 	// Circuit breaker configuration
 	CircuitBreakerFailureThreshold int
 	CircuitBreakerTimeout          time.Duration
 	CircuitBreakerMaxRetries       int
 	CircuitBreakerRetryDelay       time.Duration
+	CircuitBreakerHalfOpenProbes   int           // Trial calls let through at once while half-open
+	CircuitBreakerSuccessThreshold int           // Trial calls in a row that must succeed to close the circuit
+	CircuitBreakerPolicy           string        // consecutive or error_rate
+	CircuitBreakerServicePolicies  string        // Per-service policies, e.g. central-mgmt=error_rate
+	CircuitBreakerErrorRate        float64       // Percentage of failed calls that opens an error_rate breaker
+	CircuitBreakerWindow           time.Duration // Sliding window of error_rate breakers
+	CircuitBreakerMinCalls         int           // Calls in the window before the error rate counts
+	CircuitBreakerFallback         bool          // Answer GETs from a fallback while a breaker is open
+	CircuitBreakerFallbackTTL      time.Duration // How long last-known-good responses are kept
+	CircuitBreakerFallbackFile     string        // JSON file of static fallback payloads by "service /path"
+
+	// Resilience policy chains around backend calls
+	ResilienceChain         string // Policies from the outermost in, e.g. fallback,bulkhead,timeout,breaker
+	ResilienceServiceChains string // Per-service chains, e.g. central-mgmt=timeout,retry,breaker;pms-opera=breaker
 
 	// Security settings
 	MaxRequestBodySize     int64         // Maximum request body size in bytes
@@ -39,6 +326,47 @@ type Config struct {
 	IdleTimeout            time.Duration // Maximum time for idle connections
 	EnableSecurityHeaders  bool          // Enable security headers
 	EnableAuditLogging     bool          // Enable audit logging
+	AuditStoreCapacity     int           // Number of recent audit entries kept in memory for chain verification and the memory store
+	AuditStoreBackend      string        // memory, redis or postgres; where access reports are read from
+	AuditStoreRetention    time.Duration // How long the redis and postgres audit stores keep entries
+	AuditPIIFields         string        // Comma-separated fields and query parameters masked in audit logs
+	AuditRedactFields      string        // Comma-separated field name globs whose values are always redacted from audit logs
+	AuditRedactCardNumbers bool          // Redact Luhn-valid card numbers in any audit logged value
+	AuditCapturePolicy     string        // Default audit capture policy: none, metadata, redacted or full
+	AuditCaptureRoutes     string        // Comma-separated "METHOD /route=policy" capture overrides
+	AuditShipEnabled       bool          // Also ship audit entries to Central Management
+	AuditShipEndpoint      string        // Central Management path receiving audit batches
+	AuditShipBatchSize     int           // Entries per batch
+	AuditShipFlushInterval time.Duration // Longest an entry waits for its batch to fill
+	AuditShipBufferSize    int           // Entries buffered while Central Management is slow or down
+	AuditShipMaxAttempts   int           // Sends of a batch before it is given up
+	AuditShipOverflow      string        // drop_oldest or drop_newest when the buffer is full
+	AuditShipSpillFile     string        // JSON lines file for entries that cannot be shipped; empty drops them
+	AuditAnchorInterval    time.Duration // Time between audit chain anchors sent to Central Management; 0 disables them
+	AuditAnchorEndpoint    string        // Central Management path receiving audit chain anchors
+	MiddlewareTraceEnabled bool          // Honour X-Debug-Trace outside production
+	LogFile                string        // Also write application and audit logs here; rotatable via /admin/actions
+	LogLevel               string        // Lowest level logged: debug, info, warn or error
+
+	// Response data masking settings
+	DataMaskingMode   string // auto (mask unless APP_ENV=production), on or off
+	DataMaskingFields string // Comma-separated field:strategy entries (hash, partial or fake)
+	DataMaskingSalt   string // Key for masked hashes; empty uses a random key per start
+
+	// OpenAPI schema validation settings
+	SchemaValidation       string // Default mode: off, request, response or full
+	SchemaValidationRoutes string // Comma-separated "METHOD /route=mode" overrides
+
+	// API documentation settings
+	DocsEnabled bool // Serve /openapi.json and Swagger UI at /docs to admins
+
+	// gRPC interface settings
+	GRPCEnabled bool   // Serve the gRPC contract next to REST
+	GRPCPort    string // Port of the gRPC listener
+
+	// GraphQL settings
+	GraphQLEnabled  bool // Serve POST /api/v1/graphql
+	GraphQLMaxDepth int  // Deepest selection nesting a query may use
 
 	// Rate limiting settings
 	RateLimitEnabled       bool          // Enable rate limiting
@@ -48,17 +376,143 @@ type Config struct {
 	LoginRateLimitInterval time.Duration // Time window for login rate limiting
 	AdminRateLimitRequests int           // Requests per interval for admin endpoints
 	AdminRateLimitInterval time.Duration // Time window for admin rate limiting
+
+	// Login reputation settings (credential stuffing detection)
+	ReputationEnabled          bool          // Enable login reputation scoring
+	ReputationBackend          string        // memory or redis (uses REDIS_URL)
+	ReputationChallengeScore   int           // Score at or below which a CAPTCHA/step-up is required
+	ReputationBlockScore       int           // Score at or below which logins are blocked
+	ReputationFailurePenalty   int           // Points removed per failed login
+	ReputationSuccessReward    int           // Points restored per successful login
+	ReputationRecoveryInterval time.Duration // Time to recover one point
+	ReputationChallengeURL     string        // Endpoint used to verify challenge tokens
+
+	// Anonymous availability widget (/public/v1)
+	PublicAvailabilityEnabled  bool          // Register the anonymous route tier
+	PublicAvailabilityHotels   string        // Comma-separated hotels the widget may query; empty allows all
+	PublicAvailabilityCacheTTL time.Duration // How long public search results are cached and may be cached by browsers
+	PublicRateLimitRequests    int           // Requests per interval per IP
+	PublicRateLimitInterval    time.Duration // Time window for public rate limiting
+	PublicBlockedUserAgents    string        // Comma-separated User-Agent fragments rejected as bots
+	PublicChallengeURL         string        // Endpoint verifying X-Challenge-Token; empty disables challenges
+	PublicWidgetOrigins        string        // Website origins added to the CORS allow-list
+
+	// Account lockout settings
+	LockoutEnabled     bool          // Lock accounts after repeated failed logins
+	LockoutMaxFailures int           // Failed logins within the window that lock an account
+	LockoutWindow      time.Duration // Period over which failures are counted
+	LockoutDuration    time.Duration // How long an account stays locked
+
+	// Usage reporting settings
+	UsageTrackingEnabled     bool          // Record per-consumer usage for /admin/usage-reports
+	UsageMonthlyQuota        int64         // Default monthly request quota per consumer (0 = none)
+	UsageQuotas              string        // consumer=limit overrides, e.g. service:billing=100000
+	UsageRetention           time.Duration // How long daily aggregates are kept
+	UsageAggregationInterval time.Duration // How often buffered requests are aggregated
+
+	issues []string // Malformed values Load replaced with their default, reported by Validate
 }
 
-// Load loads configuration from environment variables with sensible defaults
+// Load loads configuration from environment variables with sensible
+// defaults. Malformed numbers and booleans fall back to their default and
+// are reported by Validate.
 func Load() *Config {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	loadIssues = nil
+	cfg := load()
+	cfg.issues = loadIssues
+	return cfg
+}
+
+// load builds the configuration from the environment; callers hold loadMu
+func load() *Config {
+	environment := getEnv("APP_ENV", "development")
 	return &Config{
 		// Server settings
-		Host: getEnv("HOST", "localhost"),
-		Port: getEnv("PORT", "8080"),
+		Host:        getEnv("HOST", "localhost"),
+		Port:        getEnv("PORT", "8080"),
+		Environment: environment,
+		GinMode:     getEnv("GIN_MODE", "debug"),
+		Hardened:    getEnvBool("HARDENED_MODE", false),
+
+		// Configuration reload settings
+		ConfigFile:          getEnv("CONFIG_FILE", ""),
+		ConfigWatchInterval: time.Duration(getEnvInt("CONFIG_WATCH_INTERVAL_SECONDS", 10)) * time.Second,
+
+		// Settings synced from Central Management
+		ConfigSyncEnabled:    getEnvBool("CONFIG_SYNC_ENABLED", false),
+		ConfigSyncService:    getEnv("CONFIG_SYNC_SERVICE", "internal-api"),
+		ConfigSyncInterval:   time.Duration(getEnvInt("CONFIG_SYNC_INTERVAL_SECONDS", 60)) * time.Second,
+		ConfigSyncTimeout:    time.Duration(getEnvInt("CONFIG_SYNC_TIMEOUT_SECONDS", 5)) * time.Second,
+		ConfigSyncPrecedence: getEnv("CONFIG_SYNC_PRECEDENCE", "remote"),
+
+		// TLS termination settings
+		TLSMode:           getEnv("TLS_MODE", "off"),
+		TLSCertFile:       getEnv("TLS_CERT_FILE", "certs/server.crt"),
+		TLSKeyFile:        getEnv("TLS_KEY_FILE", "certs/server.key"),
+		TLSAutoReload:     getEnvBool("TLS_AUTO_RELOAD", true),
+		TLSReloadInterval: time.Duration(getEnvInt("TLS_RELOAD_INTERVAL_SECONDS", 60)) * time.Second,
+		ACMEDomains:       getEnv("ACME_DOMAINS", ""),
+		ACMEEmail:         getEnv("ACME_EMAIL", ""),
+		ACMECacheDir:      getEnv("ACME_CACHE_DIR", "data/acme"),
+		ACMEHTTPPort:      getEnv("ACME_HTTP_PORT", "80"),
+		HSTSMaxAge:        time.Duration(getEnvInt("HSTS_MAX_AGE_SECONDS", 31536000)) * time.Second,
 
 		// JWT settings
-		JWTSecret: getEnv("JWT_SECRET", "your-jwt-secret-key"),
+		JWTSecret:          getEnv("JWT_SECRET", "your-jwt-secret-key"),
+		JWTPreviousSecrets: getEnv("JWT_PREVIOUS_SECRETS", ""),
+		CursorSecret:       getEnv("CURSOR_SECRET", ""),
+		AccessTokenTTL:     time.Duration(getEnvInt("ACCESS_TOKEN_TTL_MINUTES", 15)) * time.Minute,
+		RefreshTokenTTL:    time.Duration(getEnvInt("REFRESH_TOKEN_TTL_HOURS", 168)) * time.Hour,
+
+		// Secrets backend
+		SecretsProvider:        getEnv("SECRETS_PROVIDER", ""),
+		SecretsPath:            getEnv("SECRETS_PATH", "internal-api"),
+		SecretsRefreshInterval: time.Duration(getEnvInt("SECRETS_REFRESH_MINUTES", 5)) * time.Minute,
+		SecretsTimeout:         time.Duration(getEnvInt("SECRETS_TIMEOUT_SECONDS", 10)) * time.Second,
+		VaultAddr:              getEnv("VAULT_ADDR", "http://127.0.0.1:8200"),
+		VaultToken:             getEnv("VAULT_TOKEN", ""),
+		VaultNamespace:         getEnv("VAULT_NAMESPACE", ""),
+		VaultMount:             getEnv("VAULT_KV_MOUNT", "secret"),
+		AWSRegion:              getEnv("AWS_REGION", ""),
+		AWSAccessKeyID:         getEnv("AWS_ACCESS_KEY_ID", ""),
+		AWSSecretAccessKey:     getEnv("AWS_SECRET_ACCESS_KEY", ""),
+		AWSSessionToken:        getEnv("AWS_SESSION_TOKEN", ""),
+		AWSSecretsEndpoint:     getEnv("AWS_SECRETS_ENDPOINT", ""),
+
+		// Session extension
+		TokenExtensionRoles: getEnv("TOKEN_EXTENSION_ROLES", "kiosk"),
+		TokenMaxExtensions:  getEnvInt("TOKEN_MAX_EXTENSIONS", 8),
+		TokenRenewBefore:    time.Duration(getEnvInt("TOKEN_RENEW_BEFORE_SECONDS", 120)) * time.Second,
+
+		// Cookie-based auth
+		EnableCookieAuth: getEnvBool("ENABLE_COOKIE_AUTH", false),
+		CookieDomain:     getEnv("COOKIE_DOMAIN", ""),
+		CookieSecure:     getEnvBool("COOKIE_SECURE", true),
+		CookieSameSite:   getEnv("COOKIE_SAMESITE", "lax"),
+
+		// OIDC single sign-on settings
+		OIDCEnabled:           getEnvBool("OIDC_ENABLED", false),
+		OIDCIssuerURL:         getEnv("OIDC_ISSUER_URL", ""),
+		OIDCClientID:          getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:      getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:       getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/auth/oidc/callback"),
+		OIDCScopes:            getEnv("OIDC_SCOPES", "openid profile email"),
+		OIDCRolesClaim:        getEnv("OIDC_ROLES_CLAIM", "roles"),
+		OIDCPostLoginRedirect: getEnv("OIDC_POST_LOGIN_REDIRECT", ""),
+		OIDCStateSecret:       getEnv("OIDC_STATE_SECRET", ""),
+
+		// Service-to-service API keys
+		InternalAPIKeys: getEnv("INTERNAL_API_KEYS", ""),
+
+		// Token blacklist settings
+		BlacklistBackend:          getEnv("BLACKLIST_BACKEND", "memory"),
+		BlacklistCleanupInterval:  time.Duration(getEnvInt("BLACKLIST_CLEANUP_MINUTES", 60)) * time.Minute,
+		BlacklistMaxEntries:       getEnvInt("BLACKLIST_MAX_ENTRIES", 100000),
+		BlacklistCleanupThreshold: getEnvInt("BLACKLIST_CLEANUP_THRESHOLD", 0),
+		RedisURL:                  getEnv("REDIS_URL", "redis://localhost:6379/0"),
+		DatabaseURL:               getEnv("DATABASE_URL", "postgres://localhost:5432/internal_api?sslmode=disable"),
 
 		// External services
 		APIBeheerderURL: getEnv("API_BEHEERDER_URL", "http://localhost:8081"),
@@ -66,24 +520,271 @@ func Load() *Config {
 		CentralMgmtURL:  getEnv("CENTRAL_MGMT_URL", "http://localhost:8082"),
 		CentralMgmtKey:  getEnv("CENTRAL_MGMT_KEY", "central-mgmt-service-key"),
 
+		// Third-party property management systems for bookings and rooms
+		PMSAdapters:    getEnv("PMS_ADAPTERS", ""),
+		PMSAdapterKeys: getEnv("PMS_ADAPTER_KEYS", ""),
+		PMSTenants:     getEnv("PMS_TENANTS", ""),
+
+		// Further backends
+		UpstreamServices:         getEnv("UPSTREAM_SERVICES", ""),
+		UpstreamServiceKeys:      getEnv("UPSTREAM_SERVICE_KEYS", ""),
+		UpstreamServiceSPIFFEIDs: getEnv("UPSTREAM_SERVICE_SPIFFE_IDS", ""),
+
+		// Mutual TLS towards the backend services
+		MTLSEnabled:          getEnvBool("MTLS_ENABLED", false),
+		MTLSCertFile:         getEnv("MTLS_CERT_FILE", "certs/client.crt"),
+		MTLSKeyFile:          getEnv("MTLS_KEY_FILE", "certs/client.key"),
+		MTLSCAFile:           getEnv("MTLS_CA_FILE", "certs/ca.crt"),
+		APIBeheerderSPIFFEID: getEnv("API_BEHEERDER_SPIFFE_ID", ""),
+		CentralMgmtSPIFFEID:  getEnv("CENTRAL_MGMT_SPIFFE_ID", ""),
+
+		// Backend connection pools
+		UpstreamMaxConnsPerHost:     getEnvInt("UPSTREAM_MAX_CONNS_PER_HOST", 100),
+		UpstreamMaxIdleConnsPerHost: getEnvInt("UPSTREAM_MAX_IDLE_CONNS_PER_HOST", 20),
+		UpstreamIdleConnTimeout:     time.Duration(getEnvInt("UPSTREAM_IDLE_CONN_TIMEOUT_SECONDS", 90)) * time.Second,
+		UpstreamTLSHandshakeTimeout: time.Duration(getEnvInt("UPSTREAM_TLS_HANDSHAKE_TIMEOUT_SECONDS", 10)) * time.Second,
+		UpstreamKeepAlive:           time.Duration(getEnvInt("UPSTREAM_KEEPALIVE_SECONDS", 30)) * time.Second,
+		UpstreamHTTP2:               getEnvBool("UPSTREAM_HTTP2", true),
+		UpstreamTimeout:             time.Duration(getEnvInt("UPSTREAM_TIMEOUT_SECONDS", 10)) * time.Second,
+		UpstreamServiceTimeouts:     getEnv("UPSTREAM_SERVICE_TIMEOUTS", ""),
+		UpstreamMaxConcurrency:      getEnvInt("UPSTREAM_MAX_CONCURRENCY", 100),
+		UpstreamServiceConcurrency:  getEnv("UPSTREAM_SERVICE_CONCURRENCY", ""),
+		BeheerderHedgeEnabled:       getEnvBool("BEHEERDER_HEDGE_ENABLED", false),
+		BeheerderHedgeDelay:         time.Duration(getEnvInt("BEHEERDER_HEDGE_DELAY_MS", 150)) * time.Millisecond,
+
+		// Reference data cache settings
+		ReferenceDatasets: getEnv("REFERENCE_DATASETS", "business-rules=central:/business-rules/albums,roles=central:/admin/roles"),
+		ReferenceCacheTTL: time.Duration(getEnvInt("REFERENCE_CACHE_TTL_SECONDS", 300)) * time.Second,
+		WarmTimeout:       time.Duration(getEnvInt("WARM_TIMEOUT_SECONDS", 10)) * time.Second,
+
+		// Role hierarchy settings
+		RoleHierarchy:       getEnv("ROLE_HIERARCHY", "super_admin:admin,admin:user"),
+		RoleHierarchySource: getEnv("ROLE_HIERARCHY_SOURCE", "config"),
+		RoleRefreshInterval: time.Duration(getEnvInt("ROLE_REFRESH_INTERVAL_MINUTES", 5)) * time.Minute,
+
+		// Permission check settings
+		PermissionCacheTTL: time.Duration(getEnvInt("PERMISSION_CACHE_TTL_SECONDS", 60)) * time.Second,
+
+		// Business rule settings
+		BusinessRulesEnabled: getEnvBool("BUSINESS_RULES_ENABLED", true),
+
+		// Guest messaging settings
+		MessageRetention:    time.Duration(getEnvInt("MESSAGE_RETENTION_DAYS", 90)) * 24 * time.Hour,
+		MessageBlockedWords: getEnv("MESSAGE_BLOCKED_WORDS", ""),
+
+		// Localized enum labels
+		LabelsDir: getEnv("LABELS_DIR", "labels"),
+
+		// Availability search settings
+		AvailabilityCacheTTL: time.Duration(getEnvInt("AVAILABILITY_CACHE_TTL_SECONDS", 30)) * time.Second,
+
+		// Housekeeping stream settings
+		HousekeepingStreamConcurrency: getEnvInt("HOUSEKEEPING_STREAM_CONCURRENCY", 8),
+		HousekeepingStreamIdleTimeout: time.Duration(getEnvInt("HOUSEKEEPING_STREAM_IDLE_SECONDS", 60)) * time.Second,
+		HousekeepingTaskRetention:     time.Duration(getEnvInt("HOUSEKEEPING_TASK_RETENTION_HOURS", 72)) * time.Hour,
+
+		// Server-sent event stream settings
+		StreamQueueSize:  getEnvInt("STREAM_QUEUE_SIZE", 64),
+		StreamMaxDropped: getEnvInt("STREAM_MAX_DROPPED_EVENTS", 32),
+		StreamMaxLag:     time.Duration(getEnvInt("STREAM_MAX_LAG_SECONDS", 30)) * time.Second,
+		StreamHeartbeat:  time.Duration(getEnvInt("STREAM_HEARTBEAT_SECONDS", 15)) * time.Second,
+
+		// Wildcard proxy settings
+		BeheerderProxyRules:       getEnv("BEHEERDER_PROXY_RULES", ""),
+		BeheerderProxyStripFields: getEnv("BEHEERDER_PROXY_STRIP_FIELDS", "password,password_hash,api_key,secret,internal_notes"),
+
+		// Transparent passthrough
+		PassthroughServices:     getEnv("PASSTHROUGH_SERVICES", ""),
+		PassthroughRoles:        getEnv("PASSTHROUGH_ROLES", "admin,super_admin"),
+		PassthroughStripHeaders: getEnv("PASSTHROUGH_STRIP_HEADERS", ""),
+
+		// Backend call transformations
+		TransformRulesFile: getEnv("TRANSFORM_RULES_FILE", ""),
+
+		// Streaming proxy settings
+		BeheerderStreamRules:       getEnv("BEHEERDER_STREAM_RULES", ""),
+		BeheerderStreamIdleTimeout: time.Duration(getEnvInt("BEHEERDER_STREAM_IDLE_SECONDS", 60)) * time.Second,
+
+		// Maintenance window settings
+		MaintenanceCacheTTL: time.Duration(getEnvInt("MAINTENANCE_CACHE_TTL_MINUTES", 60)) * time.Minute,
+
+		// Upstream callback settings
+		BeheerderWebhookSecret: getEnv("BEHEERDER_WEBHOOK_SECRET", ""),
+		WebhookSignatureMaxAge: time.Duration(getEnvInt("WEBHOOK_SIGNATURE_MAX_AGE_SECONDS", 300)) * time.Second,
+		WebhookInboxDir:        getEnv("WEBHOOK_INBOX_DIR", "data/callbacks"),
+		WebhookDedupRetention:  time.Duration(getEnvInt("WEBHOOK_DEDUP_RETENTION_HOURS", 168)) * time.Hour,
+
+		// Outbound webhook settings
+		OutboundWebhooksEnabled:    getEnvBool("OUTBOUND_WEBHOOKS_ENABLED", false),
+		OutboundWebhookDir:         getEnv("OUTBOUND_WEBHOOK_DIR", "data/webhooks"),
+		OutboundWebhookTimeout:     time.Duration(getEnvInt("OUTBOUND_WEBHOOK_TIMEOUT_SECONDS", 10)) * time.Second,
+		OutboundWebhookMaxAttempts: getEnvInt("OUTBOUND_WEBHOOK_MAX_ATTEMPTS", 8),
+		OutboundWebhookRetention:   time.Duration(getEnvInt("OUTBOUND_WEBHOOK_RETENTION_HOURS", 168)) * time.Hour,
+
+		// Message broker publishing
+		EventBrokerDriver:     getEnv("EVENT_BROKER_DRIVER", ""),
+		EventBrokerURLs:       getEnv("EVENT_BROKER_URLS", ""),
+		EventBrokerTopic:      getEnv("EVENT_BROKER_TOPIC", "hotel.events"),
+		EventBrokerAuditTopic: getEnv("EVENT_BROKER_AUDIT_TOPIC", "hotel.audit"),
+		EventBrokerBufferSize: getEnvInt("EVENT_BROKER_BUFFER_SIZE", 1024),
+		EventBrokerTimeout:    time.Duration(getEnvInt("EVENT_BROKER_TIMEOUT_SECONDS", 5)) * time.Second,
+
+		// OpenTelemetry tracing
+		TracingEnabled:       getEnvBool("TRACING_ENABLED", false),
+		TracingEndpoint:      getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
+		TracingHeaders:       getEnv("OTEL_EXPORTER_OTLP_HEADERS", ""),
+		TracingServiceName:   getEnv("OTEL_SERVICE_NAME", "internal-api"),
+		TracingSamplePercent: getEnvInt("TRACING_SAMPLE_PERCENT", 100),
+
+		// Prometheus endpoint protection
+		MetricsBasicAuthUser:     getEnv("METRICS_BASIC_AUTH_USER", ""),
+		MetricsBasicAuthPassword: getEnv("METRICS_BASIC_AUTH_PASSWORD", ""),
+		MetricsAPIKey:            getEnv("METRICS_API_KEY", ""),
+
+		// Service level objectives
+		SLOObjectives:        getEnv("SLO_OBJECTIVES", "api=/api/:99.9:500ms:99,public=/public/:99.5:500ms:99"),
+		SLOWindow:            time.Duration(getEnvInt("SLO_WINDOW_DAYS", 30)) * 24 * time.Hour,
+		SLOFastBurnThreshold: getEnvFloat("SLO_FAST_BURN_THRESHOLD", 14.4),
+		SLOSlowBurnThreshold: getEnvFloat("SLO_SLOW_BURN_THRESHOLD", 6),
+
+		// Debug endpoints
+		DebugEndpointsEnabled: getEnvBool("DEBUG_ENDPOINTS_ENABLED", false),
+
+		// Readiness probe settings
+		ReadinessUpstreams:     getEnv("READINESS_UPSTREAMS", ""),
+		ReadinessRequireBroker: getEnvBool("READINESS_REQUIRE_BROKER", true),
+
+		// Broker registration heartbeat
+		BrokerHeartbeatInterval:    time.Duration(getEnvInt("BROKER_HEARTBEAT_SECONDS", 30)) * time.Second,
+		BrokerRetryMax:             time.Duration(getEnvInt("BROKER_RETRY_MAX_SECONDS", 60)) * time.Second,
+		BrokerDeregisterOnShutdown: getEnvBool("BROKER_DEREGISTER_ON_SHUTDOWN", true),
+
+		// Backend addresses
+		ServiceDiscovery:        getEnv("SERVICE_DISCOVERY", "static"),
+		DiscoveryServices:       getEnv("DISCOVERY_SERVICES", "api-beheerder=api-beheerder,central-mgmt=central-mgmt"),
+		DiscoveryRefresh:        time.Duration(getEnvInt("DISCOVERY_REFRESH_SECONDS", 60)) * time.Second,
+		DiscoveryFailureRefresh: time.Duration(getEnvInt("DISCOVERY_FAILURE_REFRESH_SECONDS", 5)) * time.Second,
+
+		// Background dependency probes
+		HealthProbeEnabled:          getEnvBool("HEALTH_PROBE_ENABLED", true),
+		HealthProbeInterval:         time.Duration(getEnvInt("HEALTH_PROBE_INTERVAL_SECONDS", 15)) * time.Second,
+		HealthProbeTimeout:          time.Duration(getEnvInt("HEALTH_PROBE_TIMEOUT_SECONDS", 3)) * time.Second,
+		HealthProbeFailureThreshold: getEnvInt("HEALTH_PROBE_FAILURE_THRESHOLD", 2),
+		HealthProbePaths:            getEnv("HEALTH_PROBE_PATHS", ""),
+
+		// Response caching
+		ResponseCacheEnabled:   getEnvBool("RESPONSE_CACHE_ENABLED", false),
+		ResponseCacheBackend:   getEnv("RESPONSE_CACHE_BACKEND", "memory"),
+		ResponseCacheTTL:       time.Duration(getEnvInt("RESPONSE_CACHE_TTL_SECONDS", 30)) * time.Second,
+		ResponseCacheRouteTTLs: getEnv("RESPONSE_CACHE_ROUTE_TTLS", ""),
+
+		// Background jobs
+		JobsEnabled:   getEnvBool("JOBS_ENABLED", true),
+		JobsStore:     getEnv("JOBS_STORE", "memory"),
+		JobsDir:       getEnv("JOBS_DIR", "data/jobs"),
+		JobsWorkers:   getEnvInt("JOBS_WORKERS", 4),
+		JobsQueueSize: getEnvInt("JOBS_QUEUE_SIZE", 100),
+		JobsTimeout:   time.Duration(getEnvInt("JOBS_TIMEOUT_SECONDS", 300)) * time.Second,
+		JobsRetention: time.Duration(getEnvInt("JOBS_RETENTION_HOURS", 24)) * time.Hour,
+
+		// Response compression
+		CompressionEnabled:       getEnvBool("COMPRESSION_ENABLED", true),
+		CompressionMinSize:       getEnvInt("COMPRESSION_MIN_SIZE", 1024),
+		CompressionLevel:         getEnvInt("COMPRESSION_LEVEL", 5),
+		CompressionTypes:         getEnv("COMPRESSION_TYPES", "application/json,application/problem+json,application/javascript,application/xml,image/svg+xml,text/*"),
+		CompressionDisabledPaths: getEnv("COMPRESSION_DISABLED_PATHS", ""),
+
+		// Field-level encryption
+		FieldEncryptionEnabled:   getEnvBool("FIELD_ENCRYPTION_ENABLED", false),
+		FieldEncryptionKeys:      getEnv("FIELD_ENCRYPTION_KEYS", ""),
+		FieldEncryptionActiveKey: getEnv("FIELD_ENCRYPTION_ACTIVE_KEY", ""),
+		FieldEncryptionSchema:    getEnv("FIELD_ENCRYPTION_SCHEMA", "guests=passport_number|payment.card_token;bookings=card_token"),
+
+		// Optional feature flags
+		FeatureWebSockets: getEnvBool("FEATURE_WEBSOCKETS", false),
+		FeatureTwoFactor:  getEnvBool("FEATURE_TWO_FACTOR", false),
+		FeaturePayments:   getEnvBool("FEATURE_PAYMENTS", false),
+		FeaturePartnerAPI: getEnvBool("FEATURE_PARTNER_API", false),
+		FeatureFlags:      getEnv("FEATURE_FLAGS", ""),
+		SupportedLocales:  getEnv("SUPPORTED_LOCALES", "en,nl"),
+
 		// CORS settings
-		UserPortalURL:  getEnv("USER_PORTAL_URL", "http://localhost:3000"),
-		AllowedOrigins: getEnv("CORS_ORIGINS", "http://localhost:3000,http://localhost:3001,https://hotel-portal.local"),
+		UserPortalURL:        getEnv("USER_PORTAL_URL", "http://localhost:3000"),
+		AllowedOrigins:       getEnvFor(environment, "CORS_ORIGINS", "http://localhost:3000,http://localhost:3001,https://hotel-portal.local"),
+		CORSAllowedMethods:   getEnvFor(environment, "CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,HEAD,OPTIONS"),
+		CORSAllowedHeaders:   getEnvFor(environment, "CORS_ALLOWED_HEADERS", "Origin,Content-Length,Content-Type,Authorization,X-Internal-API-Key,X-Request-ID,If-Match,If-None-Match,If-Modified-Since,X-CSRF-Token"),
+		CORSExposedHeaders:   getEnvFor(environment, "CORS_EXPOSED_HEADERS", "ETag,Last-Modified"),
+		CORSMaxAge:           time.Duration(getEnvInt(envKeyFor(environment, "CORS_MAX_AGE_SECONDS"), 43200)) * time.Second,
+		CORSAllowCredentials: getEnvBool(envKeyFor(environment, "CORS_ALLOW_CREDENTIALS"), true),
 
 		// Circuit breaker defaults
 		CircuitBreakerFailureThreshold: getEnvInt("CB_FAILURE_THRESHOLD", 5),
 		CircuitBreakerTimeout:          time.Duration(getEnvInt("CB_TIMEOUT_SECONDS", 60)) * time.Second,
 		CircuitBreakerMaxRetries:       getEnvInt("CB_MAX_RETRIES", 3),
 		CircuitBreakerRetryDelay:       time.Duration(getEnvInt("CB_RETRY_DELAY_MS", 1000)) * time.Millisecond,
+		CircuitBreakerHalfOpenProbes:   getEnvInt("CB_HALF_OPEN_MAX_PROBES", 1),
+		CircuitBreakerSuccessThreshold: getEnvInt("CB_SUCCESS_THRESHOLD", 3),
+		CircuitBreakerPolicy:           getEnv("CB_POLICY", "consecutive"),
+		CircuitBreakerServicePolicies:  getEnv("CB_SERVICE_POLICIES", ""),
+		CircuitBreakerErrorRate:        float64(getEnvInt("CB_ERROR_RATE_PERCENT", 50)),
+		CircuitBreakerWindow:           time.Duration(getEnvInt("CB_WINDOW_SECONDS", 60)) * time.Second,
+		CircuitBreakerMinCalls:         getEnvInt("CB_MIN_CALLS", 20),
+		CircuitBreakerFallback:         getEnvBool("CB_FALLBACK_ENABLED", false),
+		CircuitBreakerFallbackTTL:      time.Duration(getEnvInt("CB_FALLBACK_TTL_MINUTES", 60)) * time.Minute,
+		CircuitBreakerFallbackFile:     getEnv("CB_FALLBACK_PAYLOADS_FILE", ""),
+
+		ResilienceChain:         getEnv("RESILIENCE_CHAIN", "fallback,bulkhead,timeout,breaker"),
+		ResilienceServiceChains: getEnv("RESILIENCE_SERVICE_CHAINS", ""),
 
 		// Security settings
-		MaxRequestBodySize:    int64(getEnvInt("MAX_REQUEST_BODY_SIZE", 5*1024*1024)), // 5MB default
-		RequestTimeout:        time.Duration(getEnvInt("REQUEST_TIMEOUT_SECONDS", 30)) * time.Second,
-		ReadTimeout:           time.Duration(getEnvInt("READ_TIMEOUT_SECONDS", 15)) * time.Second,
-		WriteTimeout:          time.Duration(getEnvInt("WRITE_TIMEOUT_SECONDS", 15)) * time.Second,
-		IdleTimeout:           time.Duration(getEnvInt("IDLE_TIMEOUT_SECONDS", 60)) * time.Second,
-		EnableSecurityHeaders: getEnvBool("ENABLE_SECURITY_HEADERS", true),
-		EnableAuditLogging:    getEnvBool("ENABLE_AUDIT_LOGGING", true),
+		MaxRequestBodySize:     int64(getEnvInt("MAX_REQUEST_BODY_SIZE", 5*1024*1024)), // 5MB default
+		RequestTimeout:         time.Duration(getEnvInt("REQUEST_TIMEOUT_SECONDS", 30)) * time.Second,
+		ReadTimeout:            time.Duration(getEnvInt("READ_TIMEOUT_SECONDS", 15)) * time.Second,
+		WriteTimeout:           time.Duration(getEnvInt("WRITE_TIMEOUT_SECONDS", 15)) * time.Second,
+		IdleTimeout:            time.Duration(getEnvInt("IDLE_TIMEOUT_SECONDS", 60)) * time.Second,
+		EnableSecurityHeaders:  getEnvBool("ENABLE_SECURITY_HEADERS", true),
+		EnableAuditLogging:     getEnvBool("ENABLE_AUDIT_LOGGING", true),
+		AuditStoreCapacity:     getEnvInt("AUDIT_STORE_CAPACITY", 10000),
+		AuditStoreBackend:      getEnv("AUDIT_STORE_BACKEND", "memory"),
+		AuditStoreRetention:    time.Duration(getEnvInt("AUDIT_STORE_RETENTION_DAYS", 365)) * 24 * time.Hour,
+		AuditPIIFields:         getEnv("AUDIT_PII_FIELDS", "email,guest_email,phone,first_name,last_name,guest_name,date_of_birth,passport_number,address,card_token"),
+		AuditRedactFields:      getEnv("AUDIT_REDACT_FIELDS", "*password*,*token*,*secret*,api_key,authorization,card_number,cvv,cvc"),
+		AuditRedactCardNumbers: getEnvBool("AUDIT_REDACT_CARD_NUMBERS", true),
+		AuditCapturePolicy:     getEnv("AUDIT_CAPTURE_POLICY", "redacted"),
+		AuditCaptureRoutes:     getEnv("AUDIT_CAPTURE_ROUTES", ""),
+		AuditShipEnabled:       getEnvBool("AUDIT_SHIP_ENABLED", false),
+		AuditShipEndpoint:      getEnv("AUDIT_SHIP_ENDPOINT", "/audit-log"),
+		AuditShipBatchSize:     getEnvInt("AUDIT_SHIP_BATCH_SIZE", 100),
+		AuditShipFlushInterval: time.Duration(getEnvInt("AUDIT_SHIP_FLUSH_SECONDS", 5)) * time.Second,
+		AuditShipBufferSize:    getEnvInt("AUDIT_SHIP_BUFFER_SIZE", 10000),
+		AuditShipMaxAttempts:   getEnvInt("AUDIT_SHIP_MAX_ATTEMPTS", 5),
+		AuditShipOverflow:      getEnv("AUDIT_SHIP_OVERFLOW", "drop_oldest"),
+		AuditShipSpillFile:     getEnv("AUDIT_SHIP_SPILL_FILE", ""),
+		AuditAnchorInterval:    time.Duration(getEnvInt("AUDIT_ANCHOR_INTERVAL_MINUTES", 60)) * time.Minute,
+		AuditAnchorEndpoint:    getEnv("AUDIT_ANCHOR_ENDPOINT", "/audit-anchors"),
+		MiddlewareTraceEnabled: getEnvBool("MIDDLEWARE_TRACE_ENABLED", false),
+		LogFile:                getEnv("LOG_FILE", ""),
+		LogLevel:               getEnv("LOG_LEVEL", "INFO"),
+
+		// Response data masking settings
+		DataMaskingMode:   getEnv("DATA_MASKING_MODE", "auto"),
+		DataMaskingFields: getEnv("DATA_MASKING_FIELDS", "email:partial,guest_email:partial,phone:partial,first_name:fake,last_name:fake,guest_name:fake,date_of_birth:fake,passport_number:hash,address:fake,card_token:hash"),
+		DataMaskingSalt:   getEnv("DATA_MASKING_SALT", ""),
+
+		// OpenAPI schema validation settings
+		SchemaValidation:       getEnv("OPENAPI_VALIDATION", "request"),
+		SchemaValidationRoutes: getEnv("OPENAPI_VALIDATION_ROUTES", ""),
+
+		// API documentation settings
+		DocsEnabled: getEnvBool("DOCS_ENABLED", false),
+
+		// gRPC interface settings
+		GRPCEnabled: getEnvBool("GRPC_ENABLED", false),
+		GRPCPort:    getEnv("GRPC_PORT", "9090"),
+
+		// GraphQL settings
+		GraphQLEnabled:  getEnvBool("GRAPHQL_ENABLED", false),
+		GraphQLMaxDepth: getEnvInt("GRAPHQL_MAX_DEPTH", 6),
 
 		// Rate limiting settings
 		RateLimitEnabled:       getEnvBool("RATE_LIMIT_ENABLED", true),
@@ -93,6 +794,39 @@ func Load() *Config {
 		LoginRateLimitInterval: time.Duration(getEnvInt("LOGIN_RATE_LIMIT_INTERVAL_SECONDS", 300)) * time.Second, // 5 minutes
 		AdminRateLimitRequests: getEnvInt("ADMIN_RATE_LIMIT_REQUESTS", 50),
 		AdminRateLimitInterval: time.Duration(getEnvInt("ADMIN_RATE_LIMIT_INTERVAL_SECONDS", 60)) * time.Second,
+
+		// Login reputation settings
+		ReputationEnabled:          getEnvBool("REPUTATION_ENABLED", false),
+		ReputationBackend:          getEnv("REPUTATION_BACKEND", "memory"),
+		ReputationChallengeScore:   getEnvInt("REPUTATION_CHALLENGE_SCORE", 50),
+		ReputationBlockScore:       getEnvInt("REPUTATION_BLOCK_SCORE", 20),
+		ReputationFailurePenalty:   getEnvInt("REPUTATION_FAILURE_PENALTY", 10),
+		ReputationSuccessReward:    getEnvInt("REPUTATION_SUCCESS_REWARD", 20),
+		ReputationRecoveryInterval: time.Duration(getEnvInt("REPUTATION_RECOVERY_SECONDS", 60)) * time.Second,
+		ReputationChallengeURL:     getEnv("REPUTATION_CHALLENGE_URL", ""),
+
+		// Anonymous availability widget
+		PublicAvailabilityEnabled:  getEnvBool("PUBLIC_AVAILABILITY_ENABLED", false),
+		PublicAvailabilityHotels:   getEnv("PUBLIC_AVAILABILITY_HOTELS", ""),
+		PublicAvailabilityCacheTTL: time.Duration(getEnvInt("PUBLIC_AVAILABILITY_CACHE_TTL_SECONDS", 300)) * time.Second,
+		PublicRateLimitRequests:    getEnvInt("PUBLIC_RATE_LIMIT_REQUESTS", 20),
+		PublicRateLimitInterval:    time.Duration(getEnvInt("PUBLIC_RATE_LIMIT_INTERVAL_SECONDS", 60)) * time.Second,
+		PublicBlockedUserAgents:    getEnv("PUBLIC_BLOCKED_USER_AGENTS", "curl,wget,python-requests,scrapy,headlesschrome"),
+		PublicChallengeURL:         getEnv("PUBLIC_CHALLENGE_URL", ""),
+		PublicWidgetOrigins:        getEnv("PUBLIC_WIDGET_ORIGINS", ""),
+
+		// Account lockout settings
+		LockoutEnabled:     getEnvBool("LOCKOUT_ENABLED", true),
+		LockoutMaxFailures: getEnvInt("LOCKOUT_MAX_FAILURES", 5),
+		LockoutWindow:      time.Duration(getEnvInt("LOCKOUT_WINDOW_SECONDS", 900)) * time.Second,
+		LockoutDuration:    time.Duration(getEnvInt("LOCKOUT_DURATION_SECONDS", 900)) * time.Second,
+
+		// Usage reporting settings
+		UsageTrackingEnabled:     getEnvBool("USAGE_TRACKING_ENABLED", true),
+		UsageMonthlyQuota:        int64(getEnvInt("USAGE_MONTHLY_QUOTA", 0)),
+		UsageQuotas:              getEnv("USAGE_QUOTAS", ""),
+		UsageRetention:           time.Duration(getEnvInt("USAGE_RETENTION_DAYS", 100)) * 24 * time.Hour,
+		UsageAggregationInterval: time.Duration(getEnvInt("USAGE_AGGREGATION_INTERVAL_SECONDS", 60)) * time.Second,
 	}
 }
 
@@ -118,28 +852,78 @@ func (c *Config) GetCentralMgmtKey() string {
 
 // getEnv gets an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+	noteKey(key)
+	if value, ok := lookupSetting(key); ok {
 		return value
 	}
 	return defaultValue
 }
 
+// lookupSetting returns the value of a setting from the first source that
+// sets it: the secrets backend, Central Management when it has precedence,
+// the environment (including CONFIG_FILE), then Central Management
+func lookupSetting(key string) (string, bool) {
+	if value, ok := secretValue(key); ok {
+		return value, true
+	}
+	remote, remoteOK, remoteFirst := remoteValue(key)
+	if remoteOK && remoteFirst {
+		return remote, true
+	}
+	if value := os.Getenv(key); value != "" {
+		return value, true
+	}
+	return remote, remoteOK
+}
+
+// getEnvFor is getEnv with a per-environment override: KEY_<APP_ENV> wins
+// over KEY, e.g. CORS_ORIGINS_PRODUCTION over CORS_ORIGINS
+func getEnvFor(environment, key, defaultValue string) string {
+	return getEnv(envKeyFor(environment, key), defaultValue)
+}
+
+// envKeyFor returns KEY_<APP_ENV> when that variable is set, else key
+func envKeyFor(environment, key string) string {
+	notePerEnvKey(key)
+	override := key + "_" + strings.ToUpper(environment)
+	if _, ok := lookupSetting(override); ok {
+		return override
+	}
+	return key
+}
+
 // getEnvInt gets an environment variable as int or returns a default value
 func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
+	noteKey(key)
+	if value, ok := lookupSetting(key); ok {
 		if intValue, err := strconv.Atoi(value); err == nil {
 			return intValue
 		}
+		noteInvalid(key, value, "a whole number")
+	}
+	return defaultValue
+}
+
+// getEnvFloat gets an environment variable as float or returns a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	noteKey(key)
+	if value, ok := lookupSetting(key); ok {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+		noteInvalid(key, value, "a number")
 	}
 	return defaultValue
 }
 
 // getEnvBool gets an environment variable as bool or returns a default value
 func getEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
+	noteKey(key)
+	if value, ok := lookupSetting(key); ok {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
 			return boolValue
 		}
+		noteInvalid(key, value, "true or false")
 	}
 	return defaultValue
 }
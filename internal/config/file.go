@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+var (
+	fileMu sync.Mutex
+	// fileKeys are the variables the config file set because the
+	// environment did not, so keys removed from the file can be unset again
+	fileKeys = map[string]bool{}
+)
+
+// ApplyFile sets the settings of a config file in the process environment,
+// where Load picks them up. YAML (.yaml, .yml) and TOML (.toml) files are
+// structured; any other file is read as KEY=VALUE lines in the format of
+// .env.example. Variables set in the environment win over the file. Keys
+// removed since the previous call are unset again.
+func ApplyFile(path string) error {
+	var values map[string]string
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".toml":
+		values, err = readStructuredFile(path)
+	default:
+		values, err = readEnvFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	for key := range fileKeys {
+		if _, ok := values[key]; !ok {
+			os.Unsetenv(key)
+			delete(fileKeys, key)
+		}
+	}
+	for key, value := range values {
+		if !fileKeys[key] && os.Getenv(key) != "" {
+			continue
+		}
+		fileKeys[key] = true
+		os.Setenv(key, value)
+	}
+	return nil
+}
+
+// readStructuredFile reads a YAML or TOML file into settings by variable
+// name. Nested keys join with underscores, so rate_limit: {requests: 100}
+// sets RATE_LIMIT_REQUESTS, and lists become comma-separated values.
+// Unknown settings are rejected.
+func readStructuredFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := map[string]interface{}{}
+	if strings.ToLower(filepath.Ext(path)) == ".toml" {
+		err = toml.Unmarshal(data, &tree)
+	} else {
+		err = yaml.Unmarshal(data, &tree)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	values := make(map[string]string)
+	var problems []string
+	flattenSettings("", tree, values, &problems)
+	for key := range values {
+		if !isKnownKey(key) {
+			problems = append(problems, "unknown setting "+key)
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("%s: %s", path, strings.Join(problems, "; "))
+	}
+	return values, nil
+}
+
+// flattenSettings adds the settings below a table of a structured file to
+// values, recording duplicate keys and unsupported values in problems
+func flattenSettings(prefix string, table map[string]interface{}, values map[string]string, problems *[]string) {
+	for name, value := range table {
+		key := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flattenSettings(key, v, values, problems)
+			continue
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				text, ok := settingText(item)
+				if !ok {
+					*problems = append(*problems, key+" may only list plain values")
+				}
+				items = append(items, text)
+			}
+			value = strings.Join(items, ",")
+		}
+
+		text, ok := settingText(value)
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s has an unsupported value %v", key, value))
+			continue
+		}
+		if _, exists := values[key]; exists {
+			*problems = append(*problems, key+" is set twice")
+			continue
+		}
+		values[key] = text
+	}
+}
+
+// settingText formats a plain value of a structured file as an
+// environment variable value
+func settingText(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int, int64, uint64:
+		return fmt.Sprint(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// readEnvFile parses KEY=VALUE lines. Blank lines, # comments, an export
+// prefix, quotes around values and trailing " # comments" after unquoted
+// values are allowed.
+func readEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, number)
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
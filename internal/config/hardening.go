@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// minSecretLength is the shortest JWT secret hardened mode accepts
+const minSecretLength = 32
+
+// defaultSecrets maps settings to the development defaults that must never reach production
+var defaultSecrets = map[string]string{
+	"JWT_SECRET":        "your-jwt-secret-key",
+	"API_BEHEERDER_KEY": "beheerder-service-key",
+	"CENTRAL_MGMT_KEY":  "central-mgmt-service-key",
+}
+
+// HardeningViolations lists every production requirement the configuration
+// does not meet. Hardened mode refuses to start unless the list is empty.
+func (c *Config) HardeningViolations() []string {
+	var violations []string
+
+	secrets := map[string]string{
+		"JWT_SECRET":        c.JWTSecret,
+		"API_BEHEERDER_KEY": c.APIBeheerderKey,
+		"CENTRAL_MGMT_KEY":  c.CentralMgmtKey,
+	}
+	for _, name := range []string{"JWT_SECRET", "API_BEHEERDER_KEY", "CENTRAL_MGMT_KEY"} {
+		if secrets[name] == defaultSecrets[name] {
+			violations = append(violations, name+" is still the development default")
+		}
+	}
+	if len(c.JWTSecret) < minSecretLength {
+		violations = append(violations, fmt.Sprintf("JWT_SECRET must be at least %d characters", minSecretLength))
+	}
+	for _, previous := range splitList(c.JWTPreviousSecrets) {
+		if len(previous) < minSecretLength {
+			violations = append(violations, fmt.Sprintf("JWT_PREVIOUS_SECRETS entries must be at least %d characters", minSecretLength))
+			break
+		}
+	}
+
+	// Transport
+	if c.TLSMode == "" || c.TLSMode == "off" {
+		violations = append(violations, "TLS_MODE must be files or acme")
+	}
+	if c.HSTSMaxAge <= 0 {
+		violations = append(violations, "HSTS_MAX_AGE_SECONDS must be positive")
+	}
+	if !strings.HasPrefix(c.APIBeheerderURL, "https://") {
+		violations = append(violations, "API_BEHEERDER_URL must use https")
+	}
+	if !strings.HasPrefix(c.CentralMgmtURL, "https://") {
+		violations = append(violations, "CENTRAL_MGMT_URL must use https")
+	}
+	if c.EnableCookieAuth && !c.CookieSecure {
+		violations = append(violations, "COOKIE_SECURE must be true when cookie auth is enabled")
+	}
+
+	// Security middleware
+	if !c.EnableSecurityHeaders {
+		violations = append(violations, "ENABLE_SECURITY_HEADERS must be true")
+	}
+	if !c.EnableAuditLogging {
+		violations = append(violations, "ENABLE_AUDIT_LOGGING must be true")
+	}
+	if strings.EqualFold(strings.TrimSpace(c.AuditCapturePolicy), "none") {
+		violations = append(violations, "AUDIT_CAPTURE_POLICY must not be none")
+	}
+
+	// Debug facilities
+	if c.MiddlewareTraceEnabled {
+		violations = append(violations, "MIDDLEWARE_TRACE_ENABLED must be false")
+	}
+	if c.DebugEndpointsEnabled {
+		violations = append(violations, "DEBUG_ENDPOINTS_ENABLED must be false")
+	}
+	if c.DocsEnabled {
+		violations = append(violations, "DOCS_ENABLED must be false")
+	}
+
+	// Prometheus endpoint
+	if c.MetricsBasicAuthUser == "" && c.MetricsAPIKey == "" {
+		violations = append(violations, "METRICS_BASIC_AUTH_USER or METRICS_API_KEY must protect /metrics")
+	}
+	if c.MetricsBasicAuthUser != "" && len(c.MetricsBasicAuthPassword) < minSecretLength {
+		violations = append(violations, fmt.Sprintf("METRICS_BASIC_AUTH_PASSWORD must be at least %d characters", minSecretLength))
+	}
+
+	// CORS
+	origins := c.CORSOrigins()
+	if len(origins) == 0 {
+		violations = append(violations, "CORS_ORIGINS must list the allowed origins")
+	}
+	for _, origin := range origins {
+		if origin == "*" {
+			violations = append(violations, "CORS_ORIGINS must not contain a wildcard")
+		} else if !strings.HasPrefix(origin, "https://") {
+			violations = append(violations, "CORS origin "+origin+" must use https")
+		}
+	}
+
+	for _, origin := range splitList(c.PublicWidgetOrigins) {
+		if !strings.HasPrefix(origin, "https://") {
+			violations = append(violations, "PUBLIC_WIDGET_ORIGINS origin "+origin+" must use https")
+		}
+	}
+
+	return violations
+}
+
+// CORSOrigins returns the configured CORS origins
+func (c *Config) CORSOrigins() []string {
+	return splitList(c.AllowedOrigins)
+}
+
+// splitList splits a comma-separated setting, dropping blank entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// secretMask replaces secret values in Masked
+const secretMask = "********"
+
+// secretFields hold keys, passwords and other credentials. Keep it in sync
+// when a secret setting is added.
+var secretFields = map[string]bool{
+	"JWTSecret":                true,
+	"JWTPreviousSecrets":       true,
+	"CursorSecret":             true,
+	"OIDCClientSecret":         true,
+	"OIDCStateSecret":          true,
+	"InternalAPIKeys":          true,
+	"APIBeheerderKey":          true,
+	"CentralMgmtKey":           true,
+	"PMSAdapterKeys":           true,
+	"UpstreamServiceKeys":      true,
+	"BeheerderWebhookSecret":   true,
+	"TracingHeaders":           true,
+	"MetricsBasicAuthPassword": true,
+	"MetricsAPIKey":            true,
+	"FieldEncryptionKeys":      true,
+	"DataMaskingSalt":          true,
+	"VaultToken":               true,
+	"AWSSecretAccessKey":       true,
+	"AWSSessionToken":          true,
+}
+
+// Masked returns every setting by field name, with secrets replaced by
+// asterisks (empty secrets stay empty, so unset ones show) and
+// credentials in URLs removed
+func (c *Config) Masked() map[string]interface{} {
+	values := make(map[string]interface{})
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		name := v.Type().Field(i).Name
+		value := v.Field(i).Interface()
+		if s, ok := value.(string); ok {
+			switch {
+			case secretFields[name] && s != "":
+				value = secretMask
+			case strings.Contains(s, "://"):
+				value = maskURLCredentials(s)
+			}
+		}
+		values[name] = value
+	}
+	return values
+}
+
+// maskURLCredentials masks the password, or a lone token, in each URL of a
+// comma-separated list
+func maskURLCredentials(value string) string {
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		u, err := url.Parse(strings.TrimSpace(part))
+		if err != nil || u.User == nil {
+			continue
+		}
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), "xxxxx")
+		} else {
+			u.User = url.User("xxxxx")
+		}
+		parts[i] = u.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// ChangedFields returns the names of the settings that differ between two
+// configurations
+func ChangedFields(previous, next *Config) []string {
+	var changed []string
+	a, b := reflect.ValueOf(previous).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < a.NumField(); i++ {
+		if !a.Type().Field(i).IsExported() {
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			changed = append(changed, a.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
+
+// CopyFields sets the named settings of c to their values in from
+func (c *Config) CopyFields(from *Config, names []string) {
+	dst, src := reflect.ValueOf(c).Elem(), reflect.ValueOf(from).Elem()
+	for _, name := range names {
+		if field := dst.FieldByName(name); field.IsValid() && field.CanSet() {
+			field.Set(src.FieldByName(name))
+		}
+	}
+}
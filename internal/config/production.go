@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProductionMode reports whether the gateway runs in production, which is
+// APP_ENV=production or GIN_MODE=release
+func (c *Config) ProductionMode() bool {
+	return c.Environment == "production" || c.GinMode == "release"
+}
+
+// ProductionProblems lists what keeps the configuration from running in
+// production: development default secrets, missing service keys and
+// timeouts that contradict each other. Production mode refuses to start, or
+// to reload, unless the list is empty; elsewhere the problems are logged.
+func (c *Config) ProductionProblems() []string {
+	var problems []string
+
+	// Secrets and service keys
+	secrets := map[string]string{
+		"JWT_SECRET":        c.JWTSecret,
+		"API_BEHEERDER_KEY": c.APIBeheerderKey,
+		"CENTRAL_MGMT_KEY":  c.CentralMgmtKey,
+	}
+	for _, name := range []string{"JWT_SECRET", "API_BEHEERDER_KEY", "CENTRAL_MGMT_KEY"} {
+		switch secrets[name] {
+		case "":
+			problems = append(problems, name+" is required")
+		case defaultSecrets[name]:
+			problems = append(problems, name+" is still the development default")
+		}
+	}
+	for _, previous := range splitList(c.JWTPreviousSecrets) {
+		if previous == defaultSecrets["JWT_SECRET"] {
+			problems = append(problems, "JWT_PREVIOUS_SECRETS still accepts the development default JWT secret")
+		}
+	}
+
+	// Timeouts. The server stops writing a response WRITE_TIMEOUT_SECONDS
+	// after the request was read, so backend calls and health probes that
+	// take longer can never be answered.
+	below := func(name string, value time.Duration, limitName string, limit time.Duration) {
+		if value >= limit {
+			problems = append(problems, fmt.Sprintf("%s (%v) must be below %s (%v)", name, value, limitName, limit))
+		}
+	}
+	below("UPSTREAM_TIMEOUT_SECONDS", c.UpstreamTimeout, "WRITE_TIMEOUT_SECONDS", c.WriteTimeout)
+	for _, entry := range splitList(c.UpstreamServiceTimeouts) {
+		service, value, _ := strings.Cut(entry, "=")
+		if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			below("UPSTREAM_SERVICE_TIMEOUTS "+strings.TrimSpace(service), time.Duration(seconds)*time.Second, "WRITE_TIMEOUT_SECONDS", c.WriteTimeout)
+		}
+	}
+	below("HEALTH_PROBE_TIMEOUT_SECONDS", c.HealthProbeTimeout, "WRITE_TIMEOUT_SECONDS", c.WriteTimeout)
+	below("ACCESS_TOKEN_TTL_MINUTES", c.AccessTokenTTL, "REFRESH_TOKEN_TTL_HOURS", c.RefreshTokenTTL)
+	below("TOKEN_RENEW_BEFORE_SECONDS", c.TokenRenewBefore, "ACCESS_TOKEN_TTL_MINUTES", c.AccessTokenTTL)
+	if c.SecretsProvider != "" && c.SecretsRefreshInterval > 0 {
+		below("SECRETS_TIMEOUT_SECONDS", c.SecretsTimeout, "SECRETS_REFRESH_MINUTES", c.SecretsRefreshInterval)
+	}
+
+	return problems
+}
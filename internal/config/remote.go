@@ -0,0 +1,233 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RemoteSource reads the settings Central Management keeps for the gateway.
+// The document may hold a settings object of settings by variable name,
+// nested like a YAML config file, and the features and limits of
+// Central Management's service config, see remoteFields.
+type RemoteSource interface {
+	Fetch(ctx context.Context) (map[string]interface{}, error)
+}
+
+// RemoteSync describes the latest fetch from Central Management
+type RemoteSync struct {
+	At         time.Time `json:"at"`
+	OK         bool      `json:"ok"`
+	Error      string    `json:"error,omitempty"`
+	Precedence string    `json:"precedence"`
+	Settings   []string  `json:"settings"`          // Settings Central Management provides
+	Ignored    []string  `json:"ignored,omitempty"` // Fields that are unknown or may not be set remotely
+}
+
+// remoteField translates a field of Central Management's service config
+// document to a setting
+type remoteField struct {
+	path    string                      // Dotted path in the document
+	setting string                      // Setting it provides
+	convert func(string) (string, bool) // Converts the value; nil keeps it
+}
+
+// remoteFields are the fields of Central Management's service config
+// document the gateway understands
+var remoteFields = []remoteField{
+	{path: "features.rateLimitEnabled", setting: "RATE_LIMIT_ENABLED"},
+	{path: "features.auditEnabled", setting: "ENABLE_AUDIT_LOGGING"},
+	{path: "features.cacheEnabled", setting: "RESPONSE_CACHE_ENABLED"},
+	{path: "limits.maxRequestsPerMinute", setting: "RATE_LIMIT_REQUESTS"},
+	{path: "limits.maxRequestsPerMinute", setting: "RATE_LIMIT_INTERVAL_SECONDS", convert: func(string) (string, bool) { return "60", true }},
+	{path: "limits.maxDataSize", setting: "MAX_REQUEST_BODY_SIZE", convert: parseByteSize},
+}
+
+// remoteMetadata are document fields that describe it rather than set anything
+var remoteMetadata = map[string]bool{"service": true, "version": true, "lastUpdated": true}
+
+// Settings Central Management may not change: secrets, and the settings
+// that decide how the gateway starts and reaches Central Management
+var (
+	remoteProtected         = []string{"APP_ENV", "GIN_MODE", "HARDENED_MODE", "HOST", "PORT"}
+	remoteProtectedPrefixes = []string{"CONFIG_", "CENTRAL_MGMT_", "SECRETS_", "VAULT_", "AWS_", "TLS_", "ACME_", "MTLS_"}
+)
+
+var (
+	remoteMu     sync.RWMutex
+	remoteValues map[string]string
+	remoteFirst  bool // Central Management wins over the environment and the config file
+	remoteLast   RemoteSync
+)
+
+// LoadRemote fetches the settings from Central Management and makes Load
+// use them. With precedence remote they win over the environment and the
+// config file; with local they only fill in settings neither sets. Values
+// from the secrets backend always win. It reports whether a setting
+// changed since the previous call. On error the settings in use stay.
+func LoadRemote(ctx context.Context, source RemoteSource, precedence string) (bool, error) {
+	status := RemoteSync{At: time.Now(), Precedence: precedence, Settings: []string{}}
+	document, err := source.Fetch(ctx)
+	var values map[string]string
+	if err == nil {
+		values, status.Ignored, err = remoteSettings(document)
+	}
+	if err != nil {
+		status.Error = err.Error()
+		remoteMu.Lock()
+		if remoteLast.OK {
+			status.Settings = remoteLast.Settings
+		}
+		remoteLast = status
+		remoteMu.Unlock()
+		return false, err
+	}
+	status.OK = true
+	for key := range values {
+		status.Settings = append(status.Settings, key)
+	}
+	sort.Strings(status.Settings)
+
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+	changed := len(values) != len(remoteValues) || remoteFirst != (precedence == "remote")
+	for key, value := range values {
+		if previous, ok := remoteValues[key]; !ok || previous != value {
+			changed = true
+		}
+	}
+	remoteValues = values
+	remoteFirst = precedence == "remote"
+	remoteLast = status
+	return changed, nil
+}
+
+// LastRemoteSync returns the latest fetch from Central Management
+func LastRemoteSync() RemoteSync {
+	remoteMu.RLock()
+	defer remoteMu.RUnlock()
+	return remoteLast
+}
+
+// remoteSettings translates a document into settings by variable name,
+// leaving out fields that are unknown or may not be set remotely
+func remoteSettings(document map[string]interface{}) (map[string]string, []string, error) {
+	values := make(map[string]string)
+	var ignored []string
+
+	for _, field := range remoteFields {
+		group, name, _ := strings.Cut(field.path, ".")
+		fields, _ := document[group].(map[string]interface{})
+		value, ok := fields[name]
+		if !ok {
+			continue
+		}
+		text, ok := settingText(value)
+		if ok && field.convert != nil {
+			text, ok = field.convert(text)
+		}
+		if !ok {
+			return nil, nil, fmt.Errorf("%s has an unsupported value %v", field.path, value)
+		}
+		values[field.setting] = text
+	}
+	for name, value := range document {
+		switch fields, _ := value.(map[string]interface{}); {
+		case name == "settings":
+		case name == "features" || name == "limits":
+			for field := range fields {
+				if !isRemoteField(name + "." + field) {
+					ignored = append(ignored, name+"."+field)
+				}
+			}
+		case !remoteMetadata[name]:
+			ignored = append(ignored, name)
+		}
+	}
+
+	// Explicit settings win over translated fields
+	if settings, ok := document["settings"].(map[string]interface{}); ok {
+		explicit := make(map[string]string)
+		var problems []string
+		flattenSettings("", settings, explicit, &problems)
+		if len(problems) > 0 {
+			sort.Strings(problems)
+			return nil, nil, fmt.Errorf("invalid settings: %s", strings.Join(problems, "; "))
+		}
+		for key, value := range explicit {
+			values[key] = value
+		}
+	} else if _, ok := document["settings"]; ok {
+		return nil, nil, fmt.Errorf("settings must be an object")
+	}
+
+	for key := range values {
+		if !remoteAllowed(key) || !isKnownKey(key) {
+			ignored = append(ignored, key)
+			delete(values, key)
+		}
+	}
+	sort.Strings(ignored)
+	return values, ignored, nil
+}
+
+// isRemoteField reports whether path is one of remoteFields
+func isRemoteField(path string) bool {
+	for _, field := range remoteFields {
+		if field.path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteAllowed reports whether Central Management may set key
+func remoteAllowed(key string) bool {
+	if isSecretSetting(key) {
+		return false
+	}
+	for _, protected := range remoteProtected {
+		if key == protected {
+			return false
+		}
+	}
+	for _, prefix := range remoteProtectedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// remoteValue returns the value Central Management gave for a setting and
+// whether it wins over the environment
+func remoteValue(key string) (string, bool, bool) {
+	remoteMu.RLock()
+	defer remoteMu.RUnlock()
+	value, ok := remoteValues[key]
+	return value, ok && value != "", remoteFirst
+}
+
+// parseByteSize converts a size such as 10MB or 512KB to bytes
+func parseByteSize(text string) (string, bool) {
+	text = strings.ToUpper(strings.TrimSpace(text))
+	multiplier := 1
+	for _, unit := range []struct {
+		suffix string
+		size   int
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if number, ok := strings.CutSuffix(text, unit.suffix); ok {
+			text, multiplier = strings.TrimSpace(number), unit.size
+			break
+		}
+	}
+	size, err := strconv.Atoi(text)
+	if err != nil || size <= 0 {
+		return "", false
+	}
+	return strconv.Itoa(size * multiplier), true
+}
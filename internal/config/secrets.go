@@ -0,0 +1,135 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SecretProvider reads the gateway's secrets from a secrets backend. The
+// secret is a JSON object of settings by variable name, such as
+// {"JWT_SECRET": "...", "CENTRAL_MGMT_KEY": "..."}.
+type SecretProvider interface {
+	Name() string
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// secretSettings are the settings a secrets backend may provide
+var secretSettings = []string{
+	"JWT_SECRET",
+	"JWT_PREVIOUS_SECRETS",
+	"CURSOR_SECRET",
+	"OIDC_CLIENT_SECRET",
+	"OIDC_STATE_SECRET",
+	"INTERNAL_API_KEYS",
+	"API_BEHEERDER_KEY",
+	"CENTRAL_MGMT_KEY",
+	"UPSTREAM_SERVICE_KEYS",
+	"BEHEERDER_WEBHOOK_SECRET",
+}
+
+var (
+	secretsMu    sync.RWMutex
+	secretValues map[string]string
+)
+
+// NewSecretProvider returns the secrets backend named by SECRETS_PROVIDER,
+// or nil when secrets come from the environment
+func NewSecretProvider(c *Config) (SecretProvider, error) {
+	client := &http.Client{Timeout: c.SecretsTimeout}
+	switch c.SecretsProvider {
+	case "":
+		return nil, nil
+	case "vault":
+		return &VaultProvider{
+			Addr:      strings.TrimSuffix(c.VaultAddr, "/"),
+			Token:     c.VaultToken,
+			Namespace: c.VaultNamespace,
+			Mount:     c.VaultMount,
+			Path:      c.SecretsPath,
+			Client:    client,
+		}, nil
+	case "aws":
+		return &AWSSecretsProvider{
+			Region:          c.AWSRegion,
+			SecretID:        c.SecretsPath,
+			AccessKeyID:     c.AWSAccessKeyID,
+			SecretAccessKey: c.AWSSecretAccessKey,
+			SessionToken:    c.AWSSessionToken,
+			Endpoint:        c.AWSSecretsEndpoint,
+			Client:          client,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown SECRETS_PROVIDER %q (expected vault or aws)", c.SecretsProvider)
+}
+
+// LoadSecrets fetches the secrets and makes Load prefer them over the
+// environment and the config file. It reports whether any secret changed
+// since the previous call. On error the secrets in use stay.
+func LoadSecrets(ctx context.Context, provider SecretProvider) (bool, error) {
+	values, err := provider.Fetch(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%s: %v", provider.Name(), err)
+	}
+	var unknown []string
+	for key := range values {
+		if !isSecretSetting(key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return false, fmt.Errorf("%s: not secret settings: %s", provider.Name(), strings.Join(unknown, ", "))
+	}
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	changed := len(values) != len(secretValues)
+	for key, value := range values {
+		if previous, ok := secretValues[key]; !ok || previous != value {
+			changed = true
+		}
+	}
+	secretValues = values
+	return changed, nil
+}
+
+// secretValue returns the value a secrets backend gave for a setting
+func secretValue(key string) (string, bool) {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	value, ok := secretValues[key]
+	return value, ok && value != ""
+}
+
+// isSecretSetting reports whether a secrets backend may provide key
+func isSecretSetting(key string) bool {
+	for _, setting := range secretSettings {
+		if key == setting {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeSecret parses a secret holding a JSON object of settings. Numbers
+// and booleans are accepted as their text.
+func decodeSecret(data []byte) (map[string]string, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("secret is not a JSON object: %v", err)
+	}
+	values := make(map[string]string, len(fields))
+	for key, value := range fields {
+		text, ok := settingText(value)
+		if !ok {
+			return nil, fmt.Errorf("secret field %s must be a string", key)
+		}
+		values[key] = text
+	}
+	return values, nil
+}
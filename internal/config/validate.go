@@ -0,0 +1,193 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// environments are the accepted APP_ENV values
+var environments = []string{"development", "staging", "production"}
+
+// externalKeys are settings read from the environment outside Load
+var externalKeys = []string{"BROKER_URL", "BROKER_AUTH_TOKEN"}
+
+var (
+	loadMu     sync.Mutex
+	loadIssues []string // Malformed values met by the running Load
+
+	keysMu      sync.Mutex
+	knownKeys   = map[string]bool{}
+	perEnvKeys  = map[string]bool{} // Keys that also take a _<APP_ENV> suffix
+	knownLoaded sync.Once
+)
+
+// noteKey records a setting Load reads
+func noteKey(key string) {
+	keysMu.Lock()
+	knownKeys[key] = true
+	keysMu.Unlock()
+}
+
+// notePerEnvKey records a setting that can be overridden per APP_ENV
+func notePerEnvKey(key string) {
+	keysMu.Lock()
+	perEnvKeys[key] = true
+	keysMu.Unlock()
+}
+
+// noteInvalid records a value that could not be parsed; callers hold loadMu
+func noteInvalid(key, value, expected string) {
+	loadIssues = append(loadIssues, fmt.Sprintf("%s must be %s, got %q", key, expected, value))
+}
+
+// isKnownKey reports whether key is a setting of the gateway, including
+// per-environment overrides such as CORS_ORIGINS_PRODUCTION
+func isKnownKey(key string) bool {
+	knownLoaded.Do(func() { Load() })
+
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	if knownKeys[key] {
+		return true
+	}
+	for _, external := range externalKeys {
+		if key == external {
+			return true
+		}
+	}
+	for _, environment := range environments {
+		if base, ok := strings.CutSuffix(key, "_"+strings.ToUpper(environment)); ok && perEnvKeys[base] {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate lists every problem with the configuration: malformed values,
+// missing required settings and values out of range. The gateway refuses
+// to start, or to reload, unless the list is empty.
+func (c *Config) Validate() []string {
+	problems := append([]string(nil), c.issues...)
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	// Server
+	port, err := strconv.Atoi(c.Port)
+	check(err == nil && port > 0 && port <= 65535, "PORT must be a port number from 1 to 65535, got %q", c.Port)
+	check(oneOf(c.Environment, environments...), "APP_ENV must be one of %s, got %q", strings.Join(environments, ", "), c.Environment)
+	check(oneOf(strings.ToLower(c.LogLevel), "trace", "debug", "info", "warn", "warning", "error", "fatal", "panic"), "LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel)
+	check(c.RequestTimeout > 0, "REQUEST_TIMEOUT_SECONDS must be positive")
+	check(c.ReadTimeout > 0, "READ_TIMEOUT_SECONDS must be positive")
+	check(c.WriteTimeout > 0, "WRITE_TIMEOUT_SECONDS must be positive")
+	check(c.IdleTimeout > 0, "IDLE_TIMEOUT_SECONDS must be positive")
+	check(c.ConfigWatchInterval >= 0, "CONFIG_WATCH_INTERVAL_SECONDS must not be negative")
+	if c.ConfigSyncEnabled {
+		check(c.ConfigSyncService != "", "CONFIG_SYNC_SERVICE is required when CONFIG_SYNC_ENABLED=true")
+		check(c.ConfigSyncInterval >= 0, "CONFIG_SYNC_INTERVAL_SECONDS must not be negative")
+		check(c.ConfigSyncTimeout > 0, "CONFIG_SYNC_TIMEOUT_SECONDS must be positive")
+		check(oneOf(c.ConfigSyncPrecedence, "remote", "local"), "CONFIG_SYNC_PRECEDENCE must be remote or local, got %q", c.ConfigSyncPrecedence)
+	}
+
+	// TLS
+	switch c.TLSMode {
+	case "off":
+	case "files":
+		check(c.TLSCertFile != "" && c.TLSKeyFile != "", "TLS_CERT_FILE and TLS_KEY_FILE are required when TLS_MODE=files")
+	case "acme":
+		check(len(splitList(c.ACMEDomains)) > 0, "ACME_DOMAINS is required when TLS_MODE=acme")
+	default:
+		problems = append(problems, fmt.Sprintf("TLS_MODE must be off, files or acme, got %q", c.TLSMode))
+	}
+
+	// Authentication and backends
+	check(c.JWTSecret != "", "JWT_SECRET is required")
+	switch c.SecretsProvider {
+	case "":
+	case "vault":
+		check(c.VaultToken != "", "VAULT_TOKEN is required when SECRETS_PROVIDER=vault")
+		check(c.SecretsPath != "", "SECRETS_PATH is required when SECRETS_PROVIDER=vault")
+	case "aws":
+		check(c.AWSRegion != "", "AWS_REGION is required when SECRETS_PROVIDER=aws")
+		check(c.AWSAccessKeyID != "" && c.AWSSecretAccessKey != "", "AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required when SECRETS_PROVIDER=aws")
+		check(c.SecretsPath != "", "SECRETS_PATH is required when SECRETS_PROVIDER=aws")
+	default:
+		problems = append(problems, fmt.Sprintf("SECRETS_PROVIDER must be vault or aws, got %q", c.SecretsProvider))
+	}
+	check(c.SecretsRefreshInterval >= 0, "SECRETS_REFRESH_MINUTES must not be negative")
+	check(c.SecretsTimeout > 0, "SECRETS_TIMEOUT_SECONDS must be positive")
+	for name, value := range map[string]string{"API_BEHEERDER_URL": c.APIBeheerderURL, "CENTRAL_MGMT_URL": c.CentralMgmtURL} {
+		u, err := url.Parse(value)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "%s must be an http or https URL, got %q", name, value)
+	}
+
+	// Broker registration
+	check(c.BrokerHeartbeatInterval >= 0, "BROKER_HEARTBEAT_SECONDS must not be negative")
+	check(c.BrokerRetryMax >= time.Second, "BROKER_RETRY_MAX_SECONDS must be at least 1")
+	check(oneOf(c.ServiceDiscovery, "static", "broker"), "SERVICE_DISCOVERY must be static or broker, got %q", c.ServiceDiscovery)
+	if c.ServiceDiscovery == "broker" {
+		check(len(splitList(c.DiscoveryServices)) > 0, "DISCOVERY_SERVICES is required when SERVICE_DISCOVERY=broker")
+		check(c.DiscoveryRefresh >= 0, "DISCOVERY_REFRESH_SECONDS must not be negative")
+		check(c.DiscoveryFailureRefresh >= 0, "DISCOVERY_FAILURE_REFRESH_SECONDS must not be negative")
+	}
+
+	// Rate limits
+	limits := []struct {
+		prefix   string
+		requests int
+		interval time.Duration
+	}{
+		{"RATE_LIMIT", c.RateLimitRequests, c.RateLimitInterval},
+		{"LOGIN_RATE_LIMIT", c.LoginRateLimitRequests, c.LoginRateLimitInterval},
+		{"ADMIN_RATE_LIMIT", c.AdminRateLimitRequests, c.AdminRateLimitInterval},
+		{"PUBLIC_RATE_LIMIT", c.PublicRateLimitRequests, c.PublicRateLimitInterval},
+	}
+	for _, limit := range limits {
+		check(limit.requests > 0, "%s_REQUESTS must be positive", limit.prefix)
+		check(limit.interval > 0, "%s_INTERVAL_SECONDS must be positive", limit.prefix)
+	}
+
+	// Circuit breakers
+	check(c.CircuitBreakerFailureThreshold > 0, "CB_FAILURE_THRESHOLD must be positive")
+	check(c.CircuitBreakerTimeout > 0, "CB_TIMEOUT_SECONDS must be positive")
+	check(c.CircuitBreakerMaxRetries >= 0, "CB_MAX_RETRIES must not be negative")
+	check(c.CircuitBreakerRetryDelay >= 0, "CB_RETRY_DELAY_MS must not be negative")
+	check(c.CircuitBreakerHalfOpenProbes > 0, "CB_HALF_OPEN_MAX_PROBES must be positive")
+	check(c.CircuitBreakerSuccessThreshold > 0, "CB_SUCCESS_THRESHOLD must be positive")
+	check(oneOf(c.CircuitBreakerPolicy, "consecutive", "error_rate"), "CB_POLICY must be consecutive or error_rate, got %q", c.CircuitBreakerPolicy)
+	check(c.CircuitBreakerErrorRate > 0 && c.CircuitBreakerErrorRate <= 100, "CB_ERROR_RATE_PERCENT must be from 1 to 100")
+	check(c.CircuitBreakerWindow > 0, "CB_WINDOW_SECONDS must be positive")
+	check(c.CircuitBreakerMinCalls > 0, "CB_MIN_CALLS must be positive")
+
+	// CORS
+	check(c.CORSMaxAge >= 0, "CORS_MAX_AGE_SECONDS must not be negative")
+
+	// Audit shipping
+	if c.AuditShipEnabled {
+		check(c.AuditShipBatchSize > 0, "AUDIT_SHIP_BATCH_SIZE must be positive")
+		check(c.AuditShipFlushInterval > 0, "AUDIT_SHIP_FLUSH_SECONDS must be positive")
+		check(c.AuditShipBufferSize >= c.AuditShipBatchSize, "AUDIT_SHIP_BUFFER_SIZE must be at least AUDIT_SHIP_BATCH_SIZE")
+		check(c.AuditShipMaxAttempts > 0, "AUDIT_SHIP_MAX_ATTEMPTS must be positive")
+		check(oneOf(c.AuditShipOverflow, "drop_oldest", "drop_newest"), "AUDIT_SHIP_OVERFLOW must be drop_oldest or drop_newest, got %q", c.AuditShipOverflow)
+	}
+
+	sort.Strings(problems)
+	return problems
+}
+
+// oneOf reports whether value is one of the allowed values
+func oneOf(value string, allowed ...string) bool {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// VaultProvider reads secrets from a HashiCorp Vault KV version 2 engine
+type VaultProvider struct {
+	Addr      string // e.g. https://vault.hotel.com:8200
+	Token     string
+	Namespace string // Vault Enterprise namespace, optional
+	Mount     string // KV engine mount, e.g. secret
+	Path      string // Secret path below the mount, e.g. internal-api
+	Client    *http.Client
+}
+
+// Name identifies the provider in logs and errors
+func (p *VaultProvider) Name() string {
+	return "vault"
+}
+
+// Fetch reads the latest version of the secret
+func (p *VaultProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	endpoint := p.Addr + "/v1/" + url.PathEscape(p.Mount) + "/data/" + escapeSecretPath(p.Path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+	if p.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.Namespace)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reading %s/%s returned %d: %s", p.Mount, p.Path, resp.StatusCode, vaultErrors(body))
+	}
+
+	var secret struct {
+		Data struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("unexpected response: %v", err)
+	}
+	return decodeSecret(secret.Data.Data)
+}
+
+// escapeSecretPath escapes each segment of a slash-separated secret path
+func escapeSecretPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// vaultErrors returns the messages of a Vault error response
+func vaultErrors(body []byte) string {
+	var response struct {
+		Errors []string `json:"errors"`
+	}
+	if json.Unmarshal(body, &response) == nil && len(response.Errors) > 0 {
+		return strings.Join(response.Errors, "; ")
+	}
+	return strings.TrimSpace(string(body))
+}
@@ -0,0 +1,292 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// envelopePrefix marks values encrypted by this package: enc:v1:<key-id>:<base64 nonce+ciphertext>
+const envelopePrefix = "enc:v1:"
+
+// KeyProvider supplies encryption keys. Implementations can back onto a KMS,
+// Vault or static configuration.
+type KeyProvider interface {
+	// ActiveKey returns the key used for new encryptions
+	ActiveKey() (keyID string, key []byte, err error)
+	// Key returns the key for a key ID found on an encrypted value
+	Key(keyID string) ([]byte, error)
+}
+
+// StaticKeyProvider serves AES keys from configuration
+type StaticKeyProvider struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+// NewStaticKeyProvider parses a spec of "id:base64key" pairs separated by commas.
+// Keys must decode to 16, 24 or 32 bytes.
+func NewStaticKeyProvider(spec, activeID string) (*StaticKeyProvider, error) {
+	keys := make(map[string][]byte)
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid key entry %q, expected id:base64key", part)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 for key %s: %w", id, err)
+		}
+		if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+			return nil, fmt.Errorf("key %s must be 16, 24 or 32 bytes", id)
+		}
+		keys[id] = key
+	}
+
+	if _, exists := keys[activeID]; !exists {
+		return nil, fmt.Errorf("active key %q not found in key set", activeID)
+	}
+
+	return &StaticKeyProvider{activeID: activeID, keys: keys}, nil
+}
+
+// ActiveKey returns the key used for new encryptions
+func (p *StaticKeyProvider) ActiveKey() (string, []byte, error) {
+	return p.activeID, p.keys[p.activeID], nil
+}
+
+// Key returns a key by ID
+func (p *StaticKeyProvider) Key(keyID string) ([]byte, error) {
+	key, exists := p.keys[keyID]
+	if !exists {
+		return nil, fmt.Errorf("unknown encryption key: %s", keyID)
+	}
+	return key, nil
+}
+
+// Schema maps a resource name to the dotted field paths that must be encrypted
+type Schema map[string][]string
+
+// ParseSchema parses a spec like "guests=passport_number|payment.card_token;bookings=card_token"
+func ParseSchema(spec string) Schema {
+	schema := make(Schema)
+
+	for _, part := range strings.Split(spec, ";") {
+		resource, fields, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		for _, field := range strings.Split(fields, "|") {
+			if field = strings.TrimSpace(field); field != "" {
+				schema[resource] = append(schema[resource], field)
+			}
+		}
+	}
+
+	return schema
+}
+
+// FieldEncryptor encrypts and decrypts configured fields with AES-GCM
+type FieldEncryptor struct {
+	keys   KeyProvider
+	schema Schema
+}
+
+// NewFieldEncryptor creates a field encryptor
+func NewFieldEncryptor(keys KeyProvider, schema Schema) *FieldEncryptor {
+	return &FieldEncryptor{keys: keys, schema: schema}
+}
+
+// EncryptValue encrypts a single string into an envelope
+func (fe *FieldEncryptor) EncryptValue(plaintext string) (string, error) {
+	keyID, key, err := fe.keys.ActiveKey()
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(keyID))
+	return envelopePrefix + keyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptValue reverses EncryptValue; values without an envelope are returned unchanged
+func (fe *FieldEncryptor) DecryptValue(value string) (string, error) {
+	if !strings.HasPrefix(value, envelopePrefix) {
+		return value, nil
+	}
+
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(value, envelopePrefix), ":")
+	if !ok {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	key, err := fe.keys.Key(keyID)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// EncryptPayload encrypts the schema fields for a resource in an outbound
+// payload. Payloads for resources without configured fields are returned as-is.
+func (fe *FieldEncryptor) EncryptPayload(resource string, data interface{}) (interface{}, error) {
+	fields := fe.schema[resource]
+	if len(fields) == 0 || data == nil {
+		return data, nil
+	}
+
+	// Normalize structs into generic JSON values so fields can be addressed by name
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		if err := applyToDocument(doc, strings.Split(field, "."), fe.EncryptValue); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// DecryptResponse decrypts the schema fields for a resource in a backend response in place
+func (fe *FieldEncryptor) DecryptResponse(resource string, response map[string]interface{}) error {
+	for _, field := range fe.schema[resource] {
+		if err := applyToDocument(response, strings.Split(field, "."), fe.DecryptValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyToDocument applies fn at the path from the document root and from each
+// top-level value, so both bare objects and envelopes like {"guest": {...}} or
+// {"guests": [...]} are covered
+func applyToDocument(doc interface{}, path []string, fn func(string) (string, error)) error {
+	if err := applyAtPath(doc, path, fn); err != nil {
+		return err
+	}
+	if root, ok := doc.(map[string]interface{}); ok {
+		for key, value := range root {
+			if key == path[0] {
+				continue
+			}
+			if err := applyAtPath(value, path, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyAtPath walks objects along path, fanning out over arrays, and replaces
+// string leaves with fn's result
+func applyAtPath(node interface{}, path []string, fn func(string) (string, error)) error {
+	switch n := node.(type) {
+	case []interface{}:
+		for _, elem := range n {
+			if err := applyAtPath(elem, path, fn); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		value, exists := n[path[0]]
+		if !exists {
+			return nil
+		}
+		if len(path) > 1 {
+			return applyAtPath(value, path[1:], fn)
+		}
+		if s, ok := value.(string); ok && s != "" {
+			transformed, err := fn(s)
+			if err != nil {
+				return err
+			}
+			n[path[0]] = transformed
+		}
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Global field encryptor
+var (
+	fieldEncryptor *FieldEncryptor
+	encryptorMu    sync.RWMutex
+)
+
+// Init sets the global field encryptor
+func Init(keys KeyProvider, schema Schema) {
+	encryptorMu.Lock()
+	defer encryptorMu.Unlock()
+	fieldEncryptor = NewFieldEncryptor(keys, schema)
+}
+
+// EncryptPayload encrypts outbound fields using the global encryptor, if configured
+func EncryptPayload(resource string, data interface{}) (interface{}, error) {
+	encryptorMu.RLock()
+	defer encryptorMu.RUnlock()
+	if fieldEncryptor == nil {
+		return data, nil
+	}
+	return fieldEncryptor.EncryptPayload(resource, data)
+}
+
+// DecryptResponse decrypts response fields using the global encryptor, if configured
+func DecryptResponse(resource string, response map[string]interface{}) error {
+	encryptorMu.RLock()
+	defer encryptorMu.RUnlock()
+	if fieldEncryptor == nil {
+		return nil
+	}
+	return fieldEncryptor.DecryptResponse(resource, response)
+}
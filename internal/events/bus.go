@@ -0,0 +1,85 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is a domain event published on the internal bus
+type Event struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	Source     string                 `json:"source"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+}
+
+// RecipientField in an event's data names the only user whose event
+// streams may receive it
+const RecipientField = "recipient_id"
+
+// Handler processes a published event. Returning an error tells the
+// publisher that delivery failed and may be retried.
+type Handler func(event Event) error
+
+// Bus delivers events synchronously to subscribers of their type
+type Bus struct {
+	handlers map[string][]Handler
+	mu       sync.RWMutex
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe registers a handler for an event type; "*" receives every event
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish delivers an event to every matching subscriber and returns the
+// first error. All subscribers are called even when one fails.
+func (b *Bus) Publish(event Event) error {
+	b.mu.RLock()
+	handlers := append([]Handler{}, b.handlers[event.Type]...)
+	handlers = append(handlers, b.handlers["*"]...)
+	b.mu.RUnlock()
+
+	var firstErr error
+	for _, handler := range handlers {
+		if err := handler(event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+var defaultBus = NewBus()
+
+// Subscribe registers a handler on the default bus
+func Subscribe(eventType string, handler Handler) {
+	defaultBus.Subscribe(eventType, handler)
+}
+
+// Publish delivers an event on the default bus
+func Publish(event Event) error {
+	return defaultBus.Publish(event)
+}
+
+// adminBus carries operational events for admin dashboards: audit entries,
+// circuit breaker transitions and health changes. They are kept off the
+// default bus so domain subscribers and staff streams never see them.
+var adminBus = NewBus()
+
+// SubscribeAdmin registers a handler on the admin bus
+func SubscribeAdmin(eventType string, handler Handler) {
+	adminBus.Subscribe(eventType, handler)
+}
+
+// PublishAdmin delivers an operational event on the admin bus
+func PublishAdmin(event Event) error {
+	return adminBus.Publish(event)
+}
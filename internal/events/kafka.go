@@ -0,0 +1,465 @@
+package events
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Kafka API keys and the versions the driver speaks. Produce v3 is the
+// oldest version Kafka 4 accepts and the first with v2 record batches.
+const (
+	kafkaProduceKey      = 0
+	kafkaProduceVersion  = 3
+	kafkaMetadataKey     = 3
+	kafkaMetadataVersion = 4
+)
+
+// maxKafkaPartitions bounds partition indexes accepted from metadata
+const maxKafkaPartitions = 1 << 16
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// errNoBrokers is returned when no bootstrap broker answers
+var errNoBrokers = errors.New("no kafka broker reachable")
+
+// kafkaDriver produces to plaintext Kafka listeners. Messages are spread
+// over a topic's partitions by a hash of the event type and sent to each
+// partition's leader with acks=all; the leader's answer is the delivery
+// confirmation.
+type kafkaDriver struct {
+	bootstrap []string
+	timeout   time.Duration
+
+	mu         sync.Mutex
+	brokers    map[int32]string      // Node ID to address, from metadata
+	partitions map[string][]int32    // Topic to leader per partition
+	conns      map[string]*kafkaConn // Address to open connection
+}
+
+// kafkaConn is one broker connection; requests on it are sequential
+type kafkaConn struct {
+	conn          net.Conn
+	reader        *bufio.Reader
+	correlationID int32
+}
+
+// newKafkaDriver creates a driver for host:port bootstrap brokers
+func newKafkaDriver(bootstrap []string, timeout time.Duration) *kafkaDriver {
+	return &kafkaDriver{
+		bootstrap: bootstrap,
+		timeout:   timeout,
+		conns:     make(map[string]*kafkaConn),
+	}
+}
+
+// Name identifies the driver in logs
+func (d *kafkaDriver) Name() string {
+	return "kafka"
+}
+
+// Send produces a batch of messages. Metadata and connections are dropped
+// after any failure, so the next call starts from the bootstrap brokers.
+func (d *kafkaDriver) Send(msgs []Message) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.send(msgs); err != nil {
+		d.resetLocked()
+		return err
+	}
+	return nil
+}
+
+// send groups messages per leader, topic and partition and produces them
+func (d *kafkaDriver) send(msgs []Message) error {
+	// leader -> topic -> partition -> messages
+	batches := make(map[int32]map[string]map[int32][]Message)
+	for _, msg := range msgs {
+		leaders, err := d.leaders(msg.Topic)
+		if err != nil {
+			return err
+		}
+		hash := fnv.New32a()
+		hash.Write([]byte(msg.Key))
+		partition := int32(hash.Sum32() % uint32(len(leaders)))
+		leader := leaders[partition]
+		if leader < 0 {
+			return fmt.Errorf("kafka partition %s/%d has no leader", msg.Topic, partition)
+		}
+
+		if batches[leader] == nil {
+			batches[leader] = make(map[string]map[int32][]Message)
+		}
+		if batches[leader][msg.Topic] == nil {
+			batches[leader][msg.Topic] = make(map[int32][]Message)
+		}
+		batches[leader][msg.Topic][partition] = append(batches[leader][msg.Topic][partition], msg)
+	}
+
+	for leader, topics := range batches {
+		addr, known := d.brokers[leader]
+		if !known {
+			return fmt.Errorf("kafka broker %d is not in the cluster metadata", leader)
+		}
+		if err := d.produce(addr, topics); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// leaders returns the leader of every partition of a topic, fetching
+// metadata when the topic is not cached
+func (d *kafkaDriver) leaders(topic string) ([]int32, error) {
+	if leaders, cached := d.partitions[topic]; cached {
+		return leaders, nil
+	}
+
+	var lastErr error = errNoBrokers
+	for _, addr := range d.bootstrap {
+		if err := d.fetchMetadata(addr, topic); err != nil {
+			lastErr = err
+			continue
+		}
+		return d.partitions[topic], nil
+	}
+	return nil, lastErr
+}
+
+// fetchMetadata loads the brokers and the partition leaders of a topic
+func (d *kafkaDriver) fetchMetadata(addr, topic string) error {
+	var req kafkaEncoder
+	req.int32(1)
+	req.string(topic)
+	req.int8(0) // allow_auto_topic_creation
+
+	resp, err := d.roundTrip(addr, kafkaMetadataKey, kafkaMetadataVersion, req.buf)
+	if err != nil {
+		return err
+	}
+
+	dec := kafkaDecoder{buf: resp}
+	dec.int32() // throttle_time_ms
+	brokers := make(map[int32]string)
+	for i, n := 0, dec.arrayLen(); i < n; i++ {
+		nodeID := dec.int32()
+		host := dec.string()
+		port := dec.int32()
+		dec.string() // rack
+		brokers[nodeID] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	dec.string() // cluster_id
+	dec.int32()  // controller_id
+
+	var leaders []int32
+	var topicErr int16
+	for i, n := 0, dec.arrayLen(); i < n; i++ {
+		errorCode := dec.int16()
+		name := dec.string()
+		dec.int8() // is_internal
+		var partitionLeaders []int32
+		for j, m := 0, dec.arrayLen(); j < m; j++ {
+			dec.int16() // partition error_code
+			index := dec.int32()
+			leader := dec.int32()
+			for k, r := 0, dec.arrayLen(); k < r; k++ {
+				dec.int32() // replica_nodes
+			}
+			for k, r := 0, dec.arrayLen(); k < r; k++ {
+				dec.int32() // isr_nodes
+			}
+			if index < 0 || index > maxKafkaPartitions {
+				continue
+			}
+			for int(index) >= len(partitionLeaders) {
+				partitionLeaders = append(partitionLeaders, -1)
+			}
+			partitionLeaders[index] = leader
+		}
+		if name == topic {
+			topicErr, leaders = errorCode, partitionLeaders
+		}
+	}
+	if dec.err != nil {
+		return fmt.Errorf("malformed kafka metadata response: %w", dec.err)
+	}
+	if topicErr != 0 {
+		return fmt.Errorf("kafka topic %s unavailable (error code %d)", topic, topicErr)
+	}
+	if len(leaders) == 0 {
+		return fmt.Errorf("kafka topic %s has no partitions", topic)
+	}
+
+	d.brokers = brokers
+	if d.partitions == nil {
+		d.partitions = make(map[string][]int32)
+	}
+	d.partitions[topic] = leaders
+	return nil
+}
+
+// produce sends one Produce request to a leader and checks every partition
+func (d *kafkaDriver) produce(addr string, topics map[string]map[int32][]Message) error {
+	var req kafkaEncoder
+	req.int16(-1) // transactional_id: null
+	req.int16(-1) // acks: all in-sync replicas
+	req.int32(int32(d.timeout / time.Millisecond))
+	req.int32(int32(len(topics)))
+	for topic, partitions := range topics {
+		req.string(topic)
+		req.int32(int32(len(partitions)))
+		for partition, msgs := range partitions {
+			req.int32(partition)
+			req.bytes(recordBatch(msgs, time.Now()))
+		}
+	}
+
+	resp, err := d.roundTrip(addr, kafkaProduceKey, kafkaProduceVersion, req.buf)
+	if err != nil {
+		return err
+	}
+
+	dec := kafkaDecoder{buf: resp}
+	for i, n := 0, dec.arrayLen(); i < n; i++ {
+		topic := dec.string()
+		for j, m := 0, dec.arrayLen(); j < m; j++ {
+			partition := dec.int32()
+			errorCode := dec.int16()
+			dec.int64() // base_offset
+			dec.int64() // log_append_time_ms
+			if dec.err == nil && errorCode != 0 {
+				return fmt.Errorf("kafka rejected the batch for %s/%d (error code %d)", topic, partition, errorCode)
+			}
+		}
+	}
+	if dec.err != nil {
+		return fmt.Errorf("malformed kafka produce response: %w", dec.err)
+	}
+	return nil
+}
+
+// recordBatch encodes messages as an uncompressed v2 record batch
+func recordBatch(msgs []Message, now time.Time) []byte {
+	timestamp := now.UnixMilli()
+
+	var records []byte
+	for i, msg := range msgs {
+		var record []byte
+		record = append(record, 0)              // attributes
+		record = binary.AppendVarint(record, 0) // timestamp delta
+		record = binary.AppendVarint(record, int64(i))
+		record = binary.AppendVarint(record, int64(len(msg.Key)))
+		record = append(record, msg.Key...)
+		record = binary.AppendVarint(record, int64(len(msg.Value)))
+		record = append(record, msg.Value...)
+		record = binary.AppendVarint(record, 0) // headers
+
+		records = binary.AppendVarint(records, int64(len(record)))
+		records = append(records, record...)
+	}
+
+	// Everything after the CRC field, which the CRC covers
+	var body kafkaEncoder
+	body.int16(0) // attributes: no compression, create time
+	body.int32(int32(len(msgs) - 1))
+	body.int64(timestamp)
+	body.int64(timestamp)
+	body.int64(-1) // producer_id
+	body.int16(-1) // producer_epoch
+	body.int32(-1) // base_sequence
+	body.int32(int32(len(msgs)))
+	body.buf = append(body.buf, records...)
+
+	var batch kafkaEncoder
+	batch.int64(0) // base_offset, assigned by the broker
+	batch.int32(int32(4 + 1 + 4 + len(body.buf)))
+	batch.int32(-1) // partition_leader_epoch
+	batch.int8(2)   // magic
+	batch.int32(int32(crc32.Checksum(body.buf, castagnoli)))
+	batch.buf = append(batch.buf, body.buf...)
+	return batch.buf
+}
+
+// roundTrip sends one request to a broker and returns the response body
+func (d *kafkaDriver) roundTrip(addr string, apiKey, apiVersion int16, body []byte) ([]byte, error) {
+	kc, err := d.connLocked(addr)
+	if err != nil {
+		return nil, err
+	}
+	kc.correlationID++
+
+	var header kafkaEncoder
+	header.int16(apiKey)
+	header.int16(apiVersion)
+	header.int32(kc.correlationID)
+	header.string("internal-api")
+
+	frame := make([]byte, 4, 4+len(header.buf)+len(body))
+	binary.BigEndian.PutUint32(frame, uint32(len(header.buf)+len(body)))
+	frame = append(frame, header.buf...)
+	frame = append(frame, body...)
+
+	kc.conn.SetDeadline(time.Now().Add(d.timeout))
+	if _, err := kc.conn.Write(frame); err != nil {
+		return nil, fmt.Errorf("kafka request to %s failed: %w", addr, err)
+	}
+
+	var size [4]byte
+	if _, err := io.ReadFull(kc.reader, size[:]); err != nil {
+		return nil, fmt.Errorf("kafka response from %s failed: %w", addr, err)
+	}
+	resp := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := io.ReadFull(kc.reader, resp); err != nil {
+		return nil, fmt.Errorf("kafka response from %s failed: %w", addr, err)
+	}
+	if len(resp) < 4 || int32(binary.BigEndian.Uint32(resp)) != kc.correlationID {
+		return nil, fmt.Errorf("kafka response from %s does not match the request", addr)
+	}
+	return resp[4:], nil
+}
+
+// connLocked returns the connection to a broker, dialing it when needed
+func (d *kafkaDriver) connLocked(addr string) (*kafkaConn, error) {
+	if kc, open := d.conns[addr]; open {
+		return kc, nil
+	}
+	conn, err := net.DialTimeout("tcp", addr, d.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("kafka connect to %s failed: %w", addr, err)
+	}
+	kc := &kafkaConn{conn: conn, reader: bufio.NewReader(conn)}
+	d.conns[addr] = kc
+	return kc, nil
+}
+
+// resetLocked closes every connection and forgets the metadata
+func (d *kafkaDriver) resetLocked() {
+	for addr, kc := range d.conns {
+		kc.conn.Close()
+		delete(d.conns, addr)
+	}
+	d.brokers = nil
+	d.partitions = nil
+}
+
+// Close closes every broker connection
+func (d *kafkaDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resetLocked()
+	return nil
+}
+
+// kafkaEncoder appends big-endian protocol primitives
+type kafkaEncoder struct {
+	buf []byte
+}
+
+// int8 appends one byte
+func (e *kafkaEncoder) int8(v int8) { e.buf = append(e.buf, byte(v)) }
+
+// int16 appends a big-endian int16
+func (e *kafkaEncoder) int16(v int16) { e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v)) }
+
+// int32 appends a big-endian int32
+func (e *kafkaEncoder) int32(v int32) { e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v)) }
+
+// int64 appends a big-endian int64
+func (e *kafkaEncoder) int64(v int64) { e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v)) }
+
+// string appends an int16 length-prefixed string
+func (e *kafkaEncoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// bytes appends an int32 length-prefixed byte string
+func (e *kafkaEncoder) bytes(b []byte) {
+	e.int32(int32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// kafkaDecoder reads big-endian protocol primitives. The first short read
+// is kept in err and later reads return zero values.
+type kafkaDecoder struct {
+	buf []byte
+	err error
+}
+
+// take consumes n bytes
+func (d *kafkaDecoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+// int8 reads a big-endian int8
+func (d *kafkaDecoder) int8() int8 {
+	if b := d.take(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+// int16 reads a big-endian int16
+func (d *kafkaDecoder) int16() int16 {
+	if b := d.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+// int32 reads a big-endian int32
+func (d *kafkaDecoder) int32() int32 {
+	if b := d.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+// int64 reads a big-endian int64
+func (d *kafkaDecoder) int64() int64 {
+	if b := d.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+// string reads an int16 length-prefixed string; null reads as ""
+func (d *kafkaDecoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+// arrayLen reads an int32 array length; null reads as 0
+func (d *kafkaDecoder) arrayLen() int {
+	n := d.int32()
+	if n < 0 || d.err != nil {
+		return 0
+	}
+	// Every element takes at least one byte, so longer arrays are corrupt
+	if int(n) > len(d.buf) {
+		d.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	return int(n)
+}
@@ -0,0 +1,229 @@
+package events
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsDriver publishes over the NATS client protocol: after the server's
+// INFO line the client sends CONNECT and then one PUB per message to the
+// subject "<topic>.<event type>". Publishing is fire-and-forget, as with
+// any core NATS client; a reader goroutine answers server PINGs so idle
+// connections stay open.
+type natsDriver struct {
+	urls    []string
+	timeout time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	writer *bufio.Writer
+	failed chan struct{} // Closed by the reader when the connection breaks
+
+	maxPayload int // Announced by the server; 0 when unknown
+}
+
+// natsInfo is the part of the server INFO line the driver uses
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+	MaxPayload  int  `json:"max_payload"`
+}
+
+// natsConnect is the CONNECT options payload
+type natsConnect struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	Name      string `json:"name"`
+	Lang      string `json:"lang"`
+	Version   string `json:"version"`
+	Protocol  int    `json:"protocol"`
+	User      string `json:"user,omitempty"`
+	Pass      string `json:"pass,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+}
+
+// newNATSDriver creates a driver for nats:// or tls:// server URLs
+func newNATSDriver(urls []string, timeout time.Duration) *natsDriver {
+	return &natsDriver{urls: urls, timeout: timeout}
+}
+
+// Name identifies the driver in logs
+func (d *natsDriver) Name() string {
+	return "nats"
+}
+
+// Send publishes a batch of messages, connecting first when needed
+func (d *natsDriver) Send(msgs []Message) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn != nil {
+		select {
+		case <-d.failed:
+			d.closeLocked()
+		default:
+		}
+	}
+	if d.conn == nil {
+		if err := d.connectLocked(); err != nil {
+			return err
+		}
+	}
+
+	d.conn.SetWriteDeadline(time.Now().Add(d.timeout))
+	for _, msg := range msgs {
+		// The server would close the connection over an oversized payload
+		if d.maxPayload > 0 && len(msg.Value) > d.maxPayload {
+			log.WithField("event_type", msg.Key).Warn("Event exceeds the NATS max payload and was not published")
+			continue
+		}
+		fmt.Fprintf(d.writer, "PUB %s.%s %d\r\n", msg.Topic, msg.Key, len(msg.Value))
+		d.writer.Write(msg.Value)
+		d.writer.WriteString("\r\n")
+	}
+	if err := d.writer.Flush(); err != nil {
+		d.closeLocked()
+		return fmt.Errorf("nats publish failed: %w", err)
+	}
+	return nil
+}
+
+// connectLocked dials the first reachable server and completes the handshake
+func (d *natsDriver) connectLocked() error {
+	var lastErr error
+	for _, raw := range d.urls {
+		if err := d.dialLocked(raw); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	return lastErr
+}
+
+// dialLocked connects to one server
+func (d *natsDriver) dialLocked(raw string) error {
+	if !strings.Contains(raw, "://") {
+		raw = "nats://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid NATS URL %q: %w", raw, err)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	conn, err := net.DialTimeout("tcp", host, d.timeout)
+	if err != nil {
+		return fmt.Errorf("nats connect to %s failed: %w", host, err)
+	}
+	conn.SetDeadline(time.Now().Add(d.timeout))
+	reader := bufio.NewReader(conn)
+
+	line, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("nats server %s sent no INFO", host)
+	}
+	var info natsInfo
+	json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "INFO "))), &info)
+
+	if info.TLSRequired || u.Scheme == "tls" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return fmt.Errorf("nats TLS handshake with %s failed: %w", host, err)
+		}
+		conn = tlsConn
+		reader = bufio.NewReader(conn)
+	}
+
+	options := natsConnect{Name: "internal-api", Lang: "go", Version: "1.0", Protocol: 1}
+	if u.User != nil {
+		if pass, hasPass := u.User.Password(); hasPass {
+			options.User, options.Pass = u.User.Username(), pass
+		} else {
+			options.AuthToken = u.User.Username()
+		}
+	}
+	payload, _ := json.Marshal(options)
+
+	// The PONG confirms the server accepted CONNECT; a rejection arrives as -ERR
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", payload); err != nil {
+		conn.Close()
+		return fmt.Errorf("nats handshake with %s failed: %w", host, err)
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			conn.Close()
+			return fmt.Errorf("nats handshake with %s failed: %w", host, err)
+		}
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "-ERR") {
+			conn.Close()
+			return fmt.Errorf("nats server %s refused the connection: %s", host, strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		if line == "PONG" {
+			break
+		}
+	}
+	conn.SetDeadline(time.Time{})
+
+	d.conn = conn
+	d.writer = bufio.NewWriter(conn)
+	d.failed = make(chan struct{})
+	d.maxPayload = info.MaxPayload
+	go d.read(conn, reader, d.failed)
+	return nil
+}
+
+// read answers server PINGs and reports a broken connection. Errors such
+// as a rejected subject are logged; the connection stays usable.
+func (d *natsDriver) read(conn net.Conn, reader *bufio.Reader, failed chan struct{}) {
+	defer close(failed)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PING":
+			d.mu.Lock()
+			if d.conn == conn {
+				conn.SetWriteDeadline(time.Now().Add(d.timeout))
+				d.writer.WriteString("PONG\r\n")
+				d.writer.Flush()
+			}
+			d.mu.Unlock()
+		case strings.HasPrefix(line, "-ERR"):
+			log.WithField("error", strings.TrimPrefix(line, "-ERR ")).Warn("NATS server reported an error")
+		}
+	}
+}
+
+// closeLocked drops the current connection
+func (d *natsDriver) closeLocked() {
+	if d.conn != nil {
+		d.conn.Close()
+		d.conn = nil
+		d.writer = nil
+	}
+}
+
+// Close closes the connection
+func (d *natsDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closeLocked()
+	return nil
+}
@@ -0,0 +1,219 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logging.Logger()
+
+// maxBatchSize caps the events handed to a driver in one send
+const maxBatchSize = 100
+
+var (
+	brokerPublished = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "event_broker_published_total",
+		Help:      "Events published to the message broker by topic",
+	}, []string{"topic"})
+
+	brokerDropped = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "event_broker_dropped_total",
+		Help:      "Events not published to the message broker by reason (buffer_full, send_failed, encode_failed, shutdown)",
+	}, []string{"reason"})
+
+	brokerBuffered = metrics.Factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "event_broker_buffered",
+		Help:      "Events waiting to be published to the message broker",
+	})
+)
+
+// Message is one record handed to a broker driver
+type Message struct {
+	Topic string
+	Key   string // Event type; NATS appends it to the subject, Kafka partitions by it
+	Value []byte
+}
+
+// Driver sends batches of messages to a message broker. Send is called
+// from a single goroutine; a driver reconnects on its own after a failed
+// send.
+type Driver interface {
+	Name() string
+	Send(msgs []Message) error
+	Close() error
+}
+
+// NewDriver creates the driver for a broker name ("nats" or "kafka")
+func NewDriver(name string, urls []string, timeout time.Duration) (Driver, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no %s broker URLs configured", name)
+	}
+	switch strings.ToLower(name) {
+	case "nats":
+		return newNATSDriver(urls, timeout), nil
+	case "kafka":
+		return newKafkaDriver(urls, timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown event broker driver %q", name)
+	}
+}
+
+// PublisherConfig configures the broker publisher
+type PublisherConfig struct {
+	Topic      string // Domain events
+	AuditTopic string // Audit entries
+	BufferSize int
+	// Redact is applied to event data before it leaves the gateway
+	Redact func(map[string]interface{}) map[string]interface{}
+}
+
+// Publisher forwards bus events to a message broker. Events are buffered
+// and sent by one background goroutine, so publishers on the bus never wait
+// for the broker; when the buffer is full, or the broker keeps failing,
+// events are dropped and counted.
+type Publisher struct {
+	driver Driver
+	config PublisherConfig
+	queue  chan Message
+	done   chan struct{}
+
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// NewPublisher creates a publisher for a driver. Call Start to begin sending.
+func NewPublisher(driver Driver, config PublisherConfig) *Publisher {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1024
+	}
+	return &Publisher{
+		driver: driver,
+		config: config,
+		queue:  make(chan Message, config.BufferSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// Enqueue buffers an event for publishing to topic without blocking
+func (p *Publisher) Enqueue(topic string, event Event) error {
+	if p.config.Redact != nil {
+		event.Data = p.config.Redact(event.Data)
+	}
+	value, err := json.Marshal(event)
+	if err != nil {
+		brokerDropped.WithLabelValues("encode_failed").Inc()
+		return nil
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.stopped {
+		brokerDropped.WithLabelValues("shutdown").Inc()
+		return nil
+	}
+
+	select {
+	case p.queue <- Message{Topic: topic, Key: event.Type, Value: value}:
+		brokerBuffered.Inc()
+	default:
+		brokerDropped.WithLabelValues("buffer_full").Inc()
+	}
+	// Broker trouble is never the publisher's error
+	return nil
+}
+
+// Start sends buffered events until Stop is called. Whatever has queued
+// up while a batch was being sent goes out as the next batch.
+func (p *Publisher) Start() {
+	go func() {
+		defer close(p.done)
+		for msg := range p.queue {
+			batch := []Message{msg}
+		drain:
+			for len(batch) < maxBatchSize {
+				select {
+				case next, open := <-p.queue:
+					if !open {
+						break drain
+					}
+					batch = append(batch, next)
+				default:
+					break drain
+				}
+			}
+			brokerBuffered.Sub(float64(len(batch)))
+			p.send(batch)
+		}
+	}()
+}
+
+// send publishes one batch, retrying once on a fresh connection
+func (p *Publisher) send(batch []Message) {
+	err := p.driver.Send(batch)
+	if err != nil {
+		err = p.driver.Send(batch)
+	}
+	if err != nil {
+		brokerDropped.WithLabelValues("send_failed").Add(float64(len(batch)))
+		log.WithError(err).WithFields(logrus.Fields{
+			"driver": p.driver.Name(),
+			"events": len(batch),
+		}).Warn("Failed to publish events to broker")
+		return
+	}
+	for _, msg := range batch {
+		brokerPublished.WithLabelValues(msg.Topic).Inc()
+	}
+}
+
+// Stop stops accepting events and sends what is buffered until ctx ends.
+// Events still buffered then are dropped.
+func (p *Publisher) Stop(ctx context.Context) {
+	p.mu.Lock()
+	p.stopped = true
+	close(p.queue)
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		brokerDropped.WithLabelValues("shutdown").Add(float64(len(p.queue)))
+	}
+	p.driver.Close()
+}
+
+var publisher *Publisher
+
+// StartPublisher forwards domain events from the default bus and audit
+// entries from the admin bus to a broker through the global publisher
+func StartPublisher(driver Driver, config PublisherConfig) {
+	publisher = NewPublisher(driver, config)
+	publisher.Start()
+
+	Subscribe("*", func(event Event) error {
+		return publisher.Enqueue(config.Topic, event)
+	})
+	SubscribeAdmin("audit.recorded", func(event Event) error {
+		return publisher.Enqueue(config.AuditTopic, event)
+	})
+}
+
+// StopPublisher flushes and closes the global publisher, if started
+func StopPublisher(ctx context.Context) {
+	if publisher != nil {
+		publisher.Stop(ctx)
+	}
+}
@@ -0,0 +1,88 @@
+// Package graphql is the GraphQL interface of the gateway: the schema in
+// schema.graphqls with the executor gqlgen generates from it, the models
+// the schema is bound to, a depth limit for queries and a dataloader that
+// batches backend lookups made while resolving a query. Resolvers live in
+// internal/handlers.
+package graphql
+
+//go:generate go tool gqlgen generate --config gqlgen.yml
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// NewError creates an error carrying the gateway's error code in its
+// extensions, as REST errors carry it in code
+func NewError(code, message string) error {
+	return &gqlerror.Error{Message: message, Extensions: map[string]interface{}{"code": code}}
+}
+
+// DepthLimit rejects operations whose selections are nested deeper than
+// MaxDepth levels, counting the root fields as the first level. Fragments
+// count at the depth they are spread.
+type DepthLimit struct {
+	MaxDepth int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = DepthLimit{}
+
+// ExtensionName names the extension
+func (DepthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+// Validate accepts every schema
+func (DepthLimit) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationContext checks the operation before it runs
+func (d DepthLimit) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	if d.MaxDepth <= 0 || rc.Operation == nil {
+		return nil
+	}
+	if field := tooDeep(rc.Operation.SelectionSet, 1, d.MaxDepth); field != nil {
+		err := gqlerror.ErrorPosf(field.Position, "query is nested deeper than %d levels", d.MaxDepth)
+		err.Extensions = map[string]interface{}{"code": "QUERY_TOO_DEEP"}
+		return err
+	}
+	return nil
+}
+
+// tooDeep returns the first field whose selections exceed max levels
+func tooDeep(selections ast.SelectionSet, depth, max int) *ast.Field {
+	for _, selection := range selections {
+		var (
+			children ast.SelectionSet
+			next     = depth
+		)
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if len(sel.SelectionSet) == 0 {
+				continue
+			}
+			if depth+1 > max {
+				return sel
+			}
+			children, next = sel.SelectionSet, depth+1
+		case *ast.InlineFragment:
+			children = sel.SelectionSet
+		case *ast.FragmentSpread:
+			if sel.Definition == nil {
+				continue
+			}
+			children = sel.Definition.SelectionSet
+		}
+		if field := tooDeep(children, next, max); field != nil {
+			return field
+		}
+	}
+	return nil
+}
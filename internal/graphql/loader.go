@@ -0,0 +1,100 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var batchSizes = metrics.Factory.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: metrics.Namespace,
+	Name:      "graphql_batch_size",
+	Help:      "Keys fetched per batched backend call by loader",
+	Buckets:   []float64{1, 2, 5, 10, 25, 50, 100},
+}, []string{"loader"})
+
+// BatchFunc fetches the values of keys in one backend call. Keys missing
+// from the result resolve to null.
+type BatchFunc func(ctx context.Context, keys []string) (map[string]interface{}, error)
+
+// Loader batches and caches lookups by key for the duration of one
+// request. Keys requested within the wait window, e.g. the owners of every
+// album in a list, are fetched with a single call instead of one per item.
+type Loader struct {
+	name     string
+	fetch    BatchFunc
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	pending *batch
+	batches map[string]*batch
+}
+
+// batch is one backend call for a set of keys
+type batch struct {
+	ctx     context.Context
+	keys    []string
+	once    sync.Once
+	done    chan struct{}
+	results map[string]interface{}
+	err     error
+}
+
+// NewLoader creates a loader that waits up to wait for more keys and
+// fetches at most maxBatch keys per call (zero means no limit). Create one
+// per request so cached values never leak between callers.
+func NewLoader(name string, fetch BatchFunc, wait time.Duration, maxBatch int) *Loader {
+	return &Loader{name: name, fetch: fetch, wait: wait, maxBatch: maxBatch, batches: map[string]*batch{}}
+}
+
+// Load returns the value of key, joining the pending batch
+func (l *Loader) Load(ctx context.Context, key string) (interface{}, error) {
+	l.mu.Lock()
+	b, cached := l.batches[key]
+	if !cached {
+		if l.pending == nil {
+			l.pending = &batch{ctx: ctx, done: make(chan struct{})}
+			pending := l.pending
+			time.AfterFunc(l.wait, func() { l.dispatch(pending) })
+		}
+		b = l.pending
+		b.keys = append(b.keys, key)
+		l.batches[key] = b
+		if l.maxBatch > 0 && len(b.keys) >= l.maxBatch {
+			l.pending = nil
+			go l.dispatch(b)
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.results[key], nil
+}
+
+// dispatch fetches a batch once, whether it filled up or its window ended
+func (l *Loader) dispatch(b *batch) {
+	b.once.Do(func() {
+		l.mu.Lock()
+		if l.pending == b {
+			l.pending = nil
+		}
+		keys := b.keys
+		l.mu.Unlock()
+
+		batchSizes.WithLabelValues(l.name).Observe(float64(len(keys)))
+		b.results, b.err = l.fetch(b.ctx, keys)
+		close(b.done)
+	})
+}
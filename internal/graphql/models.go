@@ -0,0 +1,224 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Album is the GraphQL Album type. Its owners are resolved from the IDs
+// through the users loader.
+type Album struct {
+	ID     string   `json:"id"`
+	Title  *string  `json:"title"`
+	Artist *string  `json:"artist"`
+	Price  *float64 `json:"price"`
+	Genre  *string  `json:"genre"`
+
+	CreatedByID string `json:"-"`
+	UpdatedByID string `json:"-"`
+}
+
+// Booking is the GraphQL Booking type
+type Booking struct {
+	ID         string  `json:"id"`
+	HotelID    *string `json:"hotel_id"`
+	RoomType   *string `json:"room_type"`
+	GuestName  *string `json:"guest_name"`
+	GuestEmail *string `json:"guest_email"`
+	CheckIn    *string `json:"check_in"`
+	CheckOut   *string `json:"check_out"`
+	Guests     *int    `json:"guests"`
+	Status     *string `json:"status"`
+	Notes      *string `json:"notes"`
+}
+
+// User is the GraphQL User type
+type User struct {
+	ID       string    `json:"id"`
+	Username *string   `json:"username"`
+	Email    *string   `json:"email"`
+	Roles    []*string `json:"roles"`
+	Active   *bool     `json:"active"`
+	Created  *string   `json:"created"`
+	Modified *string   `json:"modified"`
+}
+
+// AlbumFrom converts an album as API Beheerder returns it. Owners may be
+// stored as createdBy or created_by.
+func AlbumFrom(value interface{}) *Album {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return &Album{
+		ID:          textOf(m["id"]),
+		Title:       text(m["title"]),
+		Artist:      text(m["artist"]),
+		Price:       float(m["price"]),
+		Genre:       text(m["genre"]),
+		CreatedByID: firstText(m["createdBy"], m["created_by"]),
+		UpdatedByID: firstText(m["updatedBy"], m["updated_by"]),
+	}
+}
+
+// BookingFrom converts a booking as the PMS adapters return it
+func BookingFrom(value interface{}) *Booking {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return &Booking{
+		ID:         textOf(m["id"]),
+		HotelID:    text(m["hotel_id"]),
+		RoomType:   text(m["room_type"]),
+		GuestName:  text(m["guest_name"]),
+		GuestEmail: text(m["guest_email"]),
+		CheckIn:    text(m["check_in"]),
+		CheckOut:   text(m["check_out"]),
+		Guests:     integer(m["guests"]),
+		Status:     text(m["status"]),
+		Notes:      text(m["notes"]),
+	}
+}
+
+// UserFrom converts a user as Central Management returns it
+func UserFrom(value interface{}) *User {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	user := &User{
+		ID:       textOf(m["id"]),
+		Username: text(m["username"]),
+		Email:    text(m["email"]),
+		Active:   boolean(m["active"]),
+		Created:  text(m["created"]),
+		Modified: text(m["modified"]),
+	}
+	switch roles := m["roles"].(type) {
+	case []interface{}:
+		for _, role := range roles {
+			user.Roles = append(user.Roles, text(role))
+		}
+	case []string:
+		for _, role := range roles {
+			user.Roles = append(user.Roles, text(role))
+		}
+	}
+	return user
+}
+
+// AlbumsFrom converts a list of albums
+func AlbumsFrom(value interface{}) []*Album {
+	list, _ := value.([]interface{})
+	albums := make([]*Album, len(list))
+	for i, item := range list {
+		albums[i] = AlbumFrom(item)
+	}
+	return albums
+}
+
+// BookingsFrom converts a list of bookings
+func BookingsFrom(value interface{}) []*Booking {
+	list, _ := value.([]interface{})
+	bookings := make([]*Booking, len(list))
+	for i, item := range list {
+		bookings[i] = BookingFrom(item)
+	}
+	return bookings
+}
+
+// UsersFrom converts a list of users
+func UsersFrom(value interface{}) []*User {
+	list, _ := value.([]interface{})
+	users := make([]*User, len(list))
+	for i, item := range list {
+		users[i] = UserFrom(item)
+	}
+	return users
+}
+
+// The helpers below read backend JSON leniently, as REST clients see it:
+// numbers and booleans are shown as text where the schema has a String or
+// ID, and values of another type become null.
+
+// text returns a scalar as a string, or nil
+func text(value interface{}) *string {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		s = v.String()
+	case int, int64:
+		s = fmt.Sprint(v)
+	case bool:
+		s = strconv.FormatBool(v)
+	default:
+		return nil
+	}
+	return &s
+}
+
+// textOf returns a scalar as a string, or "" when it is missing
+func textOf(value interface{}) string {
+	if s := text(value); s != nil {
+		return *s
+	}
+	return ""
+}
+
+// firstText returns the first of values that is set
+func firstText(values ...interface{}) string {
+	for _, value := range values {
+		if s := textOf(value); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// float returns a number, or nil
+func float(value interface{}) *float64 {
+	var f float64
+	switch v := value.(type) {
+	case float64:
+		f = v
+	case json.Number:
+		parsed, err := v.Float64()
+		if err != nil {
+			return nil
+		}
+		f = parsed
+	case int:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	default:
+		return nil
+	}
+	return &f
+}
+
+// integer returns a whole number that fits a GraphQL Int, or nil
+func integer(value interface{}) *int {
+	f := float(value)
+	if f == nil || *f != math.Trunc(*f) || *f < math.MinInt32 || *f > math.MaxInt32 {
+		return nil
+	}
+	n := int(*f)
+	return &n
+}
+
+// boolean returns a boolean, or nil
+func boolean(value interface{}) *bool {
+	b, ok := value.(bool)
+	if !ok {
+		return nil
+	}
+	return &b
+}
@@ -0,0 +1,63 @@
+package grpcserver
+
+import "net/http"
+
+// gRPC status codes
+const (
+	codeOK                 = 0
+	codeInvalidArgument    = 3
+	codeDeadlineExceeded   = 4
+	codeNotFound           = 5
+	codeAlreadyExists      = 6
+	codePermissionDenied   = 7
+	codeResourceExhausted  = 8
+	codeFailedPrecondition = 9
+	codeUnimplemented      = 12
+	codeInternal           = 13
+	codeUnavailable        = 14
+	codeUnauthenticated    = 16
+)
+
+// codeNames label the hotel_grpc_requests_total metric
+var codeNames = map[int]string{
+	codeOK:                 "OK",
+	codeInvalidArgument:    "INVALID_ARGUMENT",
+	codeDeadlineExceeded:   "DEADLINE_EXCEEDED",
+	codeNotFound:           "NOT_FOUND",
+	codeAlreadyExists:      "ALREADY_EXISTS",
+	codePermissionDenied:   "PERMISSION_DENIED",
+	codeResourceExhausted:  "RESOURCE_EXHAUSTED",
+	codeFailedPrecondition: "FAILED_PRECONDITION",
+	codeUnimplemented:      "UNIMPLEMENTED",
+	codeInternal:           "INTERNAL",
+	codeUnavailable:        "UNAVAILABLE",
+	codeUnauthenticated:    "UNAUTHENTICATED",
+}
+
+// codeForStatus maps the REST route's status to a gRPC status code
+func codeForStatus(status int) int {
+	switch status {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType:
+		return codeInvalidArgument
+	case http.StatusUnauthorized:
+		return codeUnauthenticated
+	case http.StatusForbidden:
+		return codePermissionDenied
+	case http.StatusNotFound, http.StatusGone:
+		return codeNotFound
+	case http.StatusConflict:
+		return codeAlreadyExists
+	case http.StatusPreconditionFailed, http.StatusPreconditionRequired, http.StatusUnprocessableEntity, http.StatusLocked:
+		return codeFailedPrecondition
+	case http.StatusTooManyRequests:
+		return codeResourceExhausted
+	case http.StatusNotImplemented, http.StatusMethodNotAllowed:
+		return codeUnimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codeUnavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return codeDeadlineExceeded
+	default:
+		return codeInternal
+	}
+}
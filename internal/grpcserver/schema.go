@@ -0,0 +1,216 @@
+package grpcserver
+
+import (
+	"embed"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+//go:embed proto/hotel.proto
+var protoFiles embed.FS
+
+// ProtoFile is the embedded gRPC contract
+const ProtoFile = "proto/hotel.proto"
+
+// method is one RPC and the REST route that serves it
+type method struct {
+	desc       protoreflect.MethodDescriptor
+	httpMethod string
+	route      string // Gin route, e.g. /api/v1/albums/:id
+}
+
+// Lines of the supported proto3 subset
+var (
+	syntaxLine  = regexp.MustCompile(`^syntax\s*=\s*"(\w+)"\s*;$`)
+	packageLine = regexp.MustCompile(`^package\s+([\w.]+)\s*;$`)
+	blockLine   = regexp.MustCompile(`^(message|service)\s+(\w+)\s*\{\s*(\})?$`)
+	fieldLine   = regexp.MustCompile(`^(repeated\s+)?([\w.]+)\s+(\w+)\s*=\s*(\d+)\s*;$`)
+	rpcLine     = regexp.MustCompile(`^rpc\s+(\w+)\s*\(\s*(\w+)\s*\)\s*returns\s*\(\s*(\w+)\s*\)\s*;$`)
+	routeLine   = regexp.MustCompile(`^//\s*(GET|POST|PUT|PATCH|DELETE)\s+(/\S*)$`)
+)
+
+// scalarTypes maps proto3 scalar type names to descriptor types
+var scalarTypes = map[string]descriptorpb.FieldDescriptorProto_Type{
+	"string": descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	"bool":   descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	"int32":  descriptorpb.FieldDescriptorProto_TYPE_INT32,
+	"int64":  descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	"uint32": descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+	"uint64": descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+	"double": descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+	"float":  descriptorpb.FieldDescriptorProto_TYPE_FLOAT,
+	"bytes":  descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+}
+
+// loadMethods reads the embedded proto file and returns its RPCs keyed by
+// gRPC path, e.g. /hotel.v1.AlbumService/GetAlbum
+func loadMethods() (map[string]*method, error) {
+	source, err := protoFiles.ReadFile(ProtoFile)
+	if err != nil {
+		return nil, err
+	}
+	file, routes, err := parseProto(ProtoFile, string(source))
+	if err != nil {
+		return nil, err
+	}
+	fd, err := protodesc.NewFile(file, new(protoregistry.Files))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ProtoFile, err)
+	}
+
+	methods := make(map[string]*method)
+	services := fd.Services()
+	for i := 0; i < services.Len(); i++ {
+		service := services.Get(i)
+		for j := 0; j < service.Methods().Len(); j++ {
+			desc := service.Methods().Get(j)
+			route := routes[string(desc.FullName())]
+			methods["/"+string(service.FullName())+"/"+string(desc.Name())] = &method{
+				desc:       desc,
+				httpMethod: route[0],
+				route:      route[1],
+			}
+		}
+	}
+	return methods, nil
+}
+
+// parseProto parses the proto3 subset the gateway's contract uses. It
+// returns the file descriptor and the REST route of each RPC, taken from
+// the "// METHOD /route" comment above it.
+func parseProto(name, source string) (*descriptorpb.FileDescriptorProto, map[string][2]string, error) {
+	file := &descriptorpb.FileDescriptorProto{Name: proto.String(name)}
+	routes := make(map[string][2]string)
+
+	var message *descriptorpb.DescriptorProto
+	var service *descriptorpb.ServiceDescriptorProto
+	var route []string
+	for i, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		fail := func(format string, args ...interface{}) error {
+			return fmt.Errorf("%s:%d: %s", name, i+1, fmt.Sprintf(format, args...))
+		}
+
+		if strings.HasPrefix(line, "//") {
+			if match := routeLine.FindStringSubmatch(line); match != nil && service != nil {
+				route = match[1:]
+			}
+			continue
+		}
+		if comment := strings.Index(line, "//"); comment >= 0 {
+			line = strings.TrimSpace(line[:comment])
+		}
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case message != nil && line == "}":
+			message = nil
+
+		case message != nil:
+			match := fieldLine.FindStringSubmatch(line)
+			if match == nil {
+				return nil, nil, fail("unsupported field %q", line)
+			}
+			number, _ := strconv.Atoi(match[4])
+			field := &descriptorpb.FieldDescriptorProto{
+				Name:     proto.String(match[3]),
+				Number:   proto.Int32(int32(number)),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				JsonName: proto.String(jsonName(match[3])),
+			}
+			if match[1] != "" {
+				field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+			}
+			if scalar, ok := scalarTypes[match[2]]; ok {
+				field.Type = scalar.Enum()
+			} else {
+				field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+				field.TypeName = proto.String(qualify(file.GetPackage(), match[2]))
+			}
+			message.Field = append(message.Field, field)
+
+		case service != nil && line == "}":
+			service = nil
+
+		case service != nil:
+			match := rpcLine.FindStringSubmatch(line)
+			if match == nil {
+				return nil, nil, fail("unsupported rpc %q", line)
+			}
+			if route == nil {
+				return nil, nil, fail("rpc %s has no REST route comment", match[1])
+			}
+			service.Method = append(service.Method, &descriptorpb.MethodDescriptorProto{
+				Name:       proto.String(match[1]),
+				InputType:  proto.String(qualify(file.GetPackage(), match[2])),
+				OutputType: proto.String(qualify(file.GetPackage(), match[3])),
+			})
+			routes[file.GetPackage()+"."+service.GetName()+"."+match[1]] = [2]string{route[0], route[1]}
+			route = nil
+
+		case syntaxLine.MatchString(line):
+			if syntax := syntaxLine.FindStringSubmatch(line)[1]; syntax != "proto3" {
+				return nil, nil, fail("syntax %q is not supported", syntax)
+			}
+			file.Syntax = proto.String("proto3")
+
+		case packageLine.MatchString(line):
+			file.Package = proto.String(packageLine.FindStringSubmatch(line)[1])
+
+		case blockLine.MatchString(line):
+			match := blockLine.FindStringSubmatch(line)
+			if match[1] == "message" {
+				message = &descriptorpb.DescriptorProto{Name: proto.String(match[2])}
+				file.MessageType = append(file.MessageType, message)
+				if match[3] != "" {
+					message = nil
+				}
+			} else {
+				service = &descriptorpb.ServiceDescriptorProto{Name: proto.String(match[2])}
+				file.Service = append(file.Service, service)
+			}
+
+		default:
+			return nil, nil, fail("unsupported declaration %q", line)
+		}
+	}
+
+	if message != nil || service != nil {
+		return nil, nil, fmt.Errorf("%s: unexpected end of file", name)
+	}
+	return file, routes, nil
+}
+
+// qualify returns the fully qualified name of a message in the package
+func qualify(pkg, name string) string {
+	return "." + pkg + "." + name
+}
+
+// jsonName returns the proto3 JSON name of a field, e.g. hotelId for hotel_id
+func jsonName(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,392 @@
+// Package grpcserver serves the gRPC contract in proto/hotel.proto on its
+// own port. Each RPC is transcoded to the REST route named in the contract
+// and run through the gateway's router, so gRPC callers get the same
+// authentication, rate limits, permission checks, audit entries and
+// business rules as REST callers, without a second implementation.
+package grpcserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
+	"InternalAPI/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var log = logging.Logger()
+
+var (
+	grpcRequests = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "grpc_requests_total",
+		Help:      "gRPC calls by method and status code",
+	}, []string{"method", "code"})
+	grpcDuration = metrics.Factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "gRPC call latency by method",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// forwardedMetadata are the gRPC metadata keys passed to the REST route as
+// request headers
+var forwardedMetadata = []string{"authorization", "x-internal-api-key", "x-request-id", "x-tenant-id", "accept-language", "user-agent"}
+
+// Server serves gRPC calls by transcoding them to the REST router
+type Server struct {
+	router         http.Handler
+	methods        map[string]*method
+	maxMessageSize int64
+	http           *http.Server
+}
+
+// New creates a gRPC server on address that dispatches to router. With a
+// TLS config the server speaks HTTP/2 over TLS, otherwise cleartext HTTP/2.
+func New(address string, router http.Handler, maxMessageSize int64, tlsConfig *tls.Config) (*Server, error) {
+	methods, err := loadMethods()
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{router: router, methods: methods, maxMessageSize: maxMessageSize}
+
+	protocols := new(http.Protocols)
+	if tlsConfig != nil {
+		protocols.SetHTTP2(true)
+	} else {
+		protocols.SetUnencryptedHTTP2(true)
+	}
+	s.http = &http.Server{
+		Addr:      address,
+		Handler:   s,
+		Protocols: protocols,
+		TLSConfig: tlsConfig,
+	}
+	return s, nil
+}
+
+// Methods returns the gRPC paths the server answers
+func (s *Server) Methods() []string {
+	paths := make([]string, 0, len(s.methods))
+	for path := range s.methods {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// ListenAndServe accepts gRPC connections until Shutdown is called
+func (s *Server) ListenAndServe() error {
+	if s.http.TLSConfig != nil {
+		return s.http.ListenAndServeTLS("", "")
+	}
+	return s.http.ListenAndServe()
+}
+
+// Shutdown stops accepting calls and waits for running ones to finish
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+// ServeHTTP handles one unary gRPC call
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	if r.Method != http.MethodPost || (contentType != "application/grpc" && contentType != "application/grpc+proto") {
+		http.Error(w, "gRPC requests only", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	start := time.Now()
+	call := &call{w: w}
+	w.Header().Set("Content-Type", contentType)
+
+	m, ok := s.methods[r.URL.Path]
+	if !ok {
+		call.fail(codeUnimplemented, "unknown method "+r.URL.Path, "")
+		grpcRequests.WithLabelValues("unknown", codeNames[codeUnimplemented]).Inc()
+		return
+	}
+	s.serve(call, r, m)
+
+	grpcRequests.WithLabelValues(r.URL.Path, codeNames[call.code]).Inc()
+	grpcDuration.WithLabelValues(r.URL.Path).Observe(time.Since(start).Seconds())
+	if call.code != codeOK {
+		log.WithFields(logrus.Fields{
+			"method": r.URL.Path,
+			"code":   codeNames[call.code],
+			"error":  call.message,
+		}).Debug("gRPC call failed")
+	}
+}
+
+// serve decodes the request message, runs the REST route and encodes its
+// response
+func (s *Server) serve(call *call, r *http.Request, m *method) {
+	ctx := r.Context()
+	if timeout, ok := parseTimeout(r.Header.Get("Grpc-Timeout")); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	payload, code, err := readMessage(r.Body, s.maxMessageSize)
+	if err != nil {
+		call.fail(code, err.Error(), "")
+		return
+	}
+	in := dynamicpb.NewMessage(m.desc.Input())
+	if err := proto.Unmarshal(payload, in); err != nil {
+		call.fail(codeInvalidArgument, "invalid request message: "+err.Error(), "")
+		return
+	}
+
+	req, err := m.restRequest(ctx, in)
+	if err != nil {
+		call.fail(codeInvalidArgument, err.Error(), "")
+		return
+	}
+	req.RemoteAddr = r.RemoteAddr
+	req.Host = r.Host
+	for _, key := range forwardedMetadata {
+		if value := r.Header.Get(key); value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+
+	rec := &recorder{header: http.Header{}, status: http.StatusOK}
+	s.router.ServeHTTP(rec, req)
+	if requestID := rec.header.Get("X-Request-ID"); requestID != "" {
+		call.w.Header().Set("X-Request-ID", requestID)
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		call.fail(codeDeadlineExceeded, "deadline exceeded", "")
+		return
+	}
+	if rec.status >= 300 {
+		var errResp models.ErrorResponse
+		_ = json.Unmarshal(rec.body.Bytes(), &errResp)
+		if errResp.Message == "" {
+			errResp.Message = http.StatusText(rec.status)
+		}
+		if len(errResp.Violations) > 0 {
+			violations, _ := json.Marshal(errResp.Violations)
+			call.w.Header().Set(http.TrailerPrefix+"X-Violations", string(violations))
+		}
+		call.fail(codeForStatus(rec.status), errResp.Message, errResp.Code)
+		return
+	}
+
+	out := dynamicpb.NewMessage(m.desc.Output())
+	if rec.body.Len() > 0 {
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(rec.body.Bytes(), out); err != nil {
+			call.fail(codeInternal, "response does not match the gRPC contract: "+err.Error(), "")
+			return
+		}
+	}
+	encoded, err := proto.Marshal(out)
+	if err != nil {
+		call.fail(codeInternal, err.Error(), "")
+		return
+	}
+	call.reply(encoded)
+}
+
+// restRequest builds the REST request of an RPC. Fields named in the route
+// fill its parameters; the others become the JSON body, or query
+// parameters for GET and DELETE, where repeated fields repeat the parameter.
+func (m *method) restRequest(ctx context.Context, in *dynamicpb.Message) (*http.Request, error) {
+	encoded, err := (protojson.MarshalOptions{UseProtoNames: true}).Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	segments := strings.Split(m.route, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := segment[1:]
+		value, ok := fields[name]
+		if !ok || fmt.Sprint(value) == "" {
+			return nil, fmt.Errorf("%s is required", name)
+		}
+		segments[i] = url.PathEscape(fmt.Sprint(value))
+		delete(fields, name)
+	}
+	target := strings.Join(segments, "/")
+
+	var body io.Reader
+	if m.httpMethod == http.MethodGet || m.httpMethod == http.MethodDelete {
+		query := url.Values{}
+		for name, value := range fields {
+			if values, ok := value.([]interface{}); ok {
+				for _, v := range values {
+					query.Add(name, fmt.Sprint(v))
+				}
+				continue
+			}
+			query.Set(name, fmt.Sprint(value))
+		}
+		if len(query) > 0 {
+			target += "?" + query.Encode()
+		}
+	} else {
+		payload, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, m.httpMethod, target, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// readMessage reads one length-prefixed gRPC message
+func readMessage(body io.Reader, maxSize int64) ([]byte, int, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(body, prefix[:]); err != nil {
+		return nil, codeInvalidArgument, errors.New("missing request message")
+	}
+	if prefix[0] != 0 {
+		return nil, codeUnimplemented, errors.New("compressed messages are not supported")
+	}
+	size := int64(binary.BigEndian.Uint32(prefix[1:]))
+	if maxSize > 0 && size > maxSize {
+		return nil, codeResourceExhausted, fmt.Errorf("request message of %d bytes exceeds the limit of %d", size, maxSize)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(body, payload); err != nil {
+		return nil, codeInvalidArgument, errors.New("truncated request message")
+	}
+	return payload, codeOK, nil
+}
+
+// parseTimeout parses a grpc-timeout header such as 250m or 5S
+func parseTimeout(value string) (time.Duration, bool) {
+	if len(value) < 2 {
+		return 0, false
+	}
+	units := map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second, 'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond}
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return 0, false
+	}
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, false
+	}
+	return time.Duration(amount) * unit, true
+}
+
+// call writes the response of one RPC
+type call struct {
+	w       http.ResponseWriter
+	code    int
+	message string
+}
+
+// reply sends a response message with an OK status
+func (c *call) reply(message []byte) {
+	frame := make([]byte, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(message)))
+	copy(frame[5:], message)
+
+	c.w.WriteHeader(http.StatusOK)
+	_, _ = c.w.Write(frame)
+	c.setStatus(codeOK, "", "")
+}
+
+// fail ends the RPC with an error status. errorCode is the gateway's error
+// code, passed in the x-error-code trailer.
+func (c *call) fail(code int, message, errorCode string) {
+	c.w.WriteHeader(http.StatusOK)
+	c.setStatus(code, message, errorCode)
+}
+
+// setStatus writes the status trailers
+func (c *call) setStatus(code int, message, errorCode string) {
+	c.code, c.message = code, message
+	header := c.w.Header()
+	header.Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+	if message != "" {
+		header.Set(http.TrailerPrefix+"Grpc-Message", encodeMessage(message))
+	}
+	if errorCode != "" {
+		header.Set(http.TrailerPrefix+"X-Error-Code", errorCode)
+	}
+}
+
+// encodeMessage percent-encodes a grpc-message value
+func encodeMessage(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		ch := message[i]
+		if ch < ' ' || ch > '~' || ch == '%' {
+			fmt.Fprintf(&b, "%%%02X", ch)
+			continue
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+// recorder captures the REST router's response
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+// Header returns the response headers
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader records the status code
+func (r *recorder) WriteHeader(status int) {
+	if !r.wrote {
+		r.status, r.wrote = status, true
+	}
+}
+
+// Write buffers the response body
+func (r *recorder) Write(data []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(data)
+}
+
+// Flush is a no-op; the response is sent once the route returns
+func (r *recorder) Flush() {}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"InternalAPI/internal/actions"
+
+	"github.com/gin-gonic/gin"
+)
+
+// actionListing is an action as shown to the calling admin
+type actionListing struct {
+	actions.Action
+	Allowed bool `json:"allowed"` // Whether the caller may run it
+}
+
+// runActionRequest is the body of POST /admin/actions/:name
+type runActionRequest struct {
+	Params map[string]string `json:"params"`
+	DryRun bool              `json:"dry_run"`
+}
+
+// ListActionsHandler lists the runbook actions and whether the caller may run each one
+func ListActionsHandler(c *gin.Context) {
+	list := actions.List()
+	listings := make([]actionListing, 0, len(list))
+	for _, action := range list {
+		listings = append(listings, actionListing{
+			Action:  action,
+			Allowed: hasAnyRole(c, action.Roles...),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"actions": listings,
+		"count":   len(listings),
+	})
+}
+
+// RunActionHandler runs a runbook action, or only reports what it would do
+// when dry_run is set
+func RunActionHandler(c *gin.Context) {
+	name := c.Param("name")
+	action, ok := actions.Get(name)
+	if !ok {
+		sendError(c, http.StatusNotFound, "ACTION_NOT_FOUND", "Unknown action: "+name)
+		return
+	}
+
+	if !hasAnyRole(c, action.Roles...) {
+		sendError(c, http.StatusForbidden, "INSUFFICIENT_PERMISSIONS", "Running "+name+" requires one of the roles "+strings.Join(action.Roles, ", "))
+		return
+	}
+
+	var req runActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+	if req.Params == nil {
+		req.Params = map[string]string{}
+	}
+
+	result, err := actions.Execute(name, req.Params, req.DryRun, c.GetString("userID"))
+	if err != nil {
+		if errors.Is(err, actions.ErrInvalidParams) {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "ACTION_FAILED", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
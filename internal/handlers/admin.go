@@ -1,8 +1,18 @@
 package handlers
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
+	"InternalAPI/internal/audit"
 	"InternalAPI/internal/config"
 	"InternalAPI/internal/models"
 	"InternalAPI/internal/services"
@@ -22,15 +32,33 @@ func NewAdminHandlers(config *config.Config) *AdminHandlers {
 	}
 }
 
-// GetUsers retrieves all users
+// userListFields are the fields users may be sorted and filtered by
+var userListFields = []string{"username", "email", "roles", "active", "created", "modified"}
+
+// GetUsers retrieves users, paginated by page/page_size or cursor, with the
+// sort order and filter expressions passed on to Central Management
 func (ah *AdminHandlers) GetUsers(c *gin.Context) {
-	response, err := ah.externalService.Call("central", "GET", "/admin/users", nil)
+	filters, ok := sortAndFilter(c, userListFields)
+	if !ok {
+		return
+	}
+	var page models.PaginationParams
+	c.ShouldBindQuery(&page) // Validated by listQuery
+
+	query, cursor, ok := listQuery(c, "users", filters)
+	if !ok {
+		return
+	}
+
+	response, err := ah.externalService.Call(c.Request.Context(), "central", "GET", "/admin/users"+query, nil)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	setNextCursor(response, cursor, "users")
+	users := listItems(response, "users")
+	c.JSON(http.StatusOK, pageOf(response, users, len(users), page, cursor))
 }
 
 // GetUserByID retrieves a specific user by ID
@@ -38,9 +66,9 @@ func (ah *AdminHandlers) GetUserByID(c *gin.Context) {
 	id := c.Param("id")
 	endpoint := "/admin/users/" + id
 
-	response, err := ah.externalService.Call("central", "GET", endpoint, nil)
+	response, err := ah.externalService.Call(c.Request.Context(), "central", "GET", endpoint, nil)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
@@ -55,9 +83,9 @@ func (ah *AdminHandlers) CreateUser(c *gin.Context) {
 		return
 	}
 
-	response, err := ah.externalService.Call("central", "POST", "/admin/users", req)
+	response, err := ah.externalService.Call(c.Request.Context(), "central", "POST", "/admin/users", req)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
@@ -75,9 +103,9 @@ func (ah *AdminHandlers) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	response, err := ah.externalService.Call("central", "PUT", endpoint, req)
+	response, err := ah.externalService.Call(c.Request.Context(), "central", "PUT", endpoint, req)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
@@ -89,9 +117,9 @@ func (ah *AdminHandlers) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
 	endpoint := "/admin/users/" + id
 
-	response, err := ah.externalService.Call("central", "DELETE", endpoint, nil)
+	response, err := ah.externalService.Call(c.Request.Context(), "central", "DELETE", endpoint, nil)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
@@ -100,9 +128,9 @@ func (ah *AdminHandlers) DeleteUser(c *gin.Context) {
 
 // GetRoles retrieves all roles
 func (ah *AdminHandlers) GetRoles(c *gin.Context) {
-	response, err := ah.externalService.Call("central", "GET", "/admin/roles", nil)
+	response, err := ah.externalService.CallCached(c.Request.Context(), "central", "/admin/roles")
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
@@ -120,9 +148,9 @@ func (ah *AdminHandlers) AssignRole(c *gin.Context) {
 		return
 	}
 
-	response, err := ah.externalService.Call("central", "POST", endpoint, req)
+	response, err := ah.externalService.Call(c.Request.Context(), "central", "POST", endpoint, req)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
@@ -135,9 +163,9 @@ func (ah *AdminHandlers) RemoveRole(c *gin.Context) {
 	role := c.Param("role")
 	endpoint := "/admin/users/" + id + "/roles/" + role
 
-	response, err := ah.externalService.Call("central", "DELETE", endpoint, nil)
+	response, err := ah.externalService.Call(c.Request.Context(), "central", "DELETE", endpoint, nil)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
@@ -146,22 +174,237 @@ func (ah *AdminHandlers) RemoveRole(c *gin.Context) {
 
 // GetSystemStats retrieves system statistics
 func (ah *AdminHandlers) GetSystemStats(c *gin.Context) {
-	response, err := ah.externalService.Call("central", "GET", "/admin/system/stats", nil)
+	response, err := ah.externalService.Call(c.Request.Context(), "central", "GET", "/admin/system/stats", nil)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-// GetAuditLogs retrieves audit logs
+// auditLogFilters are the query parameters passed through to Central Management
+var auditLogFilters = []string{"since", "until", "user_id", "action", "resource_type", "resource_id"}
+
+// GetAuditLogs lists audit logs from Central Management, filtered by time
+// range, user, action and resource. Results are paginated by page/page_size
+// or cursor and normalized into a PaginatedResponse of AuditLog entries.
 func (ah *AdminHandlers) GetAuditLogs(c *gin.Context) {
-	response, err := ah.externalService.Call("central", "GET", "/admin/audit-logs", nil)
+	var params models.AuditLogQuery
+	if err := c.ShouldBindQuery(&params); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if params.Since != "" && params.Until != "" {
+		since, _ := time.Parse(time.RFC3339, params.Since)
+		until, _ := time.Parse(time.RFC3339, params.Until)
+		if until.Before(since) {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "until must not be before since")
+			return
+		}
+	}
+	var page models.PaginationParams
+	c.ShouldBindQuery(&page) // Validated by listQuery
+
+	filters := url.Values{}
+	for _, key := range auditLogFilters {
+		if value := c.Query(key); value != "" {
+			filters.Set(key, value)
+		}
+	}
+
+	query, cursor, ok := listQuery(c, "audit_logs", filters)
+	if !ok {
+		return
+	}
+
+	response, err := ah.externalService.Call(c.Request.Context(), "central", "GET", "/admin/audit-logs"+query, nil)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	setNextCursor(response, cursor, "audit_logs")
+	c.JSON(http.StatusOK, auditLogPage(response, page, cursor))
+}
+
+// auditLogPage normalizes a Central Management audit log list
+func auditLogPage(response map[string]interface{}, page models.PaginationParams, cursor *models.Cursor) models.PaginatedResponse {
+	items := listItems(response, "audit_logs")
+	logs := make([]models.AuditLog, 0, len(items))
+	for _, item := range items {
+		if fields, ok := item.(map[string]interface{}); ok {
+			logs = append(logs, normalizeAuditLog(fields))
+		}
+	}
+
+	return pageOf(response, logs, len(logs), page, cursor)
+}
+
+// normalizeAuditLog maps an upstream audit record onto AuditLog, accepting
+// the field names Central Management versions have used
+func normalizeAuditLog(fields map[string]interface{}) models.AuditLog {
+	entry := models.AuditLog{
+		ID:         firstString(fields, "id", "audit_id"),
+		UserID:     firstString(fields, "user_id", "user", "actor"),
+		Action:     firstString(fields, "action", "event"),
+		Resource:   firstString(fields, "resource", "resource_type"),
+		ResourceID: firstString(fields, "resource_id"),
+	}
+
+	for _, key := range []string{"timestamp", "created_at", "occurred_at"} {
+		if t, ok := auditTimestamp(fields[key]); ok {
+			entry.Timestamp = t.UTC().Format(time.RFC3339)
+			break
+		}
+	}
+
+	switch details := fields["details"].(type) {
+	case nil:
+	case string:
+		entry.Details = details
+	default:
+		if encoded, err := json.Marshal(details); err == nil {
+			entry.Details = string(encoded)
+		}
+	}
+	return entry
+}
+
+// auditTimestamp reads an RFC3339 string or Unix seconds or milliseconds
+func auditTimestamp(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		return t, err == nil
+	case float64:
+		if v > 1e12 {
+			return time.UnixMilli(int64(v)), true
+		}
+		return time.Unix(int64(v), 0), true
+	}
+	return time.Time{}, false
+}
+
+// firstString returns the first of keys present in fields, as a string
+func firstString(fields map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		switch value := fields[key].(type) {
+		case nil:
+		case float64:
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		default:
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// firstNumber returns the first of keys present in fields as a number
+func firstNumber(fields map[string]interface{}, keys ...string) (int, bool) {
+	for _, key := range keys {
+		if value, ok := fields[key].(float64); ok {
+			return int(value), true
+		}
+	}
+	return 0, false
+}
+
+// csvCell neutralizes a value a spreadsheet would run as a formula by
+// prefixing it with a quote
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// filenamePart replaces everything but letters, digits, dots, dashes and
+// underscores, so a path parameter cannot break out of a
+// Content-Disposition filename
+func filenamePart(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 128 && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_') {
+			return r
+		}
+		return '_'
+	}, value)
+}
+
+// GetResourceAccessReport lists every user who read or modified a resource,
+// built from the audit store, including list responses that returned it.
+// The response states what the report does not cover. Use ?format=csv to
+// export.
+func (ah *AdminHandlers) GetResourceAccessReport(c *gin.Context) {
+	filter := audit.Filter{
+		ResourceType: c.Param("type"),
+		ResourceID:   c.Param("id"),
+		UserID:       c.Query("user_id"),
+		Action:       c.Query("action"),
+	}
+
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "since must be an RFC3339 timestamp")
+			return
+		}
+		filter.Since = t
+	}
+	if until := c.Query("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "until must be an RFC3339 timestamp")
+			return
+		}
+		filter.Until = t
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+	entries, err := audit.Query(ctx, filter)
+	if err != nil {
+		sendError(c, http.StatusServiceUnavailable, "AUDIT_STORE_UNAVAILABLE", err.Error())
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		filename := "access-report-" + filenamePart(filter.ResourceType) + "-" + filenamePart(filter.ResourceID) + ".csv"
+		c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+		c.Header("Content-Type", "text/csv")
+		c.Status(http.StatusOK)
+
+		w := csv.NewWriter(c.Writer)
+		w.Write([]string{"timestamp", "user_id", "action", "method", "path", "ip", "user_agent", "status", "request_id"})
+		for _, e := range entries {
+			w.Write([]string{
+				e.Timestamp.UTC().Format(time.RFC3339),
+				csvCell(e.UserID),
+				csvCell(e.Action),
+				csvCell(e.Method),
+				csvCell(e.Path),
+				csvCell(e.IP),
+				csvCell(e.UserAgent),
+				strconv.Itoa(e.Status),
+				csvCell(e.RequestID),
+			})
+		}
+		w.Flush()
+		return
+	}
+
+	users := make(map[string]int)
+	for _, e := range entries {
+		users[e.UserID]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"resource_type": filter.ResourceType,
+		"resource_id":   filter.ResourceID,
+		"accesses":      entries,
+		"total":         len(entries),
+		"users":         users,
+		"coverage":      audit.ReportCoverage(),
+		"timestamp":     time.Now().Unix(),
+	})
 }
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"InternalAPI/internal/config"
+	"InternalAPI/internal/labels"
 	"InternalAPI/internal/models"
 	"InternalAPI/internal/services"
 
@@ -22,15 +23,39 @@ func NewAlbumHandlers(config *config.Config) *AlbumHandlers {
 	}
 }
 
-// GetAlbums retrieves all albums
+// albumListFields are the fields albums may be sorted and filtered by
+var albumListFields = []string{"title", "artist", "genre", "price"}
+
+// GetAlbums retrieves the albums the user's Central Management filters
+// allow, paginated by page/page_size or cursor, with the sort order and
+// filter expressions passed on to API Beheerder. Paging follows the
+// upstream page, so filtered pages may be short.
 func (ah *AlbumHandlers) GetAlbums(c *gin.Context) {
-	response, err := ah.externalService.Call("beheerder", "GET", "/albums", nil)
+	filters, ok := sortAndFilter(c, albumListFields)
+	if !ok {
+		return
+	}
+	var page models.PaginationParams
+	c.ShouldBindQuery(&page) // Validated by listQuery
+
+	query, cursor, ok := listQuery(c, "albums", filters)
+	if !ok {
+		return
+	}
+
+	response, err := ah.externalService.Call(c.Request.Context(), "beheerder", "GET", "/albums"+query, nil)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	setNextCursor(response, cursor, "albums")
+	count := len(listItems(response, "albums"))
+	if !applyListFilter(c, response, "albums") {
+		return
+	}
+	labels.Transform(response, albumLabelFields, labelContext(c))
+	c.JSON(http.StatusOK, pageOf(response, listItems(response, "albums"), count, page, cursor))
 }
 
 // GetAlbumByID retrieves a specific album by ID
@@ -38,26 +63,31 @@ func (ah *AlbumHandlers) GetAlbumByID(c *gin.Context) {
 	id := c.Param("id")
 	endpoint := "/albums/" + id
 
-	response, err := ah.externalService.Call("beheerder", "GET", endpoint, nil)
+	response, err := ah.externalService.Call(c.Request.Context(), "beheerder", "GET", endpoint, nil)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
+	setETag(c, response, "album")
+	labels.Transform(response, albumLabelFields, labelContext(c))
 	c.JSON(http.StatusOK, response)
 }
 
-// CreateAlbum creates a new album
+// CreateAlbum creates a new album after checking it against the business rules
 func (ah *AlbumHandlers) CreateAlbum(c *gin.Context) {
 	var album models.Album
 	if err := c.ShouldBindJSON(&album); err != nil {
 		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
 		return
 	}
+	if !checkBusinessRules(c, "albums", album) {
+		return
+	}
 
-	response, err := ah.externalService.Call("beheerder", "POST", "/albums", album)
+	response, err := ah.externalService.Call(c.Request.Context(), "beheerder", "POST", "/albums", album)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
@@ -74,13 +104,43 @@ func (ah *AlbumHandlers) UpdateAlbum(c *gin.Context) {
 		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
 		return
 	}
+	if album.ID == "" {
+		album.ID = id
+	}
+	if !checkBusinessRules(c, "albums", album) {
+		return
+	}
+
+	response, err := ah.externalService.Call(c.Request.Context(), "beheerder", "PUT", endpoint, album)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// PatchAlbum applies a JSON Merge Patch or JSON Patch to an album and sends
+// the result upstream as a full update
+func (ah *AlbumHandlers) PatchAlbum(c *gin.Context) {
+	endpoint := "/albums/" + c.Param("id")
+	_, patched, ok := loadPatched(c, beheerderBackend(ah.externalService), endpoint, "album")
+	if !ok {
+		return
+	}
+
+	var album models.Album
+	if !decodePatched(c, patched, &album) || !checkBusinessRules(c, "albums", album) {
+		return
+	}
 
-	response, err := ah.externalService.Call("beheerder", "PUT", endpoint, album)
+	response, err := ah.externalService.Call(c.Request.Context(), "beheerder", "PUT", endpoint, album)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
+	setETag(c, response, "album")
 	c.JSON(http.StatusOK, response)
 }
 
@@ -89,9 +149,9 @@ func (ah *AlbumHandlers) DeleteAlbum(c *gin.Context) {
 	id := c.Param("id")
 	endpoint := "/albums/" + id
 
-	response, err := ah.externalService.Call("beheerder", "DELETE", endpoint, nil)
+	response, err := ah.externalService.Call(c.Request.Context(), "beheerder", "DELETE", endpoint, nil)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "SERVICE_ERROR", err.Error())
+		sendServiceError(c, "SERVICE_ERROR", err)
 		return
 	}
 
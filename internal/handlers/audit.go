@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/audit"
+	"InternalAPI/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// recordAuditEvent writes a domain event such as a status change or an
+// erasure to the audit store, next to the request entry the audit
+// middleware records
+func recordAuditEvent(c *gin.Context, action, resourceType, resourceID string) {
+	audit.Record(audit.Entry{
+		ID:           uuid.New().String(),
+		RequestID:    c.GetString("request_id"),
+		Timestamp:    time.Now(),
+		Method:       c.Request.Method,
+		Path:         c.Request.URL.Path,
+		Route:        c.FullPath(),
+		IP:           c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+		UserID:       c.GetString("userID"),
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Status:       http.StatusOK,
+		Backend:      c.GetString("pms_backend"),
+	})
+}
+
+// captureDefaultRequest changes the default audit capture policy
+type captureDefaultRequest struct {
+	Policy string `json:"policy" binding:"required"`
+}
+
+// captureRouteRequest overrides the audit capture policy for one route,
+// given as "METHOD /path" with gin route parameters such as :id
+type captureRouteRequest struct {
+	Route  string `json:"route" binding:"required"`
+	Policy string `json:"policy" binding:"required"`
+}
+
+// GetAuditCaptureHandler returns the default audit capture policy and the
+// per-route overrides
+func GetAuditCaptureHandler(c *gin.Context) {
+	defaultPolicy, routes := middleware.CapturePolicies()
+
+	c.JSON(http.StatusOK, gin.H{
+		"default": defaultPolicy,
+		"routes":  routes,
+		"count":   len(routes),
+	})
+}
+
+// SetDefaultAuditCaptureHandler changes the policy for routes without an override
+func SetDefaultAuditCaptureHandler(c *gin.Context) {
+	var req captureDefaultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	policy, err := middleware.ParseCapturePolicy(req.Policy)
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_CAPTURE_POLICY", err.Error())
+		return
+	}
+
+	middleware.SetDefaultCapturePolicy(policy)
+	recordAuditEvent(c, "audit_capture_changed", "audit_capture", "default")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Default audit capture policy updated",
+		"default": policy,
+	})
+}
+
+// SetRouteAuditCaptureHandler overrides the capture policy for one route
+func SetRouteAuditCaptureHandler(c *gin.Context) {
+	var req captureRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	policy, err := middleware.ParseCapturePolicy(req.Policy)
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_CAPTURE_POLICY", err.Error())
+		return
+	}
+
+	route, err := middleware.SetRouteCapturePolicy(req.Route, policy)
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	recordAuditEvent(c, "audit_capture_changed", "audit_capture", route)
+
+	c.JSON(http.StatusOK, middleware.CaptureRule{Route: route, Policy: policy})
+}
+
+// ClearRouteAuditCaptureHandler removes the override for ?route= so the
+// default policy applies again
+func ClearRouteAuditCaptureHandler(c *gin.Context) {
+	route := c.Query("route")
+
+	if !middleware.ClearRouteCapturePolicy(route) {
+		sendError(c, http.StatusNotFound, "CAPTURE_RULE_NOT_FOUND", "No audit capture override exists for "+route)
+		return
+	}
+	recordAuditEvent(c, "audit_capture_changed", "audit_capture", route)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Audit capture override for " + route + " removed",
+	})
+}
+
+// VerifyAuditChainHandler checks the hash chain of the retained audit
+// entries against each other and the anchors taken so far
+func VerifyAuditChainHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"verification": audit.Verify(),
+		"anchors":      audit.Anchors(),
+	})
+}
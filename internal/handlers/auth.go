@@ -1,9 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"InternalAPI/internal/auth"
+	"InternalAPI/internal/auth/lockout"
 	"InternalAPI/internal/config"
+	"InternalAPI/internal/middleware"
 	"InternalAPI/internal/models"
 	"InternalAPI/internal/services"
 
@@ -12,14 +20,32 @@ import (
 
 // AuthHandlers contains all authentication-related handlers
 type AuthHandlers struct {
-	externalService *services.ExternalService
+	externalService   *services.ExternalService
+	oidc              *auth.OIDCProvider
+	postLoginRedirect string
+	cookieSecure      bool
 }
 
 // NewAuthHandlers creates a new auth handlers instance
 func NewAuthHandlers(config *config.Config) *AuthHandlers {
-	return &AuthHandlers{
-		externalService: services.New(config),
+	ah := &AuthHandlers{
+		externalService:   services.New(config),
+		postLoginRedirect: config.OIDCPostLoginRedirect,
+		cookieSecure:      config.CookieSecure,
 	}
+
+	if config.OIDCEnabled {
+		ah.oidc = auth.NewOIDCProvider(auth.OIDCConfig{
+			IssuerURL:    config.OIDCIssuerURL,
+			ClientID:     config.OIDCClientID,
+			ClientSecret: config.OIDCClientSecret,
+			RedirectURL:  config.OIDCRedirectURL,
+			Scopes:       strings.Fields(config.OIDCScopes),
+			RolesClaim:   config.OIDCRolesClaim,
+		})
+	}
+
+	return ah
 }
 
 // Login handles user login
@@ -30,41 +56,194 @@ func (ah *AuthHandlers) Login(c *gin.Context) {
 		return
 	}
 
+	// Locked accounts are rejected without asking Central Management
+	if locked, until := lockout.Check(req.Username); locked {
+		sendAccountLocked(c, until)
+		return
+	}
+
 	// Call central management service for authentication
 	authData := map[string]interface{}{
 		"username": req.Username,
 		"password": req.Password,
 	}
 
-	response, err := ah.externalService.Call("central", "POST", "/auth/login", authData)
+	response, err := ah.externalService.Call(c.Request.Context(), "central", "POST", "/auth/login", authData)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "AUTH_SERVICE_ERROR", err.Error())
+		// Only rejected credentials count towards a lockout, not outages
+		var statusErr *services.ServiceError
+		if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden) {
+			if locked, until := lockout.RecordFailure(req.Username, c.ClientIP()); locked {
+				sendAccountLocked(c, until)
+				return
+			}
+		}
+		sendServiceError(c, "AUTH_SERVICE_ERROR", err)
 		return
 	}
+	lockout.RecordSuccess(req.Username)
 
-	c.JSON(http.StatusOK, response)
+	// Issue tokens locally for the authenticated user
+	user := userFromAuthResponse(response, req.Username)
+	tokens, err := auth.IssueTokens(user)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "TOKEN_ISSUE_FAILED", "Failed to issue tokens")
+		return
+	}
+	tokens.User = &user
+
+	if !setAuthCookies(c, tokens) {
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// setAuthCookies issues auth cookies when cookie auth is enabled. It writes
+// the error response and returns false on failure.
+func setAuthCookies(c *gin.Context, tokens *models.LoginResponse) bool {
+	csrfToken, err := middleware.SetAuthCookies(c, tokens.AccessToken, time.Duration(tokens.ExpiresIn)*time.Second, tokens.RefreshToken)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "TOKEN_ISSUE_FAILED", "Failed to issue CSRF token")
+		return false
+	}
+	tokens.CSRFToken = csrfToken
+	return true
+}
+
+// sendAccountLocked rejects a login for a locked account with a Retry-After hint
+func sendAccountLocked(c *gin.Context, until time.Time) {
+	retryAfter := int(time.Until(until).Seconds()) + 1
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	sendError(c, http.StatusLocked, "ACCOUNT_LOCKED", "Account is temporarily locked after too many failed login attempts")
+}
+
+// OIDCLogin starts the OIDC authorization code flow by redirecting to the identity provider
+func (ah *AuthHandlers) OIDCLogin(c *gin.Context) {
+	if ah.oidc == nil {
+		sendError(c, http.StatusNotFound, "OIDC_DISABLED", "Single sign-on is not enabled")
+		return
+	}
+
+	authURL, state, err := ah.oidc.AuthCodeURL()
+	if err != nil {
+		sendError(c, http.StatusBadGateway, "OIDC_PROVIDER_ERROR", err.Error())
+		return
+	}
+
+	ah.setOIDCStateCookie(c, state, int(auth.OIDCLoginTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// setOIDCStateCookie sets or, with a negative maxAge, clears the login state
+// cookie. It is sent only to the OIDC routes, and SameSite=Lax still sends
+// it on the provider's top-level redirect back to the callback.
+func (ah *AuthHandlers) setOIDCStateCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     auth.OIDCStateCookie,
+		Value:    value,
+		Path:     "/auth/oidc",
+		MaxAge:   maxAge,
+		Secure:   ah.cookieSecure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// OIDCCallback completes the OIDC flow and exchanges the IdP identity for our own tokens
+func (ah *AuthHandlers) OIDCCallback(c *gin.Context) {
+	if ah.oidc == nil {
+		sendError(c, http.StatusNotFound, "OIDC_DISABLED", "Single sign-on is not enabled")
+		return
+	}
+
+	// The login state is single use, whatever the outcome
+	stateCookie, _ := c.Cookie(auth.OIDCStateCookie)
+	ah.setOIDCStateCookie(c, "", -1)
+
+	if errParam := c.Query("error"); errParam != "" {
+		sendError(c, http.StatusUnauthorized, "OIDC_LOGIN_FAILED", c.DefaultQuery("error_description", errParam))
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "code and state are required")
+		return
+	}
+
+	user, err := ah.oidc.Exchange(code, state, stateCookie)
+	if err != nil {
+		sendError(c, http.StatusUnauthorized, "OIDC_LOGIN_FAILED", err.Error())
+		return
+	}
+
+	tokens, err := auth.IssueTokens(user)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "TOKEN_ISSUE_FAILED", "Failed to issue tokens")
+		return
+	}
+
+	if !setAuthCookies(c, tokens) {
+		return
+	}
+
+	// With cookie auth the cookies carry the session; otherwise hand tokens to
+	// the portal in the URL fragment so they never reach server logs
+	if ah.postLoginRedirect != "" && middleware.CookieAuthEnabled() {
+		c.Redirect(http.StatusFound, ah.postLoginRedirect)
+		return
+	}
+	if ah.postLoginRedirect != "" {
+		fragment := url.Values{}
+		fragment.Set("access_token", tokens.AccessToken)
+		fragment.Set("refresh_token", tokens.RefreshToken)
+		fragment.Set("expires_in", strconv.Itoa(tokens.ExpiresIn))
+		fragment.Set("token_type", tokens.TokenType)
+		c.Redirect(http.StatusFound, ah.postLoginRedirect+"#"+fragment.Encode())
+		return
+	}
+
+	tokens.User = &user
+	c.JSON(http.StatusOK, tokens)
 }
 
 // RefreshToken handles token refresh
 func (ah *AuthHandlers) RefreshToken(c *gin.Context) {
 	var req models.RefreshTokenRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+	if req.RefreshToken == "" {
+		req.RefreshToken = middleware.RefreshTokenFromCookie(c)
+	}
+	if req.RefreshToken == "" {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "refresh_token is required")
 		return
 	}
 
-	// Call central management service for token refresh
-	refreshData := map[string]interface{}{
-		"refresh_token": req.RefreshToken,
+	// Rotate the refresh token; the presented token cannot be used again
+	tokens, err := auth.RotateRefreshToken(req.RefreshToken)
+	switch {
+	case errors.Is(err, auth.ErrRefreshTokenReused):
+		sendError(c, http.StatusUnauthorized, "REFRESH_TOKEN_REUSED", "Refresh token was already used; all related sessions have been revoked")
+		return
+	case errors.Is(err, auth.ErrInvalidRefreshToken), errors.Is(err, auth.ErrRefreshTokenExpired):
+		sendError(c, http.StatusUnauthorized, "INVALID_REFRESH_TOKEN", err.Error())
+		return
+	case err != nil:
+		sendError(c, http.StatusInternalServerError, "TOKEN_ISSUE_FAILED", "Failed to issue tokens")
+		return
 	}
 
-	response, err := ah.externalService.Call("central", "POST", "/auth/refresh", refreshData)
-	if err != nil {
-		sendError(c, http.StatusInternalServerError, "AUTH_SERVICE_ERROR", err.Error())
+	if !setAuthCookies(c, tokens) {
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, tokens)
 }
 
 // Logout handles user logout
@@ -80,12 +259,47 @@ func (ah *AuthHandlers) Logout(c *gin.Context) {
 		"token": token,
 	}
 
-	_, err := ah.externalService.Call("central", "POST", "/auth/logout", logoutData)
+	_, err := ah.externalService.Call(c.Request.Context(), "central", "POST", "/auth/logout", logoutData)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "AUTH_SERVICE_ERROR", err.Error())
+		sendServiceError(c, "AUTH_SERVICE_ERROR", err)
 		return
 	}
 
+	// End only this session's refresh token family; the user's other
+	// sessions stay signed in. Without a refresh token the family comes
+	// from the access token's fid claim.
+	var req models.RefreshTokenRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+	if req.RefreshToken == "" {
+		req.RefreshToken = middleware.RefreshTokenFromCookie(c)
+	}
+	if req.RefreshToken != "" {
+		err = auth.RevokeRefreshFamily(req.RefreshToken)
+	} else if claims, claimsErr := middleware.ValidateJWT(token.(string)); claimsErr == nil && claims.FamilyID != "" {
+		err = auth.RevokeRefreshFamilyID(claims.FamilyID)
+	}
+	if err != nil && !errors.Is(err, auth.ErrNotInitialized) {
+		sendError(c, http.StatusInternalServerError, "REVOCATION_FAILED", "Failed to revoke refresh token")
+		return
+	}
+
+	// Revoke the token locally until it expires
+	expiresAt := time.Now().Add(24 * time.Hour)
+	if user, ok := c.Get("user"); ok {
+		expiresAt = time.Unix(user.(*models.UserInfo).Exp, 0)
+	}
+	if err := middleware.BlacklistToken(token.(string), expiresAt); err != nil {
+		sendError(c, http.StatusInternalServerError, "REVOCATION_FAILED", "Failed to revoke token")
+		return
+	}
+
+	middleware.ClearAuthCookies(c)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Successfully logged out",
 	})
@@ -102,6 +316,56 @@ func (ah *AuthHandlers) GetUserInfo(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+// GetTokenStatus reports the remaining lifetime of the caller's access token
+// and whether to extend or refresh it now
+func (ah *AuthHandlers) GetTokenStatus(c *gin.Context) {
+	token, exists := c.Get("token")
+	if !exists {
+		sendError(c, http.StatusUnauthorized, "MISSING_TOKEN", "Token not found")
+		return
+	}
+
+	status, err := auth.TokenStatus(token.(string))
+	if err != nil {
+		sendError(c, http.StatusUnauthorized, "INVALID_TOKEN", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// ExtendToken issues a fresh access token for roles the extension policy
+// allows, up to the maximum number of extensions per session. Every attempt
+// is audited.
+func (ah *AuthHandlers) ExtendToken(c *gin.Context) {
+	token, exists := c.Get("token")
+	if !exists {
+		sendError(c, http.StatusUnauthorized, "MISSING_TOKEN", "Token not found")
+		return
+	}
+	userID := c.GetString("userID")
+
+	extension, err := auth.ExtendAccessToken(token.(string))
+	switch {
+	case errors.Is(err, auth.ErrExtensionNotAllowed):
+		recordAuditEvent(c, "token_extension_denied", "sessions", userID)
+		sendError(c, http.StatusForbidden, "TOKEN_EXTENSION_NOT_ALLOWED", err.Error())
+		return
+	case errors.Is(err, auth.ErrExtensionLimit):
+		recordAuditEvent(c, "token_extension_denied", "sessions", userID)
+		sendError(c, http.StatusForbidden, "TOKEN_EXTENSION_LIMIT", err.Error())
+		return
+	case err != nil:
+		sendError(c, http.StatusInternalServerError, "TOKEN_ISSUE_FAILED", "Failed to extend token")
+		return
+	}
+
+	middleware.SetAccessCookie(c, extension.AccessToken, time.Duration(extension.ExpiresIn)*time.Second)
+	recordAuditEvent(c, "token_extended", "sessions", userID)
+
+	c.JSON(http.StatusOK, extension)
+}
+
 // ChangePassword handles password change requests
 func (ah *AuthHandlers) ChangePassword(c *gin.Context) {
 	var req models.ChangePasswordRequest
@@ -125,11 +389,45 @@ func (ah *AuthHandlers) ChangePassword(c *gin.Context) {
 		"new_password":     req.NewPassword,
 	}
 
-	response, err := ah.externalService.Call("central", "PUT", "/auth/change-password", changeData)
+	response, err := ah.externalService.Call(c.Request.Context(), "central", "PUT", "/auth/change-password", changeData)
 	if err != nil {
-		sendError(c, http.StatusInternalServerError, "AUTH_SERVICE_ERROR", err.Error())
+		sendServiceError(c, "AUTH_SERVICE_ERROR", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// userFromAuthResponse builds user info from the central management login
+// response, which may nest the user under a "user" key
+func userFromAuthResponse(response map[string]interface{}, username string) models.UserInfo {
+	source := response
+	if nested, ok := response["user"].(map[string]interface{}); ok {
+		source = nested
+	}
+
+	user := models.UserInfo{Username: username}
+	if v, ok := source["user_id"].(string); ok {
+		user.UserID = v
+	} else if v, ok := source["id"].(string); ok {
+		user.UserID = v
+	}
+	if v, ok := source["username"].(string); ok {
+		user.Username = v
+	}
+	if v, ok := source["email"].(string); ok {
+		user.Email = v
+	}
+	if roles, ok := source["roles"].([]interface{}); ok {
+		for _, r := range roles {
+			if role, ok := r.(string); ok {
+				user.Roles = append(user.Roles, role)
+			}
+		}
+	}
+	if user.UserID == "" {
+		user.UserID = user.Username
+	}
+
+	return user
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/cache"
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/labels"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAvailabilityNights bounds the date range of a single search
+const maxAvailabilityNights = 30
+
+// AvailabilityHandlers merges room inventory and rate restrictions into a
+// single availability search
+type AvailabilityHandlers struct {
+	externalService *services.ExternalService
+	cache           *cache.Cache
+	cacheTTL        time.Duration
+}
+
+// availabilitySources holds the raw upstream responses for a date range.
+// Restrictions and bookings are optional: when they fail the search still
+// answers from inventory and the failure is kept in failures.
+type availabilitySources struct {
+	inventory    map[string]interface{}
+	restrictions map[string]interface{}
+	bookings     map[string]interface{}
+	failures     map[string]error // source name -> error
+}
+
+// unavailable lists the optional sources that failed, in a stable order
+func (s availabilitySources) unavailable() []string {
+	names := make([]string, 0, len(s.failures))
+	for name := range s.failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// err returns the first optional source failure, or nil when every source answered
+func (s availabilitySources) err() error {
+	names := s.unavailable()
+	if len(names) == 0 {
+		return nil
+	}
+	return s.failures[names[0]]
+}
+
+// NewAvailabilityHandlers creates a new availability handlers instance
+func NewAvailabilityHandlers(config *config.Config) *AvailabilityHandlers {
+	return &AvailabilityHandlers{
+		externalService: services.New(config),
+		cache:           cache.New(),
+		cacheTTL:        config.AvailabilityCacheTTL,
+	}
+}
+
+// SearchAvailability returns bookable room types with pricing for a stay and
+// party size. Inventory and existing bookings come from API Beheerder and rate
+// restrictions from Central Management; all three are fetched in parallel and
+// cached per hotel and date range. Without inventory the search fails; when
+// bookings or restrictions are unavailable the response is marked partial.
+func (ah *AvailabilityHandlers) SearchAvailability(c *gin.Context) {
+	var query models.AvailabilityQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	checkIn, _ := time.Parse("2006-01-02", query.CheckIn)
+	checkOut, _ := time.Parse("2006-01-02", query.CheckOut)
+	nights := int(checkOut.Sub(checkIn).Hours() / 24)
+	if nights < 1 || nights > maxAvailabilityNights {
+		sendError(c, http.StatusBadRequest, "INVALID_DATE_RANGE", "check_out must be 1 to 30 nights after check_in")
+		return
+	}
+
+	sources, cached, err := ah.fetchSources(c.Request.Context(), query)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	rooms := mergeAvailability(sources, query, nights)
+	rooms = filterAvailability(rooms, query)
+
+	ctx := labelContext(c)
+	for i := range rooms {
+		if rooms[i].Reason != "" {
+			rooms[i].ReasonLabel = labels.Lookup(ctx.Tenant, "availability_reason", rooms[i].Reason, ctx.Locale, ctx.Audience)
+		}
+	}
+
+	c.JSON(http.StatusOK, models.AvailabilityResponse{
+		HotelID:  query.HotelID,
+		CheckIn:  query.CheckIn,
+		CheckOut: query.CheckOut,
+		Nights:   nights,
+		Rooms:    rooms,
+		Count:    len(rooms),
+		Cached:   cached,
+		Partial:  len(sources.failures) > 0,
+		Missing:  sources.unavailable(),
+	})
+}
+
+// fetchSources returns the upstream data for a date range, from cache when
+// possible. User filters are applied afterwards so they share cache entries.
+// Partial results are not cached.
+func (ah *AvailabilityHandlers) fetchSources(ctx context.Context, query models.AvailabilityQuery) (availabilitySources, bool, error) {
+	key := query.HotelID + "|" + query.CheckIn + "|" + query.CheckOut
+	if cached, ok := ah.cache.Get(key); ok {
+		return cached.(availabilitySources), true, nil
+	}
+
+	sources, err := ah.loadSources(ctx, query)
+	if err != nil {
+		return sources, false, err
+	}
+
+	if len(sources.failures) == 0 {
+		ah.cache.Set(key, sources, ah.cacheTTL)
+	}
+	return sources, false, nil
+}
+
+// loadSources fetches inventory, restrictions and overlapping bookings for a
+// date range from the upstreams in parallel, bypassing the cache. Only an
+// inventory failure is returned as an error.
+func (ah *AvailabilityHandlers) loadSources(ctx context.Context, query models.AvailabilityQuery) (availabilitySources, error) {
+	params := url.Values{}
+	params.Set("check_in", query.CheckIn)
+	params.Set("check_out", query.CheckOut)
+	if query.HotelID != "" {
+		params.Set("hotel_id", query.HotelID)
+	}
+	qs := "?" + params.Encode()
+
+	// Bookings starting before check-out; those ending before check-in are
+	// dropped when counting
+	bookingParams := url.Values{}
+	bookingParams.Set("check_in_to", query.CheckOut)
+	if query.HotelID != "" {
+		bookingParams.Set("hotel_id", query.HotelID)
+	}
+
+	var (
+		sources                               availabilitySources
+		inventoryErr, restrictErr, bookingErr error
+		wg                                    sync.WaitGroup
+	)
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		sources.inventory, inventoryErr = ah.externalService.Call(ctx, "beheerder", "GET", "/rooms/inventory"+qs, nil)
+	}()
+	go func() {
+		defer wg.Done()
+		sources.restrictions, restrictErr = ah.externalService.Call(ctx, "central", "GET", "/rates/restrictions"+qs, nil)
+	}()
+	go func() {
+		defer wg.Done()
+		sources.bookings, bookingErr = ah.externalService.Call(ctx, "beheerder", "GET", "/bookings?"+bookingParams.Encode(), nil)
+	}()
+	wg.Wait()
+
+	if inventoryErr != nil {
+		return sources, inventoryErr
+	}
+	if restrictErr != nil || bookingErr != nil {
+		sources.failures = make(map[string]error)
+	}
+	if restrictErr != nil {
+		sources.failures["restrictions"] = restrictErr
+	}
+	if bookingErr != nil {
+		sources.failures["bookings"] = bookingErr
+	}
+	return sources, nil
+}
+
+// bookedUnits counts the active bookings per room type that overlap the stay
+func bookedUnits(bookings map[string]interface{}, checkIn, checkOut string) map[string]int {
+	items := listField(bookings, "bookings")
+	if len(items) == 0 {
+		items = listField(bookings, "data")
+	}
+
+	booked := make(map[string]int)
+	for _, item := range items {
+		if strings.EqualFold(stringField(item, "status"), "cancelled") {
+			continue
+		}
+		// Dates are YYYY-MM-DD, so string comparison orders them
+		if stringField(item, "check_in") >= checkOut || stringField(item, "check_out") <= checkIn {
+			continue
+		}
+		booked[strings.ToLower(stringField(item, "room_type"))]++
+	}
+	return booked
+}
+
+// mergeAvailability combines inventory, restrictions and bookings per room
+// type. Inventory items with a total are reduced by the overlapping bookings;
+// without a total, or when bookings are unavailable, the inventory's own
+// available count is used.
+func mergeAvailability(sources availabilitySources, query models.AvailabilityQuery, nights int) []models.RoomAvailability {
+	restrictions := make(map[string]map[string]interface{})
+	for _, item := range listField(sources.restrictions, "restrictions") {
+		if roomType := stringField(item, "room_type"); roomType != "" {
+			restrictions[roomType] = item
+		}
+	}
+
+	_, bookingsFailed := sources.failures["bookings"]
+	booked := bookedUnits(sources.bookings, query.CheckIn, query.CheckOut)
+
+	rooms := []models.RoomAvailability{}
+	for _, item := range listField(sources.inventory, "rooms") {
+		room := models.RoomAvailability{
+			RoomType:  stringField(item, "room_type"),
+			Available: int(numberField(item, "available", 0)),
+			Capacity:  int(numberField(item, "capacity", 0)),
+			Currency:  stringField(item, "currency"),
+			Bookable:  true,
+		}
+		if total, ok := item["total"].(float64); ok && !bookingsFailed {
+			room.Available = int(total) - booked[strings.ToLower(room.RoomType)]
+			if room.Available < 0 {
+				room.Available = 0
+			}
+		}
+
+		rate := numberField(item, "base_rate", 0)
+		if restriction, ok := restrictions[room.RoomType]; ok {
+			rate *= numberField(restriction, "rate_multiplier", 1)
+			room.MinStay = int(numberField(restriction, "min_stay", 0))
+
+			switch {
+			case boolField(restriction, "closed"):
+				room.Bookable, room.Reason = false, "closed"
+			case boolField(restriction, "closed_to_arrival"):
+				room.Bookable, room.Reason = false, "closed_to_arrival"
+			case room.MinStay > nights:
+				room.Bookable, room.Reason = false, "min_stay"
+			}
+		}
+		if room.Bookable && room.Available < 1 {
+			room.Bookable, room.Reason = false, "sold_out"
+		}
+
+		room.NightlyRate = roundPrice(rate)
+		room.TotalPrice = roundPrice(rate * float64(nights))
+		rooms = append(rooms, room)
+	}
+
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].NightlyRate < rooms[j].NightlyRate
+	})
+	return rooms
+}
+
+// filterAvailability applies the user's search filters
+func filterAvailability(rooms []models.RoomAvailability, query models.AvailabilityQuery) []models.RoomAvailability {
+	filtered := []models.RoomAvailability{}
+	for _, room := range rooms {
+		if query.AvailableOnly && !room.Bookable {
+			continue
+		}
+		if query.Guests > 0 && room.Capacity < query.Guests {
+			continue
+		}
+		if query.RoomType != "" && !strings.EqualFold(room.RoomType, query.RoomType) {
+			continue
+		}
+		if query.MaxPrice > 0 && room.NightlyRate > query.MaxPrice {
+			continue
+		}
+		filtered = append(filtered, room)
+	}
+	return filtered
+}
+
+// listField returns the objects in a list field of an upstream response
+func listField(data map[string]interface{}, key string) []map[string]interface{} {
+	raw, _ := data[key].([]interface{})
+	items := make([]map[string]interface{}, 0, len(raw))
+	for _, entry := range raw {
+		if item, ok := entry.(map[string]interface{}); ok {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// stringField returns a string field or ""
+func stringField(data map[string]interface{}, key string) string {
+	value, _ := data[key].(string)
+	return value
+}
+
+// numberField returns a numeric field or fallback
+func numberField(data map[string]interface{}, key string, fallback float64) float64 {
+	if value, ok := data[key].(float64); ok {
+		return value
+	}
+	return fallback
+}
+
+// boolField returns a boolean field or false
+func boolField(data map[string]interface{}, key string) bool {
+	value, _ := data[key].(bool)
+	return value
+}
+
+// roundPrice rounds to cents
+func roundPrice(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/callbacks"
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/events"
+	"InternalAPI/internal/labels"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/pms"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// bookingListFilters are the query parameters passed through to API Beheerder
+var bookingListFilters = []string{"hotel_id", "status", "guest_email", "check_in_from", "check_in_to"}
+
+// BookingHandlers contains all booking-related handlers
+type BookingHandlers struct {
+	externalService *services.ExternalService
+	availability    *AvailabilityHandlers
+}
+
+// NewBookingHandlers creates a new booking handlers instance
+func NewBookingHandlers(config *config.Config) *BookingHandlers {
+	return &BookingHandlers{
+		externalService: services.New(config),
+		availability:    NewAvailabilityHandlers(config),
+	}
+}
+
+// GetBookings lists bookings, forwarding the supported filters and applying
+// the user's Central Management filters. Results are paginated by
+// page/page_size or cursor.
+func (bh *BookingHandlers) GetBookings(c *gin.Context) {
+	filters := url.Values{}
+	for _, key := range bookingListFilters {
+		if value := c.Query(key); value != "" {
+			filters.Set(key, value)
+		}
+	}
+
+	query, cursor, ok := listQuery(c, "bookings", filters)
+	if !ok {
+		return
+	}
+
+	response, err := pmsBackend(c, bh.externalService, "").Call(c.Request.Context(), "GET", "/bookings"+query, nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	// Paging follows the upstream page, so filtered pages may be short
+	setNextCursor(response, cursor, "bookings")
+	if !applyListFilter(c, response, "bookings") {
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetBookingByID retrieves a specific booking by ID
+func (bh *BookingHandlers) GetBookingByID(c *gin.Context) {
+	response, err := pmsBackend(c, bh.externalService, "").Call(c.Request.Context(), "GET", "/bookings/"+c.Param("id"), nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	setETag(c, response, "booking")
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateBooking validates the stay, checks that the room type is still
+// bookable for it and creates the booking
+func (bh *BookingHandlers) CreateBooking(c *gin.Context) {
+	var req models.CreateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	booking := models.Booking{
+		HotelID:    req.HotelID,
+		RoomType:   req.RoomType,
+		GuestName:  req.GuestName,
+		GuestEmail: req.GuestEmail,
+		CheckIn:    req.CheckIn,
+		CheckOut:   req.CheckOut,
+		Guests:     req.Guests,
+		Status:     "confirmed",
+		Notes:      req.Notes,
+		CardToken:  req.CardToken,
+	}
+
+	nights, msg := validateStay(booking.CheckIn, booking.CheckOut, false)
+	if msg != "" {
+		sendError(c, http.StatusBadRequest, "INVALID_DATE_RANGE", msg)
+		return
+	}
+	if !bh.checkAvailability(c, booking, nights, false) {
+		return
+	}
+
+	backend := pmsBackend(c, bh.externalService, booking.HotelID)
+	response, err := backend.Call(c.Request.Context(), "POST", "/bookings", booking)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	// API Beheerder reports its own bookings through callbacks when they are
+	// enabled; other backends have no way to, so the gateway announces them
+	if backend.Name() != pms.DefaultBackend || !callbacks.Enabled() {
+		publishBookingCreated(c, response, booking)
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// publishBookingCreated announces a new booking on the event bus. Guest
+// details are left out; subscribers fetch the booking if they need them.
+func publishBookingCreated(c *gin.Context, response map[string]interface{}, booking models.Booking) {
+	created, _ := response["booking"].(map[string]interface{})
+	bookingID, _ := created["id"].(string)
+
+	events.Publish(events.Event{
+		ID:         uuid.New().String(),
+		Type:       "reservation.created",
+		Source:     "internal-api",
+		OccurredAt: time.Now(),
+		Data: map[string]interface{}{
+			"booking_id": bookingID,
+			"hotel_id":   booking.HotelID,
+			"room_type":  booking.RoomType,
+			"check_in":   booking.CheckIn,
+			"check_out":  booking.CheckOut,
+			"guests":     booking.Guests,
+			"status":     booking.Status,
+			"created_by": c.GetString("userID"),
+		},
+	})
+}
+
+// UpdateBooking applies a partial update. Changes to the stay are validated
+// and re-checked against availability before they are sent upstream.
+func (bh *BookingHandlers) UpdateBooking(c *gin.Context) {
+	var req models.UpdateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	endpoint := "/bookings/" + c.Param("id")
+	response, err := pmsBackend(c, bh.externalService, "").Call(c.Request.Context(), "GET", endpoint, nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+	current, err := bookingFromResponse(response)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	bh.saveBooking(c, endpoint, current, applyBookingUpdate(current, req))
+}
+
+// PatchBooking applies a JSON Merge Patch or JSON Patch to a booking. The
+// result is validated like a PUT and sent upstream as a full update.
+func (bh *BookingHandlers) PatchBooking(c *gin.Context) {
+	endpoint := "/bookings/" + c.Param("id")
+	record, patched, ok := loadPatched(c, pmsBackend(c, bh.externalService, ""), endpoint, "booking")
+	if !ok {
+		return
+	}
+	if rejectChanged(c, record, patched, "hotel_id", "card_token") {
+		return
+	}
+
+	var req models.UpdateBookingRequest
+	if !decodePatched(c, patched, &req) {
+		return
+	}
+
+	current, err := bookingFromResponse(record)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+	updated, err := bookingFromResponse(patched)
+	if err != nil {
+		sendError(c, http.StatusUnprocessableEntity, "INVALID_PATCH", err.Error())
+		return
+	}
+
+	bh.saveBooking(c, endpoint, current, updated)
+}
+
+// saveBooking validates changes to the stay, re-checks availability and
+// sends the updated booking upstream
+func (bh *BookingHandlers) saveBooking(c *gin.Context, endpoint string, current, updated models.Booking) {
+	stayChanged := updated.RoomType != current.RoomType || updated.CheckIn != current.CheckIn ||
+		updated.CheckOut != current.CheckOut || updated.Guests > current.Guests
+	if stayChanged && updated.Status != "cancelled" {
+		// Guests already in house may extend their stay past a check-in in the past
+		nights, msg := validateStay(updated.CheckIn, updated.CheckOut, updated.CheckIn == current.CheckIn)
+		if msg != "" {
+			sendError(c, http.StatusBadRequest, "INVALID_DATE_RANGE", msg)
+			return
+		}
+
+		// The booking's own room counts against inventory while the stays overlap
+		ownUnit := strings.EqualFold(updated.RoomType, current.RoomType) &&
+			updated.CheckIn < current.CheckOut && current.CheckIn < updated.CheckOut
+		if !bh.checkAvailability(c, updated, nights, ownUnit) {
+			return
+		}
+	}
+
+	response, err := pmsBackend(c, bh.externalService, "").Call(c.Request.Context(), "PUT", endpoint, updated)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	setETag(c, response, "booking")
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteBooking deletes a booking
+func (bh *BookingHandlers) DeleteBooking(c *gin.Context) {
+	response, err := pmsBackend(c, bh.externalService, "").Call(c.Request.Context(), "DELETE", "/bookings/"+c.Param("id"), nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// checkAvailability rejects a stay the room type cannot take, using fresh
+// upstream data rather than the search cache. ownUnit tolerates a sold-out
+// room type when the booking itself holds one of its rooms. It writes the
+// error response and returns false on failure.
+func (bh *BookingHandlers) checkAvailability(c *gin.Context, booking models.Booking, nights int, ownUnit bool) bool {
+	query := models.AvailabilityQuery{
+		HotelID:  booking.HotelID,
+		CheckIn:  booking.CheckIn,
+		CheckOut: booking.CheckOut,
+	}
+	sources, err := bh.availability.loadSources(c.Request.Context(), query)
+	if err == nil {
+		// Bookings are only accepted against complete availability data
+		err = sources.err()
+	}
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return false
+	}
+
+	for _, room := range mergeAvailability(sources, query, nights) {
+		if !strings.EqualFold(room.RoomType, booking.RoomType) {
+			continue
+		}
+		if room.Capacity > 0 && booking.Guests > room.Capacity {
+			sendError(c, http.StatusConflict, "ROOM_UNAVAILABLE", fmt.Sprintf("Room type %s holds at most %d guests", room.RoomType, room.Capacity))
+			return false
+		}
+		if !room.Bookable && !(ownUnit && room.Reason == "sold_out") {
+			ctx := labelContext(c)
+			reason := labels.Lookup(ctx.Tenant, "availability_reason", room.Reason, ctx.Locale, ctx.Audience)
+			sendError(c, http.StatusConflict, "ROOM_UNAVAILABLE", fmt.Sprintf("Room type %s is not available for these dates: %s", room.RoomType, reason))
+			return false
+		}
+		return true
+	}
+
+	sendError(c, http.StatusConflict, "ROOM_UNAVAILABLE", fmt.Sprintf("Room type %s is not offered for these dates", booking.RoomType))
+	return false
+}
+
+// validateStay checks the check-in and check-out dates and returns the number
+// of nights, or a message describing why the stay is invalid
+func validateStay(checkIn, checkOut string, allowPastCheckIn bool) (int, string) {
+	in, err := time.Parse("2006-01-02", checkIn)
+	if err != nil {
+		return 0, "check_in must be a date in YYYY-MM-DD format"
+	}
+	out, err := time.Parse("2006-01-02", checkOut)
+	if err != nil {
+		return 0, "check_out must be a date in YYYY-MM-DD format"
+	}
+
+	nights := int(out.Sub(in).Hours() / 24)
+	if nights < 1 || nights > maxAvailabilityNights {
+		return 0, "check_out must be 1 to 30 nights after check_in"
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if !allowPastCheckIn && in.Before(today) {
+		return 0, "check_in cannot be in the past"
+	}
+
+	return nights, ""
+}
+
+// applyBookingUpdate returns the booking with the non-empty update fields applied
+func applyBookingUpdate(booking models.Booking, req models.UpdateBookingRequest) models.Booking {
+	if req.RoomType != "" {
+		booking.RoomType = req.RoomType
+	}
+	if req.GuestName != "" {
+		booking.GuestName = req.GuestName
+	}
+	if req.GuestEmail != "" {
+		booking.GuestEmail = req.GuestEmail
+	}
+	if req.CheckIn != "" {
+		booking.CheckIn = req.CheckIn
+	}
+	if req.CheckOut != "" {
+		booking.CheckOut = req.CheckOut
+	}
+	if req.Guests > 0 {
+		booking.Guests = req.Guests
+	}
+	if req.Status != "" {
+		booking.Status = req.Status
+	}
+	if req.Notes != "" {
+		booking.Notes = req.Notes
+	}
+	return booking
+}
+
+// bookingFromResponse decodes an API Beheerder booking, which may be nested
+// under a "booking" key
+func bookingFromResponse(response map[string]interface{}) (models.Booking, error) {
+	source := response
+	if nested, ok := response["booking"].(map[string]interface{}); ok {
+		source = nested
+	}
+
+	var booking models.Booking
+	raw, err := json.Marshal(source)
+	if err != nil {
+		return booking, err
+	}
+	if err := json.Unmarshal(raw, &booking); err != nil {
+		return booking, fmt.Errorf("unexpected booking response: %v", err)
+	}
+	return booking, nil
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"InternalAPI/internal/callbacks"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BeheerderCallbackHandler receives event callbacks pushed by API Beheerder.
+// Events are verified and durably buffered before they are acknowledged, so
+// a 2xx response means the event will be processed exactly once.
+func BeheerderCallbackHandler(c *gin.Context) {
+	if !callbacks.Enabled() {
+		sendError(c, http.StatusServiceUnavailable, "WEBHOOK_NOT_CONFIGURED", "Upstream callbacks are not configured")
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_WEBHOOK_PAYLOAD", "Unable to read callback body")
+		return
+	}
+
+	if err := callbacks.Verify(c.GetHeader(callbacks.TimestampHeader), c.GetHeader(callbacks.SignatureHeader), body); err != nil {
+		sendError(c, http.StatusUnauthorized, "INVALID_SIGNATURE", "Callback signature is missing, stale or invalid")
+		return
+	}
+
+	eventID, accepted, err := callbacks.Receive(body)
+	if err != nil {
+		if errors.Is(err, callbacks.ErrInvalidPayload) {
+			sendError(c, http.StatusBadRequest, "INVALID_WEBHOOK_PAYLOAD", "Callback must be a JSON event with id and type")
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "WEBHOOK_BUFFER_FAILED", "Callback could not be stored, please retry")
+		return
+	}
+
+	if !accepted {
+		c.JSON(http.StatusOK, gin.H{"event_id": eventID, "status": "duplicate"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"event_id": eventID, "status": "accepted"})
+}
+
+// GetCallbackStatsHandler returns buffered callback counts per status
+func GetCallbackStatsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"beheerder": callbacks.Stats(),
+	})
+}
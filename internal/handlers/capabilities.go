@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CapabilityHandlers reports which optional gateway features are active
+type CapabilityHandlers struct {
+	config *config.Config
+}
+
+// NewCapabilityHandlers creates a new capability handlers instance
+func NewCapabilityHandlers(config *config.Config) *CapabilityHandlers {
+	return &CapabilityHandlers{
+		config: config,
+	}
+}
+
+// GetCapabilities returns the features enabled in this deployment so the
+// portal can adapt its UI without environment-specific builds
+func (ch *CapabilityHandlers) GetCapabilities(c *gin.Context) {
+	cfg := ch.config
+
+	features := gin.H{
+		"websockets":       cfg.FeatureWebSockets,
+		"two_factor_auth":  cfg.FeatureTwoFactor,
+		"payments":         cfg.FeaturePayments,
+		"partner_api":      cfg.FeaturePartnerAPI,
+		"token_refresh":    true,
+		"sso":              cfg.OIDCEnabled,
+		"login_challenge":  cfg.ReputationEnabled,
+		"field_encryption": cfg.FieldEncryptionEnabled,
+		"audit_logging":    cfg.EnableAuditLogging,
+		"rate_limiting":    cfg.RateLimitEnabled,
+		"usage_reports":    cfg.UsageTrackingEnabled,
+		"graphql":          cfg.GraphQLEnabled,
+		"webhooks":         cfg.OutboundWebhooksEnabled,
+		"async_jobs":       cfg.JobsEnabled,
+	}
+
+	// Additional free-form feature flags
+	for _, flag := range splitList(cfg.FeatureFlags) {
+		features[flag] = true
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"features": features,
+		"locales":  splitList(cfg.SupportedLocales),
+		"limits": gin.H{
+			"max_request_body_bytes": cfg.MaxRequestBodySize,
+			"rate_limit_requests":    cfg.RateLimitRequests,
+			"rate_limit_interval_s":  cfg.RateLimitInterval.Seconds(),
+		},
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// splitList splits a comma-separated config value, dropping empty entries
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
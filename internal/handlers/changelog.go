@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"InternalAPI/internal/changelog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetChangelogHandler lists API changes, newest first. since (exclusive)
+// and until (inclusive) select a version range, so clients can diff the
+// version they were built against with the running gateway; type and route
+// (a prefix) narrow the list. The changelog only changes with the gateway
+// version, which the ETag carries for cheap polling.
+func GetChangelogHandler(c *gin.Context) {
+	filter := changelog.Filter{
+		Since: c.Query("since"),
+		Until: c.Query("until"),
+		Type:  c.Query("type"),
+		Route: c.Query("route"),
+	}
+	for _, version := range []string{filter.Since, filter.Until} {
+		if version != "" && !changelog.ValidVersion(version) {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "since and until must be versions such as 1.2.0")
+			return
+		}
+	}
+	switch filter.Type {
+	case "", changelog.Added, changelog.Changed, changelog.Deprecated, changelog.Removed:
+	default:
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "type must be added, changed, deprecated or removed")
+		return
+	}
+
+	current := changelog.Current()
+	etag := `"changelog-` + current + `"`
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	changes := changelog.Changes(filter)
+	c.JSON(http.StatusOK, gin.H{
+		"current_version": current,
+		"changes":         changes,
+		"count":           len(changes),
+	})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/reload"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetConfigHandler returns the settings in effect with secrets masked,
+// which of them can change without a restart, the latest reload and the
+// latest sync from Central Management
+func GetConfigHandler(c *gin.Context) {
+	response := gin.H{
+		"settings":    reload.Effective().Masked(),
+		"reloadable":  reload.Reloadable(),
+		"last_reload": reload.Last(),
+		"timestamp":   time.Now().Unix(),
+	}
+	if sync := config.LastRemoteSync(); !sync.At.IsZero() {
+		response["remote_sync"] = sync
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ReloadConfigHandler reloads the configuration, as a change to
+// CONFIG_FILE or SIGHUP would
+func ReloadConfigHandler(c *gin.Context) {
+	status := reload.Reload("admin")
+	recordAuditEvent(c, "config_reloaded", "config", "")
+	if !status.OK {
+		sendError(c, http.StatusUnprocessableEntity, "CONFIG_RELOAD_FAILED", status.Error)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"reload":    status,
+		"timestamp": time.Now().Unix(),
+	})
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCORSPolicyHandler returns the CORS policy in effect. With ?origin=
+// it also tells whether that origin is allowed.
+func GetCORSPolicyHandler(c *gin.Context) {
+	policy := middleware.EffectiveCORSPolicy()
+	response := gin.H{
+		"policy":    policy,
+		"timestamp": time.Now().Unix(),
+	}
+	if origin := c.Query("origin"); origin != "" {
+		response["origin"] = origin
+		response["allowed"] = middleware.MatchOrigin(policy.Origins, origin)
+	}
+	c.JSON(http.StatusOK, response)
+}
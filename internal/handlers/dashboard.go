@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/permissions"
+	"InternalAPI/internal/resilience"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dashboardResources are the API Beheerder lists counted on the dashboard
+var dashboardResources = []string{"albums", "bookings", "rooms"}
+
+// DashboardHandlers aggregates the portal's start page from several
+// upstreams
+type DashboardHandlers struct {
+	externalService *services.ExternalService
+}
+
+// dashboardSources holds the upstream responses for one dashboard, by
+// resource
+type dashboardSources struct {
+	lists      map[string]map[string]interface{}
+	listErrs   map[string]error
+	filters    map[string]permissions.ListFilter
+	filterErrs map[string]error
+}
+
+// NewDashboardHandlers creates a new dashboard handlers instance
+func NewDashboardHandlers(config *config.Config) *DashboardHandlers {
+	return &DashboardHandlers{
+		externalService: services.New(config),
+	}
+}
+
+// GetDashboard returns the portal's start page: the number of albums,
+// bookings and rooms the user sees, the user's roles and list filters, and
+// the circuit breaker state of every backend. The lists from API Beheerder
+// and the filters from Central Management are fetched in parallel. A failed
+// call marks the sections it feeds unavailable instead of failing the
+// request; counts are only given once the user's filter is applied.
+func (dh *DashboardHandlers) GetDashboard(c *gin.Context) {
+	ctx := c.Request.Context()
+	_, isService := c.Get("api_key")
+	filtered := !isService && permissions.Enabled()
+
+	sources := dh.loadSources(ctx, c.GetString("userID"), filtered)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		sendServiceError(c, "SERVICE_ERROR", ctx.Err())
+		return
+	}
+
+	var response models.DashboardResponse
+	counts := map[string]*models.DashboardCount{
+		"albums":   &response.Albums,
+		"bookings": &response.Bookings,
+		"rooms":    &response.Rooms,
+	}
+	for _, resource := range dashboardResources {
+		count := counts[resource]
+		list, err := sources.lists[resource], sources.listErrs[resource]
+		switch {
+		case err != nil:
+			count.DashboardSection = unavailableSection(err, "SERVICE_ERROR")
+			continue
+		case isService:
+		case !filtered:
+			count.DashboardSection = models.DashboardSection{Status: "unavailable", Error: "PERMISSIONS_NOT_CONFIGURED"}
+			continue
+		case sources.filterErrs[resource] != nil:
+			count.DashboardSection = unavailableSection(sources.filterErrs[resource], "PERMISSION_CHECK_FAILED")
+			continue
+		default:
+			count.FilteredOut = sources.filters[resource].Apply(list, resource)
+		}
+		count.DashboardSection = models.DashboardSection{Status: "ok"}
+		count.Count = countItems(list, resource)
+	}
+
+	response.Permissions = dashboardPermissions(c, sources, isService, filtered)
+	response.Services = models.DashboardServices{DashboardSection: models.DashboardSection{Status: "ok"}, Upstreams: []models.DashboardUpstream{}}
+	for _, dep := range dh.externalService.Dependencies() {
+		response.Services.Upstreams = append(response.Services.Upstreams, models.DashboardUpstream{
+			Name:         dep.Name,
+			BreakerState: dep.BreakerState,
+			Health:       dep.Health.Status,
+		})
+	}
+
+	sections := []struct {
+		name   string
+		status string
+	}{
+		{"albums", response.Albums.Status},
+		{"bookings", response.Bookings.Status},
+		{"rooms", response.Rooms.Status},
+		{"permissions", response.Permissions.Status},
+		{"services", response.Services.Status},
+	}
+	for _, section := range sections {
+		if section.status != "ok" {
+			response.Missing = append(response.Missing, section.name)
+		}
+	}
+	response.Partial = len(response.Missing) > 0
+	response.Timestamp = time.Now().Unix()
+	c.JSON(http.StatusOK, response)
+}
+
+// loadSources fetches the dashboard lists from API Beheerder and, when
+// filtered, the user's list filters from Central Management, all in
+// parallel. Failures are kept per resource.
+func (dh *DashboardHandlers) loadSources(ctx context.Context, userID string, filtered bool) dashboardSources {
+	sources := dashboardSources{
+		lists:      make(map[string]map[string]interface{}),
+		listErrs:   make(map[string]error),
+		filters:    make(map[string]permissions.ListFilter),
+		filterErrs: make(map[string]error),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, resource := range dashboardResources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			list, err := dh.externalService.Call(ctx, "beheerder", "GET", "/"+resource, nil)
+			mu.Lock()
+			defer mu.Unlock()
+			sources.lists[resource], sources.listErrs[resource] = list, err
+		}()
+		if !filtered {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			filter, err := permissions.Filters(ctx, userID, resource)
+			mu.Lock()
+			defer mu.Unlock()
+			sources.filters[resource], sources.filterErrs[resource] = filter, err
+		}()
+	}
+	wg.Wait()
+
+	for resource, err := range sources.listErrs {
+		if err == nil {
+			delete(sources.listErrs, resource)
+		}
+	}
+	for resource, err := range sources.filterErrs {
+		if err == nil {
+			delete(sources.filterErrs, resource)
+		}
+	}
+	return sources
+}
+
+// dashboardPermissions reports the user's roles and list filters. Service
+// API keys are governed by scopes and have no filters.
+func dashboardPermissions(c *gin.Context, sources dashboardSources, isService, filtered bool) models.DashboardPermissions {
+	section := models.DashboardPermissions{Roles: []string{}}
+	value, _ := c.Get("user")
+	if user, ok := value.(*models.UserInfo); ok && user.Roles != nil {
+		section.Roles = user.Roles
+	}
+	if isService {
+		section.Status = "ok"
+		return section
+	}
+	if !filtered {
+		section.DashboardSection = models.DashboardSection{Status: "unavailable", Error: "PERMISSIONS_NOT_CONFIGURED"}
+		return section
+	}
+
+	for _, resource := range dashboardResources {
+		if err := sources.filterErrs[resource]; err != nil {
+			section.DashboardSection = unavailableSection(err, "PERMISSION_CHECK_FAILED")
+			return section
+		}
+	}
+	section.Status = "ok"
+	section.Filters = make(map[string]models.DashboardFilter)
+	for resource, filter := range sources.filters {
+		if filter.Empty() {
+			continue
+		}
+		section.Filters[resource] = models.DashboardFilter{
+			MaxPrice: filter.MaxPrice,
+			Genres:   filter.Genres,
+			Region:   filter.Region,
+			Hidden:   filter.Hidden,
+		}
+	}
+	return section
+}
+
+// unavailableSection reports a failed call with the code sendServiceError
+// would answer it with, or code for other failures
+func unavailableSection(err error, code string) models.DashboardSection {
+	var (
+		openErr     *resilience.OpenError
+		bulkheadErr *resilience.BulkheadError
+	)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		code = "UPSTREAM_TIMEOUT"
+	case errors.As(err, &openErr):
+		code = "CIRCUIT_OPEN"
+	case errors.As(err, &bulkheadErr):
+		code = "UPSTREAM_SATURATED"
+	}
+	return models.DashboardSection{Status: "unavailable", Error: code}
+}
+
+// countItems counts the items of a list response, read from listKey or
+// "data"
+func countItems(response map[string]interface{}, listKey string) int {
+	for _, key := range []string{listKey, "data"} {
+		if items, ok := response[key].([]interface{}); ok {
+			return len(items)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/buildinfo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetBuildInfoHandler returns the version, git commit and build date of the
+// running binary
+func GetBuildInfoHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"build":          buildinfo.Get(),
+		"uptime_seconds": int64(time.Since(startedAt).Seconds()),
+		"timestamp":      time.Now().Unix(),
+	})
+}
+
+// GetGoroutineDumpHandler writes the stack of every goroutine as text, in
+// the format of an unrecovered panic
+func GetGoroutineDumpHandler(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(http.StatusOK)
+	runtimepprof.Lookup("goroutine").WriteTo(c.Writer, 2)
+}
+
+// PprofHandler serves the net/http/pprof index and profiles under
+// /debug/pprof/. CPU profiles and execution traces may run longer than the
+// server's write timeout.
+func PprofHandler(c *gin.Context) {
+	name := strings.TrimPrefix(c.Param("profile"), "/")
+	switch name {
+	case "":
+		pprof.Index(c.Writer, c.Request)
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, liftWriteTimeout(c))
+	case "trace":
+		pprof.Trace(c.Writer, liftWriteTimeout(c))
+	default:
+		if runtimepprof.Lookup(name) == nil {
+			sendError(c, http.StatusNotFound, "PROFILE_NOT_FOUND", "Unknown profile: "+name)
+			return
+		}
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// liftWriteTimeout extends the write deadline of a sampling request past
+// its seconds parameter. pprof refuses durations beyond the server's write
+// timeout, so the returned request no longer carries the server.
+func liftWriteTimeout(c *gin.Context) *http.Request {
+	seconds, err := strconv.Atoi(c.Query("seconds"))
+	if err != nil || seconds <= 0 {
+		seconds = 30
+	}
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(time.Duration(seconds)*time.Second + 30*time.Second))
+	return c.Request.WithContext(context.WithValue(c.Request.Context(), http.ServerContextKey, &http.Server{}))
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/health"
+	"InternalAPI/internal/maintenance"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// upstreamDependency adds the active maintenance window and the latest
+// background probe to an upstream description
+type upstreamDependency struct {
+	services.Dependency
+	Maintenance *maintenance.Window `json:"maintenance,omitempty"`
+	Probe       *health.Status      `json:"probe,omitempty"`
+}
+
+// GetDependencies describes every upstream the gateway depends on: its
+// sanitized URL, auth mechanism, breaker state, recent health, latest
+// probe, active maintenance window and the routes that call it
+func (ah *AdminHandlers) GetDependencies(c *gin.Context) {
+	now := time.Now()
+	deps := []upstreamDependency{}
+	for _, dep := range ah.externalService.Dependencies() {
+		dependency := upstreamDependency{
+			Dependency:  dep,
+			Maintenance: maintenance.Active(dep.Name, now),
+		}
+		if probe, ok := health.Get(dep.Name); ok {
+			dependency.Probe = &probe
+		}
+		deps = append(deps, dependency)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dependencies": deps,
+		"count":        len(deps),
+		"timestamp":    now.Unix(),
+	})
+}
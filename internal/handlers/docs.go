@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// openAPIDocument is the generated document, set once all routes are registered
+var openAPIDocument []byte
+
+// SetOpenAPIDocument sets the document served at /openapi.json
+func SetOpenAPIDocument(doc []byte) {
+	openAPIDocument = doc
+}
+
+// OpenAPIHandler serves the OpenAPI document generated from the route
+// registrations and models
+func OpenAPIHandler(c *gin.Context) {
+	if openAPIDocument == nil {
+		sendError(c, http.StatusServiceUnavailable, "DOCS_UNAVAILABLE", "The API document has not been generated")
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openAPIDocument)
+}
+
+// Swagger UI 5.32.8 is embedded so the admin-only page runs no script the
+// gateway does not ship itself; see swaggerui/NOTICE
+var (
+	//go:embed swaggerui/swagger-ui-bundle.js
+	swaggerUIBundle []byte
+	//go:embed swaggerui/swagger-ui.css
+	swaggerUICSS []byte
+)
+
+// swaggerUIPage loads the embedded Swagger UI; the initializer is served
+// separately so the page needs no inline script
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Hotel Internal API</title>
+  <link rel="stylesheet" href="/docs/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="/docs/swagger-ui-bundle.js"></script>
+  <script src="/docs/swagger-init.js"></script>
+</body>
+</html>
+`
+
+// swaggerUIPolicy allows only the gateway's own scripts and styles
+const swaggerUIPolicy = "default-src 'self'; script-src 'self'; style-src 'self'; img-src 'self' data:; object-src 'none';"
+
+// swaggerInitScript points Swagger UI at the generated document and sends
+// the auth cookies along with "Try it out" requests
+const swaggerInitScript = `window.ui = SwaggerUIBundle({
+  url: "/openapi.json",
+  dom_id: "#swagger-ui",
+  withCredentials: true,
+  persistAuthorization: true
+});
+`
+
+// SwaggerUIHandler serves a Swagger UI page for the generated document
+func SwaggerUIHandler(c *gin.Context) {
+	c.Header("Content-Security-Policy", swaggerUIPolicy)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+// SwaggerInitHandler serves the Swagger UI initializer script
+func SwaggerInitHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(swaggerInitScript))
+}
+
+// SwaggerBundleHandler serves the embedded Swagger UI script
+func SwaggerBundleHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/javascript; charset=utf-8", swaggerUIBundle)
+}
+
+// SwaggerCSSHandler serves the embedded Swagger UI stylesheet
+func SwaggerCSSHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/css; charset=utf-8", swaggerUICSS)
+}
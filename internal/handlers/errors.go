@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"InternalAPI/internal/resilience"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorCatalogEntry documents an error code returned by the API
+type ErrorCatalogEntry struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+	Retryable   bool   `json:"retryable"`
+	Headers     string `json:"headers,omitempty"`
+}
+
+// errorCatalog lists the error codes clients can expect from the API
+var errorCatalog = []ErrorCatalogEntry{
+	{Code: "INVALID_REQUEST", Status: http.StatusBadRequest, Description: "The request body or parameters failed validation"},
+	{Code: "SCHEMA_VALIDATION_FAILED", Status: http.StatusBadRequest, Description: "The request body does not match the OpenAPI schema; violations gives a JSON pointer, rule and message for each problem"},
+	{Code: "INVALID_CURSOR", Status: http.StatusBadRequest, Description: "The pagination cursor is malformed, was tampered with or belongs to a different list or filter set"},
+	{Code: "INVALID_WEBHOOK_PAYLOAD", Status: http.StatusBadRequest, Description: "The upstream callback body is not a JSON event with id and type"},
+	{Code: "INVALID_CAPTURE_POLICY", Status: http.StatusBadRequest, Description: "The audit capture policy must be none, metadata, redacted or full"},
+	{Code: "INVALID_PROXY_PATH", Status: http.StatusBadRequest, Description: "The proxied path contains empty, . or .. segments"},
+	{Code: "INVALID_DATE_RANGE", Status: http.StatusBadRequest, Description: "check_out must be between 1 and 30 nights after check_in, and a new booking cannot start in the past"},
+	{Code: "WEBSOCKET_UPGRADE_REQUIRED", Status: http.StatusBadRequest, Description: "The request is not a valid WebSocket (version 13) upgrade"},
+	{Code: "MISSING_AUTH", Status: http.StatusUnauthorized, Description: "The Authorization header is missing"},
+	{Code: "INVALID_AUTH_FORMAT", Status: http.StatusUnauthorized, Description: "The Authorization header is not in 'Bearer <token>' format"},
+	{Code: "INVALID_TOKEN", Status: http.StatusUnauthorized, Description: "The access token is invalid, expired or revoked"},
+	{Code: "MISSING_TOKEN", Status: http.StatusUnauthorized, Description: "No token was found for the current request"},
+	{Code: "MISSING_USER", Status: http.StatusUnauthorized, Description: "No authenticated user was found for the current request"},
+	{Code: "MISSING_API_KEY", Status: http.StatusUnauthorized, Description: "The X-Internal-API-Key header is missing"},
+	{Code: "INVALID_API_KEY", Status: http.StatusUnauthorized, Description: "The service API key is not recognized"},
+	{Code: "INVALID_REFRESH_TOKEN", Status: http.StatusUnauthorized, Description: "The refresh token is unknown, revoked or expired"},
+	{Code: "REFRESH_TOKEN_REUSED", Status: http.StatusUnauthorized, Description: "A rotated refresh token was presented again; every session in its family has been revoked"},
+	{Code: "OIDC_LOGIN_FAILED", Status: http.StatusUnauthorized, Description: "Single sign-on failed at the identity provider or the returned identity could not be verified"},
+	{Code: "INVALID_METRICS_CREDENTIALS", Status: http.StatusUnauthorized, Description: "/metrics requires the configured basic auth credentials or metrics API key", Headers: "WWW-Authenticate"},
+	{Code: "INVALID_SIGNATURE", Status: http.StatusUnauthorized, Description: "The upstream callback signature is missing, stale or does not match the body"},
+	{Code: "CHALLENGE_REQUIRED", Status: http.StatusUnauthorized, Description: "A CAPTCHA or step-up challenge must accompany the login request", Headers: "X-Challenge-Required"},
+	{Code: "CSRF_TOKEN_INVALID", Status: http.StatusForbidden, Description: "A cookie-authenticated write did not echo the csrf_token cookie in the X-CSRF-Token header"},
+	{Code: "INSUFFICIENT_PERMISSIONS", Status: http.StatusForbidden, Description: "The user lacks the role required for this endpoint"},
+	{Code: "TOKEN_EXTENSION_NOT_ALLOWED", Status: http.StatusForbidden, Description: "The user's roles may not extend their session; use the refresh token instead"},
+	{Code: "TOKEN_EXTENSION_LIMIT", Status: http.StatusForbidden, Description: "The session was already extended the maximum number of times"},
+	{Code: "API_KEY_NOT_ALLOWED", Status: http.StatusForbidden, Description: "The endpoint does not accept service API keys"},
+	{Code: "INSUFFICIENT_SCOPE", Status: http.StatusForbidden, Description: "The service API key does not grant the scope required for this endpoint"},
+	{Code: "PERMISSION_DENIED", Status: http.StatusForbidden, Description: "Central Management denied the action for this user"},
+	{Code: "AUTOMATED_TRAFFIC", Status: http.StatusForbidden, Description: "An anonymous request was rejected by bot mitigation; X-Challenge-Required is set when a challenge token would be accepted", Headers: "X-Challenge-Required"},
+	{Code: "LOGIN_BLOCKED", Status: http.StatusForbidden, Description: "Logins from this network or device are temporarily blocked"},
+	{Code: "FIELD_NOT_WRITABLE", Status: http.StatusForbidden, Description: "The patch changes a field hidden from the caller"},
+	{Code: "ORIGIN_NOT_ALLOWED", Status: http.StatusForbidden, Description: "The WebSocket handshake came from a page whose origin is not in CORS_ORIGINS"},
+	{Code: "ACCOUNT_NOT_LOCKED", Status: http.StatusNotFound, Description: "The account is not currently locked"},
+	{Code: "SERVICE_NOT_FOUND", Status: http.StatusNotFound, Description: "The requested backend service is unknown"},
+	{Code: "MAINTENANCE_WINDOW_NOT_FOUND", Status: http.StatusNotFound, Description: "The maintenance window does not exist"},
+	{Code: "PROXY_ROUTE_NOT_ALLOWED", Status: http.StatusNotFound, Description: "The method and path are not on the BEHEERDER_PROXY_RULES allow-list"},
+	{Code: "TASK_NOT_FOUND", Status: http.StatusNotFound, Description: "The housekeeping task does not exist or has been purged by retention"},
+	{Code: "NO_PENDING_TASKS", Status: http.StatusNotFound, Description: "No housekeeping task is waiting to be claimed"},
+	{Code: "MESSAGE_NOT_FOUND", Status: http.StatusNotFound, Description: "The guest message does not exist or has been purged by retention"},
+	{Code: "ROOM_UNAVAILABLE", Status: http.StatusConflict, Description: "The room type is sold out, closed or too small for the requested stay"},
+	{Code: "INVALID_STATUS_TRANSITION", Status: http.StatusConflict, Description: "The room cannot move from its current status to the requested one; the message lists the allowed statuses"},
+	{Code: "TASK_NOT_CLAIMABLE", Status: http.StatusConflict, Description: "The housekeeping task was already claimed or finished"},
+	{Code: "TASK_NOT_ASSIGNED", Status: http.StatusConflict, Description: "Only the worker who claimed a housekeeping task can complete it or report an issue"},
+	{Code: "RESOURCE_CONFLICT", Status: http.StatusConflict, Description: "The backend service rejected the write because it conflicts with the resource's current state, such as a duplicate or a concurrent change"},
+	{Code: "PATCH_TEST_FAILED", Status: http.StatusConflict, Description: "A JSON Patch test operation did not match the current resource"},
+	{Code: "PRECONDITION_FAILED", Status: http.StatusPreconditionFailed, Description: "If-Match does not match the resource's current ETag; the response carries the current ETag", Headers: "ETag"},
+	{Code: "UNSUPPORTED_PATCH_FORMAT", Status: http.StatusUnsupportedMediaType, Description: "PATCH bodies must be application/merge-patch+json or application/json-patch+json"},
+	{Code: "INVALID_PATCH", Status: http.StatusUnprocessableEntity, Description: "The patch could not be applied or produced an invalid resource"},
+	{Code: "UPSTREAM_VALIDATION_FAILED", Status: http.StatusUnprocessableEntity, Description: "The backend service rejected the payload; the message is the backend's explanation"},
+	{Code: "MESSAGE_REJECTED", Status: http.StatusUnprocessableEntity, Description: "The message was rejected by the content filter"},
+	{Code: "CONFIG_RELOAD_FAILED", Status: http.StatusUnprocessableEntity, Description: "The configuration could not be reloaded; settings the message does not name may have been applied, see GET /admin/config"},
+	{Code: "BUSINESS_RULE_VIOLATION", Status: http.StatusUnprocessableEntity, Description: "The payload breaks Central Management business rules; violations lists each field, rule and message"},
+	{Code: "PROFILE_NOT_FOUND", Status: http.StatusNotFound, Description: "No runtime profile with this name exists; /debug/pprof/ lists them"},
+	{Code: "OIDC_DISABLED", Status: http.StatusNotFound, Description: "Single sign-on is not enabled in this deployment"},
+	{Code: "ACTION_NOT_FOUND", Status: http.StatusNotFound, Description: "No runbook action with this name is registered"},
+	{Code: "CAPTURE_RULE_NOT_FOUND", Status: http.StatusNotFound, Description: "No audit capture override exists for the route"},
+	{Code: "CONNECTION_NOT_FOUND", Status: http.StatusNotFound, Description: "No live event stream connection exists with the given ID"},
+	{Code: "HOTEL_NOT_PUBLIC", Status: http.StatusNotFound, Description: "The hotel is not on PUBLIC_AVAILABILITY_HOTELS"},
+	{Code: "REPUTATION_NOT_FOUND", Status: http.StatusNotFound, Description: "No reputation entry exists for the given key"},
+	{Code: "WEBHOOK_NOT_FOUND", Status: http.StatusNotFound, Description: "No outbound webhook subscription exists with the given ID"},
+	{Code: "DEAD_LETTER_NOT_FOUND", Status: http.StatusNotFound, Description: "No dead-lettered webhook delivery exists with the given ID"},
+	{Code: "RESOURCE_NOT_FOUND", Status: http.StatusNotFound, Description: "The backend service has no album, booking, room, guest or user with the given ID"},
+	{Code: "JOB_NOT_FOUND", Status: http.StatusNotFound, Description: "No background job with the given ID exists for this user, or it has expired"},
+	{Code: "ACCOUNT_LOCKED", Status: http.StatusLocked, Description: "The account is locked after too many failed logins; wait for Retry-After or ask an admin to unlock it", Retryable: true, Headers: "Retry-After"},
+	{Code: "RATE_LIMIT_EXCEEDED", Status: http.StatusTooManyRequests, Description: "Too many requests were made in the current window", Retryable: true},
+	{Code: "SERVICE_ERROR", Status: http.StatusInternalServerError, Description: "A backend service call failed"},
+	{Code: "WEBHOOK_BUFFER_FAILED", Status: http.StatusInternalServerError, Description: "The upstream callback could not be stored durably and was not accepted", Retryable: true},
+	{Code: "WEBHOOK_STORE_FAILED", Status: http.StatusInternalServerError, Description: "An outbound webhook or dead letter could not be written to disk", Retryable: true},
+	{Code: "REPUTATION_STORE_FAILED", Status: http.StatusInternalServerError, Description: "Login reputation entries could not be read or removed", Retryable: true},
+	{Code: "JOB_STORE_FAILED", Status: http.StatusInternalServerError, Description: "A background job could not be stored and was not queued", Retryable: true},
+	{Code: "ACTION_FAILED", Status: http.StatusInternalServerError, Description: "The runbook action was started but did not complete"},
+	{Code: "STREAM_NOT_SUPPORTED", Status: http.StatusInternalServerError, Description: "The connection does not support streaming request and response bodies"},
+	{Code: "PERMISSIONS_NOT_CONFIGURED", Status: http.StatusInternalServerError, Description: "Permission checks have not been initialized"},
+	{Code: "TOKEN_ISSUE_FAILED", Status: http.StatusInternalServerError, Description: "Access or refresh tokens could not be issued"},
+	{Code: "JWT_KEY_GENERATION_FAILED", Status: http.StatusInternalServerError, Description: "No random JWT key could be generated; the signing key was not changed"},
+	{Code: "REVOCATION_FAILED", Status: http.StatusInternalServerError, Description: "The token could not be written to the revocation store"},
+	{Code: "AUTH_SERVICE_ERROR", Status: http.StatusInternalServerError, Description: "The authentication service call failed"},
+	{Code: "OIDC_PROVIDER_ERROR", Status: http.StatusBadGateway, Description: "The identity provider could not be reached"},
+	{Code: "WEBHOOK_NOT_CONFIGURED", Status: http.StatusServiceUnavailable, Description: "Upstream callbacks are not enabled in this deployment"},
+	{Code: "READ_ONLY_MODE", Status: http.StatusServiceUnavailable, Description: "Writes are rejected while an operator has switched the API to read-only mode", Retryable: true},
+	{Code: "BUSINESS_RULES_UNAVAILABLE", Status: http.StatusServiceUnavailable, Description: "Business rules could not be loaded from Central Management", Retryable: true},
+	{Code: "PERMISSION_CHECK_FAILED", Status: http.StatusServiceUnavailable, Description: "Permissions could not be verified with Central Management", Retryable: true},
+	{Code: "BLACKLIST_UNAVAILABLE", Status: http.StatusServiceUnavailable, Description: "The token blacklist store could not be reached", Retryable: true},
+	{Code: "AUDIT_STORE_UNAVAILABLE", Status: http.StatusServiceUnavailable, Description: "The audit store could not be reached", Retryable: true},
+	{Code: "DOCS_UNAVAILABLE", Status: http.StatusServiceUnavailable, Description: "The API document has not been generated", Retryable: true},
+	{Code: "MAINTENANCE_DEGRADED", Status: http.StatusServiceUnavailable, Description: "The backend service is in a degraded maintenance window", Retryable: true, Headers: "Retry-After, X-Maintenance-Mode, X-Maintenance-Until"},
+	{Code: "MAINTENANCE_READ_ONLY", Status: http.StatusServiceUnavailable, Description: "Writes are suspended while the backend service is in a maintenance window", Retryable: true, Headers: "Retry-After, X-Maintenance-Mode, X-Maintenance-Until"},
+	{Code: "MAINTENANCE_NO_CACHE", Status: http.StatusServiceUnavailable, Description: "No cached copy is available while the backend service is in a cached-mode maintenance window", Retryable: true, Headers: "Retry-After, X-Maintenance-Mode, X-Maintenance-Until"},
+	{Code: "CIRCUIT_OPEN", Status: http.StatusServiceUnavailable, Description: "The backend service is temporarily unavailable because its circuit breaker is open. Wait for the Retry-After period before retrying", Retryable: true, Headers: "Retry-After"},
+	{Code: "UPSTREAM_SATURATED", Status: http.StatusServiceUnavailable, Description: "The backend service already has as many calls in progress as the gateway allows; retry after Retry-After", Retryable: true, Headers: "Retry-After"},
+	{Code: "JOB_QUEUE_FULL", Status: http.StatusServiceUnavailable, Description: "Too many background jobs are waiting for a worker; retry after Retry-After", Retryable: true, Headers: "Retry-After"},
+	{Code: "JOB_STORE_UNAVAILABLE", Status: http.StatusServiceUnavailable, Description: "The background job store could not be reached", Retryable: true},
+	{Code: "UPSTREAM_TIMEOUT", Status: http.StatusGatewayTimeout, Description: "The backend service did not answer within its configured timeout", Retryable: true},
+}
+
+// GetErrorCatalogHandler returns the catalog of API error codes
+func GetErrorCatalogHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"errors":    errorCatalog,
+		"count":     len(errorCatalog),
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// backendStatusCodes are the backend error statuses passed on to clients,
+// with the code they are reported under. They describe the request, not
+// an outage, so the client can act on them.
+var backendStatusCodes = map[int]string{
+	http.StatusBadRequest:          "INVALID_REQUEST",
+	http.StatusNotFound:            "RESOURCE_NOT_FOUND",
+	http.StatusConflict:            "RESOURCE_CONFLICT",
+	http.StatusUnprocessableEntity: "UPSTREAM_VALIDATION_FAILED",
+}
+
+// sendServiceError maps a backend call error to a client response. Open
+// circuit breakers produce a 503 with Retry-After so clients back off
+// instead of retrying immediately, and backend timeouts a 504. Backend
+// answers listed in backendStatusCodes keep their status. When the client
+// disconnected no body is written; other failures use the given code.
+func sendServiceError(c *gin.Context, code string, err error) {
+	if errors.Is(err, context.Canceled) {
+		// 499 is nginx's "client closed request"; it only reaches the logs
+		c.AbortWithStatus(499)
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		sendError(c, http.StatusGatewayTimeout, "UPSTREAM_TIMEOUT", err.Error())
+		return
+	}
+
+	var openErr *resilience.OpenError
+	if errors.As(err, &openErr) {
+		retryAfter := int(math.Ceil(openErr.RetryAfter.Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		sendError(c, http.StatusServiceUnavailable, "CIRCUIT_OPEN", err.Error())
+		return
+	}
+
+	var bulkheadErr *resilience.BulkheadError
+	if errors.As(err, &bulkheadErr) {
+		c.Header("Retry-After", "1")
+		sendError(c, http.StatusServiceUnavailable, "UPSTREAM_SATURATED", err.Error())
+		return
+	}
+
+	var serviceErr *services.ServiceError
+	if errors.As(err, &serviceErr) {
+		if backendCode, ok := backendStatusCodes[serviceErr.StatusCode]; ok {
+			message := serviceErr.Message
+			if message == "" {
+				message = http.StatusText(serviceErr.StatusCode)
+			}
+			sendError(c, serviceErr.StatusCode, backendCode, message)
+			return
+		}
+	}
+
+	sendError(c, http.StatusInternalServerError, code, err.Error())
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"InternalAPI/internal/permissions"
+	"InternalAPI/internal/resilience"
+
+	"github.com/gin-gonic/gin"
+)
+
+// applyListFilter removes the items Central Management hides from the
+// current user and redacts hidden fields from the rest. The number of items
+// removed is reported as filtered_out. Service API keys are governed by
+// scopes and see every item. It writes the error response and returns false
+// on failure.
+func applyListFilter(c *gin.Context, response map[string]interface{}, resource string) bool {
+	if _, isService := c.Get("api_key"); isService {
+		return true
+	}
+	if !permissions.Enabled() {
+		sendError(c, http.StatusInternalServerError, "PERMISSIONS_NOT_CONFIGURED", "Permission checks are not configured")
+		return false
+	}
+
+	filter, err := permissions.Filters(c.Request.Context(), c.GetString("userID"), resource)
+	if err != nil {
+		sendFilterLookupError(c, err)
+		return false
+	}
+
+	if removed := filter.Apply(response, resource); removed > 0 {
+		response["filtered_out"] = removed
+	}
+	return true
+}
+
+// sendFilterLookupError reports a failed field or list filter lookup with
+// Central Management
+func sendFilterLookupError(c *gin.Context, err error) {
+	var openErr *resilience.OpenError
+	if errors.As(err, &openErr) {
+		sendServiceError(c, "PERMISSION_CHECK_FAILED", err)
+		return
+	}
+	sendError(c, http.StatusServiceUnavailable, "PERMISSION_CHECK_FAILED", "Unable to load the user's data filters")
+}
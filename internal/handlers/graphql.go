@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/graphql"
+	"InternalAPI/internal/labels"
+	"InternalAPI/internal/middleware"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/permissions"
+	"InternalAPI/internal/resilience"
+	"InternalAPI/internal/roles"
+	"InternalAPI/internal/services"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/executor"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// userBatchWait is how long the users loader collects IDs before it
+	// calls Central Management
+	userBatchWait = 2 * time.Millisecond
+	// userBatchSize caps the IDs sent in one /admin/users?ids= call
+	userBatchSize = 100
+)
+
+// GraphQLHandlers serves /graphql, composing albums and bookings from API
+// Beheerder with users from Central Management in one query
+type GraphQLHandlers struct {
+	externalService *services.ExternalService
+	executor        *executor.Executor
+}
+
+// graphQLRequest is the per-request state resolvers read from the context
+type graphQLRequest struct {
+	c     *gin.Context
+	users *graphql.Loader
+}
+
+// graphQLRequestKey stores the graphQLRequest in the resolver context
+type graphQLRequestKey struct{}
+
+// NewGraphQLHandlers creates a new GraphQL handlers instance
+func NewGraphQLHandlers(config *config.Config) *GraphQLHandlers {
+	gh := &GraphQLHandlers{
+		externalService: services.New(config),
+	}
+	schema := graphql.NewExecutableSchema(graphql.Config{
+		Resolvers:  &graphQLResolver{gh},
+		Directives: graphql.DirectiveRoot{HasAccess: hasAccess},
+	})
+	// Introspection stays off: the executor only allows it with the
+	// Introspection extension
+	gh.executor = executor.New(schema)
+	gh.executor.Use(graphql.DepthLimit{MaxDepth: config.GraphQLMaxDepth})
+	return gh
+}
+
+// Query executes a GraphQL query. Requests that fail to parse or validate,
+// including mutations, which the schema does not declare, get 400; errors while resolving fields are reported next to the partial
+// data with 200, as GraphQL clients expect.
+func (gh *GraphQLHandlers) Query(c *gin.Context) {
+	start := gqlgen.Now()
+	var params gqlgen.RawParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if strings.TrimSpace(params.Query) == "" {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "query is required")
+		return
+	}
+	params.Headers = c.Request.Header
+	params.ReadTime = gqlgen.TraceTiming{Start: start, End: gqlgen.Now()}
+
+	state := &graphQLRequest{c: c}
+	state.users = graphql.NewLoader("users", gh.fetchUsers, userBatchWait, userBatchSize)
+	ctx := context.WithValue(gqlgen.StartOperationTrace(c.Request.Context()), graphQLRequestKey{}, state)
+
+	rc, errs := gh.executor.CreateOperationContext(ctx, &params)
+	if errs != nil {
+		c.JSON(http.StatusBadRequest, gh.executor.DispatchError(gqlgen.WithOperationContext(ctx, rc), errs))
+		return
+	}
+
+	responses, ctx := gh.executor.DispatchOperation(ctx, rc)
+	c.JSON(http.StatusOK, responses(ctx))
+}
+
+// requestFrom returns the request state of a resolver context
+func requestFrom(ctx context.Context) *graphQLRequest {
+	return ctx.Value(graphQLRequestKey{}).(*graphQLRequest)
+}
+
+// graphQLResolver implements the resolvers gqlgen generated interfaces for
+// from internal/graphql/schema.graphqls
+type graphQLResolver struct {
+	gh *GraphQLHandlers
+}
+
+// Query returns the resolvers of the query root
+func (r *graphQLResolver) Query() graphql.QueryResolver {
+	return &graphQLQueryResolver{r.gh}
+}
+
+// Album returns the resolvers of album owners
+func (r *graphQLResolver) Album() graphql.AlbumResolver {
+	return &graphQLAlbumResolver{}
+}
+
+// graphQLQueryResolver resolves the root fields
+type graphQLQueryResolver struct {
+	gh *GraphQLHandlers
+}
+
+// Albums lists the albums the user's Central Management filters allow
+func (r *graphQLQueryResolver) Albums(ctx context.Context) ([]*graphql.Album, error) {
+	c := requestFrom(ctx).c
+	response, err := r.gh.externalService.Call(ctx, "beheerder", "GET", "/albums", nil)
+	if err != nil {
+		return nil, graphQLServiceError(err)
+	}
+	if err := filterList(c, response, "albums"); err != nil {
+		return nil, err
+	}
+	labels.Transform(response, albumLabelFields, labelContext(c))
+	return graphql.AlbumsFrom(response["albums"]), nil
+}
+
+// Album retrieves one album
+func (r *graphQLQueryResolver) Album(ctx context.Context, id string) (*graphql.Album, error) {
+	c := requestFrom(ctx).c
+	response, err := r.gh.externalService.Call(ctx, "beheerder", "GET", "/albums/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, graphQLServiceError(err)
+	}
+	labels.Transform(response, albumLabelFields, labelContext(c))
+	return graphql.AlbumFrom(response["album"]), nil
+}
+
+// Bookings lists bookings through the hotel's PMS adapter, with the same
+// filters as GET /api/v1/bookings
+func (r *graphQLQueryResolver) Bookings(ctx context.Context, page *int, pageSize *int, hotelID *string, status *string, guestEmail *string, checkInFrom *string, checkInTo *string) ([]*graphql.Booking, error) {
+	c := requestFrom(ctx).c
+	params := url.Values{}
+	filters := map[string]*string{
+		"hotel_id":      hotelID,
+		"status":        status,
+		"guest_email":   guestEmail,
+		"check_in_from": checkInFrom,
+		"check_in_to":   checkInTo,
+	}
+	for _, key := range bookingListFilters {
+		if value := filters[key]; value != nil && *value != "" {
+			params.Set(key, *value)
+		}
+	}
+	if page != nil {
+		params.Set("page", strconv.Itoa(*page))
+	}
+	if pageSize != nil {
+		params.Set("page_size", strconv.Itoa(*pageSize))
+	}
+
+	response, err := pmsBackend(c, r.gh.externalService, params.Get("hotel_id")).Call(ctx, "GET", "/bookings"+encodeQuery(params), nil)
+	if err != nil {
+		return nil, graphQLServiceError(err)
+	}
+	if err := filterList(c, response, "bookings"); err != nil {
+		return nil, err
+	}
+	return graphql.BookingsFrom(response["bookings"]), nil
+}
+
+// Booking retrieves one booking
+func (r *graphQLQueryResolver) Booking(ctx context.Context, id string) (*graphql.Booking, error) {
+	c := requestFrom(ctx).c
+	response, err := pmsBackend(c, r.gh.externalService, "").Call(ctx, "GET", "/bookings/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, graphQLServiceError(err)
+	}
+	return graphql.BookingFrom(response["booking"]), nil
+}
+
+// Users lists the users known to Central Management
+func (r *graphQLQueryResolver) Users(ctx context.Context) ([]*graphql.User, error) {
+	response, err := r.gh.externalService.Call(ctx, "central", "GET", "/admin/users", nil)
+	if err != nil {
+		return nil, graphQLServiceError(err)
+	}
+	return graphql.UsersFrom(response["users"]), nil
+}
+
+// User retrieves one user through the users loader
+func (r *graphQLQueryResolver) User(ctx context.Context, id string) (*graphql.User, error) {
+	return loadUser(ctx, id)
+}
+
+// Me returns the caller from the validated token, without a backend call;
+// service API keys have no user and get null
+func (r *graphQLQueryResolver) Me(ctx context.Context) (*graphql.User, error) {
+	userInterface, exists := requestFrom(ctx).c.Get("user")
+	if !exists {
+		return nil, nil
+	}
+	user, ok := userInterface.(*models.UserInfo)
+	if !ok {
+		return nil, nil
+	}
+	return graphql.UserFrom(map[string]interface{}{
+		"id":       user.UserID,
+		"username": user.Username,
+		"email":    user.Email,
+		"roles":    user.Roles,
+	}), nil
+}
+
+// graphQLAlbumResolver resolves album owners through the users loader, so
+// a list of albums costs one Central Management call however many owners
+// it has
+type graphQLAlbumResolver struct{}
+
+// CreatedBy resolves the user who created an album
+func (r *graphQLAlbumResolver) CreatedBy(ctx context.Context, album *graphql.Album) (*graphql.User, error) {
+	return loadUser(ctx, album.CreatedByID)
+}
+
+// UpdatedBy resolves the user who last updated an album
+func (r *graphQLAlbumResolver) UpdatedBy(ctx context.Context, album *graphql.Album) (*graphql.User, error) {
+	return loadUser(ctx, album.UpdatedByID)
+}
+
+// loadUser looks a user up through the request's users loader
+func loadUser(ctx context.Context, id string) (*graphql.User, error) {
+	if id == "" {
+		return nil, nil
+	}
+	user, err := requestFrom(ctx).users.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return graphql.UserFrom(user), nil
+}
+
+// fetchUsers is the users loader's batch call: one request to Central
+// Management for every user ID referenced while resolving a query
+func (gh *GraphQLHandlers) fetchUsers(ctx context.Context, ids []string) (map[string]interface{}, error) {
+	response, err := gh.externalService.Call(ctx, "central", "GET", "/admin/users"+encodeQuery(url.Values{"ids": {strings.Join(ids, ",")}}), nil)
+	if err != nil {
+		return nil, graphQLServiceError(err)
+	}
+
+	users := map[string]interface{}{}
+	list, _ := response["users"].([]interface{})
+	for _, item := range list {
+		if u, ok := item.(map[string]interface{}); ok {
+			users[fmt.Sprint(u["id"])] = u
+		}
+	}
+	return users, nil
+}
+
+// hasAccess implements the @hasAccess directive. A denied field resolves
+// to null with an error carrying the REST error code.
+func hasAccess(ctx context.Context, obj interface{}, next gqlgen.Resolver, scope string, action *string, resource *string) (interface{}, error) {
+	var actionName, resourceName string
+	if action != nil {
+		actionName = *action
+	}
+	if resource != nil {
+		resourceName = *resource
+	}
+	if err := authorize(requestFrom(ctx).c, scope, actionName, resourceName); err != nil {
+		return nil, err
+	}
+	return next(ctx)
+}
+
+// authorize checks a field the way the matching REST route is guarded: API
+// keys need scope, users need Central Management to allow action on
+// resource. Without an action the field is limited to admins, as the
+// /admin routes are.
+func authorize(c *gin.Context, scope, action, resource string) error {
+	if key, isService := c.Get("api_key"); isService {
+		if !key.(*middleware.APIKey).HasScope(scope) {
+			return graphql.NewError("INSUFFICIENT_SCOPE", "API key does not grant scope "+scope)
+		}
+		return nil
+	}
+
+	if action == "" {
+		userInterface, _ := c.Get("user")
+		user, ok := userInterface.(*models.UserInfo)
+		if !ok || !roles.Satisfies(user.Roles, "admin", "super_admin") {
+			return graphql.NewError("INSUFFICIENT_PERMISSIONS", "User does not have required permissions")
+		}
+		return nil
+	}
+
+	if !permissions.Enabled() {
+		return graphql.NewError("PERMISSIONS_NOT_CONFIGURED", "Permission checks are not configured")
+	}
+	decision, err := permissions.Check(c.Request.Context(), c.GetString("userID"), action, resource, nil)
+	if err != nil {
+		return graphQLPermissionError(err)
+	}
+	if !decision.Allowed {
+		message := "User is not permitted to " + action + " on " + resource
+		if decision.Reason != "" {
+			message = decision.Reason
+		}
+		return graphql.NewError("PERMISSION_DENIED", message)
+	}
+	return nil
+}
+
+// filterList applies the user's Central Management list filters, as
+// applyListFilter does for REST responses
+func filterList(c *gin.Context, response map[string]interface{}, resource string) error {
+	if _, isService := c.Get("api_key"); isService {
+		return nil
+	}
+	if !permissions.Enabled() {
+		return graphql.NewError("PERMISSIONS_NOT_CONFIGURED", "Permission checks are not configured")
+	}
+	filter, err := permissions.Filters(c.Request.Context(), c.GetString("userID"), resource)
+	if err != nil {
+		return graphQLPermissionError(err)
+	}
+	filter.Apply(response, resource)
+	return nil
+}
+
+// graphQLServiceError converts a backend failure to a field error with the
+// code sendServiceError would use
+func graphQLServiceError(err error) error {
+	var openErr *resilience.OpenError
+	if errors.As(err, &openErr) {
+		return graphql.NewError("CIRCUIT_OPEN", err.Error())
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return graphql.NewError("UPSTREAM_TIMEOUT", err.Error())
+	}
+	var bulkheadErr *resilience.BulkheadError
+	if errors.As(err, &bulkheadErr) {
+		return graphql.NewError("UPSTREAM_SATURATED", err.Error())
+	}
+	var serviceErr *services.ServiceError
+	if errors.As(err, &serviceErr) {
+		if code, ok := backendStatusCodes[serviceErr.StatusCode]; ok {
+			return graphql.NewError(code, err.Error())
+		}
+	}
+	return graphql.NewError("SERVICE_ERROR", err.Error())
+}
+
+// graphQLPermissionError reports a failed permission or filter lookup
+func graphQLPermissionError(err error) error {
+	var openErr *resilience.OpenError
+	if errors.As(err, &openErr) {
+		return graphql.NewError("CIRCUIT_OPEN", err.Error())
+	}
+	return graphql.NewError("PERMISSION_CHECK_FAILED", "Unable to check permissions with Central Management")
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/audit"
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/permissions"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// guestListFilters are the query parameters passed through to API Beheerder.
+// Guests are searched by free text rather than by e-mail so personal data
+// stays out of access logs.
+var guestListFilters = []string{"hotel_id", "search", "nationality"}
+
+// GuestHandlers contains all guest-related handlers
+type GuestHandlers struct {
+	externalService *services.ExternalService
+}
+
+// NewGuestHandlers creates a new guest handlers instance
+func NewGuestHandlers(config *config.Config) *GuestHandlers {
+	return &GuestHandlers{
+		externalService: services.New(config),
+	}
+}
+
+// GetGuests lists guests with the fields the caller may see. Results are
+// paginated by page/page_size or cursor.
+func (gh *GuestHandlers) GetGuests(c *gin.Context) {
+	filters := url.Values{}
+	for _, key := range guestListFilters {
+		if value := c.Query(key); value != "" {
+			filters.Set(key, value)
+		}
+	}
+
+	query, cursor, ok := listQuery(c, "guests", filters)
+	if !ok {
+		return
+	}
+
+	response, err := gh.externalService.Call(c.Request.Context(), "beheerder", "GET", "/guests"+query, nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	setNextCursor(response, cursor, "guests")
+	if !filterGuestFields(c, response) {
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetGuestByID retrieves a guest with the fields the caller may see
+func (gh *GuestHandlers) GetGuestByID(c *gin.Context) {
+	response, err := gh.externalService.Call(c.Request.Context(), "beheerder", "GET", "/guests/"+url.PathEscape(c.Param("id")), nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	setETag(c, response, "guest")
+	if !filterGuestFields(c, response) {
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateGuest creates a guest profile
+func (gh *GuestHandlers) CreateGuest(c *gin.Context) {
+	var req models.GuestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := gh.externalService.Call(c.Request.Context(), "beheerder", "POST", "/guests", req)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	if !filterGuestFields(c, response) {
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateGuest replaces a guest profile
+func (gh *GuestHandlers) UpdateGuest(c *gin.Context) {
+	var req models.GuestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := gh.externalService.Call(c.Request.Context(), "beheerder", "PUT", "/guests/"+url.PathEscape(c.Param("id")), req)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	if !filterGuestFields(c, response) {
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// PatchGuest applies a JSON Merge Patch or JSON Patch to a guest profile and
+// sends the result upstream as a full update. Fields hidden from the caller
+// cannot be changed.
+func (gh *GuestHandlers) PatchGuest(c *gin.Context) {
+	endpoint := "/guests/" + url.PathEscape(c.Param("id"))
+	current, patched, ok := loadPatched(c, beheerderBackend(gh.externalService), endpoint, "guest")
+	if !ok {
+		return
+	}
+
+	filter, ok := guestFieldFilter(c)
+	if !ok {
+		return
+	}
+	if changed := filter.Changed(current, patched); len(changed) > 0 {
+		sendError(c, http.StatusForbidden, "FIELD_NOT_WRITABLE", "Not allowed to change "+strings.Join(changed, ", "))
+		return
+	}
+
+	var req models.GuestRequest
+	if !decodePatched(c, patched, &req) {
+		return
+	}
+
+	response, err := gh.externalService.Call(c.Request.Context(), "beheerder", "PUT", endpoint, req)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	setETag(c, response, "guest")
+	filter.Apply(response, "guests", "guest")
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteGuest deletes a guest profile
+func (gh *GuestHandlers) DeleteGuest(c *gin.Context) {
+	response, err := gh.externalService.Call(c.Request.Context(), "beheerder", "DELETE", "/guests/"+url.PathEscape(c.Param("id")), nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportGuest returns everything held about a guest for a data subject
+// access request: the full profile, their bookings and who accessed the
+// profile through this gateway. Field filters do not apply.
+func (gh *GuestHandlers) ExportGuest(c *gin.Context) {
+	id := c.Param("id")
+
+	profile, err := gh.externalService.Call(c.Request.Context(), "beheerder", "GET", "/guests/"+url.PathEscape(id), nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+	if nested, ok := profile["guest"].(map[string]interface{}); ok {
+		profile = nested
+	}
+
+	bookings, err := gh.externalService.Call(c.Request.Context(), "beheerder", "GET", "/bookings?guest_id="+url.QueryEscape(id), nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+	bookingList, ok := bookings["bookings"].([]interface{})
+	if !ok {
+		bookingList, _ = bookings["data"].([]interface{})
+	}
+	if bookingList == nil {
+		bookingList = []interface{}{}
+	}
+
+	entries, err := audit.Query(c.Request.Context(), audit.Filter{ResourceType: "guests", ResourceID: id})
+	if err != nil {
+		sendError(c, http.StatusServiceUnavailable, "AUDIT_STORE_UNAVAILABLE", err.Error())
+		return
+	}
+	trail := []models.GuestAccessRecord{}
+	for _, entry := range entries {
+		trail = append(trail, models.GuestAccessRecord{
+			Timestamp: entry.Timestamp.Unix(),
+			UserID:    entry.UserID,
+			Action:    entry.Action,
+			Method:    entry.Method,
+			Path:      entry.Path,
+		})
+	}
+
+	recordAuditEvent(c, "guest_exported", "guests", id)
+
+	c.Header("Content-Disposition", "attachment; filename=\"guest-"+url.PathEscape(id)+"-export.json\"")
+	c.JSON(http.StatusOK, models.GuestExport{
+		GuestID:     id,
+		ExportedAt:  time.Now().Unix(),
+		ExportedBy:  c.GetString("userID"),
+		Profile:     profile,
+		Bookings:    bookingList,
+		AccessTrail: trail,
+	})
+}
+
+// ErasePersonalData anonymizes a guest's personal data in API Beheerder
+// while keeping their booking history for accounting
+func (gh *GuestHandlers) ErasePersonalData(c *gin.Context) {
+	id := c.Param("id")
+
+	payload := map[string]interface{}{
+		"requested_by": c.GetString("userID"),
+		"reason":       c.DefaultQuery("reason", "data_subject_request"),
+	}
+	response, err := gh.externalService.Call(c.Request.Context(), "beheerder", "POST", "/guests/"+url.PathEscape(id)+"/anonymize", payload)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	recordAuditEvent(c, "guest_erased", "guests", id)
+	c.JSON(http.StatusOK, response)
+}
+
+// filterGuestFields removes the guest fields Central Management hides from
+// the current user. It writes the error response and returns false on failure.
+func filterGuestFields(c *gin.Context, response map[string]interface{}) bool {
+	filter, ok := guestFieldFilter(c)
+	if ok {
+		filter.Apply(response, "guests", "guest")
+	}
+	return ok
+}
+
+// guestFieldFilter returns the guest fields hidden from the caller. Service
+// API keys are governed by scopes and see every field. It writes the error response and returns false when
+// the filter cannot be loaded.
+func guestFieldFilter(c *gin.Context) (permissions.FieldFilter, bool) {
+	if _, isService := c.Get("api_key"); isService {
+		return permissions.FieldFilter{}, true
+	}
+	if !permissions.Enabled() {
+		sendError(c, http.StatusInternalServerError, "PERMISSIONS_NOT_CONFIGURED", "Permission checks are not configured")
+		return permissions.FieldFilter{}, false
+	}
+
+	filter, err := permissions.Fields(c.Request.Context(), c.GetString("userID"), "guests")
+	if err != nil {
+		sendFilterLookupError(c, err)
+		return permissions.FieldFilter{}, false
+	}
+	return filter, true
+}
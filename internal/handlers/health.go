@@ -2,26 +2,158 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
-	"InternalAPI/internal/circuitbreaker"
+	"InternalAPI/internal/broker"
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/health"
+	"InternalAPI/internal/middleware"
 	"InternalAPI/internal/models"
+	"InternalAPI/internal/resilience"
+	"InternalAPI/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
-// HealthHandler handles health check requests
-func HealthHandler(c *gin.Context) {
+// startedAt is when the process started, for the uptime in health responses
+var startedAt = time.Now()
+
+// HealthHandlers serves the liveness, readiness and aggregate health checks
+type HealthHandlers struct {
+	externalService *services.ExternalService
+	upstreams       map[string]bool // Upstreams checked for readiness; nil checks all
+	requireBroker   bool
+}
+
+// readinessCheck is the outcome of one readiness check
+type readinessCheck struct {
+	Ready  bool        `json:"ready"`
+	Detail interface{} `json:"detail,omitempty"`
+}
+
+// NewHealthHandlers creates health handlers
+func NewHealthHandlers(config *config.Config) *HealthHandlers {
+	h := &HealthHandlers{
+		externalService: services.New(config),
+		requireBroker:   config.ReadinessRequireBroker,
+	}
+	for _, name := range strings.Split(config.ReadinessUpstreams, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			if h.upstreams == nil {
+				h.upstreams = make(map[string]bool)
+			}
+			h.upstreams[name] = true
+		}
+	}
+	return h
+}
+
+// GetLiveness reports that the process is up and serving requests. It checks no
+// dependencies, so a liveness probe never restarts the gateway because a
+// backend is briefly unavailable.
+func (h *HealthHandlers) GetLiveness(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"status":    "healthy",
-		"service":   "internal-api",
+		"status":         "alive",
+		"uptime_seconds": int64(time.Since(startedAt).Seconds()),
+		"timestamp":      time.Now().Unix(),
+	})
+}
+
+// GetReadiness reports whether the instance should receive traffic: reference
+// data is warmed, no checked upstream has an open circuit breaker or is
+// down according to the background probes, and the instance is registered
+// with the broker. It answers 503 otherwise.
+func (h *HealthHandlers) GetReadiness(c *gin.Context) {
+	ready, checks := h.readiness()
+
+	status := http.StatusOK
+	state := "ready"
+	if !ready {
+		status = http.StatusServiceUnavailable
+		state = "not_ready"
+	}
+
+	c.JSON(status, gin.H{
+		"status":    state,
+		"checks":    checks,
 		"timestamp": time.Now().Unix(),
 	})
 }
 
+// GetHealth aggregates liveness and readiness for people and dashboards. It
+// answers 200 while the process is alive, with status "degraded" when a
+// readiness check fails.
+func (h *HealthHandlers) GetHealth(c *gin.Context) {
+	ready, checks := h.readiness()
+
+	state := "healthy"
+	if !ready {
+		state = "degraded"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":         state,
+		"service":        "internal-api",
+		"live":           true,
+		"ready":          ready,
+		"checks":         checks,
+		"uptime_seconds": int64(time.Since(startedAt).Seconds()),
+		"timestamp":      time.Now().Unix(),
+	})
+}
+
+// readiness runs the readiness checks and reports whether all passed
+func (h *HealthHandlers) readiness() (bool, map[string]readinessCheck) {
+	checks := make(map[string]readinessCheck)
+
+	warm := services.GetWarmStatus()
+	checks["reference_data"] = readinessCheck{Ready: warm.State == "complete", Detail: warm}
+
+	// A failed call only degrades an upstream; it is down once its breaker
+	// opens
+	upstreams := make(map[string]services.UpstreamHealth)
+	upstreamsReady := true
+	for _, dep := range h.externalService.Dependencies() {
+		if h.upstreams != nil && !h.upstreams[dep.Name] {
+			continue
+		}
+		upstreams[dep.Name] = dep.Health
+		if dep.Health.Status == "down" {
+			upstreamsReady = false
+		}
+	}
+	checks["upstreams"] = readinessCheck{Ready: upstreamsReady, Detail: upstreams}
+
+	// Probe results are cached by the background prober
+	probes := make(map[string]health.Status)
+	probesReady := true
+	for _, status := range health.Statuses() {
+		if h.upstreams != nil && !h.upstreams[status.Name] {
+			continue
+		}
+		probes[status.Name] = status
+		if status.Status == "down" {
+			probesReady = false
+		}
+	}
+	checks["probes"] = readinessCheck{Ready: probesReady, Detail: probes}
+
+	if h.requireBroker {
+		registration := broker.Status()
+		checks["broker"] = readinessCheck{Ready: registration.Registered, Detail: registration}
+	}
+
+	ready := true
+	for _, check := range checks {
+		ready = ready && check.Ready
+	}
+	return ready, checks
+}
+
 // GetCircuitBreakerStatusHandler returns the status of all circuit breakers
 func GetCircuitBreakerStatusHandler(c *gin.Context) {
-	status := circuitbreaker.GetAllStatus()
+	status := resilience.BreakerStatus()
 
 	c.JSON(http.StatusOK, gin.H{
 		"circuit_breakers": status,
@@ -33,7 +165,7 @@ func GetCircuitBreakerStatusHandler(c *gin.Context) {
 func ResetCircuitBreakerHandler(c *gin.Context) {
 	serviceName := c.Param("service")
 
-	err := circuitbreaker.ResetByName(serviceName)
+	err := resilience.ResetBreaker(serviceName)
 	if err != nil {
 		sendError(c, http.StatusNotFound, "SERVICE_NOT_FOUND", "Circuit breaker for service not found")
 		return
@@ -44,6 +176,93 @@ func ResetCircuitBreakerHandler(c *gin.Context) {
 	})
 }
 
+// UpdateCircuitBreakerConfigHandler tunes a circuit breaker at runtime.
+// Omitted fields keep their value; the breaker's state is not changed.
+func UpdateCircuitBreakerConfigHandler(c *gin.Context) {
+	serviceName := c.Param("service")
+	cb := resilience.Breaker(serviceName)
+	if cb == nil {
+		sendError(c, http.StatusNotFound, "SERVICE_NOT_FOUND", "Circuit breaker for service not found")
+		return
+	}
+
+	var req models.CircuitBreakerConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	previous := cb.Settings()
+	settings := previous
+	if req.FailureThreshold != nil {
+		settings.FailureThreshold = *req.FailureThreshold
+	}
+	if req.TimeoutSeconds != nil {
+		settings.Timeout = time.Duration(*req.TimeoutSeconds) * time.Second
+	}
+	if req.MaxRetries != nil {
+		settings.MaxRetries = *req.MaxRetries
+	}
+	if req.RetryDelayMs != nil {
+		settings.RetryDelay = time.Duration(*req.RetryDelayMs) * time.Millisecond
+	}
+	if req.HalfOpenMaxProbes != nil {
+		settings.HalfOpenProbes = *req.HalfOpenMaxProbes
+	}
+	if req.SuccessThreshold != nil {
+		settings.SuccessThreshold = *req.SuccessThreshold
+	}
+	if req.Policy != nil {
+		settings.Trip = resilience.TripPolicy(*req.Policy)
+	}
+	if req.ErrorRatePercent != nil {
+		settings.ErrorRateThreshold = *req.ErrorRatePercent
+	}
+	if req.WindowSeconds != nil {
+		settings.Window = time.Duration(*req.WindowSeconds) * time.Second
+	}
+	if req.MinCalls != nil {
+		settings.MinCalls = *req.MinCalls
+	}
+
+	cb.Configure(settings)
+	recordAuditEvent(c, "circuit_breaker_config_changed", "circuit_breaker", serviceName)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Circuit breaker for " + serviceName + " has been reconfigured",
+		"service":  serviceName,
+		"previous": breakerSettingsResponse(previous),
+		"config":   breakerSettingsResponse(cb.Settings()),
+	})
+}
+
+// breakerSettingsResponse renders a breaker configuration for admin responses
+func breakerSettingsResponse(settings resilience.Settings) gin.H {
+	return gin.H{
+		"failure_threshold":    settings.FailureThreshold,
+		"timeout_seconds":      int(settings.Timeout.Seconds()),
+		"max_retries":          settings.MaxRetries,
+		"retry_delay_ms":       settings.RetryDelay.Milliseconds(),
+		"half_open_max_probes": settings.HalfOpenProbes,
+		"success_threshold":    settings.SuccessThreshold,
+		"policy":               settings.Trip,
+		"error_rate_percent":   settings.ErrorRateThreshold,
+		"window_seconds":       int(settings.Window.Seconds()),
+		"min_calls":            settings.MinCalls,
+	}
+}
+
+// MiddlewareChainHandler lists the effective middleware chain of every route
+func MiddlewareChainHandler(router *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		routes := middleware.RouteChains(router)
+		c.JSON(http.StatusOK, gin.H{
+			"routes": routes,
+			"count":  len(routes),
+		})
+	}
+}
+
 // sendError sends an error response
 func sendError(c *gin.Context, statusCode int, code, message string) {
 	c.JSON(statusCode, models.ErrorResponse{
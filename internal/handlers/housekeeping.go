@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/middleware"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxHousekeepingLineBytes bounds a single NDJSON line
+const maxHousekeepingLineBytes = 16 * 1024
+
+// housekeepingStatuses are the room statuses housekeeping may set
+var housekeepingStatuses = map[string]bool{
+	"available":    true,
+	"cleaning":     true,
+	"dirty":        true,
+	"maintenance":  true,
+	"out_of_order": true,
+}
+
+// HousekeepingHandlers applies streamed room status updates through API Beheerder
+type HousekeepingHandlers struct {
+	externalService *services.ExternalService
+	concurrency     int
+	idleTimeout     time.Duration
+}
+
+// NewHousekeepingHandlers creates a new housekeeping handlers instance
+func NewHousekeepingHandlers(config *config.Config) *HousekeepingHandlers {
+	concurrency := config.HousekeepingStreamConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &HousekeepingHandlers{
+		externalService: services.New(config),
+		concurrency:     concurrency,
+		idleTimeout:     config.HousekeepingStreamIdleTimeout,
+	}
+}
+
+// housekeepingStream writes acknowledgements for one stream; acks from
+// concurrent upstream calls are serialized through it
+type housekeepingStream struct {
+	c       *gin.Context
+	encoder *json.Encoder
+	touch   func()
+	summary models.HousekeepingStreamSummary
+	mu      sync.Mutex
+}
+
+// ack writes and flushes one acknowledgement line
+func (s *housekeepingStream) ack(ack models.HousekeepingAck) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch ack.Result {
+	case "applied":
+		s.summary.Applied++
+	case "invalid":
+		s.summary.Invalid++
+	case "failed":
+		s.summary.Failed++
+	}
+
+	s.encoder.Encode(ack)
+	s.c.Writer.Flush()
+	s.touch()
+}
+
+// StreamUpdates accepts NDJSON room status updates over a single long-lived
+// POST. Every line is validated and applied upstream with bounded
+// concurrency; an acknowledgement line is streamed back for each one as it
+// completes, followed by a summary line when the request body ends.
+func (hh *HousekeepingHandlers) StreamUpdates(c *gin.Context) {
+	touch, err := middleware.OpenStream(c, hh.idleTimeout)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "STREAM_NOT_SUPPORTED", "Streaming is not supported on this connection")
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	start := time.Now()
+	stream := &housekeepingStream{c: c, encoder: json.NewEncoder(c.Writer), touch: touch}
+	userID := c.GetString("userID")
+	ctx := c.Request.Context()
+
+	scanner := bufio.NewScanner(c.Request.Body)
+	scanner.Buffer(make([]byte, 0, 4096), maxHousekeepingLineBytes)
+
+	slots := make(chan struct{}, hh.concurrency)
+	var wg sync.WaitGroup
+	var streamErr error
+
+	line := 0
+read:
+	for scanner.Scan() {
+		line++
+		touch()
+
+		raw := scanner.Bytes()
+		if len(raw) == 0 {
+			continue
+		}
+
+		stream.mu.Lock()
+		stream.summary.Received++
+		stream.mu.Unlock()
+
+		update, err := parseHousekeepingUpdate(raw)
+		if err != nil {
+			stream.ack(models.HousekeepingAck{Line: line, ID: update.ID, RoomID: update.RoomID, Result: "invalid", Error: err.Error()})
+			continue
+		}
+
+		// Waiting for a free slot stops reading, which pushes back on the client
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			streamErr = ctx.Err()
+			break read
+		}
+
+		wg.Add(1)
+		go func(line int, update models.HousekeepingUpdate) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			stream.ack(hh.apply(ctx, line, update, userID))
+		}(line, update)
+	}
+	if err := scanner.Err(); err != nil && streamErr == nil {
+		streamErr = err
+	}
+
+	wg.Wait()
+
+	stream.mu.Lock()
+	summary := stream.summary
+	stream.mu.Unlock()
+	summary.Summary = true
+	summary.DurationMs = time.Since(start).Milliseconds()
+	if streamErr != nil {
+		summary.Error = streamError(streamErr)
+	}
+	stream.encoder.Encode(summary)
+	c.Writer.Flush()
+}
+
+// apply sends one status update upstream
+func (hh *HousekeepingHandlers) apply(ctx context.Context, line int, update models.HousekeepingUpdate, userID string) models.HousekeepingAck {
+	ack := models.HousekeepingAck{Line: line, ID: update.ID, RoomID: update.RoomID, Result: "applied"}
+
+	payload := map[string]interface{}{
+		"status":     update.Status,
+		"updated_by": userID,
+	}
+	if update.Notes != "" {
+		payload["notes"] = update.Notes
+	}
+
+	if _, err := hh.externalService.Call(ctx, "beheerder", "PATCH", "/rooms/"+url.PathEscape(update.RoomID)+"/status", payload); err != nil {
+		ack.Result, ack.Error = "failed", err.Error()
+	}
+	return ack
+}
+
+// parseHousekeepingUpdate decodes and validates one NDJSON line. The partly
+// decoded update is returned with the error so the ack can reference it.
+func parseHousekeepingUpdate(raw []byte) (models.HousekeepingUpdate, error) {
+	var update models.HousekeepingUpdate
+	if err := json.Unmarshal(raw, &update); err != nil {
+		return update, errors.New("line is not a JSON object")
+	}
+	if update.RoomID == "" {
+		return update, errors.New("room_id is required")
+	}
+	if !housekeepingStatuses[update.Status] {
+		return update, errors.New("status must be one of available, cleaning, dirty, maintenance, out_of_order")
+	}
+	if len(update.Notes) > 500 {
+		return update, errors.New("notes must be at most 500 characters")
+	}
+	return update, nil
+}
+
+// streamError describes why a stream ended early
+func streamError(err error) string {
+	switch {
+	case errors.Is(err, bufio.ErrTooLong):
+		return "line exceeds 16KB; stream closed"
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return "no line received within the idle timeout; stream closed"
+	case errors.Is(err, context.Canceled):
+		return "client disconnected"
+	default:
+		return err.Error()
+	}
+}
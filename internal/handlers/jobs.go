@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"InternalAPI/internal/jobs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJobHandler returns the status of a background job and, once it has
+// finished, the status code and body the request would have answered with.
+// Only the user who queued a job, or an admin, can see it.
+func GetJobHandler(c *gin.Context) {
+	job, exists, err := jobs.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		sendError(c, http.StatusServiceUnavailable, "JOB_STORE_UNAVAILABLE", "The job store could not be reached, please retry")
+		return
+	}
+	if !exists || (job.UserID != c.GetString("userID") && !hasAnyRole(c, "admin", "super_admin")) {
+		sendError(c, http.StatusNotFound, "JOB_NOT_FOUND", "Job not found")
+		return
+	}
+
+	if !job.Finished() {
+		c.Header("Retry-After", "2")
+	}
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/middleware"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/reload"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListJWTKeysHandler lists the keys access tokens are accepted with, the
+// signing key first. Secrets are never returned.
+func ListJWTKeysHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"keys":      middleware.JWTKeys(),
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// RotateJWTKeyHandler makes a new key sign access tokens. The replaced key
+// stays accepted for grace_seconds, by default the access token lifetime.
+// The rotation lasts until this instance restarts or reloads the JWT
+// settings; persist it through JWT_SECRET and JWT_PREVIOUS_SECRETS.
+func RotateJWTKeyHandler(c *gin.Context) {
+	var req models.JWTKeyRotateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			sendError(c, http.StatusInternalServerError, "JWT_KEY_GENERATION_FAILED", "Could not generate a JWT key")
+			return
+		}
+		secret = hex.EncodeToString(random)
+	}
+	grace := reload.Effective().AccessTokenTTL
+	if req.GraceSeconds != nil {
+		grace = time.Duration(*req.GraceSeconds) * time.Second
+	}
+
+	middleware.RotateJWT(secret, grace)
+	keys := middleware.JWTKeys()
+	recordAuditEvent(c, "jwt_key_rotated", "jwt_key", keys[0].ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"keys":      keys,
+		"timestamp": time.Now().Unix(),
+	})
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"InternalAPI/internal/labels"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantHeader selects tenant-specific label overrides
+const TenantHeader = "X-Tenant-ID"
+
+// albumLabelFields maps album response fields to label catalog enums
+var albumLabelFields = map[string]string{
+	"status": "room_status",
+}
+
+// labelContext determines the tenant, locale and audience used to label a
+// response. Front-desk staff get staff wording; everyone else gets guest wording.
+func labelContext(c *gin.Context) labels.Context {
+	tenant := c.GetHeader(TenantHeader)
+	if tenant == "" {
+		tenant = c.Query("hotel_id")
+	}
+
+	locale := c.Query("locale")
+	if locale == "" {
+		locale = labels.Negotiate(c.GetHeader("Accept-Language"))
+	}
+
+	audience := labels.AudienceGuest
+	if hasAnyRole(c, frontDeskRoles...) {
+		audience = labels.AudienceStaff
+	}
+
+	return labels.Context{Tenant: tenant, Locale: locale, Audience: audience}
+}
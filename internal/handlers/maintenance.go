@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/maintenance"
+	"InternalAPI/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMaintenanceWindowsHandler lists scheduled maintenance windows
+func GetMaintenanceWindowsHandler(c *gin.Context) {
+	windows := maintenance.List()
+
+	c.JSON(http.StatusOK, gin.H{
+		"maintenance_windows": windows,
+		"count":               len(windows),
+		"timestamp":           time.Now().Unix(),
+	})
+}
+
+// GetMaintenanceWindowHandler returns a single maintenance window
+func GetMaintenanceWindowHandler(c *gin.Context) {
+	window, exists := maintenance.Get(c.Param("id"))
+	if !exists {
+		sendError(c, http.StatusNotFound, "MAINTENANCE_WINDOW_NOT_FOUND", "Maintenance window not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+// CreateMaintenanceWindowHandler schedules a maintenance window
+func CreateMaintenanceWindowHandler(c *gin.Context) {
+	var req models.MaintenanceWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	window, err := maintenance.Create(maintenance.Window{
+		Service:   req.Service,
+		Start:     req.Start,
+		End:       req.End,
+		Mode:      req.Mode,
+		Reason:    req.Reason,
+		CreatedBy: c.GetString("userID"),
+	})
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, window)
+}
+
+// UpdateMaintenanceWindowHandler reschedules a maintenance window
+func UpdateMaintenanceWindowHandler(c *gin.Context) {
+	var req models.MaintenanceWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	if _, exists := maintenance.Get(c.Param("id")); !exists {
+		sendError(c, http.StatusNotFound, "MAINTENANCE_WINDOW_NOT_FOUND", "Maintenance window not found")
+		return
+	}
+
+	window, err := maintenance.Update(c.Param("id"), maintenance.Window{
+		Service: req.Service,
+		Start:   req.Start,
+		End:     req.End,
+		Mode:    req.Mode,
+		Reason:  req.Reason,
+	})
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+// DeleteMaintenanceWindowHandler cancels a maintenance window
+func DeleteMaintenanceWindowHandler(c *gin.Context) {
+	if err := maintenance.Delete(c.Param("id")); err != nil {
+		sendError(c, http.StatusNotFound, "MAINTENANCE_WINDOW_NOT_FOUND", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Maintenance window has been cancelled",
+	})
+}
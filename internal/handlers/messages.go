@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/messaging"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/roles"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// frontDeskRoles may read the message queue and reply to guests
+var frontDeskRoles = []string{"front_desk", "admin", "super_admin"}
+
+// MessageHandlers serves guest message threads, which are only open to the
+// guest holding the reservation and to front-desk staff
+type MessageHandlers struct {
+	externalService *services.ExternalService
+}
+
+// NewMessageHandlers creates a new message handlers instance
+func NewMessageHandlers(config *config.Config) *MessageHandlers {
+	return &MessageHandlers{
+		externalService: services.New(config),
+	}
+}
+
+// PostGuestMessage lets a guest send a message about their reservation
+func (mh *MessageHandlers) PostGuestMessage(c *gin.Context) {
+	var req models.MessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if !mh.checkReservationAccess(c) {
+		return
+	}
+
+	msg, err := messaging.Post(c.Param("id"), c.GetString("userID"), req.Body)
+	if err != nil {
+		sendError(c, http.StatusUnprocessableEntity, "MESSAGE_REJECTED", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg)
+}
+
+// GetReservationMessages returns the message thread for a reservation to
+// the guest holding it and to front-desk staff
+func (mh *MessageHandlers) GetReservationMessages(c *gin.Context) {
+	if !mh.checkReservationAccess(c) {
+		return
+	}
+	thread := messaging.Thread(c.Param("id"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"reservation_id": c.Param("id"),
+		"messages":       thread,
+		"count":          len(thread),
+	})
+}
+
+// checkReservationAccess lets front-desk staff through and otherwise looks
+// the reservation up with the PMS: only the guest whose email it is booked
+// under may use its thread. It writes the error response and returns false
+// when access is denied or the lookup fails.
+func (mh *MessageHandlers) checkReservationAccess(c *gin.Context) bool {
+	if hasAnyRole(c, frontDeskRoles...) {
+		return true
+	}
+
+	response, err := pmsBackend(c, mh.externalService, "").Call(c.Request.Context(), "GET", "/bookings/"+url.PathEscape(c.Param("id")), nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return false
+	}
+	booking, ok := response["booking"].(map[string]interface{})
+	if !ok {
+		booking = response
+	}
+
+	value, _ := c.Get("user")
+	user, _ := value.(*models.UserInfo)
+	guestEmail := stringField(booking, "guest_email")
+	if user == nil || user.Email == "" || !strings.EqualFold(guestEmail, user.Email) {
+		sendError(c, http.StatusForbidden, "INSUFFICIENT_PERMISSIONS", "You do not have access to this conversation")
+		return false
+	}
+	return true
+}
+
+// GetFrontDeskQueueHandler lists guest messages for front-desk staff (?status=open by default)
+func GetFrontDeskQueueHandler(c *gin.Context) {
+	status := c.DefaultQuery("status", messaging.StatusOpen)
+	if status == "all" {
+		status = ""
+	}
+
+	queue := messaging.Queue(status)
+
+	c.JSON(http.StatusOK, gin.H{
+		"messages":  queue,
+		"count":     len(queue),
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// ReplyToMessageHandler posts a front-desk reply; the guest is notified via
+// registered notifiers, which push it to their event stream
+func ReplyToMessageHandler(c *gin.Context) {
+	var req models.MessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	reply, err := messaging.Reply(c.Param("id"), c.GetString("userID"), req.Body)
+	if errors.Is(err, messaging.ErrMessageNotFound) {
+		sendError(c, http.StatusNotFound, "MESSAGE_NOT_FOUND", err.Error())
+		return
+	}
+	if err != nil {
+		sendError(c, http.StatusUnprocessableEntity, "MESSAGE_REJECTED", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, reply)
+}
+
+// CloseMessageHandler marks a guest message as handled without replying
+func CloseMessageHandler(c *gin.Context) {
+	msg, err := messaging.Close(c.Param("id"))
+	if err != nil {
+		sendError(c, http.StatusNotFound, "MESSAGE_NOT_FOUND", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, msg)
+}
+
+// hasAnyRole reports whether the authenticated user holds one of the roles,
+// directly or through the role hierarchy
+func hasAnyRole(c *gin.Context, required ...string) bool {
+	userInterface, exists := c.Get("user")
+	if !exists {
+		return false
+	}
+	user, ok := userInterface.(*models.UserInfo)
+	if !ok {
+		return false
+	}
+
+	return roles.Satisfies(user.Roles, required...)
+}
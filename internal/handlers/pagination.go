@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"InternalAPI/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listQuery builds the upstream query string for a paginated list. Requests
+// with cursor or limit use signed cursor pagination, translated into the
+// upstream's native cursor or offset/limit (plus the equivalent
+// page/page_size); other requests forward page/page_size as before. The
+// returned cursor is nil in page mode. It writes the error response and
+// returns false on failure.
+func listQuery(c *gin.Context, scope string, filters url.Values) (string, *models.Cursor, bool) {
+	params := url.Values{}
+	for key, values := range filters {
+		params[key] = values
+	}
+
+	var cursorParams models.CursorParams
+	if err := c.ShouldBindQuery(&cursorParams); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return "", nil, false
+	}
+
+	if cursorParams.Cursor == "" && cursorParams.Limit == 0 {
+		var page models.PaginationParams
+		if err := c.ShouldBindQuery(&page); err != nil {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return "", nil, false
+		}
+		if page.Page > 0 || page.PageSize > 0 {
+			params.Set("page", strconv.Itoa(page.GetPage()))
+			params.Set("page_size", strconv.Itoa(page.GetPageSize()))
+		}
+		return encodeQuery(params), nil, true
+	}
+
+	filter := filterFingerprint(filters)
+	cursor := models.Cursor{Scope: scope, Filter: filter, Limit: cursorParams.GetLimit()}
+	if cursorParams.Cursor != "" {
+		decoded, err := models.DecodeCursor(cursorParams.Cursor, scope, filter)
+		if err != nil {
+			sendError(c, http.StatusBadRequest, "INVALID_CURSOR", "The cursor is invalid or was issued for a different list or filters")
+			return "", nil, false
+		}
+		cursor = decoded
+	}
+
+	params.Set("limit", strconv.Itoa(cursor.Limit))
+	if cursor.Upstream != "" {
+		params.Set("cursor", cursor.Upstream)
+	} else {
+		params.Set("offset", strconv.Itoa(cursor.Offset))
+		params.Set("page", strconv.Itoa(cursor.Offset/cursor.Limit+1))
+		params.Set("page_size", strconv.Itoa(cursor.Limit))
+	}
+	return encodeQuery(params), &cursor, true
+}
+
+// filterOperators are the comparisons a filter expression may use
+var filterOperators = []string{"eq", "ne", "lt", "lte", "gt", "gte", "contains", "in"}
+
+// sortAndFilter validates the sort order and filter expressions of a list
+// against the fields it may be sorted and filtered by, and returns them as
+// upstream query parameters to pass to listQuery with the other filters, so
+// cursors stay bound to them. It writes the error response and returns false
+// on failure.
+func sortAndFilter(c *gin.Context, fields []string) (url.Values, bool) {
+	var query models.ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return nil, false
+	}
+
+	params := url.Values{}
+	if query.Sort != "" {
+		var order []string
+		seen := make(map[string]bool)
+		for _, key := range strings.Split(query.Sort, ",") {
+			key = strings.TrimSpace(key)
+			field := strings.TrimPrefix(key, "-")
+			if !slices.Contains(fields, field) || seen[field] {
+				sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "sort takes distinct fields of "+strings.Join(fields, ", ")+", each optionally prefixed with -")
+				return nil, false
+			}
+			seen[field] = true
+			order = append(order, key)
+		}
+		params.Set("sort", strings.Join(order, ","))
+	}
+
+	for _, expression := range query.Filter {
+		parts := strings.SplitN(expression, ":", 3)
+		if len(parts) != 3 || !slices.Contains(fields, parts[0]) || !slices.Contains(filterOperators, parts[1]) || parts[2] == "" {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "filter takes field:operator:value with a field of "+strings.Join(fields, ", ")+" and an operator of "+strings.Join(filterOperators, ", "))
+			return nil, false
+		}
+		params.Add("filter", expression)
+	}
+	return params, true
+}
+
+// setNextCursor adds next_cursor and has_more to an upstream list response.
+// An upstream next_cursor is wrapped in a signed cursor; otherwise a full
+// page of items means another page may follow.
+func setNextCursor(response map[string]interface{}, cursor *models.Cursor, itemsKey string) {
+	if cursor == nil || response == nil {
+		return
+	}
+
+	var next *models.Cursor
+	if upstream, _ := response["next_cursor"].(string); upstream != "" {
+		next = &models.Cursor{Scope: cursor.Scope, Filter: cursor.Filter, Upstream: upstream, Limit: cursor.Limit}
+	} else if cursor.Upstream == "" {
+		items, ok := response[itemsKey].([]interface{})
+		if !ok {
+			items, _ = response["data"].([]interface{})
+		}
+		if len(items) >= cursor.Limit {
+			next = &models.Cursor{Scope: cursor.Scope, Filter: cursor.Filter, Offset: cursor.Offset + cursor.Limit, Limit: cursor.Limit}
+		}
+	}
+
+	if next == nil {
+		response["next_cursor"] = nil
+		response["has_more"] = false
+		return
+	}
+	response["next_cursor"] = models.EncodeCursor(*next)
+	response["has_more"] = true
+}
+
+// pageOf wraps the items of an upstream list page in a PaginatedResponse.
+// count is the number of items the upstream returned, before any were
+// filtered out. Totals the upstream does not report are counted up to the
+// current page, plus one page when it is full.
+func pageOf(response map[string]interface{}, data interface{}, count int, page models.PaginationParams, cursor *models.Cursor) models.PaginatedResponse {
+	result := models.PaginatedResponse{Data: data, Page: page.GetPage(), PageSize: page.GetPageSize()}
+	more := count >= result.PageSize
+	if cursor != nil {
+		result.Page, result.PageSize = 0, cursor.Limit
+		if cursor.Upstream == "" {
+			result.Page = cursor.Offset/cursor.Limit + 1
+		}
+		result.NextCursor, _ = response["next_cursor"].(string)
+		more, _ = response["has_more"].(bool)
+		result.HasMore = &more
+	}
+	result.FilteredOut, _ = response["filtered_out"].(int) // Set by applyListFilter
+
+	// Upstream totals count the items the user's filter hides, so they are
+	// only passed on when the page hid nothing
+	visible := result.FilteredOut == 0
+	if total, ok := firstNumber(response, "total_items", "total", "total_count"); ok && visible {
+		result.TotalItems = total
+	} else if result.Page > 0 {
+		result.TotalItems = (result.Page-1)*result.PageSize + count - result.FilteredOut
+	}
+	if pages, ok := firstNumber(response, "total_pages"); ok && visible {
+		result.TotalPages = pages
+	} else if result.PageSize > 0 {
+		result.TotalPages = (result.TotalItems + result.PageSize - 1) / result.PageSize
+		if more && result.TotalPages <= result.Page {
+			result.TotalPages = result.Page + 1
+		}
+	}
+	return result
+}
+
+// listItems returns the items of a list response, read from listKey or
+// "data"
+func listItems(response map[string]interface{}, listKey string) []interface{} {
+	items, ok := response[listKey].([]interface{})
+	if !ok {
+		items, _ = response["data"].([]interface{})
+	}
+	if items == nil {
+		items = []interface{}{}
+	}
+	return items
+}
+
+// filterFingerprint identifies a filter set so cursors cannot be replayed
+// against different filters
+func filterFingerprint(filters url.Values) string {
+	if len(filters) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(filters.Encode()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// encodeQuery returns "?query" or "" when there are no parameters
+func encodeQuery(params url.Values) string {
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + params.Encode()
+}
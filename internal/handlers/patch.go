@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"InternalAPI/internal/patch"
+	"InternalAPI/internal/pms"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// unwrapRecord returns the resource of an upstream response, which may be
+// nested under key
+func unwrapRecord(response map[string]interface{}, key string) map[string]interface{} {
+	if nested, ok := response[key].(map[string]interface{}); ok {
+		return nested
+	}
+	return response
+}
+
+// resourceETag derives a strong ETag from the upstream representation of a
+// resource. encoding/json sorts map keys, so equal records hash equally.
+func resourceETag(record map[string]interface{}) string {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// setETag sets the ETag header for the resource in an upstream response
+func setETag(c *gin.Context, response map[string]interface{}, key string) {
+	if etag := resourceETag(unwrapRecord(response, key)); etag != "" {
+		c.Header("ETag", etag)
+	}
+}
+
+// ifMatchSatisfied reports whether an If-Match header accepts etag. A
+// missing header always matches.
+func ifMatchSatisfied(header, etag string) bool {
+	if header == "" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// loadPatched fetches the resource at endpoint, checks If-Match against it
+// and applies the request body as a JSON Merge Patch or JSON Patch. It
+// returns the current and patched records, or writes the error response
+// and returns false.
+func loadPatched(c *gin.Context, backend pms.Adapter, endpoint, key string) (map[string]interface{}, map[string]interface{}, bool) {
+	response, err := backend.Call(c.Request.Context(), "GET", endpoint, nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return nil, nil, false
+	}
+	current := unwrapRecord(response, key)
+
+	etag := resourceETag(current)
+	if !ifMatchSatisfied(c.GetHeader("If-Match"), etag) {
+		c.Header("ETag", etag)
+		sendError(c, http.StatusPreconditionFailed, "PRECONDITION_FAILED", "The resource was changed since it was read; fetch it again and reapply the patch")
+		return nil, nil, false
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "Failed to read request body")
+		return nil, nil, false
+	}
+
+	patched, err := patch.Apply(c.GetHeader("Content-Type"), body, current)
+	switch {
+	case errors.Is(err, patch.ErrUnsupportedType):
+		sendError(c, http.StatusUnsupportedMediaType, "UNSUPPORTED_PATCH_FORMAT", err.Error())
+		return nil, nil, false
+	case errors.Is(err, patch.ErrTestFailed):
+		sendError(c, http.StatusConflict, "PATCH_TEST_FAILED", err.Error())
+		return nil, nil, false
+	case err != nil:
+		sendError(c, http.StatusUnprocessableEntity, "INVALID_PATCH", err.Error())
+		return nil, nil, false
+	}
+
+	if !reflect.DeepEqual(current["id"], patched["id"]) {
+		sendError(c, http.StatusUnprocessableEntity, "INVALID_PATCH", "id cannot be changed")
+		return nil, nil, false
+	}
+	return current, patched, true
+}
+
+// rejectChanged writes an INVALID_PATCH error and returns true when the
+// patch changed one of fields; they are updated through other endpoints
+func rejectChanged(c *gin.Context, current, patched map[string]interface{}, fields ...string) bool {
+	for _, field := range fields {
+		if !reflect.DeepEqual(current[field], patched[field]) {
+			sendError(c, http.StatusUnprocessableEntity, "INVALID_PATCH", field+" cannot be changed with PATCH")
+			return true
+		}
+	}
+	return false
+}
+
+// decodePatched converts a patched record into the update model and runs
+// its binding validation, so patches are held to the same rules as PUT
+func decodePatched(c *gin.Context, patched map[string]interface{}, target interface{}) bool {
+	raw, err := json.Marshal(patched)
+	if err == nil {
+		err = json.Unmarshal(raw, target)
+	}
+	if err == nil {
+		err = binding.Validator.ValidateStruct(target)
+	}
+	if err != nil {
+		sendError(c, http.StatusUnprocessableEntity, "INVALID_PATCH", "Patched resource is invalid: "+err.Error())
+		return false
+	}
+	return true
+}
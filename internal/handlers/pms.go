@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"InternalAPI/internal/pms"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pmsBackend returns the PMS adapter serving the request's tenant, taken
+// from the tenant header, then hotelID, then the hotel_id query parameter.
+// The adapter name is kept on the context for the audit log.
+func pmsBackend(c *gin.Context, es *services.ExternalService, hotelID string) pms.Adapter {
+	tenant := c.GetHeader(TenantHeader)
+	if tenant == "" {
+		tenant = hotelID
+	}
+	if tenant == "" {
+		tenant = c.Query("hotel_id")
+	}
+
+	adapter := pms.For(tenant)
+	if adapter == nil {
+		adapter = beheerderBackend(es)
+	}
+	c.Set("pms_backend", adapter.Name())
+	return adapter
+}
+
+// beheerderBackend returns an adapter that always calls API Beheerder
+func beheerderBackend(es *services.ExternalService) pms.Adapter {
+	return pms.NewHTTPAdapter(pms.DefaultBackend, "beheerder", es)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/middleware"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ProxyHandlers forwards allow-listed requests to API Beheerder endpoints the
+// gateway has no dedicated handler for yet
+type ProxyHandlers struct {
+	externalService *services.ExternalService
+	streamIdle      time.Duration
+}
+
+// NewProxyHandlers creates a new proxy handlers instance
+func NewProxyHandlers(config *config.Config) *ProxyHandlers {
+	return &ProxyHandlers{
+		externalService: services.New(config),
+		streamIdle:      config.BeheerderStreamIdleTimeout,
+	}
+}
+
+// ForwardBeheerder sends the request to the same path on API Beheerder. It
+// runs behind middleware.ProxyGuard, which checks the allow-list and
+// permissions. Configured fields are stripped from the response.
+func (ph *ProxyHandlers) ForwardBeheerder(c *gin.Context) {
+	var body interface{}
+	if c.Request.ContentLength > 0 && c.Request.Method != http.MethodGet {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+
+	response, err := ph.externalService.Call(c.Request.Context(), "beheerder", c.Request.Method, proxyEndpoint(c), body)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	services.StripProxyFields(response)
+	c.Header("X-Proxied-Upstream", "api-beheerder")
+	c.JSON(http.StatusOK, response)
+}
+
+// StreamBeheerder copies a large API Beheerder response, such as a report
+// download or export, to the client as it arrives. It runs behind
+// middleware.StreamProxyGuard. The body is passed through unmodified, so
+// fields are not stripped.
+func (ph *ProxyHandlers) StreamBeheerder(c *gin.Context) {
+	touch, err := middleware.OpenStream(c, ph.streamIdle)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "STREAM_NOT_SUPPORTED", "Streaming is not supported on this connection")
+		return
+	}
+
+	c.Header("X-Proxied-Upstream", "api-beheerder")
+	c.Header("X-Accel-Buffering", "no")
+	writer := &touchWriter{ResponseWriter: c.Writer, touch: touch}
+	err = ph.externalService.Stream(c.Request.Context(), "beheerder", http.MethodGet, proxyEndpoint(c), c.Request.Header, writer)
+	if err == nil {
+		return
+	}
+	if !c.Writer.Written() {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+	// The status has been sent; all that is left is to cut the response short
+	logging.For(c).WithError(err).WithField("path", c.Param("path")).Warn("Streamed proxy response ended early")
+}
+
+// Passthrough forwards the request to the same path on a backend enabled
+// with PASSTHROUGH_SERVICES and copies its answer as it is, error statuses
+// included. Only the headers are rewritten, see services.Passthrough.
+func (ph *ProxyHandlers) Passthrough(service string) gin.HandlerFunc {
+	prefix := "/api/v1/proxy/" + service
+	return func(c *gin.Context) {
+		err := ph.externalService.Passthrough(c.Request.Context(), service, proxyEndpoint(c), prefix, c.ClientIP(), c.Request, c.Writer)
+		if err == nil {
+			return
+		}
+		if !c.Writer.Written() {
+			sendServiceError(c, "SERVICE_ERROR", err)
+			return
+		}
+		logging.For(c).WithError(err).WithFields(logrus.Fields{"service": service, "path": c.Param("path")}).Warn("Passthrough response ended early")
+	}
+}
+
+// touchWriter extends the stream deadlines on every write
+type touchWriter struct {
+	gin.ResponseWriter
+	touch func()
+}
+
+// Write writes a chunk of the response
+func (w *touchWriter) Write(data []byte) (int, error) {
+	w.touch()
+	return w.ResponseWriter.Write(data)
+}
+
+// proxyEndpoint returns the backend endpoint of a wildcard proxy request
+func proxyEndpoint(c *gin.Context) string {
+	segments := strings.Split(strings.Trim(c.Param("path"), "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	endpoint := "/" + strings.Join(segments, "/")
+	if c.Request.URL.RawQuery != "" {
+		endpoint += "?" + c.Request.URL.RawQuery
+	}
+	return endpoint
+}
+
+// GetProxyRulesHandler lists the API Beheerder endpoints exposed through the
+// proxy, the backends passed through and the rules transforming backend calls
+func GetProxyRulesHandler(c *gin.Context) {
+	rules := services.ProxyRules()
+
+	c.JSON(http.StatusOK, gin.H{
+		"rules":           rules,
+		"count":           len(rules),
+		"stream_rules":    services.StreamRules(),
+		"passthrough":     services.PassthroughServices(),
+		"transform_rules": services.TransformRules(),
+	})
+}
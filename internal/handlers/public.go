@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/cache"
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PublicAvailabilityHandlers serve the anonymous availability widget. Results
+// are cached much longer than for staff and only show bookable room types.
+type PublicAvailabilityHandlers struct {
+	availability *AvailabilityHandlers
+	cache        *cache.Cache
+	cacheTTL     time.Duration
+	hotels       map[string]bool // Hotels the widget may query; empty allows all
+}
+
+// NewPublicAvailabilityHandlers creates a new public availability handlers instance
+func NewPublicAvailabilityHandlers(config *config.Config, availability *AvailabilityHandlers) *PublicAvailabilityHandlers {
+	hotels := make(map[string]bool)
+	for _, hotel := range strings.Split(config.PublicAvailabilityHotels, ",") {
+		if hotel = strings.TrimSpace(hotel); hotel != "" {
+			hotels[hotel] = true
+		}
+	}
+
+	return &PublicAvailabilityHandlers{
+		availability: availability,
+		cache:        cache.New(),
+		cacheTTL:     config.PublicAvailabilityCacheTTL,
+		hotels:       hotels,
+	}
+}
+
+// SearchAvailability returns the bookable room types and prices of one hotel
+// for a stay. Unlike the staff search it never answers from partial data,
+// hides inventory counts and lets browsers and CDNs cache the response.
+func (ph *PublicAvailabilityHandlers) SearchAvailability(c *gin.Context) {
+	var query models.PublicAvailabilityQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if len(ph.hotels) > 0 && !ph.hotels[query.HotelID] {
+		sendError(c, http.StatusNotFound, "HOTEL_NOT_PUBLIC", "Availability for this hotel is not published")
+		return
+	}
+
+	checkIn, _ := time.Parse("2006-01-02", query.CheckIn)
+	checkOut, _ := time.Parse("2006-01-02", query.CheckOut)
+	nights := int(checkOut.Sub(checkIn).Hours() / 24)
+	if nights < 1 || nights > maxAvailabilityNights {
+		sendError(c, http.StatusBadRequest, "INVALID_DATE_RANGE", "check_out must be 1 to 30 nights after check_in")
+		return
+	}
+	if checkIn.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+		sendError(c, http.StatusBadRequest, "INVALID_DATE_RANGE", "check_in cannot be in the past")
+		return
+	}
+
+	key := fmt.Sprintf("%s|%s|%s|%d", query.HotelID, query.CheckIn, query.CheckOut, query.Guests)
+	cached, ok := ph.cache.Get(key)
+	if !ok {
+		response, err := ph.search(c.Request.Context(), query, nights)
+		if err != nil {
+			sendServiceError(c, "SERVICE_ERROR", err)
+			return
+		}
+		ph.cache.Set(key, response, ph.cacheTTL)
+		cached = response
+	}
+
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(ph.cacheTTL.Seconds())))
+	c.JSON(http.StatusOK, cached)
+}
+
+// search builds the public response from complete upstream data
+func (ph *PublicAvailabilityHandlers) search(ctx context.Context, query models.PublicAvailabilityQuery, nights int) (models.PublicAvailabilityResponse, error) {
+	search := models.AvailabilityQuery{
+		HotelID:       query.HotelID,
+		CheckIn:       query.CheckIn,
+		CheckOut:      query.CheckOut,
+		Guests:        query.Guests,
+		AvailableOnly: true,
+	}
+
+	sources, _, err := ph.availability.fetchSources(ctx, search)
+	if err == nil {
+		// Overstating availability to the public is worse than not answering
+		err = sources.err()
+	}
+	if err != nil {
+		return models.PublicAvailabilityResponse{}, err
+	}
+
+	response := models.PublicAvailabilityResponse{
+		HotelID:  query.HotelID,
+		CheckIn:  query.CheckIn,
+		CheckOut: query.CheckOut,
+		Nights:   nights,
+		Rooms:    []models.PublicRoomAvailability{},
+	}
+	for _, room := range filterAvailability(mergeAvailability(sources, search, nights), search) {
+		response.Rooms = append(response.Rooms, models.PublicRoomAvailability{
+			RoomType:    room.RoomType,
+			MaxGuests:   room.Capacity,
+			MinStay:     room.MinStay,
+			NightlyRate: room.NightlyRate,
+			TotalPrice:  room.TotalPrice,
+			Currency:    room.Currency,
+		})
+	}
+	return response, nil
+}
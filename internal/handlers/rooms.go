@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/events"
+	"InternalAPI/internal/labels"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// roomListFilters are the query parameters passed through to API Beheerder
+var roomListFilters = []string{"hotel_id", "status", "room_type", "floor"}
+
+// roomLabelFields maps room response fields to label catalog enums
+var roomLabelFields = map[string]string{
+	"status": "room_status",
+}
+
+// RoomHandlers contains all room-related handlers
+type RoomHandlers struct {
+	externalService *services.ExternalService
+}
+
+// NewRoomHandlers creates a new room handlers instance
+func NewRoomHandlers(config *config.Config) *RoomHandlers {
+	return &RoomHandlers{
+		externalService: services.New(config),
+	}
+}
+
+// GetRooms lists rooms, forwarding the supported filters. Results are
+// paginated by page/page_size or cursor.
+func (rh *RoomHandlers) GetRooms(c *gin.Context) {
+	filters := url.Values{}
+	for _, key := range roomListFilters {
+		if value := c.Query(key); value != "" {
+			filters.Set(key, value)
+		}
+	}
+
+	query, cursor, ok := listQuery(c, "rooms", filters)
+	if !ok {
+		return
+	}
+
+	response, err := pmsBackend(c, rh.externalService, "").Call(c.Request.Context(), "GET", "/rooms"+query, nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	setNextCursor(response, cursor, "rooms")
+	labels.Transform(response, roomLabelFields, labelContext(c))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRoomByID retrieves a specific room by ID
+func (rh *RoomHandlers) GetRoomByID(c *gin.Context) {
+	response, err := pmsBackend(c, rh.externalService, "").Call(c.Request.Context(), "GET", "/rooms/"+url.PathEscape(c.Param("id")), nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	setETag(c, response, "room")
+	labels.Transform(response, roomLabelFields, labelContext(c))
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateRoom adds a room; new rooms start dirty until housekeeping has cleaned them
+func (rh *RoomHandlers) CreateRoom(c *gin.Context) {
+	var req models.CreateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	room := models.Room{
+		HotelID:  req.HotelID,
+		Number:   req.Number,
+		RoomType: req.RoomType,
+		Floor:    req.Floor,
+		Capacity: req.Capacity,
+		Status:   models.RoomDirty,
+		Notes:    req.Notes,
+	}
+
+	response, err := pmsBackend(c, rh.externalService, room.HotelID).Call(c.Request.Context(), "POST", "/rooms", room)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateRoom updates a room's details; its status is left unchanged
+func (rh *RoomHandlers) UpdateRoom(c *gin.Context) {
+	var req models.UpdateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := pmsBackend(c, rh.externalService, "").Call(c.Request.Context(), "PUT", "/rooms/"+url.PathEscape(c.Param("id")), req)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// PatchRoom applies a JSON Merge Patch or JSON Patch to a room and sends the
+// result upstream as a full update. Status is left to the status endpoint.
+func (rh *RoomHandlers) PatchRoom(c *gin.Context) {
+	endpoint := "/rooms/" + url.PathEscape(c.Param("id"))
+	current, patched, ok := loadPatched(c, pmsBackend(c, rh.externalService, ""), endpoint, "room")
+	if !ok {
+		return
+	}
+	if rejectChanged(c, current, patched, "status") {
+		return
+	}
+
+	var req models.UpdateRoomRequest
+	if !decodePatched(c, patched, &req) {
+		return
+	}
+
+	response, err := pmsBackend(c, rh.externalService, "").Call(c.Request.Context(), "PUT", endpoint, req)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	setETag(c, response, "room")
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteRoom deletes a room
+func (rh *RoomHandlers) DeleteRoom(c *gin.Context) {
+	response, err := pmsBackend(c, rh.externalService, "").Call(c.Request.Context(), "DELETE", "/rooms/"+url.PathEscape(c.Param("id")), nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateRoomStatus moves a room to a new housekeeping status. The change is
+// checked against the room state machine, applied upstream, written to the
+// audit store and published as a room.status_changed event.
+func (rh *RoomHandlers) UpdateRoomStatus(c *gin.Context) {
+	var req models.RoomStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	endpoint := "/rooms/" + url.PathEscape(id)
+	response, err := pmsBackend(c, rh.externalService, "").Call(c.Request.Context(), "GET", endpoint, nil)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+	source := response
+	if nested, ok := response["room"].(map[string]interface{}); ok {
+		source = nested
+	}
+	current := stringField(source, "status")
+
+	if err := models.ValidateRoomTransition(current, req.Status); err != nil {
+		sendError(c, http.StatusConflict, "INVALID_STATUS_TRANSITION", err.Error())
+		return
+	}
+
+	userID := c.GetString("userID")
+	payload := map[string]interface{}{
+		"status":          req.Status,
+		"previous_status": current,
+		"updated_by":      userID,
+	}
+	if req.Notes != "" {
+		payload["notes"] = req.Notes
+	}
+
+	response, err = pmsBackend(c, rh.externalService, "").Call(c.Request.Context(), "PATCH", endpoint+"/status", payload)
+	if err != nil {
+		sendServiceError(c, "SERVICE_ERROR", err)
+		return
+	}
+
+	if current != req.Status {
+		recordRoomStatusChange(c, id, current, req.Status, userID)
+	}
+
+	labels.Transform(response, roomLabelFields, labelContext(c))
+	c.JSON(http.StatusOK, response)
+}
+
+// recordRoomStatusChange audits a status transition and publishes it on the event bus
+func recordRoomStatusChange(c *gin.Context, roomID, from, to, userID string) {
+	recordAuditEvent(c, "room_status_changed", "rooms", roomID)
+
+	events.Publish(events.Event{
+		ID:         uuid.New().String(),
+		Type:       "room.status_changed",
+		Source:     "internal-api",
+		OccurredAt: time.Now(),
+		Data: map[string]interface{}{
+			"room_id":    roomID,
+			"from":       from,
+			"to":         to,
+			"changed_by": userID,
+		},
+	})
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/rules"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkBusinessRules validates a write payload against the Central
+// Management rules for resource before it is forwarded. It writes a 422
+// listing every violation, or a 503 when the rules cannot be loaded, and
+// returns false in either case.
+func checkBusinessRules(c *gin.Context, resource string, payload interface{}) bool {
+	var record map[string]interface{}
+	raw, err := json.Marshal(payload)
+	if err == nil {
+		err = json.Unmarshal(raw, &record)
+	}
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return false
+	}
+
+	violations, err := rules.Validate(c.Request.Context(), resource, record)
+	if err != nil {
+		sendError(c, http.StatusServiceUnavailable, "BUSINESS_RULES_UNAVAILABLE", err.Error())
+		return false
+	}
+	if len(violations) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Code:       "BUSINESS_RULE_VIOLATION",
+			Message:    "The request breaks one or more business rules",
+			Violations: violations,
+			Timestamp:  time.Now().Unix(),
+		})
+		return false
+	}
+	return true
+}
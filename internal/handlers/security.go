@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/auth/lockout"
+	"InternalAPI/internal/middleware"
+	"InternalAPI/internal/permissions"
+	"InternalAPI/internal/roles"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetReputationHandler returns login reputation scores per IP and device
+func GetReputationHandler(c *gin.Context) {
+	entries, err := middleware.GetReputationStatus(c.Request.Context())
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "REPUTATION_STORE_FAILED", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries":   entries,
+		"count":     len(entries),
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// ResetReputationHandler resets the reputation score for an IP or device key
+func ResetReputationHandler(c *gin.Context) {
+	key := c.Param("key")
+
+	if err := middleware.ResetReputation(c.Request.Context(), key); err != nil {
+		if errors.Is(err, middleware.ErrReputationNotFound) {
+			sendError(c, http.StatusNotFound, "REPUTATION_NOT_FOUND", err.Error())
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "REPUTATION_STORE_FAILED", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reputation for " + key + " has been reset",
+	})
+}
+
+// GetPermissionCacheHandler reports how many permission decisions are cached
+func GetPermissionCacheHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"entries":   permissions.CacheSize(),
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// InvalidatePermissionCacheHandler drops cached permission decisions, for a
+// single user when ?user_id= is given and for everyone otherwise
+func InvalidatePermissionCacheHandler(c *gin.Context) {
+	var removed int
+	if userID := c.Query("user_id"); userID != "" {
+		removed = permissions.InvalidateUser(userID)
+	} else {
+		removed = permissions.InvalidateAll()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Permission cache invalidated",
+		"removed": removed,
+	})
+}
+
+// GetBlacklistStatusHandler reports the token blacklist size, its limits and
+// the last purge
+func GetBlacklistStatusHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	status, err := middleware.GetBlacklistStatus(ctx)
+	if err != nil {
+		sendError(c, http.StatusServiceUnavailable, "BLACKLIST_UNAVAILABLE", err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// PurgeBlacklistHandler removes expired entries from the token blacklist
+// without waiting for the scheduled cleanup
+func PurgeBlacklistHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	removed, err := middleware.PurgeBlacklist(ctx)
+	if err != nil {
+		sendError(c, http.StatusServiceUnavailable, "BLACKLIST_UNAVAILABLE", err.Error())
+		return
+	}
+	recordAuditEvent(c, "token_blacklist_purged", "token_blacklist", "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Expired blacklist entries purged",
+		"removed": removed,
+	})
+}
+
+// GetRoleHierarchyHandler returns the role hierarchy RequireRoles currently applies
+func GetRoleHierarchyHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"roles": roles.Current().Grants(),
+	})
+}
+
+// GetLockedAccountsHandler lists accounts with recent failed logins, locked accounts first
+func GetLockedAccountsHandler(c *gin.Context) {
+	accounts := lockout.List()
+
+	c.JSON(http.StatusOK, gin.H{
+		"accounts":  accounts,
+		"count":     len(accounts),
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// UnlockAccountHandler lifts an account lockout before it expires
+func UnlockAccountHandler(c *gin.Context) {
+	username := c.Param("username")
+
+	if err := lockout.Unlock(username, c.GetString("userID")); err != nil {
+		sendError(c, http.StatusNotFound, "ACCOUNT_NOT_LOCKED", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account " + username + " has been unlocked",
+	})
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/auth"
+	"InternalAPI/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListSessionsHandler lists every user with active access tokens
+func ListSessionsHandler(c *gin.Context) {
+	users := middleware.ListSessionUsers()
+
+	c.JSON(http.StatusOK, gin.H{
+		"users":     users,
+		"count":     len(users),
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// GetUserSessionsHandler lists the active access tokens of a user
+func GetUserSessionsHandler(c *gin.Context) {
+	userID := c.Param("id")
+	sessions := middleware.ListSessions(userID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":  userID,
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// RevokeUserSessionsHandler force-logs-out a user by revoking all their
+// access tokens and refresh token families
+func RevokeUserSessionsHandler(c *gin.Context) {
+	userID := c.Param("id")
+
+	// Refresh tokens go first so a blacklist failure cannot leave them usable
+	families, err := auth.RevokeUserTokens(userID)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "REVOCATION_FAILED", "Failed to revoke refresh tokens: "+err.Error())
+		return
+	}
+	revoked, err := middleware.RevokeUserSessions(userID)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "REVOCATION_FAILED", "Failed to revoke sessions: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":                  "All sessions for " + userID + " have been revoked",
+		"revoked_access_tokens":    len(revoked),
+		"revoked_refresh_families": families,
+	})
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/slo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSLOStatusHandler reports every service level objective with its
+// remaining error budget, burn rates and active alerts
+func GetSLOStatusHandler(c *gin.Context) {
+	statuses := slo.Statuses()
+	c.JSON(http.StatusOK, gin.H{
+		"objectives": statuses,
+		"count":      len(statuses),
+		"timestamp":  time.Now().Unix(),
+	})
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/events"
+	"InternalAPI/internal/middleware"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/stream"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamHandlers serves domain events as server-sent events and over
+// WebSockets
+type StreamHandlers struct {
+	heartbeat time.Duration
+	origins   []string
+}
+
+// NewStreamHandlers creates a new stream handlers instance
+func NewStreamHandlers(config *config.Config) *StreamHandlers {
+	heartbeat := config.StreamHeartbeat
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &StreamHandlers{heartbeat: heartbeat, origins: splitList(config.AllowedOrigins)}
+}
+
+// StreamEvents pushes bus events to the client as server-sent events.
+// ?types= limits the stream to a comma-separated list of event types, where
+// "prefix.*" matches a family. A comment line is sent every heartbeat so
+// proxies keep the connection open; slow clients are evicted by policy.
+func (sh *StreamHandlers) StreamEvents(c *gin.Context) {
+	sh.serveSSE(c, stream.TransportSSE, nil)
+}
+
+// AdminEvents pushes admin bus events to dashboards as server-sent events:
+// audit entries (audit.recorded), circuit breaker transitions
+// (circuit_breaker.state_changed) and upstream health changes
+// (health.changed). Besides ?types=, audit entries can be narrowed by
+// user_id, resource_type, action and min_status, and breaker and health
+// events by a comma-separated service list.
+func (sh *StreamHandlers) AdminEvents(c *gin.Context) {
+	var minStatus int
+	if raw := c.Query("min_status"); raw != "" {
+		status, err := strconv.Atoi(raw)
+		if err != nil || status < 100 || status > 599 {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "min_status must be an HTTP status code")
+			return
+		}
+		minStatus = status
+	}
+
+	userID, resourceType, action := c.Query("user_id"), c.Query("resource_type"), c.Query("action")
+	services := splitList(c.Query("service"))
+
+	sh.serveSSE(c, stream.TransportAdminSSE, func(event events.Event) bool {
+		if event.Type == "audit.recorded" {
+			status, _ := event.Data["status"].(float64)
+			return (userID == "" || event.Data["user_id"] == userID) &&
+				(resourceType == "" || strings.EqualFold(fmt.Sprint(event.Data["resource_type"]), resourceType)) &&
+				(action == "" || event.Data["action"] == action) &&
+				int(status) >= minStatus
+		}
+		if len(services) == 0 {
+			return true
+		}
+		for _, service := range services {
+			if event.Data["service"] == service {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// serveSSE streams the events of a new hub connection until the client
+// leaves or the connection is evicted
+func (sh *StreamHandlers) serveSSE(c *gin.Context, transport string, filter func(events.Event) bool) {
+	touch, err := middleware.OpenStream(c, 2*sh.heartbeat)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "STREAM_NOT_SUPPORTED", "Streaming is not supported on this connection")
+		return
+	}
+
+	conn := stream.Open(transport, c.GetString("userID"), c.ClientIP(), splitList(c.Query("types")), filter)
+	defer conn.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Header("X-Connection-ID", conn.ID)
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sh.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event := <-conn.Events():
+			event.Data = middleware.MaskData(event.Data)
+			data, err := json.Marshal(event)
+			if err != nil {
+				conn.Delivered(event)
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+			c.Writer.Flush()
+			touch()
+			conn.Delivered(event)
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+			touch()
+		case <-conn.Done():
+			// Tell the client why, so it can back off before reconnecting
+			fmt.Fprintf(c.Writer, "event: evicted\ndata: {\"reason\":%q}\n\n", conn.Reason())
+			c.Writer.Flush()
+			return
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// ListConnectionsHandler lists live event stream connections with their
+// queue depth, dropped events and delivery lag
+func ListConnectionsHandler(c *gin.Context) {
+	connections := stream.List()
+
+	c.JSON(http.StatusOK, gin.H{
+		"connections": connections,
+		"count":       len(connections),
+		"policy":      streamPolicyResponse(stream.CurrentPolicy()),
+		"timestamp":   time.Now().Unix(),
+	})
+}
+
+// DisconnectConnectionHandler closes one live event stream connection
+func DisconnectConnectionHandler(c *gin.Context) {
+	id := c.Param("id")
+	if !stream.Disconnect(id) {
+		sendError(c, http.StatusNotFound, "CONNECTION_NOT_FOUND", "No live connection with this ID")
+		return
+	}
+	recordAuditEvent(c, "stream_connection_closed", "stream_connection", id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Connection " + id + " has been closed",
+	})
+}
+
+// SetStreamPolicyHandler updates the slow-consumer eviction policy
+func SetStreamPolicyHandler(c *gin.Context) {
+	var req models.StreamPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	policy := stream.CurrentPolicy()
+	if req.QueueSize != nil {
+		policy.QueueSize = *req.QueueSize
+	}
+	if req.MaxDropped != nil {
+		policy.MaxDropped = *req.MaxDropped
+	}
+	if req.MaxLagSeconds != nil {
+		policy.MaxLag = time.Duration(*req.MaxLagSeconds) * time.Second
+	}
+	stream.SetPolicy(policy)
+	recordAuditEvent(c, "stream_policy_changed", "stream_policy", "global")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stream eviction policy updated",
+		"policy":  streamPolicyResponse(stream.CurrentPolicy()),
+	})
+}
+
+// streamPolicyResponse renders the eviction policy for admin responses
+func streamPolicyResponse(policy stream.Policy) gin.H {
+	return gin.H{
+		"queue_size":      policy.QueueSize,
+		"max_dropped":     policy.MaxDropped,
+		"max_lag_seconds": int(policy.MaxLag.Seconds()),
+	}
+}
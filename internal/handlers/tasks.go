@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/housekeeping"
+	"InternalAPI/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// supervisorRoles see every task and may create tasks by hand; workers only
+// see tasks that are pending or assigned to them
+var supervisorRoles = []string{"housekeeping", "front_desk", "admin", "super_admin", "service"}
+
+// ListTasksHandler lists housekeeping tasks (?status=, ?room_id=, ?assigned_to=).
+// Workers see the pending queue and their own tasks.
+func ListTasksHandler(c *gin.Context) {
+	filter := housekeeping.Filter{
+		Status:     c.Query("status"),
+		RoomID:     c.Query("room_id"),
+		AssignedTo: c.Query("assigned_to"),
+	}
+
+	tasks := housekeeping.List(filter)
+	if !hasAnyRole(c, supervisorRoles...) {
+		userID := c.GetString("userID")
+		visible := []housekeeping.Task{}
+		for _, task := range tasks {
+			if task.Status == housekeeping.StatusPending || task.AssignedTo == userID {
+				visible = append(visible, task)
+			}
+		}
+		tasks = visible
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tasks":     tasks,
+		"count":     len(tasks),
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// GetTaskStatsHandler returns the number of tasks per status
+func GetTaskStatsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"counts":    housekeeping.Counts(),
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// CreateTaskHandler queues a cleaning task by hand, e.g. a deep clean
+func CreateTaskHandler(c *gin.Context) {
+	var req models.HousekeepingTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	task := housekeeping.Create(req.RoomID, req.Notes, req.Priority, c.GetString("userID"))
+	c.JSON(http.StatusCreated, task)
+}
+
+// ClaimNextTaskHandler assigns the next pending task to the calling worker
+func ClaimNextTaskHandler(c *gin.Context) {
+	task, err := housekeeping.ClaimNext(c.GetString("userID"))
+	if err != nil {
+		sendTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// ClaimTaskHandler assigns a specific pending task to the calling worker
+func ClaimTaskHandler(c *gin.Context) {
+	task, err := housekeeping.Claim(c.Param("id"), c.GetString("userID"))
+	if err != nil {
+		sendTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// CompleteTaskHandler marks a task the calling worker claimed as done
+func CompleteTaskHandler(c *gin.Context) {
+	var req models.CompleteTaskRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+
+	task, err := housekeeping.Complete(c.Param("id"), c.GetString("userID"), req.Notes)
+	if err != nil {
+		sendTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// ReportTaskHandler closes a claimed task with an issue for a supervisor
+func ReportTaskHandler(c *gin.Context) {
+	var req models.ReportTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	task, err := housekeeping.Report(c.Param("id"), c.GetString("userID"), req.Issue)
+	if err != nil {
+		sendTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// sendTaskError maps housekeeping task errors to API errors
+func sendTaskError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, housekeeping.ErrTaskNotFound):
+		sendError(c, http.StatusNotFound, "TASK_NOT_FOUND", err.Error())
+	case errors.Is(err, housekeeping.ErrNoPendingTasks):
+		sendError(c, http.StatusNotFound, "NO_PENDING_TASKS", err.Error())
+	case errors.Is(err, housekeeping.ErrTaskNotClaimable):
+		sendError(c, http.StatusConflict, "TASK_NOT_CLAIMABLE", err.Error())
+	default:
+		sendError(c, http.StatusConflict, "TASK_NOT_ASSIGNED", err.Error())
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/usage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// usagePeriod reads the period and date query parameters, defaulting to the
+// current month. It writes the error response when they are invalid.
+func usagePeriod(c *gin.Context) (string, time.Time, bool) {
+	period := c.DefaultQuery("period", usage.PeriodMonth)
+	if period != usage.PeriodWeek && period != usage.PeriodMonth {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "period must be week or month")
+		return "", time.Time{}, false
+	}
+
+	date := time.Now().UTC()
+	if value := c.Query("date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "date must be in YYYY-MM-DD format")
+			return "", time.Time{}, false
+		}
+		date = parsed
+	}
+	return period, date, true
+}
+
+// GetUsageReportsHandler returns per-consumer usage for a week or month,
+// optionally limited to one consumer with ?consumer=
+func GetUsageReportsHandler(c *gin.Context) {
+	period, date, ok := usagePeriod(c)
+	if !ok {
+		return
+	}
+
+	reports, err := usage.Reports(period, date, c.Query("consumer"))
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"period":        period,
+		"reports":       reports,
+		"count":         len(reports),
+		"aggregated_at": usage.LastAggregated(),
+	})
+}
+
+// GetMyUsageHandler returns the caller's own usage report, for users and API key integrators alike
+func GetMyUsageHandler(c *gin.Context) {
+	period, date, ok := usagePeriod(c)
+	if !ok {
+		return
+	}
+
+	consumerType := usage.ConsumerUser
+	if _, isService := c.Get("api_key"); isService {
+		consumerType = usage.ConsumerAPIKey
+	}
+
+	report, err := usage.ConsumerReport(period, date, c.GetString("userID"), consumerType)
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"report":        report,
+		"aggregated_at": usage.LastAggregated(),
+	})
+}
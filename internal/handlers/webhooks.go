@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWebhooksHandler lists outbound webhook subscriptions. Signing secrets
+// are only shown when a subscription is created.
+func GetWebhooksHandler(c *gin.Context) {
+	subscriptions := webhooks.List()
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhooks":   subscriptions,
+		"count":      len(subscriptions),
+		"deliveries": webhooks.Stats(),
+		"timestamp":  time.Now().Unix(),
+	})
+}
+
+// GetWebhookHandler returns a single outbound webhook subscription
+func GetWebhookHandler(c *gin.Context) {
+	subscription, exists := webhooks.Get(c.Param("id"))
+	if !exists {
+		sendError(c, http.StatusNotFound, "WEBHOOK_NOT_FOUND", "Webhook not found")
+		return
+	}
+	subscription.Secret = ""
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// CreateWebhookHandler registers an outbound webhook. The response carries
+// the signing secret, generated unless one was given; it is not shown again.
+func CreateWebhookHandler(c *gin.Context) {
+	var req models.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	subscription, err := webhooks.Create(webhooks.Subscription{
+		URL:         req.URL,
+		Events:      req.Events,
+		Secret:      req.Secret,
+		Description: req.Description,
+		Active:      req.Active == nil || *req.Active,
+		CreatedBy:   c.GetString("userID"),
+	})
+	if errors.Is(err, webhooks.ErrInvalidWebhook) {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "WEBHOOK_STORE_FAILED", "Webhook records could not be written, please retry")
+		return
+	}
+	recordAuditEvent(c, "webhook_created", "webhook", subscription.ID)
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// UpdateWebhookHandler changes an outbound webhook. The secret is rotated
+// only when a new one is given.
+func UpdateWebhookHandler(c *gin.Context) {
+	var req models.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	subscription, err := webhooks.Update(c.Param("id"), webhooks.Subscription{
+		URL:         req.URL,
+		Events:      req.Events,
+		Secret:      req.Secret,
+		Description: req.Description,
+		Active:      req.Active == nil || *req.Active,
+	})
+	if errors.Is(err, webhooks.ErrWebhookNotFound) {
+		sendError(c, http.StatusNotFound, "WEBHOOK_NOT_FOUND", "Webhook not found")
+		return
+	}
+	if errors.Is(err, webhooks.ErrInvalidWebhook) {
+		sendError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "WEBHOOK_STORE_FAILED", "Webhook records could not be written, please retry")
+		return
+	}
+	recordAuditEvent(c, "webhook_updated", "webhook", subscription.ID)
+	subscription.Secret = ""
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteWebhookHandler removes an outbound webhook and its pending
+// deliveries. Its dead letters are kept for inspection.
+func DeleteWebhookHandler(c *gin.Context) {
+	id := c.Param("id")
+	if err := webhooks.Delete(id); err != nil {
+		if errors.Is(err, webhooks.ErrWebhookNotFound) {
+			sendError(c, http.StatusNotFound, "WEBHOOK_NOT_FOUND", "Webhook not found")
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "WEBHOOK_STORE_FAILED", "Webhook records could not be written, please retry")
+		return
+	}
+	recordAuditEvent(c, "webhook_deleted", "webhook", id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhook has been deleted",
+	})
+}
+
+// GetDeadLettersHandler lists webhook deliveries that exhausted their
+// attempts, optionally for one ?webhook_id=
+func GetDeadLettersHandler(c *gin.Context) {
+	deadLetters := webhooks.DeadLetters(c.Query("webhook_id"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"dead_letters": deadLetters,
+		"count":        len(deadLetters),
+		"timestamp":    time.Now().Unix(),
+	})
+}
+
+// RetryDeadLetterHandler queues a dead letter for a fresh round of attempts
+// to its webhook's current URL
+func RetryDeadLetterHandler(c *gin.Context) {
+	delivery, err := webhooks.Retry(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, webhooks.ErrWebhookNotFound) {
+			sendError(c, http.StatusNotFound, "WEBHOOK_NOT_FOUND", "The webhook of this delivery has been deleted")
+			return
+		}
+		if errors.Is(err, webhooks.ErrDeadLetterNotFound) {
+			sendError(c, http.StatusNotFound, "DEAD_LETTER_NOT_FOUND", "Dead letter not found")
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "WEBHOOK_STORE_FAILED", "Webhook records could not be written, please retry")
+		return
+	}
+	recordAuditEvent(c, "webhook_delivery_retried", "webhook_delivery", delivery.ID)
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// DiscardDeadLetterHandler deletes a dead letter
+func DiscardDeadLetterHandler(c *gin.Context) {
+	id := c.Param("id")
+	if err := webhooks.Discard(id); err != nil {
+		if errors.Is(err, webhooks.ErrDeadLetterNotFound) {
+			sendError(c, http.StatusNotFound, "DEAD_LETTER_NOT_FOUND", "Dead letter not found")
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "WEBHOOK_STORE_FAILED", "Webhook records could not be written, please retry")
+		return
+	}
+	recordAuditEvent(c, "webhook_delivery_discarded", "webhook_delivery", id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Dead letter has been discarded",
+	})
+}
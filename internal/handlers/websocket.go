@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/events"
+	"InternalAPI/internal/middleware"
+	"InternalAPI/internal/stream"
+	"InternalAPI/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+)
+
+// wsReadLimit caps the size of subscription messages sent by clients
+const wsReadLimit = 4096
+
+// wsStaffRoles receive every bus event over the WebSocket
+var wsStaffRoles = []string{"front_desk", "housekeeping", "admin", "super_admin"}
+
+// wsClientMessage changes the subscription of a WebSocket connection
+type wsClientMessage struct {
+	Action string   `json:"action"` // "subscribe"
+	Types  []string `json:"types"`  // Replaces the subscribed types; empty means every event
+}
+
+// wsServerMessage is a message pushed to a WebSocket client
+type wsServerMessage struct {
+	Type         string        `json:"type"` // connected, subscribed, event or error
+	ConnectionID string        `json:"connection_id,omitempty"`
+	Types        []string      `json:"types,omitempty"`
+	Event        *events.Event `json:"event,omitempty"`
+	Code         string        `json:"code,omitempty"`
+	Message      string        `json:"message,omitempty"`
+}
+
+// WebSocket upgrades an authenticated session to a WebSocket that pushes
+// bus events, such as room status changes and new bookings, to staff, and
+// the events addressed to them, such as front-desk replies, to guests. It
+// shares the event stream's per-connection queue and eviction policy;
+// clients change their subscription by sending
+// {"action":"subscribe","types":[...]}. The server pings every heartbeat
+// and drops clients that stop answering.
+func (sh *StreamHandlers) WebSocket(c *gin.Context) {
+	if !sh.originAllowed(c.Request) {
+		sendError(c, http.StatusForbidden, "ORIGIN_NOT_ALLOWED", "Origin "+c.GetHeader("Origin")+" may not open WebSocket connections")
+		return
+	}
+
+	ws, err := websocket.Upgrade(c.Writer, c.Request)
+	if err != nil {
+		if errors.Is(err, websocket.ErrBadHandshake) {
+			sendError(c, http.StatusBadRequest, "WEBSOCKET_UPGRADE_REQUIRED", err.Error())
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "STREAM_NOT_SUPPORTED", "Streaming is not supported on this connection")
+		return
+	}
+	defer ws.Close()
+
+	// Guests only receive the events addressed to them, such as replies
+	var filter func(events.Event) bool
+	if userID := c.GetString("userID"); !hasAnyRole(c, wsStaffRoles...) {
+		filter = func(event events.Event) bool {
+			return event.Data[events.RecipientField] == userID
+		}
+	}
+	conn := stream.Open(stream.TransportWebSocket, c.GetString("userID"), c.ClientIP(), splitList(c.Query("types")), filter)
+	defer conn.Close()
+
+	// A client that answers neither pings nor sends anything for two
+	// heartbeats is gone; its reads time out and end the connection
+	idle := 2 * sh.heartbeat
+	ws.SetReadLimit(wsReadLimit)
+	ws.SetReadDeadline(time.Now().Add(idle))
+	ws.SetPongHandler(func() {
+		ws.SetReadDeadline(time.Now().Add(idle))
+	})
+
+	if err := sh.sendWS(ws, wsServerMessage{Type: "connected", ConnectionID: conn.ID, Types: conn.Types()}); err != nil {
+		return
+	}
+
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- sh.readSubscriptions(ws, conn, idle)
+	}()
+
+	heartbeat := time.NewTicker(sh.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event := <-conn.Events():
+			event.Data = middleware.MaskData(event.Data)
+			// A client that cannot take the event within a heartbeat is
+			// stalled; dropping it lets its queue be reclaimed
+			if err := sh.sendWS(ws, wsServerMessage{Type: "event", Event: &event}); err != nil {
+				conn.Delivered(event)
+				return
+			}
+			conn.Delivered(event)
+		case <-heartbeat.C:
+			if err := ws.WriteMessage(websocket.PingMessage, nil, time.Now().Add(sh.heartbeat)); err != nil {
+				return
+			}
+		case <-conn.Done():
+			// Tell the client why, so it can back off before reconnecting
+			ws.WriteClose(websocket.CloseTryAgainLater, conn.Reason(), time.Now().Add(time.Second))
+			return
+		case err := <-readErr:
+			var closeErr *websocket.CloseError
+			if !errors.As(err, &closeErr) {
+				ws.WriteClose(websocket.CloseGoingAway, "heartbeat timeout", time.Now().Add(time.Second))
+			}
+			return
+		}
+	}
+}
+
+// readSubscriptions applies subscription messages until the client closes
+// the connection or stops responding
+func (sh *StreamHandlers) readSubscriptions(ws *websocket.Conn, conn *stream.Conn, idle time.Duration) error {
+	for {
+		messageType, data, err := ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		ws.SetReadDeadline(time.Now().Add(idle))
+
+		var msg wsClientMessage
+		if messageType != websocket.TextMessage || json.Unmarshal(data, &msg) != nil || msg.Action != "subscribe" {
+			sh.sendWS(ws, wsServerMessage{Type: "error", Code: "INVALID_MESSAGE", Message: `Expected {"action":"subscribe","types":[...]}`})
+			continue
+		}
+
+		var types []string
+		for _, t := range msg.Types {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+		conn.SetTypes(types)
+		if err := sh.sendWS(ws, wsServerMessage{Type: "subscribed", Types: types}); err != nil {
+			return err
+		}
+	}
+}
+
+// sendWS writes a JSON message, giving up after one heartbeat
+func (sh *StreamHandlers) sendWS(ws *websocket.Conn, msg wsServerMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(websocket.TextMessage, data, time.Now().Add(sh.heartbeat))
+}
+
+// originAllowed guards against cross-site WebSocket hijacking: browsers
+// send cookies with the handshake from any page, so only the CORS origins
+// and the gateway's own host may connect. Non-browser clients send no
+// Origin and are authenticated by their token alone.
+func (sh *StreamHandlers) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if middleware.MatchOrigin(sh.origins, origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
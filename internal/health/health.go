@@ -0,0 +1,244 @@
+// Package health probes the gateway's dependencies in the background and
+// caches the results, so health and readiness checks answer instantly
+// without calling the backends themselves.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logging.Logger()
+
+// Check probes one dependency; a nil error means it is up
+type Check func(ctx context.Context) error
+
+// Status is the cached result of a dependency's probes
+type Status struct {
+	Name                string     `json:"name"`
+	Status              string     `json:"status"` // up, down or unknown
+	LastCheck           *time.Time `json:"last_check,omitempty"`
+	LatencyMs           int64      `json:"latency_ms"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Since               *time.Time `json:"since,omitempty"` // When Status last changed
+}
+
+// Settings tunes the prober
+type Settings struct {
+	Interval         time.Duration // Time between probe rounds
+	Timeout          time.Duration // Per probe
+	FailureThreshold int           // Failed probes in a row before a dependency is down
+}
+
+var (
+	dependencyUp = metrics.Factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "dependency_up",
+		Help:      "Whether the latest probes found a dependency up (1) or down (0)",
+	}, []string{"dependency"})
+
+	dependencyCheckDuration = metrics.Factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "dependency_check_duration_seconds",
+		Help:      "Duration of the latest probe of a dependency",
+	}, []string{"dependency"})
+
+	dependencyChecks = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "dependency_checks_total",
+		Help:      "Dependency probes by result (ok, failed)",
+	}, []string{"dependency", "result"})
+)
+
+var (
+	mu       sync.RWMutex
+	checks   = make(map[string]Check)
+	statuses = make(map[string]*Status)
+	settings = Settings{Interval: 15 * time.Second, Timeout: 3 * time.Second, FailureThreshold: 2}
+	stop     chan struct{}
+)
+
+// Register adds a dependency to probe. Its status is unknown until the
+// first probe.
+func Register(name string, check Check) {
+	mu.Lock()
+	defer mu.Unlock()
+	checks[name] = check
+	statuses[name] = &Status{Name: name, Status: "unknown"}
+}
+
+// Start probes every registered dependency now and then every interval,
+// until Stop is called
+func Start(s Settings) {
+	if s.Interval <= 0 {
+		s.Interval = 15 * time.Second
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = 3 * time.Second
+	}
+	if s.FailureThreshold <= 0 {
+		s.FailureThreshold = 1
+	}
+
+	mu.Lock()
+	settings = s
+	done := make(chan struct{})
+	stop = done
+	mu.Unlock()
+
+	go func() {
+		probeAll()
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				probeAll()
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends background probing. Cached results are kept.
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+	if stop != nil {
+		close(stop)
+		stop = nil
+	}
+}
+
+// Statuses returns the cached status of every dependency, sorted by name
+func Statuses() []Status {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]Status, 0, len(statuses))
+	for _, status := range statuses {
+		list = append(list, *status)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Get returns the cached status of a dependency
+func Get(name string) (Status, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	status, ok := statuses[name]
+	if !ok {
+		return Status{}, false
+	}
+	return *status, true
+}
+
+// ParsePaths parses a spec like "api-beheerder=/health,central-mgmt=/ping"
+// into the probe path of each dependency
+func ParsePaths(spec string) (map[string]string, error) {
+	paths := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, path, ok := strings.Cut(entry, "=")
+		name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+		if !ok || name == "" || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("entry %q must be service=/path", entry)
+		}
+		paths[name] = path
+	}
+	return paths, nil
+}
+
+// probeAll probes every dependency concurrently and waits for the results
+func probeAll() {
+	mu.RLock()
+	pending := make(map[string]Check, len(checks))
+	for name, check := range checks {
+		pending[name] = check
+	}
+	timeout := settings.Timeout
+	mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for name, check := range pending {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			started := time.Now()
+			err := check(ctx)
+			record(name, err, time.Since(started))
+		}()
+	}
+	wg.Wait()
+}
+
+// record caches the result of one probe. A dependency goes down only after
+// FailureThreshold failed probes in a row, so a single lost probe does not
+// flip readiness.
+func record(name string, err error, duration time.Duration) {
+	now := time.Now()
+	mu.Lock()
+	status, ok := statuses[name]
+	if !ok {
+		mu.Unlock()
+		return
+	}
+	from := status.Status
+	status.LastCheck = &now
+	status.LatencyMs = duration.Milliseconds()
+	if err == nil {
+		status.Status = "up"
+		status.ConsecutiveFailures = 0
+		status.LastError = ""
+	} else {
+		status.ConsecutiveFailures++
+		status.LastError = err.Error()
+		if status.ConsecutiveFailures >= settings.FailureThreshold {
+			status.Status = "down"
+		}
+	}
+	to, lastError := status.Status, status.LastError
+	if from != to {
+		status.Since = &now
+	}
+	mu.Unlock()
+
+	dependencyCheckDuration.WithLabelValues(name).Set(duration.Seconds())
+	if err == nil {
+		dependencyChecks.WithLabelValues(name, "ok").Inc()
+	} else {
+		dependencyChecks.WithLabelValues(name, "failed").Inc()
+	}
+	switch to {
+	case "up":
+		dependencyUp.WithLabelValues(name).Set(1)
+	case "down":
+		dependencyUp.WithLabelValues(name).Set(0)
+	}
+
+	if from != to {
+		entry := log.WithFields(logrus.Fields{"dependency": name, "from": from, "to": to})
+		if to == "down" {
+			entry.WithField("error", lastError).Warn("Dependency is down")
+		} else {
+			entry.Info("Dependency health changed")
+		}
+	}
+}
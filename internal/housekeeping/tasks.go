@@ -0,0 +1,341 @@
+package housekeeping
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/events"
+	"InternalAPI/internal/metrics"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Task statuses. Workers claim pending tasks and either complete them or
+// report an issue that needs a supervisor.
+const (
+	StatusPending   = "pending"
+	StatusClaimed   = "claimed"
+	StatusCompleted = "completed"
+	StatusIssue     = "issue"
+)
+
+// Task sources
+const (
+	SourceCheckout = "checkout"
+	SourceManual   = "manual"
+)
+
+var (
+	// ErrTaskNotFound is returned for unknown task IDs
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrNoPendingTasks is returned when a worker asks for the next task and none is waiting
+	ErrNoPendingTasks = errors.New("no pending tasks")
+	// ErrTaskNotClaimable is returned when claiming a task that is not pending
+	ErrTaskNotClaimable = errors.New("task is not pending")
+	// ErrTaskNotAssigned is returned when a worker finishes a task they have not claimed
+	ErrTaskNotAssigned = errors.New("task is not claimed by this worker")
+)
+
+var taskCount = metrics.Factory.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: metrics.Namespace,
+	Name:      "housekeeping_tasks",
+	Help:      "Housekeeping tasks by status",
+}, []string{"status"})
+
+var taskTransitions = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "housekeeping_task_transitions_total",
+	Help:      "Housekeeping task status changes by new status",
+}, []string{"status"})
+
+// Task is a cleaning job for one room
+type Task struct {
+	ID          string     `json:"id"`
+	RoomID      string     `json:"room_id"`
+	BookingID   string     `json:"booking_id,omitempty"`
+	Source      string     `json:"source"`
+	Priority    int        `json:"priority"` // Higher is claimed first
+	Status      string     `json:"status"`
+	Notes       string     `json:"notes,omitempty"`
+	Issue       string     `json:"issue,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	AssignedTo  string     `json:"assigned_to,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ClaimedAt   *time.Time `json:"claimed_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	DurationSec int64      `json:"duration_seconds,omitempty"` // Claim to finish
+}
+
+// Filter selects tasks; zero values match everything
+type Filter struct {
+	Status     string
+	RoomID     string
+	AssignedTo string
+}
+
+var (
+	tasks  = make(map[string]*Task)
+	taskMu sync.RWMutex
+)
+
+// Init generates a cleaning task for every room a guest checks out of and
+// starts the sweeper that drops finished tasks after retention
+func Init(retention time.Duration) {
+	events.Subscribe("reservation.checked_out", handleCheckout)
+	if retention > 0 {
+		go sweep(retention)
+	}
+}
+
+// handleCheckout creates the cleaning task for a checkout event
+func handleCheckout(event events.Event) error {
+	roomID, _ := event.Data["room_id"].(string)
+	if roomID == "" {
+		return fmt.Errorf("checkout event %s has no room_id", event.ID)
+	}
+	bookingID, _ := event.Data["booking_id"].(string)
+	if bookingID == "" {
+		bookingID, _ = event.Data["id"].(string)
+	}
+
+	Generate(roomID, bookingID)
+	return nil
+}
+
+// Generate creates a checkout cleaning task for a room unless the room
+// already has an open task. It returns the room's open task and whether it
+// was created.
+func Generate(roomID, bookingID string) (*Task, bool) {
+	return store(Task{RoomID: roomID, BookingID: bookingID, Source: SourceCheckout}, true)
+}
+
+// Create adds a manual task, e.g. a deep clean requested by a supervisor
+func Create(roomID, notes string, priority int, createdBy string) *Task {
+	task, _ := store(Task{RoomID: roomID, Source: SourceManual, Notes: notes, Priority: priority, CreatedBy: createdBy}, false)
+	return task
+}
+
+// store saves a new pending task and announces it. With onePerRoom, an
+// existing open task for the room is returned instead.
+func store(task Task, onePerRoom bool) (*Task, bool) {
+	taskMu.Lock()
+	if onePerRoom {
+		for _, existing := range tasks {
+			if existing.RoomID == task.RoomID && (existing.Status == StatusPending || existing.Status == StatusClaimed) {
+				copied := *existing
+				taskMu.Unlock()
+				return &copied, false
+			}
+		}
+	}
+
+	task.ID = uuid.New().String()
+	task.Status = StatusPending
+	task.CreatedAt = time.Now()
+	tasks[task.ID] = &task
+	copied := task
+	taskMu.Unlock()
+
+	taskCount.WithLabelValues(StatusPending).Inc()
+	taskTransitions.WithLabelValues(StatusPending).Inc()
+	publish("housekeeping.task_created", copied)
+	return &copied, true
+}
+
+// Claim assigns a pending task to a worker
+func Claim(taskID, workerID string) (*Task, error) {
+	taskMu.Lock()
+	defer taskMu.Unlock()
+
+	task, exists := tasks[taskID]
+	if !exists {
+		return nil, ErrTaskNotFound
+	}
+	if task.Status != StatusPending {
+		return nil, ErrTaskNotClaimable
+	}
+	return claim(task, workerID), nil
+}
+
+// ClaimNext assigns the highest-priority, oldest pending task to a worker
+func ClaimNext(workerID string) (*Task, error) {
+	taskMu.Lock()
+	defer taskMu.Unlock()
+
+	var next *Task
+	for _, task := range tasks {
+		if task.Status != StatusPending {
+			continue
+		}
+		if next == nil || task.Priority > next.Priority ||
+			(task.Priority == next.Priority && task.CreatedAt.Before(next.CreatedAt)) {
+			next = task
+		}
+	}
+	if next == nil {
+		return nil, ErrNoPendingTasks
+	}
+	return claim(next, workerID), nil
+}
+
+// claim marks a task claimed. Callers must hold taskMu.
+func claim(task *Task, workerID string) *Task {
+	now := time.Now()
+	task.Status = StatusClaimed
+	task.AssignedTo = workerID
+	task.ClaimedAt = &now
+	recordTransition(StatusPending, StatusClaimed)
+
+	copied := *task
+	return &copied
+}
+
+// Complete finishes a task the worker has claimed
+func Complete(taskID, workerID, notes string) (*Task, error) {
+	task, err := finish(taskID, workerID, StatusCompleted, func(t *Task) {
+		if notes != "" {
+			t.Notes = notes
+		}
+	})
+	if err == nil {
+		publish("housekeeping.task_completed", *task)
+	}
+	return task, err
+}
+
+// Report closes a claimed task with an issue, such as damage or a room that
+// cannot be cleaned, for a supervisor to follow up
+func Report(taskID, workerID, issue string) (*Task, error) {
+	task, err := finish(taskID, workerID, StatusIssue, func(t *Task) {
+		t.Issue = issue
+	})
+	if err == nil {
+		publish("housekeeping.issue_reported", *task)
+	}
+	return task, err
+}
+
+// finish moves a claimed task to a final status
+func finish(taskID, workerID, status string, update func(*Task)) (*Task, error) {
+	taskMu.Lock()
+	defer taskMu.Unlock()
+
+	task, exists := tasks[taskID]
+	if !exists {
+		return nil, ErrTaskNotFound
+	}
+	if task.Status != StatusClaimed || task.AssignedTo != workerID {
+		return nil, ErrTaskNotAssigned
+	}
+
+	now := time.Now()
+	update(task)
+	task.Status = status
+	task.FinishedAt = &now
+	if task.ClaimedAt != nil {
+		task.DurationSec = int64(now.Sub(*task.ClaimedAt).Seconds())
+	}
+	recordTransition(StatusClaimed, status)
+
+	copied := *task
+	return &copied, nil
+}
+
+// Get returns a task by ID
+func Get(taskID string) (*Task, bool) {
+	taskMu.RLock()
+	defer taskMu.RUnlock()
+
+	task, exists := tasks[taskID]
+	if !exists {
+		return nil, false
+	}
+	copied := *task
+	return &copied, true
+}
+
+// List returns matching tasks, highest priority first, then oldest first
+func List(filter Filter) []Task {
+	taskMu.RLock()
+	result := []Task{}
+	for _, task := range tasks {
+		if filter.Status != "" && task.Status != filter.Status {
+			continue
+		}
+		if filter.RoomID != "" && task.RoomID != filter.RoomID {
+			continue
+		}
+		if filter.AssignedTo != "" && task.AssignedTo != filter.AssignedTo {
+			continue
+		}
+		result = append(result, *task)
+	}
+	taskMu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Priority != result[j].Priority {
+			return result[i].Priority > result[j].Priority
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// Counts returns the number of tasks per status
+func Counts() map[string]int {
+	taskMu.RLock()
+	defer taskMu.RUnlock()
+
+	counts := map[string]int{StatusPending: 0, StatusClaimed: 0, StatusCompleted: 0, StatusIssue: 0}
+	for _, task := range tasks {
+		counts[task.Status]++
+	}
+	return counts
+}
+
+// recordTransition updates the task metrics for a status change
+func recordTransition(from, to string) {
+	taskCount.WithLabelValues(from).Dec()
+	taskCount.WithLabelValues(to).Inc()
+	taskTransitions.WithLabelValues(to).Inc()
+}
+
+// publish announces a task change on the event bus
+func publish(eventType string, task Task) {
+	events.Publish(events.Event{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		Source:     "internal-api",
+		OccurredAt: time.Now(),
+		Data: map[string]interface{}{
+			"task_id":     task.ID,
+			"room_id":     task.RoomID,
+			"booking_id":  task.BookingID,
+			"status":      task.Status,
+			"assigned_to": task.AssignedTo,
+			"issue":       task.Issue,
+		},
+	})
+}
+
+// sweep drops finished tasks once they are older than retention
+func sweep(retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cutoff := time.Now().Add(-retention)
+		taskMu.Lock()
+		for id, task := range tasks {
+			if task.FinishedAt != nil && task.FinishedAt.Before(cutoff) {
+				taskCount.WithLabelValues(task.Status).Dec()
+				delete(tasks, id)
+			}
+		}
+		taskMu.Unlock()
+	}
+}
@@ -0,0 +1,347 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logging.Logger()
+
+// Job statuses
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+var (
+	// ErrQueueFull is returned by Submit when every queue slot is taken
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrStopped is returned by Submit during shutdown
+	ErrStopped = errors.New("job queue is shutting down")
+
+	errInterrupted = errors.New("job was interrupted by a restart before it finished")
+)
+
+var (
+	jobsFinished = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "jobs_total",
+		Help:      "Finished background jobs by status",
+	}, []string{"status"})
+
+	jobsQueued = metrics.Factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "jobs_queued",
+		Help:      "Background jobs waiting for a worker",
+	})
+
+	jobDuration = metrics.Factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Time background jobs spent running",
+		Buckets:   []float64{0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+	})
+)
+
+// Job is a request that runs in the background; clients poll it by ID
+type Job struct {
+	ID         string          `json:"id"`
+	Status     string          `json:"status"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	UserID     string          `json:"user_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	Deadline   *time.Time      `json:"deadline,omitempty"`
+	StatusCode int             `json:"status_code,omitempty"` // Status the request would have answered with
+	Result     json.RawMessage `json:"result,omitempty"`      // Response body the request would have returned
+	Error      string          `json:"error,omitempty"`
+}
+
+// Finished reports whether the job has succeeded or failed
+func (j Job) Finished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed
+}
+
+// fail marks the job failed without a result
+func (j *Job) fail(message string) {
+	now := time.Now()
+	j.Status = StatusFailed
+	j.Error = message
+	j.FinishedAt = &now
+}
+
+// Result is what a job function produced: the response it would have sent
+type Result struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Func does the work of a job. The context ends when the job times out.
+type Func func(ctx context.Context) (Result, error)
+
+// task pairs a queued job with its work
+type task struct {
+	job Job
+	fn  Func
+}
+
+// Queue runs submitted jobs on a fixed pool of workers. Jobs wait in a
+// bounded queue; when it is full Submit fails rather than piling up work
+// the workers cannot get to.
+type Queue struct {
+	store     Store
+	tasks     chan task
+	timeout   time.Duration
+	retention time.Duration
+
+	mu       sync.RWMutex
+	stopping bool
+	workers  sync.WaitGroup
+}
+
+// NewQueue creates a queue and starts its workers
+func NewQueue(store Store, workers, size int, timeout, retention time.Duration) *Queue {
+	if workers <= 0 {
+		workers = 1
+	}
+	if size <= 0 {
+		size = 100
+	}
+	q := &Queue{
+		store:     store,
+		tasks:     make(chan task, size),
+		timeout:   timeout,
+		retention: retention,
+	}
+	for i := 0; i < workers; i++ {
+		q.workers.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// Submit queues fn as a new job and returns it in the queued state
+func (q *Queue) Submit(ctx context.Context, method, path, userID string, fn Func) (Job, error) {
+	job := Job{
+		ID:        uuid.New().String(),
+		Status:    StatusQueued,
+		Method:    method,
+		Path:      path,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.stopping {
+		return Job{}, ErrStopped
+	}
+	if len(q.tasks) == cap(q.tasks) {
+		return Job{}, ErrQueueFull
+	}
+	if err := q.store.Save(ctx, job); err != nil {
+		return Job{}, fmt.Errorf("failed to store job: %w", err)
+	}
+
+	select {
+	case q.tasks <- task{job: job, fn: fn}:
+		jobsQueued.Inc()
+		return job, nil
+	default:
+		// Another submitter took the last slot after the check above
+		job.fail(ErrQueueFull.Error())
+		q.store.Save(ctx, job)
+		return Job{}, ErrQueueFull
+	}
+}
+
+// Get returns a job. A running job past its deadline is reported failed:
+// the replica running it has gone away without recording the outcome.
+func (q *Queue) Get(ctx context.Context, id string) (Job, bool, error) {
+	job, exists, err := q.store.Get(ctx, id)
+	if err != nil || !exists {
+		return job, exists, err
+	}
+	if job.Status == StatusRunning && job.Deadline != nil && time.Now().After(job.Deadline.Add(time.Minute)) {
+		job.fail(errInterrupted.Error())
+	}
+	return job, true, nil
+}
+
+// work runs queued jobs until the queue is closed
+func (q *Queue) work() {
+	defer q.workers.Done()
+	for t := range q.tasks {
+		jobsQueued.Dec()
+
+		q.mu.RLock()
+		stopping := q.stopping
+		q.mu.RUnlock()
+		if stopping {
+			t.job.fail(errInterrupted.Error())
+			q.save(t.job)
+			jobsFinished.WithLabelValues(StatusFailed).Inc()
+			continue
+		}
+		q.run(t)
+	}
+}
+
+// run executes one job and records its outcome
+func (q *Queue) run(t task) {
+	job := t.job
+	started := time.Now()
+	deadline := started.Add(q.timeout)
+	job.Status = StatusRunning
+	job.StartedAt = &started
+	job.Deadline = &deadline
+	q.save(job)
+
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	result, err := q.call(ctx, t.fn)
+	cancel()
+
+	finished := time.Now()
+	job.FinishedAt = &finished
+	job.StatusCode = result.StatusCode
+	if json.Valid(result.Body) {
+		job.Result = result.Body
+	}
+	switch {
+	case err != nil:
+		job.Status = StatusFailed
+		job.Error = err.Error()
+	case result.StatusCode >= 400:
+		job.Status = StatusFailed
+	default:
+		job.Status = StatusSucceeded
+	}
+	q.save(job)
+
+	jobsFinished.WithLabelValues(job.Status).Inc()
+	jobDuration.Observe(finished.Sub(started).Seconds())
+	log.WithFields(logrus.Fields{
+		"job_id":      job.ID,
+		"path":        job.Path,
+		"status":      job.Status,
+		"status_code": job.StatusCode,
+		"duration_ms": finished.Sub(started).Milliseconds(),
+	}).Info("Background job finished")
+}
+
+// call runs a job function, turning a panic into a failed job
+func (q *Queue) call(ctx context.Context, fn Func) (result Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// save writes a job, logging failures; the worker cannot do more about them
+func (q *Queue) save(job Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := q.store.Save(ctx, job); err != nil {
+		log.WithError(err).WithField("job_id", job.ID).Error("Failed to store job")
+	}
+}
+
+// prune removes expired jobs until the queue stops
+func (q *Queue) prune(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		q.mu.RLock()
+		stopping := q.stopping
+		q.mu.RUnlock()
+		if stopping {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		removed, err := q.store.Prune(ctx, time.Now().Add(-q.retention))
+		cancel()
+		if err != nil {
+			log.WithError(err).Warn("Failed to prune finished jobs")
+		} else if removed > 0 {
+			log.WithField("removed", removed).Info("Pruned finished jobs")
+		}
+	}
+}
+
+// Stop stops accepting jobs and waits until ctx ends for running jobs to
+// finish. Jobs still queued are marked failed rather than started.
+func (q *Queue) Stop(ctx context.Context) {
+	q.mu.Lock()
+	if q.stopping {
+		q.mu.Unlock()
+		return
+	}
+	q.stopping = true
+	close(q.tasks)
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Warn("Background jobs were still running at shutdown")
+	}
+}
+
+var queue *Queue
+
+// Init starts the global job queue
+func Init(store Store, workers, size int, timeout, retention time.Duration) {
+	queue = NewQueue(store, workers, size, timeout, retention)
+	go queue.prune(time.Hour)
+}
+
+// Enabled reports whether the global job queue has been started
+func Enabled() bool {
+	return queue != nil
+}
+
+// Submit queues fn on the global job queue
+func Submit(ctx context.Context, method, path, userID string, fn Func) (Job, error) {
+	if queue == nil {
+		return Job{}, ErrStopped
+	}
+	return queue.Submit(ctx, method, path, userID, fn)
+}
+
+// Get returns a job from the global job queue
+func Get(ctx context.Context, id string) (Job, bool, error) {
+	if queue == nil {
+		return Job{}, false, nil
+	}
+	return queue.Get(ctx, id)
+}
+
+// Stop shuts the global job queue down, if started
+func Stop(ctx context.Context) {
+	if queue != nil {
+		queue.Stop(ctx)
+	}
+}
@@ -0,0 +1,254 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store persists jobs so their results can be fetched after they finish
+type Store interface {
+	// Save creates or replaces a job
+	Save(ctx context.Context, job Job) error
+	// Get returns a job, or false when it does not exist or has expired
+	Get(ctx context.Context, id string) (Job, bool, error)
+	// Prune removes finished jobs that finished before cutoff
+	Prune(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// NewStore creates a job store for the configured backend
+func NewStore(backend, dir, redisURL string, retention time.Duration) (Store, error) {
+	switch backend {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "file":
+		return NewFileStore(dir)
+	case "redis":
+		return NewRedisStore(redisURL, retention)
+	default:
+		return nil, fmt.Errorf("unknown job store: %s", backend)
+	}
+}
+
+// MemoryStore keeps jobs in process memory; they are lost on restart
+type MemoryStore struct {
+	jobs map[string]Job
+	mu   sync.RWMutex
+}
+
+// NewMemoryStore creates an in-memory job store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]Job)}
+}
+
+// Save creates or replaces a job
+func (s *MemoryStore) Save(ctx context.Context, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// Get returns a job
+func (s *MemoryStore) Get(ctx context.Context, id string) (Job, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, exists := s.jobs[id]
+	return job, exists, nil
+}
+
+// Prune removes finished jobs that finished before cutoff
+func (s *MemoryStore) Prune(ctx context.Context, cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			delete(s.jobs, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// FileStore keeps one JSON file per job, so results survive a restart.
+// Jobs that were queued or running when the process stopped are marked
+// failed when the store is opened, since nothing will finish them.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore opens (or creates) a job directory
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
+	}
+	s := &FileStore{dir: dir}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		job, err := s.read(file)
+		if err != nil {
+			return nil, err
+		}
+		if !job.Finished() {
+			job.fail(errInterrupted.Error())
+			if err := s.write(job); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// Save creates or replaces a job
+func (s *FileStore) Save(ctx context.Context, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(job)
+}
+
+// Get returns a job
+func (s *FileStore) Get(ctx context.Context, id string) (Job, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.read(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Job{}, false, nil
+	}
+	if err != nil {
+		return Job{}, false, err
+	}
+	return job, true, nil
+}
+
+// Prune removes finished jobs that finished before cutoff
+func (s *FileStore) Prune(ctx context.Context, cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, file := range files {
+		job, err := s.read(file)
+		if err != nil || job.FinishedAt == nil || !job.FinishedAt.Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(file); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// read parses one job file
+func (s *FileStore) read(file string) (Job, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Job{}, err
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return Job{}, fmt.Errorf("failed to parse job %s: %w", file, err)
+	}
+	return job, nil
+}
+
+// write stores a job atomically so a crash never leaves a torn file
+func (s *FileStore) write(job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".job-*")
+	if err != nil {
+		return fmt.Errorf("failed to store job: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to store job: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to store job: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path(job.ID))
+}
+
+// path maps a job ID to its file; IDs are generated UUIDs
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+".json")
+}
+
+// RedisStore keeps jobs in Redis, so any replica can answer a status poll.
+// Every job expires after the retention period.
+type RedisStore struct {
+	client    *redis.Client
+	prefix    string
+	retention time.Duration
+}
+
+// NewRedisStore connects to Redis using a redis:// URL
+func NewRedisStore(redisURL string, retention time.Duration) (*RedisStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	return &RedisStore{client: client, prefix: "internal-api:jobs:", retention: retention}, nil
+}
+
+// Save creates or replaces a job
+func (s *RedisStore) Save(ctx context.Context, job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+job.ID, data, s.retention).Err()
+}
+
+// Get returns a job
+func (s *RedisStore) Get(ctx context.Context, id string) (Job, bool, error) {
+	data, err := s.client.Get(ctx, s.prefix+id).Bytes()
+	if err == redis.Nil {
+		return Job{}, false, nil
+	}
+	if err != nil {
+		return Job{}, false, err
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return Job{}, false, err
+	}
+	return job, true, nil
+}
+
+// Prune is a no-op because Redis expires jobs itself
+func (s *RedisStore) Prune(ctx context.Context, cutoff time.Time) (int, error) {
+	return 0, nil
+}
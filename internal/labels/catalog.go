@@ -0,0 +1,200 @@
+package labels
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Audiences a label can be worded for
+const (
+	AudienceGuest = "guest"
+	AudienceStaff = "staff"
+)
+
+// defaultTenant is the catalog every tenant override falls back to
+const defaultTenant = "default"
+
+// Entries maps enum -> value -> locale -> audience -> label
+type Entries map[string]map[string]map[string]map[string]string
+
+// Catalog holds enum labels per tenant. Tenants only need to define the
+// labels they want to change; everything else comes from the default catalog.
+type Catalog struct {
+	tenants       map[string]Entries
+	defaultLocale string
+	mu            sync.RWMutex
+}
+
+// NewCatalog creates an empty catalog falling back to defaultLocale
+func NewCatalog(defaultLocale string) *Catalog {
+	return &Catalog{
+		tenants:       make(map[string]Entries),
+		defaultLocale: defaultLocale,
+	}
+}
+
+// LoadDir loads default.json and one <tenant>.json override per tenant from dir
+func (c *Catalog) LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	tenants := make(map[string]Entries, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read label file %s: %v", file, err)
+		}
+		var entries Entries
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("failed to parse label file %s: %v", file, err)
+		}
+		tenants[strings.TrimSuffix(filepath.Base(file), ".json")] = entries
+	}
+
+	c.mu.Lock()
+	c.tenants = tenants
+	c.mu.Unlock()
+	return nil
+}
+
+// Set replaces the entries for a tenant; use "default" for the base catalog
+func (c *Catalog) Set(tenant string, entries Entries) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tenants[tenant] = entries
+}
+
+// Lookup returns the label for an enum value. It falls back from the tenant
+// to the default catalog, from a regional locale ("nl-BE") to its language
+// ("nl") and then the default locale, and from the requested audience to the
+// other one. The raw value is returned when no label exists.
+func (c *Catalog) Lookup(tenant, enum, value, locale, audience string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	tenants := []string{defaultTenant}
+	if tenant != "" && tenant != defaultTenant {
+		tenants = []string{tenant, defaultTenant}
+	}
+
+	for _, loc := range c.localeChain(locale) {
+		for _, aud := range audienceChain(audience) {
+			for _, t := range tenants {
+				if label, ok := c.tenants[t][enum][value][loc][aud]; ok && label != "" {
+					return label
+				}
+			}
+		}
+	}
+	return value
+}
+
+// localeChain returns the locales to try in order
+func (c *Catalog) localeChain(locale string) []string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	var chain []string
+	if locale != "" {
+		chain = append(chain, locale)
+		if lang, _, found := strings.Cut(locale, "-"); found {
+			chain = append(chain, lang)
+		}
+	}
+	if c.defaultLocale != "" {
+		chain = append(chain, c.defaultLocale)
+	}
+	return chain
+}
+
+// audienceChain returns the audiences to try in order
+func audienceChain(audience string) []string {
+	if audience == AudienceStaff {
+		return []string{AudienceStaff, AudienceGuest}
+	}
+	return []string{AudienceGuest, AudienceStaff}
+}
+
+// Context identifies who a response is being localized for
+type Context struct {
+	Tenant   string
+	Locale   string
+	Audience string
+}
+
+// Transform adds a "<field>_label" entry next to every enum field found in
+// data, walking nested objects and lists. fields maps response field names
+// to enum names, e.g. {"status": "room_status"}.
+func (c *Catalog) Transform(data interface{}, fields map[string]string, ctx Context) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if enum, ok := fields[key]; ok {
+				if s, ok := value.(string); ok {
+					v[key+"_label"] = c.Lookup(ctx.Tenant, enum, s, ctx.Locale, ctx.Audience)
+					continue
+				}
+			}
+			c.Transform(value, fields, ctx)
+		}
+	case []interface{}:
+		for _, item := range v {
+			c.Transform(item, fields, ctx)
+		}
+	}
+}
+
+var (
+	catalog          = NewCatalog("en")
+	supportedLocales = []string{"en"}
+)
+
+// Init loads the global catalog from dir. The first supported locale is the
+// fallback for labels missing in the requested one.
+func Init(dir string, locales []string) error {
+	if len(locales) == 0 {
+		locales = []string{"en"}
+	}
+
+	c := NewCatalog(strings.ToLower(locales[0]))
+	if err := c.LoadDir(dir); err != nil {
+		return err
+	}
+	catalog = c
+	supportedLocales = locales
+	return nil
+}
+
+// Negotiate picks the best supported locale for an Accept-Language header,
+// matching either the full tag or its language
+func Negotiate(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		lang, _, _ := strings.Cut(tag, "-")
+		for _, supported := range supportedLocales {
+			s := strings.ToLower(supported)
+			if s == tag || s == lang {
+				return s
+			}
+		}
+	}
+	return strings.ToLower(supportedLocales[0])
+}
+
+// Lookup returns a label from the global catalog
+func Lookup(tenant, enum, value, locale, audience string) string {
+	return catalog.Lookup(tenant, enum, value, locale, audience)
+}
+
+// Transform adds labels to a response using the global catalog
+func Transform(data interface{}, fields map[string]string, ctx Context) {
+	catalog.Transform(data, fields, ctx)
+}
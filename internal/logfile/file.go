@@ -0,0 +1,78 @@
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// File is a log file that can be rotated while loggers keep writing to it
+type File struct {
+	path string
+	file *os.File
+	mu   sync.Mutex
+}
+
+// Open opens (or creates) a log file for appending
+func Open(path string) (*File, error) {
+	lf := &File{path: path}
+	if err := lf.open(); err != nil {
+		return nil, err
+	}
+	return lf, nil
+}
+
+// Write implements io.Writer
+func (lf *File) Write(p []byte) (int, error) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	return lf.file.Write(p)
+}
+
+// Path returns the active log file path
+func (lf *File) Path() string {
+	return lf.path
+}
+
+// Size returns the current size of the active log file
+func (lf *File) Size() (int64, error) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+
+	info, err := lf.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Rotate moves the active file aside with a timestamp suffix and starts a
+// new one, returning the archived path
+func (lf *File) Rotate() (string, error) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+
+	archived := fmt.Sprintf("%s.%s", lf.path, time.Now().UTC().Format("20060102-150405"))
+	if err := os.Rename(lf.path, archived); err != nil {
+		return "", fmt.Errorf("failed to archive log file: %v", err)
+	}
+
+	old := lf.file
+	if err := lf.open(); err != nil {
+		// Keep writing to the archived file rather than losing logs
+		return archived, err
+	}
+	old.Close()
+	return archived, nil
+}
+
+// open opens the log file path; callers hold the lock or own lf exclusively
+func (lf *File) open() error {
+	file, err := os.OpenFile(lf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	lf.file = file
+	return nil
+}
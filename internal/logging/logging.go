@@ -0,0 +1,73 @@
+// Package logging holds the gateway's shared logger and the request-scoped
+// log entries derived from it, so every line written while handling a
+// request carries the same correlation fields.
+package logging
+
+import (
+	"context"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+var logger = newLogger()
+
+type entryKey struct{}
+
+// newLogger creates the JSON logger all packages write to
+func newLogger() *logrus.Logger {
+	l := logrus.New()
+	l.SetFormatter(&logrus.JSONFormatter{})
+	l.SetLevel(logrus.InfoLevel)
+	return l
+}
+
+// Logger returns the shared logger. Packages keep it in their log
+// variable instead of creating their own, so LOG_LEVEL and LOG_FILE apply
+// to every log line.
+func Logger() *logrus.Logger {
+	return logger
+}
+
+// SetOutput sends log lines to w instead of stderr
+func SetOutput(w io.Writer) {
+	logger.SetOutput(w)
+}
+
+// SetLevel sets the lowest level logged, e.g. "debug" or "WARN"
+func SetLevel(name string) error {
+	level, err := logrus.ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	logger.SetLevel(level)
+	return nil
+}
+
+// WithFields returns a context whose log entry has fields added to those
+// already on ctx
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return context.WithValue(ctx, entryKey{}, FromContext(ctx).WithFields(fields))
+}
+
+// FromContext returns the log entry of a context, with its request's
+// correlation fields, or a plain entry of the shared logger
+func FromContext(ctx context.Context) *logrus.Entry {
+	if entry, ok := ctx.Value(entryKey{}).(*logrus.Entry); ok {
+		return entry
+	}
+	return logrus.NewEntry(logger)
+}
+
+// For returns the log entry of a request. It carries request_id from the
+// start, and user_id, tenant and trace_id once they are known.
+func For(c *gin.Context) *logrus.Entry {
+	return FromContext(c.Request.Context())
+}
+
+// AddFields adds fields to the log entry of a request for the handlers and
+// backend calls that follow
+func AddFields(c *gin.Context, fields logrus.Fields) {
+	c.Request = c.Request.WithContext(WithFields(c.Request.Context(), fields))
+}
@@ -0,0 +1,176 @@
+package maintenance
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Degradation modes applied to routes while a window is active
+const (
+	ModeReadOnly = "read-only" // Reads pass through, writes are rejected
+	ModeCached   = "cached"    // Reads are served from the last good response, writes are rejected
+	ModeDegraded = "degraded"  // All requests fail fast without calling the upstream
+)
+
+// Window is a scheduled maintenance period for an upstream service
+type Window struct {
+	ID        string    `json:"id"`
+	Service   string    `json:"service"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Mode      string    `json:"mode"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ActiveAt reports whether the window covers the given time
+func (w *Window) ActiveAt(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+var (
+	windows   = make(map[string]*Window)
+	windowsMu sync.RWMutex
+)
+
+// ValidMode reports whether mode is a supported degradation mode
+func ValidMode(mode string) bool {
+	return mode == ModeReadOnly || mode == ModeCached || mode == ModeDegraded
+}
+
+// validate checks a window's fields
+func validate(w *Window) error {
+	if w.Service == "" {
+		return fmt.Errorf("service is required")
+	}
+	if !w.End.After(w.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+	if !ValidMode(w.Mode) {
+		return fmt.Errorf("mode must be one of %s, %s, %s", ModeReadOnly, ModeCached, ModeDegraded)
+	}
+	return nil
+}
+
+// Create schedules a new maintenance window
+func Create(w Window) (*Window, error) {
+	if err := validate(&w); err != nil {
+		return nil, err
+	}
+
+	w.ID = uuid.New().String()
+	w.CreatedAt = time.Now()
+
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
+	windows[w.ID] = &w
+
+	copied := w
+	return &copied, nil
+}
+
+// Update replaces the schedule of an existing window
+func Update(id string, w Window) (*Window, error) {
+	if err := validate(&w); err != nil {
+		return nil, err
+	}
+
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
+
+	existing, exists := windows[id]
+	if !exists {
+		return nil, fmt.Errorf("maintenance window %s not found", id)
+	}
+
+	existing.Service = w.Service
+	existing.Start = w.Start
+	existing.End = w.End
+	existing.Mode = w.Mode
+	existing.Reason = w.Reason
+
+	copied := *existing
+	return &copied, nil
+}
+
+// Delete removes a window
+func Delete(id string) error {
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
+
+	if _, exists := windows[id]; !exists {
+		return fmt.Errorf("maintenance window %s not found", id)
+	}
+	delete(windows, id)
+	return nil
+}
+
+// Get returns a window by ID
+func Get(id string) (*Window, bool) {
+	windowsMu.RLock()
+	defer windowsMu.RUnlock()
+
+	w, exists := windows[id]
+	if !exists {
+		return nil, false
+	}
+	copied := *w
+	return &copied, true
+}
+
+// List returns all windows ordered by start time
+func List() []Window {
+	windowsMu.RLock()
+	defer windowsMu.RUnlock()
+
+	result := make([]Window, 0, len(windows))
+	for _, w := range windows {
+		result = append(result, *w)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result
+}
+
+// Active returns the active window for a service, if any. When windows
+// overlap the most restrictive mode wins.
+func Active(service string, now time.Time) *Window {
+	windowsMu.RLock()
+	defer windowsMu.RUnlock()
+
+	var active *Window
+	for _, w := range windows {
+		if w.Service != service || !w.ActiveAt(now) {
+			continue
+		}
+		if active == nil || severity(w.Mode) > severity(active.Mode) {
+			active = w
+		}
+	}
+
+	if active == nil {
+		return nil
+	}
+	copied := *active
+	return &copied
+}
+
+// severity orders modes from least to most restrictive
+func severity(mode string) int {
+	switch mode {
+	case ModeReadOnly:
+		return 1
+	case ModeCached:
+		return 2
+	case ModeDegraded:
+		return 3
+	default:
+		return 0
+	}
+}
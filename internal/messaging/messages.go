@@ -0,0 +1,262 @@
+package messaging
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/events"
+
+	"github.com/google/uuid"
+)
+
+// Message directions and statuses
+const (
+	FromGuest = "guest"
+	FromStaff = "staff"
+
+	StatusOpen     = "open"
+	StatusAnswered = "answered"
+	StatusClosed   = "closed"
+)
+
+// ErrMessageNotFound is returned for unknown message IDs
+var ErrMessageNotFound = errors.New("message not found")
+
+// Message is a single guest or front-desk message tied to a reservation
+type Message struct {
+	ID            string    `json:"id"`
+	ReservationID string    `json:"reservation_id"`
+	SenderID      string    `json:"sender_id"`
+	Direction     string    `json:"direction"`
+	Body          string    `json:"body"`
+	Status        string    `json:"status"`
+	ReplyTo       string    `json:"reply_to,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ContentFilter inspects a message body and returns the text to store, or an
+// error to reject the message
+type ContentFilter func(body string) (string, error)
+
+// Notifier is called when a message is posted, e.g. to push replies to the guest
+type Notifier func(msg Message)
+
+var (
+	messages  = make(map[string]*Message)
+	messageMu sync.RWMutex
+
+	contentFilter ContentFilter = func(body string) (string, error) { return body, nil }
+	notifiers     []Notifier
+	hooksMu       sync.RWMutex
+)
+
+// Init configures the blocked-word filter, starts the retention sweeper
+// and has front-desk replies announced to their guests
+func Init(retention time.Duration, blockedWords []string) {
+	if len(blockedWords) > 0 {
+		SetContentFilter(WordMaskFilter(blockedWords))
+	}
+	OnMessage(notifyGuest)
+	if retention > 0 {
+		go sweep(retention)
+	}
+}
+
+// SetContentFilter replaces the profanity/content filter hook
+func SetContentFilter(filter ContentFilter) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	contentFilter = filter
+}
+
+// OnMessage registers a notifier called for every new message
+func OnMessage(notifier Notifier) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	notifiers = append(notifiers, notifier)
+}
+
+// notifyGuest publishes a front-desk reply as a message.replied event
+// addressed to the guest who wrote the message it answers, so only their
+// event streams receive it. The body stays out of the event; the guest
+// reads it from the thread.
+func notifyGuest(msg Message) {
+	if msg.Direction != FromStaff {
+		return
+	}
+	original, ok := Get(msg.ReplyTo)
+	if !ok {
+		return
+	}
+	events.Publish(events.Event{
+		ID:         uuid.New().String(),
+		Type:       "message.replied",
+		Source:     "internal-api",
+		OccurredAt: msg.CreatedAt,
+		Data: map[string]interface{}{
+			events.RecipientField: original.SenderID,
+			"reservation_id":      msg.ReservationID,
+			"message_id":          msg.ID,
+			"reply_to":            msg.ReplyTo,
+		},
+	})
+}
+
+// WordMaskFilter masks blocked words (case-insensitive, whole words) with asterisks
+func WordMaskFilter(words []string) ContentFilter {
+	quoted := make([]string, 0, len(words))
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w != "" {
+			quoted = append(quoted, regexp.QuoteMeta(w))
+		}
+	}
+	if len(quoted) == 0 {
+		return func(body string) (string, error) { return body, nil }
+	}
+
+	pattern := regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+	return func(body string) (string, error) {
+		return pattern.ReplaceAllStringFunc(body, func(match string) string {
+			return strings.Repeat("*", len(match))
+		}), nil
+	}
+}
+
+// Post stores a new guest message for a reservation
+func Post(reservationID, senderID, body string) (*Message, error) {
+	return store(Message{
+		ReservationID: reservationID,
+		SenderID:      senderID,
+		Direction:     FromGuest,
+		Body:          body,
+		Status:        StatusOpen,
+	})
+}
+
+// Reply stores a front-desk reply and marks the original message answered
+func Reply(messageID, staffID, body string) (*Message, error) {
+	messageMu.Lock()
+	original, exists := messages[messageID]
+	if !exists {
+		messageMu.Unlock()
+		return nil, ErrMessageNotFound
+	}
+	original.Status = StatusAnswered
+	reservationID := original.ReservationID
+	messageMu.Unlock()
+
+	return store(Message{
+		ReservationID: reservationID,
+		SenderID:      staffID,
+		Direction:     FromStaff,
+		Body:          body,
+		Status:        StatusAnswered,
+		ReplyTo:       messageID,
+	})
+}
+
+// Close marks a guest message as handled without a reply
+func Close(messageID string) (*Message, error) {
+	messageMu.Lock()
+	defer messageMu.Unlock()
+
+	msg, exists := messages[messageID]
+	if !exists {
+		return nil, ErrMessageNotFound
+	}
+	msg.Status = StatusClosed
+
+	copied := *msg
+	return &copied, nil
+}
+
+// Get returns a message by ID
+func Get(messageID string) (*Message, bool) {
+	messageMu.RLock()
+	defer messageMu.RUnlock()
+
+	msg, exists := messages[messageID]
+	if !exists {
+		return nil, false
+	}
+	copied := *msg
+	return &copied, true
+}
+
+// Thread returns every message for a reservation, oldest first
+func Thread(reservationID string) []Message {
+	return list(func(m *Message) bool { return m.ReservationID == reservationID })
+}
+
+// Queue returns guest messages with the given status (all statuses if empty), oldest first
+func Queue(status string) []Message {
+	return list(func(m *Message) bool {
+		return m.Direction == FromGuest && (status == "" || m.Status == status)
+	})
+}
+
+// store filters, saves and announces a message
+func store(msg Message) (*Message, error) {
+	hooksMu.RLock()
+	filter := contentFilter
+	hooks := append([]Notifier(nil), notifiers...)
+	hooksMu.RUnlock()
+
+	body, err := filter(msg.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.ID = uuid.New().String()
+	msg.Body = body
+	msg.CreatedAt = time.Now()
+
+	messageMu.Lock()
+	messages[msg.ID] = &msg
+	messageMu.Unlock()
+
+	for _, notify := range hooks {
+		go notify(msg)
+	}
+
+	copied := msg
+	return &copied, nil
+}
+
+// list returns matching messages sorted by creation time
+func list(match func(m *Message) bool) []Message {
+	messageMu.RLock()
+	defer messageMu.RUnlock()
+
+	result := []Message{}
+	for _, m := range messages {
+		if match(m) {
+			result = append(result, *m)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// sweep deletes messages older than the retention period
+func sweep(retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cutoff := time.Now().Add(-retention)
+		messageMu.Lock()
+		for id, m := range messages {
+			if m.CreatedAt.Before(cutoff) {
+				delete(messages, id)
+			}
+		}
+		messageMu.Unlock()
+	}
+}
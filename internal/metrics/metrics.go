@@ -0,0 +1,41 @@
+// Package metrics holds the gateway's Prometheus registry. Packages create
+// their metrics with Factory instead of promauto's package functions, so
+// /metrics exposes exactly the gateway's own series plus the Go runtime and
+// process collectors, and nothing a dependency registers globally.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes the name of every metric the gateway defines
+const Namespace = "hotel"
+
+// Registry collects every metric served on /metrics
+var Registry = newRegistry()
+
+// Factory creates metrics registered with Registry, e.g.
+// metrics.Factory.NewCounter(prometheus.CounterOpts{...})
+var Factory = promauto.With(Registry)
+
+// newRegistry creates the registry with the standard go_* and process_*
+// collectors: goroutines, GC, memory, CPU time, open file descriptors
+func newRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// Handler serves Registry in the Prometheus exposition format. Scrape
+// errors are reported in promhttp_metric_handler_errors_total.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"InternalAPI/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader is the header internal services use to authenticate
+const APIKeyHeader = "X-Internal-API-Key"
+
+// APIKey describes a service-to-service key and what it may access
+type APIKey struct {
+	Name   string
+	Scopes []string
+	hash   [32]byte
+}
+
+// HasScope reports whether the key grants a scope; "*" grants every scope
+// and "albums:*" grants every albums scope
+func (k *APIKey) HasScope(scope string) bool {
+	resource, _, _ := strings.Cut(scope, ":")
+	for _, s := range k.Scopes {
+		if s == "*" || s == scope || s == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	apiKeys   []*APIKey
+	apiKeysMu sync.RWMutex
+
+	routeScopes   = make(map[string]string)
+	routeScopesMu sync.RWMutex
+)
+
+// InitAPIKeys loads API keys from a spec of "name:key:scope1|scope2" entries separated by commas
+func InitAPIKeys(spec string) error {
+	var keys []*APIKey
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid API key entry for %q, expected name:key:scopes", parts[0])
+		}
+
+		key := &APIKey{Name: parts[0], hash: sha256.Sum256([]byte(parts[1]))}
+		if len(parts) == 3 && parts[2] != "" {
+			key.Scopes = strings.Split(parts[2], "|")
+		}
+		keys = append(keys, key)
+	}
+
+	apiKeysMu.Lock()
+	apiKeys = keys
+	apiKeysMu.Unlock()
+	return nil
+}
+
+// lookupAPIKey finds the key matching a presented value using constant-time comparison
+func lookupAPIKey(presented string) *APIKey {
+	hash := sha256.Sum256([]byte(presented))
+
+	apiKeysMu.RLock()
+	defer apiKeysMu.RUnlock()
+
+	var match *APIKey
+	for _, key := range apiKeys {
+		if subtle.ConstantTimeCompare(hash[:], key.hash[:]) == 1 {
+			match = key
+		}
+	}
+	return match
+}
+
+// APIKeyAuthMiddleware authenticates service-to-service calls using X-Internal-API-Key
+func APIKeyAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authenticateAPIKey(c) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RegisterRouteScope lets API keys call a route when they hold scope. Keys
+// are refused on every route that was not registered.
+func RegisterRouteScope(method, path, scope string) {
+	routeScopesMu.Lock()
+	defer routeScopesMu.Unlock()
+	routeScopes[method+" "+path] = scope
+}
+
+// HandleScoped registers a route on group and lets API keys call it when
+// they hold scope. User tokens are not affected.
+func HandleScoped(group *gin.RouterGroup, method, path, scope string, handlers ...gin.HandlerFunc) {
+	RegisterRouteScope(method, group.BasePath()+path, scope)
+	group.Handle(method, path, handlers...)
+}
+
+// routeScope returns the scope API keys need on the matched route
+func routeScope(c *gin.Context) (string, bool) {
+	routeScopesMu.RLock()
+	defer routeScopesMu.RUnlock()
+	scope, ok := routeScopes[c.Request.Method+" "+c.FullPath()]
+	return scope, ok
+}
+
+// JWTOrAPIKeyAuth accepts either a user JWT or a service API key. Keys are
+// only accepted on routes registered with a scope the key holds.
+func JWTOrAPIKeyAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ok bool
+		if c.GetHeader(APIKeyHeader) != "" {
+			ok = authenticateAPIKey(c) && requireRouteScope(c)
+		} else {
+			ok = authenticateJWT(c)
+		}
+
+		if !ok {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireRouteScope checks the authenticated key against the scope of the
+// matched route and records the granted scope. It writes the error response
+// on failure.
+func requireRouteScope(c *gin.Context) bool {
+	scope, ok := routeScope(c)
+	if !ok {
+		traceDecision(c, "scope", "denied: route takes no API keys")
+		sendError(c, http.StatusForbidden, "API_KEY_NOT_ALLOWED", "This endpoint does not accept API keys")
+		return false
+	}
+
+	key := c.MustGet("api_key").(*APIKey)
+	if !key.HasScope(scope) {
+		traceDecision(c, "scope", "denied "+scope)
+		sendError(c, http.StatusForbidden, "INSUFFICIENT_SCOPE", "API key does not grant scope "+scope)
+		return false
+	}
+
+	traceDecision(c, "scope", "granted "+scope)
+	c.Set("api_key_scope", scope)
+	return true
+}
+
+// authenticateAPIKey validates the API key header and stores the service
+// principal in the context. It writes the error response on failure.
+func authenticateAPIKey(c *gin.Context) bool {
+	presented := c.GetHeader(APIKeyHeader)
+	if presented == "" {
+		traceDecision(c, "api_key", "rejected: missing header")
+		sendError(c, http.StatusUnauthorized, "MISSING_API_KEY", APIKeyHeader+" header is required")
+		return false
+	}
+
+	key := lookupAPIKey(presented)
+	if key == nil {
+		traceDecision(c, "api_key", "rejected: unknown key")
+		sendError(c, http.StatusUnauthorized, "INVALID_API_KEY", "API key is not recognized")
+		return false
+	}
+
+	userInfo := &models.UserInfo{
+		UserID:   "service:" + key.Name,
+		Username: key.Name,
+		Roles:    []string{"service"},
+	}
+
+	setUser(c, userInfo)
+	c.Set("api_key", key)
+	traceDecision(c, "api_key", "authenticated "+key.Name)
+	return true
+}
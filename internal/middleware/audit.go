@@ -5,18 +5,30 @@ import (
 	"io"
 	"time"
 
+	"InternalAPI/internal/audit"
+	"InternalAPI/internal/logging"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 var auditLog *logrus.Logger
 
+// maxAuditBodySize is the largest request or response body logged, in bytes
+const maxAuditBodySize = 1024
+
 func init() {
 	auditLog = logrus.New()
 	auditLog.SetFormatter(&logrus.JSONFormatter{})
 	auditLog.SetLevel(logrus.InfoLevel)
 }
 
+// SetAuditOutput sends audit log lines to w instead of stderr
+func SetAuditOutput(w io.Writer) {
+	auditLog.SetOutput(w)
+}
+
 // responseWriter wraps gin.ResponseWriter to capture response body
 type responseWriter struct {
 	gin.ResponseWriter
@@ -32,21 +44,35 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 func AuditLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
+
+		policy := capturePolicyFor(c.Request.Method, c.FullPath())
+		if policy == CaptureNone {
+			traceDecision(c, "audit", "skipped by capture policy")
+			c.Next()
+			return
+		}
 		
-		// Capture request body (for non-GET requests)
+		// Capture request body (for non-GET requests). Streams are never
+		// buffered; they are only logged once they end.
+		streaming := isStreaming(c)
+		captureBody := policy == CaptureRedacted || policy == CaptureFull
 		var requestBody []byte
-		if c.Request.Method != "GET" && c.Request.Body != nil {
+		if captureBody && c.Request.Method != "GET" && c.Request.Body != nil && !streaming {
 			requestBody, _ = io.ReadAll(c.Request.Body)
 			// Restore the body for the next handler
 			c.Request.Body = io.NopCloser(bytes.NewBuffer(requestBody))
 		}
 
+		traceDecision(c, "audit", "capturing request")
+
 		// Wrap response writer to capture response
 		blw := &responseWriter{
 			ResponseWriter: c.Writer,
 			body:          bytes.NewBufferString(""),
 		}
-		c.Writer = blw
+		if !streaming {
+			c.Writer = blw
+		}
 
 		// Process request
 		c.Next()
@@ -66,13 +92,20 @@ func AuditLogger() gin.HandlerFunc {
 			requestID = rid.(string)
 		}
 
+		// Secrets in the query are always redacted, personal data unless
+		// the route captures everything
+		query := redactQuery(c.Request.URL.RawQuery)
+		if policy != CaptureFull {
+			query = maskPIIQuery(query)
+		}
+
 		// Log the request/response
 		fields := logrus.Fields{
 			"request_id":   requestID,
 			"timestamp":    start.Unix(),
 			"method":       c.Request.Method,
 			"path":         c.Request.URL.Path,
-			"query":        c.Request.URL.RawQuery,
+			"query":        query,
 			"ip":           c.ClientIP(),
 			"user_agent":   c.Request.UserAgent(),
 			"user_id":      userID,
@@ -80,18 +113,71 @@ func AuditLogger() gin.HandlerFunc {
 			"duration_ms":  duration.Milliseconds(),
 			"request_size": c.Request.ContentLength,
 			"response_size": blw.body.Len(),
+			"capture_policy": string(policy),
+		}
+
+		if streaming {
+			fields["response_size"] = c.Writer.Size()
+		}
+
+		// Booking and room calls record the PMS adapter that served them
+		backend := c.GetString("pms_backend")
+		if backend != "" {
+			fields["pms_backend"] = backend
 		}
 
-		// Log request body for sensitive operations (excluding passwords)
-		if c.Request.Method != "GET" && len(requestBody) > 0 && len(requestBody) < 1024 {
-			// Don't log passwords or sensitive data
-			if c.Request.URL.Path != "/auth/login" && 
-			   c.Request.URL.Path != "/auth/change-password" &&
-			   c.Request.URL.Path != "/admin/users" {
-				fields["request_body"] = string(requestBody)
+		// Correlation fields of the request's log entry, such as tenant and
+		// trace_id, so audit lines match the application's
+		for key, value := range logging.For(c).Data {
+			if _, set := fields[key]; !set {
+				fields[key] = value
 			}
 		}
 
+		// Log small bodies with secrets redacted, and personal data masked
+		// unless the route captures full bodies
+		if c.Request.Method != "GET" && len(requestBody) > 0 && len(requestBody) < maxAuditBodySize {
+			fields["request_body"] = redactBody(requestBody, policy)
+		}
+		if captureBody && !streaming && blw.body.Len() > 0 && blw.body.Len() < maxAuditBodySize {
+			fields["response_body"] = redactBody(blw.body.Bytes(), policy)
+		}
+
+		// Record structured entry for audit queries
+		params := make(map[string]string, len(c.Params))
+		for _, p := range c.Params {
+			params[p.Key] = p.Value
+		}
+		resourceType, resourceID := audit.ResourceFromRoute(c.FullPath(), params)
+
+		// A list response records the resources it returned, so access
+		// reports include them
+		var listedIDs []string
+		if c.Request.Method == "GET" && resourceType != "" && resourceID == "" && !streaming && c.Writer.Status() == 200 {
+			listedIDs = audit.ListedIDs(resourceType, blw.body.Bytes())
+		}
+
+		audit.Record(audit.Entry{
+			ID:           uuid.New().String(),
+			RequestID:    requestID,
+			Timestamp:    start,
+			Method:       c.Request.Method,
+			Path:         c.Request.URL.Path,
+			Route:        c.FullPath(),
+			Query:        query,
+			IP:           c.ClientIP(),
+			UserAgent:    c.Request.UserAgent(),
+			UserID:       userID,
+			Action:       audit.ActionForMethod(c.Request.Method),
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			ResourceIDs:  listedIDs,
+			Status:       c.Writer.Status(),
+			DurationMs:   duration.Milliseconds(),
+			CapturePolicy: string(policy),
+			Backend:      backend,
+		})
+
 		// Log at different levels based on status
 		if c.Writer.Status() >= 500 {
 			auditLog.WithFields(fields).Error("Server error")
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CapturePolicy controls how much of a request the audit logger records
+type CapturePolicy string
+
+const (
+	// CaptureNone skips audit logging for the route entirely
+	CaptureNone CapturePolicy = "none"
+	// CaptureMetadata records who called what and the outcome, without the body
+	CaptureMetadata CapturePolicy = "metadata"
+	// CaptureRedacted records the request body with personal data masked
+	CaptureRedacted CapturePolicy = "redacted"
+	// CaptureFull records the request body as sent
+	CaptureFull CapturePolicy = "full"
+)
+
+// CaptureRule is a capture policy override for one route
+type CaptureRule struct {
+	Route  string        `json:"route"`
+	Policy CapturePolicy `json:"policy"`
+}
+
+var (
+	capturePolicyMu      sync.RWMutex
+	defaultCapturePolicy = CaptureRedacted
+	routeCapturePolicies = map[string]CapturePolicy{}
+)
+
+// ParseCapturePolicy validates a capture policy name
+func ParseCapturePolicy(name string) (CapturePolicy, error) {
+	switch policy := CapturePolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case CaptureNone, CaptureMetadata, CaptureRedacted, CaptureFull:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown audit capture policy %q (use none, metadata, redacted or full)", name)
+}
+
+// normalizeCaptureRoute turns "post /api/v1/guests" into "POST /api/v1/guests".
+// A method of * applies the policy to every method on the path.
+func normalizeCaptureRoute(route string) (string, error) {
+	method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+	path = strings.TrimSpace(path)
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("invalid route %q (expected \"METHOD /path\")", route)
+	}
+	return strings.ToUpper(method) + " " + path, nil
+}
+
+// InitAuditCapture sets the default capture policy and the per-route
+// overrides from the config, given as comma-separated "METHOD /path=policy"
+// entries
+func InitAuditCapture(defaultPolicy, routes string) error {
+	policy, err := ParseCapturePolicy(defaultPolicy)
+	if err != nil {
+		return err
+	}
+
+	overrides := map[string]CapturePolicy{}
+	for _, entry := range strings.Split(routes, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		route, name, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid audit capture route %q (expected \"METHOD /path=policy\")", entry)
+		}
+		key, err := normalizeCaptureRoute(route)
+		if err != nil {
+			return err
+		}
+		routePolicy, err := ParseCapturePolicy(name)
+		if err != nil {
+			return err
+		}
+		overrides[key] = routePolicy
+	}
+
+	capturePolicyMu.Lock()
+	defer capturePolicyMu.Unlock()
+	defaultCapturePolicy = policy
+	routeCapturePolicies = overrides
+	return nil
+}
+
+// SetDefaultCapturePolicy changes the policy for routes without an override
+func SetDefaultCapturePolicy(policy CapturePolicy) {
+	capturePolicyMu.Lock()
+	defer capturePolicyMu.Unlock()
+	defaultCapturePolicy = policy
+}
+
+// SetRouteCapturePolicy overrides the capture policy for one route and
+// returns the normalized route
+func SetRouteCapturePolicy(route string, policy CapturePolicy) (string, error) {
+	key, err := normalizeCaptureRoute(route)
+	if err != nil {
+		return "", err
+	}
+
+	capturePolicyMu.Lock()
+	defer capturePolicyMu.Unlock()
+	routeCapturePolicies[key] = policy
+	return key, nil
+}
+
+// ClearRouteCapturePolicy removes a route override so the default applies
+// again. It reports whether an override existed.
+func ClearRouteCapturePolicy(route string) bool {
+	key, err := normalizeCaptureRoute(route)
+	if err != nil {
+		return false
+	}
+
+	capturePolicyMu.Lock()
+	defer capturePolicyMu.Unlock()
+	if _, ok := routeCapturePolicies[key]; !ok {
+		return false
+	}
+	delete(routeCapturePolicies, key)
+	return true
+}
+
+// CapturePolicies returns the default policy and the route overrides sorted by route
+func CapturePolicies() (CapturePolicy, []CaptureRule) {
+	capturePolicyMu.RLock()
+	defer capturePolicyMu.RUnlock()
+
+	rules := make([]CaptureRule, 0, len(routeCapturePolicies))
+	for route, policy := range routeCapturePolicies {
+		rules = append(rules, CaptureRule{Route: route, Policy: policy})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Route < rules[j].Route })
+	return defaultCapturePolicy, rules
+}
+
+// capturePolicyFor returns the policy for a matched route. An override for
+// the exact method wins over a * override, which wins over the default.
+func capturePolicyFor(method, route string) CapturePolicy {
+	capturePolicyMu.RLock()
+	defer capturePolicyMu.RUnlock()
+
+	if route != "" {
+		if policy, ok := routeCapturePolicies[method+" "+route]; ok {
+			return policy
+		}
+		if policy, ok := routeCapturePolicies["* "+route]; ok {
+			return policy
+		}
+	}
+	return defaultCapturePolicy
+}
@@ -5,9 +5,13 @@ import (
 	"strings"
 	"time"
 
+	"InternalAPI/internal/logging"
 	"InternalAPI/internal/models"
+	"InternalAPI/internal/roles"
+	"InternalAPI/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
 // AuthMiddleware validates authentication for protected routes
@@ -41,14 +45,24 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		// Store user info in context for use in handlers
-		c.Set("user", userInfo)
-		c.Set("userID", userInfo.UserID) // For backward compatibility
+		setUser(c, userInfo)
 		c.Set("token", token)
 		c.Next()
 	}
 }
 
-// RequireRoles creates middleware that requires specific roles
+// setUser stores the authenticated user for handlers ("user" and
+// "userID"), for backend calls and for the request's log lines
+func setUser(c *gin.Context, userInfo *models.UserInfo) {
+	c.Set("user", userInfo)
+	c.Set("userID", userInfo.UserID)
+	c.Request = c.Request.WithContext(services.WithUserID(c.Request.Context(), userInfo.UserID))
+	logging.AddFields(c, logrus.Fields{"user_id": userInfo.UserID})
+}
+
+// RequireRoles creates middleware that requires one of the given roles or
+// permissions. Roles implied through the role hierarchy count, and wildcard
+// grants such as "albums:*" satisfy matching permissions.
 func RequireRoles(requiredRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userInterface, exists := c.Get("user")
@@ -65,26 +79,14 @@ func RequireRoles(requiredRoles ...string) gin.HandlerFunc {
 			return
 		}
 
-		// Check if user has any of the required roles
-		hasRole := false
-		for _, userRole := range user.Roles {
-			for _, requiredRole := range requiredRoles {
-				if userRole == requiredRole {
-					hasRole = true
-					break
-				}
-			}
-			if hasRole {
-				break
-			}
-		}
-
-		if !hasRole {
+		if !roles.Satisfies(user.Roles, requiredRoles...) {
+			traceDecision(c, "roles", "denied")
 			sendError(c, http.StatusForbidden, "INSUFFICIENT_PERMISSIONS", "User does not have required permissions")
 			c.Abort()
 			return
 		}
 
+		traceDecision(c, "roles", "allowed")
 		c.Next()
 	}
 }
@@ -0,0 +1,308 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+)
+
+// BlacklistStore persists revoked tokens so revocations survive restarts
+// and are shared between replicas. Tokens are stored as SHA-256 hashes.
+type BlacklistStore interface {
+	// Add revokes a token until its expiry
+	Add(ctx context.Context, tokenHash string, expiresAt time.Time) error
+	// Contains reports whether a token has been revoked
+	Contains(ctx context.Context, tokenHash string) (bool, error)
+	// Cleanup removes expired entries and returns how many were removed
+	Cleanup(ctx context.Context) (int, error)
+	// Size returns the number of entries currently stored
+	Size(ctx context.Context) (int, error)
+	// RevokeUser rejects a user's tokens issued before cutoff; the cutoff is
+	// kept until expiresAt
+	RevokeUser(ctx context.Context, userID string, cutoff, expiresAt time.Time) error
+	// UserRevokedUntil returns the revocation cutoff of a user, if any
+	UserRevokedUntil(ctx context.Context, userID string) (time.Time, bool, error)
+}
+
+// NewBlacklistStore creates a blacklist store for the configured backend
+func NewBlacklistStore(backend, redisURL, databaseURL string) (BlacklistStore, error) {
+	switch backend {
+	case "", "memory":
+		return NewMemoryBlacklistStore(), nil
+	case "redis":
+		return NewRedisBlacklistStore(redisURL)
+	case "postgres":
+		return NewPostgresBlacklistStore(databaseURL)
+	default:
+		return nil, fmt.Errorf("unknown blacklist backend: %s", backend)
+	}
+}
+
+// hashToken returns the storage key for a token
+func hashToken(tokenString string) string {
+	sum := sha256.Sum256([]byte(tokenString))
+	return hex.EncodeToString(sum[:])
+}
+
+// userRevocation is a per-user revocation cutoff as the memory store keeps it
+type userRevocation struct {
+	cutoff    time.Time
+	expiresAt time.Time
+}
+
+// MemoryBlacklistStore keeps revoked tokens in process memory
+type MemoryBlacklistStore struct {
+	tokens map[string]time.Time
+	users  map[string]userRevocation
+	mu     sync.RWMutex
+}
+
+// NewMemoryBlacklistStore creates an in-memory blacklist store
+func NewMemoryBlacklistStore() *MemoryBlacklistStore {
+	return &MemoryBlacklistStore{
+		tokens: make(map[string]time.Time),
+		users:  make(map[string]userRevocation),
+	}
+}
+
+// Add revokes a token until its expiry
+func (s *MemoryBlacklistStore) Add(ctx context.Context, tokenHash string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[tokenHash] = expiresAt
+	return nil
+}
+
+// Contains reports whether a token has been revoked
+func (s *MemoryBlacklistStore) Contains(ctx context.Context, tokenHash string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, exists := s.tokens[tokenHash]
+	return exists, nil
+}
+
+// Cleanup removes expired tokens
+func (s *MemoryBlacklistStore) Cleanup(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	now := time.Now()
+	for token, expiresAt := range s.tokens {
+		if expiresAt.Before(now) {
+			delete(s.tokens, token)
+			removed++
+		}
+	}
+	for userID, revocation := range s.users {
+		if revocation.expiresAt.Before(now) {
+			delete(s.users, userID)
+		}
+	}
+	return removed, nil
+}
+
+// Size returns the number of revoked tokens held in memory
+func (s *MemoryBlacklistStore) Size(ctx context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.tokens), nil
+}
+
+// RevokeUser stores a user's revocation cutoff
+func (s *MemoryBlacklistStore) RevokeUser(ctx context.Context, userID string, cutoff, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[userID] = userRevocation{cutoff: cutoff, expiresAt: expiresAt}
+	return nil
+}
+
+// UserRevokedUntil returns the revocation cutoff of a user
+func (s *MemoryBlacklistStore) UserRevokedUntil(ctx context.Context, userID string) (time.Time, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	revocation, exists := s.users[userID]
+	return revocation.cutoff, exists, nil
+}
+
+// RedisBlacklistStore keeps revoked tokens in Redis with a TTL matching token
+// expiry. User revocation cutoffs live under their own prefix so they are
+// not counted as blacklist entries.
+type RedisBlacklistStore struct {
+	client     *redis.Client
+	prefix     string
+	userPrefix string
+}
+
+// NewRedisBlacklistStore connects to Redis using a redis:// URL
+func NewRedisBlacklistStore(redisURL string) (*RedisBlacklistStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &RedisBlacklistStore{
+		client:     client,
+		prefix:     "internal-api:blacklist:",
+		userPrefix: "internal-api:revoked-users:",
+	}, nil
+}
+
+// Add revokes a token until its expiry
+func (s *RedisBlacklistStore) Add(ctx context.Context, tokenHash string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, s.prefix+tokenHash, expiresAt.Unix(), ttl).Err()
+}
+
+// Contains reports whether a token has been revoked
+func (s *RedisBlacklistStore) Contains(ctx context.Context, tokenHash string) (bool, error) {
+	n, err := s.client.Exists(ctx, s.prefix+tokenHash).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Cleanup is a no-op because Redis expires entries itself
+func (s *RedisBlacklistStore) Cleanup(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// Size counts the blacklist keys; Redis has already dropped expired ones
+func (s *RedisBlacklistStore) Size(ctx context.Context) (int, error) {
+	count := 0
+	iter := s.client.Scan(ctx, 0, s.prefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		count++
+	}
+	return count, iter.Err()
+}
+
+// RevokeUser stores a user's revocation cutoff until expiresAt
+func (s *RedisBlacklistStore) RevokeUser(ctx context.Context, userID string, cutoff, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, s.userPrefix+userID, cutoff.UnixNano(), ttl).Err()
+}
+
+// UserRevokedUntil returns the revocation cutoff of a user
+func (s *RedisBlacklistStore) UserRevokedUntil(ctx context.Context, userID string) (time.Time, bool, error) {
+	cutoff, err := s.client.Get(ctx, s.userPrefix+userID).Int64()
+	if err == redis.Nil {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return time.Unix(0, cutoff), true, nil
+}
+
+// PostgresBlacklistStore keeps revoked tokens in a Postgres table
+type PostgresBlacklistStore struct {
+	db *sql.DB
+}
+
+// NewPostgresBlacklistStore connects to Postgres and ensures the blacklist table exists
+func NewPostgresBlacklistStore(databaseURL string) (*PostgresBlacklistStore, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS revoked_tokens (
+		token_hash TEXT PRIMARY KEY,
+		expires_at TIMESTAMPTZ NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS revoked_users (
+		user_id       TEXT PRIMARY KEY,
+		revoked_until TIMESTAMPTZ NOT NULL,
+		expires_at    TIMESTAMPTZ NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create blacklist tables: %w", err)
+	}
+
+	return &PostgresBlacklistStore{db: db}, nil
+}
+
+// Add revokes a token until its expiry
+func (s *PostgresBlacklistStore) Add(ctx context.Context, tokenHash string, expiresAt time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO revoked_tokens (token_hash, expires_at) VALUES ($1, $2)
+		 ON CONFLICT (token_hash) DO UPDATE SET expires_at = EXCLUDED.expires_at`,
+		tokenHash, expiresAt)
+	return err
+}
+
+// Contains reports whether a token has been revoked
+func (s *PostgresBlacklistStore) Contains(ctx context.Context, tokenHash string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE token_hash = $1)`,
+		tokenHash).Scan(&exists)
+	return exists, err
+}
+
+// Cleanup deletes expired tokens and user revocations
+func (s *PostgresBlacklistStore) Cleanup(ctx context.Context) (int, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM revoked_users WHERE expires_at < NOW()`); err != nil {
+		return 0, err
+	}
+	removed, err := result.RowsAffected()
+	return int(removed), err
+}
+
+// Size counts the stored revocations, including expired ones not yet purged
+func (s *PostgresBlacklistStore) Size(ctx context.Context) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM revoked_tokens`).Scan(&count)
+	return count, err
+}
+
+// RevokeUser stores a user's revocation cutoff
+func (s *PostgresBlacklistStore) RevokeUser(ctx context.Context, userID string, cutoff, expiresAt time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO revoked_users (user_id, revoked_until, expires_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id) DO UPDATE SET revoked_until = EXCLUDED.revoked_until, expires_at = EXCLUDED.expires_at`,
+		userID, cutoff, expiresAt)
+	return err
+}
+
+// UserRevokedUntil returns the revocation cutoff of a user
+func (s *PostgresBlacklistStore) UserRevokedUntil(ctx context.Context, userID string) (time.Time, bool, error) {
+	var cutoff time.Time
+	err := s.db.QueryRowContext(ctx,
+		`SELECT revoked_until FROM revoked_users WHERE user_id = $1 AND expires_at > NOW()`,
+		userID).Scan(&cutoff)
+	if err == sql.ErrNoRows {
+		return time.Time{}, false, nil
+	}
+	return cutoff, err == nil, err
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// BlacklistConfig bounds the token blacklist between scheduled cleanups
+type BlacklistConfig struct {
+	CleanupInterval  time.Duration // How often expired revocations are purged
+	MaxEntries       int           // Size cap; expired entries are force-evicted when reached (0 disables)
+	CleanupThreshold int           // Size at which an insert triggers a cleanup (0 uses 80% of MaxEntries)
+}
+
+// BlacklistStatus describes the blacklist size and the last purge
+type BlacklistStatus struct {
+	Entries          int        `json:"entries"`
+	MaxEntries       int        `json:"max_entries"`
+	CleanupThreshold int        `json:"cleanup_threshold"`
+	LastPurge        *time.Time `json:"last_purge,omitempty"`
+	LastPurgeTrigger string     `json:"last_purge_trigger,omitempty"`
+	LastPurgeRemoved int        `json:"last_purge_removed"`
+}
+
+var (
+	blacklistConfig BlacklistConfig
+	// blacklistSize is the last known entry count plus inserts since; it
+	// avoids counting the store on every revocation
+	blacklistSize    atomic.Int64
+	blacklistPurgeMu sync.Mutex
+	lastPurge        BlacklistStatus
+
+	blacklistSizeGauge = metrics.Factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "token_blacklist_size",
+		Help:      "Revoked tokens currently held in the blacklist",
+	})
+	blacklistEvictions = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "token_blacklist_evictions_total",
+		Help:      "Expired blacklist entries removed, by trigger (scheduled, threshold, capacity, manual)",
+	}, []string{"trigger"})
+	blacklistOverCapacity = metrics.Factory.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "token_blacklist_over_capacity_total",
+		Help:      "Revocations stored while the blacklist was at its size cap after evicting expired entries",
+	})
+)
+
+// effectiveThreshold returns the size at which inserts trigger a cleanup
+func (cfg BlacklistConfig) effectiveThreshold() int {
+	if cfg.CleanupThreshold > 0 {
+		return cfg.CleanupThreshold
+	}
+	return cfg.MaxEntries * 8 / 10
+}
+
+// insertPurgeInterval spaces out cleanups triggered by inserts below the cap
+const insertPurgeInterval = 10 * time.Second
+
+// afterBlacklistInsert keeps the blacklist within its limits after a
+// revocation. Past the threshold expired entries are purged at most every
+// insertPurgeInterval; at the cap they are purged on every insert. Inserts
+// never wait for a purge another request is already running. Unexpired
+// revocations are never dropped, so a blacklist still at its cap is only
+// reported.
+func afterBlacklistInsert(store BlacklistStore) {
+	size := int(blacklistSize.Add(1))
+	blacklistSizeGauge.Set(float64(size))
+
+	threshold := blacklistConfig.effectiveThreshold()
+	if threshold <= 0 || size < threshold {
+		return
+	}
+
+	trigger := "threshold"
+	if blacklistConfig.MaxEntries > 0 && size >= blacklistConfig.MaxEntries {
+		trigger = "capacity"
+	}
+
+	if !blacklistPurgeMu.TryLock() {
+		return
+	}
+	defer blacklistPurgeMu.Unlock()
+	if trigger == "threshold" && lastPurge.LastPurge != nil && time.Since(*lastPurge.LastPurge) < insertPurgeInterval {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := purgeBlacklistLocked(ctx, store, trigger); err != nil {
+		logging.Logger().WithError(err).Warn("Token blacklist cleanup on insert failed")
+		return
+	}
+
+	if remaining := int(blacklistSize.Load()); blacklistConfig.MaxEntries > 0 && remaining >= blacklistConfig.MaxEntries {
+		blacklistOverCapacity.Inc()
+		logging.Logger().WithFields(logrus.Fields{
+			"entries":     remaining,
+			"max_entries": blacklistConfig.MaxEntries,
+		}).Warn("Token blacklist is at its size cap with no expired entries left to evict")
+	}
+}
+
+// purgeBlacklist removes expired entries and refreshes the size metrics
+func purgeBlacklist(ctx context.Context, store BlacklistStore, trigger string) (int, error) {
+	blacklistPurgeMu.Lock()
+	defer blacklistPurgeMu.Unlock()
+	return purgeBlacklistLocked(ctx, store, trigger)
+}
+
+// purgeBlacklistLocked does the work of purgeBlacklist. Callers must hold
+// blacklistPurgeMu.
+func purgeBlacklistLocked(ctx context.Context, store BlacklistStore, trigger string) (int, error) {
+	removed, err := store.Cleanup(ctx)
+	if err != nil {
+		return 0, err
+	}
+	blacklistEvictions.WithLabelValues(trigger).Add(float64(removed))
+
+	size, err := store.Size(ctx)
+	if err != nil {
+		return removed, err
+	}
+	blacklistSize.Store(int64(size))
+	blacklistSizeGauge.Set(float64(size))
+
+	now := time.Now()
+	lastPurge = BlacklistStatus{
+		LastPurge:        &now,
+		LastPurgeTrigger: trigger,
+		LastPurgeRemoved: removed,
+	}
+	return removed, nil
+}
+
+// PurgeBlacklist removes expired entries from the token blacklist on
+// request and returns how many were removed
+func PurgeBlacklist(ctx context.Context) (int, error) {
+	return purgeBlacklist(ctx, tokenBlacklist, "manual")
+}
+
+// GetBlacklistStatus returns the blacklist size, its limits and the last purge
+func GetBlacklistStatus(ctx context.Context) (BlacklistStatus, error) {
+	size, err := tokenBlacklist.Size(ctx)
+	if err != nil {
+		return BlacklistStatus{}, err
+	}
+
+	blacklistPurgeMu.Lock()
+	status := lastPurge
+	blacklistPurgeMu.Unlock()
+
+	status.Entries = size
+	status.MaxEntries = blacklistConfig.MaxEntries
+	status.CleanupThreshold = blacklistConfig.effectiveThreshold()
+	return status, nil
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"InternalAPI/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// BotCheck inspects an anonymous request and returns a non-empty reason when
+// it should be rejected as automated traffic
+type BotCheck func(c *gin.Context) string
+
+type namedBotCheck struct {
+	name  string
+	check BotCheck
+}
+
+var (
+	botChecks   []namedBotCheck
+	botChecksMu sync.RWMutex
+
+	botRejections = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "public_bot_rejections_total",
+		Help:      "Anonymous requests rejected by bot mitigation, by check",
+	}, []string{"check"})
+)
+
+// RegisterBotCheck adds a bot mitigation hook for the anonymous tier. Checks
+// run in registration order; a check registered again under the same name
+// replaces the earlier one.
+func RegisterBotCheck(name string, check BotCheck) {
+	botChecksMu.Lock()
+	defer botChecksMu.Unlock()
+
+	for i, existing := range botChecks {
+		if existing.name == name {
+			botChecks[i].check = check
+			return
+		}
+	}
+	botChecks = append(botChecks, namedBotCheck{name: name, check: check})
+}
+
+// InitBotChecks registers the built-in checks: requests without a
+// User-Agent or with one containing a blocked fragment are rejected, and
+// when challengeURL is set every request must carry an X-Challenge-Token
+// that the endpoint accepts.
+func InitBotChecks(blockedAgents []string, challengeURL string) {
+	var fragments []string
+	for _, agent := range blockedAgents {
+		if agent = strings.ToLower(strings.TrimSpace(agent)); agent != "" {
+			fragments = append(fragments, agent)
+		}
+	}
+
+	RegisterBotCheck("user_agent", func(c *gin.Context) string {
+		agent := strings.ToLower(c.Request.UserAgent())
+		if agent == "" {
+			return "missing User-Agent"
+		}
+		for _, fragment := range fragments {
+			if strings.Contains(agent, fragment) {
+				return "blocked User-Agent"
+			}
+		}
+		return ""
+	})
+
+	if challengeURL != "" {
+		verify := remoteChallengeVerifier(challengeURL)
+		RegisterBotCheck("challenge", func(c *gin.Context) string {
+			if !verify(c) {
+				return "challenge token missing or rejected"
+			}
+			return ""
+		})
+	}
+}
+
+// BotGuard rejects anonymous requests that any registered bot check flags
+func BotGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		botChecksMu.RLock()
+		checks := append([]namedBotCheck(nil), botChecks...)
+		botChecksMu.RUnlock()
+
+		for _, check := range checks {
+			if reason := check.check(c); reason != "" {
+				traceDecision(c, "bot_guard", check.name+": "+reason)
+				botRejections.WithLabelValues(check.name).Inc()
+				if check.name == "challenge" {
+					c.Header("X-Challenge-Required", "captcha")
+				}
+				sendError(c, http.StatusForbidden, "AUTOMATED_TRAFFIC", "The request was identified as automated traffic")
+				c.Abort()
+				return
+			}
+		}
+
+		traceDecision(c, "bot_guard", "allowed")
+		c.Next()
+	}
+}
@@ -0,0 +1,268 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"InternalAPI/internal/metrics"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Encodings the server can produce, in order of preference when a client
+// accepts several equally
+var compressionEncodings = []string{"br", "gzip"}
+
+var (
+	compressionMu       sync.RWMutex
+	compressionMinSize  = 1024
+	compressionLevel    = gzip.DefaultCompression
+	compressionTypes    []string
+	compressionDisabled []string
+)
+
+var compressionTotal = metrics.Factory.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "response_compression_total",
+		Help:      "Responses by content encoding; identity covers responses that were not compressed",
+	},
+	[]string{"encoding"},
+)
+
+// InitCompression configures response compression. Responses smaller than
+// minSize bytes, of a content type not in types, or under one of the
+// disabled path prefixes are sent as they are.
+func InitCompression(minSize, level int, types, disabledPaths []string) error {
+	if level < gzip.BestSpeed || level > gzip.BestCompression {
+		return fmt.Errorf("gzip level must be between %d and %d, got %d", gzip.BestSpeed, gzip.BestCompression, level)
+	}
+
+	compressionMu.Lock()
+	defer compressionMu.Unlock()
+	compressionMinSize = minSize
+	compressionLevel = level
+	compressionTypes = nil
+	for _, t := range types {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			compressionTypes = append(compressionTypes, t)
+		}
+	}
+	compressionDisabled = nil
+	for _, path := range disabledPaths {
+		if path = strings.TrimSpace(path); path != "" {
+			compressionDisabled = append(compressionDisabled, strings.TrimSuffix(path, "/"))
+		}
+	}
+	return nil
+}
+
+// Compression compresses responses with gzip or Brotli as negotiated with
+// Accept-Encoding. The response is held back until it reaches the minimum
+// size, so small responses are never compressed. Responses that already
+// carry a Content-Encoding, partial content, streams and WebSocket upgrades
+// are left alone. ETags are kept as they are: they identify the resource
+// for If-Match, and 304 replies compare them weakly.
+func Compression() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		compressionMu.RLock()
+		minSize, level := compressionMinSize, compressionLevel
+		disabled := pathDisabled(c.Request.URL.Path, compressionDisabled)
+		compressionMu.RUnlock()
+
+		if disabled || c.GetHeader("Upgrade") != "" || isStreaming(c) {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: minSize, level: level}
+		c.Writer = writer
+		defer func() {
+			writer.close()
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// pathDisabled reports whether path lies under one of the prefixes
+func pathDisabled(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding picks the encoding with the highest quality value in
+// an Accept-Encoding header, or "" when the client takes only identity
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	quality := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.TrimSpace(key) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if name != "" {
+			quality[name] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range compressionEncodings {
+		q, ok := quality[encoding]
+		if !ok {
+			q, ok = quality["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressibleType reports whether a Content-Type is configured for
+// compression
+func compressibleType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	compressionMu.RLock()
+	defer compressionMu.RUnlock()
+	for _, t := range compressionTypes {
+		if t == mediaType || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter holds a response back until it knows whether to compress
+// it, then writes it through a compressor or unchanged
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+	level    int
+	buf      bytes.Buffer
+	decided  bool
+	encoder  io.WriteCloser
+}
+
+// Write buffers p until the minimum size is reached
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if w.decided {
+		if w.encoder != nil {
+			return w.encoder.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buf.Write(p)
+	if w.buf.Len() >= w.minSize {
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// WriteString buffers s like Write
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow sends the headers, so the response can no longer be
+// compressed unless enough of it was buffered already
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.decide()
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush sends what has been written so far
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide()
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide chooses between compressing the response and passing it through,
+// and writes out what was buffered
+func (w *compressWriter) decide() error {
+	w.decided = true
+	header := w.Header()
+	status := w.Status()
+	compress := w.buf.Len() >= w.minSize &&
+		status != http.StatusNoContent && status != http.StatusNotModified && status != http.StatusPartialContent &&
+		header.Get("Content-Encoding") == "" && header.Get("Content-Range") == "" &&
+		compressibleType(header.Get("Content-Type"))
+
+	if !compress {
+		if w.buf.Len() == 0 {
+			return nil
+		}
+		compressionTotal.WithLabelValues("identity").Inc()
+		_, err := w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+		return err
+	}
+
+	compressionTotal.WithLabelValues(w.encoding).Inc()
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	if w.encoding == "br" {
+		w.encoder = brotli.NewWriterLevel(w.ResponseWriter, brotli.DefaultCompression)
+	} else {
+		w.encoder, _ = gzip.NewWriterLevel(w.ResponseWriter, w.level)
+	}
+	_, err := w.encoder.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// close writes out a response that stayed below the minimum size and
+// finishes the compressed stream
+func (w *compressWriter) close() {
+	if !w.decided {
+		w.decide()
+	}
+	if w.encoder != nil {
+		w.encoder.Close()
+	}
+}
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETagMode selects how ConditionalGET derives an ETag
+type ETagMode int
+
+const (
+	// StrongETag hashes the response body; equal tags mean equal bytes
+	StrongETag ETagMode = iota
+	// WeakETag hashes the body without volatileFields. It suits responses
+	// aggregated from several sources that carry per-request details, such
+	// as whether a source was served from cache.
+	WeakETag
+)
+
+// volatileFields are top-level response fields that differ between
+// otherwise equal aggregated responses
+var volatileFields = []string{"cached", "timestamp", "generated_at", "request_id"}
+
+// modifiedFields are the record fields Last-Modified is derived from
+var modifiedFields = []string{"updated_at", "modified_at", "last_modified"}
+
+// ConditionalGET adds validators to successful GET responses and answers
+// conditional requests with 304 Not Modified. An ETag set by the handler,
+// such as a resource ETag used for If-Match, is kept; otherwise one is
+// computed as mode says. Last-Modified is the newest updated_at (or
+// modified_at, last_modified) of the returned record or list items.
+// If-None-Match takes precedence over If-Modified-Since, as in RFC 9110.
+// Responses without their own Cache-Control get "private, no-cache", so
+// clients may keep them but revalidate before use.
+func ConditionalGET(mode ETagMode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		writer := &bufferedResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.Status() != http.StatusOK {
+			writer.flush()
+			return
+		}
+
+		body := writer.body.Bytes()
+		header := writer.Header()
+		etag := header.Get("ETag")
+		if etag == "" {
+			etag = computeETag(body, mode)
+			header.Set("ETag", etag)
+		}
+
+		lastModified, hasLastModified := time.Time{}, false
+		if value := header.Get("Last-Modified"); value != "" {
+			lastModified, hasLastModified = parseHTTPTime(value)
+		} else if lastModified, hasLastModified = bodyLastModified(body); hasLastModified {
+			header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		}
+
+		if header.Get("Cache-Control") == "" {
+			header.Set("Cache-Control", "private, no-cache")
+		}
+
+		if notModified(c.Request, etag, lastModified, hasLastModified) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			writer.ResponseWriter.WriteHeader(http.StatusNotModified)
+			writer.ResponseWriter.WriteHeaderNow()
+			return
+		}
+		writer.flush()
+	}
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when the
+// request has no If-None-Match
+func notModified(r *http.Request, etag string, lastModified time.Time, hasLastModified bool) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return etagMatches(header, etag)
+	}
+	if !hasLastModified {
+		return false
+	}
+	since, ok := parseHTTPTime(r.Header.Get("If-Modified-Since"))
+	return ok && !lastModified.Truncate(time.Second).After(since)
+}
+
+// computeETag hashes a response body
+func computeETag(body []byte, mode ETagMode) string {
+	if mode == WeakETag {
+		var decoded map[string]interface{}
+		if json.Unmarshal(body, &decoded) == nil {
+			for _, field := range volatileFields {
+				delete(decoded, field)
+			}
+			if canonical, err := json.Marshal(decoded); err == nil {
+				body = canonical
+			}
+		}
+		sum := sha256.Sum256(body)
+		return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	}
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// bodyLastModified returns the newest modification time of the record in a
+// JSON response, which may be nested one level deep, or of its list items
+func bodyLastModified(body []byte) (time.Time, bool) {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return time.Time{}, false
+	}
+
+	var newest time.Time
+	consider := func(record map[string]interface{}) {
+		for _, field := range modifiedFields {
+			if value, ok := record[field].(string); ok {
+				if t, err := time.Parse(time.RFC3339, value); err == nil && t.After(newest) {
+					newest = t
+				}
+			}
+		}
+	}
+
+	consider(decoded)
+	for _, value := range decoded {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			consider(v)
+		case []interface{}:
+			for _, item := range v {
+				if record, ok := item.(map[string]interface{}); ok {
+					consider(record)
+				}
+			}
+		}
+	}
+	return newest, !newest.IsZero()
+}
+
+// parseHTTPTime parses an HTTP date header
+func parseHTTPTime(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := http.ParseTime(value)
+	return t, err == nil
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORSPolicy is the cross-origin policy applied to browser requests
+type CORSPolicy struct {
+	Origins          []string      `json:"origins"` // Exact origins, "*", or subdomain patterns such as https://*.hotel.com
+	Methods          []string      `json:"methods"`
+	Headers          []string      `json:"headers"`
+	ExposeHeaders    []string      `json:"expose_headers"`
+	MaxAge           time.Duration `json:"-"`
+	MaxAgeSeconds    int           `json:"max_age_seconds"`
+	AllowCredentials bool          `json:"allow_credentials"`
+}
+
+var (
+	corsPolicy   CORSPolicy
+	corsHandler  gin.HandlerFunc
+	corsPolicyMu sync.RWMutex
+)
+
+// CORS returns the middleware enforcing a CORS policy. With the "*" origin
+// every origin is allowed, but without credentials, which browsers refuse
+// to send to a wildcard. SetCORSPolicy replaces the policy later.
+func CORS(policy CORSPolicy) (gin.HandlerFunc, error) {
+	if err := SetCORSPolicy(policy); err != nil {
+		return nil, err
+	}
+	return func(c *gin.Context) {
+		corsPolicyMu.RLock()
+		handler := corsHandler
+		corsPolicyMu.RUnlock()
+		handler(c)
+	}, nil
+}
+
+// SetCORSPolicy replaces the policy the CORS middleware enforces. An
+// invalid policy is rejected and the current one stays.
+func SetCORSPolicy(policy CORSPolicy) error {
+	for _, origin := range policy.Origins {
+		if err := validateOriginPattern(origin); err != nil {
+			return err
+		}
+	}
+
+	config := cors.Config{
+		AllowMethods:     policy.Methods,
+		AllowHeaders:     policy.Headers,
+		ExposeHeaders:    policy.ExposeHeaders,
+		AllowCredentials: policy.AllowCredentials,
+		MaxAge:           policy.MaxAge,
+	}
+	origins := policy.Origins
+	for _, origin := range origins {
+		if origin == "*" {
+			config.AllowAllOrigins = true
+			config.AllowCredentials = false
+		}
+	}
+	if !config.AllowAllOrigins {
+		config.AllowOriginFunc = func(origin string) bool {
+			return MatchOrigin(origins, origin)
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	policy.AllowCredentials = config.AllowCredentials
+	policy.MaxAgeSeconds = int(policy.MaxAge.Seconds())
+	handler := cors.New(config)
+	corsPolicyMu.Lock()
+	corsPolicy = policy
+	corsHandler = handler
+	corsPolicyMu.Unlock()
+	return nil
+}
+
+// EffectiveCORSPolicy returns the policy the CORS middleware enforces
+func EffectiveCORSPolicy() CORSPolicy {
+	corsPolicyMu.RLock()
+	defer corsPolicyMu.RUnlock()
+	return corsPolicy
+}
+
+// MatchOrigin reports whether origin is allowed by a list of exact origins,
+// "*" and subdomain patterns. https://*.hotel.com matches
+// https://portal.hotel.com and https://eu.portal.hotel.com, but not
+// https://hotel.com. Matching ignores case.
+func MatchOrigin(allowed []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+		scheme, host, ok := strings.Cut(pattern, "://*.")
+		if !ok {
+			continue
+		}
+		originScheme, originHost, ok := strings.Cut(origin, "://")
+		if ok && originScheme == scheme && strings.HasSuffix(originHost, "."+host) && !strings.Contains(originHost, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOriginPattern checks an origin is "*" or scheme://host[:port],
+// where the host may start with a "*." wildcard label
+func validateOriginPattern(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" || u.User != nil {
+		return fmt.Errorf("invalid CORS origin %q (expected scheme://host[:port])", origin)
+	}
+	if strings.Contains(u.Host, "*") {
+		return fmt.Errorf("invalid CORS origin %q (a wildcard is only allowed as the first host label, as in https://*.hotel.com)", origin)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cookie and header names used by cookie-based auth
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+	CSRFCookie         = "csrf_token"
+	CSRFHeader         = "X-CSRF-Token"
+)
+
+// refreshCookiePath limits the refresh token cookie to the refresh endpoint
+const refreshCookiePath = "/auth/refresh"
+
+// CookieConfig controls how auth cookies are issued
+type CookieConfig struct {
+	Domain     string
+	Secure     bool
+	SameSite   http.SameSite
+	RefreshTTL time.Duration // Lifetime of the refresh and CSRF cookies
+}
+
+// cookieAuth is nil while cookie-based auth is disabled
+var cookieAuth *CookieConfig
+
+// InitCookieAuth enables reading access tokens from cookies and issuing them at login
+func InitCookieAuth(cfg CookieConfig) {
+	cookieAuth = &cfg
+}
+
+// CookieAuthEnabled reports whether cookie-based auth is on
+func CookieAuthEnabled() bool {
+	return cookieAuth != nil
+}
+
+// ParseSameSite converts "strict", "lax" or "none" to an http.SameSite mode, defaulting to lax
+func ParseSameSite(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// SetAuthCookies stores the access and refresh tokens in httpOnly cookies and
+// issues a fresh CSRF token the portal must echo in the X-CSRF-Token header.
+// It returns the CSRF token.
+func SetAuthCookies(c *gin.Context, accessToken string, accessTTL time.Duration, refreshToken string) (string, error) {
+	if cookieAuth == nil {
+		return "", nil
+	}
+
+	csrfToken, err := newCSRFToken()
+	if err != nil {
+		return "", err
+	}
+
+	refreshMaxAge := int(cookieAuth.RefreshTTL.Seconds())
+	setCookie(c, AccessTokenCookie, accessToken, "/", int(accessTTL.Seconds()), true)
+	setCookie(c, RefreshTokenCookie, refreshToken, refreshCookiePath, refreshMaxAge, true)
+	setCookie(c, CSRFCookie, csrfToken, "/", refreshMaxAge, false)
+	return csrfToken, nil
+}
+
+// SetAccessCookie replaces only the access token cookie, keeping the refresh
+// and CSRF cookies
+func SetAccessCookie(c *gin.Context, accessToken string, accessTTL time.Duration) {
+	if cookieAuth == nil {
+		return
+	}
+	setCookie(c, AccessTokenCookie, accessToken, "/", int(accessTTL.Seconds()), true)
+}
+
+// ClearAuthCookies removes the auth and CSRF cookies
+func ClearAuthCookies(c *gin.Context) {
+	if cookieAuth == nil {
+		return
+	}
+	setCookie(c, AccessTokenCookie, "", "/", -1, true)
+	setCookie(c, RefreshTokenCookie, "", refreshCookiePath, -1, true)
+	setCookie(c, CSRFCookie, "", "/", -1, false)
+}
+
+// RefreshTokenFromCookie returns the refresh token cookie, if cookie auth is on
+func RefreshTokenFromCookie(c *gin.Context) string {
+	if cookieAuth == nil {
+		return ""
+	}
+	token, _ := c.Cookie(RefreshTokenCookie)
+	return token
+}
+
+// CSRF enforces the double-submit token on state-changing requests that
+// authenticate with cookies. Requests using an Authorization or API key
+// header cannot be forged by a browser and pass through.
+func CSRF() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !needsCSRFCheck(c) {
+			c.Next()
+			return
+		}
+
+		cookieToken, _ := c.Cookie(CSRFCookie)
+		headerToken := c.GetHeader(CSRFHeader)
+		if cookieToken == "" || headerToken == "" ||
+			subtle.ConstantTimeCompare([]byte(cookieToken), []byte(headerToken)) != 1 {
+			traceDecision(c, "csrf", "rejected")
+			sendError(c, http.StatusForbidden, "CSRF_TOKEN_INVALID", "Missing or invalid "+CSRFHeader+" header")
+			c.Abort()
+			return
+		}
+
+		traceDecision(c, "csrf", "verified")
+		c.Next()
+	}
+}
+
+// needsCSRFCheck reports whether a request is a state-changing, cookie-authenticated call
+func needsCSRFCheck(c *gin.Context) bool {
+	if cookieAuth == nil {
+		return false
+	}
+
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	if c.GetHeader("Authorization") != "" || c.GetHeader(APIKeyHeader) != "" {
+		return false
+	}
+
+	for _, name := range []string{AccessTokenCookie, RefreshTokenCookie} {
+		if value, err := c.Cookie(name); err == nil && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// setCookie writes a cookie with the configured domain, Secure and SameSite settings
+func setCookie(c *gin.Context, name, value, path string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   cookieAuth.Domain,
+		MaxAge:   maxAge,
+		Secure:   cookieAuth.Secure,
+		HttpOnly: httpOnly,
+		SameSite: cookieAuth.SameSite,
+	})
+}
+
+// newCSRFToken returns a random URL-safe token
+func newCSRFToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
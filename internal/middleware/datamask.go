@@ -0,0 +1,286 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Masking strategies for DATA_MASKING_FIELDS
+const (
+	MaskHash    = "hash"    // Keyed hash; equal values stay equal so records can still be matched
+	MaskPartial = "partial" // Keeps the first and last characters, or the domain of an email address
+	MaskFake    = "fake"    // Plausible stand-in derived from the hash, e.g. an example.invalid address
+)
+
+var (
+	dataMaskFields map[string]string // Lower-case field name to strategy
+	dataMaskKey    []byte
+	dataMaskMu     sync.RWMutex
+	unmaskedRoutes = map[string]bool{} // "METHOD /route" responses that carry no data
+)
+
+// RegisterUnmaskedRoute exempts a route whose responses describe the API
+// rather than carry data, such as the OpenAPI document, from masking
+func RegisterUnmaskedRoute(method, path string) {
+	dataMaskMu.Lock()
+	defer dataMaskMu.Unlock()
+	unmaskedRoutes[method+" "+path] = true
+}
+
+// isUnmasked reports whether the matched route is exempt from masking
+func isUnmasked(c *gin.Context) bool {
+	dataMaskMu.RLock()
+	defer dataMaskMu.RUnlock()
+	return unmaskedRoutes[c.Request.Method+" "+c.FullPath()]
+}
+
+// InitDataMasking enables response masking for non-production deployments.
+// spec lists field:strategy entries separated by commas. Values are masked
+// irreversibly; an empty salt uses a random key, so hashes then change on
+// every restart.
+func InitDataMasking(spec, salt string) error {
+	fields := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		field, strategy, ok := strings.Cut(entry, ":")
+		if !ok {
+			strategy = MaskHash
+		}
+		strategy = strings.ToLower(strings.TrimSpace(strategy))
+		switch strategy {
+		case MaskHash, MaskPartial, MaskFake:
+		default:
+			return fmt.Errorf("unknown masking strategy %q for field %s", strategy, field)
+		}
+		fields[strings.ToLower(strings.TrimSpace(field))] = strategy
+	}
+
+	key := []byte(salt)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return fmt.Errorf("failed to generate masking key: %w", err)
+		}
+	}
+
+	dataMaskMu.Lock()
+	defer dataMaskMu.Unlock()
+	dataMaskFields = fields
+	dataMaskKey = key
+	return nil
+}
+
+// DataMaskingEnabled reports whether responses are being masked
+func DataMaskingEnabled() bool {
+	dataMaskMu.RLock()
+	defer dataMaskMu.RUnlock()
+	return len(dataMaskFields) > 0
+}
+
+// MaskData returns a copy of data with the configured fields masked at any
+// depth. data itself is not modified, so shared values such as published
+// events can be masked per delivery.
+func MaskData(data map[string]interface{}) map[string]interface{} {
+	if data == nil || !DataMaskingEnabled() {
+		return data
+	}
+	masked, _ := maskDataValue(data).(map[string]interface{})
+	return masked
+}
+
+// maskDataValue copies a decoded JSON value, masking configured fields
+func maskDataValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			if strategy := dataMaskStrategy(key); strategy != "" {
+				copied[key] = maskField(key, strategy, field)
+			} else {
+				copied[key] = maskDataValue(field)
+			}
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = maskDataValue(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
+// dataMaskStrategy returns the strategy for a field, or "" when it is not masked
+func dataMaskStrategy(name string) string {
+	dataMaskMu.RLock()
+	defer dataMaskMu.RUnlock()
+	return dataMaskFields[strings.ToLower(name)]
+}
+
+// maskField masks every scalar inside a configured field. Nulls stay null
+// so clients can still tell a missing value apart.
+func maskField(name, strategy string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = maskField(name, strategy, item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = maskField(name, strategy, item)
+		}
+		return copied
+	}
+
+	text := fmt.Sprint(value)
+	switch strategy {
+	case MaskPartial:
+		return maskPartial(text)
+	case MaskFake:
+		return fakeValue(strings.ToLower(name), maskHash(text))
+	default:
+		return "h:" + maskHash(text)
+	}
+}
+
+// maskHash returns a keyed, truncated hash of value
+func maskHash(value string) string {
+	dataMaskMu.RLock()
+	mac := hmac.New(sha256.New, dataMaskKey)
+	dataMaskMu.RUnlock()
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// maskPartial keeps the first and last two characters of a value, or the
+// first character and domain of an email address
+func maskPartial(value string) string {
+	if local, domain, ok := strings.Cut(value, "@"); ok && local != "" {
+		return string([]rune(local)[:1]) + "***@" + domain
+	}
+
+	runes := []rune(value)
+	if len(runes) <= 4 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:1]) + strings.Repeat("*", len(runes)-3) + string(runes[len(runes)-2:])
+}
+
+// fakeValue returns a stand-in shaped like the field, derived from hash so
+// equal values get equal stand-ins
+func fakeValue(field, hash string) string {
+	switch {
+	case strings.Contains(field, "email"):
+		return "guest-" + hash[:8] + "@example.invalid"
+	case strings.Contains(field, "phone"):
+		digits := make([]byte, 8)
+		for i := range digits {
+			digits[i] = '0' + hash[i]%10
+		}
+		return "+00 " + string(digits)
+	case strings.Contains(field, "birth") || strings.HasSuffix(field, "_date"):
+		return "1970-01-01"
+	case strings.Contains(field, "address"):
+		return "1 Example Street"
+	case strings.Contains(field, "name"):
+		return "Guest " + strings.ToUpper(hash[:4])
+	default:
+		return "masked-" + hash[:8]
+	}
+}
+
+// jsonBufferWriter holds back a JSON response so middleware can inspect or
+// rewrite it before it is sent. Other content types are written through
+// unchanged.
+type jsonBufferWriter struct {
+	gin.ResponseWriter
+	body        bytes.Buffer
+	passthrough bool
+	decided     bool
+}
+
+// Write buffers JSON bodies and passes anything else straight through
+func (w *jsonBufferWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		w.passthrough = !strings.Contains(w.Header().Get("Content-Type"), "json")
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+// WriteString buffers like Write
+func (w *jsonBufferWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written reports whether a body has been written or buffered
+func (w *jsonBufferWriter) Written() bool {
+	return w.body.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// DataMasking masks the configured fields in every JSON response. Streams
+// are not buffered; their handlers mask each event with MaskData.
+func DataMasking() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !DataMaskingEnabled() || isStreaming(c) || isUnmasked(c) {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &jsonBufferWriter{ResponseWriter: original}
+		c.Writer = writer
+		c.Header("X-Data-Masked", "true")
+
+		c.Next()
+
+		c.Writer = original
+		if writer.passthrough || writer.body.Len() == 0 {
+			return
+		}
+
+		// A body that cannot be masked is never sent as is
+		var data interface{}
+		var masked []byte
+		err := json.Unmarshal(writer.body.Bytes(), &data)
+		if err == nil {
+			masked, err = json.Marshal(maskDataValue(data))
+		}
+		original.Header().Del("Content-Length")
+		if err != nil {
+			original.WriteHeader(http.StatusInternalServerError)
+			masked, _ = json.Marshal(models.ErrorResponse{
+				Code:      "MASKING_FAILED",
+				Message:   "The response could not be masked",
+				Timestamp: time.Now().Unix(),
+			})
+		}
+		original.Write(masked)
+	}
+}
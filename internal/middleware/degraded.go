@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DegradedResponses marks responses built from backend fallbacks, served
+// while a circuit breaker is open. They get X-Degraded-Response with the
+// fallback kind, X-Degraded-Services naming the unavailable upstreams and
+// a Warning header; last-known-good responses also get their Age.
+func DegradedResponses() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, degradation := services.WithDegradation(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &degradedWriter{ResponseWriter: c.Writer, degradation: degradation}
+		c.Next()
+	}
+}
+
+// degradedWriter adds the degraded headers just before the response
+// headers are sent
+type degradedWriter struct {
+	gin.ResponseWriter
+	degradation *services.Degradation
+	once        sync.Once
+}
+
+// annotate sets the degraded headers if a fallback was served
+func (w *degradedWriter) annotate() {
+	w.once.Do(func() {
+		kind := w.degradation.Kind()
+		if kind == "" {
+			return
+		}
+		header := w.Header()
+		header.Set("X-Degraded-Response", kind)
+		header.Set("X-Degraded-Services", strings.Join(w.degradation.Services(), ","))
+		if kind == services.FallbackLastKnownGood {
+			header.Set("Age", strconv.Itoa(int(time.Since(w.degradation.Oldest()).Seconds())))
+			header.Set("Warning", `110 - "Response is Stale"`)
+		} else {
+			header.Set("Warning", `199 - "Degraded response while a backend is unavailable"`)
+		}
+	})
+}
+
+// WriteHeaderNow sends the headers
+func (w *degradedWriter) WriteHeaderNow() {
+	w.annotate()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Write writes the body, sending the headers first if needed
+func (w *degradedWriter) Write(data []byte) (int, error) {
+	w.annotate()
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes the body, sending the headers first if needed
+func (w *degradedWriter) WriteString(s string) (int, error) {
+	w.annotate()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush sends the buffered response, headers first
+func (w *degradedWriter) Flush() {
+	w.annotate()
+	w.ResponseWriter.Flush()
+}
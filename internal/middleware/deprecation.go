@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"InternalAPI/internal/changelog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecationHeaders announces deprecated routes from the API changelog
+// with Deprecation (RFC 9745) and Sunset (RFC 8594) headers, so clients
+// notice before the route is removed
+func DeprecationHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if change, ok := changelog.Deprecation(c.Request.Method, c.FullPath()); ok {
+			if since, err := time.Parse("2006-01-02", change.Date); err == nil {
+				c.Header("Deprecation", fmt.Sprintf("@%d", since.Unix()))
+			}
+			if sunset, err := time.Parse("2006-01-02", change.Sunset); err == nil {
+				c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			traceDecision(c, "deprecation", "route deprecated in "+change.Version)
+		}
+		c.Next()
+	}
+}
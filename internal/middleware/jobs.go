@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"InternalAPI/internal/jobs"
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// preferAsync is the Prefer header value (RFC 7240) that asks for a job
+const preferAsync = "respond-async"
+
+// AsyncJobs lets a client run handler in the background by sending
+// "Prefer: respond-async". The request is answered with 202 and a job ID
+// straight away; the handler then runs on a worker with the job timeout
+// instead of the usual client timeout, and the response it would have sent
+// is stored as the job result for GET /api/v1/jobs/:id. Requests without
+// the header, or when jobs are disabled, run as usual.
+func AsyncJobs(handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !jobs.Enabled() || !preferAsyncRequested(c.Request) {
+			handler(c)
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			sendError(c, http.StatusBadRequest, "INVALID_REQUEST", "Request body could not be read")
+			c.Abort()
+			return
+		}
+
+		// The context is reused once this request returns, so the job gets
+		// its own copy and its own request
+		background := c.Copy()
+		request := c.Request.Clone(context.Background())
+		logEntry := logging.For(c)
+
+		job, err := jobs.Submit(c.Request.Context(), c.Request.Method, c.Request.URL.Path, c.GetString("userID"),
+			func(ctx context.Context) (jobs.Result, error) {
+				recorder := newJobRecorder()
+				background.Writer = recorder
+				ctx = services.WithRequestID(ctx, background.GetString("request_id"))
+				ctx = services.WithUserID(ctx, background.GetString("userID"))
+				ctx = logging.WithFields(ctx, logEntry.Data)
+				background.Request = request.WithContext(ctx)
+				background.Request.Body = io.NopCloser(bytes.NewReader(body))
+				background.Request.ContentLength = int64(len(body))
+
+				handler(background)
+				return jobs.Result{StatusCode: recorder.Status(), Body: recorder.body.Bytes()}, nil
+			})
+		if errors.Is(err, jobs.ErrQueueFull) || errors.Is(err, jobs.ErrStopped) {
+			c.Header("Retry-After", "5")
+			sendError(c, http.StatusServiceUnavailable, "JOB_QUEUE_FULL", "Too many background jobs are waiting, please retry")
+			c.Abort()
+			return
+		}
+		if err != nil {
+			sendError(c, http.StatusInternalServerError, "JOB_STORE_FAILED", "The background job could not be stored, please retry")
+			c.Abort()
+			return
+		}
+
+		statusURL := "/api/v1/jobs/" + job.ID
+		c.Header("Preference-Applied", preferAsync)
+		c.Header("Location", statusURL)
+		c.JSON(http.StatusAccepted, gin.H{
+			"job_id":     job.ID,
+			"status":     job.Status,
+			"status_url": statusURL,
+		})
+	}
+}
+
+// preferAsyncRequested reports whether a Prefer header asks for respond-async
+func preferAsyncRequested(r *http.Request) bool {
+	for _, value := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(value, ",") {
+			preference, _, _ = strings.Cut(preference, ";")
+			if strings.EqualFold(strings.TrimSpace(preference), preferAsync) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jobRecorder is the gin.ResponseWriter a background job writes to. It
+// keeps the response in memory; there is no connection behind it.
+type jobRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+	wrote  bool
+}
+
+// newJobRecorder creates an empty recorder
+func newJobRecorder() *jobRecorder {
+	return &jobRecorder{header: make(http.Header), status: http.StatusOK}
+}
+
+// Header returns the response headers the handler set
+func (r *jobRecorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader records the status until the body is written
+func (r *jobRecorder) WriteHeader(status int) {
+	if !r.wrote {
+		r.status = status
+	}
+}
+
+// WriteHeaderNow fixes the status
+func (r *jobRecorder) WriteHeaderNow() {
+	r.wrote = true
+}
+
+// Write appends to the recorded body
+func (r *jobRecorder) Write(b []byte) (int, error) {
+	r.wrote = true
+	return r.body.Write(b)
+}
+
+// WriteString appends to the recorded body
+func (r *jobRecorder) WriteString(s string) (int, error) {
+	r.wrote = true
+	return r.body.WriteString(s)
+}
+
+// Status returns the recorded status
+func (r *jobRecorder) Status() int {
+	return r.status
+}
+
+// Size returns the recorded body length, or -1 before anything was written
+func (r *jobRecorder) Size() int {
+	if !r.wrote {
+		return -1
+	}
+	return r.body.Len()
+}
+
+// Written reports whether the status has been fixed
+func (r *jobRecorder) Written() bool {
+	return r.wrote
+}
+
+// Flush is a no-op; the body is kept until the job finishes
+func (r *jobRecorder) Flush() {}
+
+// Hijack is not supported
+func (r *jobRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, http.ErrNotSupported
+}
+
+// CloseNotify never fires; a job outlives the request that queued it
+func (r *jobRecorder) CloseNotify() <-chan bool {
+	return make(chan bool)
+}
+
+// Pusher returns nil; there is no connection to push on
+func (r *jobRecorder) Pusher() http.Pusher {
+	return nil
+}
@@ -1,10 +1,10 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
-	"sync"
 	"time"
 
 	"InternalAPI/internal/models"
@@ -15,38 +15,47 @@ import (
 
 var (
 	// Token blacklist for revoked tokens
-	tokenBlacklist = make(map[string]time.Time)
-	blacklistMu    sync.RWMutex
-	
-	// JWT secret key (should come from config)
-	jwtSecretKey []byte
+	tokenBlacklist BlacklistStore = NewMemoryBlacklistStore()
 )
 
-// InitJWT initializes the JWT secret key
-func InitJWT(secret string) {
-	jwtSecretKey = []byte(secret)
-	
+// InitBlacklist sets the token blacklist store, its size limits and starts
+// periodic cleanup
+func InitBlacklist(store BlacklistStore, cfg BlacklistConfig) {
+	tokenBlacklist = store
+	blacklistConfig = cfg
+
+	// Seed the size estimate used by the insert guardrails
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	purgeBlacklist(ctx, store, "scheduled")
+	cancel()
+
 	// Start cleanup routine for expired blacklisted tokens
-	go cleanupBlacklist()
+	go cleanupBlacklist(store, cfg.CleanupInterval)
 }
 
 // Claims represents JWT claims
 type Claims struct {
-	UserID   string   `json:"user_id"`
-	Username string   `json:"username"`
-	Email    string   `json:"email"`
-	Roles    []string `json:"roles"`
+	UserID     string   `json:"user_id"`
+	Username   string   `json:"username"`
+	Email      string   `json:"email"`
+	Roles      []string `json:"roles"`
+	Extensions int      `json:"ext,omitempty"` // Times the session was extended via /auth/extend
+	FamilyID   string   `json:"fid,omitempty"` // Refresh token family the session belongs to
 	jwt.RegisteredClaims
 }
 
 // ValidateJWT validates a JWT token and returns the claims
 func ValidateJWT(tokenString string) (*Claims, error) {
-	if len(jwtSecretKey) == 0 {
+	if !jwtInitialized() {
 		return nil, errors.New("JWT secret not initialized")
 	}
 
-	// Check if token is blacklisted
-	if isBlacklisted(tokenString) {
+	// Check if token is blacklisted; fail closed if the store is unreachable
+	revoked, err := isBlacklisted(tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("unable to verify token revocation: %w", err)
+	}
+	if revoked {
 		return nil, errors.New("token has been revoked")
 	}
 
@@ -56,7 +65,7 @@ func ValidateJWT(tokenString string) (*Claims, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return jwtSecretKey, nil
+		return verificationKeys(token)
 	})
 
 	if err != nil {
@@ -74,81 +83,112 @@ func ValidateJWT(tokenString string) (*Claims, error) {
 		return nil, errors.New("token has expired")
 	}
 
+	// Reject tokens issued before an admin revoked the user's sessions; fail
+	// closed like the blacklist check
+	userRevoked, err := tokenRegistry.IsRevoked(claims)
+	if err != nil {
+		return nil, fmt.Errorf("unable to verify token revocation: %w", err)
+	}
+	if userRevoked {
+		return nil, errors.New("token has been revoked")
+	}
+
 	return claims, nil
 }
 
 // BlacklistToken adds a token to the blacklist
-func BlacklistToken(tokenString string, expiresAt time.Time) {
-	blacklistMu.Lock()
-	defer blacklistMu.Unlock()
-	tokenBlacklist[tokenString] = expiresAt
+func BlacklistToken(tokenString string, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tokenBlacklist.Add(ctx, hashToken(tokenString), expiresAt); err != nil {
+		return err
+	}
+	afterBlacklistInsert(tokenBlacklist)
+	return nil
 }
 
 // isBlacklisted checks if a token is in the blacklist
-func isBlacklisted(tokenString string) bool {
-	blacklistMu.RLock()
-	defer blacklistMu.RUnlock()
-	_, exists := tokenBlacklist[tokenString]
-	return exists
+func isBlacklisted(tokenString string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return tokenBlacklist.Contains(ctx, hashToken(tokenString))
 }
 
-// cleanupBlacklist removes expired tokens from blacklist
-func cleanupBlacklist() {
-	ticker := time.NewTicker(1 * time.Hour)
+// cleanupBlacklist removes expired tokens from the blacklist store
+func cleanupBlacklist(store BlacklistStore, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		blacklistMu.Lock()
-		now := time.Now()
-		for token, expiresAt := range tokenBlacklist {
-			if expiresAt.Before(now) {
-				delete(tokenBlacklist, token)
-			}
-		}
-		blacklistMu.Unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		purgeBlacklist(ctx, store, "scheduled")
+		cancel()
 	}
 }
 
 // JWTAuthMiddleware validates JWT authentication for protected routes
 func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			sendError(c, http.StatusUnauthorized, "MISSING_AUTH", "Authorization header is required")
+		if !authenticateJWT(c) {
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
+
+// authenticateJWT validates the bearer token and stores the user in the
+// context. It writes the error response on failure.
+func authenticateJWT(c *gin.Context) bool {
+	authHeader := c.GetHeader("Authorization")
+
+	// Browsers using cookie auth send the access token as an httpOnly cookie
+	var tokenString string
+	if authHeader == "" && cookieAuth != nil {
+		tokenString, _ = c.Cookie(AccessTokenCookie)
+	}
+
+	if tokenString == "" {
+		if authHeader == "" {
+			traceDecision(c, "jwt", "rejected: missing header")
+			sendError(c, http.StatusUnauthorized, "MISSING_AUTH", "Authorization header is required")
+			return false
+		}
 
 		// Extract token from "Bearer <token>" format
-		tokenString := extractToken(authHeader)
+		tokenString = extractToken(authHeader)
 		if tokenString == "" {
+			traceDecision(c, "jwt", "rejected: invalid format")
 			sendError(c, http.StatusUnauthorized, "INVALID_AUTH_FORMAT", "Authorization header must be in format 'Bearer <token>'")
-			c.Abort()
-			return
-		}
-
-		// Validate token
-		claims, err := ValidateJWT(tokenString)
-		if err != nil {
-			sendError(c, http.StatusUnauthorized, "INVALID_TOKEN", fmt.Sprintf("Token validation failed: %v", err))
-			c.Abort()
-			return
+			return false
 		}
+	}
 
-		// Store user info in context
-		userInfo := &models.UserInfo{
-			UserID:   claims.UserID,
-			Username: claims.Username,
-			Email:    claims.Email,
-			Roles:    claims.Roles,
-			Exp:      claims.ExpiresAt.Unix(),
-		}
+	// Validate token
+	claims, err := ValidateJWT(tokenString)
+	if err != nil {
+		traceDecision(c, "jwt", "rejected: invalid token")
+		sendError(c, http.StatusUnauthorized, "INVALID_TOKEN", fmt.Sprintf("Token validation failed: %v", err))
+		return false
+	}
 
-		c.Set("user", userInfo)
-		c.Set("userID", userInfo.UserID)
-		c.Set("token", tokenString)
-		c.Next()
+	// Store user info in context
+	userInfo := &models.UserInfo{
+		UserID:   claims.UserID,
+		Username: claims.Username,
+		Email:    claims.Email,
+		Roles:    claims.Roles,
+		Exp:      claims.ExpiresAt.Unix(),
 	}
+
+	setUser(c, userInfo)
+	c.Set("token", tokenString)
+	tokenRegistry.Track(claims, tokenString, c.ClientIP(), c.Request.UserAgent())
+	traceDecision(c, "jwt", "authenticated "+userInfo.UserID)
+	return true
 }
 
 // extractToken extracts the token from Authorization header
@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtKey is a secret tokens may be signed with
+type jwtKey struct {
+	id        string
+	secret    []byte
+	addedAt   time.Time
+	expiresAt time.Time // Zero for configured keys
+}
+
+// JWTKeyInfo describes an accepted JWT key without revealing it
+type JWTKeyInfo struct {
+	ID        string     `json:"id"` // Sent as the kid header of tokens signed with the key
+	Current   bool       `json:"current"`
+	AddedAt   time.Time  `json:"added_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // When a replaced key stops being accepted
+}
+
+var (
+	// jwtKeys are the accepted keys; the first one signs new tokens
+	jwtKeys   []jwtKey
+	jwtKeysMu sync.RWMutex
+)
+
+// jwtKeyID derives the public identifier of a secret
+func jwtKeyID(secret string) string {
+	sum := sha256.Sum256([]byte("jwt-key:" + secret))
+	return hex.EncodeToString(sum[:8])
+}
+
+// InitJWT initializes the JWT keys: new tokens are signed with secret, and
+// tokens signed with any of the previous secrets are accepted too
+func InitJWT(secret string, previous ...string) {
+	SetJWTKeys(secret, previous, 0)
+}
+
+// SetJWTKeys replaces the accepted JWT keys. New tokens are signed with
+// current; tokens signed with current or previous are accepted. A current
+// key being replaced, and keys still in their grace period, stay accepted
+// for grace, which should be the access token lifetime, so sessions
+// continue across the rotation.
+func SetJWTKeys(current string, previous []string, grace time.Duration) {
+	jwtKeysMu.Lock()
+	defer jwtKeysMu.Unlock()
+
+	now := time.Now()
+	added := func(id string) time.Time {
+		for _, key := range jwtKeys {
+			if key.id == id {
+				return key.addedAt
+			}
+		}
+		return now
+	}
+
+	keys := []jwtKey{}
+	seen := make(map[string]bool)
+	for _, secret := range append([]string{current}, previous...) {
+		id := jwtKeyID(secret)
+		if secret == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		keys = append(keys, jwtKey{id: id, secret: []byte(secret), addedAt: added(id)})
+	}
+	for i, key := range jwtKeys {
+		if seen[key.id] {
+			continue
+		}
+		switch {
+		case i == 0 && grace > 0:
+			key.expiresAt = now.Add(grace)
+		case key.expiresAt.IsZero() || !now.Before(key.expiresAt):
+			continue
+		}
+		keys = append(keys, key)
+	}
+	jwtKeys = keys
+}
+
+// RotateJWT makes secret the signing key. The configured previous keys stay
+// accepted, and the key it replaces stays accepted for grace; a zero grace
+// rejects tokens signed with it at once.
+func RotateJWT(secret string, grace time.Duration) {
+	jwtKeysMu.RLock()
+	var previous []string
+	for i, key := range jwtKeys {
+		if i > 0 && key.expiresAt.IsZero() {
+			previous = append(previous, string(key.secret))
+		}
+	}
+	jwtKeysMu.RUnlock()
+	SetJWTKeys(secret, previous, grace)
+}
+
+// JWTKeys describes the accepted keys, the signing key first
+func JWTKeys() []JWTKeyInfo {
+	jwtKeysMu.RLock()
+	defer jwtKeysMu.RUnlock()
+
+	now := time.Now()
+	keys := []JWTKeyInfo{}
+	for i, key := range jwtKeys {
+		if !key.expiresAt.IsZero() && !now.Before(key.expiresAt) {
+			continue
+		}
+		info := JWTKeyInfo{ID: key.id, Current: i == 0, AddedAt: key.addedAt}
+		if !key.expiresAt.IsZero() {
+			expiresAt := key.expiresAt
+			info.ExpiresAt = &expiresAt
+		}
+		keys = append(keys, info)
+	}
+	return keys
+}
+
+// DeriveJWTKeys derives a key for purpose from each accepted JWT key with
+// HKDF-SHA256, the signing key first, so other secrets can follow JWT key
+// rotation without reusing the token signing keys themselves
+func DeriveJWTKeys(purpose string) [][]byte {
+	jwtKeysMu.RLock()
+	defer jwtKeysMu.RUnlock()
+
+	now := time.Now()
+	derived := [][]byte{}
+	for _, key := range jwtKeys {
+		if !key.expiresAt.IsZero() && !now.Before(key.expiresAt) {
+			continue
+		}
+		k, err := hkdf.Key(sha256.New, key.secret, nil, purpose, sha256.Size)
+		if err != nil {
+			continue
+		}
+		derived = append(derived, k)
+	}
+	return derived
+}
+
+// jwtInitialized reports whether a signing key is set
+func jwtInitialized() bool {
+	jwtKeysMu.RLock()
+	defer jwtKeysMu.RUnlock()
+	return len(jwtKeys) > 0
+}
+
+// SignJWT signs claims with the current key, naming it in the kid header
+func SignJWT(claims jwt.Claims) (string, error) {
+	jwtKeysMu.RLock()
+	if len(jwtKeys) == 0 {
+		jwtKeysMu.RUnlock()
+		return "", errors.New("JWT secret not initialized")
+	}
+	key := jwtKeys[0]
+	jwtKeysMu.RUnlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.secret)
+}
+
+// verificationKeys returns the keys a token may be signed with: the key
+// its kid names, or every accepted key for tokens without one
+func verificationKeys(token *jwt.Token) (interface{}, error) {
+	jwtKeysMu.RLock()
+	defer jwtKeysMu.RUnlock()
+
+	now := time.Now()
+	kid, _ := token.Header["kid"].(string)
+	set := jwt.VerificationKeySet{}
+	for _, key := range jwtKeys {
+		if !key.expiresAt.IsZero() && !now.Before(key.expiresAt) {
+			continue
+		}
+		if key.id == kid {
+			return key.secret, nil
+		}
+		set.Keys = append(set.Keys, key.secret)
+	}
+	if kid != "" {
+		return nil, errors.New("token signed with an unknown or retired key")
+	}
+	if len(set.Keys) == 0 {
+		return nil, errors.New("JWT secret not initialized")
+	}
+	return set, nil
+}
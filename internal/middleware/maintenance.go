@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"InternalAPI/internal/cache"
+	"InternalAPI/internal/maintenance"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cachedResponse is a last-known-good response served during cached-mode maintenance
+type cachedResponse struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+// MaintenanceGuard applies scheduled maintenance windows for an upstream
+// service to the routes it protects. Successful reads are remembered for up
+// to cacheTTL so they can be replayed while the upstream is in cached mode;
+// a cacheTTL of 0 remembers nothing, for personal data. Replays are keyed
+// like the response cache: by roles, tenant, locale and the Central
+// Management filters of resource, or by the caller when resource is empty.
+// Install it on each route after its scope and permission checks, so a
+// replay never skips them.
+func MaintenanceGuard(service, resource string, cacheTTL time.Duration) gin.HandlerFunc {
+	lastGood := cache.New()
+
+	return func(c *gin.Context) {
+		isRead := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+
+		window := maintenance.Active(service, time.Now())
+		if window == nil {
+			traceDecision(c, "maintenance", "no active window")
+			// Streamed downloads are too large to remember
+			if !isRead || isStreaming(c) || cacheTTL <= 0 {
+				c.Next()
+				return
+			}
+			key, ok := maintenanceCacheKey(c, resource)
+			if !ok {
+				c.Next()
+				return
+			}
+
+			// Capture successful reads for replay during cached-mode windows
+			blw := &responseWriter{ResponseWriter: c.Writer, body: bytes.NewBufferString("")}
+			c.Writer = blw
+			c.Next()
+
+			if blw.Status() >= 200 && blw.Status() < 300 && services.DegradationFrom(c.Request.Context()).Kind() == "" {
+				lastGood.Set(key, cachedResponse{
+					status:      blw.Status(),
+					contentType: blw.Header().Get("Content-Type"),
+					body:        blw.body.Bytes(),
+				}, cacheTTL)
+			}
+			return
+		}
+
+		annotateMaintenance(c, window)
+		traceDecision(c, "maintenance", window.Mode+" window active")
+
+		switch {
+		case window.Mode == maintenance.ModeDegraded:
+			sendMaintenanceError(c, window, "MAINTENANCE_DEGRADED", service+" is unavailable during scheduled maintenance")
+			return
+
+		case !isRead:
+			sendMaintenanceError(c, window, "MAINTENANCE_READ_ONLY", service+" is read-only during scheduled maintenance")
+			return
+
+		case window.Mode == maintenance.ModeCached:
+			var cached interface{}
+			key, ok := maintenanceCacheKey(c, resource)
+			if ok {
+				cached, ok = lastGood.Get(key)
+			}
+			if !ok {
+				sendMaintenanceError(c, window, "MAINTENANCE_NO_CACHE", "No cached data is available during scheduled maintenance")
+				return
+			}
+			resp := cached.(cachedResponse)
+			c.Header("X-Cache", "STALE")
+			c.Data(resp.status, resp.contentType, resp.body)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// maintenanceCacheKey derives the key a read is remembered under. Without
+// a resource the response may depend on the caller in ways filters do not
+// describe, so it is only replayed to the same user.
+func maintenanceCacheKey(c *gin.Context, resource string) (string, bool) {
+	key, ok := responseCacheKey(c, resource)
+	if !ok {
+		return "", false
+	}
+	if resource == "" {
+		key += "|" + c.GetString("userID")
+	}
+	return key, true
+}
+
+// annotateMaintenance adds headers describing the active window
+func annotateMaintenance(c *gin.Context, window *maintenance.Window) {
+	c.Header("X-Maintenance-Mode", window.Mode)
+	c.Header("X-Maintenance-Service", window.Service)
+	c.Header("X-Maintenance-Until", window.End.UTC().Format(time.RFC3339))
+	c.Header("Warning", `199 - "`+window.Service+` is in scheduled maintenance (`+window.Mode+`)"`)
+}
+
+// sendMaintenanceError rejects a request with 503 and a Retry-After matching the window end
+func sendMaintenanceError(c *gin.Context, window *maintenance.Window, code, message string) {
+	retryAfter := int(math.Ceil(time.Until(window.End).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	sendError(c, http.StatusServiceUnavailable, code, message)
+	c.Abort()
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"InternalAPI/internal/permissions"
+	"InternalAPI/internal/resilience"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission asks Central Management whether the current user may
+// perform action on resource. Decisions are cached by the permissions
+// package; service API keys are governed by scopes and skip the check.
+func RequirePermission(action, resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !checkPermission(c, action, resource) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// checkPermission runs the RequirePermission check. It writes the error
+// response and returns false when the request may not continue.
+func checkPermission(c *gin.Context, action, resource string) bool {
+	// Keys are governed by scopes; one that was not granted a scope for this
+	// route never reaches a permission-checked handler
+	if _, isService := c.Get("api_key"); isService {
+		if c.GetString("api_key_scope") == "" {
+			traceDecision(c, "permission", "denied: service key without route scope")
+			sendError(c, http.StatusForbidden, "API_KEY_NOT_ALLOWED", "This endpoint does not accept API keys")
+			return false
+		}
+		traceDecision(c, "permission", "skipped: service key with scope "+c.GetString("api_key_scope"))
+		return true
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		sendError(c, http.StatusUnauthorized, "MISSING_USER", "User information not found in context")
+		return false
+	}
+
+	if !permissions.Enabled() {
+		sendError(c, http.StatusInternalServerError, "PERMISSIONS_NOT_CONFIGURED", "Permission checks are not configured")
+		return false
+	}
+
+	// Include the request payload so business rules can inspect it
+	decision, err := permissions.Check(c.Request.Context(), userID.(string), action, resource, peekJSONBody(c))
+	if err != nil {
+		traceDecision(c, "permission", "error: "+err.Error())
+		sendPermissionServiceError(c, err)
+		return false
+	}
+
+	if !decision.Allowed {
+		traceDecision(c, "permission", "denied "+action)
+		message := "User is not permitted to " + action + " on " + resource
+		if decision.Reason != "" {
+			message = decision.Reason
+		}
+		sendError(c, http.StatusForbidden, "PERMISSION_DENIED", message)
+		return false
+	}
+
+	traceDecision(c, "permission", "allowed "+action)
+	return true
+}
+
+// peekJSONBody decodes a JSON request body without consuming it
+func peekJSONBody(c *gin.Context) map[string]interface{} {
+	if c.Request.Body == nil || c.Request.Method == http.MethodGet {
+		return nil
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+	if err != nil || len(body) == 0 {
+		return nil
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil
+	}
+	return data
+}
+
+// sendPermissionServiceError fails closed when Central Management cannot answer
+func sendPermissionServiceError(c *gin.Context, err error) {
+	var openErr *resilience.OpenError
+	var bulkheadErr *resilience.BulkheadError
+	if errors.As(err, &openErr) {
+		retryAfter := int(math.Ceil(openErr.RetryAfter.Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		sendError(c, http.StatusServiceUnavailable, "CIRCUIT_OPEN", err.Error())
+	} else if errors.As(err, &bulkheadErr) {
+		c.Header("Retry-After", "1")
+		sendError(c, http.StatusServiceUnavailable, "UPSTREAM_SATURATED", err.Error())
+	} else {
+		sendError(c, http.StatusServiceUnavailable, "PERMISSION_CHECK_FAILED", "Unable to verify permissions")
+	}
+	c.Abort()
+}
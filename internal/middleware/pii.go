@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// piiMask replaces personal data in audit logs
+const piiMask = "***"
+
+var (
+	piiFields   = map[string]bool{}
+	piiFieldsMu sync.RWMutex
+)
+
+// InitPIIMasking sets the JSON fields and query parameters whose values are
+// masked in audit logs. Names are matched case-insensitively.
+func InitPIIMasking(fields []string) {
+	masked := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if field = strings.ToLower(strings.TrimSpace(field)); field != "" {
+			masked[field] = true
+		}
+	}
+
+	piiFieldsMu.Lock()
+	defer piiFieldsMu.Unlock()
+	piiFields = masked
+}
+
+// isPIIField reports whether a field or parameter holds personal data
+func isPIIField(name string) bool {
+	piiFieldsMu.RLock()
+	defer piiFieldsMu.RUnlock()
+	return piiFields[strings.ToLower(name)]
+}
+
+// maskPIIValue masks personal data fields anywhere in a decoded JSON value
+func maskPIIValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isPIIField(key) {
+				v[key] = piiMask
+			} else {
+				v[key] = maskPIIValue(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = maskPIIValue(v[i])
+		}
+	}
+	return value
+}
+
+// maskPIIQuery masks personal data parameters in a raw query string,
+// keeping the parameter order
+func maskPIIQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	pairs := strings.Split(rawQuery, "&")
+	for i, pair := range pairs {
+		rawKey, _, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		if isPIIField(key) {
+			pairs[i] = rawKey + "=" + piiMask
+		}
+	}
+	return strings.Join(pairs, "&")
+}
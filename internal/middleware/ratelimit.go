@@ -17,6 +17,13 @@ type RateLimiter struct {
 	cleanupInt time.Duration
 }
 
+// limiters are the rate limiters of the route groups by name, so their
+// limits can change while running
+var (
+	limiters   = make(map[string]*RateLimiter)
+	limitersMu sync.Mutex
+)
+
 type bucket struct {
 	tokens     int
 	lastRefill time.Time
@@ -38,8 +45,42 @@ func NewRateLimiter(rate int, interval time.Duration) *RateLimiter {
 	return rl
 }
 
+// registerLimiter creates the rate limiter of a route group
+func registerLimiter(name string, rate int, interval time.Duration) *RateLimiter {
+	limiter := NewRateLimiter(rate, interval)
+	limitersMu.Lock()
+	limiters[name] = limiter
+	limitersMu.Unlock()
+	return limiter
+}
+
+// SetRateLimit changes the limit of the named rate limiter (public, login,
+// api or admin). Buckets pick up the new limit at their next refill. It
+// reports false when no limiter has that name, e.g. with rate limiting off.
+func SetRateLimit(name string, rate int, interval time.Duration) bool {
+	limitersMu.Lock()
+	limiter, ok := limiters[name]
+	limitersMu.Unlock()
+	if !ok {
+		return false
+	}
+	limiter.mu.Lock()
+	limiter.rate = rate
+	limiter.interval = interval
+	limiter.mu.Unlock()
+	return true
+}
+
+// limit returns the current rate and interval
+func (rl *RateLimiter) limit() (int, time.Duration) {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return rl.rate, rl.interval
+}
+
 // Allow checks if a request should be allowed
 func (rl *RateLimiter) Allow(key string) bool {
+	rate, interval := rl.limit()
 	rl.mu.RLock()
 	b, exists := rl.buckets[key]
 	rl.mu.RUnlock()
@@ -47,7 +88,7 @@ func (rl *RateLimiter) Allow(key string) bool {
 	if !exists {
 		rl.mu.Lock()
 		b = &bucket{
-			tokens:     rl.rate,
+			tokens:     rate,
 			lastRefill: time.Now(),
 		}
 		rl.buckets[key] = b
@@ -60,8 +101,8 @@ func (rl *RateLimiter) Allow(key string) bool {
 	// Refill tokens based on time passed
 	now := time.Now()
 	elapsed := now.Sub(b.lastRefill)
-	if elapsed >= rl.interval {
-		b.tokens = rl.rate
+	if elapsed >= interval {
+		b.tokens = rate
 		b.lastRefill = now
 	}
 
@@ -93,30 +134,38 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
+// retryAfter is the seconds until a limited client gets new tokens at most
+func retryAfter(limiter *RateLimiter) float64 {
+	_, interval := limiter.limit()
+	return interval.Seconds()
+}
+
 // RateLimitByIP creates middleware that rate limits by IP address
-func RateLimitByIP(rate int, interval time.Duration) gin.HandlerFunc {
-	limiter := NewRateLimiter(rate, interval)
+func RateLimitByIP(name string, rate int, interval time.Duration) gin.HandlerFunc {
+	limiter := registerLimiter(name, rate, interval)
 
 	return func(c *gin.Context) {
 		ip := c.ClientIP()
 		
 		if !limiter.Allow(ip) {
+			traceDecision(c, "rate_limit", "limited "+ip)
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"code":    "RATE_LIMIT_EXCEEDED",
 				"message": "Too many requests. Please try again later.",
-				"retry_after": interval.Seconds(),
+				"retry_after": retryAfter(limiter),
 			})
 			c.Abort()
 			return
 		}
 
+		traceDecision(c, "rate_limit", "allowed")
 		c.Next()
 	}
 }
 
 // RateLimitByUser creates middleware that rate limits by authenticated user
-func RateLimitByUser(rate int, interval time.Duration) gin.HandlerFunc {
-	limiter := NewRateLimiter(rate, interval)
+func RateLimitByUser(name string, rate int, interval time.Duration) gin.HandlerFunc {
+	limiter := registerLimiter(name, rate, interval)
 
 	return func(c *gin.Context) {
 		// Get user ID from context (set by auth middleware)
@@ -129,36 +178,40 @@ func RateLimitByUser(rate int, interval time.Duration) gin.HandlerFunc {
 		key := userID.(string)
 		
 		if !limiter.Allow(key) {
+			traceDecision(c, "rate_limit", "limited "+key)
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"code":    "RATE_LIMIT_EXCEEDED",
 				"message": "Too many requests. Please try again later.",
-				"retry_after": interval.Seconds(),
+				"retry_after": retryAfter(limiter),
 			})
 			c.Abort()
 			return
 		}
 
+		traceDecision(c, "rate_limit", "allowed")
 		c.Next()
 	}
 }
 
 // StrictRateLimitByIP creates middleware with stricter limits (e.g., for login)
-func StrictRateLimitByIP(rate int, interval time.Duration) gin.HandlerFunc {
-	limiter := NewRateLimiter(rate, interval)
+func StrictRateLimitByIP(name string, rate int, interval time.Duration) gin.HandlerFunc {
+	limiter := registerLimiter(name, rate, interval)
 
 	return func(c *gin.Context) {
 		ip := c.ClientIP()
 		
 		if !limiter.Allow(ip) {
+			traceDecision(c, "rate_limit", "limited "+ip)
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"code":    "RATE_LIMIT_EXCEEDED",
 				"message": "Too many login attempts. Please try again later.",
-				"retry_after": interval.Seconds(),
+				"retry_after": retryAfter(limiter),
 			})
 			c.Abort()
 			return
 		}
 
+		traceDecision(c, "rate_limit", "allowed")
 		c.Next()
 	}
 }
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadOnlyState describes the gateway-wide read-only switch
+type ReadOnlyState struct {
+	Enabled bool       `json:"enabled"`
+	Reason  string     `json:"reason,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+var (
+	readOnly   ReadOnlyState
+	readOnlyMu sync.RWMutex
+)
+
+// SetReadOnly switches gateway-wide read-only mode on or off
+func SetReadOnly(enabled bool, reason string) {
+	readOnlyMu.Lock()
+	defer readOnlyMu.Unlock()
+
+	if !enabled {
+		readOnly = ReadOnlyState{}
+		return
+	}
+	now := time.Now()
+	readOnly = ReadOnlyState{Enabled: true, Reason: reason, Since: &now}
+}
+
+// GetReadOnly returns the current read-only state
+func GetReadOnly() ReadOnlyState {
+	readOnlyMu.RLock()
+	defer readOnlyMu.RUnlock()
+	return readOnly
+}
+
+// ReadOnlyGuard rejects writes while read-only mode is on. Reads always pass.
+func ReadOnlyGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		state := GetReadOnly()
+		if !state.Enabled {
+			c.Next()
+			return
+		}
+
+		traceDecision(c, "read_only", "rejected write")
+		message := "The API is temporarily read-only"
+		if state.Reason != "" {
+			message += ": " + state.Reason
+		}
+		sendError(c, http.StatusServiceUnavailable, "READ_ONLY_MODE", message)
+		c.Abort()
+	}
+}
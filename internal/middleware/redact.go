@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redactedValue replaces secrets in audit logs
+const redactedValue = "[REDACTED]"
+
+// cardNumberCandidate matches 13 to 19 digits, optionally grouped by spaces
+// or dashes, that may be a payment card number
+var cardNumberCandidate = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+var (
+	redactMu       sync.RWMutex
+	redactPatterns = []string{"*password*", "*token*", "*secret*", "apikey", "authorization", "cardnumber", "cvv", "cvc"} // Normalized, see InitRedaction
+	redactCards    = true
+)
+
+// InitRedaction sets the field name patterns whose values are redacted from
+// audit logged bodies and query strings, whatever the capture policy.
+// Patterns are globs such as "*token*", matched ignoring case, "_" and "-",
+// so "api_key" also covers apiKey and X-Api-Key. With cards, strings
+// holding a Luhn-valid card number are redacted in every field.
+func InitRedaction(patterns []string, cards bool) error {
+	compiled := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = normalizeFieldName(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid redaction pattern %q: %v", pattern, err)
+		}
+		compiled = append(compiled, pattern)
+	}
+
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	redactPatterns = compiled
+	redactCards = cards
+	return nil
+}
+
+// normalizeFieldName lowercases a field name and drops "_" and "-"
+func normalizeFieldName(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// isSecretField reports whether a field or parameter matches a redaction pattern
+func isSecretField(name string) bool {
+	name = normalizeFieldName(name)
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	for _, pattern := range redactPatterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// redactBody redacts secrets in a JSON body for logging and, unless the
+// route captures full bodies, masks personal data too. Bodies that are not
+// JSON cannot be inspected and are omitted.
+func redactBody(body []byte, policy CapturePolicy) string {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "[non-JSON body omitted]"
+	}
+	data = redactValue(data)
+	if policy != CaptureFull {
+		data = maskPIIValue(data)
+	}
+	redacted, _ := json.Marshal(data)
+	return string(redacted)
+}
+
+// redactValue redacts secret fields and card numbers anywhere in a decoded
+// JSON value
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSecretField(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	case string:
+		return redactCardNumbers(v)
+	}
+	return value
+}
+
+// redactCardNumbers replaces Luhn-valid card numbers in s
+func redactCardNumbers(s string) string {
+	redactMu.RLock()
+	cards := redactCards
+	redactMu.RUnlock()
+	if !cards {
+		return s
+	}
+	return cardNumberCandidate.ReplaceAllStringFunc(s, func(candidate string) string {
+		if luhnValid(strings.NewReplacer(" ", "", "-", "").Replace(candidate)) {
+			return redactedValue
+		}
+		return candidate
+	})
+}
+
+// luhnValid reports whether digits pass the Luhn checksum of card numbers
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// redactQuery redacts secret parameters and card numbers in a raw query
+// string, keeping the parameter order
+func redactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	pairs := strings.Split(rawQuery, "&")
+	for i, pair := range pairs {
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		if isSecretField(key) {
+			pairs[i] = rawKey + "=" + url.QueryEscape(redactedValue)
+			continue
+		}
+		if value, err := url.QueryUnescape(rawValue); err == nil {
+			if redacted := redactCardNumbers(value); redacted != value {
+				pairs[i] = rawKey + "=" + url.QueryEscape(redacted)
+			}
+		}
+	}
+	return strings.Join(pairs, "&")
+}
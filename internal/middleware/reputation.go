@@ -0,0 +1,312 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"InternalAPI/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxReputationScore = 100
+
+// ReputationConfig holds the tuning knobs for login reputation scoring
+type ReputationConfig struct {
+	ChallengeScore   int           // Scores at or below this require a CAPTCHA/step-up
+	BlockScore       int           // Scores at or below this are blocked outright
+	FailurePenalty   int           // Points removed per failed login
+	SuccessReward    int           // Points restored per successful login
+	RecoveryInterval time.Duration // One point is restored per interval without activity
+	ChallengeURL     string        // Optional verification endpoint for challenge tokens
+}
+
+// ChallengeVerifier validates a CAPTCHA or step-up response attached to a login request
+type ChallengeVerifier func(c *gin.Context) bool
+
+// ReputationTracker keeps login reputation scores per IP and device fingerprint
+type ReputationTracker struct {
+	config ReputationConfig
+	store  ReputationStore
+}
+
+// ReputationStatus is the public view of a tracked reputation entry
+type ReputationStatus struct {
+	Key         string `json:"key"`
+	Score       int    `json:"score"`
+	Failures    int64  `json:"failures"`
+	Successes   int64  `json:"successes"`
+	Decision    string `json:"decision"`
+	LastUpdated int64  `json:"last_updated"`
+}
+
+var (
+	reputationTracker *ReputationTracker
+	challengeVerifier ChallengeVerifier
+)
+
+// InitReputation initializes the global login reputation tracker on store
+func InitReputation(store ReputationStore, cfg ReputationConfig) {
+	if cfg.RecoveryInterval <= 0 {
+		cfg.RecoveryInterval = time.Minute
+	}
+
+	reputationTracker = &ReputationTracker{
+		config: cfg,
+		store:  store,
+	}
+
+	if cfg.ChallengeURL != "" {
+		challengeVerifier = remoteChallengeVerifier(cfg.ChallengeURL)
+	}
+
+	go reputationTracker.cleanup()
+}
+
+// SetChallengeVerifier overrides the CAPTCHA/step-up verification hook
+func SetChallengeVerifier(verifier ChallengeVerifier) {
+	challengeVerifier = verifier
+}
+
+// retention is how long an entry is kept after its last update; by then it
+// has fully recovered
+func (rt *ReputationTracker) retention() time.Duration {
+	return rt.config.RecoveryInterval * maxReputationScore
+}
+
+// score returns the current score for a key, applying time-based recovery
+func (rt *ReputationTracker) score(ctx context.Context, key string) (int, error) {
+	entry, exists, err := rt.store.Get(ctx, key)
+	if err != nil || !exists {
+		return maxReputationScore, err
+	}
+	return rt.recovered(entry), nil
+}
+
+// recovered calculates an entry's score including recovery since its last update
+func (rt *ReputationTracker) recovered(entry ReputationEntry) int {
+	recovery := int(time.Since(entry.LastUpdated) / rt.config.RecoveryInterval)
+	score := entry.Score + recovery
+	if score > maxReputationScore {
+		score = maxReputationScore
+	}
+	return score
+}
+
+// record updates the score for a key after a login attempt
+func (rt *ReputationTracker) record(ctx context.Context, key string, success bool) error {
+	return rt.store.Update(ctx, key, rt.retention(), func(entry *ReputationEntry, exists bool) {
+		if !exists {
+			*entry = ReputationEntry{Score: maxReputationScore, LastUpdated: time.Now()}
+		}
+
+		score := rt.recovered(*entry)
+		if success {
+			entry.Successes++
+			score += rt.config.SuccessReward
+			if score > maxReputationScore {
+				score = maxReputationScore
+			}
+		} else {
+			entry.Failures++
+			score -= rt.config.FailurePenalty
+			if score < 0 {
+				score = 0
+			}
+		}
+
+		entry.Score = score
+		entry.LastUpdated = time.Now()
+	})
+}
+
+// decision maps a score to allow, challenge or block
+func (rt *ReputationTracker) decision(score int) string {
+	switch {
+	case score <= rt.config.BlockScore:
+		return "block"
+	case score <= rt.config.ChallengeScore:
+		return "challenge"
+	default:
+		return "allow"
+	}
+}
+
+// cleanup removes entries that have fully recovered from stores that do
+// not expire entries themselves
+func (rt *ReputationTracker) cleanup() {
+	ticker := time.NewTicker(rt.retention())
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := rt.store.Cleanup(context.Background()); err != nil {
+			logging.Logger().WithError(err).Warn("Login reputation cleanup failed")
+		}
+	}
+}
+
+// LoginReputation blocks or challenges login attempts from IPs and devices with
+// a degraded reputation, and records the outcome of every attempt. Only
+// rejected credentials (401 and 403) count as failures. When the store
+// cannot be read, logins are let through rather than locked out.
+func LoginReputation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if reputationTracker == nil {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		keys := reputationKeys(c)
+
+		// The worst of the IP and device scores decides
+		score := maxReputationScore
+		for _, key := range keys {
+			s, err := reputationTracker.score(ctx, key)
+			if err != nil {
+				logging.Logger().WithError(err).WithField("key", key).Warn("Failed to read login reputation")
+				continue
+			}
+			if s < score {
+				score = s
+			}
+		}
+
+		switch reputationTracker.decision(score) {
+		case "block":
+			traceDecision(c, "reputation", "blocked")
+			sendError(c, http.StatusForbidden, "LOGIN_BLOCKED", "Too many failed login attempts from this network or device")
+			c.Abort()
+			return
+		case "challenge":
+			if challengeVerifier == nil || !challengeVerifier(c) {
+				traceDecision(c, "reputation", "challenge required")
+				c.Header("X-Challenge-Required", "captcha")
+				sendError(c, http.StatusUnauthorized, "CHALLENGE_REQUIRED", "Additional verification is required to log in")
+				c.Abort()
+				return
+			}
+		}
+
+		traceDecision(c, "reputation", "allowed")
+		c.Next()
+
+		// Validation errors, rate limiting and upstream failures are not
+		// login outcomes
+		status := c.Writer.Status()
+		failed := status == http.StatusUnauthorized || status == http.StatusForbidden
+		if status >= 400 && !failed {
+			return
+		}
+
+		for _, key := range keys {
+			if err := reputationTracker.record(ctx, key, !failed); err != nil {
+				logging.Logger().WithError(err).WithField("key", key).Warn("Failed to record login reputation")
+			}
+		}
+	}
+}
+
+// reputationKeys returns the tracking keys for the client IP and device fingerprint
+func reputationKeys(c *gin.Context) []string {
+	return []string{
+		"ip:" + c.ClientIP(),
+		"device:" + deviceFingerprint(c),
+	}
+}
+
+// deviceFingerprint identifies the client device, preferring an explicit fingerprint header
+func deviceFingerprint(c *gin.Context) string {
+	if fp := c.GetHeader("X-Device-Fingerprint"); fp != "" {
+		return fp
+	}
+
+	hash := sha256.Sum256([]byte(c.Request.UserAgent() + "|" + c.GetHeader("Accept-Language")))
+	return hex.EncodeToString(hash[:8])
+}
+
+// remoteChallengeVerifier verifies the X-Challenge-Token header against an external endpoint
+func remoteChallengeVerifier(url string) ChallengeVerifier {
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	return func(c *gin.Context) bool {
+		token := c.GetHeader("X-Challenge-Token")
+		if token == "" {
+			return false
+		}
+
+		payload, err := json.Marshal(map[string]string{
+			"token":     token,
+			"remote_ip": c.ClientIP(),
+		})
+		if err != nil {
+			return false
+		}
+
+		resp, err := client.Post(url, "application/json", bytes.NewBuffer(payload))
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+
+		return resp.StatusCode >= 200 && resp.StatusCode < 300
+	}
+}
+
+// GetReputationStatus returns all tracked reputation entries, lowest score first
+func GetReputationStatus(ctx context.Context) ([]ReputationStatus, error) {
+	if reputationTracker == nil {
+		return []ReputationStatus{}, nil
+	}
+
+	entries, err := reputationTracker.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]ReputationStatus, 0, len(entries))
+	for key, entry := range entries {
+		score := reputationTracker.recovered(entry)
+		status = append(status, ReputationStatus{
+			Key:         key,
+			Score:       score,
+			Failures:    entry.Failures,
+			Successes:   entry.Successes,
+			Decision:    reputationTracker.decision(score),
+			LastUpdated: entry.LastUpdated.Unix(),
+		})
+	}
+
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].Score < status[j].Score
+	})
+
+	return status, nil
+}
+
+// ErrReputationNotFound is returned when resetting a key without an entry
+var ErrReputationNotFound = errors.New("no reputation entry")
+
+// ResetReputation clears the reputation entry for a key
+func ResetReputation(ctx context.Context, key string) error {
+	if reputationTracker == nil {
+		return fmt.Errorf("reputation tracking is not enabled")
+	}
+
+	existed, err := reputationTracker.store.Delete(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !existed {
+		return fmt.Errorf("%w for %s", ErrReputationNotFound, key)
+	}
+	return nil
+}
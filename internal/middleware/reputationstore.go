@@ -0,0 +1,238 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ReputationEntry is the stored reputation of one IP or device
+type ReputationEntry struct {
+	Score       int       `json:"score"`
+	Failures    int64     `json:"failures"`
+	Successes   int64     `json:"successes"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// ReputationStore persists login reputation entries so scores are shared
+// between replicas and survive restarts. Entries are kept until ttl after
+// their last update, by which time they have fully recovered.
+type ReputationStore interface {
+	// Get returns the entry of a key
+	Get(ctx context.Context, key string) (ReputationEntry, bool, error)
+	// Update changes the entry of a key atomically; exists is false for a
+	// key without an entry
+	Update(ctx context.Context, key string, ttl time.Duration, update func(entry *ReputationEntry, exists bool)) error
+	// List returns every stored entry by key
+	List(ctx context.Context) (map[string]ReputationEntry, error)
+	// Delete removes the entry of a key and reports whether there was one
+	Delete(ctx context.Context, key string) (bool, error)
+	// Cleanup removes expired entries and returns how many were removed
+	Cleanup(ctx context.Context) (int, error)
+}
+
+// NewReputationStore creates a reputation store for the configured backend
+func NewReputationStore(backend, redisURL string) (ReputationStore, error) {
+	switch backend {
+	case "", "memory":
+		return NewMemoryReputationStore(), nil
+	case "redis":
+		return NewRedisReputationStore(redisURL)
+	default:
+		return nil, fmt.Errorf("unknown reputation backend: %s", backend)
+	}
+}
+
+// memoryReputationEntry is an entry with its expiry
+type memoryReputationEntry struct {
+	entry     ReputationEntry
+	expiresAt time.Time
+}
+
+// MemoryReputationStore keeps reputation entries in process memory, so
+// every replica scores only the attempts it served
+type MemoryReputationStore struct {
+	entries map[string]memoryReputationEntry
+	mu      sync.RWMutex
+}
+
+// NewMemoryReputationStore creates an in-memory reputation store
+func NewMemoryReputationStore() *MemoryReputationStore {
+	return &MemoryReputationStore{entries: make(map[string]memoryReputationEntry)}
+}
+
+// Get returns the entry of a key
+func (s *MemoryReputationStore) Get(ctx context.Context, key string) (ReputationEntry, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stored, exists := s.entries[key]
+	if !exists || stored.expiresAt.Before(time.Now()) {
+		return ReputationEntry{}, false, nil
+	}
+	return stored.entry, true, nil
+}
+
+// Update changes the entry of a key under the store lock
+func (s *MemoryReputationStore) Update(ctx context.Context, key string, ttl time.Duration, update func(entry *ReputationEntry, exists bool)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, exists := s.entries[key]
+	exists = exists && !stored.expiresAt.Before(time.Now())
+	if !exists {
+		stored.entry = ReputationEntry{}
+	}
+	update(&stored.entry, exists)
+	stored.expiresAt = time.Now().Add(ttl)
+	s.entries[key] = stored
+	return nil
+}
+
+// List returns every entry that has not expired
+func (s *MemoryReputationStore) List(ctx context.Context) (map[string]ReputationEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := time.Now()
+	entries := make(map[string]ReputationEntry, len(s.entries))
+	for key, stored := range s.entries {
+		if !stored.expiresAt.Before(now) {
+			entries[key] = stored.entry
+		}
+	}
+	return entries, nil
+}
+
+// Delete removes the entry of a key
+func (s *MemoryReputationStore) Delete(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.entries[key]
+	delete(s.entries, key)
+	return exists, nil
+}
+
+// Cleanup removes expired entries
+func (s *MemoryReputationStore) Cleanup(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	now := time.Now()
+	for key, stored := range s.entries {
+		if stored.expiresAt.Before(now) {
+			delete(s.entries, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// maxReputationRetries bounds the retries of a Redis update that lost a
+// race with another replica
+const maxReputationRetries = 5
+
+// RedisReputationStore shares reputation entries between replicas, as JSON
+// values that Redis expires once they have fully recovered
+type RedisReputationStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisReputationStore connects to Redis using a redis:// URL
+func NewRedisReputationStore(redisURL string) (*RedisReputationStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &RedisReputationStore{client: client, prefix: "internal-api:reputation:"}, nil
+}
+
+// Get returns the entry of a key
+func (s *RedisReputationStore) Get(ctx context.Context, key string) (ReputationEntry, bool, error) {
+	return s.get(ctx, s.client, key)
+}
+
+// get reads an entry with a client or a transaction
+func (s *RedisReputationStore) get(ctx context.Context, client redis.Cmdable, key string) (ReputationEntry, bool, error) {
+	data, err := client.Get(ctx, s.prefix+key).Bytes()
+	if err == redis.Nil {
+		return ReputationEntry{}, false, nil
+	}
+	if err != nil {
+		return ReputationEntry{}, false, err
+	}
+	var entry ReputationEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return ReputationEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// Update changes the entry of a key in an optimistic transaction, retried
+// when another replica changed the entry in between
+func (s *RedisReputationStore) Update(ctx context.Context, key string, ttl time.Duration, update func(entry *ReputationEntry, exists bool)) error {
+	txf := func(tx *redis.Tx) error {
+		entry, exists, err := s.get(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		update(&entry, exists)
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.Set(ctx, s.prefix+key, data, ttl).Err()
+		})
+		return err
+	}
+
+	for i := 0; i < maxReputationRetries; i++ {
+		err := s.client.Watch(ctx, txf, s.prefix+key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return fmt.Errorf("reputation entry %s kept changing", key)
+}
+
+// List returns every stored entry by key
+func (s *RedisReputationStore) List(ctx context.Context) (map[string]ReputationEntry, error) {
+	entries := make(map[string]ReputationEntry)
+	iter := s.client.Scan(ctx, 0, s.prefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		key := strings.TrimPrefix(iter.Val(), s.prefix)
+		entry, exists, err := s.get(ctx, s.client, key)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			entries[key] = entry
+		}
+	}
+	return entries, iter.Err()
+}
+
+// Delete removes the entry of a key
+func (s *RedisReputationStore) Delete(ctx context.Context, key string) (bool, error) {
+	n, err := s.client.Del(ctx, s.prefix+key).Result()
+	return n > 0, err
+}
+
+// Cleanup is a no-op because Redis expires entries itself
+func (s *RedisReputationStore) Cleanup(ctx context.Context) (int, error) {
+	return 0, nil
+}
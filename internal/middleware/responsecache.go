@@ -0,0 +1,445 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/cache"
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/permissions"
+	"InternalAPI/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+var responseCacheRequests = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "response_cache_requests_total",
+	Help:      "Cacheable reads by route and result (hit, miss, bypass)",
+}, []string{"route", "result"})
+
+// CachedResponse is a stored 200 response of a cached read
+type CachedResponse struct {
+	ContentType     string    `json:"content_type"`
+	ContentLanguage string    `json:"content_language,omitempty"`
+	ETag            string    `json:"etag"`
+	Body            []byte    `json:"body"`
+	StoredAt        time.Time `json:"stored_at"`
+}
+
+// ResponseCacheStore keeps cached read responses. Entries belong to a
+// resource such as "albums"; a write to the resource invalidates all of them.
+type ResponseCacheStore interface {
+	// Get returns a cached response
+	Get(ctx context.Context, resource, key string) (CachedResponse, bool, error)
+	// Set stores a response for ttl
+	Set(ctx context.Context, resource, key string, response CachedResponse, ttl time.Duration) error
+	// Invalidate drops every cached response of a resource
+	Invalidate(ctx context.Context, resource string) error
+}
+
+// NewResponseCacheStore creates a response cache store for the configured backend
+func NewResponseCacheStore(backend, redisURL string) (ResponseCacheStore, error) {
+	switch backend {
+	case "", "memory":
+		return NewMemoryResponseCacheStore(), nil
+	case "redis":
+		return NewRedisResponseCacheStore(redisURL)
+	default:
+		return nil, fmt.Errorf("unknown response cache backend: %s", backend)
+	}
+}
+
+// MemoryResponseCacheStore keeps responses in process memory, so every
+// replica has its own cache and sees only its own writes
+type MemoryResponseCacheStore struct {
+	entries *cache.Cache
+}
+
+// NewMemoryResponseCacheStore creates an in-memory response cache
+func NewMemoryResponseCacheStore() *MemoryResponseCacheStore {
+	return &MemoryResponseCacheStore{entries: cache.New()}
+}
+
+// Get returns a cached response
+func (s *MemoryResponseCacheStore) Get(ctx context.Context, resource, key string) (CachedResponse, bool, error) {
+	entry, ok := s.entries.Get(resource + "|" + key)
+	if !ok {
+		return CachedResponse{}, false, nil
+	}
+	return entry.(CachedResponse), true, nil
+}
+
+// Set stores a response for ttl
+func (s *MemoryResponseCacheStore) Set(ctx context.Context, resource, key string, response CachedResponse, ttl time.Duration) error {
+	s.entries.Set(resource+"|"+key, response, ttl)
+	return nil
+}
+
+// Invalidate drops every cached response of a resource
+func (s *MemoryResponseCacheStore) Invalidate(ctx context.Context, resource string) error {
+	s.entries.DeletePrefix(resource + "|")
+	return nil
+}
+
+// RedisResponseCacheStore shares cached responses between replicas. Keys
+// include a per-resource generation; invalidating bumps the generation, so
+// a write on one replica hides the old entries from all of them and Redis
+// expires them in time.
+type RedisResponseCacheStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisResponseCacheStore connects to Redis using a redis:// URL
+func NewRedisResponseCacheStore(redisURL string) (*RedisResponseCacheStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &RedisResponseCacheStore{client: client, prefix: "internal-api:response-cache:"}, nil
+}
+
+// entryKey returns the key of a response under the resource's current generation
+func (s *RedisResponseCacheStore) entryKey(ctx context.Context, resource, key string) (string, error) {
+	generation, err := s.client.Get(ctx, s.prefix+resource+":generation").Int64()
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+	return s.prefix + resource + ":" + strconv.FormatInt(generation, 10) + ":" + key, nil
+}
+
+// Get returns a cached response
+func (s *RedisResponseCacheStore) Get(ctx context.Context, resource, key string) (CachedResponse, bool, error) {
+	entryKey, err := s.entryKey(ctx, resource, key)
+	if err != nil {
+		return CachedResponse{}, false, err
+	}
+	data, err := s.client.Get(ctx, entryKey).Bytes()
+	if err == redis.Nil {
+		return CachedResponse{}, false, nil
+	}
+	if err != nil {
+		return CachedResponse{}, false, err
+	}
+	var response CachedResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return CachedResponse{}, false, err
+	}
+	return response, true, nil
+}
+
+// Set stores a response for ttl
+func (s *RedisResponseCacheStore) Set(ctx context.Context, resource, key string, response CachedResponse, ttl time.Duration) error {
+	entryKey, err := s.entryKey(ctx, resource, key)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, entryKey, data, ttl).Err()
+}
+
+// Invalidate drops every cached response of a resource
+func (s *RedisResponseCacheStore) Invalidate(ctx context.Context, resource string) error {
+	return s.client.Incr(ctx, s.prefix+resource+":generation").Err()
+}
+
+var (
+	responseCache      ResponseCacheStore
+	responseCacheTTL   time.Duration
+	responseCacheRoute map[string]time.Duration // Route path to TTL; 0 disables caching
+	responseCacheMu    sync.RWMutex
+
+	// invalidations counts local invalidations per resource, so a read that
+	// overlapped a write does not store what it fetched before the write
+	invalidations   = make(map[string]uint64)
+	invalidationsMu sync.Mutex
+)
+
+// InitResponseCache enables caching on the routes that use CacheResponse.
+// Routes in routeTTLs use their own TTL, others defaultTTL.
+func InitResponseCache(store ResponseCacheStore, defaultTTL time.Duration, routeTTLs map[string]time.Duration) {
+	responseCacheMu.Lock()
+	defer responseCacheMu.Unlock()
+	responseCache = store
+	responseCacheTTL = defaultTTL
+	responseCacheRoute = routeTTLs
+}
+
+// ParseRouteTTLs parses a spec like "/api/v1/albums=60,/api/v1/albums/:id=300"
+// with TTLs in seconds
+func ParseRouteTTLs(spec string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, seconds, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("route TTL %q is missing =seconds", entry)
+		}
+		ttl, err := strconv.Atoi(strings.TrimSpace(seconds))
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid TTL for route %s: %q", route, seconds)
+		}
+		ttls[strings.TrimSpace(route)] = time.Duration(ttl) * time.Second
+	}
+	return ttls, nil
+}
+
+// cacheSettings returns the store and the TTL of a route
+func cacheSettings(route string) (ResponseCacheStore, time.Duration) {
+	responseCacheMu.RLock()
+	defer responseCacheMu.RUnlock()
+	if ttl, ok := responseCacheRoute[route]; ok {
+		return responseCache, ttl
+	}
+	return responseCache, responseCacheTTL
+}
+
+// InvalidateResponseCache drops the cached reads of a resource, e.g. after
+// an upstream callback reports a change made outside the gateway
+func InvalidateResponseCache(resource string) {
+	invalidationsMu.Lock()
+	invalidations[resource]++
+	invalidationsMu.Unlock()
+
+	store, _ := cacheSettings("")
+	if store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := store.Invalidate(ctx, resource); err != nil {
+		logging.Logger().WithError(err).WithField("resource", resource).Warn("Failed to invalidate response cache")
+	}
+}
+
+// CacheResponse caches successful GET responses of a route. It must come
+// after the route's scope and permission checks, which still run on every
+// request. Entries vary on the full URL, the user's roles and Central
+// Management filters for resource, and the locale and tenant headers, so
+// users only share an entry when they would get the same response. Cached
+// responses carry an ETag and X-Cache; If-None-Match is answered with 304.
+// Requests with Cache-Control: no-cache skip the lookup and refresh the entry.
+func CacheResponse(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		store, ttl := cacheSettings(route)
+		if store == nil || ttl <= 0 || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		key, ok := responseCacheKey(c, resource)
+		if !ok {
+			responseCacheRequests.WithLabelValues(route, "bypass").Inc()
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+		defer cancel()
+
+		if !strings.Contains(strings.ToLower(c.GetHeader("Cache-Control")), "no-cache") {
+			cached, hit, err := store.Get(ctx, resource, key)
+			if err != nil {
+				logging.For(c).WithError(err).WithField("route", route).Warn("Response cache lookup failed")
+			}
+			if hit {
+				responseCacheRequests.WithLabelValues(route, "hit").Inc()
+				c.Header("Age", strconv.Itoa(int(time.Since(cached.StoredAt).Seconds())))
+				writeCachedResponse(c, cached, "HIT")
+				c.Abort()
+				return
+			}
+		}
+		responseCacheRequests.WithLabelValues(route, "miss").Inc()
+
+		before := invalidationCount(resource)
+		writer := &bufferedResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		// Fallbacks served while a breaker is open must not outlive it
+		if writer.Status() != http.StatusOK || services.DegradationFrom(c.Request.Context()).Kind() != "" {
+			writer.flush()
+			return
+		}
+
+		response := CachedResponse{
+			ContentType:     writer.Header().Get("Content-Type"),
+			ContentLanguage: writer.Header().Get("Content-Language"),
+			ETag:            writer.Header().Get("ETag"),
+			Body:            writer.body.Bytes(),
+			StoredAt:        time.Now(),
+		}
+		if response.ETag == "" {
+			response.ETag = computeETag(response.Body, StrongETag)
+		}
+		if invalidationCount(resource) == before {
+			if err := store.Set(ctx, resource, key, response, ttl); err != nil {
+				logging.For(c).WithError(err).WithField("route", route).Warn("Failed to store cached response")
+			}
+		}
+		writeCachedResponse(c, response, "MISS")
+	}
+}
+
+// invalidationCount returns how often resource was invalidated locally
+func invalidationCount(resource string) uint64 {
+	invalidationsMu.Lock()
+	defer invalidationsMu.Unlock()
+	return invalidations[resource]
+}
+
+// InvalidatesCache drops the cached reads of resource after every
+// successful write through the routes it guards
+func InvalidatesCache(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			return
+		}
+		if status := c.Writer.Status(); status < 400 {
+			InvalidateResponseCache(resource)
+		}
+	}
+}
+
+// responseCacheKey derives the cache key of a read from everything the
+// response depends on. Filters are looked up for signed-in users when
+// resource is set. It returns false when the user's filters cannot be
+// loaded; the handler then reports that error itself.
+func responseCacheKey(c *gin.Context, resource string) (string, bool) {
+	var variant struct {
+		URL      string                 `json:"url"`
+		Roles    []string               `json:"roles,omitempty"`
+		Service  bool                   `json:"service,omitempty"`
+		Filter   permissions.ListFilter `json:"filter"`
+		Fields   []string               `json:"fields,omitempty"`
+		Locale   string                 `json:"locale,omitempty"`
+		Tenant   string                 `json:"tenant,omitempty"`
+		Accept   string                 `json:"accept,omitempty"`
+		Resource string                 `json:"resource"`
+	}
+	variant.URL = c.Request.URL.RequestURI()
+	variant.Locale = c.GetHeader("Accept-Language")
+	variant.Tenant = c.GetHeader("X-Tenant-ID")
+	variant.Accept = c.GetHeader("Accept")
+	variant.Resource = resource
+
+	if user, ok := c.Get("user"); ok {
+		if info, ok := user.(*models.UserInfo); ok {
+			variant.Roles = append([]string(nil), info.Roles...)
+			sort.Strings(variant.Roles)
+		}
+	}
+
+	if _, isService := c.Get("api_key"); isService {
+		variant.Service = true
+	} else if permissions.Enabled() && resource != "" && c.GetString("userID") != "" {
+		userID := c.GetString("userID")
+		filter, err := permissions.Filters(c.Request.Context(), userID, resource)
+		if err != nil {
+			return "", false
+		}
+		fields, err := permissions.Fields(c.Request.Context(), userID, resource)
+		if err != nil {
+			return "", false
+		}
+		variant.Filter = filter
+		variant.Fields = append([]string(nil), fields.Hidden...)
+		sort.Strings(variant.Fields)
+	}
+
+	raw, err := json.Marshal(variant)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// writeCachedResponse sends a cached or freshly stored response, or 304
+// when the client already holds it
+func writeCachedResponse(c *gin.Context, response CachedResponse, source string) {
+	c.Header("X-Cache", source)
+	c.Header("ETag", response.ETag)
+	if response.ContentLanguage != "" {
+		c.Header("Content-Language", response.ContentLanguage)
+	}
+
+	if etagMatches(c.GetHeader("If-None-Match"), response.ETag) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Data(http.StatusOK, response.ContentType, response.Body)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag. Weak
+// validators compare equal to strong ones, as RFC 9110 asks for GET.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponseWriter holds back a response body until the middleware
+// that installed it has decided what to send
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write buffers the body
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// WriteString buffers the body
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// flush sends the response as the handler wrote it
+func (w *bufferedResponseWriter) flush() {
+	w.ResponseWriter.WriteHeaderNow()
+	w.ResponseWriter.Write(w.body.Bytes())
+}
@@ -0,0 +1,215 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// SchemaMode selects which directions of a route are checked against the
+// OpenAPI document
+type SchemaMode string
+
+const (
+	// SchemaOff skips validation for the route
+	SchemaOff SchemaMode = "off"
+	// SchemaRequest rejects request bodies that do not match the document
+	SchemaRequest SchemaMode = "request"
+	// SchemaResponse logs responses that do not match the document
+	SchemaResponse SchemaMode = "response"
+	// SchemaFull does both
+	SchemaFull SchemaMode = "full"
+)
+
+var (
+	schemaDoc         *openapi.Document
+	defaultSchemaMode = SchemaOff
+	routeSchemaModes  = map[string]SchemaMode{}
+	schemaModeMu      sync.RWMutex
+
+	schemaFailures = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "schema_validation_failures_total",
+		Help:      "Bodies that did not match the OpenAPI document, by direction and route",
+	}, []string{"direction", "route"})
+)
+
+// ParseSchemaMode validates a schema validation mode name
+func ParseSchemaMode(name string) (SchemaMode, error) {
+	switch mode := SchemaMode(strings.ToLower(strings.TrimSpace(name))); mode {
+	case SchemaOff, SchemaRequest, SchemaResponse, SchemaFull:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown schema validation mode %q (use off, request, response or full)", name)
+}
+
+// InitSchemaValidation sets the OpenAPI document, the default mode and the
+// per-route overrides, given as comma-separated "METHOD /path=mode" entries
+func InitSchemaValidation(doc *openapi.Document, defaultMode, routes string) error {
+	mode, err := ParseSchemaMode(defaultMode)
+	if err != nil {
+		return err
+	}
+
+	overrides := map[string]SchemaMode{}
+	for _, entry := range strings.Split(routes, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		route, name, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid schema validation route %q (expected \"METHOD /path=mode\")", entry)
+		}
+		key, err := normalizeCaptureRoute(route)
+		if err != nil {
+			return err
+		}
+		routeMode, err := ParseSchemaMode(name)
+		if err != nil {
+			return err
+		}
+		overrides[key] = routeMode
+	}
+
+	schemaModeMu.Lock()
+	defer schemaModeMu.Unlock()
+	schemaDoc = doc
+	defaultSchemaMode = mode
+	routeSchemaModes = overrides
+	return nil
+}
+
+// schemaModeFor returns the validation mode for a matched route
+func schemaModeFor(method, route string) SchemaMode {
+	schemaModeMu.RLock()
+	defer schemaModeMu.RUnlock()
+
+	if mode, ok := routeSchemaModes[method+" "+route]; ok {
+		return mode
+	}
+	if mode, ok := routeSchemaModes["* "+route]; ok {
+		return mode
+	}
+	return defaultSchemaMode
+}
+
+// SchemaValidation checks request and response bodies of documented routes
+// against the OpenAPI document. Invalid requests get a 400 listing each
+// problem with a JSON pointer; invalid responses are logged and counted but
+// still sent, since the client cannot fix them.
+func SchemaValidation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		schemaModeMu.RLock()
+		doc := schemaDoc
+		schemaModeMu.RUnlock()
+
+		route := c.FullPath()
+		mode := schemaModeFor(c.Request.Method, route)
+		op := (*openapi.Operation)(nil)
+		if doc != nil {
+			op = doc.Operation(c.Request.Method, route)
+		}
+		if op == nil || mode == SchemaOff || isStreaming(c) {
+			c.Next()
+			return
+		}
+
+		if mode == SchemaRequest || mode == SchemaFull {
+			if errs, ok := validateRequestBody(c, doc, op); !ok {
+				traceDecision(c, "schema_validation", fmt.Sprintf("request rejected with %d violation(s)", len(errs)))
+				schemaFailures.WithLabelValues("request", route).Inc()
+				violations := make([]models.Violation, len(errs))
+				for i, e := range errs {
+					violations[i] = models.Violation{Field: e.Pointer, Rule: e.Rule, Message: e.Message}
+				}
+				c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+					Code:       "SCHEMA_VALIDATION_FAILED",
+					Message:    "The request body does not match the API schema",
+					Violations: violations,
+					Timestamp:  time.Now().Unix(),
+				})
+				return
+			}
+		}
+
+		if mode != SchemaResponse && mode != SchemaFull {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &jsonBufferWriter{ResponseWriter: original}
+		c.Writer = writer
+		c.Next()
+		c.Writer = original
+
+		if writer.passthrough || writer.body.Len() == 0 {
+			return
+		}
+		if schema := op.ResponseSchema(original.Status()); schema != nil {
+			var body interface{}
+			if err := json.Unmarshal(writer.body.Bytes(), &body); err == nil {
+				if errs := doc.Validate(schema, body); len(errs) > 0 {
+					schemaFailures.WithLabelValues("response", route).Inc()
+					logging.For(c).WithFields(logrus.Fields{
+						"route":      c.Request.Method + " " + route,
+						"status":     original.Status(),
+						"violations": errs,
+					}).Warn("Response does not match the API schema")
+				}
+			}
+		}
+		original.Write(writer.body.Bytes())
+	}
+}
+
+// validateRequestBody checks the request body against the operation's
+// schema for its content type and restores the body for the handler
+func validateRequestBody(c *gin.Context, doc *openapi.Document, op *openapi.Operation) ([]openapi.ValidationError, bool) {
+	mediaType := ""
+	if contentType := c.GetHeader("Content-Type"); contentType != "" {
+		mediaType, _, _ = mime.ParseMediaType(contentType)
+	}
+	schema, required := op.RequestSchema(mediaType)
+	if schema == nil {
+		return nil, true
+	}
+
+	var raw []byte
+	if c.Request.Body != nil {
+		var err error
+		if raw, err = io.ReadAll(c.Request.Body); err != nil {
+			return []openapi.ValidationError{{Rule: "body", Message: "request body could not be read"}}, false
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+	}
+
+	if len(bytes.TrimSpace(raw)) == 0 {
+		if required {
+			return []openapi.ValidationError{{Rule: "required", Message: "request body is required"}}, false
+		}
+		return nil, true
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return []openapi.ValidationError{{Rule: "json", Message: "request body is not valid JSON: " + err.Error()}}, false
+	}
+	errs := doc.Validate(schema, body)
+	return errs, len(errs) == 0
+}
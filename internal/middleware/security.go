@@ -2,13 +2,26 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
+	"time"
+
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 )
 
-// SecurityHeaders adds security headers to all responses
-func SecurityHeaders() gin.HandlerFunc {
+// SecurityHeaders adds security headers to all responses. A positive
+// hstsMaxAge enables Strict-Transport-Security and should only be set when
+// the server terminates TLS.
+func SecurityHeaders(hstsMaxAge time.Duration) gin.HandlerFunc {
+	hsts := ""
+	if hstsMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(hstsMaxAge.Seconds())) + "; includeSubDomains"
+	}
+
 	return func(c *gin.Context) {
 		// Prevent clickjacking
 		c.Header("X-Frame-Options", "DENY")
@@ -33,8 +46,9 @@ func SecurityHeaders() gin.HandlerFunc {
 		c.Header("Permissions-Policy", "geolocation=(), microphone=(), camera=()")
 		
 		// HSTS (HTTP Strict Transport Security) - only if using HTTPS
-		// Uncomment when HTTPS is enabled:
-		// c.Header("Strict-Transport-Security", "max-age=31536000; includeSubDomains; preload")
+		if hsts != "" {
+			c.Header("Strict-Transport-Security", hsts)
+		}
 		
 		c.Next()
 	}
@@ -49,9 +63,18 @@ func RequestID() gin.HandlerFunc {
 			requestID = uuid.New().String()
 		}
 
-		// Set request ID in context and response header
+		// Set request ID in context and response header; backend calls
+		// made with the request context forward it
 		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(services.WithRequestID(c.Request.Context(), requestID))
 		c.Header("X-Request-ID", requestID)
+
+		// Every log line written for this request carries its ID
+		fields := logrus.Fields{"request_id": requestID}
+		if tenant := c.GetHeader("X-Tenant-ID"); tenant != "" {
+			fields["tenant"] = tenant
+		}
+		logging.AddFields(c, fields)
 		
 		c.Next()
 	}
@@ -60,7 +83,14 @@ func RequestID() gin.HandlerFunc {
 // RequestSizeLimit limits the size of request bodies
 func RequestSizeLimit(maxBytes int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if isStreaming(c) {
+			traceDecision(c, "size_limit", "skipped: streaming route")
+			c.Next()
+			return
+		}
+
 		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		traceDecision(c, "size_limit", "limited to "+strconv.FormatInt(maxBytes, 10)+" bytes")
 		c.Next()
 	}
 }
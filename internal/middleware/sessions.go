@@ -0,0 +1,234 @@
+package middleware
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Session is an access token seen for a user
+type Session struct {
+	TokenID   string    `json:"token_id"`
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	LastSeen  time.Time `json:"last_seen"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+
+	tokenHash string
+}
+
+// UserSessions summarizes the active sessions of a user
+type UserSessions struct {
+	UserID   string    `json:"user_id"`
+	Username string    `json:"username"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// userRevocationLifetime is how long a user's revocation cutoff is kept at
+// least. It outlives the access tokens we issue, so every token issued before
+// the cutoff has expired by the time it is dropped.
+const userRevocationLifetime = 24 * time.Hour
+
+// TokenRegistry tracks the access tokens issued to or used by each user so
+// admins can see and revoke them. Revoking a user also rejects tokens the
+// registry never saw if they were issued before the revocation; that cutoff
+// is kept in the blacklist store so every replica enforces it.
+type TokenRegistry struct {
+	sessions map[string]map[string]*Session // user ID -> token hash -> session
+	mu       sync.RWMutex
+}
+
+// NewTokenRegistry creates an empty token registry
+func NewTokenRegistry() *TokenRegistry {
+	return &TokenRegistry{
+		sessions: make(map[string]map[string]*Session),
+	}
+}
+
+// Track records a token for its user, or refreshes its last-seen time
+func (r *TokenRegistry) Track(claims *Claims, tokenString, clientIP, userAgent string) {
+	if claims.UserID == "" {
+		return
+	}
+	hash := hashToken(tokenString)
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	userSessions, ok := r.sessions[claims.UserID]
+	if !ok {
+		userSessions = make(map[string]*Session)
+		r.sessions[claims.UserID] = userSessions
+	}
+
+	if session, ok := userSessions[hash]; ok {
+		session.LastSeen = now
+		if clientIP != "" {
+			session.ClientIP = clientIP
+		}
+		return
+	}
+
+	session := &Session{
+		TokenID:   claims.ID,
+		UserID:    claims.UserID,
+		Username:  claims.Username,
+		LastSeen:  now,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+		tokenHash: hash,
+	}
+	if session.TokenID == "" {
+		session.TokenID = hash[:16]
+	}
+	if claims.IssuedAt != nil {
+		session.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		session.ExpiresAt = claims.ExpiresAt.Time
+	}
+	userSessions[hash] = session
+}
+
+// IsRevoked reports whether a user's sessions were revoked after the token was issued
+func (r *TokenRegistry) IsRevoked(claims *Claims) (bool, error) {
+	if claims.UserID == "" {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	cutoff, ok, err := tokenBlacklist.UserRevokedUntil(ctx, claims.UserID)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	// Tokens without an issue time cannot prove they are newer than the revocation
+	if claims.IssuedAt == nil {
+		return true, nil
+	}
+	return claims.IssuedAt.Time.Before(cutoff), nil
+}
+
+// Sessions returns a user's unexpired sessions, most recently used first
+func (r *TokenRegistry) Sessions(userID string) []Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pruneUser(userID, time.Now())
+	list := make([]Session, 0, len(r.sessions[userID]))
+	for _, session := range r.sessions[userID] {
+		list = append(list, *session)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastSeen.After(list[j].LastSeen)
+	})
+	return list
+}
+
+// Users summarizes every user with at least one unexpired session
+func (r *TokenRegistry) Users() []UserSessions {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	list := make([]UserSessions, 0, len(r.sessions))
+	for userID := range r.sessions {
+		r.pruneUser(userID, now)
+		sessions, ok := r.sessions[userID]
+		if !ok {
+			continue
+		}
+
+		summary := UserSessions{UserID: userID, Count: len(sessions)}
+		for _, session := range sessions {
+			summary.Username = session.Username
+			if session.LastSeen.After(summary.LastSeen) {
+				summary.LastSeen = session.LastSeen
+			}
+		}
+		list = append(list, summary)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastSeen.After(list[j].LastSeen)
+	})
+	return list
+}
+
+// RevokeUser revokes every token issued to a user up to now. Known tokens are
+// also added to the blacklist store so other instances reject them. It
+// returns the sessions that were revoked.
+func (r *TokenRegistry) RevokeUser(userID string) ([]Session, error) {
+	// Issue times have second precision, so revoke the whole current second
+	cutoff := time.Now().Truncate(time.Second).Add(time.Second)
+	keepUntil := cutoff.Add(userRevocationLifetime)
+
+	r.mu.Lock()
+	revoked := make([]Session, 0, len(r.sessions[userID]))
+	for _, session := range r.sessions[userID] {
+		revoked = append(revoked, *session)
+		if session.ExpiresAt.After(keepUntil) {
+			keepUntil = session.ExpiresAt
+		}
+	}
+	delete(r.sessions, userID)
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tokenBlacklist.RevokeUser(ctx, userID, cutoff, keepUntil); err != nil {
+		return revoked, err
+	}
+	for _, session := range revoked {
+		expiresAt := session.ExpiresAt
+		if expiresAt.IsZero() {
+			expiresAt = time.Now().Add(24 * time.Hour)
+		}
+		if err := tokenBlacklist.Add(ctx, session.tokenHash, expiresAt); err != nil {
+			return revoked, err
+		}
+		afterBlacklistInsert(tokenBlacklist)
+	}
+	return revoked, nil
+}
+
+// pruneUser drops a user's expired sessions. Callers must hold r.mu.
+func (r *TokenRegistry) pruneUser(userID string, now time.Time) {
+	for hash, session := range r.sessions[userID] {
+		if !session.ExpiresAt.IsZero() && now.After(session.ExpiresAt) {
+			delete(r.sessions[userID], hash)
+		}
+	}
+	if len(r.sessions[userID]) == 0 {
+		delete(r.sessions, userID)
+	}
+}
+
+// Global token registry consulted by JWT authentication
+var tokenRegistry = NewTokenRegistry()
+
+// TrackSession records a freshly issued token so it shows up before first use
+func TrackSession(claims *Claims, tokenString string) {
+	tokenRegistry.Track(claims, tokenString, "", "")
+}
+
+// ListSessions returns a user's active sessions
+func ListSessions(userID string) []Session {
+	return tokenRegistry.Sessions(userID)
+}
+
+// ListSessionUsers summarizes all users with active sessions
+func ListSessionUsers() []UserSessions {
+	return tokenRegistry.Users()
+}
+
+// RevokeUserSessions revokes every access token of a user
+func RevokeUserSessions(userID string) ([]Session, error) {
+	return tokenRegistry.RevokeUser(userID)
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"time"
+
+	"InternalAPI/internal/slo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SLOTracking counts every finished request against the objective of its
+// route group. Streams are left out, since their duration says nothing
+// about latency.
+func SLOTracking() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+		if isStreaming(c) {
+			return
+		}
+		slo.Record(c.Request.URL.Path, c.Writer.Status(), time.Since(started))
+	}
+}
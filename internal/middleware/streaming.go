@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	streamingRoutes   = make(map[string]bool)
+	streamingRoutesMu sync.RWMutex
+)
+
+// RegisterStreamingRoute marks a route as a long-lived stream. Streaming
+// routes skip the overall request size limit and audit body capture; their
+// handlers enforce per-message limits instead.
+func RegisterStreamingRoute(method, path string) {
+	streamingRoutesMu.Lock()
+	defer streamingRoutesMu.Unlock()
+	streamingRoutes[method+" "+path] = true
+}
+
+// isStreaming reports whether the matched route was registered as streaming
+func isStreaming(c *gin.Context) bool {
+	streamingRoutesMu.RLock()
+	defer streamingRoutesMu.RUnlock()
+	return streamingRoutes[c.Request.Method+" "+c.FullPath()]
+}
+
+// OpenStream prepares a streaming response: it lifts the server read and
+// write deadlines, lets the handler read the request while writing the
+// response, and extends the deadlines by idle each time the returned
+// function is called.
+func OpenStream(c *gin.Context, idle time.Duration) (touch func(), err error) {
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.EnableFullDuplex(); err != nil && err != http.ErrNotSupported {
+		return nil, err
+	}
+
+	touch = func() {
+		deadline := time.Now().Add(idle)
+		rc.SetReadDeadline(deadline)
+		rc.SetWriteDeadline(deadline)
+	}
+	touch()
+	return touch, nil
+}
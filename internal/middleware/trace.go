@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// TraceHeader enables per-request middleware tracing when set to "1"
+const TraceHeader = "X-Debug-Trace"
+
+const traceContextKey = "middleware_trace"
+
+// TraceStep is a single middleware decision recorded during a traced request
+type TraceStep struct {
+	Middleware string  `json:"middleware"`
+	Decision   string  `json:"decision"`
+	LatencyMs  float64 `json:"latency_ms"` // Time since the previous step
+}
+
+type requestTrace struct {
+	start time.Time
+	last  time.Time
+	steps []TraceStep
+}
+
+// RouteChain is the effective handler chain for a registered route
+type RouteChain struct {
+	Method   string   `json:"method"`
+	Path     string   `json:"path"`
+	Handlers []string `json:"handlers"`
+}
+
+// RequestTrace logs each middleware's decision and latency for requests
+// carrying the X-Debug-Trace header. It must be registered first so it
+// observes the whole chain, and should only be enabled outside production.
+func RequestTrace(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled || c.GetHeader(TraceHeader) != "1" {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		trace := &requestTrace{start: now, last: now}
+		c.Set(traceContextKey, trace)
+
+		c.Next()
+
+		auditLog.WithFields(logrus.Fields{
+			"request_id": c.GetString("request_id"),
+			"method":     c.Request.Method,
+			"route":      c.FullPath(),
+			"chain":      shortNames(c.HandlerNames()),
+			"steps":      trace.steps,
+			"aborted":    c.IsAborted(),
+			"status":     c.Writer.Status(),
+			"total_ms":   float64(time.Since(trace.start).Microseconds()) / 1000,
+		}).Info("Middleware trace")
+	}
+}
+
+// traceDecision records a middleware decision when the request is being traced
+func traceDecision(c *gin.Context, middleware, decision string) {
+	value, exists := c.Get(traceContextKey)
+	if !exists {
+		return
+	}
+	trace := value.(*requestTrace)
+
+	now := time.Now()
+	trace.steps = append(trace.steps, TraceStep{
+		Middleware: middleware,
+		Decision:   decision,
+		LatencyMs:  float64(now.Sub(trace.last).Microseconds()) / 1000,
+	})
+	trace.last = now
+}
+
+// RouteChains returns the effective handler chain of every registered route.
+// Gin does not expose per-route chains, so the route trees are read via
+// reflection; if gin's internals change the result is simply empty.
+func RouteChains(engine *gin.Engine) (chains []RouteChain) {
+	chains = []RouteChain{}
+
+	defer func() {
+		// Never let introspection take the server down
+		if recover() != nil {
+			chains = []RouteChain{}
+		}
+	}()
+
+	trees := reflect.ValueOf(engine).Elem().FieldByName("trees")
+	if !trees.IsValid() || trees.Kind() != reflect.Slice {
+		return chains
+	}
+
+	for i := 0; i < trees.Len(); i++ {
+		tree := trees.Index(i)
+		method := tree.FieldByName("method")
+		root := tree.FieldByName("root")
+		if !method.IsValid() || !root.IsValid() {
+			continue
+		}
+		collectChains(method.String(), root, &chains)
+	}
+
+	sort.Slice(chains, func(i, j int) bool {
+		if chains[i].Path == chains[j].Path {
+			return chains[i].Method < chains[j].Method
+		}
+		return chains[i].Path < chains[j].Path
+	})
+	return chains
+}
+
+// collectChains walks a route tree node and its children
+func collectChains(method string, node reflect.Value, chains *[]RouteChain) {
+	if node.Kind() == reflect.Ptr {
+		if node.IsNil() {
+			return
+		}
+		node = node.Elem()
+	}
+
+	handlers := node.FieldByName("handlers")
+	if handlers.IsValid() && handlers.Len() > 0 {
+		names := make([]string, 0, handlers.Len())
+		for i := 0; i < handlers.Len(); i++ {
+			names = append(names, funcName(handlers.Index(i)))
+		}
+		*chains = append(*chains, RouteChain{
+			Method:   method,
+			Path:     node.FieldByName("fullPath").String(),
+			Handlers: names,
+		})
+	}
+
+	children := node.FieldByName("children")
+	if children.IsValid() {
+		for i := 0; i < children.Len(); i++ {
+			collectChains(method, children.Index(i), chains)
+		}
+	}
+}
+
+// funcName resolves a handler value to a readable function name
+func funcName(handler reflect.Value) string {
+	fn := runtime.FuncForPC(handler.Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	return shortName(fn.Name())
+}
+
+// shortNames trims module paths from handler names
+func shortNames(names []string) []string {
+	short := make([]string, len(names))
+	for i, name := range names {
+		short[i] = shortName(name)
+	}
+	return short
+}
+
+// shortName turns "InternalAPI/internal/middleware.RateLimitByUser.func1" into "middleware.RateLimitByUser"
+func shortName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, ".func"); i >= 0 {
+		name = name[:i]
+	}
+	return strings.TrimSuffix(name, "-fm")
+}
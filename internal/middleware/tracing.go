@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"fmt"
+
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Tracing starts a server span for every request, continuing the caller's
+// trace when the request carries a traceparent header. Backend calls made
+// with the request's context become child spans. The trace ID is stored as
+// "trace_id" and added to the request's log entry.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+		ctx, span := tracing.Start(ctx, c.Request.Method+" "+route, tracing.KindServer)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+		if traceID := span.TraceID(); traceID != "" {
+			c.Set("trace_id", traceID)
+			logging.AddFields(c, logrus.Fields{"trace_id": traceID})
+		}
+
+		span.SetAttribute("http.request.method", c.Request.Method)
+		span.SetAttribute("http.route", route)
+		span.SetAttribute("url.path", c.Request.URL.Path)
+		span.SetAttribute("client.address", c.ClientIP())
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttribute("http.response.status_code", status)
+		if requestID := c.GetString("request_id"); requestID != "" {
+			span.SetAttribute("request.id", requestID)
+		}
+		if userID := c.GetString("userID"); userID != "" {
+			span.SetAttribute("enduser.id", userID)
+		}
+		if status >= 500 {
+			span.SetError(fmt.Sprintf("HTTP %d", status))
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"InternalAPI/internal/usage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageTracking records each authenticated request for usage reports. It must
+// run after authentication so the consumer is known.
+func UsageTracking() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		consumer := c.GetString("userID")
+		if consumer == "" {
+			return
+		}
+
+		consumerType := usage.ConsumerUser
+		if _, isService := c.Get("api_key"); isService {
+			consumerType = usage.ConsumerAPIKey
+		}
+
+		endpoint := c.FullPath()
+		if endpoint == "" {
+			endpoint = "unmatched"
+		}
+		usage.Record(consumer, consumerType, c.Request.Method+" "+endpoint, c.Writer.Status())
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+// GuestAddress is a guest's postal address
+type GuestAddress struct {
+	Street     string `json:"street,omitempty" binding:"max=200"`
+	City       string `json:"city,omitempty" binding:"max=100"`
+	PostalCode string `json:"postal_code,omitempty" binding:"max=20"`
+	Country    string `json:"country,omitempty" binding:"omitempty,iso3166_1_alpha2"`
+}
+
+// GuestRequest represents a request to create or replace a guest profile.
+// Personal data fields are masked in audit logs and passport_number is
+// encrypted before it reaches API Beheerder when field encryption is on.
+type GuestRequest struct {
+	FirstName      string        `json:"first_name" binding:"required,min=1,max=100"`
+	LastName       string        `json:"last_name" binding:"required,min=1,max=100"`
+	Email          string        `json:"email" binding:"required,email,max=254"`
+	Phone          string        `json:"phone,omitempty" binding:"omitempty,e164"`
+	DateOfBirth    string        `json:"date_of_birth,omitempty" binding:"omitempty,datetime=2006-01-02"`
+	Nationality    string        `json:"nationality,omitempty" binding:"omitempty,iso3166_1_alpha2"`
+	PassportNumber string        `json:"passport_number,omitempty" binding:"max=50"`
+	Address        *GuestAddress `json:"address,omitempty"`
+	MarketingOptIn bool          `json:"marketing_opt_in"`
+	Notes          string        `json:"notes,omitempty" binding:"max=1000"`
+}
+
+// GuestExport is a data subject access export of everything held about a guest
+type GuestExport struct {
+	GuestID     string                 `json:"guest_id"`
+	ExportedAt  int64                  `json:"exported_at"`
+	ExportedBy  string                 `json:"exported_by"`
+	Profile     map[string]interface{} `json:"profile"`
+	Bookings    []interface{}          `json:"bookings"`
+	AccessTrail []GuestAccessRecord    `json:"access_trail"`
+}
+
+// GuestAccessRecord is one read or change of a guest profile by staff or services
+type GuestAccessRecord struct {
+	Timestamp int64  `json:"timestamp"`
+	UserID    string `json:"user_id"`
+	Action    string `json:"action"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+}
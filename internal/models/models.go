@@ -1,19 +1,30 @@
 package models
 
+import "time"
+
 // Album represents an album in the system
 type Album struct {
 	ID     string  `json:"id"`
 	Title  string  `json:"title" binding:"required,min=1,max=200"`
 	Artist string  `json:"artist" binding:"required,min=1,max=100"`
 	Price  float64 `json:"price" binding:"required,min=0,max=999999"`
+	Genre  string  `json:"genre,omitempty"`
 }
 
 // ErrorResponse represents an error response structure
 type ErrorResponse struct {
-	Code      string `json:"code"`
-	Message   string `json:"message"`
-	Details   string `json:"details,omitempty"`
-	Timestamp int64  `json:"timestamp"`
+	Code       string      `json:"code"`
+	Message    string      `json:"message"`
+	Details    string      `json:"details,omitempty"`
+	Violations []Violation `json:"violations,omitempty"`
+	Timestamp  int64       `json:"timestamp"`
+}
+
+// Violation describes one business rule a request payload breaks
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 // UserInfo represents user information from JWT or external service
@@ -33,15 +44,41 @@ type LoginRequest struct {
 
 // LoginResponse represents a login response
 type LoginResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	ExpiresIn    int    `json:"expires_in"`
-	TokenType    string `json:"token_type"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresIn    int       `json:"expires_in"`
+	TokenType    string    `json:"token_type"`
+	CSRFToken    string    `json:"csrf_token,omitempty"` // Set with cookie auth; echo in X-CSRF-Token
+	User         *UserInfo `json:"user,omitempty"`
 }
 
-// RefreshTokenRequest represents a refresh token request
+// RefreshTokenRequest represents a refresh token request. With cookie auth
+// the token may come from the refresh_token cookie instead.
 type RefreshTokenRequest struct {
-	RefreshToken string `json:"refresh_token" binding:"required"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenStatus describes the remaining lifetime of the caller's access token
+// and whether it should be renewed now
+type TokenStatus struct {
+	IssuedAt         int64  `json:"issued_at"`
+	ExpiresAt        int64  `json:"expires_at"`
+	RemainingSeconds int    `json:"remaining_seconds"`
+	RenewAt          int64  `json:"renew_at"`
+	Recommendation   string `json:"recommendation"` // none, extend or refresh
+	CanExtend        bool   `json:"can_extend"`
+	Extensions       int    `json:"extensions"`
+	MaxExtensions    int    `json:"max_extensions"`
+}
+
+// TokenExtensionResponse carries the access token issued by /auth/extend.
+// The refresh token is unchanged.
+type TokenExtensionResponse struct {
+	AccessToken         string `json:"access_token"`
+	ExpiresIn           int    `json:"expires_in"`
+	TokenType           string `json:"token_type"`
+	Extensions          int    `json:"extensions"`
+	ExtensionsRemaining int    `json:"extensions_remaining"`
 }
 
 // ChangePasswordRequest represents a change password request
@@ -104,12 +141,283 @@ type SystemStats struct {
 
 // AuditLog represents an audit log entry
 type AuditLog struct {
-	ID        string `json:"id"`
-	UserID    string `json:"user_id"`
-	Action    string `json:"action"`
-	Resource  string `json:"resource"`
-	Timestamp string `json:"timestamp"`
-	Details   string `json:"details,omitempty"`
+	ID         string `json:"id"`
+	UserID     string `json:"user_id"`
+	Action     string `json:"action"`
+	Resource   string `json:"resource"`
+	ResourceID string `json:"resource_id,omitempty"`
+	Timestamp  string `json:"timestamp"` // RFC3339, UTC
+	Details    string `json:"details,omitempty"`
+}
+
+// AuditLogQuery filters the audit logs listed by GET /admin/audit-logs
+type AuditLogQuery struct {
+	Since        string `form:"since" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Until        string `form:"until" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	UserID       string `form:"user_id" binding:"omitempty,max=64"`
+	Action       string `form:"action" binding:"omitempty,max=64"`
+	ResourceType string `form:"resource_type" binding:"omitempty,max=64"`
+	ResourceID   string `form:"resource_id" binding:"omitempty,max=128"`
+}
+
+// MaintenanceWindowRequest represents a request to schedule an upstream maintenance window
+type MaintenanceWindowRequest struct {
+	Service string    `json:"service" binding:"required,oneof=api-beheerder central-mgmt"`
+	Start   time.Time `json:"start" binding:"required"`
+	End     time.Time `json:"end" binding:"required"`
+	Mode    string    `json:"mode" binding:"required,oneof=read-only cached degraded"`
+	Reason  string    `json:"reason,omitempty" binding:"max=500"`
+}
+
+// WebhookRequest represents a request to register or change an outbound webhook
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=2000"`
+	Events      []string `json:"events" binding:"required,min=1,max=50,dive,required,max=100"`
+	Secret      string   `json:"secret,omitempty" binding:"omitempty,min=16,max=200"`
+	Description string   `json:"description,omitempty" binding:"max=500"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// JWTKeyRotateRequest represents a request to replace the JWT signing key
+type JWTKeyRotateRequest struct {
+	Secret       string `json:"secret,omitempty" binding:"omitempty,min=32,max=512"`
+	GraceSeconds *int   `json:"grace_seconds,omitempty" binding:"omitempty,min=0,max=604800"`
+}
+
+// MessageRequest represents a guest message or front-desk reply
+type MessageRequest struct {
+	Body string `json:"body" binding:"required,min=1,max=2000"`
+}
+
+// HousekeepingTaskRequest represents a manually created cleaning task
+type HousekeepingTaskRequest struct {
+	RoomID   string `json:"room_id" binding:"required,max=64"`
+	Priority int    `json:"priority" binding:"min=0,max=10"`
+	Notes    string `json:"notes,omitempty" binding:"max=500"`
+}
+
+// CompleteTaskRequest represents a worker finishing a cleaning task
+type CompleteTaskRequest struct {
+	Notes string `json:"notes,omitempty" binding:"max=500"`
+}
+
+// ReportTaskRequest represents a worker reporting a problem with a task
+type ReportTaskRequest struct {
+	Issue string `json:"issue" binding:"required,min=1,max=500"`
+}
+
+// StreamPolicyRequest updates the event stream eviction policy; omitted fields keep their value
+type StreamPolicyRequest struct {
+	QueueSize     *int `json:"queue_size" binding:"omitempty,min=1,max=10000"`
+	MaxDropped    *int `json:"max_dropped" binding:"omitempty,min=0"`
+	MaxLagSeconds *int `json:"max_lag_seconds" binding:"omitempty,min=0"`
+}
+
+// CircuitBreakerConfigRequest tunes a circuit breaker at runtime; omitted fields keep their value
+type CircuitBreakerConfigRequest struct {
+	FailureThreshold  *int     `json:"failure_threshold" binding:"omitempty,min=1,max=1000"`
+	TimeoutSeconds    *int     `json:"timeout_seconds" binding:"omitempty,min=1,max=3600"`
+	MaxRetries        *int     `json:"max_retries" binding:"omitempty,min=0,max=10"`
+	RetryDelayMs      *int     `json:"retry_delay_ms" binding:"omitempty,min=0,max=60000"`
+	HalfOpenMaxProbes *int     `json:"half_open_max_probes" binding:"omitempty,min=1,max=100"`
+	SuccessThreshold  *int     `json:"success_threshold" binding:"omitempty,min=1,max=100"`
+	Policy            *string  `json:"policy" binding:"omitempty,oneof=consecutive error_rate"`
+	ErrorRatePercent  *float64 `json:"error_rate_percent" binding:"omitempty,gt=0,lte=100"`
+	WindowSeconds     *int     `json:"window_seconds" binding:"omitempty,min=1,max=3600"`
+	MinCalls          *int     `json:"min_calls" binding:"omitempty,min=1,max=100000"`
+}
+
+// AvailabilityQuery represents the filters of an availability search
+type AvailabilityQuery struct {
+	CheckIn       string  `form:"check_in" binding:"required,datetime=2006-01-02"`
+	CheckOut      string  `form:"check_out" binding:"required,datetime=2006-01-02"`
+	HotelID       string  `form:"hotel_id" binding:"omitempty,max=64"`
+	Guests        int     `form:"guests" binding:"omitempty,min=1,max=20"`
+	RoomType      string  `form:"room_type" binding:"omitempty,max=50"`
+	MaxPrice      float64 `form:"max_price" binding:"omitempty,gt=0"`
+	AvailableOnly bool    `form:"available_only"`
+}
+
+// RoomAvailability is the merged inventory, restriction and pricing view of a room type
+type RoomAvailability struct {
+	RoomType    string  `json:"room_type"`
+	Available   int     `json:"available"`
+	Capacity    int     `json:"capacity"`
+	Bookable    bool    `json:"bookable"`
+	Reason      string  `json:"reason,omitempty"`
+	ReasonLabel string  `json:"reason_label,omitempty"`
+	MinStay     int     `json:"min_stay,omitempty"`
+	NightlyRate float64 `json:"nightly_rate"`
+	TotalPrice  float64 `json:"total_price"`
+	Currency    string  `json:"currency"`
+}
+
+// AvailabilityResponse represents the result of an availability search
+type AvailabilityResponse struct {
+	HotelID  string             `json:"hotel_id,omitempty"`
+	CheckIn  string             `json:"check_in"`
+	CheckOut string             `json:"check_out"`
+	Nights   int                `json:"nights"`
+	Rooms    []RoomAvailability `json:"rooms"`
+	Count    int                `json:"count"`
+	Cached   bool               `json:"cached"`
+	Partial  bool               `json:"partial"`                       // Some optional sources failed
+	Missing  []string           `json:"unavailable_sources,omitempty"` // bookings and/or restrictions
+}
+
+// DashboardSection reports whether a part of the dashboard could be
+// loaded: status is "ok" or "unavailable", with the error code of the
+// failed call
+type DashboardSection struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// DashboardCount is the number of items of a resource the user sees
+type DashboardCount struct {
+	DashboardSection
+	Count       int `json:"count"`
+	FilteredOut int `json:"filtered_out,omitempty"` // Items hidden by the user's list filter
+}
+
+// DashboardFilter is the list filter Central Management sets for the user
+// on a resource
+type DashboardFilter struct {
+	MaxPrice *float64 `json:"max_price,omitempty"`
+	Genres   []string `json:"genres,omitempty"`
+	Region   string   `json:"region,omitempty"`
+	Hidden   []string `json:"hidden_fields,omitempty"`
+}
+
+// DashboardPermissions are the user's roles and list filters
+type DashboardPermissions struct {
+	DashboardSection
+	Roles   []string                   `json:"roles"`
+	Filters map[string]DashboardFilter `json:"filters,omitempty"` // By resource; empty for service API keys
+}
+
+// DashboardUpstream is the circuit breaker state and health of a backend
+type DashboardUpstream struct {
+	Name         string `json:"name"`
+	BreakerState string `json:"breaker_state"`
+	Health       string `json:"health"` // healthy, degraded, down or unknown
+}
+
+// DashboardServices are the backends the gateway calls
+type DashboardServices struct {
+	DashboardSection
+	Upstreams []DashboardUpstream `json:"upstreams"`
+}
+
+// DashboardResponse is the portal's start page in a single response. Every
+// section carries its own status, so a failing backend only blanks the
+// sections it serves.
+type DashboardResponse struct {
+	Albums      DashboardCount       `json:"albums"`
+	Bookings    DashboardCount       `json:"bookings"`
+	Rooms       DashboardCount       `json:"rooms"`
+	Permissions DashboardPermissions `json:"permissions"`
+	Services    DashboardServices    `json:"services"`
+	Partial     bool                 `json:"partial"`                        // Some sections are unavailable
+	Missing     []string             `json:"unavailable_sections,omitempty"` // Names of the unavailable sections
+	Timestamp   int64                `json:"timestamp"`
+}
+
+// PublicAvailabilityQuery represents an anonymous availability search from
+// the website widget; a hotel is always required
+type PublicAvailabilityQuery struct {
+	HotelID  string `form:"hotel_id" binding:"required,max=64"`
+	CheckIn  string `form:"check_in" binding:"required,datetime=2006-01-02"`
+	CheckOut string `form:"check_out" binding:"required,datetime=2006-01-02"`
+	Guests   int    `form:"guests" binding:"omitempty,min=1,max=20"`
+}
+
+// PublicRoomAvailability is a bookable room type as shown to anonymous
+// visitors, without inventory counts
+type PublicRoomAvailability struct {
+	RoomType    string  `json:"room_type"`
+	MaxGuests   int     `json:"max_guests,omitempty"`
+	MinStay     int     `json:"min_stay,omitempty"`
+	NightlyRate float64 `json:"nightly_rate"`
+	TotalPrice  float64 `json:"total_price"`
+	Currency    string  `json:"currency"`
+}
+
+// PublicAvailabilityResponse represents the result of an anonymous availability search
+type PublicAvailabilityResponse struct {
+	HotelID  string                   `json:"hotel_id"`
+	CheckIn  string                   `json:"check_in"`
+	CheckOut string                   `json:"check_out"`
+	Nights   int                      `json:"nights"`
+	Rooms    []PublicRoomAvailability `json:"rooms"`
+}
+
+// Booking represents a room booking held by API Beheerder
+type Booking struct {
+	ID         string `json:"id,omitempty"`
+	HotelID    string `json:"hotel_id"`
+	RoomType   string `json:"room_type"`
+	GuestName  string `json:"guest_name"`
+	GuestEmail string `json:"guest_email"`
+	CheckIn    string `json:"check_in"`
+	CheckOut   string `json:"check_out"`
+	Guests     int    `json:"guests"`
+	Status     string `json:"status,omitempty"`
+	Notes      string `json:"notes,omitempty"`
+	CardToken  string `json:"card_token,omitempty"` // Encrypted when field encryption covers bookings
+}
+
+// CreateBookingRequest represents a request to book a room type for a stay
+type CreateBookingRequest struct {
+	HotelID    string `json:"hotel_id" binding:"required,max=64"`
+	RoomType   string `json:"room_type" binding:"required,max=50"`
+	GuestName  string `json:"guest_name" binding:"required,min=1,max=200"`
+	GuestEmail string `json:"guest_email" binding:"required,email,max=254"`
+	CheckIn    string `json:"check_in" binding:"required,datetime=2006-01-02"`
+	CheckOut   string `json:"check_out" binding:"required,datetime=2006-01-02"`
+	Guests     int    `json:"guests" binding:"required,min=1,max=20"`
+	Notes      string `json:"notes,omitempty" binding:"max=1000"`
+	CardToken  string `json:"card_token,omitempty" binding:"max=200"`
+}
+
+// UpdateBookingRequest represents a partial booking update; omitted fields keep their value
+type UpdateBookingRequest struct {
+	RoomType   string `json:"room_type,omitempty" binding:"max=50"`
+	GuestName  string `json:"guest_name,omitempty" binding:"max=200"`
+	GuestEmail string `json:"guest_email,omitempty" binding:"omitempty,email,max=254"`
+	CheckIn    string `json:"check_in,omitempty" binding:"omitempty,datetime=2006-01-02"`
+	CheckOut   string `json:"check_out,omitempty" binding:"omitempty,datetime=2006-01-02"`
+	Guests     int    `json:"guests,omitempty" binding:"omitempty,min=1,max=20"`
+	Status     string `json:"status,omitempty" binding:"omitempty,oneof=confirmed checked_in checked_out cancelled no_show"`
+	Notes      string `json:"notes,omitempty" binding:"max=1000"`
+}
+
+// HousekeepingUpdate is one line of a housekeeping NDJSON stream
+type HousekeepingUpdate struct {
+	ID     string `json:"id"` // Client reference echoed in the acknowledgement
+	RoomID string `json:"room_id"`
+	Status string `json:"status"`
+	Notes  string `json:"notes,omitempty"`
+}
+
+// HousekeepingAck acknowledges one line of a housekeeping stream
+type HousekeepingAck struct {
+	Line   int    `json:"line"`
+	ID     string `json:"id,omitempty"`
+	RoomID string `json:"room_id,omitempty"`
+	Result string `json:"result"` // applied, invalid or failed
+	Error  string `json:"error,omitempty"`
+}
+
+// HousekeepingStreamSummary is the final line of a housekeeping stream
+type HousekeepingStreamSummary struct {
+	Summary    bool   `json:"summary"`
+	Received   int    `json:"received"`
+	Applied    int    `json:"applied"`
+	Invalid    int    `json:"invalid"`
+	Failed     int    `json:"failed"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"` // Why the stream ended early, if it did
 }
 
 // PaginationParams represents pagination parameters
@@ -120,11 +428,27 @@ type PaginationParams struct {
 
 // PaginatedResponse represents a paginated response
 type PaginatedResponse struct {
-	Data       interface{} `json:"data"`
-	Page       int         `json:"page"`
-	PageSize   int         `json:"page_size"`
-	TotalPages int         `json:"total_pages"`
-	TotalItems int         `json:"total_items"`
+	Data        interface{} `json:"data"`
+	Page        int         `json:"page"`
+	PageSize    int         `json:"page_size"`
+	TotalPages  int         `json:"total_pages"`
+	TotalItems  int         `json:"total_items"`
+	NextCursor  string      `json:"next_cursor,omitempty"`  // Cursor pagination only
+	HasMore     *bool       `json:"has_more,omitempty"`     // Cursor pagination only
+	FilteredOut int         `json:"filtered_out,omitempty"` // Items of the page hidden by the user's list filter
+}
+
+// ListQuery represents the query parameters of a sortable, filterable list.
+// Sort is a comma-separated list of fields, each prefixed with - for
+// descending order. Every filter is a field:operator:value expression, and
+// items must match all of them.
+type ListQuery struct {
+	Page     int      `form:"page" binding:"omitempty,min=1"`
+	PageSize int      `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Cursor   string   `form:"cursor" binding:"omitempty,max=1024"`
+	Limit    int      `form:"limit" binding:"omitempty,min=1,max=100"`
+	Sort     string   `form:"sort" binding:"omitempty,max=200"`
+	Filter   []string `form:"filter" binding:"omitempty,max=10,dive,max=200"`
 }
 
 // GetPage returns the page number (defaults to 1)
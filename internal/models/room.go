@@ -0,0 +1,98 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Room statuses
+const (
+	RoomAvailable   = "available"
+	RoomOccupied    = "occupied"
+	RoomDirty       = "dirty"
+	RoomCleaning    = "cleaning"
+	RoomMaintenance = "maintenance"
+	RoomOutOfOrder  = "out_of_order"
+)
+
+// roomTransitions lists the statuses each room status may move to. A room
+// only becomes available again after cleaning.
+var roomTransitions = map[string][]string{
+	RoomAvailable:   {RoomOccupied, RoomDirty, RoomMaintenance, RoomOutOfOrder},
+	RoomOccupied:    {RoomDirty, RoomCleaning, RoomMaintenance, RoomOutOfOrder},
+	RoomDirty:       {RoomCleaning, RoomMaintenance, RoomOutOfOrder},
+	RoomCleaning:    {RoomAvailable, RoomDirty, RoomMaintenance, RoomOutOfOrder},
+	RoomMaintenance: {RoomDirty, RoomCleaning, RoomOutOfOrder},
+	RoomOutOfOrder:  {RoomMaintenance, RoomDirty},
+}
+
+// Room represents a hotel room held by API Beheerder
+type Room struct {
+	ID       string `json:"id,omitempty"`
+	HotelID  string `json:"hotel_id"`
+	Number   string `json:"number"`
+	RoomType string `json:"room_type"`
+	Floor    int    `json:"floor"`
+	Capacity int    `json:"capacity"`
+	Status   string `json:"status"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+// CreateRoomRequest represents a request to add a room
+type CreateRoomRequest struct {
+	HotelID  string `json:"hotel_id" binding:"required,max=64"`
+	Number   string `json:"number" binding:"required,max=20"`
+	RoomType string `json:"room_type" binding:"required,max=50"`
+	Floor    int    `json:"floor" binding:"min=-5,max=200"`
+	Capacity int    `json:"capacity" binding:"required,min=1,max=20"`
+	Notes    string `json:"notes,omitempty" binding:"max=500"`
+}
+
+// UpdateRoomRequest represents a room update. Status is changed through the
+// status endpoint so transitions are always checked.
+type UpdateRoomRequest struct {
+	Number   string `json:"number" binding:"required,max=20"`
+	RoomType string `json:"room_type" binding:"required,max=50"`
+	Floor    int    `json:"floor" binding:"min=-5,max=200"`
+	Capacity int    `json:"capacity" binding:"required,min=1,max=20"`
+	Notes    string `json:"notes,omitempty" binding:"max=500"`
+}
+
+// RoomStatusRequest represents a room status transition
+type RoomStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=available occupied dirty cleaning maintenance out_of_order"`
+	Notes  string `json:"notes,omitempty" binding:"max=500"`
+}
+
+// RoomTransitionError is returned for a status change the state machine forbids
+type RoomTransitionError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e *RoomTransitionError) Error() string {
+	if len(e.Allowed) == 0 {
+		return fmt.Sprintf("unknown room status %q", e.From)
+	}
+	return fmt.Sprintf("room status cannot change from %s to %s; allowed: %s", e.From, e.To, strings.Join(e.Allowed, ", "))
+}
+
+// AllowedRoomTransitions returns the statuses a room may move to from status
+func AllowedRoomTransitions(status string) []string {
+	return append([]string{}, roomTransitions[status]...)
+}
+
+// ValidateRoomTransition checks a status change against the room state
+// machine. Setting the current status again is a no-op and always allowed.
+func ValidateRoomTransition(from, to string) error {
+	if from == to {
+		return nil
+	}
+	for _, allowed := range roomTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return &RoomTransitionError{From: from, To: to, Allowed: AllowedRoomTransitions(from)}
+}
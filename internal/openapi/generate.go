@@ -0,0 +1,366 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RouteDoc describes one registered route for the generated document
+type RouteDoc struct {
+	Method   string
+	Path     string // Gin route, e.g. /api/v1/rooms/:id
+	Summary  string
+	Tag      string
+	Query    interface{} // Zero value of a struct whose form tags are query parameters, or nil
+	Request  interface{} // Zero value of the request body model, or nil
+	Response interface{} // Zero value of the success response model, or nil
+	Status   int         // Success status; 0 means 200
+	Security []string    // Accepted security schemes; empty means public
+}
+
+// Security schemes referenced by RouteDoc.Security
+const (
+	SecurityBearer = "bearerAuth" // JWT in the Authorization header
+	SecurityCookie = "cookieAuth" // JWT in the access_token cookie
+	SecurityAPIKey = "apiKeyAuth" // Service key in X-Internal-API-Key
+)
+
+// generatedOperation is an operation as written to the generated document
+type generatedOperation struct {
+	OperationID string                       `json:"operationId"`
+	Summary     string                       `json:"summary,omitempty"`
+	Tags        []string                     `json:"tags,omitempty"`
+	Parameters  []generatedParameter         `json:"parameters,omitempty"`
+	RequestBody *Body                        `json:"requestBody,omitempty"`
+	Responses   map[string]generatedResponse `json:"responses"`
+	Security    []map[string][]string        `json:"security"`
+}
+
+// generatedParameter is a path or query parameter of a generated operation
+type generatedParameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// generatedResponse is a response of a generated operation
+type generatedResponse struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// routeParam matches Gin path parameters and wildcards
+var routeParam = regexp.MustCompile(`[:*]([^/]+)`)
+
+// generator collects component schemas while routes are described
+type generator struct {
+	schemas map[string]*Schema
+}
+
+// Generate builds an OpenAPI 3 document for routes. Model structs are
+// described from their json and binding tags and shared as component
+// schemas; error responses use ErrorResponse-shaped errorModel.
+func Generate(title, version string, routes []RouteDoc, errorModel interface{}) ([]byte, error) {
+	g := &generator{schemas: make(map[string]*Schema)}
+	errorSchema := g.schemaFor(reflect.TypeOf(errorModel))
+
+	paths := make(map[string]map[string]generatedOperation)
+	for _, route := range routes {
+		path := routeParam.ReplaceAllString(route.Path, "{$1}")
+		if paths[path] == nil {
+			paths[path] = make(map[string]generatedOperation)
+		}
+		paths[path][strings.ToLower(route.Method)] = g.operation(route, errorSchema)
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]string{"title": title, "version": version},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": g.schemas,
+			"securitySchemes": map[string]interface{}{
+				SecurityBearer: map[string]string{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				SecurityCookie: map[string]string{"type": "apiKey", "in": "cookie", "name": "access_token"},
+				SecurityAPIKey: map[string]string{"type": "apiKey", "in": "header", "name": "X-Internal-API-Key"},
+			},
+		},
+	}, "", "  ")
+}
+
+// operation describes one route
+func (g *generator) operation(route RouteDoc, errorSchema *Schema) generatedOperation {
+	op := generatedOperation{
+		OperationID: operationID(route.Method, route.Path),
+		Summary:     route.Summary,
+		Responses:   make(map[string]generatedResponse),
+		Security:    []map[string][]string{},
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	for _, match := range routeParam.FindAllStringSubmatch(route.Path, -1) {
+		op.Parameters = append(op.Parameters, generatedParameter{
+			Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"},
+		})
+	}
+	if route.Query != nil {
+		op.Parameters = append(op.Parameters, g.queryParameters(reflect.TypeOf(route.Query))...)
+	}
+	for _, scheme := range route.Security {
+		op.Security = append(op.Security, map[string][]string{scheme: {}})
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &Body{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: g.schemaFor(reflect.TypeOf(route.Request))}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := generatedResponse{Description: http.StatusText(status)}
+	if status != http.StatusNoContent {
+		schema := &Schema{Type: "object"}
+		if route.Response != nil {
+			schema = g.schemaFor(reflect.TypeOf(route.Response))
+		}
+		success.Content = map[string]MediaType{"application/json": {Schema: schema}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+	op.Responses["default"] = generatedResponse{
+		Description: "Error",
+		Content:     map[string]MediaType{"application/json": {Schema: errorSchema}},
+	}
+	return op
+}
+
+// queryParameters describes the form-tagged fields of a query struct
+func (g *generator) queryParameters(t reflect.Type) []generatedParameter {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var params []generatedParameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		schema := g.schemaFor(field.Type)
+		required := applyBinding(schema, field.Tag.Get("binding"))
+		params = append(params, generatedParameter{Name: name, In: "query", Required: required, Schema: schema})
+	}
+	return params
+}
+
+// operationID derives a stable operation ID such as getApiV1RoomsById
+func operationID(method, path string) string {
+	var id strings.Builder
+	id.WriteString(strings.ToLower(method))
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' || r == '_' }) {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			id.WriteString("By")
+			segment = segment[1:]
+		}
+		if segment != "" {
+			id.WriteString(strings.ToUpper(segment[:1]) + segment[1:])
+		}
+	}
+	return id.String()
+}
+
+// schemaFor describes a Go type. Named structs become component schemas and
+// are referenced, so each model appears once.
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return &Schema{Type: "string", Format: "date-time", Nullable: nullable}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		if _, ok := g.schemas[t.Name()]; !ok {
+			g.schemas[t.Name()] = &Schema{} // Placeholder for recursive models
+			g.schemas[t.Name()] = g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	case t.Kind() == reflect.Struct:
+		return g.structSchema(t)
+	}
+
+	schema := &Schema{Nullable: nullable}
+	switch t.Kind() {
+	case reflect.String:
+		schema.Type = "string"
+	case reflect.Bool:
+		schema.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema.Type = "number"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			schema.Type, schema.Format = "string", "byte"
+			break
+		}
+		schema.Type = "array"
+		schema.Items = g.schemaFor(t.Elem())
+	case reflect.Map:
+		schema.Type = "object"
+	}
+	return schema
+}
+
+// structSchema describes a struct from its json and binding tags
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs without a json name contribute their fields
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := g.structSchema(embedded)
+				for key, property := range inner.Properties {
+					schema.Properties[key] = property
+				}
+				schema.Required = append(schema.Required, inner.Required...)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.schemaFor(field.Type)
+		if applyBinding(property, field.Tag.Get("binding")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// applyBinding adds the validator rules of a binding tag to schema and
+// reports whether the field is required. Rules after dive apply to items.
+func applyBinding(schema *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	required := false
+	target := schema
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if target.Ref != "" {
+			// Constraints cannot sit next to a $ref in OpenAPI 3.0
+			if name == "required" && target == schema {
+				required = true
+			}
+			continue
+		}
+		switch name {
+		case "required":
+			required = required || target == schema
+		case "dive":
+			if target.Items == nil {
+				return required
+			}
+			target = target.Items
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			applyBound(target, name == "min", n)
+		case "email":
+			target.Format = "email"
+		case "url":
+			target.Format = "uri"
+		case "uuid":
+			target.Format = "uuid"
+		case "datetime":
+			if param == "2006-01-02" {
+				target.Format = "date"
+			} else {
+				target.Format = "date-time"
+			}
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				target.Enum = append(target.Enum, enumValue(target.Type, value))
+			}
+		case "alphanum":
+			target.Pattern = "^[a-zA-Z0-9]+$"
+		case "e164":
+			target.Pattern = `^\+[1-9][0-9]{1,14}$`
+		case "iso3166_1_alpha2":
+			target.Pattern = "^[A-Z]{2}$"
+		}
+	}
+	return required
+}
+
+// applyBound sets a min or max rule as a length, item count or value bound
+// depending on the schema type
+func applyBound(schema *Schema, min bool, n int) {
+	switch schema.Type {
+	case "string":
+		if min {
+			schema.MinLength = &n
+		} else {
+			schema.MaxLength = &n
+		}
+	case "array":
+		if min {
+			schema.MinItems = &n
+		} else {
+			schema.MaxItems = &n
+		}
+	case "integer", "number":
+		bound := float64(n)
+		if min {
+			schema.Minimum = &bound
+		} else {
+			schema.Maximum = &bound
+		}
+	}
+}
+
+// enumValue converts a oneof member to the schema's type
+func enumValue(schemaType, value string) interface{} {
+	if schemaType == "integer" || schemaType == "number" {
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
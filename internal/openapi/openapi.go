@@ -0,0 +1,378 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed spec.json
+var embeddedSpec []byte
+
+// Document is the subset of an OpenAPI 3 document the gateway validates
+// against: operations with JSON request and response bodies, and the
+// component schemas they reference
+type Document struct {
+	OpenAPI    string                                `json:"openapi"`
+	Info       map[string]interface{}                `json:"info"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+
+	operations map[string]*Operation // "METHOD /gin/:path" to operation
+}
+
+// Operation is one method on a path
+type Operation struct {
+	OperationID string           `json:"operationId"`
+	RequestBody *Body            `json:"requestBody"`
+	Responses   map[string]*Body `json:"responses"`
+}
+
+// Body is a request body or response with its media types
+type Body struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// MediaType holds the schema of one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the JSON Schema subset of OpenAPI 3.0 the validator supports
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// ValidationError locates one schema violation with a JSON pointer
+type ValidationError struct {
+	Pointer string `json:"pointer"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+var (
+	// pathParam matches OpenAPI path templates such as {id}
+	pathParam = regexp.MustCompile(`\{([^}/]+)\}`)
+
+	// httpMethods are the path item keys that hold operations
+	httpMethods = map[string]bool{"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true, "patch": true, "trace": true}
+)
+
+// Embedded returns the OpenAPI document compiled into the binary
+func Embedded() (*Document, error) {
+	return Parse(embeddedSpec)
+}
+
+// Parse reads an OpenAPI 3 document in JSON, compiles its patterns and
+// indexes its operations by Gin route
+func Parse(raw []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", doc.OpenAPI)
+	}
+
+	doc.operations = make(map[string]*Operation)
+	for path, methods := range doc.Paths {
+		route := pathParam.ReplaceAllString(path, ":$1")
+		for method, raw := range methods {
+			if !httpMethods[method] {
+				continue
+			}
+			op := &Operation{}
+			if err := json.Unmarshal(raw, op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			doc.operations[strings.ToUpper(method)+" "+route] = op
+			if op.RequestBody != nil {
+				for _, media := range op.RequestBody.Content {
+					if err := doc.compile(media.Schema); err != nil {
+						return nil, fmt.Errorf("%s %s request: %w", strings.ToUpper(method), path, err)
+					}
+				}
+			}
+			for status, response := range op.Responses {
+				for _, media := range response.Content {
+					if err := doc.compile(media.Schema); err != nil {
+						return nil, fmt.Errorf("%s %s response %s: %w", strings.ToUpper(method), path, status, err)
+					}
+				}
+			}
+		}
+	}
+	for name, schema := range doc.Components.Schemas {
+		if err := doc.compile(schema); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+	return &doc, nil
+}
+
+// compile checks references and compiles patterns in a schema tree
+func (d *Document) compile(s *Schema) error {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		if _, err := d.resolve(s.Ref); err != nil {
+			return err
+		}
+		return nil
+	}
+	if s.Pattern != "" && s.pattern == nil {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	for _, property := range s.Properties {
+		if err := d.compile(property); err != nil {
+			return err
+		}
+	}
+	return d.compile(s.Items)
+}
+
+// resolve returns the component schema a local $ref points to
+func (d *Document) resolve(ref string) (*Schema, error) {
+	name := strings.TrimPrefix(ref, "#/components/schemas/")
+	schema, ok := d.Components.Schemas[name]
+	if name == ref || !ok {
+		return nil, fmt.Errorf("unresolvable $ref %q", ref)
+	}
+	return schema, nil
+}
+
+// Operation returns the operation for a method and Gin route pattern
+func (d *Document) Operation(method, route string) *Operation {
+	return d.operations[strings.ToUpper(method)+" "+route]
+}
+
+// Routes lists the documented operations as "METHOD /route"
+func (d *Document) Routes() []string {
+	routes := make([]string, 0, len(d.operations))
+	for route := range d.operations {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// RequestSchema returns the request body schema documented for mediaType
+// and whether a body is required. Media types the operation does not list,
+// such as PATCH documents, have no schema.
+func (op *Operation) RequestSchema(mediaType string) (*Schema, bool) {
+	if op == nil || op.RequestBody == nil {
+		return nil, false
+	}
+	if mediaType == "" {
+		return jsonSchema(op.RequestBody.Content), op.RequestBody.Required
+	}
+	return op.RequestBody.Content[mediaType].Schema, op.RequestBody.Required
+}
+
+// ResponseSchema returns the JSON schema documented for a status code,
+// falling back to the status class (e.g. 4XX) and then default
+func (op *Operation) ResponseSchema(status int) *Schema {
+	if op == nil {
+		return nil
+	}
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", "default"} {
+		if response, ok := op.Responses[key]; ok {
+			return jsonSchema(response.Content)
+		}
+	}
+	return nil
+}
+
+// jsonSchema picks the schema of the JSON media type
+func jsonSchema(content map[string]MediaType) *Schema {
+	for mediaType, media := range content {
+		if strings.Contains(mediaType, "json") {
+			return media.Schema
+		}
+	}
+	return nil
+}
+
+// Validate checks a decoded JSON value against schema and returns every
+// violation, each located by an RFC 6901 JSON pointer
+func (d *Document) Validate(schema *Schema, value interface{}) []ValidationError {
+	var errs []ValidationError
+	d.validate(schema, value, "", &errs)
+	return errs
+}
+
+// validate checks value at pointer and appends violations to errs
+func (d *Document) validate(s *Schema, value interface{}, pointer string, errs *[]ValidationError) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		resolved, err := d.resolve(s.Ref)
+		if err != nil {
+			*errs = append(*errs, ValidationError{Pointer: pointer, Rule: "$ref", Message: err.Error()})
+			return
+		}
+		s = resolved
+	}
+
+	fail := func(rule, format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Pointer: pointer, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		if !s.Nullable && s.Type != "" {
+			fail("type", "must be %s, not null", s.Type)
+		}
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		fail("enum", "must be one of %v", s.Enum)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if s.Type != "" && s.Type != "object" {
+			fail("type", "must be %s, not object", s.Type)
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, ValidationError{Pointer: pointer + "/" + escape(name), Rule: "required", Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*errs = append(*errs, ValidationError{Pointer: pointer + "/" + escape(name), Rule: "additionalProperties", Message: "is not an allowed property"})
+				}
+				continue
+			}
+			d.validate(property, v[name], pointer+"/"+escape(name), errs)
+		}
+	case []interface{}:
+		if s.Type != "" && s.Type != "array" {
+			fail("type", "must be %s, not array", s.Type)
+			return
+		}
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("minItems", "must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("maxItems", "must have at most %d items", *s.MaxItems)
+		}
+		for i, item := range v {
+			d.validate(s.Items, item, pointer+"/"+strconv.Itoa(i), errs)
+		}
+	case string:
+		if s.Type != "" && s.Type != "string" {
+			fail("type", "must be %s, not string", s.Type)
+			return
+		}
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("minLength", "must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("maxLength", "must be at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("pattern", "must match %s", s.Pattern)
+		}
+		if message := checkFormat(s.Format, v); message != "" {
+			fail("format", "%s", message)
+		}
+	case float64:
+		if s.Type != "" && s.Type != "number" && s.Type != "integer" {
+			fail("type", "must be %s, not number", s.Type)
+			return
+		}
+		if s.Type == "integer" && v != math.Trunc(v) {
+			fail("type", "must be an integer")
+		}
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("minimum", "must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("maximum", "must be at most %v", *s.Maximum)
+		}
+	case bool:
+		if s.Type != "" && s.Type != "boolean" {
+			fail("type", "must be %s, not boolean", s.Type)
+		}
+	}
+}
+
+// checkFormat validates the string formats the gateway relies on; unknown
+// formats are informational only
+func checkFormat(format, value string) string {
+	switch format {
+	case "email":
+		if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+			return "must be an email address"
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "must be a date (YYYY-MM-DD)"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	}
+	return ""
+}
+
+// inEnum reports whether value equals one of the enum members
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, member := range enum {
+		if member == value {
+			return true
+		}
+	}
+	return false
+}
+
+// escape encodes a property name as a JSON pointer token
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,154 @@
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"InternalAPI/internal/cache"
+	"InternalAPI/internal/metrics"
+	"InternalAPI/internal/services"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Decision is the outcome of a Central Management permission check
+type Decision struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+var (
+	cacheLookups = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "permission_cache_lookups_total",
+		Help:      "Permission decision lookups by result (hit, miss or bypass)",
+	}, []string{"result"})
+
+	cacheInvalidations = metrics.Factory.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "permission_cache_invalidated_total",
+		Help:      "Permission decisions removed from the cache by admins",
+	})
+)
+
+// Checker asks Central Management for permission decisions and caches them
+// per (userID, action, resource)
+type Checker struct {
+	service *services.ExternalService
+	cache   *cache.Cache
+	ttl     time.Duration
+}
+
+// NewChecker creates a checker; a ttl of zero disables caching
+func NewChecker(es *services.ExternalService, ttl time.Duration) *Checker {
+	return &Checker{
+		service: es,
+		cache:   cache.New(),
+		ttl:     ttl,
+	}
+}
+
+// Check returns whether userID may perform action on resource. Checks that
+// carry request data depend on the payload and are never cached.
+func (ch *Checker) Check(ctx context.Context, userID, action, resource string, data map[string]interface{}) (Decision, error) {
+	cacheable := data == nil && ch.ttl > 0
+	key := cacheKey(userID, action, resource)
+
+	if cacheable {
+		if cached, ok := ch.cache.Get(key); ok {
+			cacheLookups.WithLabelValues("hit").Inc()
+			return cached.(Decision), nil
+		}
+		cacheLookups.WithLabelValues("miss").Inc()
+	} else {
+		cacheLookups.WithLabelValues("bypass").Inc()
+	}
+
+	request := map[string]interface{}{
+		"userID":   userID,
+		"action":   action,
+		"resource": resource,
+	}
+	if data != nil {
+		request["data"] = data
+	}
+
+	decision, err := services.CallTyped[Decision](ctx, ch.service, "central", "POST", "/check-permission", request)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	if cacheable {
+		ch.cache.Set(key, decision, ch.ttl)
+	}
+	return decision, nil
+}
+
+// InvalidateUser drops every cached decision for a user and returns how many were removed
+func (ch *Checker) InvalidateUser(userID string) int {
+	removed := ch.cache.DeletePrefix(userID + "|")
+	cacheInvalidations.Add(float64(removed))
+	return removed
+}
+
+// InvalidateAll drops every cached decision and returns how many were removed
+func (ch *Checker) InvalidateAll() int {
+	removed := ch.cache.DeletePrefix("")
+	cacheInvalidations.Add(float64(removed))
+	return removed
+}
+
+// Size returns the number of cached decisions
+func (ch *Checker) Size() int {
+	return ch.cache.Len()
+}
+
+// cacheKey builds the cache key for a decision
+func cacheKey(userID, action, resource string) string {
+	return userID + "|" + action + "|" + resource
+}
+
+var checker *Checker
+
+// Init configures the global permission checker
+func Init(es *services.ExternalService, ttl time.Duration) {
+	checker = NewChecker(es, ttl)
+}
+
+// Enabled reports whether the global checker has been configured
+func Enabled() bool {
+	return checker != nil
+}
+
+// Check asks the global checker for a decision
+func Check(ctx context.Context, userID, action, resource string, data map[string]interface{}) (Decision, error) {
+	if checker == nil {
+		return Decision{}, fmt.Errorf("permission checks are not configured")
+	}
+	return checker.Check(ctx, userID, action, resource, data)
+}
+
+// InvalidateUser drops cached decisions for a user from the global checker
+func InvalidateUser(userID string) int {
+	if checker == nil {
+		return 0
+	}
+	return checker.InvalidateUser(userID)
+}
+
+// InvalidateAll drops every cached decision from the global checker
+func InvalidateAll() int {
+	if checker == nil {
+		return 0
+	}
+	return checker.InvalidateAll()
+}
+
+// CacheSize returns the number of decisions cached by the global checker
+func CacheSize() int {
+	if checker == nil {
+		return 0
+	}
+	return checker.Size()
+}
@@ -0,0 +1,131 @@
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// FieldFilter lists the fields of a resource a user may not see. Nested
+// fields use dotted paths such as address.street.
+type FieldFilter struct {
+	Hidden []string `json:"hidden_fields"`
+}
+
+// Apply removes hidden fields from an upstream response. Lists under the
+// given key or "data" are filtered item by item; a single object may be
+// nested under singular.
+func (f FieldFilter) Apply(response map[string]interface{}, listKey, singular string) {
+	if len(f.Hidden) == 0 || response == nil {
+		return
+	}
+
+	for _, key := range []string{listKey, "data"} {
+		if items, ok := response[key].([]interface{}); ok {
+			for _, item := range items {
+				if obj, ok := item.(map[string]interface{}); ok {
+					f.removeFields(obj)
+				}
+			}
+			return
+		}
+	}
+	if obj, ok := response[singular].(map[string]interface{}); ok {
+		f.removeFields(obj)
+		return
+	}
+	f.removeFields(response)
+}
+
+// removeFields deletes every hidden path from obj
+func (f FieldFilter) removeFields(obj map[string]interface{}) {
+	for _, path := range f.Hidden {
+		parts := strings.Split(path, ".")
+		current := obj
+		for i, part := range parts {
+			if i == len(parts)-1 {
+				delete(current, part)
+				break
+			}
+			next, ok := current[part].(map[string]interface{})
+			if !ok {
+				break
+			}
+			current = next
+		}
+	}
+}
+
+// Changed returns the hidden fields whose value differs between two
+// versions of an object, so writes cannot touch fields the user cannot see
+func (f FieldFilter) Changed(before, after map[string]interface{}) []string {
+	var changed []string
+	for _, path := range f.Hidden {
+		oldValue, _ := lookupField(before, path)
+		newValue, _ := lookupField(after, path)
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// lookupField returns the value at a dotted path
+func lookupField(obj map[string]interface{}, path string) (interface{}, bool) {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		value, ok := obj[part]
+		if !ok || i == len(parts)-1 {
+			return value, ok
+		}
+		if obj, ok = value.(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// Fields returns the field filter Central Management defines for userID on
+// resource. Filters are cached alongside permission decisions.
+func (ch *Checker) Fields(ctx context.Context, userID, resource string) (FieldFilter, error) {
+	key := cacheKey(userID, "fields", resource)
+	if ch.ttl > 0 {
+		if cached, ok := ch.cache.Get(key); ok {
+			cacheLookups.WithLabelValues("hit").Inc()
+			return cached.(FieldFilter), nil
+		}
+		cacheLookups.WithLabelValues("miss").Inc()
+	} else {
+		cacheLookups.WithLabelValues("bypass").Inc()
+	}
+
+	endpoint := "/users/" + url.PathEscape(userID) + "/field-filters?resource=" + url.QueryEscape(resource)
+	response, err := ch.service.Call(ctx, "central", "GET", endpoint, nil)
+	if err != nil {
+		return FieldFilter{}, err
+	}
+
+	var filter FieldFilter
+	if hidden, ok := response["hidden_fields"].([]interface{}); ok {
+		for _, field := range hidden {
+			if name, ok := field.(string); ok && name != "" {
+				filter.Hidden = append(filter.Hidden, name)
+			}
+		}
+	}
+
+	if ch.ttl > 0 {
+		ch.cache.Set(key, filter, ch.ttl)
+	}
+	return filter, nil
+}
+
+// Fields asks the global checker for a user's field filter
+func Fields(ctx context.Context, userID, resource string) (FieldFilter, error) {
+	if checker == nil {
+		return FieldFilter{}, fmt.Errorf("permission checks are not configured")
+	}
+	return checker.Fields(ctx, userID, resource)
+}
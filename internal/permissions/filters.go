@@ -0,0 +1,143 @@
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ListFilter restricts which items of a list a user sees. Central
+// Management sets it per user and resource, e.g. a maximum price or the
+// genres and region a reseller is licensed for. A rule only applies to items
+// that carry the field it checks.
+type ListFilter struct {
+	MaxPrice *float64 `json:"max_price,omitempty"`
+	Genres   []string `json:"genres,omitempty"`
+	Region   string   `json:"region,omitempty"`
+	Hidden   []string `json:"hidden_fields,omitempty"` // Fields redacted from the items that remain
+}
+
+// priceFields are the item fields compared against MaxPrice
+var priceFields = []string{"price", "total_price"}
+
+// Empty reports whether the filter lets every item through unchanged
+func (f ListFilter) Empty() bool {
+	return f.MaxPrice == nil && len(f.Genres) == 0 && f.Region == "" && len(f.Hidden) == 0
+}
+
+// Apply removes the items the user may not see from a list response and
+// redacts hidden fields from the rest. Lists are read from listKey or
+// "data". It returns the number of items removed.
+func (f ListFilter) Apply(response map[string]interface{}, listKey string) int {
+	if f.Empty() || response == nil {
+		return 0
+	}
+
+	for _, key := range []string{listKey, "data"} {
+		items, ok := response[key].([]interface{})
+		if !ok {
+			continue
+		}
+
+		kept := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			obj, ok := item.(map[string]interface{})
+			if !ok || !f.allows(obj) {
+				continue
+			}
+			FieldFilter{Hidden: f.Hidden}.removeFields(obj)
+			kept = append(kept, obj)
+		}
+		response[key] = kept
+		return len(items) - len(kept)
+	}
+	return 0
+}
+
+// allows reports whether an item passes every rule of the filter
+func (f ListFilter) allows(item map[string]interface{}) bool {
+	if f.MaxPrice != nil {
+		for _, field := range priceFields {
+			if price, ok := item[field].(float64); ok && price > *f.MaxPrice {
+				return false
+			}
+		}
+	}
+	if len(f.Genres) > 0 {
+		if genre, ok := item["genre"].(string); ok && !containsFold(f.Genres, genre) {
+			return false
+		}
+	}
+	if f.Region != "" {
+		if region, ok := item["region"].(string); ok && !strings.EqualFold(region, f.Region) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold reports whether values holds s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filters returns the list filter Central Management defines for userID on
+// resource. Filters are cached alongside permission decisions.
+func (ch *Checker) Filters(ctx context.Context, userID, resource string) (ListFilter, error) {
+	key := cacheKey(userID, "list-filter", resource)
+	if ch.ttl > 0 {
+		if cached, ok := ch.cache.Get(key); ok {
+			cacheLookups.WithLabelValues("hit").Inc()
+			return cached.(ListFilter), nil
+		}
+		cacheLookups.WithLabelValues("miss").Inc()
+	} else {
+		cacheLookups.WithLabelValues("bypass").Inc()
+	}
+
+	endpoint := "/user-filters/" + url.PathEscape(resource) + "?userID=" + url.QueryEscape(userID)
+	response, err := ch.service.Call(ctx, "central", "GET", endpoint, nil)
+	if err != nil {
+		return ListFilter{}, err
+	}
+
+	var filter ListFilter
+	rules, _ := response["filters"].(map[string]interface{})
+	if maxPrice, ok := rules["maxPrice"].(float64); ok {
+		filter.MaxPrice = &maxPrice
+	}
+	filter.Genres = stringList(rules["genres"])
+	filter.Region, _ = rules["region"].(string)
+	filter.Hidden = stringList(rules["hiddenFields"])
+
+	if ch.ttl > 0 {
+		ch.cache.Set(key, filter, ch.ttl)
+	}
+	return filter, nil
+}
+
+// stringList converts a decoded JSON array to its non-empty strings
+func stringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	var result []string
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// Filters asks the global checker for a user's list filter
+func Filters(ctx context.Context, userID, resource string) (ListFilter, error) {
+	if checker == nil {
+		return ListFilter{}, fmt.Errorf("permission checks are not configured")
+	}
+	return checker.Filters(ctx, userID, resource)
+}
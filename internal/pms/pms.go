@@ -0,0 +1,163 @@
+package pms
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"InternalAPI/internal/services"
+)
+
+// DefaultBackend is the adapter used by tenants without a PMS mapping
+const DefaultBackend = "api-beheerder"
+
+// Adapter routes booking and room calls to a property management system.
+// Endpoints are the API Beheerder paths the handlers already use, such as
+// /bookings?hotel_id=... or /rooms/:id/status; an adapter translates them for
+// its backend and returns the decoded JSON response.
+type Adapter interface {
+	Name() string
+	Call(ctx context.Context, method, endpoint string, data interface{}) (map[string]interface{}, error)
+}
+
+// HTTPAdapter is the reference adapter for backends that speak the API
+// Beheerder booking and room contract over HTTP. Calls go through
+// ExternalService, so they share circuit breaking, mTLS, field encryption
+// and health reporting with every other upstream.
+type HTTPAdapter struct {
+	name     string
+	upstream string
+	service  *services.ExternalService
+}
+
+// NewHTTPAdapter creates an adapter for an upstream known to ExternalService
+func NewHTTPAdapter(name, upstream string, es *services.ExternalService) *HTTPAdapter {
+	return &HTTPAdapter{name: name, upstream: upstream, service: es}
+}
+
+// Name returns the adapter name recorded in audit entries
+func (a *HTTPAdapter) Name() string {
+	return a.name
+}
+
+// Call forwards the request to the adapter's upstream
+func (a *HTTPAdapter) Call(ctx context.Context, method, endpoint string, data interface{}) (map[string]interface{}, error) {
+	return a.service.Call(ctx, a.upstream, method, endpoint, data)
+}
+
+var (
+	registryMu sync.RWMutex
+	fallback   Adapter
+	adapters   = make(map[string]Adapter) // Adapter name to adapter
+	tenants    = make(map[string]Adapter) // Tenant to adapter
+	breakers   []string
+)
+
+// Register makes a custom adapter, such as one wrapping a vendor SDK,
+// available for tenant mappings. Call it before Init.
+func Register(adapter Adapter) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	adapters[adapter.Name()] = adapter
+}
+
+// Init sets up API Beheerder as the default backend, registers an HTTP
+// adapter for every name=url entry in backendSpec (with keys from keySpec,
+// also name=value) and maps tenants to adapters from tenantSpec entries of
+// the form tenant=adapter. Each HTTP backend becomes an upstream named
+// pms-<name>, whose circuit breaker the caller initializes from Breakers.
+func Init(es *services.ExternalService, backendSpec, keySpec, tenantSpec string) error {
+	keys, err := parsePairs(keySpec, "PMS adapter key")
+	if err != nil {
+		return err
+	}
+	backends, err := parsePairs(backendSpec, "PMS adapter")
+	if err != nil {
+		return err
+	}
+	mapping, err := parsePairs(tenantSpec, "PMS tenant")
+	if err != nil {
+		return err
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	fallback = NewHTTPAdapter(DefaultBackend, "beheerder", es)
+	adapters[DefaultBackend] = fallback
+	breakers = nil
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		u, err := url.Parse(backends[name])
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("PMS adapter %s: invalid URL %q", name, backends[name])
+		}
+		if _, exists := adapters[name]; exists {
+			return fmt.Errorf("PMS adapter %s is already registered", name)
+		}
+		upstream := "pms-" + name
+		services.RegisterUpstream(services.Upstream{
+			Name:        upstream,
+			Aliases:     []string{upstream},
+			Description: "Property management system " + name + ": bookings and rooms",
+			URL:         strings.TrimSuffix(backends[name], "/"),
+			Key:         keys[name],
+		})
+		adapters[name] = NewHTTPAdapter(name, upstream, es)
+		breakers = append(breakers, upstream)
+	}
+
+	tenants = make(map[string]Adapter, len(mapping))
+	for tenant, name := range mapping {
+		adapter, ok := adapters[name]
+		if !ok {
+			return fmt.Errorf("PMS tenant %s: unknown adapter %q", tenant, name)
+		}
+		tenants[tenant] = adapter
+	}
+	return nil
+}
+
+// parsePairs reads comma-separated name=value entries
+func parsePairs(spec, what string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid %s entry %q (expected name=value)", what, entry)
+		}
+		pairs[name] = value
+	}
+	return pairs, nil
+}
+
+// Breakers returns the circuit breaker names of the configured HTTP backends
+func Breakers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]string(nil), breakers...)
+}
+
+// For returns the adapter serving tenant, falling back to API Beheerder.
+// It returns nil before Init.
+func For(tenant string) Adapter {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if adapter, ok := tenants[tenant]; ok && tenant != "" {
+		return adapter
+	}
+	return fallback
+}
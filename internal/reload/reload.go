@@ -0,0 +1,278 @@
+// Package reload applies configuration changes to the running gateway. A
+// reload re-reads the config file; settings with a registered applier take
+// effect at once, others are reported as needing a restart.
+package reload
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logging.Logger()
+
+var reloads = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "config_reloads_total",
+	Help:      "Configuration reloads by result (ok, failed)",
+}, []string{"result"})
+
+// Apply puts the settings of next into effect; previous is the
+// configuration in effect before
+type Apply func(previous, next *config.Config) error
+
+// applier applies a group of settings
+type applier struct {
+	name   string
+	fields []string
+	apply  Apply
+}
+
+// Status describes the latest reload
+type Status struct {
+	Trigger         string    `json:"trigger"` // startup, signal, file, secrets, remote or admin
+	At              time.Time `json:"at"`
+	OK              bool      `json:"ok"`
+	Error           string    `json:"error,omitempty"`
+	Applied         []string  `json:"applied"`          // Settings changed and in effect
+	RestartRequired []string  `json:"restart_required"` // Settings changed that only apply after a restart
+}
+
+var (
+	mu       sync.Mutex
+	current  *config.Config
+	file     string
+	appliers []applier
+	last     = Status{Trigger: "startup", Applied: []string{}, RestartRequired: []string{}}
+	modTime  time.Time
+	stop     chan struct{}
+)
+
+// Init records the configuration the gateway started with and the config
+// file reloads read, if any
+func Init(cfg *config.Config, path string) {
+	mu.Lock()
+	defer mu.Unlock()
+	snapshot := *cfg
+	current = &snapshot
+	file = path
+	last.At = time.Now()
+	last.OK = true
+	if path != "" {
+		if info, err := os.Stat(path); err == nil {
+			modTime = info.ModTime()
+		}
+	}
+}
+
+// Register adds an applier for the named settings (Config field names).
+// Appliers run in registration order on every reload that changes one of
+// their settings.
+func Register(name string, fields []string, apply Apply) {
+	mu.Lock()
+	defer mu.Unlock()
+	appliers = append(appliers, applier{name: name, fields: fields, apply: apply})
+}
+
+// Effective returns the configuration in effect: the startup values, with
+// the reloadable settings at their latest values
+func Effective() *config.Config {
+	mu.Lock()
+	defer mu.Unlock()
+	snapshot := *current
+	return &snapshot
+}
+
+// Reloadable returns the names of the settings that can change without a
+// restart, sorted
+func Reloadable() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	var fields []string
+	for _, a := range appliers {
+		fields = append(fields, a.fields...)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Last returns the status of the latest reload
+func Last() Status {
+	mu.Lock()
+	defer mu.Unlock()
+	return last
+}
+
+// Reload reads the configuration again and applies the changed reloadable
+// settings. A config file that cannot be read, or a configuration failing
+// validation or hardened mode, changes nothing; an applier rejecting its settings keeps
+// only that group as it was.
+func Reload(trigger string) Status {
+	mu.Lock()
+	defer mu.Unlock()
+
+	status := Status{Trigger: trigger, At: time.Now(), Applied: []string{}, RestartRequired: []string{}}
+	status.OK, status.Error = true, ""
+	if err := reload(&status); err != nil {
+		status.OK, status.Error = false, err.Error()
+		reloads.WithLabelValues("failed").Inc()
+		log.WithError(err).WithField("trigger", trigger).Error("Configuration reload failed")
+	} else {
+		reloads.WithLabelValues("ok").Inc()
+		log.WithFields(logrus.Fields{
+			"trigger":          trigger,
+			"applied":          status.Applied,
+			"restart_required": status.RestartRequired,
+		}).Info("Configuration reloaded")
+	}
+	last = status
+	return status
+}
+
+// reload does the work of Reload; callers hold mu
+func reload(status *Status) error {
+	if file != "" {
+		if err := config.ApplyFile(file); err != nil {
+			return fmt.Errorf("failed to read config file: %v", err)
+		}
+	}
+	next := config.Load()
+	if problems := next.Validate(); len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	// Settings decided by flags at startup
+	next.Hardened = current.Hardened
+	if next.Hardened {
+		next.Environment = current.Environment
+		if violations := next.HardeningViolations(); len(violations) > 0 {
+			return fmt.Errorf("hardened mode requirements not met: %v", violations)
+		}
+	}
+	if next.ProductionMode() {
+		if problems := next.ProductionProblems(); len(problems) > 0 {
+			return fmt.Errorf("not fit for production: %s", strings.Join(problems, "; "))
+		}
+	}
+
+	changed := make(map[string]bool)
+	for _, name := range config.ChangedFields(current, next) {
+		changed[name] = true
+	}
+
+	effective := *current
+	handled := make(map[string]bool)
+	var failed []string
+	for _, a := range appliers {
+		touched := false
+		for _, field := range a.fields {
+			handled[field] = true
+			touched = touched || changed[field]
+		}
+		if !touched {
+			continue
+		}
+		if err := a.apply(current, next); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", a.name, err))
+			continue
+		}
+		effective.CopyFields(next, a.fields)
+		for _, field := range a.fields {
+			if changed[field] {
+				status.Applied = append(status.Applied, field)
+			}
+		}
+	}
+	for name := range changed {
+		if !handled[name] {
+			status.RestartRequired = append(status.RestartRequired, name)
+		}
+	}
+	sort.Strings(status.Applied)
+	sort.Strings(status.RestartRequired)
+	current = &effective
+	if len(failed) > 0 {
+		return fmt.Errorf("%s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Watch reloads on SIGHUP and, with a positive interval and a config file,
+// whenever the file's modification time changes, until Stop is called
+func Watch(interval time.Duration) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	mu.Lock()
+	stop = done
+	path := file
+	mu.Unlock()
+
+	go func() {
+		defer signal.Stop(signals)
+		var tick <-chan time.Time
+		if interval > 0 && path != "" {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		for {
+			select {
+			case <-signals:
+				Reload("signal")
+			case <-tick:
+				if fileChanged(path) {
+					Reload("file")
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// fileChanged reports whether the config file was modified since the
+// last check
+func fileChanged(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if info.ModTime().Equal(modTime) {
+		return false
+	}
+	modTime = info.ModTime()
+	return true
+}
+
+// Stop ends watching for changes and refreshing secrets and remote settings
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+	if stop != nil {
+		close(stop)
+		stop = nil
+	}
+	if stopSecrets != nil {
+		close(stopSecrets)
+		stopSecrets = nil
+	}
+	if stopRemote != nil {
+		close(stopRemote)
+		stopRemote = nil
+	}
+}
@@ -0,0 +1,58 @@
+package reload
+
+import (
+	"context"
+	"time"
+
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var configSyncs = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "config_sync_total",
+	Help:      "Fetches of settings from Central Management by result (unchanged, changed, failed)",
+}, []string{"result"})
+
+var stopRemote chan struct{}
+
+// WatchRemote fetches the settings from Central Management every interval
+// and reloads the configuration when one changed, until Stop is called. A
+// failed fetch keeps the settings in use.
+func WatchRemote(source config.RemoteSource, precedence string, interval time.Duration) {
+	done := make(chan struct{})
+	mu.Lock()
+	stopRemote = done
+	mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				refreshRemote(source, precedence)
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// refreshRemote fetches the settings once and reloads if they changed
+func refreshRemote(source config.RemoteSource, precedence string) {
+	changed, err := config.LoadRemote(context.Background(), source, precedence)
+	switch {
+	case err != nil:
+		configSyncs.WithLabelValues("failed").Inc()
+		log.WithError(err).Warn("Failed to sync settings from Central Management")
+	case changed:
+		configSyncs.WithLabelValues("changed").Inc()
+		log.WithField("ignored", config.LastRemoteSync().Ignored).Info("Settings changed in Central Management")
+		Reload("remote")
+	default:
+		configSyncs.WithLabelValues("unchanged").Inc()
+	}
+}
@@ -0,0 +1,58 @@
+package reload
+
+import (
+	"context"
+	"time"
+
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var secretRefreshes = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "secrets_refresh_total",
+	Help:      "Fetches from the secrets backend by result (unchanged, rotated, failed)",
+}, []string{"result"})
+
+var stopSecrets chan struct{}
+
+// WatchSecrets fetches the secrets every interval and reloads the
+// configuration when one was rotated, until Stop is called. A failed fetch
+// keeps the secrets in use.
+func WatchSecrets(provider config.SecretProvider, interval time.Duration) {
+	done := make(chan struct{})
+	mu.Lock()
+	stopSecrets = done
+	mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				refreshSecrets(provider)
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// refreshSecrets fetches the secrets once and reloads if they changed
+func refreshSecrets(provider config.SecretProvider) {
+	changed, err := config.LoadSecrets(context.Background(), provider)
+	switch {
+	case err != nil:
+		secretRefreshes.WithLabelValues("failed").Inc()
+		log.WithError(err).Warn("Failed to refresh secrets")
+	case changed:
+		secretRefreshes.WithLabelValues("rotated").Inc()
+		log.WithField("provider", provider.Name()).Info("Secrets rotated")
+		Reload("secrets")
+	default:
+		secretRefreshes.WithLabelValues("unchanged").Inc()
+	}
+}
@@ -0,0 +1,447 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/events"
+
+	"github.com/google/uuid"
+)
+
+// CircuitState represents the state of a circuit breaker
+type CircuitState int
+
+const (
+	StateClosed CircuitState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+// CircuitBreaker implements the circuit breaker pattern for external services
+type CircuitBreaker struct {
+	serviceName      string
+	failureThreshold int
+	timeout          time.Duration
+	maxRetries       int
+	retryDelay       time.Duration
+	halfOpenProbes   int // Calls let through at once while half-open
+	successThreshold int // Probe successes in a row that close the circuit
+	trip             TripPolicy
+	errorRate        float64 // Percentage of failed calls that opens the circuit
+	minCalls         int     // Calls needed in the window before the error rate counts
+	window           *slidingWindow
+
+	state        CircuitState
+	failures     int
+	successes    int // Probe successes in a row while half-open
+	probes       int // Probes in progress
+	lastFailTime time.Time
+	mutex        sync.RWMutex
+}
+
+// OpenError is returned when a call is rejected because the circuit is open
+type OpenError struct {
+	ServiceName string
+	RetryAfter  time.Duration // Remaining time before the breaker allows a trial call
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("circuit breaker is open for service %s", e.ServiceName)
+}
+
+// ServiceMetrics tracks metrics for service calls
+type ServiceMetrics struct {
+	TotalCalls   int64
+	SuccessCalls int64
+	FailureCalls int64
+	CircuitOpen  bool
+	LastCallTime time.Time
+	mutex        sync.RWMutex
+}
+
+// Global circuit breakers and metrics for each service
+var (
+	circuitBreakers map[string]*CircuitBreaker
+	serviceMetrics  map[string]*ServiceMetrics
+	cbMutex         sync.RWMutex
+)
+
+// Settings configure a circuit breaker
+type Settings struct {
+	FailureThreshold int           // Failures in a row that open the circuit (consecutive policy)
+	Timeout          time.Duration // How long an open circuit rejects calls
+	MaxRetries       int
+	RetryDelay       time.Duration
+	HalfOpenProbes   int // Trial calls let through at once while half-open, at least 1
+	SuccessThreshold int // Trial calls in a row that close the circuit, at least 1
+
+	Trip               TripPolicy    // Empty means TripConsecutive
+	ErrorRateThreshold float64       // Percentage of failed calls above which the circuit opens (error-rate policy)
+	Window             time.Duration // Sliding window the error rate is computed over
+	MinCalls           int           // Calls needed in the window before the error rate counts
+}
+
+// InitBreaker initializes the circuit breaker of a service
+func InitBreaker(serviceName string, settings Settings) {
+	cbMutex.Lock()
+	defer cbMutex.Unlock()
+	
+	if circuitBreakers == nil {
+		circuitBreakers = make(map[string]*CircuitBreaker)
+		serviceMetrics = make(map[string]*ServiceMetrics)
+	}
+
+	trip := settings.Trip
+	if trip == "" {
+		trip = TripConsecutive
+	}
+	
+	circuitBreakers[serviceName] = &CircuitBreaker{
+		serviceName:      serviceName,
+		failureThreshold: settings.FailureThreshold,
+		timeout:          settings.Timeout,
+		maxRetries:       settings.MaxRetries,
+		retryDelay:       settings.RetryDelay,
+		halfOpenProbes:   max(settings.HalfOpenProbes, 1),
+		successThreshold: max(settings.SuccessThreshold, 1),
+		trip:             trip,
+		errorRate:        settings.ErrorRateThreshold,
+		minCalls:         max(settings.MinCalls, 1),
+		window:           newSlidingWindow(settings.Window),
+		state:            StateClosed,
+		failures:         0,
+	}
+	serviceMetrics[serviceName] = &ServiceMetrics{}
+	breakerState.WithLabelValues(serviceName).Set(float64(StateClosed))
+}
+
+// Settings returns the breaker's current configuration
+func (cb *CircuitBreaker) Settings() Settings {
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+	return Settings{
+		FailureThreshold:   cb.failureThreshold,
+		Timeout:            cb.timeout,
+		MaxRetries:         cb.maxRetries,
+		RetryDelay:         cb.retryDelay,
+		HalfOpenProbes:     cb.halfOpenProbes,
+		SuccessThreshold:   cb.successThreshold,
+		Trip:               cb.trip,
+		ErrorRateThreshold: cb.errorRate,
+		Window:             cb.window.size,
+		MinCalls:           cb.minCalls,
+	}
+}
+
+// Configure changes the breaker's configuration at runtime. The state is
+// kept; the error-rate window starts empty if the trip policy or window
+// changes.
+func (cb *CircuitBreaker) Configure(settings Settings) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if settings.Trip == "" {
+		settings.Trip = TripConsecutive
+	}
+	if settings.Trip != cb.trip || settings.Window != cb.window.size {
+		cb.window = newSlidingWindow(settings.Window)
+	}
+	cb.failureThreshold = settings.FailureThreshold
+	cb.timeout = settings.Timeout
+	cb.maxRetries = settings.MaxRetries
+	cb.retryDelay = settings.RetryDelay
+	cb.halfOpenProbes = max(settings.HalfOpenProbes, 1)
+	cb.successThreshold = max(settings.SuccessThreshold, 1)
+	cb.trip = settings.Trip
+	cb.errorRate = settings.ErrorRateThreshold
+	cb.minCalls = max(settings.MinCalls, 1)
+}
+
+// Breaker returns the circuit breaker of a service, or nil
+func Breaker(serviceName string) *CircuitBreaker {
+	cbMutex.RLock()
+	defer cbMutex.RUnlock()
+	
+	cb, exists := circuitBreakers[serviceName]
+	if !exists {
+		return nil
+	}
+	return cb
+}
+
+// Call attempts to make a call through the circuit breaker. The breaker is
+// not locked while fn runs, so calls to a service run concurrently. Once
+// the open timeout has passed, at most halfOpenProbes calls at a time are
+// let through as probes; the circuit closes after successThreshold probes
+// in a row succeed and opens again when one fails.
+func (cb *CircuitBreaker) Call(fn func() error) error {
+	cb.mutex.Lock()
+	from := cb.state
+
+	// Check if circuit is open
+	if cb.state == StateOpen {
+		if remaining := cb.timeout - time.Since(cb.lastFailTime); remaining > 0 {
+			cb.mutex.Unlock()
+			breakerRejections.WithLabelValues(cb.serviceName).Inc()
+			return &OpenError{ServiceName: cb.serviceName, RetryAfter: remaining}
+		}
+		// Transition to half-open
+		cb.state = StateHalfOpen
+		cb.successes = 0
+	}
+
+	probe := cb.state == StateHalfOpen
+	if probe {
+		if cb.probes >= cb.halfOpenProbes {
+			cb.mutex.Unlock()
+			cb.announce(from)
+			// Other probes are still out; their outcome decides the state
+			breakerRejections.WithLabelValues(cb.serviceName).Inc()
+			return &OpenError{ServiceName: cb.serviceName}
+		}
+		cb.probes++
+	}
+	cb.mutex.Unlock()
+	// Announced outside the lock, so subscribers never hold up the breaker
+	cb.announce(from)
+
+	// Attempt the call
+	err := fn()
+
+	cb.mutex.Lock()
+	from = cb.state
+	defer func() { cb.announce(from) }()
+	defer cb.mutex.Unlock()
+
+	if probe {
+		cb.probes--
+	}
+
+	// A caller that gave up says nothing about the service's health
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	now := time.Now()
+	if !probe && cb.state == StateClosed {
+		cb.window.record(now, err != nil)
+	}
+
+	if err != nil {
+		cb.failures++
+		cb.lastFailTime = now
+
+		// A failed probe reopens the circuit at once; otherwise the trip policy decides
+		if probe || (cb.state == StateClosed && cb.shouldTrip(now)) {
+			cb.state = StateOpen
+			cb.successes = 0
+			cb.window.reset()
+		}
+	} else if probe && cb.state == StateHalfOpen {
+		cb.successes++
+		if cb.successes >= cb.successThreshold {
+			cb.state = StateClosed
+			cb.failures = 0
+			cb.window.reset()
+		}
+	} else if cb.state == StateClosed {
+		// Reset on success
+		cb.failures = 0
+	}
+
+	// Update metrics
+	cbMutex.RLock()
+	metrics := serviceMetrics[cb.serviceName]
+	cbMutex.RUnlock()
+
+	if metrics != nil {
+		metrics.mutex.Lock()
+		metrics.TotalCalls++
+		metrics.LastCallTime = time.Now()
+		if err != nil {
+			metrics.FailureCalls++
+		} else {
+			metrics.SuccessCalls++
+		}
+		metrics.CircuitOpen = (cb.state == StateOpen)
+		metrics.mutex.Unlock()
+	}
+
+	return err
+}
+
+// shouldTrip reports whether a closed circuit should open after a failure.
+// The caller holds the lock.
+func (cb *CircuitBreaker) shouldTrip(now time.Time) bool {
+	if cb.trip == TripErrorRate {
+		total, failures := cb.window.counts(now)
+		return total >= cb.minCalls && float64(failures)*100/float64(total) > cb.errorRate
+	}
+	return cb.failures >= cb.failureThreshold
+}
+
+// errorRateLocked returns the percentage of failed calls in the window.
+// The caller holds the lock.
+func (cb *CircuitBreaker) errorRateLocked(now time.Time) float64 {
+	total, failures := cb.window.counts(now)
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(failures)*10000/float64(total)) / 100
+}
+
+// HTTPCall makes an HTTP call through the circuit breaker
+func (cb *CircuitBreaker) HTTPCall(client *http.Client, req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+
+	callErr := cb.Call(func() error {
+		resp, err = client.Do(req)
+		if err != nil {
+			return err
+		}
+		
+		// Consider 5xx responses as failures
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("server error: %d", resp.StatusCode)
+		}
+		
+		return nil
+	})
+
+	if callErr != nil {
+		return nil, callErr
+	}
+
+	return resp, err
+}
+
+// Reset resets the circuit breaker state
+func (cb *CircuitBreaker) Reset() {
+	cb.mutex.Lock()
+	from := cb.state
+	cb.state = StateClosed
+	cb.failures = 0
+	cb.successes = 0
+	cb.window.reset()
+	cb.mutex.Unlock()
+
+	cb.announce(from)
+}
+
+// announce reports a state transition to the state metrics, the
+// OnStateChange listeners and the admin event bus
+func (cb *CircuitBreaker) announce(from CircuitState) {
+	cb.mutex.RLock()
+	to, failures := cb.state, cb.failures
+	cb.mutex.RUnlock()
+	if to == from {
+		return
+	}
+
+	now := time.Now()
+	notify(StateChange{Service: cb.serviceName, From: from, To: to, Failures: failures, At: now})
+
+	events.PublishAdmin(events.Event{
+		ID:         uuid.New().String(),
+		Type:       "circuit_breaker.state_changed",
+		Source:     "internal-api",
+		OccurredAt: now,
+		Data: map[string]interface{}{
+			"service":  cb.serviceName,
+			"from":     from.String(),
+			"to":       to.String(),
+			"failures": failures,
+		},
+	})
+}
+
+// GetState returns the current state of the circuit breaker
+func (cb *CircuitBreaker) GetState() CircuitState {
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+	return cb.state
+}
+
+// BreakerStatus returns the status of all circuit breakers
+func BreakerStatus() map[string]interface{} {
+	cbMutex.RLock()
+	defer cbMutex.RUnlock()
+
+	status := make(map[string]interface{})
+	
+	for serviceName, cb := range circuitBreakers {
+		metrics := serviceMetrics[serviceName]
+		
+		var successRate float64
+		if metrics != nil && metrics.TotalCalls > 0 {
+			successRate = math.Round((float64(metrics.SuccessCalls)/float64(metrics.TotalCalls))*10000) / 100
+		}
+
+		cb.mutex.RLock()
+		windowErrorRate := cb.errorRateLocked(time.Now())
+		cb.mutex.RUnlock()
+
+		status[serviceName] = map[string]interface{}{
+			"state":         cb.GetState(),
+			"policy":        cb.trip,
+			"failures":      cb.failures,
+			"window_error_rate": windowErrorRate,
+			"total_calls":   metrics.TotalCalls,
+			"success_calls": metrics.SuccessCalls,
+			"failure_calls": metrics.FailureCalls,
+			"success_rate":  successRate,
+			"last_call":     metrics.LastCallTime.Unix(),
+		}
+	}
+
+	return status
+}
+
+// BreakerNames returns the services that have a circuit breaker, sorted
+func BreakerNames() []string {
+	cbMutex.RLock()
+	defer cbMutex.RUnlock()
+
+	names := make([]string, 0, len(circuitBreakers))
+	for name := range circuitBreakers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResetBreaker resets the circuit breaker of a service
+func ResetBreaker(serviceName string) error {
+	cbMutex.RLock()
+	cb, exists := circuitBreakers[serviceName]
+	cbMutex.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("circuit breaker for service %s not found", serviceName)
+	}
+
+	cb.Reset()
+	return nil
+}
+
+// String returns a string representation of the circuit state
+func (s CircuitState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
\ No newline at end of file
@@ -0,0 +1,116 @@
+package resilience
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	bulkheadMu       sync.Mutex
+	bulkheadFallback int
+	bulkheadLimits   = map[string]int{}
+	bulkheads        = map[string]chan struct{}{}
+)
+
+var (
+	bulkheadRejections = metrics.Factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "upstream_bulkhead_rejected_total",
+			Help:      "Backend calls rejected because the service's concurrency limit was reached",
+		},
+		[]string{"service"},
+	)
+
+	bulkheadInUse = metrics.Factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Name:      "upstream_bulkhead_in_use",
+			Help:      "Backend calls holding a slot of the service's concurrency limit",
+		},
+		[]string{"service"},
+	)
+)
+
+// BulkheadError is returned when a backend service already has as many
+// calls running as its concurrency limit allows
+type BulkheadError struct {
+	Service string
+	Limit   int
+}
+
+func (e *BulkheadError) Error() string {
+	return fmt.Sprintf("%s already has %d calls in progress", e.Service, e.Limit)
+}
+
+// InitBulkheads limits how many calls may run at once per upstream: perService
+// by upstream name and fallback for the others. A limit of 0 means no limit.
+func InitBulkheads(fallback int, perService map[string]int) {
+	bulkheadMu.Lock()
+	defer bulkheadMu.Unlock()
+	bulkheadFallback = fallback
+	bulkheadLimits = perService
+	bulkheads = map[string]chan struct{}{}
+}
+
+// ParseServiceLimits parses a spec like "central-mgmt=20,pms-opera=5"
+func ParseServiceLimits(spec string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		service, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("service limit %q is missing =limit", entry)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid limit for service %s: %q", service, value)
+		}
+		limits[strings.TrimSpace(service)] = limit
+	}
+	return limits, nil
+}
+
+// Acquire takes a slot of the service's bulkhead without waiting. The
+// returned function gives it back.
+func Acquire(service string) (release func(), err error) {
+	bulkheadMu.Lock()
+	slots, ok := bulkheads[service]
+	if !ok {
+		limit, configured := bulkheadLimits[service]
+		if !configured {
+			limit = bulkheadFallback
+		}
+		if limit > 0 {
+			slots = make(chan struct{}, limit)
+		}
+		bulkheads[service] = slots
+	}
+	bulkheadMu.Unlock()
+
+	if slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case slots <- struct{}{}:
+		gauge := bulkheadInUse.WithLabelValues(service)
+		gauge.Inc()
+		return func() {
+			gauge.Dec()
+			<-slots
+		}, nil
+	default:
+		bulkheadRejections.WithLabelValues(service).Inc()
+		return nil, &BulkheadError{Service: service, Limit: cap(slots)}
+	}
+}
@@ -0,0 +1,78 @@
+package resilience
+
+import (
+	"sync"
+	"time"
+
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StateChange describes one circuit breaker transition
+type StateChange struct {
+	Service  string
+	From     CircuitState
+	To       CircuitState
+	Failures int // Failures counted when the transition happened
+	At       time.Time
+}
+
+// Listener is called for every state transition. Listeners run on the
+// caller's goroutine after the breaker is unlocked, so they must be quick.
+type Listener func(StateChange)
+
+var (
+	listenersMu sync.RWMutex
+	listeners   []Listener
+)
+
+var (
+	breakerState = metrics.Factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Name:      "circuit_breaker_state",
+			Help:      "Circuit breaker state per service: 0 closed, 1 open, 2 half-open",
+		},
+		[]string{"service"},
+	)
+
+	breakerTransitions = metrics.Factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "circuit_breaker_transitions_total",
+			Help:      "Circuit breaker state transitions per service",
+		},
+		[]string{"service", "from", "to"},
+	)
+
+	breakerRejections = metrics.Factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "circuit_breaker_rejected_total",
+			Help:      "Calls failed fast by an open circuit or a half-open circuit with all probes out",
+		},
+		[]string{"service"},
+	)
+)
+
+// OnStateChange registers a listener called for every state transition of
+// every breaker
+func OnStateChange(listener Listener) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+	listeners = append(listeners, listener)
+}
+
+// notify updates the state metrics and calls the registered listeners
+func notify(change StateChange) {
+	breakerState.WithLabelValues(change.Service).Set(float64(change.To))
+	breakerTransitions.WithLabelValues(change.Service, change.From.String(), change.To.String()).Inc()
+
+	listenersMu.RLock()
+	hooks := append([]Listener(nil), listeners...)
+	listenersMu.RUnlock()
+	for _, listener := range hooks {
+		listener(change)
+	}
+}
@@ -0,0 +1,274 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/tracing"
+)
+
+// Call is one attempt at a backend call. It must stop when ctx is done.
+type Call func(ctx context.Context) error
+
+// Policy protects a backend call in one way, such as a timeout or a circuit
+// breaker. Apply runs next, the rest of the chain, zero or more times.
+type Policy interface {
+	Apply(ctx context.Context, service string, next Call) error
+}
+
+// PolicyFunc lets an ordinary function be used as a Policy
+type PolicyFunc func(ctx context.Context, service string, next Call) error
+
+// Apply calls f
+func (f PolicyFunc) Apply(ctx context.Context, service string, next Call) error {
+	return f(ctx, service, next)
+}
+
+var (
+	chainsMu sync.RWMutex
+	policies = map[string]Policy{}
+	// Retries are opt-in because not every backend can take a repeated call
+	defaultChain  = []string{"fallback", "bulkhead", "timeout", "breaker"}
+	serviceChains = map[string][]string{}
+)
+
+func init() {
+	Register("fallback", PolicyFunc(applyFallback))
+	Register("bulkhead", PolicyFunc(applyBulkhead))
+	Register("timeout", PolicyFunc(applyTimeout))
+	Register("retry", PolicyFunc(applyRetry))
+	Register("breaker", PolicyFunc(applyBreaker))
+}
+
+// Register makes a policy available to chains under name, replacing any
+// policy of that name. It must run before InitChains.
+func Register(name string, policy Policy) {
+	chainsMu.Lock()
+	defer chainsMu.Unlock()
+	policies[name] = policy
+}
+
+// InitChains sets which policies protect the calls to each service, by
+// policy name and from the outermost in: perService by upstream name and
+// fallback for the others
+func InitChains(fallback []string, perService map[string][]string) error {
+	chainsMu.Lock()
+	defer chainsMu.Unlock()
+	if err := checkChainLocked(fallback); err != nil {
+		return err
+	}
+	for service, chain := range perService {
+		if err := checkChainLocked(chain); err != nil {
+			return fmt.Errorf("chain of %s: %v", service, err)
+		}
+	}
+	defaultChain = fallback
+	serviceChains = perService
+	return nil
+}
+
+// checkChainLocked reports unknown and repeated policies in a chain
+func checkChainLocked(chain []string) error {
+	seen := make(map[string]bool, len(chain))
+	for _, name := range chain {
+		if _, ok := policies[name]; !ok {
+			return fmt.Errorf("unknown resilience policy %q", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("resilience policy %q is listed twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// ParseChain parses a spec like "fallback,bulkhead,timeout,breaker"
+func ParseChain(spec string) []string {
+	var chain []string
+	for _, name := range strings.Split(spec, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			chain = append(chain, name)
+		}
+	}
+	return chain
+}
+
+// ParseServiceChains parses a spec like
+// "central-mgmt=timeout,retry,breaker;pms-opera=bulkhead,breaker"
+func ParseServiceChains(spec string) (map[string][]string, error) {
+	chains := make(map[string][]string)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		service, chain, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("service chain %q is missing =policies", entry)
+		}
+		chains[strings.TrimSpace(service)] = ParseChain(chain)
+	}
+	return chains, nil
+}
+
+// ChainFor returns the policy names of a service's chain
+func ChainFor(service string) []string {
+	chainsMu.RLock()
+	defer chainsMu.RUnlock()
+	if chain, ok := serviceChains[service]; ok {
+		return chain
+	}
+	return defaultChain
+}
+
+// Execute runs call through the policy chain of service
+func Execute(ctx context.Context, service string, call Call) error {
+	names := ChainFor(service)
+	chainsMu.RLock()
+	chain := make([]Policy, len(names))
+	for i, name := range names {
+		chain[i] = policies[name]
+	}
+	chainsMu.RUnlock()
+
+	// Wrap from the innermost policy out
+	next := call
+	for i := len(chain) - 1; i >= 0; i-- {
+		policy, inner := chain[i], next
+		next = func(ctx context.Context) error {
+			return policy.Apply(ctx, service, inner)
+		}
+	}
+	return next(ctx)
+}
+
+// clientError is implemented by errors caused by the request rather than
+// the service, such as a backend's 4xx answer. They do not count against
+// the breaker and are not retried.
+type clientError interface {
+	ClientError() bool
+}
+
+// isClientError reports whether err was caused by the request
+func isClientError(err error) bool {
+	var clientErr clientError
+	return errors.As(err, &clientErr) && clientErr.ClientError()
+}
+
+type fallbackKey struct{}
+type idempotentKey struct{}
+
+// WithFallback returns a context in which the fallback policy answers a
+// call rejected by an open breaker with fallback. fallback gets the
+// breaker's error and returns nil if it could answer, or an error.
+func WithFallback(ctx context.Context, fallback func(error) error) context.Context {
+	return context.WithValue(ctx, fallbackKey{}, fallback)
+}
+
+// WithIdempotent returns a context in which the retry policy may repeat a
+// failed call
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// applyFallback answers calls rejected by an open breaker from the
+// context's fallback, if it has one
+func applyFallback(ctx context.Context, service string, next Call) error {
+	err := next(ctx)
+	fallback, _ := ctx.Value(fallbackKey{}).(func(error) error)
+	var openErr *OpenError
+	if fallback == nil || !errors.As(err, &openErr) {
+		return err
+	}
+	return fallback(err)
+}
+
+// applyBulkhead runs the call in a slot of the service's bulkhead
+func applyBulkhead(ctx context.Context, service string, next Call) error {
+	release, err := Acquire(service)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return next(ctx)
+}
+
+// applyTimeout bounds the call by the service's timeout. A deadline already
+// on ctx, such as a background job's, is used instead.
+func applyTimeout(ctx context.Context, service string, next Call) error {
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		return next(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, TimeoutFor(service))
+	defer cancel()
+	return next(ctx)
+}
+
+// applyRetry repeats failed idempotent calls up to the breaker's MaxRetries
+// times, doubling the RetryDelay between attempts. Client errors, rejected
+// calls and calls whose context is done are not repeated.
+func applyRetry(ctx context.Context, service string, next Call) error {
+	cb := Breaker(service)
+	idempotent, _ := ctx.Value(idempotentKey{}).(bool)
+	if cb == nil || !idempotent {
+		return next(ctx)
+	}
+	settings := cb.Settings()
+	delay := settings.RetryDelay
+
+	err := next(ctx)
+	for attempt := 0; attempt < settings.MaxRetries && !finalError(ctx, err); attempt++ {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		delay *= 2
+		err = next(ctx)
+	}
+	return err
+}
+
+// finalError reports whether repeating a call that returned err is useless
+func finalError(ctx context.Context, err error) bool {
+	var openErr *OpenError
+	var bulkheadErr *BulkheadError
+	return err == nil || ctx.Err() != nil || isClientError(err) ||
+		errors.As(err, &openErr) || errors.As(err, &bulkheadErr)
+}
+
+// applyBreaker runs the call through the service's circuit breaker. Client
+// errors are returned but count as successes, since the backend answered.
+func applyBreaker(ctx context.Context, service string, next Call) error {
+	cb := Breaker(service)
+	if cb == nil {
+		return fmt.Errorf("circuit breaker not initialized for service: %s", service)
+	}
+	ctx, span := tracing.Start(ctx, "circuit_breaker "+service, tracing.KindInternal)
+	defer span.End()
+	span.SetAttribute("circuit_breaker.state", cb.GetState().String())
+
+	var callErr error
+	err := cb.Call(func() error {
+		callErr = next(ctx)
+		if isClientError(callErr) {
+			return nil
+		}
+		return callErr
+	})
+	var openErr *OpenError
+	if errors.As(err, &openErr) {
+		span.SetAttribute("circuit_breaker.rejected", true)
+		span.SetError(err.Error())
+	}
+	if err == nil {
+		err = callErr
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+package resilience
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	timeoutsMu      sync.RWMutex
+	defaultTimeout  = 10 * time.Second
+	serviceTimeouts = map[string]time.Duration{}
+)
+
+// InitTimeouts sets how long a backend call may take: perService by upstream
+// name, such as api-beheerder or pms-opera, and fallback for the others
+func InitTimeouts(fallback time.Duration, perService map[string]time.Duration) {
+	timeoutsMu.Lock()
+	defer timeoutsMu.Unlock()
+	defaultTimeout = fallback
+	serviceTimeouts = perService
+}
+
+// ParseServiceTimeouts parses a spec like "api-beheerder=30,central-mgmt=5"
+// with timeouts in seconds
+func ParseServiceTimeouts(spec string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		service, seconds, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("service timeout %q is missing =seconds", entry)
+		}
+		timeout, err := strconv.Atoi(strings.TrimSpace(seconds))
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout for service %s: %q", service, seconds)
+		}
+		timeouts[strings.TrimSpace(service)] = time.Duration(timeout) * time.Second
+	}
+	return timeouts, nil
+}
+
+// TimeoutFor returns the call timeout of an upstream
+func TimeoutFor(service string) time.Duration {
+	timeoutsMu.RLock()
+	defer timeoutsMu.RUnlock()
+	if timeout, ok := serviceTimeouts[service]; ok {
+		return timeout
+	}
+	return defaultTimeout
+}
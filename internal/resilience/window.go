@@ -0,0 +1,103 @@
+package resilience
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TripPolicy decides when a closed circuit opens
+type TripPolicy string
+
+const (
+	// TripConsecutive opens after a number of failures in a row
+	TripConsecutive TripPolicy = "consecutive"
+	// TripErrorRate opens when the share of failed calls in a sliding time
+	// window is too high
+	TripErrorRate TripPolicy = "error_rate"
+)
+
+// windowBuckets is the number of buckets a sliding window is split into
+const windowBuckets = 10
+
+// ParseTripPolicies parses a spec like "central-mgmt=error_rate,pms-opera=consecutive"
+func ParseTripPolicies(spec string) (map[string]TripPolicy, error) {
+	policies := make(map[string]TripPolicy)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		service, name, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("breaker policy %q is missing =policy", entry)
+		}
+		policy, err := ParseTripPolicy(name)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %v", service, err)
+		}
+		policies[strings.TrimSpace(service)] = policy
+	}
+	return policies, nil
+}
+
+// ParseTripPolicy checks a trip policy name
+func ParseTripPolicy(name string) (TripPolicy, error) {
+	policy := TripPolicy(strings.TrimSpace(name))
+	if policy != TripConsecutive && policy != TripErrorRate {
+		return "", fmt.Errorf("unknown breaker policy %q", name)
+	}
+	return policy, nil
+}
+
+// bucket counts the calls of one slice of a sliding window
+type bucket struct {
+	start    time.Time
+	total    int
+	failures int
+}
+
+// slidingWindow counts calls and failures over the last size of time. It
+// is split into buckets, so calls expire a bucket at a time.
+type slidingWindow struct {
+	size    time.Duration
+	buckets [windowBuckets]bucket
+}
+
+// newSlidingWindow creates an empty window covering size
+func newSlidingWindow(size time.Duration) *slidingWindow {
+	return &slidingWindow{size: size}
+}
+
+// record adds the outcome of a call made at now
+func (w *slidingWindow) record(now time.Time, failed bool) {
+	width := w.size / windowBuckets
+	if width <= 0 {
+		width = time.Nanosecond
+	}
+	start := now.Truncate(width)
+	b := &w.buckets[int(start.UnixNano()/int64(width))%windowBuckets]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	b.total++
+	if failed {
+		b.failures++
+	}
+}
+
+// counts returns the calls and failures made within the window before now
+func (w *slidingWindow) counts(now time.Time) (total, failures int) {
+	for _, b := range w.buckets {
+		if now.Sub(b.start) < w.size {
+			total += b.total
+			failures += b.failures
+		}
+	}
+	return total, failures
+}
+
+// reset forgets every call
+func (w *slidingWindow) reset() {
+	w.buckets = [windowBuckets]bucket{}
+}
@@ -0,0 +1,179 @@
+package roles
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/services"
+)
+
+var log = logging.Logger()
+
+// Hierarchy maps each role to the roles and permissions it implies.
+// Permissions use "resource:action" form and may be wildcards such as
+// "albums:*" or "*".
+type Hierarchy struct {
+	grants map[string][]string
+}
+
+// New creates a hierarchy from a role -> implied grants map
+func New(grants map[string][]string) *Hierarchy {
+	copied := make(map[string][]string, len(grants))
+	for role, implied := range grants {
+		copied[role] = append([]string{}, implied...)
+	}
+	return &Hierarchy{grants: copied}
+}
+
+// Parse reads a hierarchy from a spec of "role:grant1|grant2" entries
+// separated by commas, e.g. "super_admin:admin,admin:user|albums:*"
+func Parse(spec string) (*Hierarchy, error) {
+	grants := make(map[string][]string)
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		role, implied, found := strings.Cut(entry, ":")
+		if !found || role == "" || implied == "" {
+			return nil, fmt.Errorf("invalid role hierarchy entry %q, expected role:grant1|grant2", entry)
+		}
+		grants[role] = append(grants[role], strings.Split(implied, "|")...)
+	}
+
+	return &Hierarchy{grants: grants}, nil
+}
+
+// Grants returns a copy of the role -> implied grants map
+func (h *Hierarchy) Grants() map[string][]string {
+	return New(h.grants).grants
+}
+
+// Expand returns the given roles plus every role and permission they imply
+func (h *Hierarchy) Expand(roles []string) []string {
+	seen := make(map[string]bool)
+	queue := append([]string{}, roles...)
+	var expanded []string
+
+	for len(queue) > 0 {
+		role := queue[0]
+		queue = queue[1:]
+		if seen[role] {
+			continue
+		}
+		seen[role] = true
+		expanded = append(expanded, role)
+		queue = append(queue, h.grants[role]...)
+	}
+	return expanded
+}
+
+// Satisfies reports whether a user holding roles meets any of the required
+// roles or permissions
+func (h *Hierarchy) Satisfies(roles []string, required ...string) bool {
+	for _, granted := range h.Expand(roles) {
+		for _, want := range required {
+			if Matches(granted, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Matches reports whether a granted role or permission covers a required one.
+// "*" covers everything and "albums:*" covers every albums permission.
+func Matches(granted, required string) bool {
+	if granted == required || granted == "*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(granted, "*"); ok {
+		return strings.HasPrefix(required, prefix)
+	}
+	return false
+}
+
+var (
+	current   = New(nil)
+	currentMu sync.RWMutex
+)
+
+// Set replaces the global hierarchy
+func Set(h *Hierarchy) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = h
+}
+
+// Current returns the global hierarchy
+func Current() *Hierarchy {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+	return current
+}
+
+// Satisfies checks roles against the global hierarchy
+func Satisfies(roles []string, required ...string) bool {
+	return Current().Satisfies(roles, required...)
+}
+
+// hierarchyResponse is the body of Central Management's /roles/hierarchy
+type hierarchyResponse struct {
+	Roles map[string][]string `json:"roles"`
+}
+
+// FetchFromCentral loads the hierarchy from Central Management's
+// /roles/hierarchy endpoint, which returns {"roles": {"role": ["grant", ...]}}
+func FetchFromCentral(es *services.ExternalService) (*Hierarchy, error) {
+	response, err := services.CallTyped[hierarchyResponse](context.Background(), es, "central", "GET", "/roles/hierarchy", nil)
+	if err != nil {
+		return nil, err
+	}
+	if response.Roles == nil {
+		return nil, fmt.Errorf("role hierarchy response has no roles object")
+	}
+
+	grants := make(map[string][]string, len(response.Roles))
+	for role, list := range response.Roles {
+		for _, grant := range list {
+			if grant != "" {
+				grants[role] = append(grants[role], grant)
+			}
+		}
+	}
+
+	return &Hierarchy{grants: grants}, nil
+}
+
+// StartRefresh fetches the hierarchy from Central Management in the
+// background, once at startup and then every interval. The configured
+// hierarchy stays in effect until a fetch succeeds and whenever one fails.
+func StartRefresh(es *services.ExternalService, interval time.Duration) {
+	refresh := func() {
+		h, err := FetchFromCentral(es)
+		if err != nil {
+			log.WithError(err).Warn("Failed to refresh role hierarchy, keeping current one")
+			return
+		}
+		Set(h)
+		log.WithField("roles", len(h.grants)).Info("Role hierarchy refreshed from Central Management")
+	}
+
+	go func() {
+		refresh()
+		if interval <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			refresh()
+		}
+	}()
+}
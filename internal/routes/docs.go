@@ -0,0 +1,157 @@
+package routes
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"InternalAPI/internal/changelog"
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeModels lists the models of routes with typed bodies or query
+// parameters for the generated OpenAPI document. Routes missing here are
+// still documented, with free-form JSON bodies. Keep it in sync when a
+// handler binds a new model.
+var routeModels = map[string]openapi.RouteDoc{
+	"POST /auth/login":   {Request: models.LoginRequest{}, Response: models.LoginResponse{}},
+	"POST /auth/refresh": {Request: models.RefreshTokenRequest{}, Response: models.LoginResponse{}},
+
+	"GET /api/v1/auth/me":              {Response: models.UserInfo{}},
+	"GET /api/v1/auth/token-status":    {Response: models.TokenStatus{}},
+	"POST /api/v1/auth/extend":         {Response: models.TokenExtensionResponse{}},
+	"PUT /api/v1/auth/change-password": {Request: models.ChangePasswordRequest{}},
+
+	"POST /api/v1/reservations/:id/messages":    {Request: models.MessageRequest{}, Status: http.StatusCreated},
+	"POST /api/v1/frontdesk/messages/:id/reply": {Request: models.MessageRequest{}, Status: http.StatusCreated},
+
+	"GET /public/v1/availability": {Query: models.PublicAvailabilityQuery{}, Response: models.PublicAvailabilityResponse{}},
+	"GET /api/v1/availability":    {Query: models.AvailabilityQuery{}, Response: models.AvailabilityResponse{}},
+
+	"GET /api/v1/dashboard": {Response: models.DashboardResponse{}},
+
+	"POST /api/v1/housekeeping/tasks":              {Request: models.HousekeepingTaskRequest{}, Status: http.StatusCreated},
+	"POST /api/v1/housekeeping/tasks/:id/complete": {Request: models.CompleteTaskRequest{}},
+	"POST /api/v1/housekeeping/tasks/:id/report":   {Request: models.ReportTaskRequest{}},
+
+	"GET /api/v1/albums":     {Query: models.ListQuery{}, Response: models.PaginatedResponse{}},
+	"GET /api/v1/albums/:id": {Response: models.Album{}},
+	"POST /api/v1/albums":    {Request: models.Album{}, Status: http.StatusCreated},
+	"PUT /api/v1/albums/:id": {Request: models.Album{}},
+
+	"GET /api/v1/bookings/:id": {Response: models.Booking{}},
+	"POST /api/v1/bookings":    {Request: models.CreateBookingRequest{}, Status: http.StatusCreated},
+	"PUT /api/v1/bookings/:id": {Request: models.UpdateBookingRequest{}},
+
+	"GET /api/v1/rooms/:id":          {Response: models.Room{}},
+	"POST /api/v1/rooms":             {Request: models.CreateRoomRequest{}, Status: http.StatusCreated},
+	"PUT /api/v1/rooms/:id":          {Request: models.UpdateRoomRequest{}},
+	"PATCH /api/v1/rooms/:id/status": {Request: models.RoomStatusRequest{}},
+
+	"POST /api/v1/guests":           {Request: models.GuestRequest{}, Status: http.StatusCreated},
+	"PUT /api/v1/guests/:id":        {Request: models.GuestRequest{}},
+	"GET /api/v1/guests/:id/export": {Response: models.GuestExport{}},
+
+	"GET /admin/users":                            {Query: models.ListQuery{}, Response: models.PaginatedResponse{}},
+	"GET /admin/users/:id":                        {Response: models.User{}},
+	"POST /admin/users":                           {Request: models.CreateUserRequest{}, Status: http.StatusCreated},
+	"PUT /admin/users/:id":                        {Request: models.UpdateUserRequest{}},
+	"POST /admin/users/:id/roles":                 {Request: models.AssignRoleRequest{}},
+	"GET /admin/system/stats":                     {Response: models.SystemStats{}},
+	"GET /admin/audit-logs":                       {Query: models.AuditLogQuery{}, Response: models.PaginatedResponse{}},
+	"POST /admin/maintenance-windows":             {Request: models.MaintenanceWindowRequest{}, Status: http.StatusCreated},
+	"PUT /admin/maintenance-windows/:id":          {Request: models.MaintenanceWindowRequest{}},
+	"PUT /admin/connections/policy":               {Request: models.StreamPolicyRequest{}},
+	"PUT /admin/circuit-breakers/:service/config": {Request: models.CircuitBreakerConfigRequest{}},
+	"POST /admin/jwt-keys/rotate":                 {Request: models.JWTKeyRotateRequest{}},
+}
+
+// undocumentedRoutes do not serve JSON and are left out of the document
+var undocumentedRoutes = map[string]bool{
+	"GET /metrics":              true,
+	"GET /docs":                 true,
+	"GET /docs/swagger-init.js": true,
+	"GET /debug/goroutines":     true,
+	"GET /debug/pprof/*profile": true,
+}
+
+// handlerName matches the method or function name at the end of a Gin
+// handler name such as InternalAPI/internal/handlers.(*RoomHandlers).GetRooms-fm,
+// skipping the closure suffix of handlers built by a constructor
+var handlerName = regexp.MustCompile(`\.([A-Za-z][A-Za-z0-9]*)(-fm|\.func[0-9]+)?$`)
+
+// camelWord splits handler names into words
+var camelWord = regexp.MustCompile(`[A-Z]+[a-z0-9]*|[a-z0-9]+`)
+
+// generateAPIDocs builds the OpenAPI document from the registered routes
+func generateAPIDocs(router *gin.Engine) ([]byte, error) {
+	var docs []openapi.RouteDoc
+	for _, route := range router.Routes() {
+		if undocumentedRoutes[route.Method+" "+route.Path] {
+			continue
+		}
+		doc := routeModels[route.Method+" "+route.Path]
+		doc.Method, doc.Path = route.Method, route.Path
+		doc.Summary = handlerSummary(route.Handler)
+		doc.Tag = routeTag(route.Path)
+		doc.Security = routeSecurity(route.Path)
+		docs = append(docs, doc)
+	}
+	return openapi.Generate("Hotel Internal API", changelog.Current(), docs, models.ErrorResponse{})
+}
+
+// handlerSummary turns a handler name such as GetRoomByID into "Get room by ID"
+func handlerSummary(handler string) string {
+	match := handlerName.FindStringSubmatch(handler)
+	if match == nil {
+		return ""
+	}
+	name := strings.TrimSuffix(match[1], "Handler")
+	words := camelWord.FindAllString(name, -1)
+	for i, word := range words {
+		if i > 0 && word != strings.ToUpper(word) {
+			words[i] = strings.ToLower(word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// routeTag groups a route by its first resource segment
+func routeTag(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, prefix := range [][]string{{"api", "v1"}, {"public", "v1"}} {
+		if len(segments) > len(prefix) && segments[0] == prefix[0] && segments[1] == prefix[1] {
+			return segments[2]
+		}
+	}
+	return segments[0]
+}
+
+// routeSecurity returns the schemes a route accepts, following the
+// authentication middleware of its route group
+func routeSecurity(path string) []string {
+	switch {
+	case strings.HasPrefix(path, "/api/v1/"):
+		return []string{openapi.SecurityBearer, openapi.SecurityCookie, openapi.SecurityAPIKey}
+	case strings.HasPrefix(path, "/admin/"), path == "/openapi.json":
+		return []string{openapi.SecurityBearer, openapi.SecurityCookie}
+	default:
+		return nil
+	}
+}
+
+// verifyChangelog warns at startup about changelog entries that disagree
+// with the registered routes, such as a renamed route still listed as added
+func verifyChangelog(router *gin.Engine) {
+	registered := make([]string, 0, len(router.Routes()))
+	for _, route := range router.Routes() {
+		registered = append(registered, route.Method+" "+route.Path)
+	}
+	for _, problem := range changelog.Verify(registered) {
+		logging.Logger().WithField("problem", problem).Warn("API changelog is out of date")
+	}
+}
@@ -1,44 +1,147 @@
 package routes
 
 import (
+	"strings"
+
+	"InternalAPI/internal/broker"
 	"InternalAPI/internal/config"
 	"InternalAPI/internal/handlers"
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
 	"InternalAPI/internal/middleware"
+	"InternalAPI/internal/services"
 	
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// upstreamRoutes lists the routes whose handlers or middleware call each
+// upstream. Keep it in sync when adding routes; /admin/dependencies reports it.
+var upstreamRoutes = map[string][]string{
+	"api-beheerder": {
+		"POST /callbacks/beheerder",
+		"GET /api/v1/availability", "GET /public/v1/availability",
+		"POST /api/v1/housekeeping/updates/stream",
+		"GET /api/v1/albums", "GET /api/v1/albums/:id", "POST /api/v1/albums", "PUT /api/v1/albums/:id", "PATCH /api/v1/albums/:id", "DELETE /api/v1/albums/:id",
+		"GET /api/v1/bookings", "GET /api/v1/bookings/:id", "POST /api/v1/bookings", "PUT /api/v1/bookings/:id", "PATCH /api/v1/bookings/:id", "DELETE /api/v1/bookings/:id",
+		"GET /api/v1/rooms", "GET /api/v1/rooms/:id", "POST /api/v1/rooms", "PUT /api/v1/rooms/:id", "PATCH /api/v1/rooms/:id", "DELETE /api/v1/rooms/:id", "PATCH /api/v1/rooms/:id/status",
+		"GET /api/v1/guests", "GET /api/v1/guests/:id", "POST /api/v1/guests", "PUT /api/v1/guests/:id", "PATCH /api/v1/guests/:id", "DELETE /api/v1/guests/:id",
+		"GET /api/v1/guests/:id/export", "DELETE /api/v1/guests/:id/personal-data",
+		"GET /api/v1/proxy/beheerder/*path", "POST /api/v1/proxy/beheerder/*path", "PUT /api/v1/proxy/beheerder/*path",
+		"PATCH /api/v1/proxy/beheerder/*path", "DELETE /api/v1/proxy/beheerder/*path",
+		"GET /api/v1/proxy/stream/beheerder/*path",
+		"POST /admin/actions/:name",
+		"POST /api/v1/graphql",
+	},
+	"central-mgmt": {
+		"POST /auth/login",
+		"POST /api/v1/auth/logout",
+		"PUT /api/v1/auth/change-password",
+		"GET /api/v1/availability", "GET /public/v1/availability",
+		// Albums, bookings, rooms, guests and proxy rules with a permission ask
+		// Central Management for permission decisions; guests also for per-user
+		// field filters
+		"GET /api/v1/albums", "GET /api/v1/albums/:id", "POST /api/v1/albums", "PUT /api/v1/albums/:id", "PATCH /api/v1/albums/:id", "DELETE /api/v1/albums/:id",
+		"GET /api/v1/bookings", "GET /api/v1/bookings/:id", "POST /api/v1/bookings", "PUT /api/v1/bookings/:id", "PATCH /api/v1/bookings/:id", "DELETE /api/v1/bookings/:id",
+		"GET /api/v1/rooms", "GET /api/v1/rooms/:id", "POST /api/v1/rooms", "PUT /api/v1/rooms/:id", "PATCH /api/v1/rooms/:id", "DELETE /api/v1/rooms/:id",
+		"GET /api/v1/guests", "GET /api/v1/guests/:id", "POST /api/v1/guests", "PUT /api/v1/guests/:id", "PATCH /api/v1/guests/:id", "DELETE /api/v1/guests/:id",
+		"GET /api/v1/guests/:id/export", "DELETE /api/v1/guests/:id/personal-data",
+		"GET /api/v1/proxy/beheerder/*path", "POST /api/v1/proxy/beheerder/*path", "PUT /api/v1/proxy/beheerder/*path",
+		"PATCH /api/v1/proxy/beheerder/*path", "DELETE /api/v1/proxy/beheerder/*path",
+		"GET /api/v1/proxy/stream/beheerder/*path",
+		"GET /admin/users", "GET /admin/users/:id", "POST /admin/users", "PUT /admin/users/:id", "DELETE /admin/users/:id",
+		"GET /admin/roles", "POST /admin/users/:id/roles", "DELETE /admin/users/:id/roles/:role",
+		"GET /admin/system/stats",
+		"GET /admin/audit-logs",
+		"POST /admin/actions/:name",
+		"POST /api/v1/graphql",
+	},
+}
+
 // Setup configures all routes for the application
 func Setup(router *gin.Engine, config *config.Config) {
 	// Create handler instances
 	authHandlers := handlers.NewAuthHandlers(config)
 	albumHandlers := handlers.NewAlbumHandlers(config)
 	adminHandlers := handlers.NewAdminHandlers(config)
+	capabilityHandlers := handlers.NewCapabilityHandlers(config)
+	availabilityHandlers := handlers.NewAvailabilityHandlers(config)
+	dashboardHandlers := handlers.NewDashboardHandlers(config)
+	messageHandlers := handlers.NewMessageHandlers(config)
+	housekeepingHandlers := handlers.NewHousekeepingHandlers(config)
+	streamHandlers := handlers.NewStreamHandlers(config)
+	bookingHandlers := handlers.NewBookingHandlers(config)
+	roomHandlers := handlers.NewRoomHandlers(config)
+	guestHandlers := handlers.NewGuestHandlers(config)
+	proxyHandlers := handlers.NewProxyHandlers(config)
+	healthHandlers := handlers.NewHealthHandlers(config)
 
 	// Public routes
-	router.GET("/health", handlers.HealthHandler)
+	router.GET("/health", healthHandlers.GetHealth)
+	router.GET("/health/live", healthHandlers.GetLiveness)
+	router.GET("/health/ready", healthHandlers.GetReadiness)
 	router.GET("/health/circuit-breakers", handlers.GetCircuitBreakerStatusHandler)
-	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.GET("/metrics", middleware.MetricsAuth(config.MetricsBasicAuthUser, config.MetricsBasicAuthPassword, config.MetricsAPIKey), gin.WrapH(metrics.Handler()))
+	router.GET("/errors", handlers.GetErrorCatalogHandler)
+
+	// Upstream callbacks are authenticated by HMAC signature, not JWT
+	router.POST("/callbacks/beheerder", handlers.BeheerderCallbackHandler)
 	
+	// Anonymous tier for the website availability widget. It shares no
+	// middleware with /api/v1: no authentication, its own per-IP limit that
+	// applies even when RATE_LIMIT_ENABLED is off, and bot mitigation hooks.
+	if config.PublicAvailabilityEnabled {
+		publicAvailability := handlers.NewPublicAvailabilityHandlers(config, availabilityHandlers)
+		public := router.Group("/public/v1")
+		public.Use(middleware.RateLimitByIP("public", config.PublicRateLimitRequests, config.PublicRateLimitInterval))
+		public.Use(middleware.BotGuard())
+		public.Use(middleware.MaintenanceGuard("api-beheerder", "availability", config.MaintenanceCacheTTL))
+		{
+			public.GET("/availability", middleware.ConditionalGET(middleware.WeakETag), publicAvailability.SearchAvailability)
+		}
+	}
+
+	// Live events for User Portal sessions over a WebSocket; browsers
+	// authenticate the handshake with the access token cookie. Guests only
+	// get the events addressed to them.
+	if config.FeatureWebSockets {
+		middleware.RegisterStreamingRoute("GET", "/ws")
+		router.GET("/ws",
+			middleware.JWTAuthMiddleware(),
+			streamHandlers.WebSocket)
+	}
+
 	// Authentication routes with strict rate limiting
 	auth := router.Group("/auth")
 	if config.RateLimitEnabled {
-		auth.Use(middleware.StrictRateLimitByIP(
+		auth.Use(middleware.StrictRateLimitByIP("login",
 			config.LoginRateLimitRequests,
 			config.LoginRateLimitInterval,
 		))
 	}
 	{
-		auth.POST("/login", authHandlers.Login)
-		auth.POST("/refresh", authHandlers.RefreshToken)
+		// Reputation scores password logins only; refreshes and SSO callbacks
+		// are not credential guesses
+		if config.ReputationEnabled {
+			auth.POST("/login", middleware.LoginReputation(), authHandlers.Login)
+		} else {
+			auth.POST("/login", authHandlers.Login)
+		}
+		auth.POST("/refresh", middleware.CSRF(), authHandlers.RefreshToken)
+		auth.GET("/oidc/login", authHandlers.OIDCLogin)
+		auth.GET("/oidc/callback", authHandlers.OIDCCallback)
 	}
 
-	// Protected routes (requires JWT authentication)
+	// Protected routes (requires JWT or service API key authentication). API
+	// keys are refused on every route not registered with HandleScoped.
 	protected := router.Group("/api/v1")
-	protected.Use(middleware.JWTAuthMiddleware())
+	protected.Use(middleware.JWTOrAPIKeyAuth())
+	protected.Use(middleware.CSRF())
+	if config.UsageTrackingEnabled {
+		protected.Use(middleware.UsageTracking())
+	}
+	protected.Use(middleware.ReadOnlyGuard())
 	if config.RateLimitEnabled {
-		protected.Use(middleware.RateLimitByUser(
+		protected.Use(middleware.RateLimitByUser("api",
 			config.RateLimitRequests,
 			config.RateLimitInterval,
 		))
@@ -47,22 +150,175 @@ func Setup(router *gin.Engine, config *config.Config) {
 		// Auth user info routes
 		protected.POST("/auth/logout", authHandlers.Logout)
 		protected.GET("/auth/me", authHandlers.GetUserInfo)
+		protected.GET("/auth/token-status", authHandlers.GetTokenStatus)
+		protected.POST("/auth/extend", authHandlers.ExtendToken)
+		protected.GET("/me/usage", handlers.GetMyUsageHandler)
 		protected.PUT("/auth/change-password", authHandlers.ChangePassword)
 
-		// Album/Hotel management routes
-		protected.GET("/albums", albumHandlers.GetAlbums)
-		protected.GET("/albums/:id", albumHandlers.GetAlbumByID)
-		protected.POST("/albums", albumHandlers.CreateAlbum)
-		protected.PUT("/albums/:id", albumHandlers.UpdateAlbum)
-		protected.DELETE("/albums/:id", albumHandlers.DeleteAlbum)
+		// Guest messaging
+		protected.POST("/reservations/:id/messages", messageHandlers.PostGuestMessage)
+		protected.GET("/reservations/:id/messages", messageHandlers.GetReservationMessages)
+
+		frontDesk := protected.Group("/frontdesk", middleware.RequireRoles("front_desk", "admin", "super_admin"))
+		frontDesk.GET("/messages", handlers.GetFrontDeskQueueHandler)
+		frontDesk.POST("/messages/:id/reply", handlers.ReplyToMessageHandler)
+		frontDesk.POST("/messages/:id/close", handlers.CloseMessageHandler)
+
+		// Feature detection for the portal
+		protected.GET("/capabilities", capabilityHandlers.GetCapabilities)
+
+		// Machine-readable API changelog for client teams
+		protected.GET("/changelog", handlers.GetChangelogHandler)
+
+		// Status and results of requests queued with Prefer: respond-async
+		middleware.HandleScoped(protected, "GET", "/jobs/:id", "jobs:read", handlers.GetJobHandler)
+
+		// GraphQL queries over albums, bookings and users; every field is
+		// authorized like the REST route it mirrors
+		if config.GraphQLEnabled {
+			graphQLHandlers := handlers.NewGraphQLHandlers(config)
+			middleware.HandleScoped(protected, "POST", "/graphql", "graphql:query", middleware.MaintenanceGuard("api-beheerder", "", 0), graphQLHandlers.Query)
+		}
+
+		// Availability search across inventory and rate restrictions. The
+		// response is aggregated, so it gets a weak ETag.
+		middleware.HandleScoped(protected, "GET", "/availability", "availability:read", middleware.ConditionalGET(middleware.WeakETag), availabilityHandlers.SearchAvailability)
+
+		// Portal start page: counts, filters and backend status in one call.
+		// Sections a failing backend feeds are marked unavailable.
+		middleware.HandleScoped(protected, "GET", "/dashboard", "dashboard:read", dashboardHandlers.GetDashboard)
+
+		// Housekeeping tablets stream room status updates as NDJSON
+		middleware.RegisterStreamingRoute("POST", "/api/v1/housekeeping/updates/stream")
+		middleware.HandleScoped(protected, "POST", "/housekeeping/updates/stream", "housekeeping:write",
+			middleware.RequireRoles("housekeeping", "front_desk", "admin", "super_admin", "service"),
+			housekeepingHandlers.StreamUpdates)
+
+		// Domain events pushed to staff dashboards as server-sent events
+		middleware.RegisterStreamingRoute("GET", "/api/v1/events/stream")
+		middleware.HandleScoped(protected, "GET", "/events/stream", "events:read",
+			middleware.RequireRoles("front_desk", "housekeeping", "admin", "super_admin", "service"),
+			streamHandlers.StreamEvents)
+
+		// Housekeeping task queue: workers claim, complete and report cleaning
+		// tasks generated at checkout; supervisors may also queue tasks by hand
+		tasks := protected.Group("/housekeeping/tasks")
+		workerRoles := middleware.RequireRoles("worker", "housekeeping")
+		middleware.HandleScoped(tasks, "GET", "", "housekeeping:read", middleware.RequireRoles("worker", "housekeeping", "front_desk", "admin", "super_admin", "service"), handlers.ListTasksHandler)
+		middleware.HandleScoped(tasks, "GET", "/stats", "housekeeping:read", middleware.RequireRoles("worker", "housekeeping", "front_desk", "admin", "super_admin", "service"), handlers.GetTaskStatsHandler)
+		middleware.HandleScoped(tasks, "POST", "", "housekeeping:write", middleware.RequireRoles("housekeeping", "front_desk", "admin", "super_admin", "service"), handlers.CreateTaskHandler)
+		tasks.POST("/claim", workerRoles, handlers.ClaimNextTaskHandler)
+		tasks.POST("/:id/claim", workerRoles, handlers.ClaimTaskHandler)
+		tasks.POST("/:id/complete", workerRoles, handlers.CompleteTaskHandler)
+		tasks.POST("/:id/report", workerRoles, handlers.ReportTaskHandler)
+
+		// Album/Hotel management routes (backed by API Beheerder). Reads of
+		// albums, bookings and rooms are cached when RESPONSE_CACHE_ENABLED is
+		// set; successful writes drop the cached reads of their resource.
+		albumsMaintenance := middleware.MaintenanceGuard("api-beheerder", "albums", config.MaintenanceCacheTTL)
+		albums := protected.Group("/albums", middleware.ConditionalGET(middleware.StrongETag), middleware.InvalidatesCache("albums"))
+		middleware.HandleScoped(albums, "GET", "", "albums:read", middleware.RequirePermission("read_album", "albums"), albumsMaintenance, middleware.CacheResponse("albums"), albumHandlers.GetAlbums)
+		middleware.HandleScoped(albums, "GET", "/:id", "albums:read", middleware.RequirePermission("read_album", "albums"), albumsMaintenance, middleware.CacheResponse("albums"), albumHandlers.GetAlbumByID)
+		middleware.HandleScoped(albums, "POST", "", "albums:write", middleware.RequirePermission("create_album", "albums"), albumsMaintenance, albumHandlers.CreateAlbum)
+		middleware.HandleScoped(albums, "PUT", "/:id", "albums:write", middleware.RequirePermission("update_album", "albums"), albumsMaintenance, albumHandlers.UpdateAlbum)
+		middleware.HandleScoped(albums, "PATCH", "/:id", "albums:write", middleware.RequirePermission("update_album", "albums"), albumsMaintenance, albumHandlers.PatchAlbum)
+		middleware.HandleScoped(albums, "DELETE", "/:id", "albums:write", middleware.RequirePermission("delete_album", "albums"), albumsMaintenance, albumHandlers.DeleteAlbum)
+
+		// Booking management (backed by API Beheerder, checked against availability)
+		bookingsMaintenance := middleware.MaintenanceGuard("api-beheerder", "bookings", config.MaintenanceCacheTTL)
+		bookings := protected.Group("/bookings", middleware.ConditionalGET(middleware.StrongETag), middleware.InvalidatesCache("bookings"))
+		middleware.HandleScoped(bookings, "GET", "", "bookings:read", middleware.RequirePermission("read_booking", "bookings"), bookingsMaintenance, middleware.CacheResponse("bookings"), bookingHandlers.GetBookings)
+		middleware.HandleScoped(bookings, "GET", "/:id", "bookings:read", middleware.RequirePermission("read_booking", "bookings"), bookingsMaintenance, middleware.CacheResponse("bookings"), bookingHandlers.GetBookingByID)
+		middleware.HandleScoped(bookings, "POST", "", "bookings:write", middleware.RequirePermission("create_booking", "bookings"), bookingsMaintenance, bookingHandlers.CreateBooking)
+		middleware.HandleScoped(bookings, "PUT", "/:id", "bookings:write", middleware.RequirePermission("update_booking", "bookings"), bookingsMaintenance, bookingHandlers.UpdateBooking)
+		middleware.HandleScoped(bookings, "PATCH", "/:id", "bookings:write", middleware.RequirePermission("update_booking", "bookings"), bookingsMaintenance, bookingHandlers.PatchBooking)
+		middleware.HandleScoped(bookings, "DELETE", "/:id", "bookings:write", middleware.RequirePermission("delete_booking", "bookings"), bookingsMaintenance, bookingHandlers.DeleteBooking)
+
+		// Rooms and housekeeping status transitions (backed by API Beheerder)
+		roomsMaintenance := middleware.MaintenanceGuard("api-beheerder", "rooms", config.MaintenanceCacheTTL)
+		rooms := protected.Group("/rooms", middleware.ConditionalGET(middleware.StrongETag), middleware.InvalidatesCache("rooms"))
+		middleware.HandleScoped(rooms, "GET", "", "rooms:read", middleware.RequirePermission("read_room", "rooms"), roomsMaintenance, middleware.CacheResponse("rooms"), roomHandlers.GetRooms)
+		middleware.HandleScoped(rooms, "GET", "/:id", "rooms:read", middleware.RequirePermission("read_room", "rooms"), roomsMaintenance, middleware.CacheResponse("rooms"), roomHandlers.GetRoomByID)
+		middleware.HandleScoped(rooms, "POST", "", "rooms:write", middleware.RequirePermission("create_room", "rooms"), roomsMaintenance, roomHandlers.CreateRoom)
+		middleware.HandleScoped(rooms, "PUT", "/:id", "rooms:write", middleware.RequirePermission("update_room", "rooms"), roomsMaintenance, roomHandlers.UpdateRoom)
+		middleware.HandleScoped(rooms, "PATCH", "/:id", "rooms:write", middleware.RequirePermission("update_room", "rooms"), roomsMaintenance, roomHandlers.PatchRoom)
+		middleware.HandleScoped(rooms, "DELETE", "/:id", "rooms:write", middleware.RequirePermission("delete_room", "rooms"), roomsMaintenance, roomHandlers.DeleteRoom)
+		middleware.HandleScoped(rooms, "PATCH", "/:id/status", "housekeeping:write",
+			middleware.RequireRoles("housekeeping", "front_desk", "admin", "super_admin", "service"),
+			roomsMaintenance,
+			roomHandlers.UpdateRoomStatus)
+
+		// Guest profiles (personal data; fields filtered per user by Central Management)
+		guestsMaintenance := middleware.MaintenanceGuard("api-beheerder", "", 0) // Personal data is never replayed
+		guests := protected.Group("/guests", middleware.ConditionalGET(middleware.StrongETag))
+		middleware.HandleScoped(guests, "GET", "", "guests:read", middleware.RequirePermission("read_guest", "guests"), guestsMaintenance, guestHandlers.GetGuests)
+		middleware.HandleScoped(guests, "GET", "/:id", "guests:read", middleware.RequirePermission("read_guest", "guests"), guestsMaintenance, guestHandlers.GetGuestByID)
+		middleware.HandleScoped(guests, "POST", "", "guests:write", middleware.RequirePermission("create_guest", "guests"), guestsMaintenance, guestHandlers.CreateGuest)
+		middleware.HandleScoped(guests, "PUT", "/:id", "guests:write", middleware.RequirePermission("update_guest", "guests"), guestsMaintenance, guestHandlers.UpdateGuest)
+		middleware.HandleScoped(guests, "PATCH", "/:id", "guests:write", middleware.RequirePermission("update_guest", "guests"), guestsMaintenance, guestHandlers.PatchGuest)
+		middleware.HandleScoped(guests, "DELETE", "/:id", "guests:write", middleware.RequirePermission("delete_guest", "guests"), guestsMaintenance, guestHandlers.DeleteGuest)
+		middleware.HandleScoped(guests, "GET", "/:id/export", "guests:privacy", middleware.RequirePermission("export_guest", "guests"), guestsMaintenance, guestHandlers.ExportGuest)
+		middleware.HandleScoped(guests, "DELETE", "/:id/personal-data", "guests:privacy", middleware.RequirePermission("erase_guest", "guests"), guestsMaintenance, guestHandlers.ErasePersonalData)
+
+		// Allow-listed API Beheerder endpoints without a dedicated handler yet.
+		// Slow writes can be run as background jobs with Prefer: respond-async.
+		proxy := protected.Group("/proxy/beheerder", middleware.ConditionalGET(middleware.StrongETag), middleware.ProxyGuard(), middleware.MaintenanceGuard("api-beheerder", "", config.MaintenanceCacheTTL))
+		middleware.HandleScoped(proxy, "GET", "/*path", "proxy:read", proxyHandlers.ForwardBeheerder)
+		for _, method := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+			middleware.HandleScoped(proxy, method, "/*path", "proxy:write", middleware.AsyncJobs(proxyHandlers.ForwardBeheerder))
+		}
+
+		// Large API Beheerder downloads, such as reports and exports, streamed
+		// to the client unmodified. Kept off the proxy group, whose ETag
+		// middleware buffers the whole response.
+		middleware.RegisterStreamingRoute("GET", "/api/v1/proxy/stream/beheerder/*path")
+		middleware.HandleScoped(protected, "GET", "/proxy/stream/beheerder/*path", "proxy:read",
+			middleware.StreamProxyGuard(),
+			middleware.MaintenanceGuard("api-beheerder", "", config.MaintenanceCacheTTL),
+			proxyHandlers.StreamBeheerder)
+
+		// Other backends forwarded as they are, see PASSTHROUGH_SERVICES
+		passthroughRoles := strings.Split(config.PassthroughRoles, ",")
+		for i := range passthroughRoles {
+			passthroughRoles[i] = strings.TrimSpace(passthroughRoles[i])
+		}
+		for _, service := range services.PassthroughServices() {
+			passthrough := protected.Group("/proxy/"+service,
+				middleware.RequireRoles(passthroughRoles...),
+				middleware.PassthroughGuard(service),
+				middleware.MaintenanceGuard(service, "", config.MaintenanceCacheTTL))
+			for _, method := range []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"} {
+				scope := "passthrough:" + service + ":write"
+				if method == "GET" || method == "HEAD" {
+					scope = "passthrough:" + service + ":read"
+				}
+				middleware.HandleScoped(passthrough, method, "/*path", scope, proxyHandlers.Passthrough(service))
+				services.RegisterRouteDependency(service, method, "/api/v1/proxy/"+service+"/*path")
+			}
+		}
 	}
 
 	// Admin routes (requires JWT + admin role)
+	// Runtime profiling for diagnosing live instances without redeploying;
+	// never exposed in hardened deployments
+	if config.DebugEndpointsEnabled && !config.Hardened {
+		debug := router.Group("/debug")
+		debug.Use(middleware.JWTAuthMiddleware())
+		debug.Use(middleware.RequireRoles("admin", "super_admin"))
+		{
+			debug.GET("/build", handlers.GetBuildInfoHandler)
+			debug.GET("/goroutines", handlers.GetGoroutineDumpHandler)
+			middleware.RegisterStreamingRoute("GET", "/debug/pprof/*profile")
+			debug.GET("/pprof/*profile", handlers.PprofHandler)
+		}
+	}
+
 	admin := router.Group("/admin")
 	admin.Use(middleware.JWTAuthMiddleware())
+	admin.Use(middleware.CSRF())
 	admin.Use(middleware.RequireRoles("admin", "super_admin"))
 	if config.RateLimitEnabled {
-		admin.Use(middleware.RateLimitByUser(
+		admin.Use(middleware.RateLimitByUser("admin",
 			config.AdminRateLimitRequests,
 			config.AdminRateLimitInterval,
 		))
@@ -83,6 +339,141 @@ func Setup(router *gin.Engine, config *config.Config) {
 		// System management
 		admin.GET("/system/stats", adminHandlers.GetSystemStats)
 		admin.GET("/audit-logs", adminHandlers.GetAuditLogs)
+		admin.GET("/audit-logs/resource/:type/:id", adminHandlers.GetResourceAccessReport)
+		admin.GET("/audit-logs/verify", handlers.VerifyAuditChainHandler)
+		admin.GET("/audit-capture", handlers.GetAuditCaptureHandler)
+		admin.GET("/cors", handlers.GetCORSPolicyHandler)
+		admin.GET("/config", handlers.GetConfigHandler)
+		admin.POST("/config/reload", handlers.ReloadConfigHandler)
+		admin.GET("/jwt-keys", handlers.ListJWTKeysHandler)
+		admin.POST("/jwt-keys/rotate", handlers.RotateJWTKeyHandler)
+		admin.PUT("/audit-capture/default", handlers.SetDefaultAuditCaptureHandler)
+		admin.PUT("/audit-capture/routes", handlers.SetRouteAuditCaptureHandler)
+		admin.DELETE("/audit-capture/routes", handlers.ClearRouteAuditCaptureHandler)
+		admin.GET("/usage-reports", handlers.GetUsageReportsHandler)
 		admin.POST("/circuit-breakers/:service/reset", handlers.ResetCircuitBreakerHandler)
+		admin.PUT("/circuit-breakers/:service/config", handlers.UpdateCircuitBreakerConfigHandler)
+		if !config.Hardened {
+			// Debug introspection is not exposed in hardened deployments
+			admin.GET("/system/middleware", handlers.MiddlewareChainHandler(router))
+		}
+		admin.GET("/system/callbacks", handlers.GetCallbackStatsHandler)
+		admin.GET("/slo", handlers.GetSLOStatusHandler)
+
+		// Upstream maintenance windows
+		admin.GET("/maintenance-windows", handlers.GetMaintenanceWindowsHandler)
+		admin.GET("/maintenance-windows/:id", handlers.GetMaintenanceWindowHandler)
+		admin.POST("/maintenance-windows", handlers.CreateMaintenanceWindowHandler)
+		admin.PUT("/maintenance-windows/:id", handlers.UpdateMaintenanceWindowHandler)
+		admin.DELETE("/maintenance-windows/:id", handlers.DeleteMaintenanceWindowHandler)
+
+		// Security management
+		admin.GET("/security/reputation", handlers.GetReputationHandler)
+		admin.DELETE("/security/reputation/:key", handlers.ResetReputationHandler)
+		admin.GET("/security/lockouts", handlers.GetLockedAccountsHandler)
+		admin.DELETE("/security/lockouts/:username", handlers.UnlockAccountHandler)
+		admin.GET("/security/role-hierarchy", handlers.GetRoleHierarchyHandler)
+		admin.GET("/security/permission-cache", handlers.GetPermissionCacheHandler)
+		admin.DELETE("/security/permission-cache", handlers.InvalidatePermissionCacheHandler)
+		admin.GET("/security/blacklist", handlers.GetBlacklistStatusHandler)
+		admin.POST("/security/blacklist/purge", handlers.PurgeBlacklistHandler)
+
+		// Session management
+		admin.GET("/sessions", handlers.ListSessionsHandler)
+		admin.GET("/sessions/:id", handlers.GetUserSessionsHandler)
+		admin.DELETE("/sessions/:id", handlers.RevokeUserSessionsHandler)
+
+		// Audit, circuit breaker and health events for dashboards
+		middleware.RegisterStreamingRoute("GET", "/admin/events")
+		admin.GET("/events", streamHandlers.AdminEvents)
+
+		// Outbound webhooks and their dead letters
+		if config.OutboundWebhooksEnabled {
+			admin.GET("/webhooks", handlers.GetWebhooksHandler)
+			admin.POST("/webhooks", handlers.CreateWebhookHandler)
+			admin.GET("/webhooks/dead-letters", handlers.GetDeadLettersHandler)
+			admin.POST("/webhooks/dead-letters/:id/retry", handlers.RetryDeadLetterHandler)
+			admin.DELETE("/webhooks/dead-letters/:id", handlers.DiscardDeadLetterHandler)
+			admin.GET("/webhooks/:id", handlers.GetWebhookHandler)
+			admin.PUT("/webhooks/:id", handlers.UpdateWebhookHandler)
+			admin.DELETE("/webhooks/:id", handlers.DeleteWebhookHandler)
+		}
+
+		// Event stream connections
+		admin.GET("/connections", handlers.ListConnectionsHandler)
+		admin.PUT("/connections/policy", handlers.SetStreamPolicyHandler)
+		admin.DELETE("/connections/:id", handlers.DisconnectConnectionHandler)
+
+		// Runbook actions
+		admin.GET("/actions", handlers.ListActionsHandler)
+		admin.POST("/actions/:name", handlers.RunActionHandler)
+
+		// Upstream dependency graph
+		admin.GET("/dependencies", adminHandlers.GetDependencies)
+		admin.GET("/proxy-rules", handlers.GetProxyRulesHandler)
+	}
+
+	// API documentation for portal developers (requires JWT + admin role);
+	// never exposed in hardened deployments
+	if config.DocsEnabled && !config.Hardened {
+		docs := router.Group("", middleware.JWTAuthMiddleware(), middleware.RequireRoles("admin", "super_admin"))
+		docs.GET("/openapi.json", handlers.OpenAPIHandler)
+		docs.GET("/docs", handlers.SwaggerUIHandler)
+		docs.GET("/docs/swagger-init.js", handlers.SwaggerInitHandler)
+		docs.GET("/docs/swagger-ui-bundle.js", handlers.SwaggerBundleHandler)
+		docs.GET("/docs/swagger-ui.css", handlers.SwaggerCSSHandler)
+		middleware.RegisterUnmaskedRoute("GET", "/openapi.json")
+	}
+
+	registerUpstreamRoutes(router)
+	registerBrokerRoutes(router)
+	verifyChangelog(router)
+
+	// The document is generated last so it covers every route
+	if config.DocsEnabled && !config.Hardened {
+		spec, err := generateAPIDocs(router)
+		if err != nil {
+			logging.Logger().WithError(err).Error("Failed to generate the OpenAPI document")
+		} else {
+			handlers.SetOpenAPIDocument(spec)
+		}
+	}
+}
+
+// brokerHiddenPrefixes are routes not advertised to the broker: operational
+// endpoints used on the instance itself
+var brokerHiddenPrefixes = []string{"/metrics", "/debug/"}
+
+// registerBrokerRoutes advertises every registered route to the broker,
+// so its routing table follows the router
+func registerBrokerRoutes(router *gin.Engine) {
+	var endpoints []broker.Endpoint
+	for _, route := range router.Routes() {
+		hidden := false
+		for _, prefix := range brokerHiddenPrefixes {
+			hidden = hidden || strings.HasPrefix(route.Path, prefix)
+		}
+		if !hidden {
+			endpoints = append(endpoints, broker.Endpoint{Method: route.Method, Path: route.Path})
+		}
+	}
+	broker.SetRoutes(endpoints)
+}
+
+// registerUpstreamRoutes records the upstream dependencies of every
+// registered route for the dependency graph
+func registerUpstreamRoutes(router *gin.Engine) {
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+
+	for upstream, routes := range upstreamRoutes {
+		for _, route := range routes {
+			if registered[route] {
+				method, path, _ := strings.Cut(route, " ")
+				services.RegisterRouteDependency(upstream, method, path)
+			}
+		}
 	}
 }
\ No newline at end of file
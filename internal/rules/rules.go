@@ -0,0 +1,176 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+
+	"InternalAPI/internal/metrics"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/services"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RuleSet holds the business rules Central Management defines for a
+// resource. A zero value rule is not enforced.
+type RuleSet struct {
+	MinPrice       *float64 `json:"min_price,omitempty"`
+	MaxPrice       *float64 `json:"max_price,omitempty"`
+	PricePrecision int      `json:"price_precision,omitempty"`
+	MaxTitleLength int      `json:"max_title_length,omitempty"`
+	RequiredFields []string `json:"required_fields,omitempty"`
+	AllowedGenres  []string `json:"allowed_genres,omitempty"`
+	Version        string   `json:"version,omitempty"`
+}
+
+var (
+	engine *Engine
+
+	violations = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "business_rule_violations_total",
+		Help:      "Payloads rejected by business rules, by resource and rule",
+	}, []string{"resource", "rule"})
+)
+
+// Engine loads business rules from Central Management and validates
+// payloads against them. Rules are read through the reference data cache,
+// so they are preloaded at startup and reloaded by resync-business-rules.
+type Engine struct {
+	service *services.ExternalService
+}
+
+// Init sets up the global rule engine
+func Init(es *services.ExternalService) {
+	engine = &Engine{service: es}
+}
+
+// Rules returns the rule set for resource
+func (e *Engine) Rules(ctx context.Context, resource string) (RuleSet, error) {
+	response, err := e.service.CallCached(ctx, "central", "/business-rules/"+url.PathEscape(resource))
+	if err != nil {
+		return RuleSet{}, err
+	}
+	return parseRuleSet(response), nil
+}
+
+// Validate checks payload against the rules for resource and returns every
+// violation found
+func (e *Engine) Validate(ctx context.Context, resource string, payload map[string]interface{}) ([]models.Violation, error) {
+	set, err := e.Rules(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	found := set.Check(payload)
+	for _, v := range found {
+		violations.WithLabelValues(resource, v.Rule).Inc()
+	}
+	return found, nil
+}
+
+// parseRuleSet reads the Central Management rule document
+func parseRuleSet(response map[string]interface{}) RuleSet {
+	var set RuleSet
+	if min, ok := response["minPrice"].(float64); ok {
+		set.MinPrice = &min
+	}
+	if max, ok := response["maxPrice"].(float64); ok {
+		set.MaxPrice = &max
+	}
+	if precision, ok := response["priceValidation"].(map[string]interface{}); ok {
+		if digits, ok := precision["precision"].(float64); ok {
+			set.PricePrecision = int(digits)
+		}
+	}
+	if maxLen, ok := response["maxTitleLen"].(float64); ok {
+		set.MaxTitleLength = int(maxLen)
+	}
+	set.RequiredFields = stringList(response["requiredFields"])
+	set.AllowedGenres = stringList(response["allowedGenres"])
+	set.Version, _ = response["version"].(string)
+	return set
+}
+
+// Check returns the violations of payload against the rule set
+func (set RuleSet) Check(payload map[string]interface{}) []models.Violation {
+	var found []models.Violation
+
+	for _, field := range set.RequiredFields {
+		if isMissing(payload[field]) {
+			found = append(found, models.Violation{Field: field, Rule: "required", Message: field + " is required"})
+		}
+	}
+
+	if price, ok := payload["price"].(float64); ok {
+		if set.MinPrice != nil && price < *set.MinPrice {
+			found = append(found, models.Violation{Field: "price", Rule: "min_price", Message: fmt.Sprintf("price must be at least %.2f", *set.MinPrice)})
+		}
+		if set.MaxPrice != nil && price > *set.MaxPrice {
+			found = append(found, models.Violation{Field: "price", Rule: "max_price", Message: fmt.Sprintf("price must not exceed %.2f", *set.MaxPrice)})
+		}
+		if set.PricePrecision > 0 {
+			scale := math.Pow(10, float64(set.PricePrecision))
+			if math.Abs(price*scale-math.Round(price*scale)) > 1e-6 {
+				found = append(found, models.Violation{Field: "price", Rule: "price_precision", Message: fmt.Sprintf("price may have at most %d decimals", set.PricePrecision)})
+			}
+		}
+	}
+
+	if title, ok := payload["title"].(string); ok && set.MaxTitleLength > 0 && len([]rune(title)) > set.MaxTitleLength {
+		found = append(found, models.Violation{Field: "title", Rule: "max_title_length", Message: fmt.Sprintf("title must be at most %d characters", set.MaxTitleLength)})
+	}
+
+	if genre, ok := payload["genre"].(string); ok && genre != "" && len(set.AllowedGenres) > 0 && !containsFold(set.AllowedGenres, genre) {
+		found = append(found, models.Violation{Field: "genre", Rule: "allowed_genres", Message: "genre must be one of " + strings.Join(set.AllowedGenres, ", ")})
+	}
+
+	return found
+}
+
+// isMissing reports whether a decoded JSON value counts as absent
+func isMissing(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// containsFold reports whether values holds s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringList converts a decoded JSON array to its non-empty strings
+func stringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	var result []string
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// Validate checks payload with the global engine; without one every payload passes
+func Validate(ctx context.Context, resource string, payload map[string]interface{}) ([]models.Violation, error) {
+	if engine == nil {
+		return nil, nil
+	}
+	return engine.Validate(ctx, resource, payload)
+}
@@ -0,0 +1,215 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/config"
+	"InternalAPI/internal/logging"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+var log = logging.Logger()
+
+// TLS modes
+const (
+	ModeOff   = "off"
+	ModeFiles = "files"
+	ModeACME  = "acme"
+)
+
+// TLS holds the server-side TLS setup for the configured mode
+type TLS struct {
+	Config *tls.Config
+	acme   *autocert.Manager
+	cfg    *config.Config
+}
+
+// NewTLS builds the server TLS configuration. It returns a TLS with a nil
+// Config when TLS is off.
+func NewTLS(cfg *config.Config) (*TLS, error) {
+	t := &TLS{cfg: cfg}
+
+	switch cfg.TLSMode {
+	case "", ModeOff:
+		return t, nil
+
+	case ModeFiles:
+		reloader, err := NewCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.TLSAutoReload {
+			reloader.Watch(cfg.TLSReloadInterval)
+		}
+		t.Config = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+		return t, nil
+
+	case ModeACME:
+		domains := splitDomains(cfg.ACMEDomains)
+		if len(domains) == 0 {
+			return nil, errors.New("ACME mode requires ACME_DOMAINS")
+		}
+		t.acme = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(domains...),
+			Cache:      autocert.DirCache(cfg.ACMECacheDir),
+			Email:      cfg.ACMEEmail,
+		}
+		t.Config = t.acme.TLSConfig()
+		t.Config.MinVersion = tls.VersionTLS12
+		return t, nil
+
+	default:
+		return nil, fmt.Errorf("unknown TLS mode %q, expected off, files or acme", cfg.TLSMode)
+	}
+}
+
+// Enabled reports whether the server terminates TLS
+func (t *TLS) Enabled() bool {
+	return t.Config != nil
+}
+
+// Scheme returns the URL scheme the server is reachable on
+func (t *TLS) Scheme() string {
+	if t.Enabled() {
+		return "https"
+	}
+	return "http"
+}
+
+// ServeACMEChallenges answers HTTP-01 challenges on the ACME HTTP port and
+// redirects all other plain HTTP traffic to HTTPS. It is a no-op outside
+// ACME mode.
+func (t *TLS) ServeACMEChallenges() {
+	if t.acme == nil {
+		return
+	}
+
+	address := fmt.Sprintf("%s:%s", t.cfg.Host, t.cfg.ACMEHTTPPort)
+	go func() {
+		srv := &http.Server{
+			Addr:              address,
+			Handler:           t.acme.HTTPHandler(nil),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("ACME challenge listener stopped")
+		}
+	}()
+}
+
+// CertReloader serves a certificate loaded from disk and can pick up
+// renewed files without a restart
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	cert     *tls.Certificate
+	modTime  time.Time
+	mu       sync.RWMutex
+}
+
+// NewCertReloader loads the certificate and key once
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Watch polls the certificate and key files and reloads them when either
+// changes. A failed reload keeps serving the previous certificate.
+func (r *CertReloader) Watch(interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			modTime, err := latestModTime(r.certFile, r.keyFile)
+			if err != nil {
+				log.WithError(err).Warn("Unable to stat TLS certificate files")
+				continue
+			}
+
+			r.mu.RLock()
+			changed := modTime.After(r.modTime)
+			r.mu.RUnlock()
+			if !changed {
+				continue
+			}
+
+			if err := r.reload(); err != nil {
+				log.WithError(err).Error("Failed to reload TLS certificate, keeping the current one")
+				continue
+			}
+			log.WithField("cert_file", r.certFile).Info("TLS certificate reloaded")
+		}
+	}()
+}
+
+// reload reads the certificate and key from disk
+func (r *CertReloader) reload() error {
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %v", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// latestModTime returns the most recent modification time of the files
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// splitDomains parses a comma-separated domain list
+func splitDomains(value string) []string {
+	var domains []string
+	for _, domain := range strings.Split(value, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
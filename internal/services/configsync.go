@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"InternalAPI/internal/config"
+)
+
+// ConfigSource reads the settings Central Management keeps for the gateway
+// from GET /config/:service. It has a client of its own, so it works
+// before the upstream transports and circuit breakers are set up.
+type ConfigSource struct {
+	es      *ExternalService
+	service string
+	timeout time.Duration
+	client  *http.Client
+}
+
+// NewConfigSource creates the source of CONFIG_SYNC_SERVICE's settings,
+// connecting over mTLS when it is enabled
+func NewConfigSource(cfg *config.Config) (*ConfigSource, error) {
+	es := New(cfg)
+	upstream, _ := es.resolve("central")
+	client := newClient(upstream.Name, nil)
+	if cfg.MTLSEnabled {
+		tlsConfig, err := NewTLSConfig(cfg.MTLSCertFile, cfg.MTLSKeyFile, cfg.MTLSCAFile, upstream.SPIFFEID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build TLS config for %s: %v", upstream.Name, err)
+		}
+		client = newClient(upstream.Name, tlsConfig)
+	}
+	return &ConfigSource{es: es, service: cfg.ConfigSyncService, timeout: cfg.ConfigSyncTimeout, client: client}, nil
+}
+
+// Fetch reads the current settings document. The service key is looked
+// up on every fetch, so a rotated CENTRAL_MGMT_KEY is used at once.
+func (s *ConfigSource) Fetch(ctx context.Context) (map[string]interface{}, error) {
+	upstream, ok := s.es.resolve("central")
+	if !ok {
+		return nil, fmt.Errorf("unknown service: central")
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var document map[string]interface{}
+	endpoint := upstream.URL + "/config/" + url.PathEscape(s.service)
+	if err := s.es.makeHTTPCall(ctx, s.client, http.MethodGet, endpoint, upstream.Key, nil, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
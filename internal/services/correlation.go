@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"net/http"
+)
+
+type requestIDKey struct{}
+type userIDKey struct{}
+
+// WithRequestID returns a context whose backend calls carry requestID in
+// their X-Request-ID header, so a request can be followed across services
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// WithUserID returns a context whose backend calls carry userID in their
+// X-User-ID header
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// RequestIDFrom returns the request ID of a context, or ""
+func RequestIDFrom(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// UserIDFrom returns the user ID of a context, or ""
+func UserIDFrom(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	return userID
+}
+
+// setCorrelationHeaders adds the request and user ID of ctx to a backend
+// request
+func setCorrelationHeaders(ctx context.Context, header http.Header) {
+	if requestID := RequestIDFrom(ctx); requestID != "" {
+		header.Set("X-Request-ID", requestID)
+	}
+	if userID := UserIDFrom(ctx); userID != "" {
+		header.Set("X-User-ID", userID)
+	}
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"InternalAPI/internal/resilience"
+)
+
+// Deliver POSTs data to an upstream without the resilience chain: no
+// breaker, bulkhead, retries or fallbacks. It is meant for background work
+// that retries on its own, whose failures must not open the breaker that
+// user traffic to the same upstream depends on.
+func (es *ExternalService) Deliver(ctx context.Context, serviceName, endpoint string, data interface{}) error {
+	upstream, ok := es.resolve(serviceName)
+	if !ok {
+		return fmt.Errorf("unknown service: %s", serviceName)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resilience.TimeoutFor(upstream.Name))
+	defer cancel()
+
+	var response map[string]interface{}
+	err := es.makeHTTPCall(ctx, clientFor(upstream.Name), http.MethodPost, strings.TrimSuffix(upstream.URL, "/")+endpoint, upstream.Key, data, &response)
+	var serviceErr *ServiceError
+	if errors.As(err, &serviceErr) {
+		serviceErr.Service = upstream.Name
+	}
+	return err
+}
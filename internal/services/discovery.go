@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/broker"
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// DiscoverySettings control looking up upstream addresses with the broker
+type DiscoverySettings struct {
+	Services       map[string]string // Broker slug by upstream name
+	Refresh        time.Duration     // Time between lookups of every upstream; 0 looks up only at startup and after failures
+	FailureRefresh time.Duration     // Least time between lookups of an upstream triggered by failed calls
+	RequireHTTPS   bool              // Reject plain http addresses, as mTLS needs https
+}
+
+var (
+	discoveryMu     sync.Mutex
+	discovery       DiscoverySettings
+	lastLookup      = map[string]time.Time{}
+	stopDiscovering chan struct{}
+
+	urlsMu       sync.RWMutex
+	upstreamURLs = make(map[string]string) // Addresses found with the broker, by upstream name
+)
+
+var discoveryLookups = metrics.Factory.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "discovery_lookups_total",
+		Help:      "Lookups of upstream addresses with the broker by result (ok, failed)",
+	},
+	[]string{"service", "result"},
+)
+
+// InitDiscovery looks up the address of every upstream in settings.Services
+// with the broker and keeps looking them up until StopDiscovery is called.
+// An upstream the broker cannot find keeps its configured URL, or the
+// address found before.
+func InitDiscovery(settings DiscoverySettings) {
+	done := make(chan struct{})
+	discoveryMu.Lock()
+	discovery = settings
+	stopDiscovering = done
+	discoveryMu.Unlock()
+
+	for name := range settings.Services {
+		discover(name)
+	}
+	if settings.Refresh <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(settings.Refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				for name := range settings.Services {
+					discover(name)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// StopDiscovery ends the periodic lookups; the addresses found stay in use
+func StopDiscovery() {
+	discoveryMu.Lock()
+	defer discoveryMu.Unlock()
+	if stopDiscovering != nil {
+		close(stopDiscovering)
+		stopDiscovering = nil
+	}
+}
+
+// DiscoveredURL returns the address found with the broker for an upstream,
+// if any
+func DiscoveredURL(name string) (string, bool) {
+	urlsMu.RLock()
+	defer urlsMu.RUnlock()
+	address, ok := upstreamURLs[name]
+	return address, ok
+}
+
+// ParseDiscoveryServices parses a spec like
+// "api-beheerder=api-beheerder,central-mgmt=central-mgmt" mapping upstream
+// names to the slugs they register with at the broker
+func ParseDiscoveryServices(spec string) (map[string]string, error) {
+	return parsePairs(spec, "discovery service")
+}
+
+// discover looks up the address of an upstream and uses it from then on
+func discover(name string) {
+	discoveryMu.Lock()
+	slug, ok := discovery.Services[name]
+	requireHTTPS := discovery.RequireHTTPS
+	lastLookup[name] = time.Now()
+	discoveryMu.Unlock()
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	address, err := broker.Lookup(ctx, slug)
+	if err == nil && requireHTTPS && !strings.HasPrefix(address, "https://") {
+		err = fmt.Errorf("mTLS needs an https address, broker gave %s", SanitizeURL(address))
+	}
+	log := logging.Logger().WithFields(logrus.Fields{"service": name, "slug": slug})
+	if err != nil {
+		discoveryLookups.WithLabelValues(name, "failed").Inc()
+		log.WithError(err).Warn("Failed to look up upstream address with the broker, keeping the current one")
+		return
+	}
+	discoveryLookups.WithLabelValues(name, "ok").Inc()
+
+	urlsMu.Lock()
+	previous := upstreamURLs[name]
+	upstreamURLs[name] = address
+	urlsMu.Unlock()
+	if previous != address {
+		log.WithField("url", SanitizeURL(address)).Info("Upstream address discovered with the broker")
+	}
+}
+
+// rediscover looks up an upstream again in the background after a call got
+// no answer, as the backend may have moved. Answers, even errors, prove the
+// address is right, and lookups are at most FailureRefresh apart.
+func rediscover(name string, err error) {
+	var serviceErr *ServiceError
+	if err == nil || errors.As(err, &serviceErr) || errors.Is(err, context.Canceled) {
+		return
+	}
+	discoveryMu.Lock()
+	_, ok := discovery.Services[name]
+	due := ok && time.Since(lastLookup[name]) >= discovery.FailureRefresh
+	if due {
+		lastLookup[name] = time.Now()
+	}
+	discoveryMu.Unlock()
+	if due {
+		go discover(name)
+	}
+}
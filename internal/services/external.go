@@ -2,17 +2,22 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
-	"InternalAPI/internal/circuitbreaker"
 	"InternalAPI/internal/config"
-)
+	"InternalAPI/internal/encryption"
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/resilience"
+	"InternalAPI/internal/tracing"
 
-// HTTPClient is the global HTTP client with timeout
-var HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	"github.com/sirupsen/logrus"
+)
 
 // ExternalService handles calls to external services with circuit breaker protection
 type ExternalService struct {
@@ -26,37 +31,183 @@ func New(config *config.Config) *ExternalService {
 	}
 }
 
-// Call makes a call to an external service with circuit breaker protection
-func (es *ExternalService) Call(serviceName, method, endpoint string, data interface{}) (map[string]interface{}, error) {
-	var url, authKey string
-
-	switch serviceName {
-	case "beheerder", "api-beheerder":
-		url = es.config.APIBeheerderURL + endpoint
-		authKey = es.config.APIBeheerderKey
-	case "central", "central-mgmt":
-		url = es.config.CentralMgmtURL + endpoint
-		authKey = es.config.CentralMgmtKey
-	default:
+// Call makes a call to an external service through the service's
+// resilience policy chain, see resilience.InitChains. The call is cancelled
+// with ctx, so a client that disconnects stops its backend calls. GET calls
+// to upstreams with hedging enabled may be sent twice, see InitHedging.
+// While the breaker is open, GET calls are answered from a fallback if
+// there is one, see InitFallbacks.
+func (es *ExternalService) Call(ctx context.Context, serviceName, method, endpoint string, data interface{}) (map[string]interface{}, error) {
+	upstream, ok := es.resolve(serviceName)
+	if !ok {
 		return nil, fmt.Errorf("unknown service: %s", serviceName)
 	}
+	authKey, breakerName := upstream.Key, upstream.Name
+	transform := matchTransform(breakerName, method, endpoint)
+	url := upstream.URL + transform.rewriteEndpoint(endpoint)
+	ctx = withTransform(ctx, transform)
+
+	ctx, span := tracing.Start(ctx, "call "+breakerName, tracing.KindInternal)
+	defer span.End()
+	span.SetAttribute("peer.service", breakerName)
+	span.SetAttribute("http.request.method", method)
+	span.SetAttribute("url.path", strings.SplitN(endpoint, "?", 2)[0])
 
-	// Get circuit breaker for this service
-	cb := circuitbreaker.Get(serviceName)
-	if cb == nil {
-		return nil, fmt.Errorf("circuit breaker not initialized for service: %s", serviceName)
+	// Encrypt sensitive fields before they leave the gateway
+	resource := resourceFromEndpoint(endpoint)
+	data, err := encryption.EncryptPayload(resource, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt request fields: %v", err)
+	}
+	if data, err = transform.requestData(data); err != nil {
+		return nil, err
 	}
 
 	var response map[string]interface{}
-	err := cb.Call(func() error {
-		return es.makeHTTPCall(method, url, authKey, data, &response)
+	var fallbackKind string
+	switch method {
+	case http.MethodGet:
+		// Answer reads from a fallback while the service is unavailable
+		ctx = resilience.WithFallback(ctx, func(openErr error) error {
+			fallback, kind := fallbackResponse(ctx, breakerName, endpoint)
+			if kind == "" {
+				return openErr
+			}
+			response, fallbackKind = fallback, kind
+			return nil
+		})
+		ctx = resilience.WithIdempotent(ctx)
+	case http.MethodHead, http.MethodPut, http.MethodDelete:
+		ctx = resilience.WithIdempotent(ctx)
+	}
+
+	started := time.Now()
+	err = resilience.Execute(ctx, breakerName, func(ctx context.Context) error {
+		if delay := hedgeDelayFor(breakerName); delay > 0 && method == http.MethodGet {
+			return es.hedgedCall(ctx, breakerName, delay, clientFor(breakerName), url, authKey, &response)
+		}
+		return es.makeHTTPCall(ctx, clientFor(breakerName), method, url, authKey, data, &response)
 	})
+	var serviceErr *ServiceError
+	if errors.As(err, &serviceErr) {
+		serviceErr.Service = breakerName
+		serviceErr.RequestID = RequestIDFrom(ctx)
+	}
+	recordOutcome(breakerName, err)
+	rediscover(breakerName, err)
+	observeCall(breakerName, method, endpoint, started, err, fallbackKind)
+	if err != nil {
+		span.SetError(err.Error())
+		logCallFailure(ctx, breakerName, method, endpoint, err)
+		return response, err
+	}
+	if fallbackKind != "" {
+		span.SetAttribute("fallback", fallbackKind)
+	}
+	switch fallbackKind {
+	case FallbackStatic:
+		return response, nil
+	case "":
+		transform.responseData(response)
+		if method == http.MethodGet {
+			rememberResponse(breakerName, endpoint, response)
+		}
+	}
+
+	if err := encryption.DecryptResponse(resource, response); err != nil {
+		return nil, fmt.Errorf("failed to decrypt response fields: %v", err)
+	}
+
+	return response, nil
+}
+
+// CallTyped is Call for backends whose response has a known shape: the
+// response is decoded into a T. Go methods cannot take type parameters, so
+// the service is passed in.
+func CallTyped[T any](ctx context.Context, es *ExternalService, serviceName, method, endpoint string, data interface{}) (T, error) {
+	var result T
+	response, err := es.Call(ctx, serviceName, method, endpoint, data)
+	if err != nil {
+		return result, err
+	}
+
+	// Round-trip through JSON so decryption, which works on the generic
+	// form, has already been applied
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return result, fmt.Errorf("failed to re-encode response: %v", err)
+	}
+	if err := json.Unmarshal(encoded, &result); err != nil {
+		return result, fmt.Errorf("failed to decode response from %s: %w", serviceName, err)
+	}
+	return result, nil
+}
+
+// ServiceError is returned when a backend answers with an HTTP error status
+type ServiceError struct {
+	Service    string // Upstream name, e.g. api-beheerder
+	RequestID  string // X-Request-ID sent with the call, if any
+	StatusCode int
+	Code       string // The backend's "code" field, if any
+	Message    string // The backend's "error" (or "message") field, if any
+}
+
+// newServiceError reads the code and message of a backend error body,
+// which may be nil
+func newServiceError(statusCode int, body map[string]interface{}) *ServiceError {
+	serviceErr := &ServiceError{StatusCode: statusCode}
+	serviceErr.Code, _ = body["code"].(string)
+	if errorMsg, exists := body["error"]; exists {
+		serviceErr.Message = fmt.Sprint(errorMsg)
+	} else if message, exists := body["message"]; exists {
+		serviceErr.Message = fmt.Sprint(message)
+	}
+	return serviceErr
+}
+
+func (e *ServiceError) Error() string {
+	message := fmt.Sprintf("external service returned status %d", e.StatusCode)
+	if e.Message != "" {
+		message = fmt.Sprintf("external service error: %s", e.Message)
+	}
+	if e.RequestID != "" {
+		message += fmt.Sprintf(" (request %s)", e.RequestID)
+	}
+	return message
+}
 
-	return response, err
+// ClientError reports a 4xx answer, which shows the backend is up and only
+// the request was wrong
+func (e *ServiceError) ClientError() bool {
+	return e.StatusCode < 500
+}
+
+// logCallFailure logs a failed backend call with the request and user it
+// was made for. Client errors and calls cancelled by the client are not
+// failures of the backend and are left out.
+func logCallFailure(ctx context.Context, service, method, endpoint string, err error) {
+	var serviceErr *ServiceError
+	if errors.Is(err, context.Canceled) || (errors.As(err, &serviceErr) && serviceErr.ClientError()) {
+		return
+	}
+	logging.FromContext(ctx).WithError(err).WithFields(logrus.Fields{
+		"service": service,
+		"method":  method,
+		"path":    strings.SplitN(endpoint, "?", 2)[0],
+	}).Warn("Backend call failed")
+}
+
+// resourceFromEndpoint returns the first path segment of an endpoint, e.g. "guests" for /guests/42
+func resourceFromEndpoint(endpoint string) string {
+	trimmed := strings.TrimPrefix(endpoint, "/")
+	if i := strings.IndexAny(trimmed, "/?"); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+	return trimmed
 }
 
 // makeHTTPCall performs the actual HTTP request
-func (es *ExternalService) makeHTTPCall(method, url, authKey string, data interface{}, response *map[string]interface{}) error {
+func (es *ExternalService) makeHTTPCall(ctx context.Context, client *http.Client, method, url, authKey string, data interface{}, response *map[string]interface{}) error {
 	var body []byte
 	var err error
 
@@ -67,31 +218,45 @@ func (es *ExternalService) makeHTTPCall(method, url, authKey string, data interf
 		}
 	}
 
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(body))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %v", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if transform := transformFrom(ctx); transform != nil {
+		transform.RequestHeaders.applyHeaders(req.Header)
+	}
 	req.Header.Set("X-Service-Key", authKey)
+	setCorrelationHeaders(ctx, req.Header)
+
+	// One client span per attempt, so retries and hedges show separately
+	ctx, span := tracing.Start(ctx, method, tracing.KindClient)
+	defer span.End()
+	span.SetAttribute("http.request.method", method)
+	span.SetAttribute("server.address", req.URL.Host)
+	span.SetAttribute("url.path", req.URL.Path)
+	tracing.Inject(ctx, req.Header)
 
-	resp, err := HTTPClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to make request: %v", err)
+		span.SetError(err.Error())
+		return fmt.Errorf("failed to make request: %w", err)
 	}
 	defer resp.Body.Close()
-
-	// Read response body
-	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
-		return fmt.Errorf("failed to decode response: %v", err)
+	span.SetAttribute("http.response.status_code", resp.StatusCode)
+	if resp.StatusCode >= 500 {
+		span.SetError(fmt.Sprintf("HTTP %d", resp.StatusCode))
 	}
 
-	// Check HTTP status
+	// Error statuses are reported even when the body is not JSON, such as
+	// a proxy's HTML error page
+	decodeErr := json.NewDecoder(resp.Body).Decode(response)
 	if resp.StatusCode >= 400 {
-		if errorMsg, exists := (*response)["error"]; exists {
-			return fmt.Errorf("external service error: %v", errorMsg)
-		}
-		return fmt.Errorf("external service returned status %d", resp.StatusCode)
+		return newServiceError(resp.StatusCode, *response)
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("failed to decode response: %w", decodeErr)
 	}
 
 	return nil
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/cache"
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Kinds of degraded response, as reported in the X-Degraded-Response header
+const (
+	FallbackLastKnownGood = "last-known-good"
+	FallbackStatic        = "static"
+)
+
+// staticFallback is a configured payload served for matching GETs
+type staticFallback struct {
+	service string
+	pattern string
+	payload json.RawMessage
+}
+
+// knownGood is a successful GET response kept for fallback, encoded so
+// callers that modify their response cannot change it
+type knownGood struct {
+	body     []byte
+	storedAt time.Time
+}
+
+var (
+	fallbackMu       sync.RWMutex
+	fallbackTTL      time.Duration // 0 keeps no last-known-good responses
+	fallbackPayloads []staticFallback
+	lastKnownGood    = cache.New()
+)
+
+var fallbackResponses = metrics.Factory.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "fallback_responses_total",
+		Help:      "GET calls answered with a fallback while the circuit breaker was open, by kind",
+	},
+	[]string{"service", "kind"},
+)
+
+// InitFallbacks configures what GET calls are answered with while a
+// service's breaker is open: the last successful response to the same
+// endpoint if it is younger than ttl, otherwise a static payload from
+// payloadFile. The file holds a JSON object keyed by "service /pattern",
+// e.g. "api-beheerder /room-types", with patterns as in proxy rules.
+func InitFallbacks(ttl time.Duration, payloadFile string) error {
+	var payloads []staticFallback
+	if payloadFile != "" {
+		data, err := os.ReadFile(payloadFile)
+		if err != nil {
+			return fmt.Errorf("failed to read fallback payloads: %v", err)
+		}
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("invalid fallback payloads: %v", err)
+		}
+		for key, payload := range entries {
+			service, pattern, ok := strings.Cut(strings.TrimSpace(key), " ")
+			pattern = strings.TrimSpace(pattern)
+			if !ok || !strings.HasPrefix(pattern, "/") {
+				return fmt.Errorf("invalid fallback key %q, expected \"service /path\"", key)
+			}
+			var object map[string]interface{}
+			if err := json.Unmarshal(payload, &object); err != nil {
+				return fmt.Errorf("fallback payload for %q must be a JSON object", key)
+			}
+			payloads = append(payloads, staticFallback{service: service, pattern: strings.TrimSuffix(pattern, "/"), payload: payload})
+		}
+		// Map order is random; keep the first match stable
+		sort.Slice(payloads, func(i, j int) bool {
+			return payloads[i].service+" "+payloads[i].pattern < payloads[j].service+" "+payloads[j].pattern
+		})
+	}
+
+	fallbackMu.Lock()
+	defer fallbackMu.Unlock()
+	fallbackTTL = ttl
+	fallbackPayloads = payloads
+	return nil
+}
+
+// rememberResponse keeps a successful GET response for fallback
+func rememberResponse(service, endpoint string, response map[string]interface{}) {
+	fallbackMu.RLock()
+	ttl := fallbackTTL
+	fallbackMu.RUnlock()
+	if ttl <= 0 {
+		return
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	lastKnownGood.Set(service+"|"+endpoint, knownGood{body: body, storedAt: time.Now()}, ttl)
+}
+
+// fallbackResponse returns the response to serve instead of calling an
+// unavailable service, and its kind, or nil when there is none
+func fallbackResponse(ctx context.Context, service, endpoint string) (map[string]interface{}, string) {
+	if cached, ok := lastKnownGood.Get(service + "|" + endpoint); ok {
+		good := cached.(knownGood)
+		var response map[string]interface{}
+		if json.Unmarshal(good.body, &response) == nil {
+			fallbackResponses.WithLabelValues(service, FallbackLastKnownGood).Inc()
+			DegradationFrom(ctx).mark(service, FallbackLastKnownGood, good.storedAt)
+			return response, FallbackLastKnownGood
+		}
+	}
+
+	path, _, _ := strings.Cut(endpoint, "?")
+	fallbackMu.RLock()
+	defer fallbackMu.RUnlock()
+	for _, fallback := range fallbackPayloads {
+		if fallback.service != service || !matchProxyPattern(fallback.pattern, path) {
+			continue
+		}
+		var response map[string]interface{}
+		if json.Unmarshal(fallback.payload, &response) == nil {
+			fallbackResponses.WithLabelValues(service, FallbackStatic).Inc()
+			DegradationFrom(ctx).mark(service, FallbackStatic, time.Time{})
+			return response, FallbackStatic
+		}
+	}
+	return nil, ""
+}
+
+// Degradation records the fallbacks served while handling one request, so
+// the response can be marked as degraded
+type Degradation struct {
+	mu       sync.Mutex
+	kind     string
+	services map[string]bool
+	oldest   time.Time
+}
+
+type degradationKey struct{}
+
+// WithDegradation returns a context in which backend fallbacks are
+// recorded in the returned Degradation
+func WithDegradation(ctx context.Context) (context.Context, *Degradation) {
+	d := &Degradation{services: map[string]bool{}}
+	return context.WithValue(ctx, degradationKey{}, d), d
+}
+
+// DegradationFrom returns the Degradation of a context, or nil. The
+// methods of a nil Degradation report no fallbacks.
+func DegradationFrom(ctx context.Context) *Degradation {
+	d, _ := ctx.Value(degradationKey{}).(*Degradation)
+	return d
+}
+
+// mark records a fallback served for service
+func (d *Degradation) mark(service, kind string, storedAt time.Time) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.services[service] = true
+	// A static payload is the more degraded of the two
+	if d.kind != FallbackStatic {
+		d.kind = kind
+	}
+	if !storedAt.IsZero() && (d.oldest.IsZero() || storedAt.Before(d.oldest)) {
+		d.oldest = storedAt
+	}
+}
+
+// Kind returns FallbackStatic or FallbackLastKnownGood if any fallback was
+// served, the former if both were, and "" otherwise
+func (d *Degradation) Kind() string {
+	if d == nil {
+		return ""
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.kind
+}
+
+// Services returns the services that were answered by a fallback
+func (d *Degradation) Services() []string {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	names := make([]string, 0, len(d.services))
+	for name := range d.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Oldest returns when the oldest last-known-good response served was
+// stored, or the zero time
+func (d *Degradation) Oldest() time.Time {
+	if d == nil {
+		return time.Time{}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.oldest
+}
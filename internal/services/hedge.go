@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	hedgeMu     sync.RWMutex
+	hedgeDelays = map[string]time.Duration{}
+)
+
+var upstreamHedges = metrics.Factory.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "upstream_hedge_total",
+		Help:      "Hedged GET calls by outcome: not_needed, primary_won or hedge_won",
+	},
+	[]string{"service", "outcome"},
+)
+
+// InitHedging turns on hedged GET calls for the upstreams in delays: when
+// the first attempt has not answered after the upstream's delay, a second
+// one is sent and whichever answers first is used
+func InitHedging(delays map[string]time.Duration) {
+	hedgeMu.Lock()
+	defer hedgeMu.Unlock()
+	hedgeDelays = delays
+}
+
+// hedgeDelayFor returns the hedge delay of an upstream, 0 if it is not hedged
+func hedgeDelayFor(service string) time.Duration {
+	hedgeMu.RLock()
+	defer hedgeMu.RUnlock()
+	return hedgeDelays[service]
+}
+
+// hedgeAttempt is the result of one attempt of a hedged call
+type hedgeAttempt struct {
+	response map[string]interface{}
+	err      error
+	hedge    bool
+}
+
+// hedgedCall makes a GET call that is sent a second time when the first
+// attempt is slower than delay. The first attempt that gets an answer from
+// the backend, even an error status, wins and the other is cancelled.
+// Connection failures wait for the other attempt, if one is running.
+func (es *ExternalService) hedgedCall(ctx context.Context, service string, delay time.Duration, client *http.Client, url, authKey string, response *map[string]interface{}) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered so the losing attempt can finish after we return
+	results := make(chan hedgeAttempt, 2)
+	attempt := func(hedge bool) {
+		var attemptResponse map[string]interface{}
+		err := es.makeHTTPCall(ctx, client, http.MethodGet, url, authKey, nil, &attemptResponse)
+		results <- hedgeAttempt{response: attemptResponse, err: err, hedge: hedge}
+	}
+
+	go attempt(false)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	running, hedged := 1, false
+	for {
+		select {
+		case <-timer.C:
+			hedged = true
+			running++
+			go attempt(true)
+
+		case result := <-results:
+			running--
+			var serviceErr *ServiceError
+			if result.err != nil && !errors.As(result.err, &serviceErr) && running > 0 {
+				continue
+			}
+
+			switch {
+			case result.hedge:
+				upstreamHedges.WithLabelValues(service, "hedge_won").Inc()
+			case hedged:
+				upstreamHedges.WithLabelValues(service, "primary_won").Inc()
+			default:
+				upstreamHedges.WithLabelValues(service, "not_needed").Inc()
+			}
+			*response = result.response
+			return result.err
+		}
+	}
+}
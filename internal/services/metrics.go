@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"InternalAPI/internal/metrics"
+	"InternalAPI/internal/resilience"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	externalServiceCalls = metrics.Factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "external_calls_total",
+			Help:      "Backend calls by service, method, endpoint and status",
+		},
+		[]string{"service", "method", "endpoint", "status"},
+	)
+
+	externalServiceDuration = metrics.Factory.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Name:      "external_duration_seconds",
+			Help:      "Backend call duration by service, method and endpoint, including retries",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"service", "method", "endpoint"},
+	)
+)
+
+// observeCall records a finished backend call. fallback is the kind of
+// fallback served instead, if any.
+func observeCall(service, method, endpoint string, started time.Time, err error, fallback string) {
+	label := endpointLabel(endpoint)
+	externalServiceCalls.WithLabelValues(service, method, label, callStatus(err, fallback)).Inc()
+	externalServiceDuration.WithLabelValues(service, method, label).Observe(time.Since(started).Seconds())
+}
+
+// callStatus is the status label of a call: the backend's HTTP status for
+// error answers, "ok" for other answers, or why there was no answer
+func callStatus(err error, fallback string) string {
+	var serviceErr *ServiceError
+	var openErr *resilience.OpenError
+	var bulkheadErr *resilience.BulkheadError
+	switch {
+	case fallback != "":
+		return "fallback"
+	case err == nil:
+		return "ok"
+	case errors.As(err, &serviceErr):
+		return strconv.Itoa(serviceErr.StatusCode)
+	case errors.As(err, &openErr):
+		return "circuit_open"
+	case errors.As(err, &bulkheadErr):
+		return "saturated"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
+
+// endpointLabel turns an endpoint into a label of bounded cardinality:
+// the query is dropped and path segments holding IDs, which contain
+// digits, become :id. /bookings/42?status=open becomes /bookings/:id.
+func endpointLabel(endpoint string) string {
+	path, _, _ := strings.Cut(endpoint, "?")
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.IndexFunc(segment, unicode.IsDigit) >= 0 {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"InternalAPI/internal/resilience"
+	"InternalAPI/internal/tracing"
+)
+
+// hopHeaders only concern one connection and are never forwarded
+var hopHeaders = []string{
+	"Connection", "Proxy-Connection", "Keep-Alive", "Proxy-Authenticate",
+	"Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// passthroughRequestHeaders are client headers replaced by the gateway: the
+// client's credentials stay with the gateway, which authenticates itself
+// with the service key instead. Encodings are negotiated by the gateway,
+// so response middleware sees plain bodies.
+var passthroughRequestHeaders = []string{
+	"Accept-Encoding", "Authorization", "Cookie", "X-Internal-API-Key", "X-Service-Key", "X-CSRF-Token",
+	"X-Request-ID", "X-User-ID", "X-Forwarded-For", "X-Forwarded-Host",
+	"X-Forwarded-Proto", "X-Forwarded-Prefix", "Forwarded",
+}
+
+// passthroughResponseHeaders are backend headers never sent to clients
+var passthroughResponseHeaders = []string{"Set-Cookie", "Server", "X-Powered-By"}
+
+var (
+	passthroughMu       sync.RWMutex
+	passthroughServices []string
+	passthroughStrip    []string // Further headers removed in both directions
+)
+
+// InitPassthrough enables transparent forwarding to the upstreams named in
+// names, and removes the headers in strip from requests and responses on
+// top of the ones always rewritten. API Beheerder has its allow-listed
+// proxy instead.
+func (es *ExternalService) InitPassthrough(names, strip []string) error {
+	var enabled []string
+	for _, name := range names {
+		upstream, ok := es.resolve(name)
+		if !ok {
+			return fmt.Errorf("passthrough to unknown service %s", name)
+		}
+		if upstream.Name == "api-beheerder" {
+			return fmt.Errorf("API Beheerder has its allow-listed proxy and cannot be passed through")
+		}
+		if upstream.Name == "stream" {
+			return fmt.Errorf("passthrough to %s would clash with the streaming proxy", name)
+		}
+		if !slices.Contains(enabled, upstream.Name) {
+			enabled = append(enabled, upstream.Name)
+		}
+	}
+	canonical := make([]string, len(strip))
+	for i, name := range strip {
+		canonical[i] = http.CanonicalHeaderKey(name)
+	}
+
+	passthroughMu.Lock()
+	defer passthroughMu.Unlock()
+	passthroughServices = enabled
+	passthroughStrip = canonical
+	return nil
+}
+
+// PassthroughServices returns the upstreams requests are forwarded to
+// transparently
+func PassthroughServices() []string {
+	passthroughMu.RLock()
+	defer passthroughMu.RUnlock()
+	return append([]string{}, passthroughServices...)
+}
+
+// Passthrough sends r to endpoint on a backend as it is and copies the
+// backend's answer, error statuses included, to w. Headers are rewritten:
+// the client's credentials and connection headers are dropped, the service
+// key, request and user IDs and X-Forwarded-* headers (with prefix, the
+// gateway path the backend is reached under) are set, and cookies and
+// server banners are removed from the response. Like Stream it holds a
+// bulkhead slot for the whole transfer while the timeout and breaker only
+// cover the wait for the response headers, and it never retries. An
+// error is returned only when nothing was written to w, or when copying
+// the body failed.
+func (es *ExternalService) Passthrough(ctx context.Context, serviceName, endpoint, prefix, clientIP string, r *http.Request, w http.ResponseWriter) error {
+	upstream, ok := es.resolve(serviceName)
+	if !ok {
+		return fmt.Errorf("unknown service: %s", serviceName)
+	}
+	breakerName := upstream.Name
+
+	cb := resilience.Breaker(breakerName)
+	if cb == nil {
+		return fmt.Errorf("circuit breaker not initialized for service: %s", breakerName)
+	}
+	release, err := resilience.Acquire(breakerName)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	ctx, span := tracing.Start(ctx, r.Method, tracing.KindClient)
+	defer span.End()
+	span.SetAttribute("peer.service", breakerName)
+	span.SetAttribute("http.request.method", r.Method)
+	span.SetAttribute("url.path", strings.SplitN(endpoint, "?", 2)[0])
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var body io.Reader
+	if r.ContentLength != 0 {
+		body = r.Body
+	}
+	transform := matchTransform(breakerName, r.Method, endpoint)
+	req, err := http.NewRequestWithContext(ctx, r.Method, upstream.URL+transform.rewriteEndpoint(endpoint), body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.ContentLength = r.ContentLength
+	req.Header = passthroughHeader(r.Header, passthroughRequestHeaders)
+	if transform != nil {
+		transform.RequestHeaders.applyHeaders(req.Header)
+	}
+	req.Header.Set("X-Service-Key", upstream.Key)
+	setCorrelationHeaders(ctx, req.Header)
+	tracing.Inject(ctx, req.Header)
+	if ip := net.ParseIP(clientIP); ip != nil {
+		req.Header.Set("X-Forwarded-For", ip.String())
+	}
+	proto := "http"
+	if r.TLS != nil {
+		proto = "https"
+	}
+	req.Header.Set("X-Forwarded-Proto", proto)
+	req.Header.Set("X-Forwarded-Host", r.Host)
+	req.Header.Set("X-Forwarded-Prefix", prefix)
+
+	timeout := resilience.TimeoutFor(breakerName)
+	var timedOut atomic.Bool
+	headerTimer := time.AfterFunc(timeout, func() {
+		timedOut.Store(true)
+		cancel()
+	})
+
+	started := time.Now()
+	var resp *http.Response
+	err = cb.Call(func() error {
+		var callErr error
+		resp, callErr = clientFor(breakerName).Do(req)
+		headerTimer.Stop()
+		if callErr != nil {
+			if timedOut.Load() {
+				return fmt.Errorf("no response from %s within %s: %w", breakerName, timeout, context.DeadlineExceeded)
+			}
+			return fmt.Errorf("failed to make request: %w", callErr)
+		}
+		if resp.StatusCode >= 500 {
+			// The answer is still passed on; the breaker counts the failure
+			return &ServiceError{StatusCode: resp.StatusCode, Service: breakerName, RequestID: RequestIDFrom(ctx)}
+		}
+		return nil
+	})
+	outcome := err
+	if outcome == nil && resp.StatusCode >= 400 {
+		outcome = &ServiceError{StatusCode: resp.StatusCode, Service: breakerName, RequestID: RequestIDFrom(ctx)}
+	}
+	recordOutcome(breakerName, outcome)
+	rediscover(breakerName, outcome)
+	observeCall(breakerName, r.Method, endpoint, started, outcome, "")
+	if resp == nil {
+		span.SetError(err.Error())
+		logCallFailure(ctx, breakerName, r.Method, endpoint, err)
+		return err
+	}
+	defer resp.Body.Close()
+	span.SetAttribute("http.response.status_code", resp.StatusCode)
+
+	header := passthroughHeader(resp.Header, passthroughResponseHeaders)
+	if transform != nil {
+		transform.ResponseHeaders.applyHeaders(header)
+	}
+	for name, values := range header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("X-Proxied-Upstream", breakerName)
+	w.WriteHeader(resp.StatusCode)
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to copy passthrough response: %w", err)
+	}
+	return nil
+}
+
+// passthroughHeader copies header without the hop-by-hop headers, the
+// headers named in Connection, the given headers and the configured ones
+func passthroughHeader(header http.Header, drop []string) http.Header {
+	copied := header.Clone()
+	if copied == nil {
+		copied = http.Header{}
+	}
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			copied.Del(strings.TrimSpace(name))
+		}
+	}
+	for _, name := range hopHeaders {
+		copied.Del(name)
+	}
+	for _, name := range drop {
+		copied.Del(name)
+	}
+	passthroughMu.RLock()
+	defer passthroughMu.RUnlock()
+	for _, name := range passthroughStrip {
+		copied.Del(name)
+	}
+	return copied
+}
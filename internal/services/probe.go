@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Probe checks that an upstream answers a GET of path. It bypasses the
+// resilience chain, so a recovered backend is seen while its circuit
+// breaker is still open. Any answer below 500 counts as reachable, so
+// backends without a health endpoint can be probed too.
+func (es *ExternalService) Probe(ctx context.Context, upstream Upstream, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(upstream.URL, "/")+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create probe request: %v", err)
+	}
+	req.Header.Set("X-Service-Key", upstream.Key)
+
+	resp, err := clientFor(upstream.Name).Do(req)
+	if err != nil {
+		rediscover(upstream.Name, err)
+		return fmt.Errorf("failed to reach %s: %w", upstream.Name, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("%s answered the probe with status %d", upstream.Name, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ProxyRule allows one API Beheerder endpoint pattern through the wildcard
+// proxy. Patterns match segment by segment: "*" matches one segment and a
+// trailing "**" matches the rest of the path.
+type ProxyRule struct {
+	Methods  []string `json:"methods"`
+	Pattern  string   `json:"pattern"`
+	Action   string   `json:"action,omitempty"`   // Central Management permission, if any
+	Resource string   `json:"resource,omitempty"` // Resource the permission applies to
+}
+
+var (
+	proxyRules       []ProxyRule
+	streamRules      []ProxyRule
+	proxyStripFields = map[string]bool{}
+	proxyMu          sync.RWMutex
+)
+
+// ParseProxyRules parses a spec like
+// "GET /minibar/items;GET,POST /spa/bookings/*=manage_spa:spa" where the
+// optional "=action:resource" names the permission Central Management checks
+func ParseProxyRules(spec string) ([]ProxyRule, error) {
+	var rules []ProxyRule
+
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		target, permission, hasPermission := strings.Cut(entry, "=")
+		methods, pattern, ok := strings.Cut(strings.TrimSpace(target), " ")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || !strings.HasPrefix(pattern, "/") {
+			return nil, fmt.Errorf("invalid proxy rule %q, expected \"METHODS /path[=action:resource]\"", entry)
+		}
+		if strings.Contains(pattern, "..") {
+			return nil, fmt.Errorf("invalid proxy rule %q: path must not contain ..", entry)
+		}
+
+		rule := ProxyRule{Pattern: strings.TrimSuffix(pattern, "/")}
+		for _, method := range strings.Split(methods, ",") {
+			if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+				rule.Methods = append(rule.Methods, method)
+			}
+		}
+		if hasPermission {
+			action, resource, ok := strings.Cut(strings.TrimSpace(permission), ":")
+			if !ok || action == "" || resource == "" {
+				return nil, fmt.Errorf("invalid proxy rule %q: permission must be action:resource", entry)
+			}
+			rule.Action, rule.Resource = action, resource
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// InitProxyRules sets the proxy allow-list and the response fields the
+// proxy always removes
+func InitProxyRules(spec string, stripFields []string) error {
+	rules, err := ParseProxyRules(spec)
+	if err != nil {
+		return err
+	}
+
+	strip := make(map[string]bool, len(stripFields))
+	for _, field := range stripFields {
+		if field = strings.TrimSpace(field); field != "" {
+			strip[field] = true
+		}
+	}
+
+	proxyMu.Lock()
+	defer proxyMu.Unlock()
+	proxyRules = rules
+	proxyStripFields = strip
+	return nil
+}
+
+// ProxyRules returns the configured proxy allow-list
+func ProxyRules() []ProxyRule {
+	proxyMu.RLock()
+	defer proxyMu.RUnlock()
+	return append([]ProxyRule(nil), proxyRules...)
+}
+
+// InitStreamRules sets the allow-list of the streaming proxy. Streamed
+// responses skip field stripping, masking and decryption, so it is kept
+// separate from the proxy allow-list and only GET rules are accepted.
+func InitStreamRules(spec string) error {
+	rules, err := ParseProxyRules(spec)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		for _, method := range rule.Methods {
+			if method != "GET" {
+				return fmt.Errorf("invalid stream rule %s: only GET can be streamed", rule.Pattern)
+			}
+		}
+	}
+
+	proxyMu.Lock()
+	defer proxyMu.Unlock()
+	streamRules = rules
+	return nil
+}
+
+// StreamRules returns the configured streaming proxy allow-list
+func StreamRules() []ProxyRule {
+	proxyMu.RLock()
+	defer proxyMu.RUnlock()
+	return append([]ProxyRule(nil), streamRules...)
+}
+
+// MatchProxyRule returns the first rule allowing method on path
+func MatchProxyRule(method, path string) (ProxyRule, bool) {
+	proxyMu.RLock()
+	defer proxyMu.RUnlock()
+	return matchRules(proxyRules, method, path)
+}
+
+// MatchStreamRule returns the first streaming rule allowing method on path
+func MatchStreamRule(method, path string) (ProxyRule, bool) {
+	proxyMu.RLock()
+	defer proxyMu.RUnlock()
+	return matchRules(streamRules, method, path)
+}
+
+// matchRules returns the first of rules allowing method on path
+func matchRules(rules []ProxyRule, method, path string) (ProxyRule, bool) {
+	for _, rule := range rules {
+		if rule.allowsMethod(method) && matchProxyPattern(rule.Pattern, path) {
+			return rule, true
+		}
+	}
+	return ProxyRule{}, false
+}
+
+// allowsMethod reports whether the rule covers an HTTP method
+func (r ProxyRule) allowsMethod(method string) bool {
+	for _, allowed := range r.Methods {
+		if allowed == method {
+			return true
+		}
+	}
+	return false
+}
+
+// matchProxyPattern matches a path against a rule pattern segment by segment
+func matchProxyPattern(pattern, path string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, part := range patternParts {
+		if part == "**" && i == len(patternParts)-1 {
+			return len(pathParts) > i
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if part != "*" && part != pathParts[i] {
+			return false
+		}
+	}
+	return len(pathParts) == len(patternParts)
+}
+
+// StripProxyFields removes the configured fields anywhere in a proxied response
+func StripProxyFields(value interface{}) {
+	proxyMu.RLock()
+	defer proxyMu.RUnlock()
+	stripFields(value, proxyStripFields)
+}
+
+// stripFields removes fields from nested objects and lists
+func stripFields(value interface{}, fields map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if fields[key] {
+				delete(v, key)
+			} else {
+				stripFields(field, fields)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			stripFields(item, fields)
+		}
+	}
+}
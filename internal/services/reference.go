@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/cache"
+)
+
+// referenceCache holds reference data such as business rules and role definitions
+var referenceCache = cache.New()
+
+// ReferenceDataset describes a reference dataset to preload at startup
+type ReferenceDataset struct {
+	Name     string
+	Service  string
+	Endpoint string
+}
+
+// DatasetStatus reports the warm-up outcome for a single dataset
+type DatasetStatus struct {
+	Status     string `json:"status"` // pending, loaded, failed, timed_out
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	LoadedAt   int64  `json:"loaded_at,omitempty"`
+}
+
+// WarmStatus reports the state of the startup warming phase
+type WarmStatus struct {
+	State    string                   `json:"state"` // pending, warming, complete
+	Datasets map[string]DatasetStatus `json:"datasets"`
+}
+
+var (
+	warmStatus = WarmStatus{State: "pending", Datasets: map[string]DatasetStatus{}}
+	// warmGeneration changes when a warm-up starts or finishes, so loads
+	// that outlive their warm-up cannot overwrite its final status
+	warmGeneration uint64
+	warmMu         sync.RWMutex
+)
+
+// ParseReferenceDatasets parses a spec like "roles=central:/admin/roles,rules=central:/business-rules/albums"
+func ParseReferenceDatasets(spec string) []ReferenceDataset {
+	var datasets []ReferenceDataset
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		name, target, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		service, endpoint, ok := strings.Cut(target, ":")
+		if !ok {
+			continue
+		}
+		datasets = append(datasets, ReferenceDataset{
+			Name:     strings.TrimSpace(name),
+			Service:  strings.TrimSpace(service),
+			Endpoint: strings.TrimSpace(endpoint),
+		})
+	}
+
+	return datasets
+}
+
+// CallCached performs a GET through the reference cache, fetching from the
+// backend on a miss
+func (es *ExternalService) CallCached(ctx context.Context, serviceName, endpoint string) (map[string]interface{}, error) {
+	key := referenceKey(serviceName, endpoint)
+	if cached, ok := referenceCache.Get(key); ok {
+		return cached.(map[string]interface{}), nil
+	}
+
+	response, err := es.Call(ctx, serviceName, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	referenceCache.Set(key, response, es.config.ReferenceCacheTTL)
+	return response, nil
+}
+
+// FlushReferenceCache drops all cached reference data and returns how many entries were removed
+func FlushReferenceCache() int {
+	return referenceCache.DeletePrefix("")
+}
+
+// RefreshReferenceData drops the cached copies of the given datasets and loads them again
+func (es *ExternalService) RefreshReferenceData(datasets []ReferenceDataset, timeout time.Duration) WarmStatus {
+	for _, ds := range datasets {
+		referenceCache.Delete(referenceKey(ds.Service, ds.Endpoint))
+	}
+	return es.WarmReferenceData(datasets, timeout)
+}
+
+// WarmReferenceData preloads the given datasets in parallel, giving up on any
+// that have not loaded within timeout
+func (es *ExternalService) WarmReferenceData(datasets []ReferenceDataset, timeout time.Duration) WarmStatus {
+	warmMu.Lock()
+	warmGeneration++
+	generation := warmGeneration
+	warmStatus.State = "warming"
+	for _, ds := range datasets {
+		warmStatus.Datasets[ds.Name] = DatasetStatus{Status: "pending"}
+	}
+	warmMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, ds := range datasets {
+		wg.Add(1)
+		go func(ds ReferenceDataset) {
+			defer wg.Done()
+
+			start := time.Now()
+			_, err := es.CallCached(ctx, ds.Service, ds.Endpoint)
+
+			status := DatasetStatus{
+				Status:     "loaded",
+				DurationMs: time.Since(start).Milliseconds(),
+				LoadedAt:   time.Now().Unix(),
+			}
+			if err != nil {
+				status = DatasetStatus{
+					Status:     "failed",
+					Error:      err.Error(),
+					DurationMs: time.Since(start).Milliseconds(),
+				}
+			}
+
+			warmMu.Lock()
+			if warmGeneration == generation {
+				warmStatus.Datasets[ds.Name] = status
+			}
+			warmMu.Unlock()
+		}(ds)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	warmMu.Lock()
+	defer warmMu.Unlock()
+
+	// A newer warm-up took over and will report its own result
+	if warmGeneration != generation {
+		return copyWarmStatus()
+	}
+	warmGeneration++
+
+	for name, status := range warmStatus.Datasets {
+		if status.Status == "pending" {
+			warmStatus.Datasets[name] = DatasetStatus{
+				Status:     "timed_out",
+				DurationMs: timeout.Milliseconds(),
+			}
+		}
+	}
+	warmStatus.State = "complete"
+
+	return copyWarmStatus()
+}
+
+// GetWarmStatus returns the current state of the warming phase
+func GetWarmStatus() WarmStatus {
+	warmMu.RLock()
+	defer warmMu.RUnlock()
+	return copyWarmStatus()
+}
+
+// copyWarmStatus copies the warm status. Callers must hold warmMu.
+func copyWarmStatus() WarmStatus {
+	datasets := make(map[string]DatasetStatus, len(warmStatus.Datasets))
+	for name, status := range warmStatus.Datasets {
+		datasets[name] = status
+	}
+	return WarmStatus{State: warmStatus.State, Datasets: datasets}
+}
+
+// referenceKey builds the cache key for a service endpoint
+func referenceKey(serviceName, endpoint string) string {
+	switch serviceName {
+	case "beheerder":
+		serviceName = "api-beheerder"
+	case "central":
+		serviceName = "central-mgmt"
+	}
+	return serviceName + ":" + endpoint
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/events"
+	"InternalAPI/internal/resilience"
+
+	"github.com/google/uuid"
+)
+
+// Upstream describes a backend service the gateway calls
+type Upstream struct {
+	Name        string   // Circuit breaker and metrics name
+	Aliases     []string // Names accepted by Call
+	Description string
+	URL         string
+	Key         string // Sent as X-Service-Key
+	SPIFFEID    string // Expected server identity when mTLS is enabled
+}
+
+// UpstreamHealth is the outcome of the most recent calls to an upstream
+type UpstreamHealth struct {
+	Status      string     `json:"status"` // healthy, degraded, down or unknown
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastFailure *time.Time `json:"last_failure,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// Dependency describes an upstream for the dependency graph
+type Dependency struct {
+	Name         string         `json:"name"`
+	Aliases      []string       `json:"aliases"`
+	Description  string         `json:"description"`
+	URL          string         `json:"url"`
+	Auth         []string       `json:"auth"`
+	BreakerState string         `json:"breaker_state"`
+	Health       UpstreamHealth `json:"health"`
+	Routes       []string       `json:"routes"`
+}
+
+var (
+	healthMu       sync.RWMutex
+	upstreamHealth = make(map[string]*UpstreamHealth)
+
+	routeDepsMu sync.RWMutex
+	routeDeps   = make(map[string]map[string]bool)
+
+	extraMu        sync.RWMutex
+	extraUpstreams []Upstream
+
+	keysMu       sync.RWMutex
+	upstreamKeys = make(map[string]string) // Service keys rotated since startup, by upstream name
+)
+
+// RegisterUpstream adds a backend configured at startup, such as a
+// third-party PMS, so Call, the dependency graph and health reporting know it.
+// Its circuit breaker must be initialized under the upstream's Name.
+func RegisterUpstream(upstream Upstream) {
+	extraMu.Lock()
+	defer extraMu.Unlock()
+	extraUpstreams = append(extraUpstreams, upstream)
+}
+
+// SetUpstreamKey replaces the key sent to an upstream, e.g. after the
+// secrets backend rotated it
+func SetUpstreamKey(name, key string) {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	upstreamKeys[name] = key
+}
+
+// Upstreams returns the backend services known to the gateway, at the
+// addresses found with the broker when discovery is on
+func (es *ExternalService) Upstreams() []Upstream {
+	upstreams := []Upstream{
+		{
+			Name:        "api-beheerder",
+			Aliases:     []string{"beheerder", "api-beheerder"},
+			Description: "Hotel operations: albums, bookings, rooms and inventory",
+			URL:         es.config.APIBeheerderURL,
+			Key:         es.config.APIBeheerderKey,
+			SPIFFEID:    es.config.APIBeheerderSPIFFEID,
+		},
+		{
+			Name:        "central-mgmt",
+			Aliases:     []string{"central", "central-mgmt"},
+			Description: "Central Management: authentication, users, roles, permissions and rates",
+			URL:         es.config.CentralMgmtURL,
+			Key:         es.config.CentralMgmtKey,
+			SPIFFEID:    es.config.CentralMgmtSPIFFEID,
+		},
+	}
+
+	extraMu.RLock()
+	upstreams = append(upstreams, extraUpstreams...)
+	extraMu.RUnlock()
+
+	keysMu.RLock()
+	for i := range upstreams {
+		if key, ok := upstreamKeys[upstreams[i].Name]; ok {
+			upstreams[i].Key = key
+		}
+	}
+	keysMu.RUnlock()
+
+	urlsMu.RLock()
+	defer urlsMu.RUnlock()
+	for i := range upstreams {
+		if address, ok := upstreamURLs[upstreams[i].Name]; ok {
+			upstreams[i].URL = address
+		}
+	}
+	return upstreams
+}
+
+// resolve finds an upstream by name or alias
+func (es *ExternalService) resolve(serviceName string) (Upstream, bool) {
+	for _, upstream := range es.Upstreams() {
+		for _, alias := range upstream.Aliases {
+			if alias == serviceName {
+				return upstream, true
+			}
+		}
+	}
+	return Upstream{}, false
+}
+
+// Dependencies describes every upstream with its breaker state, recent
+// health and the routes that call it
+func (es *ExternalService) Dependencies() []Dependency {
+	deps := []Dependency{}
+	for _, upstream := range es.Upstreams() {
+		auth := []string{"service_key (X-Service-Key)"}
+		if es.config.MTLSEnabled {
+			auth = append(auth, "mtls")
+		}
+
+		breakerState := "not_initialized"
+		if cb := resilience.Breaker(upstream.Name); cb != nil {
+			breakerState = cb.GetState().String()
+		}
+
+		deps = append(deps, Dependency{
+			Name:         upstream.Name,
+			Aliases:      upstream.Aliases,
+			Description:  upstream.Description,
+			URL:          SanitizeURL(upstream.URL),
+			Auth:         auth,
+			BreakerState: breakerState,
+			Health:       healthOf(upstream.Name, breakerState),
+			Routes:       RouteDependencies(upstream.Name),
+		})
+	}
+	return deps
+}
+
+// RegisterRouteDependency records that a route calls an upstream
+func RegisterRouteDependency(upstream, method, path string) {
+	routeDepsMu.Lock()
+	defer routeDepsMu.Unlock()
+	if routeDeps[upstream] == nil {
+		routeDeps[upstream] = make(map[string]bool)
+	}
+	routeDeps[upstream][method+" "+path] = true
+}
+
+// RouteDependencies returns the routes that call an upstream, sorted
+func RouteDependencies(upstream string) []string {
+	routeDepsMu.RLock()
+	defer routeDepsMu.RUnlock()
+	routes := make([]string, 0, len(routeDeps[upstream]))
+	for route := range routeDeps[upstream] {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// SanitizeURL strips credentials, query and fragment from an upstream URL
+func SanitizeURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "invalid"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// recordOutcome remembers the result of a call for health reporting. Client
+// errors (4xx) still prove the upstream is reachable, and calls cancelled by
+// a disconnecting client prove nothing.
+func recordOutcome(upstream string, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	var statusErr *ServiceError
+	failed := err != nil && !(errors.As(err, &statusErr) && statusErr.StatusCode < 500)
+	now := time.Now()
+
+	healthMu.Lock()
+	h, ok := upstreamHealth[upstream]
+	if !ok {
+		h = &UpstreamHealth{}
+		upstreamHealth[upstream] = h
+	}
+	from := outcomeStatus(h)
+	if failed {
+		h.LastFailure = &now
+		h.LastError = err.Error()
+	} else {
+		h.LastSuccess = &now
+	}
+	to, lastError := outcomeStatus(h), h.LastError
+	healthMu.Unlock()
+
+	if from != to {
+		data := map[string]interface{}{"service": upstream, "from": from, "to": to}
+		if failed {
+			data["last_error"] = lastError
+		}
+		events.PublishAdmin(events.Event{
+			ID:         uuid.New().String(),
+			Type:       "health.changed",
+			Source:     "internal-api",
+			OccurredAt: now,
+			Data:       data,
+		})
+	}
+}
+
+// outcomeStatus derives health from the last calls alone: unknown before
+// the first call, degraded while the latest call failed
+func outcomeStatus(h *UpstreamHealth) string {
+	switch {
+	case h.LastSuccess == nil && h.LastFailure == nil:
+		return "unknown"
+	case h.LastFailure != nil && (h.LastSuccess == nil || h.LastFailure.After(*h.LastSuccess)):
+		return "degraded"
+	default:
+		return "healthy"
+	}
+}
+
+// healthOf derives an upstream's health from its breaker and last calls
+func healthOf(upstream, breakerState string) UpstreamHealth {
+	healthMu.RLock()
+	defer healthMu.RUnlock()
+
+	health := UpstreamHealth{Status: "unknown"}
+	if h, ok := upstreamHealth[upstream]; ok {
+		health = *h
+		health.Status = outcomeStatus(h)
+	}
+
+	switch breakerState {
+	case resilience.StateOpen.String():
+		health.Status = "down"
+	case resilience.StateHalfOpen.String():
+		health.Status = "degraded"
+	}
+	return health
+}
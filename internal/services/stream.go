@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"InternalAPI/internal/resilience"
+	"InternalAPI/internal/tracing"
+)
+
+// streamedRequestHeaders are the client request headers Stream forwards,
+// so downloads can be negotiated and resumed
+var streamedRequestHeaders = []string{"Accept", "Range", "If-Range"}
+
+// streamedResponseHeaders are the backend response headers Stream copies
+var streamedResponseHeaders = []string{
+	"Content-Type", "Content-Length", "Content-Disposition", "Content-Range",
+	"Accept-Ranges", "ETag", "Last-Modified",
+}
+
+// maxErrorBody caps how much of a backend error response is read
+const maxErrorBody = 1 << 20
+
+// Stream sends a request to a backend and copies the response body to w as
+// it arrives, flushing after every chunk, instead of decoding it. It suits
+// report downloads and exports too large to hold in memory. Streams skip
+// the service's policy chain: the bulkhead slot is held for the whole
+// transfer, while the timeout and breaker only cover the wait for the
+// response headers; after that only ctx ends the transfer. Backend error statuses are returned as a ServiceError
+// with nothing written to w. Field encryption is not applied, so only
+// stream endpoints without encrypted fields.
+func (es *ExternalService) Stream(ctx context.Context, serviceName, method, endpoint string, header http.Header, w http.ResponseWriter) error {
+	upstream, ok := es.resolve(serviceName)
+	if !ok {
+		return fmt.Errorf("unknown service: %s", serviceName)
+	}
+	breakerName := upstream.Name
+
+	cb := resilience.Breaker(breakerName)
+	if cb == nil {
+		return fmt.Errorf("circuit breaker not initialized for service: %s", breakerName)
+	}
+
+	// The slot is held until the whole body has been copied
+	release, err := resilience.Acquire(breakerName)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	ctx, span := tracing.Start(ctx, method, tracing.KindClient)
+	defer span.End()
+	span.SetAttribute("peer.service", breakerName)
+	span.SetAttribute("http.request.method", method)
+	span.SetAttribute("url.path", strings.SplitN(endpoint, "?", 2)[0])
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	transform := matchTransform(breakerName, method, endpoint)
+	req, err := http.NewRequestWithContext(ctx, method, upstream.URL+transform.rewriteEndpoint(endpoint), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	for _, name := range streamedRequestHeaders {
+		if value := header.Get(name); value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+	if transform != nil {
+		transform.RequestHeaders.applyHeaders(req.Header)
+	}
+	req.Header.Set("X-Service-Key", upstream.Key)
+	setCorrelationHeaders(ctx, req.Header)
+	tracing.Inject(ctx, req.Header)
+
+	// The breaker only judges the wait for the response headers; a long
+	// download says nothing more about the service's health
+	timeout := resilience.TimeoutFor(breakerName)
+	var timedOut atomic.Bool
+	headerTimer := time.AfterFunc(timeout, func() {
+		timedOut.Store(true)
+		cancel()
+	})
+
+	started := time.Now()
+	var resp *http.Response
+	var callErr error
+	err = cb.Call(func() error {
+		resp, callErr = clientFor(breakerName).Do(req)
+		headerTimer.Stop()
+		if callErr != nil {
+			if timedOut.Load() {
+				callErr = fmt.Errorf("no response from %s within %s: %w", breakerName, timeout, context.DeadlineExceeded)
+			} else {
+				callErr = fmt.Errorf("failed to make request: %w", callErr)
+			}
+			return callErr
+		}
+		if resp.StatusCode >= 400 {
+			callErr = streamError(ctx, breakerName, resp)
+			if resp.StatusCode < 500 {
+				return nil
+			}
+		}
+		return callErr
+	})
+	if err == nil {
+		err = callErr
+	}
+	recordOutcome(breakerName, err)
+	observeCall(breakerName, method, endpoint, started, err, "")
+	if err != nil {
+		span.SetError(err.Error())
+		logCallFailure(ctx, breakerName, method, endpoint, err)
+		return err
+	}
+	defer resp.Body.Close()
+	span.SetAttribute("http.response.status_code", resp.StatusCode)
+
+	if transform != nil {
+		transform.ResponseHeaders.applyHeaders(resp.Header)
+	}
+	for _, name := range streamedResponseHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			w.Header().Set(name, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return fmt.Errorf("failed to write streamed response: %w", err)
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read streamed response: %w", readErr)
+		}
+	}
+}
+
+// streamError reads a backend error response into a ServiceError
+func streamError(ctx context.Context, service string, resp *http.Response) error {
+	defer resp.Body.Close()
+
+	var body map[string]interface{}
+	json.NewDecoder(io.LimitReader(resp.Body, maxErrorBody)).Decode(&body)
+	serviceErr := newServiceError(resp.StatusCode, body)
+	serviceErr.Service = service
+	serviceErr.RequestID = RequestIDFrom(ctx)
+	return serviceErr
+}
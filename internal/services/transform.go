@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// TransformRule adapts the calls to matching backend endpoints to a changed
+// backend API, e.g. albums renamed to rooms, without changing handlers.
+// Paths match the endpoint the gateway calls, without the query string,
+// with patterns as in proxy rules.
+type TransformRule struct {
+	Service         string            `json:"service"`           // Upstream name or alias
+	Methods         []string          `json:"methods,omitempty"` // Empty matches every method
+	Paths           []string          `json:"paths"`
+	Rewrite         *PathRewrite      `json:"rewrite,omitempty"`
+	RequestHeaders  HeaderChanges     `json:"request_headers"`
+	ResponseHeaders HeaderChanges     `json:"response_headers"` // Streamed and passed-through responses only
+	Fields          map[string]string `json:"fields,omitempty"` // Backend name by gateway name, at any depth
+}
+
+// PathRewrite replaces the leading path segments From with To
+type PathRewrite struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// HeaderChanges sets and removes headers
+type HeaderChanges struct {
+	Set    map[string]string `json:"set,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+}
+
+type transformKey struct{}
+
+var (
+	transformMu    sync.RWMutex
+	transformRules []TransformRule
+)
+
+// InitTransforms loads the rules in file, a JSON list of TransformRule; an
+// empty file name removes every rule. The first rule matching a call
+// applies.
+func (es *ExternalService) InitTransforms(file string) error {
+	var rules []TransformRule
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read transform rules: %v", err)
+		}
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return fmt.Errorf("invalid transform rules: %v", err)
+		}
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		upstream, ok := es.resolve(rule.Service)
+		if !ok {
+			return fmt.Errorf("transform rule %d: unknown service %q", i+1, rule.Service)
+		}
+		rule.Service = upstream.Name
+		for j, method := range rule.Methods {
+			rule.Methods[j] = strings.ToUpper(strings.TrimSpace(method))
+		}
+		if len(rule.Paths) == 0 {
+			return fmt.Errorf("transform rule %d: paths is required", i+1)
+		}
+		for j, path := range rule.Paths {
+			if !strings.HasPrefix(path, "/") || strings.Contains(path, "..") {
+				return fmt.Errorf("transform rule %d: invalid path %q", i+1, path)
+			}
+			rule.Paths[j] = strings.TrimSuffix(path, "/")
+		}
+		if rule.Rewrite != nil {
+			for _, path := range []string{rule.Rewrite.From, rule.Rewrite.To} {
+				if !strings.HasPrefix(path, "/") || strings.Contains(path, "..") {
+					return fmt.Errorf("transform rule %d: invalid rewrite path %q", i+1, path)
+				}
+			}
+			rule.Rewrite.From = strings.TrimSuffix(rule.Rewrite.From, "/")
+			rule.Rewrite.To = strings.TrimSuffix(rule.Rewrite.To, "/")
+		}
+		backendNames := make(map[string]bool, len(rule.Fields))
+		for field, backendName := range rule.Fields {
+			if field == "" || backendName == "" || backendNames[backendName] {
+				return fmt.Errorf("transform rule %d: fields must map names to distinct non-empty names", i+1)
+			}
+			backendNames[backendName] = true
+		}
+	}
+
+	transformMu.Lock()
+	defer transformMu.Unlock()
+	transformRules = rules
+	return nil
+}
+
+// TransformRules returns the loaded transform rules
+func TransformRules() []TransformRule {
+	transformMu.RLock()
+	defer transformMu.RUnlock()
+	return append([]TransformRule{}, transformRules...)
+}
+
+// matchTransform returns the first rule for a call, or nil
+func matchTransform(service, method, endpoint string) *TransformRule {
+	path, _, _ := strings.Cut(endpoint, "?")
+	transformMu.RLock()
+	defer transformMu.RUnlock()
+	for i := range transformRules {
+		rule := &transformRules[i]
+		if rule.Service != service || (len(rule.Methods) > 0 && !slices.Contains(rule.Methods, method)) {
+			continue
+		}
+		for _, pattern := range rule.Paths {
+			if matchProxyPattern(pattern, path) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// withTransform makes the rule's request headers apply to the calls made
+// with ctx
+func withTransform(ctx context.Context, rule *TransformRule) context.Context {
+	if rule == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, transformKey{}, rule)
+}
+
+// transformFrom returns the rule applying to calls made with ctx, or nil
+func transformFrom(ctx context.Context) *TransformRule {
+	rule, _ := ctx.Value(transformKey{}).(*TransformRule)
+	return rule
+}
+
+// rewriteEndpoint returns the endpoint the backend is called at
+func (rule *TransformRule) rewriteEndpoint(endpoint string) string {
+	if rule == nil || rule.Rewrite == nil {
+		return endpoint
+	}
+	path, query, hasQuery := strings.Cut(endpoint, "?")
+	rest, ok := strings.CutPrefix(path, rule.Rewrite.From)
+	if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+		return endpoint
+	}
+	path = rule.Rewrite.To + rest
+	if path == "" {
+		path = "/"
+	}
+	if hasQuery {
+		path += "?" + query
+	}
+	return path
+}
+
+// applyHeaders changes header as told
+func (changes HeaderChanges) applyHeaders(header http.Header) {
+	for _, name := range changes.Remove {
+		header.Del(name)
+	}
+	for name, value := range changes.Set {
+		header.Set(name, value)
+	}
+}
+
+// requestData renames the fields of a request body to the backend's names
+func (rule *TransformRule) requestData(data interface{}) (interface{}, error) {
+	if rule == nil || len(rule.Fields) == 0 || data == nil {
+		return data, nil
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request for field mapping: %v", err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return nil, fmt.Errorf("failed to decode request for field mapping: %v", err)
+	}
+	renameFields(generic, rule.Fields)
+	return generic, nil
+}
+
+// responseData renames the fields of a backend response to the gateway's
+// names
+func (rule *TransformRule) responseData(response map[string]interface{}) {
+	if rule == nil || len(rule.Fields) == 0 {
+		return
+	}
+	reverse := make(map[string]string, len(rule.Fields))
+	for field, backendName := range rule.Fields {
+		reverse[backendName] = field
+	}
+	renameFields(response, reverse)
+}
+
+// renameFields renames fields in nested objects and lists
+func renameFields(value interface{}, names map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		renamed := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			renameFields(field, names)
+			if name, ok := names[key]; ok {
+				renamed[name] = field
+				delete(v, key)
+			}
+		}
+		for key, field := range renamed {
+			v[key] = field
+		}
+	case []interface{}:
+		for _, item := range v {
+			renameFields(item, names)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TransportSettings tunes the connection pool each backend service gets
+type TransportSettings struct {
+	MaxConnsPerHost     int           // Connections per service, 0 for no limit
+	MaxIdleConnsPerHost int           // Idle connections kept for reuse per service
+	IdleConnTimeout     time.Duration // How long an idle connection is kept
+	TLSHandshakeTimeout time.Duration
+	KeepAlive           time.Duration // TCP keep-alive probe interval
+	HTTP2               bool          // Negotiate HTTP/2 with TLS backends
+}
+
+var (
+	clientsMu         sync.Mutex
+	transportSettings = TransportSettings{
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		KeepAlive:           30 * time.Second,
+		HTTP2:               true,
+	}
+)
+
+var (
+	upstreamConnectionsOpen = metrics.Factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Name:      "upstream_connections_open",
+			Help:      "Open connections to each backend service, idle or in use",
+		},
+		[]string{"service"},
+	)
+
+	upstreamConnectionsMax = metrics.Factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Name:      "upstream_connections_max",
+			Help:      "Configured connection limit per backend service, 0 for no limit",
+		},
+		[]string{"service"},
+	)
+
+	upstreamRequestsInFlight = metrics.Factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Name:      "upstream_requests_in_flight",
+			Help:      "Requests to each backend service waiting for a response",
+		},
+		[]string{"service"},
+	)
+)
+
+// InitTransports sets the pool settings of backend clients. It must run
+// before InitMTLS and before the first backend call.
+func InitTransports(settings TransportSettings) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	transportSettings = settings
+	serviceClients = map[string]*http.Client{}
+}
+
+// newClient creates the HTTP client of a service with its own connection
+// pool. tlsConfig may be nil to use the system roots.
+func newClient(service string, tlsConfig *tls.Config) *http.Client {
+	settings := transportSettings
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: settings.KeepAlive}
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: settings.TLSHandshakeTimeout,
+		IdleConnTimeout:     settings.IdleConnTimeout,
+		MaxConnsPerHost:     settings.MaxConnsPerHost,
+		MaxIdleConns:        settings.MaxIdleConnsPerHost,
+		MaxIdleConnsPerHost: settings.MaxIdleConnsPerHost,
+		ForceAttemptHTTP2:   settings.HTTP2,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			upstreamConnectionsOpen.WithLabelValues(service).Inc()
+			return &countedConn{Conn: conn, service: service}, nil
+		},
+	}
+	if !settings.HTTP2 {
+		// A non-nil empty map turns off the transport's HTTP/2 support
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	upstreamConnectionsMax.WithLabelValues(service).Set(float64(settings.MaxConnsPerHost))
+	// Calls are bounded by their context instead of a client timeout, see Call
+	return &http.Client{Transport: &inFlightTransport{base: transport, service: service}}
+}
+
+// clientFor returns the HTTP client for a service, creating it on first use
+func clientFor(breakerName string) *http.Client {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	client, ok := serviceClients[breakerName]
+	if !ok {
+		client = newClient(breakerName, nil)
+		serviceClients[breakerName] = client
+	}
+	return client
+}
+
+// countedConn keeps hotel_upstream_connections_open up to date
+type countedConn struct {
+	net.Conn
+	service string
+	once    sync.Once
+}
+
+// Close closes the connection and counts it as closed once
+func (c *countedConn) Close() error {
+	c.once.Do(func() { upstreamConnectionsOpen.WithLabelValues(c.service).Dec() })
+	return c.Conn.Close()
+}
+
+// inFlightTransport counts requests that are waiting for a response
+type inFlightTransport struct {
+	base    http.RoundTripper
+	service string
+}
+
+// RoundTrip sends the request through the service's pool
+func (t *inFlightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	gauge := upstreamRequestsInFlight.WithLabelValues(t.service)
+	gauge.Inc()
+	defer gauge.Dec()
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// upstreamName is the form of a configured upstream's name; it becomes a
+// breaker name and metrics label
+var upstreamName = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// reservedUpstreams are names the gateway's own upstreams use
+var reservedUpstreams = []string{"api-beheerder", "beheerder", "central-mgmt", "central"}
+
+// configuredUpstreams are the names registered by InitUpstreams
+var configuredUpstreams []string
+
+// InitUpstreams registers the backends named in serviceSpec, e.g.
+// "billing=https://billing.internal,housekeeping=http://hk:8080", with
+// service keys from keySpec and SPIFFE IDs from spiffeSpec (also
+// name=value). Call them by name like the built-in upstreams. Timeouts,
+// concurrency limits, breaker policies, resilience chains, probe paths and
+// discovery slugs are set per name by their own settings. It returns the
+// names, sorted, so the caller can initialize their circuit breakers.
+func InitUpstreams(serviceSpec, keySpec, spiffeSpec string) ([]string, error) {
+	backends, err := parsePairs(serviceSpec, "upstream service")
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parsePairs(keySpec, "upstream service key")
+	if err != nil {
+		return nil, err
+	}
+	spiffeIDs, err := parsePairs(spiffeSpec, "upstream service SPIFFE ID")
+	if err != nil {
+		return nil, err
+	}
+	for name := range keys {
+		if _, ok := backends[name]; !ok {
+			return nil, fmt.Errorf("upstream service key for unknown service %s", name)
+		}
+	}
+	for name := range spiffeIDs {
+		if _, ok := backends[name]; !ok {
+			return nil, fmt.Errorf("upstream service SPIFFE ID for unknown service %s", name)
+		}
+	}
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !upstreamName.MatchString(name) {
+			return nil, fmt.Errorf("upstream service %s: name must be lowercase letters, digits and dashes", name)
+		}
+		if isReservedUpstream(name) {
+			return nil, fmt.Errorf("upstream service %s: name is taken", name)
+		}
+		u, err := url.Parse(backends[name])
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("upstream service %s: invalid URL %q", name, backends[name])
+		}
+		RegisterUpstream(Upstream{
+			Name:        name,
+			Aliases:     []string{name},
+			Description: "Configured backend " + name,
+			URL:         strings.TrimSuffix(backends[name], "/"),
+			Key:         keys[name],
+			SPIFFEID:    spiffeIDs[name],
+		})
+	}
+	keysMu.Lock()
+	configuredUpstreams = names
+	keysMu.Unlock()
+	return names, nil
+}
+
+// SetUpstreamKeys replaces the keys of the configured upstreams from a
+// keySpec as taken by InitUpstreams, e.g. after the secrets backend rotated
+// them. Upstreams missing from keySpec are called without a key.
+func SetUpstreamKeys(keySpec string) error {
+	keys, err := parsePairs(keySpec, "upstream service key")
+	if err != nil {
+		return err
+	}
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	for name := range keys {
+		if !slices.Contains(configuredUpstreams, name) {
+			return fmt.Errorf("upstream service key for unknown service %s", name)
+		}
+	}
+	for _, name := range configuredUpstreams {
+		upstreamKeys[name] = keys[name]
+	}
+	return nil
+}
+
+// isReservedUpstream reports whether name is used by the gateway's own
+// upstreams, a PMS adapter or an upstream registered before
+func isReservedUpstream(name string) bool {
+	if strings.HasPrefix(name, "pms-") {
+		return true
+	}
+	for _, reserved := range reservedUpstreams {
+		if name == reserved {
+			return true
+		}
+	}
+	extraMu.RLock()
+	defer extraMu.RUnlock()
+	for _, upstream := range extraUpstreams {
+		for _, alias := range upstream.Aliases {
+			if alias == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parsePairs reads comma-separated name=value entries
+func parsePairs(spec, what string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid %s entry %q (expected name=value)", what, entry)
+		}
+		pairs[name] = value
+	}
+	return pairs, nil
+}
@@ -0,0 +1,440 @@
+// Package slo tracks availability and latency of route groups against
+// their service level objectives. It reports how much of each error budget
+// is left and how fast it is burning, and warns when the burn rate is high
+// enough to exhaust the budget early.
+package slo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logging.Logger()
+
+// Service level indicators tracked for every objective
+const (
+	Availability = "availability" // Responses below 500
+	Latency      = "latency"      // Responses within the latency threshold
+)
+
+// Objective is the service level objective of a route group
+type Objective struct {
+	Name               string        `json:"name"`
+	Prefix             string        `json:"prefix"`              // Request paths the objective covers
+	AvailabilityTarget float64       `json:"availability_target"` // Percent of responses below 500
+	LatencyThreshold   time.Duration `json:"-"`
+	LatencyTarget      float64       `json:"latency_target"` // Percent of responses within LatencyThreshold
+}
+
+// Settings tunes budget and alerting
+type Settings struct {
+	Window            time.Duration // Period the error budget covers
+	FastBurnThreshold float64       // Burn rate over 1h (confirmed over 5m) that warns
+	SlowBurnThreshold float64       // Burn rate over 6h (confirmed over 30m) that warns
+}
+
+// burnWindows are the windows burn rates are reported for, shortest first
+var burnWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"30m", 30 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+}
+
+// minuteBuckets covers the longest burn rate window
+const minuteBuckets = 6 * 60
+
+var (
+	sloEvents = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "slo_events_total",
+		Help:      "Requests counted against an objective by indicator and result (good, bad)",
+	}, []string{"objective", "sli", "result"})
+
+	sloBurnRate = metrics.Factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "slo_burn_rate",
+		Help:      "Error budget burn rate per window; 1 spends the budget exactly over the SLO window",
+	}, []string{"objective", "sli", "window"})
+
+	sloBudgetRemaining = metrics.Factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "slo_error_budget_remaining",
+		Help:      "Share of the error budget left over the SLO window; negative once overspent",
+	}, []string{"objective", "sli"})
+)
+
+// bucket counts the requests of one minute or hour
+type bucket struct {
+	slot  int64 // Minute or hour since the epoch
+	total int64
+	bad   [2]int64 // Indexed like indicators
+}
+
+// indicators lists the indicators in bucket order
+var indicators = [2]string{Availability, Latency}
+
+// tracker holds the counts of one objective
+type tracker struct {
+	objective Objective
+	mu        sync.Mutex
+	minutes   [minuteBuckets]bucket
+	hours     []bucket
+	alerts    map[string]string // Indicator to the alert currently raised
+}
+
+// SLIStatus is the state of one indicator of an objective
+type SLIStatus struct {
+	Target          float64            `json:"target"`                 // Percent of good responses
+	Compliance      float64            `json:"compliance"`             // Percent good over the SLO window so far
+	BudgetRemaining float64            `json:"error_budget_remaining"` // Share left; negative once overspent
+	Total           int64              `json:"total"`
+	Bad             int64              `json:"bad"`
+	BurnRates       map[string]float64 `json:"burn_rates"`
+	Alert           string             `json:"alert,omitempty"` // fast_burn or slow_burn
+}
+
+// Status is the state of an objective
+type Status struct {
+	Objective
+	LatencyThresholdMs int64     `json:"latency_threshold_ms"`
+	WindowDays         float64   `json:"window_days"`
+	Availability       SLIStatus `json:"availability"`
+	Latency            SLIStatus `json:"latency"`
+}
+
+var (
+	mu       sync.RWMutex
+	trackers []*tracker
+	settings = Settings{Window: 30 * 24 * time.Hour, FastBurnThreshold: 14.4, SlowBurnThreshold: 6}
+	stop     chan struct{}
+)
+
+// Init starts tracking the objectives and evaluates their burn rates every
+// minute until Stop is called
+func Init(objectives []Objective, s Settings) {
+	if s.Window < time.Hour {
+		s.Window = 30 * 24 * time.Hour
+	}
+
+	list := make([]*tracker, 0, len(objectives))
+	for _, objective := range objectives {
+		list = append(list, &tracker{
+			objective: objective,
+			hours:     make([]bucket, int(s.Window/time.Hour)),
+			alerts:    make(map[string]string),
+		})
+	}
+	// The longest matching prefix wins
+	sort.SliceStable(list, func(i, j int) bool {
+		return len(list[i].objective.Prefix) > len(list[j].objective.Prefix)
+	})
+
+	mu.Lock()
+	trackers, settings = list, s
+	if stop != nil {
+		close(stop)
+	}
+	done := make(chan struct{})
+	stop = done
+	mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				evaluate(time.Now())
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the periodic evaluation
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+	if stop != nil {
+		close(stop)
+		stop = nil
+	}
+}
+
+// ParseObjectives parses a spec like
+// "api=/api/:99.9:500ms:99,public=/public/:99.5:800ms:95", giving each
+// route group its path prefix, availability target, latency threshold and
+// latency target
+func ParseObjectives(spec string) ([]Objective, error) {
+	var objectives []Objective
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(entry, "=")
+		parts := strings.Split(rest, ":")
+		if !ok || name == "" || len(parts) != 4 || !strings.HasPrefix(parts[0], "/") {
+			return nil, fmt.Errorf("objective %q must be name=/prefix:availability:latency:latency_target", entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("objective %q is defined twice", name)
+		}
+		seen[name] = true
+
+		availability, err := parseTarget(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("objective %q: availability %w", name, err)
+		}
+		threshold, err := time.ParseDuration(parts[2])
+		if err != nil || threshold <= 0 {
+			return nil, fmt.Errorf("objective %q: latency threshold %q is not a positive duration", name, parts[2])
+		}
+		latency, err := parseTarget(parts[3])
+		if err != nil {
+			return nil, fmt.Errorf("objective %q: latency target %w", name, err)
+		}
+
+		objectives = append(objectives, Objective{
+			Name:               name,
+			Prefix:             parts[0],
+			AvailabilityTarget: availability,
+			LatencyThreshold:   threshold,
+			LatencyTarget:      latency,
+		})
+	}
+	return objectives, nil
+}
+
+// parseTarget parses a percentage that leaves an error budget
+func parseTarget(value string) (float64, error) {
+	target, err := strconv.ParseFloat(value, 64)
+	if err != nil || target <= 0 || target >= 100 {
+		return 0, fmt.Errorf("%q must be a percentage above 0 and below 100", value)
+	}
+	return target, nil
+}
+
+// Record counts a finished request against the objective covering its
+// path, if any
+func Record(path string, status int, duration time.Duration) {
+	t := trackerFor(path)
+	if t == nil {
+		return
+	}
+	bad := [2]bool{status >= 500, duration > t.objective.LatencyThreshold}
+
+	now := time.Now()
+	t.mu.Lock()
+	t.add(now, bad)
+	t.mu.Unlock()
+
+	for i, sli := range indicators {
+		result := "good"
+		if bad[i] {
+			result = "bad"
+		}
+		sloEvents.WithLabelValues(t.objective.Name, sli, result).Inc()
+	}
+}
+
+// Statuses returns the state of every objective, sorted by name
+func Statuses() []Status {
+	mu.RLock()
+	list := trackers
+	window := settings.Window
+	mu.RUnlock()
+
+	now := time.Now()
+	statuses := make([]Status, 0, len(list))
+	for _, t := range list {
+		statuses = append(statuses, t.status(now, window))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// trackerFor returns the tracker whose prefix matches path
+func trackerFor(path string) *tracker {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, t := range trackers {
+		if strings.HasPrefix(path, t.objective.Prefix) {
+			return t
+		}
+	}
+	return nil
+}
+
+// add counts a request in its minute and hour buckets. Callers must hold
+// t.mu.
+func (t *tracker) add(now time.Time, bad [2]bool) {
+	minute, hour := now.Unix()/60, now.Unix()/3600
+	t.minutes[minute%minuteBuckets].count(minute, bad)
+	t.hours[hour%int64(len(t.hours))].count(hour, bad)
+}
+
+// count adds a request to the bucket, clearing it first if it still holds
+// an older slot
+func (b *bucket) count(slot int64, bad [2]bool) {
+	if b.slot != slot {
+		*b = bucket{slot: slot}
+	}
+	b.total++
+	for i := range bad {
+		if bad[i] {
+			b.bad[i]++
+		}
+	}
+}
+
+// sumMinutes adds up the minute buckets within window before now. Callers
+// must hold t.mu.
+func (t *tracker) sumMinutes(now time.Time, window time.Duration) (total int64, bad [2]int64) {
+	current := now.Unix() / 60
+	oldest := current - int64(window/time.Minute) + 1
+	for _, b := range t.minutes {
+		if b.slot >= oldest && b.slot <= current {
+			total += b.total
+			bad[0] += b.bad[0]
+			bad[1] += b.bad[1]
+		}
+	}
+	return total, bad
+}
+
+// sumHours adds up the hour buckets of the SLO window. Callers must hold
+// t.mu.
+func (t *tracker) sumHours(now time.Time) (total int64, bad [2]int64) {
+	current := now.Unix() / 3600
+	oldest := current - int64(len(t.hours)) + 1
+	for _, b := range t.hours {
+		if b.slot >= oldest && b.slot <= current {
+			total += b.total
+			bad[0] += b.bad[0]
+			bad[1] += b.bad[1]
+		}
+	}
+	return total, bad
+}
+
+// status computes the budget and burn rates of both indicators
+func (t *tracker) status(now time.Time, window time.Duration) Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	targets := [2]float64{t.objective.AvailabilityTarget, t.objective.LatencyTarget}
+	var slis [2]SLIStatus
+	total, bad := t.sumHours(now)
+	for i := range indicators {
+		budget := 1 - targets[i]/100
+		sli := SLIStatus{
+			Target:          targets[i],
+			Compliance:      100,
+			BudgetRemaining: 1,
+			Total:           total,
+			Bad:             bad[i],
+			BurnRates:       make(map[string]float64, len(burnWindows)),
+			Alert:           t.alerts[indicators[i]],
+		}
+		if total > 0 {
+			badRatio := float64(bad[i]) / float64(total)
+			sli.Compliance = round(100 * (1 - badRatio))
+			sli.BudgetRemaining = round(1 - badRatio/budget)
+		}
+		for _, w := range burnWindows {
+			windowTotal, windowBad := t.sumMinutes(now, w.duration)
+			rate := 0.0
+			if windowTotal > 0 {
+				rate = float64(windowBad[i]) / float64(windowTotal) / budget
+			}
+			sli.BurnRates[w.name] = round(rate)
+		}
+		slis[i] = sli
+	}
+
+	return Status{
+		Objective:          t.objective,
+		LatencyThresholdMs: t.objective.LatencyThreshold.Milliseconds(),
+		WindowDays:         round(window.Hours() / 24),
+		Availability:       slis[0],
+		Latency:            slis[1],
+	}
+}
+
+// evaluate updates the gauges and warns about objectives whose budget is
+// burning fast. An alert needs both its long and its short window above
+// the threshold, so it clears soon after the burn stops.
+func evaluate(now time.Time) {
+	mu.RLock()
+	list := trackers
+	s := settings
+	mu.RUnlock()
+
+	for _, t := range list {
+		status := t.status(now, s.Window)
+		for i, sli := range [2]SLIStatus{status.Availability, status.Latency} {
+			name := indicators[i]
+			sloBudgetRemaining.WithLabelValues(t.objective.Name, name).Set(sli.BudgetRemaining)
+			for window, rate := range sli.BurnRates {
+				sloBurnRate.WithLabelValues(t.objective.Name, name, window).Set(rate)
+			}
+
+			alert, threshold, window := "", 0.0, ""
+			switch {
+			case sli.BurnRates["1h"] > s.FastBurnThreshold && sli.BurnRates["5m"] > s.FastBurnThreshold:
+				alert, threshold, window = "fast_burn", s.FastBurnThreshold, "1h"
+			case sli.BurnRates["6h"] > s.SlowBurnThreshold && sli.BurnRates["30m"] > s.SlowBurnThreshold:
+				alert, threshold, window = "slow_burn", s.SlowBurnThreshold, "6h"
+			}
+
+			t.mu.Lock()
+			previous := t.alerts[name]
+			if alert == "" {
+				delete(t.alerts, name)
+			} else {
+				t.alerts[name] = alert
+			}
+			t.mu.Unlock()
+
+			entry := log.WithFields(logrus.Fields{
+				"objective":              t.objective.Name,
+				"sli":                    name,
+				"error_budget_remaining": sli.BudgetRemaining,
+			})
+			switch {
+			case alert != "" && alert != previous:
+				entry.WithFields(logrus.Fields{
+					"alert":     alert,
+					"window":    window,
+					"burn_rate": sli.BurnRates[window],
+					"threshold": threshold,
+				}).Warn("SLO error budget is burning too fast")
+			case alert == "" && previous != "":
+				entry.Info("SLO burn rate back below thresholds")
+			}
+		}
+	}
+}
+
+// round keeps four decimals
+func round(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
@@ -0,0 +1,379 @@
+package stream
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/events"
+	"InternalAPI/internal/metrics"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Reasons a connection was closed by the server
+const (
+	ReasonDropped      = "dropped_events" // Too many events dropped in a row
+	ReasonLag          = "lag"            // Events were delivered too late
+	ReasonDisconnected = "disconnected"   // An admin closed the connection
+)
+
+var (
+	streamConnections = metrics.Factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "stream_connections",
+		Help:      "Open event stream connections",
+	})
+
+	streamQueueDepth = metrics.Factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "stream_queue_depth",
+		Help:      "Events queued across all event stream connections",
+	})
+
+	streamEventsSent = metrics.Factory.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "stream_events_sent_total",
+		Help:      "Events written to event stream connections",
+	})
+
+	streamEventsDropped = metrics.Factory.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "stream_events_dropped_total",
+		Help:      "Events dropped because a connection's queue was full",
+	})
+
+	streamEvictions = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "stream_evictions_total",
+		Help:      "Event stream connections closed by the server, by reason",
+	}, []string{"reason"})
+
+	streamLag = metrics.Factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Name:      "stream_delivery_lag_seconds",
+		Help:      "Time between an event occurring and it being written to a connection",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30},
+	})
+)
+
+// Policy controls per-connection buffering and when slow consumers are evicted
+type Policy struct {
+	QueueSize  int           // Events buffered per connection
+	MaxDropped int           // Consecutive drops before eviction; 0 never evicts
+	MaxLag     time.Duration // Delivery lag before eviction; 0 never evicts
+}
+
+// ConnectionInfo is a snapshot of one live connection
+type ConnectionInfo struct {
+	ID            string    `json:"id"`
+	Transport     string    `json:"transport"`
+	UserID        string    `json:"user_id"`
+	RemoteAddr    string    `json:"remote_addr"`
+	Types         []string  `json:"types"`
+	ConnectedAt   time.Time `json:"connected_at"`
+	QueueDepth    int       `json:"queue_depth"`
+	QueueCapacity int       `json:"queue_capacity"`
+	Sent          int64     `json:"sent"`
+	Dropped       int64     `json:"dropped"`
+	LagMs         int64     `json:"lag_ms"` // Delivery lag of the last event written
+	LastEventAt   time.Time `json:"last_event_at"`
+}
+
+// Transports a connection can use
+const (
+	TransportSSE       = "sse"
+	TransportWebSocket = "websocket"
+	TransportAdminSSE  = "admin-sse" // Receives admin bus events only
+)
+
+// Conn is one subscriber of the event stream
+type Conn struct {
+	ID          string
+	Transport   string
+	UserID      string
+	RemoteAddr  string
+	ConnectedAt time.Time
+
+	queue  chan events.Event
+	done   chan struct{}
+	once   sync.Once
+	reason string
+
+	filter func(events.Event) bool
+
+	mu          sync.Mutex
+	types       []string
+	sent        int64
+	dropped     int64
+	consecutive int
+	lag         time.Duration
+	lastEventAt time.Time
+}
+
+var (
+	policy   = Policy{QueueSize: 64, MaxDropped: 32, MaxLag: 30 * time.Second}
+	conns    = make(map[string]*Conn)
+	connMu   sync.RWMutex
+	initOnce sync.Once
+)
+
+// Init sets the eviction policy and starts fanning out bus events to
+// connected streams: domain events to user streams and admin bus events to
+// admin streams
+func Init(p Policy) {
+	SetPolicy(p)
+	initOnce.Do(func() {
+		events.Subscribe("*", fanout(false))
+		events.SubscribeAdmin("*", fanout(true))
+	})
+}
+
+// SetPolicy replaces the eviction policy. The queue size applies to
+// connections opened afterwards.
+func SetPolicy(p Policy) {
+	if p.QueueSize < 1 {
+		p.QueueSize = 1
+	}
+
+	connMu.Lock()
+	defer connMu.Unlock()
+	policy = p
+}
+
+// CurrentPolicy returns the eviction policy
+func CurrentPolicy() Policy {
+	connMu.RLock()
+	defer connMu.RUnlock()
+	return policy
+}
+
+// Open registers a connection receiving the given event types; no types
+// means every event. A non-nil filter further narrows the events before
+// they are queued.
+func Open(transport, userID, remoteAddr string, types []string, filter func(events.Event) bool) *Conn {
+	connMu.Lock()
+	defer connMu.Unlock()
+
+	conn := &Conn{
+		ID:          uuid.New().String(),
+		Transport:   transport,
+		UserID:      userID,
+		RemoteAddr:  remoteAddr,
+		types:       types,
+		filter:      filter,
+		ConnectedAt: time.Now(),
+		queue:       make(chan events.Event, policy.QueueSize),
+		done:        make(chan struct{}),
+	}
+	conns[conn.ID] = conn
+	streamConnections.Inc()
+	return conn
+}
+
+// Events returns the connection's queue
+func (c *Conn) Events() <-chan events.Event {
+	return c.queue
+}
+
+// Types returns the subscribed event types
+func (c *Conn) Types() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.types
+}
+
+// SetTypes replaces the subscribed event types; events already queued are
+// still delivered
+func (c *Conn) SetTypes(types []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.types = types
+}
+
+// Done is closed once the connection was closed or evicted
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Reason returns why the server closed the connection, if it did
+func (c *Conn) Reason() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reason
+}
+
+// Delivered records that an event taken from the queue was written to the
+// client, and evicts the connection if it is lagging too far behind
+func (c *Conn) Delivered(event events.Event) {
+	streamQueueDepth.Dec()
+	lag := time.Since(event.OccurredAt)
+	streamEventsSent.Inc()
+	streamLag.Observe(lag.Seconds())
+
+	c.mu.Lock()
+	c.sent++
+	c.lag = lag
+	c.lastEventAt = time.Now()
+	c.mu.Unlock()
+
+	if maxLag := CurrentPolicy().MaxLag; maxLag > 0 && lag > maxLag {
+		c.evict(ReasonLag)
+	}
+}
+
+// Close unregisters the connection. Events still queued are discarded.
+func (c *Conn) Close() {
+	c.once.Do(func() {
+		connMu.Lock()
+		delete(conns, c.ID)
+		connMu.Unlock()
+
+		close(c.done)
+		streamConnections.Dec()
+		streamQueueDepth.Sub(float64(len(c.queue)))
+	})
+}
+
+// evict closes the connection on the server's initiative
+func (c *Conn) evict(reason string) {
+	c.mu.Lock()
+	if c.reason != "" {
+		c.mu.Unlock()
+		return
+	}
+	c.reason = reason
+	c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	streamEvictions.WithLabelValues(reason).Inc()
+	c.Close()
+}
+
+// wants reports whether the connection subscribed to an event. Events
+// addressed to a recipient only reach that user's connections.
+func (c *Conn) wants(event events.Event) bool {
+	if recipient, ok := event.Data[events.RecipientField].(string); ok && recipient != c.UserID {
+		return false
+	}
+	if c.filter != nil && !c.filter(event) {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.types) == 0 {
+		return true
+	}
+	for _, t := range c.types {
+		if t == event.Type || (strings.HasSuffix(t, ".*") && strings.HasPrefix(event.Type, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// offer queues an event without blocking the publisher. A full queue drops
+// the event; too many drops in a row evict the connection.
+func (c *Conn) offer(event events.Event, maxDropped int) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	select {
+	case c.queue <- event:
+		streamQueueDepth.Inc()
+		c.mu.Lock()
+		c.consecutive = 0
+		c.mu.Unlock()
+		return
+	default:
+	}
+
+	streamEventsDropped.Inc()
+	c.mu.Lock()
+	c.dropped++
+	c.consecutive++
+	evict := maxDropped > 0 && c.consecutive >= maxDropped
+	c.mu.Unlock()
+
+	if evict {
+		c.evict(ReasonDropped)
+	}
+}
+
+// info returns a snapshot of the connection
+func (c *Conn) info() ConnectionInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ConnectionInfo{
+		ID:            c.ID,
+		Transport:     c.Transport,
+		UserID:        c.UserID,
+		RemoteAddr:    c.RemoteAddr,
+		Types:         c.types,
+		ConnectedAt:   c.ConnectedAt,
+		QueueDepth:    len(c.queue),
+		QueueCapacity: cap(c.queue),
+		Sent:          c.sent,
+		Dropped:       c.dropped,
+		LagMs:         c.lag.Milliseconds(),
+		LastEventAt:   c.lastEventAt,
+	}
+}
+
+// fanout returns a bus handler offering events to every subscribed admin
+// or user connection
+func fanout(admin bool) events.Handler {
+	return func(event events.Event) error {
+		connMu.RLock()
+		maxDropped := policy.MaxDropped
+		targets := make([]*Conn, 0, len(conns))
+		for _, conn := range conns {
+			if (conn.Transport == TransportAdminSSE) == admin && conn.wants(event) {
+				targets = append(targets, conn)
+			}
+		}
+		connMu.RUnlock()
+
+		for _, conn := range targets {
+			conn.offer(event, maxDropped)
+		}
+		return nil
+	}
+}
+
+// List returns all live connections, oldest first
+func List() []ConnectionInfo {
+	connMu.RLock()
+	result := make([]ConnectionInfo, 0, len(conns))
+	for _, conn := range conns {
+		result = append(result, conn.info())
+	}
+	connMu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ConnectedAt.Before(result[j].ConnectedAt)
+	})
+	return result
+}
+
+// Disconnect closes a live connection; it reports false for unknown IDs
+func Disconnect(id string) bool {
+	connMu.RLock()
+	conn, exists := conns[id]
+	connMu.RUnlock()
+	if !exists {
+		return false
+	}
+	conn.evict(ReasonDisconnected)
+	return true
+}
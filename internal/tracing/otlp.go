@@ -0,0 +1,255 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var log = logging.Logger()
+
+// maxBatchSize caps the spans sent to the collector in one request
+const maxBatchSize = 512
+
+var (
+	spansExported = metrics.Factory.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "tracing_spans_exported_total",
+		Help:      "Spans sent to the OTLP collector",
+	})
+
+	spansDropped = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "tracing_spans_dropped_total",
+		Help:      "Spans not exported by reason (buffer_full, send_failed, shutdown)",
+	}, []string{"reason"})
+)
+
+// Config configures span export
+type Config struct {
+	Endpoint      string            // OTLP/HTTP base URL; spans go to Endpoint + /v1/traces
+	Headers       map[string]string // Sent with every export, e.g. an API key
+	ServiceName   string            // Reported as the service.name resource attribute
+	SamplePercent int               // Share of new traces recorded; inbound traces keep their own decision
+	BufferSize    int               // Spans buffered before new ones are dropped
+	Timeout       time.Duration     // Per export request
+}
+
+// exporter sends finished spans to the collector from one background
+// goroutine, so requests never wait for it
+type exporter struct {
+	config Config
+	client *http.Client
+	queue  chan *Span
+	done   chan struct{}
+}
+
+var active *exporter
+
+// Init turns tracing on and starts exporting spans to an OTLP/HTTP
+// collector with JSON encoding
+func Init(config Config) {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 2048
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	exp := &exporter{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		queue:  make(chan *Span, config.BufferSize),
+		done:   make(chan struct{}),
+	}
+	go exp.run()
+
+	tracingMu.Lock()
+	defer tracingMu.Unlock()
+	active = exp
+	enabled = true
+	samplePercent = config.SamplePercent
+}
+
+// Stop turns tracing off and sends the buffered spans until ctx ends
+func Stop(ctx context.Context) {
+	tracingMu.Lock()
+	exp := active
+	active = nil
+	enabled = false
+	if exp != nil {
+		close(exp.queue)
+	}
+	tracingMu.Unlock()
+	if exp == nil {
+		return
+	}
+
+	select {
+	case <-exp.done:
+	case <-ctx.Done():
+		spansDropped.WithLabelValues("shutdown").Add(float64(len(exp.queue)))
+	}
+}
+
+// ParseHeaders parses a spec like "x-api-key=secret,x-tenant=hotels"
+func ParseHeaders(spec string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("header %q is missing =value", entry)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// enqueue buffers a finished span without blocking
+func enqueue(span *Span) {
+	tracingMu.RLock()
+	defer tracingMu.RUnlock()
+	if active == nil {
+		spansDropped.WithLabelValues("shutdown").Inc()
+		return
+	}
+	select {
+	case active.queue <- span:
+	default:
+		spansDropped.WithLabelValues("buffer_full").Inc()
+	}
+}
+
+// run sends buffered spans until the queue is closed. Whatever has queued
+// up while a batch was being sent goes out as the next batch.
+func (e *exporter) run() {
+	defer close(e.done)
+	for span := range e.queue {
+		batch := []*Span{span}
+	drain:
+		for len(batch) < maxBatchSize {
+			select {
+			case next, open := <-e.queue:
+				if !open {
+					break drain
+				}
+				batch = append(batch, next)
+			default:
+				break drain
+			}
+		}
+		if err := e.send(batch); err != nil {
+			spansDropped.WithLabelValues("send_failed").Add(float64(len(batch)))
+			log.WithError(err).WithField("spans", len(batch)).Warn("Failed to export spans")
+			continue
+		}
+		spansExported.Add(float64(len(batch)))
+	}
+}
+
+// send posts one batch to the collector
+func (e *exporter) send(batch []*Span) error {
+	body, err := json.Marshal(e.encode(batch))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(e.config.Endpoint, "/")+"/v1/traces", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range e.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// encode builds an OTLP ExportTraceServiceRequest in its JSON mapping
+func (e *exporter) encode(batch []*Span) map[string]interface{} {
+	spans := make([]map[string]interface{}, 0, len(batch))
+	for _, span := range batch {
+		span.mu.Lock()
+		encoded := map[string]interface{}{
+			"traceId":           hex.EncodeToString(span.traceID[:]),
+			"spanId":            hex.EncodeToString(span.spanID[:]),
+			"name":              span.name,
+			"kind":              int(span.kind),
+			"startTimeUnixNano": strconv.FormatInt(span.start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(span.end.UnixNano(), 10),
+			"attributes":        encodeAttributes(span.attributes),
+		}
+		if span.parentID != [8]byte{} {
+			encoded["parentSpanId"] = hex.EncodeToString(span.parentID[:])
+		}
+		if span.failed {
+			encoded["status"] = map[string]interface{}{"code": 2, "message": span.message}
+		}
+		span.mu.Unlock()
+		spans = append(spans, encoded)
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": encodeAttributes(map[string]interface{}{"service.name": e.config.ServiceName}),
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]interface{}{"name": "InternalAPI"},
+				"spans": spans,
+			}},
+		}},
+	}
+}
+
+// encodeAttributes converts attributes to OTLP key-value pairs, sorted by
+// key
+func encodeAttributes(attributes map[string]interface{}) []map[string]interface{} {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	encoded := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		var value map[string]interface{}
+		switch v := attributes[key].(type) {
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		case int:
+			value = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case int64:
+			value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = map[string]interface{}{"doubleValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		encoded = append(encoded, map[string]interface{}{"key": key, "value": value})
+	}
+	return encoded
+}
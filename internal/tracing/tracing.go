@@ -0,0 +1,181 @@
+// Package tracing records OpenTelemetry spans for inbound requests and
+// backend calls, propagates W3C trace context to the backends and exports
+// the spans to an OTLP collector.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanKind says which side of a call a span describes, numbered as in OTLP
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+// Span is one timed operation of a trace. The methods of a nil Span do
+// nothing, so callers need not check whether tracing is enabled.
+type Span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte // Zero for a root span
+	sampled  bool
+	remote   bool // Parent taken from a traceparent header, not recorded here
+
+	name  string
+	kind  SpanKind
+	start time.Time
+
+	mu         sync.Mutex
+	end        time.Time
+	attributes map[string]interface{}
+	failed     bool
+	message    string
+}
+
+type spanKey struct{}
+
+var (
+	tracingMu     sync.RWMutex
+	enabled       bool
+	samplePercent = 100
+)
+
+// Start begins a span as a child of the span in ctx, or as the root of a
+// new trace, and returns a context carrying it. It returns a nil span when
+// tracing is disabled. End must be called on the span.
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	tracingMu.RLock()
+	on, percent := enabled, samplePercent
+	tracingMu.RUnlock()
+	if !on {
+		return ctx, nil
+	}
+
+	span := &Span{name: name, kind: kind, start: time.Now(), attributes: map[string]interface{}{}}
+	if parent := FromContext(ctx); parent != nil {
+		span.traceID, span.parentID, span.sampled = parent.traceID, parent.spanID, parent.sampled
+	} else {
+		fillRandom(span.traceID[:])
+		span.sampled = rand.IntN(100) < percent
+	}
+	fillRandom(span.spanID[:])
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// FromContext returns the current span of a context, or nil
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Extract returns a context whose spans continue the trace of an inbound
+// traceparent header. Missing or malformed headers start a new trace.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	parts := strings.Split(strings.TrimSpace(header.Get("traceparent")), "-")
+	if len(parts) != 4 || parts[0] == "ff" || len(parts[0]) != 2 || len(parts[3]) != 2 {
+		return ctx
+	}
+	span := &Span{remote: true}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || !decodeID(span.traceID[:], parts[1]) || !decodeID(span.spanID[:], parts[2]) {
+		return ctx
+	}
+	span.sampled = flags[0]&1 == 1
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// Inject adds the traceparent header of the span in ctx to an outbound
+// request, so the backend's spans join the trace
+func Inject(ctx context.Context, header http.Header) {
+	span := FromContext(ctx)
+	if span == nil {
+		return
+	}
+	flags := "00"
+	if span.sampled {
+		flags = "01"
+	}
+	header.Set("traceparent", fmt.Sprintf("00-%x-%x-%s", span.traceID, span.spanID, flags))
+}
+
+// TraceID returns the hex trace ID of the span, or "" for a nil span
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.traceID[:])
+}
+
+// SetAttribute records a string, bool, int, int64 or float64 attribute
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil || s.remote {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes[key] = value
+}
+
+// SetError marks the span as failed with a message
+func (s *Span) SetError(message string) {
+	if s == nil || s.remote {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = true
+	s.message = message
+}
+
+// End finishes the span and queues it for export if its trace is sampled.
+// Later calls do nothing.
+func (s *Span) End() {
+	if s == nil || s.remote {
+		return
+	}
+	s.mu.Lock()
+	if !s.end.IsZero() {
+		s.mu.Unlock()
+		return
+	}
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	if s.sampled {
+		enqueue(s)
+	}
+}
+
+// decodeID decodes a non-zero hex trace or span ID into id
+func decodeID(id []byte, value string) bool {
+	if len(value) != 2*len(id) {
+		return false
+	}
+	if _, err := hex.Decode(id, []byte(value)); err != nil {
+		return false
+	}
+	for _, b := range id {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// fillRandom fills an ID with random bytes
+func fillRandom(id []byte) {
+	for i := range id {
+		id[i] = byte(rand.UintN(256))
+	}
+}
@@ -0,0 +1,413 @@
+package usage
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/logging"
+)
+
+var log = logging.Logger()
+
+// Report periods
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// Consumer types
+const (
+	ConsumerUser   = "user"
+	ConsumerAPIKey = "api_key"
+)
+
+// dayFormat keys daily aggregates
+const dayFormat = "2006-01-02"
+
+// topEndpointCount is how many endpoints a report lists
+const topEndpointCount = 5
+
+// event is a single request waiting to be aggregated
+type event struct {
+	consumer     string
+	consumerType string
+	endpoint     string
+	status       int
+	at           time.Time
+}
+
+// dayStats aggregates one consumer's requests for one day
+type dayStats struct {
+	requests     int64
+	clientErrors int64
+	serverErrors int64
+	endpoints    map[string]int64
+}
+
+// consumerStats holds a consumer's daily aggregates
+type consumerStats struct {
+	consumerType string
+	days         map[string]*dayStats
+}
+
+// EndpointCount is a request count for a single endpoint
+type EndpointCount struct {
+	Endpoint string `json:"endpoint"`
+	Requests int64  `json:"requests"`
+}
+
+// QuotaUsage reports consumption of a monthly request quota
+type QuotaUsage struct {
+	Limit     int64   `json:"limit"`
+	Used      int64   `json:"used"`
+	Remaining int64   `json:"remaining"`
+	Percent   float64 `json:"percent"`
+	Month     string  `json:"month"` // Calendar month the quota applies to, e.g. 2026-10
+}
+
+// Report summarizes one consumer's usage for a period
+type Report struct {
+	Consumer     string          `json:"consumer"`
+	ConsumerType string          `json:"consumer_type"`
+	Period       string          `json:"period"`
+	Start        string          `json:"start"`
+	End          string          `json:"end"`
+	Requests     int64           `json:"requests"`
+	ClientErrors int64           `json:"client_errors"`
+	ServerErrors int64           `json:"server_errors"`
+	ErrorRate    float64         `json:"error_rate"` // Share of requests answered with 4xx or 5xx
+	TopEndpoints []EndpointCount `json:"top_endpoints"`
+	Quota        *QuotaUsage     `json:"quota,omitempty"`
+}
+
+// Tracker buffers request events and aggregates them per consumer and day
+type Tracker struct {
+	pending      []event
+	pendingMu    sync.Mutex
+	consumers    map[string]*consumerStats
+	aggregatedAt time.Time
+	quotas       map[string]int64
+	defaultQuota int64
+	retention    time.Duration
+	mu           sync.RWMutex
+}
+
+// NewTracker creates a tracker. defaultQuota applies to consumers without an
+// entry in quotas; 0 means no quota.
+func NewTracker(defaultQuota int64, quotas map[string]int64, retention time.Duration) *Tracker {
+	if quotas == nil {
+		quotas = map[string]int64{}
+	}
+	return &Tracker{
+		consumers:    make(map[string]*consumerStats),
+		quotas:       quotas,
+		defaultQuota: defaultQuota,
+		retention:    retention,
+	}
+}
+
+// Record buffers a request for the next aggregation run
+func (t *Tracker) Record(consumer, consumerType, endpoint string, status int) {
+	t.pendingMu.Lock()
+	t.pending = append(t.pending, event{
+		consumer:     consumer,
+		consumerType: consumerType,
+		endpoint:     endpoint,
+		status:       status,
+		at:           time.Now().UTC(),
+	})
+	t.pendingMu.Unlock()
+}
+
+// Aggregate folds buffered events into the daily aggregates and drops days
+// older than the retention period
+func (t *Tracker) Aggregate() int {
+	t.pendingMu.Lock()
+	events := t.pending
+	t.pending = nil
+	t.pendingMu.Unlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, e := range events {
+		stats, ok := t.consumers[e.consumer]
+		if !ok {
+			stats = &consumerStats{consumerType: e.consumerType, days: make(map[string]*dayStats)}
+			t.consumers[e.consumer] = stats
+		}
+
+		key := e.at.Format(dayFormat)
+		day, ok := stats.days[key]
+		if !ok {
+			day = &dayStats{endpoints: make(map[string]int64)}
+			stats.days[key] = day
+		}
+
+		day.requests++
+		day.endpoints[e.endpoint]++
+		switch {
+		case e.status >= 500:
+			day.serverErrors++
+		case e.status >= 400:
+			day.clientErrors++
+		}
+	}
+
+	if t.retention > 0 {
+		cutoff := time.Now().UTC().Add(-t.retention).Format(dayFormat)
+		for consumer, stats := range t.consumers {
+			for key := range stats.days {
+				if key < cutoff {
+					delete(stats.days, key)
+				}
+			}
+			if len(stats.days) == 0 {
+				delete(t.consumers, consumer)
+			}
+		}
+	}
+
+	t.aggregatedAt = time.Now()
+	return len(events)
+}
+
+// AggregatedAt returns when the aggregates were last updated
+func (t *Tracker) AggregatedAt() time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.aggregatedAt
+}
+
+// Reports builds a report per consumer for the week or month containing
+// date, busiest consumer first. An empty consumer includes everyone.
+func (t *Tracker) Reports(period string, date time.Time, consumer string) ([]Report, error) {
+	start, end, err := periodBounds(period, date)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	reports := []Report{}
+	for id, stats := range t.consumers {
+		if consumer != "" && id != consumer {
+			continue
+		}
+		report := t.report(id, stats, period, start, end)
+		if report.Requests == 0 {
+			continue
+		}
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Requests > reports[j].Requests
+	})
+	return reports, nil
+}
+
+// Report builds the report for a single consumer, including empty periods
+func (t *Tracker) Report(period string, date time.Time, consumer, consumerType string) (Report, error) {
+	start, end, err := periodBounds(period, date)
+	if err != nil {
+		return Report{}, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	stats, ok := t.consumers[consumer]
+	if !ok {
+		stats = &consumerStats{consumerType: consumerType}
+	}
+	return t.report(consumer, stats, period, start, end), nil
+}
+
+// report sums a consumer's days within [start, end). Callers must hold t.mu.
+func (t *Tracker) report(consumer string, stats *consumerStats, period string, start, end time.Time) Report {
+	report := Report{
+		Consumer:     consumer,
+		ConsumerType: stats.consumerType,
+		Period:       period,
+		Start:        start.Format(dayFormat),
+		End:          end.AddDate(0, 0, -1).Format(dayFormat),
+	}
+
+	endpoints := make(map[string]int64)
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		d, ok := stats.days[day.Format(dayFormat)]
+		if !ok {
+			continue
+		}
+		report.Requests += d.requests
+		report.ClientErrors += d.clientErrors
+		report.ServerErrors += d.serverErrors
+		for endpoint, count := range d.endpoints {
+			endpoints[endpoint] += count
+		}
+	}
+
+	if report.Requests > 0 {
+		report.ErrorRate = roundRate(float64(report.ClientErrors+report.ServerErrors) / float64(report.Requests))
+	}
+	report.TopEndpoints = topEndpoints(endpoints, topEndpointCount)
+	report.Quota = t.quotaUsage(consumer, stats, end.AddDate(0, 0, -1))
+	return report
+}
+
+// quotaUsage reports the monthly quota for the month containing date. Callers must hold t.mu.
+func (t *Tracker) quotaUsage(consumer string, stats *consumerStats, date time.Time) *QuotaUsage {
+	limit, ok := t.quotas[consumer]
+	if !ok {
+		limit = t.defaultQuota
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	monthStart, monthEnd, _ := periodBounds(PeriodMonth, date)
+	usage := &QuotaUsage{Limit: limit, Month: monthStart.Format("2006-01")}
+	for day := monthStart; day.Before(monthEnd); day = day.AddDate(0, 0, 1) {
+		if d, ok := stats.days[day.Format(dayFormat)]; ok {
+			usage.Used += d.requests
+		}
+	}
+
+	usage.Remaining = limit - usage.Used
+	if usage.Remaining < 0 {
+		usage.Remaining = 0
+	}
+	usage.Percent = math.Round(float64(usage.Used)/float64(limit)*10000) / 100
+	return usage
+}
+
+// periodBounds returns the UTC start (inclusive) and end (exclusive) of the
+// ISO week or calendar month containing date
+func periodBounds(period string, date time.Time) (time.Time, time.Time, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case PeriodWeek:
+		offset := (int(day.Weekday()) + 6) % 7 // Monday starts the week
+		start := day.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 7), nil
+	case PeriodMonth:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown period %q, expected week or month", period)
+	}
+}
+
+// topEndpoints returns the n busiest endpoints
+func topEndpoints(endpoints map[string]int64, n int) []EndpointCount {
+	list := make([]EndpointCount, 0, len(endpoints))
+	for endpoint, count := range endpoints {
+		list = append(list, EndpointCount{Endpoint: endpoint, Requests: count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Requests != list[j].Requests {
+			return list[i].Requests > list[j].Requests
+		}
+		return list[i].Endpoint < list[j].Endpoint
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// roundRate rounds a ratio to four decimals
+func roundRate(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
+
+// ParseQuotas parses a spec like "service:billing=100000,user-42=5000"
+func ParseQuotas(spec string) (map[string]int64, error) {
+	quotas := make(map[string]int64)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		consumer, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid quota entry %q, expected consumer=limit", entry)
+		}
+		limit, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quota limit for %s: %v", consumer, err)
+		}
+		quotas[strings.TrimSpace(consumer)] = limit
+	}
+	return quotas, nil
+}
+
+// Global usage tracker
+var tracker *Tracker
+
+// Init enables usage tracking and starts the background aggregation job
+func Init(defaultQuota int64, quotas map[string]int64, retention, interval time.Duration) {
+	tracker = NewTracker(defaultQuota, quotas, retention)
+
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if n := tracker.Aggregate(); n > 0 {
+				log.WithField("events", n).Debug("Usage events aggregated")
+			}
+		}
+	}()
+}
+
+// Enabled reports whether usage tracking is active
+func Enabled() bool {
+	return tracker != nil
+}
+
+// Record buffers a request using the global tracker
+func Record(consumer, consumerType, endpoint string, status int) {
+	if tracker != nil {
+		tracker.Record(consumer, consumerType, endpoint, status)
+	}
+}
+
+// Reports builds reports using the global tracker
+func Reports(period string, date time.Time, consumer string) ([]Report, error) {
+	if tracker == nil {
+		return []Report{}, nil
+	}
+	return tracker.Reports(period, date, consumer)
+}
+
+// ConsumerReport builds a single consumer's report using the global tracker
+func ConsumerReport(period string, date time.Time, consumer, consumerType string) (Report, error) {
+	if tracker == nil {
+		return NewTracker(0, nil, 0).Report(period, date, consumer, consumerType)
+	}
+	return tracker.Report(period, date, consumer, consumerType)
+}
+
+// LastAggregated returns the Unix time of the global tracker's last
+// aggregation run, or 0 before the first one
+func LastAggregated() int64 {
+	if tracker == nil {
+		return 0
+	}
+	if at := tracker.AggregatedAt(); !at.IsZero() {
+		return at.Unix()
+	}
+	return 0
+}
@@ -0,0 +1,356 @@
+// Package webhooks notifies admin-registered endpoints of gateway events.
+// Deliveries are persisted before they are sent, signed with the
+// subscription's secret and retried with exponential backoff; those that
+// exhaust their attempts are kept as dead letters for admins to inspect
+// and retry.
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/events"
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Headers sent with every delivery
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+	WebhookHeader   = "X-Webhook-ID"
+)
+
+const (
+	pollInterval = 15 * time.Second
+	concurrency  = 8
+	maxBackoff   = time.Hour
+)
+
+var log = logging.Logger()
+
+var deliveryResults = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "webhook_deliveries_total",
+	Help:      "Outbound webhook delivery attempts by result (delivered, retry, dead)",
+}, []string{"result"})
+
+// publicTypes renames internal events to the names webhook subscribers use
+var publicTypes = map[string]string{
+	"reservation.created":     "booking.created",
+	"reservation.updated":     "booking.updated",
+	"reservation.cancelled":   "booking.cancelled",
+	"reservation.checked_out": "booking.checked_out",
+}
+
+// breakerTypes names circuit breaker transitions by their target state
+var breakerTypes = map[string]string{
+	"open":      "circuitbreaker.opened",
+	"half-open": "circuitbreaker.half_opened",
+	"closed":    "circuitbreaker.closed",
+}
+
+// PublicType returns the event type subscribers see for a bus event, or
+// false for events that are never sent, such as audit entries
+func PublicType(event events.Event) (string, bool) {
+	switch event.Type {
+	case "audit.recorded":
+		return "", false
+	case "circuit_breaker.state_changed":
+		to, _ := event.Data["to"].(string)
+		eventType, known := breakerTypes[to]
+		return eventType, known
+	}
+	if eventType, renamed := publicTypes[event.Type]; renamed {
+		return eventType, true
+	}
+	return event.Type, true
+}
+
+// Sign computes the signature header value: HMAC-SHA256 over
+// "<timestamp>.<body>", hex encoded with a "sha256=" prefix. This is the
+// scheme the gateway itself verifies for upstream callbacks.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher delivers queued webhook events in the background
+type Dispatcher struct {
+	store       *Store
+	client      *http.Client
+	maxAttempts int
+	retention   time.Duration
+	wake        chan struct{}
+}
+
+// NewDispatcher creates a dispatcher that gives up on a delivery after
+// maxAttempts and forgets delivered events after retention
+func NewDispatcher(store *Store, timeout time.Duration, maxAttempts int, retention time.Duration) *Dispatcher {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &Dispatcher{
+		store:       store,
+		client:      &http.Client{Timeout: timeout},
+		maxAttempts: maxAttempts,
+		retention:   retention,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Notify queues a bus event for every matching subscription
+func (d *Dispatcher) Notify(event events.Event) error {
+	eventType, send := PublicType(event)
+	if !send {
+		return nil
+	}
+	event.Type = eventType
+
+	queued, err := d.store.Enqueue(event)
+	if queued > 0 {
+		d.Wake()
+	}
+	return err
+}
+
+// Wake makes the dispatcher look for due deliveries now
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start delivers queued events in the background, including any left
+// pending by a previous run. It sleeps until the next retry is due, a new
+// delivery is queued or the poll interval passes.
+func (d *Dispatcher) Start() {
+	go func() {
+		for {
+			d.deliverDue()
+			if d.retention > 0 {
+				d.store.Prune(time.Now().Add(-d.retention))
+			}
+
+			wait := pollInterval
+			if next, pending := d.store.NextDue(); pending && time.Until(next) < wait {
+				wait = time.Until(next)
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-d.wake:
+			case <-timer.C:
+			}
+			timer.Stop()
+		}
+	}()
+}
+
+// deliverDue attempts every pending delivery whose next attempt has
+// arrived, a few at a time so one slow endpoint does not hold up the rest
+func (d *Dispatcher) deliverDue() {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+
+	for _, delivery := range d.store.Due(time.Now()) {
+		sub, exists := d.store.Get(delivery.SubscriptionID)
+		if !exists {
+			delivery.Status = StatusDead
+			delivery.LastError = "webhook no longer exists"
+			d.store.UpdateDelivery(delivery)
+			continue
+		}
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(delivery Delivery, sub *Subscription) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			d.attempt(delivery, sub)
+		}(delivery, sub)
+	}
+	wg.Wait()
+}
+
+// attempt sends one delivery and records the outcome
+func (d *Dispatcher) attempt(delivery Delivery, sub *Subscription) {
+	status, err := d.send(delivery, sub)
+
+	delivery.Attempts++
+	delivery.LastStatus = status
+	result := "delivered"
+	if err == nil {
+		now := time.Now()
+		delivery.Status = StatusDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= d.maxAttempts {
+			delivery.Status = StatusDead
+			result = "dead"
+		} else {
+			delivery.NextAttempt = time.Now().Add(backoff(delivery.Attempts))
+			result = "retry"
+		}
+	}
+	deliveryResults.WithLabelValues(result).Inc()
+
+	if updateErr := d.store.UpdateDelivery(delivery); updateErr != nil {
+		log.WithError(updateErr).WithField("delivery_id", delivery.ID).Error("Failed to persist webhook delivery status")
+	}
+
+	entry := log.WithFields(logrus.Fields{
+		"delivery_id": delivery.ID,
+		"webhook_id":  delivery.SubscriptionID,
+		"event_type":  delivery.Event.Type,
+		"status":      delivery.Status,
+		"attempts":    delivery.Attempts,
+		"http_status": status,
+		"error":       delivery.LastError,
+	})
+	if delivery.Status == StatusDead {
+		entry.Warn("Webhook delivery moved to dead letters")
+	} else {
+		entry.Info("Webhook delivery attempted")
+	}
+}
+
+// backoff returns the wait before the next attempt: 2s, 4s, 8s, ...
+// capped at an hour
+func backoff(attempts int) time.Duration {
+	if attempts >= 12 {
+		return maxBackoff
+	}
+	wait := time.Duration(1<<attempts) * time.Second
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+	return wait
+}
+
+// send POSTs the signed event to the subscriber. Any 2xx response counts
+// as delivered.
+func (d *Dispatcher) send(delivery Delivery, sub *Subscription) (int, error) {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "InternalAPI-Webhooks/1.0")
+	req.Header.Set(WebhookHeader, sub.ID)
+	req.Header.Set(EventHeader, delivery.Event.Type)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+var dispatcher *Dispatcher
+
+// Init opens the webhook store, subscribes to the event buses and starts
+// the global dispatcher
+func Init(dir string, timeout time.Duration, maxAttempts int, retention time.Duration) error {
+	store, err := NewStore(dir)
+	if err != nil {
+		return err
+	}
+
+	dispatcher = NewDispatcher(store, timeout, maxAttempts, retention)
+	events.Subscribe("*", dispatcher.Notify)
+	events.SubscribeAdmin("*", dispatcher.Notify)
+	dispatcher.Start()
+	return nil
+}
+
+// Enabled reports whether the global dispatcher has been initialized
+func Enabled() bool {
+	return dispatcher != nil
+}
+
+// Create registers a subscription with the global dispatcher
+func Create(sub Subscription) (*Subscription, error) {
+	return dispatcher.store.Create(sub)
+}
+
+// Update changes a subscription of the global dispatcher
+func Update(id string, sub Subscription) (*Subscription, error) {
+	return dispatcher.store.Update(id, sub)
+}
+
+// Delete removes a subscription from the global dispatcher
+func Delete(id string) error {
+	return dispatcher.store.Delete(id)
+}
+
+// Get returns a subscription of the global dispatcher
+func Get(id string) (*Subscription, bool) {
+	return dispatcher.store.Get(id)
+}
+
+// List returns the subscriptions of the global dispatcher
+func List() []Subscription {
+	return dispatcher.store.List()
+}
+
+// DeadLetters returns the global dispatcher's dead letters
+func DeadLetters(subscriptionID string) []Delivery {
+	return dispatcher.store.DeadLetters(subscriptionID)
+}
+
+// Retry requeues a dead letter and wakes the global dispatcher
+func Retry(id string) (*Delivery, error) {
+	delivery, err := dispatcher.store.Requeue(id)
+	if err != nil {
+		return nil, err
+	}
+	dispatcher.Wake()
+	return delivery, nil
+}
+
+// Discard deletes a dead letter from the global dispatcher
+func Discard(id string) error {
+	return dispatcher.store.DeleteDelivery(id)
+}
+
+// Stats returns delivery counts from the global dispatcher
+func Stats() map[string]int {
+	if dispatcher == nil {
+		return map[string]int{}
+	}
+	return dispatcher.store.Counts()
+}
@@ -0,0 +1,505 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"InternalAPI/internal/events"
+
+	"github.com/google/uuid"
+)
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+)
+
+var (
+	// ErrWebhookNotFound is returned for an unknown subscription ID
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrDeadLetterNotFound is returned for an unknown or non-dead delivery ID
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	// ErrInvalidWebhook is returned when a subscription's URL or events are invalid
+	ErrInvalidWebhook = errors.New("invalid webhook")
+)
+
+// Subscription is an admin-registered endpoint that receives matching events
+type Subscription struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Secret      string    `json:"secret,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	CreatedBy   string    `json:"created_by"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Matches reports whether the subscription wants an event type. Patterns
+// are exact types, "prefix.*" for a family or "*" for every event.
+func (s *Subscription) Matches(eventType string) bool {
+	for _, pattern := range s.Events {
+		if pattern == "*" || pattern == eventType {
+			return true
+		}
+		if strings.HasSuffix(pattern, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is one event queued for one subscription
+type Delivery struct {
+	ID             string       `json:"id"`
+	SubscriptionID string       `json:"subscription_id"`
+	URL            string       `json:"url"`
+	Event          events.Event `json:"event"`
+	Status         string       `json:"status"`
+	Attempts       int          `json:"attempts"`
+	LastStatus     int          `json:"last_status,omitempty"`
+	LastError      string       `json:"last_error,omitempty"`
+	NextAttempt    time.Time    `json:"next_attempt"`
+	CreatedAt      time.Time    `json:"created_at"`
+	DeliveredAt    *time.Time   `json:"delivered_at,omitempty"`
+}
+
+// Store keeps subscriptions and deliveries as one JSON file each, so queued
+// deliveries survive a restart
+type Store struct {
+	dir           string
+	subscriptions map[string]*Subscription
+	deliveries    map[string]*Delivery
+	mu            sync.Mutex
+}
+
+// NewStore opens (or creates) a webhook directory and loads its records
+func NewStore(dir string) (*Store, error) {
+	s := &Store{
+		dir:           dir,
+		subscriptions: make(map[string]*Subscription),
+		deliveries:    make(map[string]*Delivery),
+	}
+
+	for _, kind := range []string{"subscriptions", "deliveries"} {
+		if err := os.MkdirAll(filepath.Join(dir, kind), 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create webhook directory: %w", err)
+		}
+	}
+
+	if err := load(filepath.Join(dir, "subscriptions"), func(data []byte) error {
+		var sub Subscription
+		if err := json.Unmarshal(data, &sub); err != nil {
+			return err
+		}
+		s.subscriptions[sub.ID] = &sub
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := load(filepath.Join(dir, "deliveries"), func(data []byte) error {
+		var d Delivery
+		if err := json.Unmarshal(data, &d); err != nil {
+			return err
+		}
+		s.deliveries[d.ID] = &d
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// load parses every JSON file in a directory
+func load(dir string, parse func([]byte) error) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read webhook record %s: %w", file, err)
+		}
+		if err := parse(data); err != nil {
+			return fmt.Errorf("failed to parse webhook record %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// validate checks a subscription's URL and event patterns
+func validate(sub *Subscription) error {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if len(sub.Events) == 0 {
+		return fmt.Errorf("%w: at least one event type is required", ErrInvalidWebhook)
+	}
+	for _, pattern := range sub.Events {
+		if strings.TrimSpace(pattern) == "" || strings.Contains(strings.TrimSuffix(pattern, "*"), "*") {
+			return fmt.Errorf("%w: invalid event pattern %q", ErrInvalidWebhook, pattern)
+		}
+	}
+	return nil
+}
+
+// Create registers a subscription. A signing secret is generated when none
+// is given.
+func (s *Store) Create(sub Subscription) (*Subscription, error) {
+	if err := validate(&sub); err != nil {
+		return nil, err
+	}
+	if sub.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		sub.Secret = secret
+	}
+
+	sub.ID = uuid.New().String()
+	sub.CreatedAt = time.Now()
+	sub.UpdatedAt = sub.CreatedAt
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.write("subscriptions", sub.ID, &sub); err != nil {
+		return nil, err
+	}
+	s.subscriptions[sub.ID] = &sub
+
+	copied := sub
+	return &copied, nil
+}
+
+// Update replaces a subscription's URL, events, description and active
+// flag. The secret is only replaced when a new one is given.
+func (s *Store) Update(id string, sub Subscription) (*Subscription, error) {
+	if err := validate(&sub); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.subscriptions[id]
+	if !exists {
+		return nil, ErrWebhookNotFound
+	}
+
+	updated := *existing
+	updated.URL = sub.URL
+	updated.Events = sub.Events
+	updated.Description = sub.Description
+	updated.Active = sub.Active
+	if sub.Secret != "" {
+		updated.Secret = sub.Secret
+	}
+	updated.UpdatedAt = time.Now()
+
+	if err := s.write("subscriptions", id, &updated); err != nil {
+		return nil, err
+	}
+	s.subscriptions[id] = &updated
+
+	copied := updated
+	return &copied, nil
+}
+
+// Delete removes a subscription together with its pending deliveries
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.subscriptions[id]; !exists {
+		return ErrWebhookNotFound
+	}
+	if err := os.Remove(s.path("subscriptions", id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	delete(s.subscriptions, id)
+
+	for deliveryID, d := range s.deliveries {
+		if d.SubscriptionID == id && d.Status == StatusPending {
+			os.Remove(s.path("deliveries", deliveryID))
+			delete(s.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+// Get returns a subscription by ID
+func (s *Store) Get(id string) (*Subscription, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, exists := s.subscriptions[id]
+	if !exists {
+		return nil, false
+	}
+	copied := *sub
+	return &copied, true
+}
+
+// List returns all subscriptions, oldest first
+func (s *Store) List() []Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Subscription, 0, len(s.subscriptions))
+	for _, sub := range s.subscriptions {
+		result = append(result, *sub)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// Enqueue queues an event for every active subscription that wants it and
+// returns the number of deliveries created
+func (s *Store) Enqueue(event events.Event) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	queued := 0
+	var firstErr error
+	for _, sub := range s.subscriptions {
+		if !sub.Active || !sub.Matches(event.Type) {
+			continue
+		}
+		d := &Delivery{
+			ID:             uuid.New().String(),
+			SubscriptionID: sub.ID,
+			URL:            sub.URL,
+			Event:          event,
+			Status:         StatusPending,
+			NextAttempt:    now,
+			CreatedAt:      now,
+		}
+		if err := s.write("deliveries", d.ID, d); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		s.deliveries[d.ID] = d
+		queued++
+	}
+	return queued, firstErr
+}
+
+// Due returns copies of pending deliveries whose next attempt has arrived
+func (s *Store) Due(now time.Time) []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []Delivery
+	for _, d := range s.deliveries {
+		if d.Status == StatusPending && !d.NextAttempt.After(now) {
+			due = append(due, *d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].CreatedAt.Before(due[j].CreatedAt)
+	})
+	return due
+}
+
+// NextDue returns the earliest next attempt of any pending delivery
+func (s *Store) NextDue() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	found := false
+	for _, d := range s.deliveries {
+		if d.Status == StatusPending && (!found || d.NextAttempt.Before(next)) {
+			next, found = d.NextAttempt, true
+		}
+	}
+	return next, found
+}
+
+// UpdateDelivery persists a changed delivery. Deliveries whose subscription
+// was deleted meanwhile are dropped.
+func (s *Store) UpdateDelivery(d Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.deliveries[d.ID]; !exists {
+		return nil
+	}
+	if err := s.write("deliveries", d.ID, &d); err != nil {
+		return err
+	}
+	s.deliveries[d.ID] = &d
+	return nil
+}
+
+// Delivery returns a delivery by ID
+func (s *Store) Delivery(id string) (*Delivery, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, exists := s.deliveries[id]
+	if !exists {
+		return nil, false
+	}
+	copied := *d
+	return &copied, true
+}
+
+// DeadLetters returns deliveries that exhausted their attempts, newest
+// first, optionally for one subscription
+func (s *Store) DeadLetters(subscriptionID string) []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []Delivery{}
+	for _, d := range s.deliveries {
+		if d.Status == StatusDead && (subscriptionID == "" || d.SubscriptionID == subscriptionID) {
+			result = append(result, *d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+// Requeue resets a dead delivery so it is attempted again
+func (s *Store) Requeue(id string) (*Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, exists := s.deliveries[id]
+	if !exists || d.Status != StatusDead {
+		return nil, ErrDeadLetterNotFound
+	}
+	sub, exists := s.subscriptions[d.SubscriptionID]
+	if !exists {
+		return nil, ErrWebhookNotFound
+	}
+
+	requeued := *d
+	requeued.URL = sub.URL
+	requeued.Status = StatusPending
+	requeued.Attempts = 0
+	requeued.NextAttempt = time.Now()
+	if err := s.write("deliveries", id, &requeued); err != nil {
+		return nil, err
+	}
+	s.deliveries[id] = &requeued
+
+	copied := requeued
+	return &copied, nil
+}
+
+// DeleteDelivery discards a dead delivery
+func (s *Store) DeleteDelivery(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, exists := s.deliveries[id]
+	if !exists || d.Status != StatusDead {
+		return ErrDeadLetterNotFound
+	}
+	if err := os.Remove(s.path("deliveries", id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	delete(s.deliveries, id)
+	return nil
+}
+
+// Counts returns the number of deliveries per status
+func (s *Store) Counts() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := map[string]int{StatusPending: 0, StatusDelivered: 0, StatusDead: 0}
+	for _, d := range s.deliveries {
+		counts[d.Status]++
+	}
+	return counts
+}
+
+// Prune removes delivered records created before cutoff. Dead letters are
+// kept until an admin retries or discards them.
+func (s *Store) Prune(cutoff time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for id, d := range s.deliveries {
+		if d.Status == StatusDelivered && d.CreatedAt.Before(cutoff) {
+			if err := os.Remove(s.path("deliveries", id)); err != nil && !os.IsNotExist(err) {
+				continue
+			}
+			delete(s.deliveries, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// write stores a record atomically so a crash never leaves a torn file
+func (s *Store) write(kind, id string, record interface{}) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Join(s.dir, kind), ".record-*")
+	if err != nil {
+		return fmt.Errorf("failed to store webhook record: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to store webhook record: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to store webhook record: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to store webhook record: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), s.path(kind, id))
+}
+
+// path maps a record ID to its file; IDs are generated UUIDs
+func (s *Store) path(kind, id string) string {
+	return filepath.Join(s.dir, kind, filepath.Base(id)+".json")
+}
+
+// generateSecret returns a random signing secret
+func generateSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(raw), nil
+}
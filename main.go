@@ -3,18 +3,50 @@ package main
 import (
 	"InternalAPI/internal/broker"
 	"context"
+	"flag"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
-	"InternalAPI/internal/circuitbreaker"
+	"InternalAPI/internal/actions"
+	"InternalAPI/internal/audit"
+	"InternalAPI/internal/auth"
+	"InternalAPI/internal/auth/lockout"
+	"InternalAPI/internal/buildinfo"
+	"InternalAPI/internal/callbacks"
 	"InternalAPI/internal/config"
+	"InternalAPI/internal/encryption"
+	"InternalAPI/internal/events"
+	"InternalAPI/internal/grpcserver"
+	"InternalAPI/internal/health"
+	"InternalAPI/internal/housekeeping"
+	"InternalAPI/internal/jobs"
+	"InternalAPI/internal/labels"
+	"InternalAPI/internal/logging"
+	"InternalAPI/internal/logfile"
+	"InternalAPI/internal/messaging"
 	"InternalAPI/internal/middleware"
+	"InternalAPI/internal/models"
+	"InternalAPI/internal/openapi"
+	"InternalAPI/internal/permissions"
+	"InternalAPI/internal/pms"
+	"InternalAPI/internal/reload"
+	"InternalAPI/internal/resilience"
+	"InternalAPI/internal/roles"
 	"InternalAPI/internal/routes"
-	"github.com/gin-contrib/cors"
+	"InternalAPI/internal/rules"
+	"InternalAPI/internal/server"
+	"InternalAPI/internal/slo"
+	"InternalAPI/internal/services"
+	"InternalAPI/internal/stream"
+	"InternalAPI/internal/tracing"
+	"InternalAPI/internal/usage"
+	"InternalAPI/internal/webhooks"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
@@ -28,28 +60,572 @@ func init() {
 }
 
 func main() {
-	// Load configuration
+	hardened := flag.Bool("hardened", false, "refuse to start unless production security requirements are met")
+	flag.Parse()
+
+	// Load configuration; the environment wins over CONFIG_FILE, which wins
+	// over the defaults
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := config.ApplyFile(path); err != nil {
+			log.Fatalf("Failed to read CONFIG_FILE: %v", err)
+		}
+	}
 	cfg := config.Load()
 
-	// Validate JWT secret
-	if cfg.JWTSecret == "your-jwt-secret-key" {
-		log.Warn("⚠️  WARNING: Using default JWT secret! Set JWT_SECRET environment variable in production!")
+	// Secrets from a secrets backend win over the environment and CONFIG_FILE
+	secrets, err := config.NewSecretProvider(cfg)
+	if err != nil {
+		log.Fatalf("Invalid secrets configuration: %v", err)
+	}
+	if secrets != nil {
+		if _, err := config.LoadSecrets(context.Background(), secrets); err != nil {
+			log.Fatalf("Failed to load secrets: %v", err)
+		}
+		cfg = config.Load()
+		log.WithField("provider", secrets.Name()).Info("Secrets loaded from the secrets backend")
+	}
+
+	// Backend addresses come from the broker when discovery is on, so
+	// backends can move without a config change; until the broker knows a
+	// backend its configured URL is used
+	if cfg.ServiceDiscovery == "broker" {
+		slugs, err := services.ParseDiscoveryServices(cfg.DiscoveryServices)
+		if err != nil {
+			log.Fatalf("Invalid DISCOVERY_SERVICES: %v", err)
+		}
+		services.InitDiscovery(services.DiscoverySettings{
+			Services:       slugs,
+			Refresh:        cfg.DiscoveryRefresh,
+			FailureRefresh: cfg.DiscoveryFailureRefresh,
+			RequireHTTPS:   cfg.MTLSEnabled,
+		})
+	}
+
+	// Settings kept in Central Management merge over the local ones. Without
+	// Central Management the gateway starts with its local settings and
+	// keeps trying every CONFIG_SYNC_INTERVAL_SECONDS.
+	var remoteConfig *services.ConfigSource
+	if cfg.ConfigSyncEnabled {
+		remoteConfig, err = services.NewConfigSource(cfg)
+		if err != nil {
+			log.Fatalf("Invalid config sync settings: %v", err)
+		}
+		if _, err := config.LoadRemote(context.Background(), remoteConfig, cfg.ConfigSyncPrecedence); err != nil {
+			log.WithError(err).Warn("Failed to sync settings from Central Management, starting with local settings")
+		} else {
+			cfg = config.Load()
+			synced := config.LastRemoteSync()
+			log.WithFields(logrus.Fields{
+				"settings":   synced.Settings,
+				"ignored":    synced.Ignored,
+				"precedence": cfg.ConfigSyncPrecedence,
+			}).Info("Settings synced from Central Management")
+		}
+	}
+	if *hardened {
+		cfg.Hardened = true
+	}
+
+	// Invalid configuration fails fast, listing every problem at once
+	if problems := cfg.Validate(); len(problems) > 0 {
+		for _, problem := range problems {
+			log.WithField("problem", problem).Error("Invalid configuration")
+		}
+		log.WithField("problems", problems).Fatalf("Refusing to start: %d configuration problem(s)", len(problems))
+	}
+
+	// Hardened mode fails fast, listing every unmet requirement at once
+	if cfg.Hardened {
+		if violations := cfg.HardeningViolations(); len(violations) > 0 {
+			for _, violation := range violations {
+				log.WithField("requirement", violation).Error("Hardened mode requirement not met")
+			}
+			log.WithField("violations", violations).Fatalf("Refusing to start in hardened mode: %d requirement(s) not met", len(violations))
+		}
+		cfg.Environment = "production"
+		log.Info("Hardened mode enabled")
+	}
+
+	// Production mode refuses development secrets, missing service keys and
+	// contradictory timeouts; elsewhere they are only logged
+	if problems := cfg.ProductionProblems(); len(problems) > 0 {
+		if cfg.ProductionMode() {
+			for _, problem := range problems {
+				log.WithField("problem", problem).Error("Configuration not fit for production")
+			}
+			log.WithField("problems", problems).Fatalf("Refusing to start in production mode: %d problem(s)", len(problems))
+		}
+		for _, problem := range problems {
+			log.WithField("problem", problem).Warn("⚠️  Configuration not fit for production")
+		}
+	}
+
+	if err := logging.SetLevel(cfg.LogLevel); err != nil {
+		log.Fatalf("Invalid LOG_LEVEL: %v", err)
+	}
+
+	// Optionally mirror logs to a file that the rotate-log action can rotate
+	var logFile *logfile.File
+	if cfg.LogFile != "" {
+		lf, err := logfile.Open(cfg.LogFile)
+		if err != nil {
+			log.Fatalf("Failed to open log file: %v", err)
+		}
+		logFile = lf
+		logging.SetOutput(io.MultiWriter(os.Stderr, logFile))
+		middleware.SetAuditOutput(io.MultiWriter(os.Stderr, logFile))
 	}
 
 	// Initialize JWT middleware with secret
-	middleware.InitJWT(cfg.JWTSecret)
+	middleware.InitJWT(cfg.JWTSecret, splitSetting(cfg.JWTPreviousSecrets)...)
+
+	// Sign pagination cursors so clients cannot forge offsets
+	cursorSecret := cfg.CursorSecret
+	if cursorSecret == "" {
+		cursorSecret = cfg.JWTSecret
+	}
+	models.InitCursors(cursorSecret)
+
+	// Sign OIDC login state with its own secret, or a key derived from the JWT keys
+	auth.SetOIDCStateSecret(cfg.OIDCStateSecret)
+
+	// Load service-to-service API keys
+	if err := middleware.InitAPIKeys(cfg.InternalAPIKeys); err != nil {
+		log.Fatalf("Failed to load internal API keys: %v", err)
+	}
+
+	// Initialize local access/refresh token issuance. Refresh token families
+	// are kept in the same backend as the token blacklist.
+	refreshStore, err := auth.NewRefreshTokenStore(cfg.BlacklistBackend, cfg.RedisURL, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to initialize refresh token store: %v", err)
+	}
+	auth.Init(refreshStore, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	auth.InitExtensions(auth.ExtensionPolicy{
+		Roles:         strings.Split(cfg.TokenExtensionRoles, ","),
+		MaxExtensions: cfg.TokenMaxExtensions,
+		RenewBefore:   cfg.TokenRenewBefore,
+	})
+
+	// httpOnly cookie auth for the User Portal, protected by CSRF tokens
+	if cfg.EnableCookieAuth {
+		middleware.InitCookieAuth(middleware.CookieConfig{
+			Domain:     cfg.CookieDomain,
+			Secure:     cfg.CookieSecure,
+			SameSite:   middleware.ParseSameSite(cfg.CookieSameSite),
+			RefreshTTL: cfg.RefreshTokenTTL,
+		})
+		log.WithField("samesite", cfg.CookieSameSite).Info("Cookie-based auth enabled")
+	}
+
+	// Initialize token blacklist store
+	blacklistStore, err := middleware.NewBlacklistStore(cfg.BlacklistBackend, cfg.RedisURL, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to initialize token blacklist: %v", err)
+	}
+	middleware.InitBlacklist(blacklistStore, middleware.BlacklistConfig{
+		CleanupInterval:  cfg.BlacklistCleanupInterval,
+		MaxEntries:       cfg.BlacklistMaxEntries,
+		CleanupThreshold: cfg.BlacklistCleanupThreshold,
+	})
+	log.WithField("backend", cfg.BlacklistBackend).Info("Token blacklist initialized")
+
+	// Initialize login reputation scoring
+	if cfg.ReputationEnabled {
+		reputationStore, err := middleware.NewReputationStore(cfg.ReputationBackend, cfg.RedisURL)
+		if err != nil {
+			log.Fatalf("Failed to initialize login reputation store: %v", err)
+		}
+		middleware.InitReputation(reputationStore, middleware.ReputationConfig{
+			ChallengeScore:   cfg.ReputationChallengeScore,
+			BlockScore:       cfg.ReputationBlockScore,
+			FailurePenalty:   cfg.ReputationFailurePenalty,
+			SuccessReward:    cfg.ReputationSuccessReward,
+			RecoveryInterval: cfg.ReputationRecoveryInterval,
+			ChallengeURL:     cfg.ReputationChallengeURL,
+		})
+		log.WithField("backend", cfg.ReputationBackend).Info("Login reputation scoring enabled")
+	}
+
+	// Bot mitigation for the anonymous availability widget
+	if cfg.PublicAvailabilityEnabled {
+		middleware.InitBotChecks(strings.Split(cfg.PublicBlockedUserAgents, ","), cfg.PublicChallengeURL)
+		log.Info("Public availability search enabled")
+	}
+
+	// Initialize per-account lockout after repeated failed logins
+	if cfg.LockoutEnabled {
+		lockout.Init(lockout.Config{
+			MaxFailures:  cfg.LockoutMaxFailures,
+			Window:       cfg.LockoutWindow,
+			LockDuration: cfg.LockoutDuration,
+		})
+		log.WithFields(logrus.Fields{
+			"max_failures": cfg.LockoutMaxFailures,
+			"duration":     cfg.LockoutDuration,
+		}).Info("Account lockout enabled")
+	}
+
+	// Per-consumer usage reports, aggregated in the background
+	if cfg.UsageTrackingEnabled {
+		quotas, err := usage.ParseQuotas(cfg.UsageQuotas)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid USAGE_QUOTAS")
+		}
+		usage.Init(cfg.UsageMonthlyQuota, quotas, cfg.UsageRetention, cfg.UsageAggregationInterval)
+		log.WithField("aggregation_interval", cfg.UsageAggregationInterval).Info("Usage reporting enabled")
+	}
+
+	// Initialize field-level encryption for sensitive upstream fields
+	if cfg.FieldEncryptionEnabled {
+		keys, err := encryption.NewStaticKeyProvider(cfg.FieldEncryptionKeys, cfg.FieldEncryptionActiveKey)
+		if err != nil {
+			log.Fatalf("Failed to initialize field encryption keys: %v", err)
+		}
+		encryption.Init(keys, encryption.ParseSchema(cfg.FieldEncryptionSchema))
+		log.WithField("active_key", cfg.FieldEncryptionActiveKey).Info("Field-level encryption enabled")
+	}
+
+	// Connection pools for backend calls
+	services.InitTransports(services.TransportSettings{
+		MaxConnsPerHost:     cfg.UpstreamMaxConnsPerHost,
+		MaxIdleConnsPerHost: cfg.UpstreamMaxIdleConnsPerHost,
+		IdleConnTimeout:     cfg.UpstreamIdleConnTimeout,
+		TLSHandshakeTimeout: cfg.UpstreamTLSHandshakeTimeout,
+		KeepAlive:           cfg.UpstreamKeepAlive,
+		HTTP2:               cfg.UpstreamHTTP2,
+	})
+	serviceTimeouts, err := resilience.ParseServiceTimeouts(cfg.UpstreamServiceTimeouts)
+	if err != nil {
+		log.Fatalf("Invalid UPSTREAM_SERVICE_TIMEOUTS: %v", err)
+	}
+	resilience.InitTimeouts(cfg.UpstreamTimeout, serviceTimeouts)
+	serviceConcurrency, err := resilience.ParseServiceLimits(cfg.UpstreamServiceConcurrency)
+	if err != nil {
+		log.Fatalf("Invalid UPSTREAM_SERVICE_CONCURRENCY: %v", err)
+	}
+	resilience.InitBulkheads(cfg.UpstreamMaxConcurrency, serviceConcurrency)
+	if cfg.BeheerderHedgeEnabled {
+		services.InitHedging(map[string]time.Duration{"api-beheerder": cfg.BeheerderHedgeDelay})
+	}
+
+	// Backends added by configuration, next to API Beheerder and Central
+	// Management
+	configuredUpstreams, err := services.InitUpstreams(cfg.UpstreamServices, cfg.UpstreamServiceKeys, cfg.UpstreamServiceSPIFFEIDs)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid UPSTREAM_SERVICES configuration")
+	}
+
+	// Mutual TLS for backend calls
+	if err := services.InitMTLS(cfg); err != nil {
+		log.WithError(err).Fatal("Failed to configure mTLS")
+	}
+	if cfg.MTLSEnabled {
+		log.WithFields(logrus.Fields{
+			"ca_file":          cfg.MTLSCAFile,
+			"beheerder_spiffe": cfg.APIBeheerderSPIFFEID,
+			"central_spiffe":   cfg.CentralMgmtSPIFFEID,
+		}).Info("mTLS enabled for backend services")
+	}
 
 	// Initialize circuit breakers for external services
-	circuitbreaker.Init("api-beheerder", cfg.CircuitBreakerFailureThreshold, cfg.CircuitBreakerTimeout, cfg.CircuitBreakerMaxRetries, cfg.CircuitBreakerRetryDelay)
-	circuitbreaker.Init("central-mgmt", cfg.CircuitBreakerFailureThreshold, cfg.CircuitBreakerTimeout, cfg.CircuitBreakerMaxRetries, cfg.CircuitBreakerRetryDelay)
+	breakerSettings, err := newBreakerSettings(cfg)
+	if err != nil {
+		log.Fatalf("Invalid circuit breaker configuration: %v", err)
+	}
+	resilience.OnStateChange(func(change resilience.StateChange) {
+		entry := log.WithFields(logrus.Fields{
+			"service":  change.Service,
+			"from":     change.From.String(),
+			"to":       change.To.String(),
+			"failures": change.Failures,
+		})
+		if change.To == resilience.StateOpen {
+			entry.Warn("Circuit breaker opened")
+		} else {
+			entry.Info("Circuit breaker state changed")
+		}
+	})
+	resilience.InitBreaker("api-beheerder", breakerSettings("api-beheerder"))
+	resilience.InitBreaker("central-mgmt", breakerSettings("central-mgmt"))
+	for _, name := range configuredUpstreams {
+		resilience.InitBreaker(name, breakerSettings(name))
+		log.WithField("upstream", name).Info("Upstream service enabled")
+	}
+
+	// Third-party PMS backends get their own breakers next to API Beheerder
+	if err := pms.Init(services.New(cfg), cfg.PMSAdapters, cfg.PMSAdapterKeys, cfg.PMSTenants); err != nil {
+		log.WithError(err).Fatal("Invalid PMS adapter configuration")
+	}
+	for _, name := range pms.Breakers() {
+		resilience.InitBreaker(name, breakerSettings(name))
+		log.WithField("upstream", name).Info("PMS adapter enabled")
+	}
+
+	// Policies every backend call goes through, per service
+	serviceChains, err := resilience.ParseServiceChains(cfg.ResilienceServiceChains)
+	if err != nil {
+		log.Fatalf("Invalid RESILIENCE_SERVICE_CHAINS: %v", err)
+	}
+	if err := resilience.InitChains(resilience.ParseChain(cfg.ResilienceChain), serviceChains); err != nil {
+		log.Fatalf("Invalid resilience chain: %v", err)
+	}
+
+	// Dependencies are probed in the background; health checks serve the
+	// cached results
+	if cfg.HealthProbeEnabled {
+		probePaths, err := health.ParsePaths(cfg.HealthProbePaths)
+		if err != nil {
+			log.Fatalf("Invalid HEALTH_PROBE_PATHS: %v", err)
+		}
+		es := services.New(cfg)
+		for _, upstream := range es.Upstreams() {
+			path := probePaths[upstream.Name]
+			if path == "" {
+				path = "/health"
+			}
+			health.Register(upstream.Name, func(ctx context.Context) error {
+				return es.Probe(ctx, upstream, path)
+			})
+		}
+		health.Start(health.Settings{
+			Interval:         cfg.HealthProbeInterval,
+			Timeout:          cfg.HealthProbeTimeout,
+			FailureThreshold: cfg.HealthProbeFailureThreshold,
+		})
+	}
+
+	// Reads served from fallbacks while a breaker is open
+	if cfg.CircuitBreakerFallback {
+		if err := services.InitFallbacks(cfg.CircuitBreakerFallbackTTL, cfg.CircuitBreakerFallbackFile); err != nil {
+			log.WithError(err).Fatal("Invalid CB_FALLBACK_PAYLOADS_FILE")
+		}
+	}
 
 	log.WithFields(logrus.Fields{
 		"failure_threshold": cfg.CircuitBreakerFailureThreshold,
 		"timeout":          cfg.CircuitBreakerTimeout,
 		"max_retries":      cfg.CircuitBreakerMaxRetries,
 		"retry_delay":      cfg.CircuitBreakerRetryDelay,
+		"policy":           cfg.CircuitBreakerPolicy,
 	}).Info("Circuit breakers initialized")
 
+	// Guest messaging retention and content filtering
+	messaging.Init(cfg.MessageRetention, strings.Split(cfg.MessageBlockedWords, ","))
+	messaging.OnMessage(func(msg messaging.Message) {
+		log.WithFields(logrus.Fields{
+			"message_id":     msg.ID,
+			"reservation_id": msg.ReservationID,
+			"direction":      msg.Direction,
+		}).Info("Guest message relayed")
+	})
+
+	// Cleaning tasks are queued for every checkout
+	housekeeping.Init(cfg.HousekeepingTaskRetention)
+
+	// Bus events are pushed to /api/v1/events/stream subscribers
+	stream.Init(stream.Policy{
+		QueueSize:  cfg.StreamQueueSize,
+		MaxDropped: cfg.StreamMaxDropped,
+		MaxLag:     cfg.StreamMaxLag,
+	})
+
+	// Upstream callbacks are buffered durably and translated onto the event bus
+	if cfg.BeheerderWebhookSecret != "" {
+		if err := callbacks.Init(cfg.BeheerderWebhookSecret, cfg.WebhookSignatureMaxAge, cfg.WebhookDedupRetention, cfg.WebhookInboxDir); err != nil {
+			log.WithError(err).Fatal("Failed to open callback inbox")
+		}
+		log.WithField("inbox", cfg.WebhookInboxDir).Info("API Beheerder callbacks enabled")
+	}
+	// Admin-registered webhooks are notified of bus events
+	if cfg.OutboundWebhooksEnabled {
+		if err := webhooks.Init(cfg.OutboundWebhookDir, cfg.OutboundWebhookTimeout, cfg.OutboundWebhookMaxAttempts, cfg.OutboundWebhookRetention); err != nil {
+			log.WithError(err).Fatal("Failed to open webhook store")
+		}
+		log.WithField("dir", cfg.OutboundWebhookDir).Info("Outbound webhooks enabled")
+	}
+	// Album, booking and room reads are served from a cache; writes through
+	// the gateway and upstream change callbacks invalidate it
+	if cfg.ResponseCacheEnabled {
+		routeTTLs, err := middleware.ParseRouteTTLs(cfg.ResponseCacheRouteTTLs)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid RESPONSE_CACHE_ROUTE_TTLS")
+		}
+		cacheStore, err := middleware.NewResponseCacheStore(cfg.ResponseCacheBackend, cfg.RedisURL)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to open response cache")
+		}
+		middleware.InitResponseCache(cacheStore, cfg.ResponseCacheTTL, routeTTLs)
+		events.Subscribe("*", func(event events.Event) error {
+			switch {
+			case strings.HasPrefix(event.Type, "reservation."):
+				middleware.InvalidateResponseCache("bookings")
+			case strings.HasPrefix(event.Type, "room."):
+				middleware.InvalidateResponseCache("rooms")
+			}
+			return nil
+		})
+		log.WithFields(logrus.Fields{
+			"backend": cfg.ResponseCacheBackend,
+			"ttl":     cfg.ResponseCacheTTL,
+		}).Info("Response caching enabled")
+	}
+	// Long-running upstream requests can run as background jobs
+	if cfg.JobsEnabled {
+		jobStore, err := jobs.NewStore(cfg.JobsStore, cfg.JobsDir, cfg.RedisURL, cfg.JobsRetention)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to open job store")
+		}
+		jobs.Init(jobStore, cfg.JobsWorkers, cfg.JobsQueueSize, cfg.JobsTimeout, cfg.JobsRetention)
+		log.WithFields(logrus.Fields{
+			"store":   cfg.JobsStore,
+			"workers": cfg.JobsWorkers,
+		}).Info("Background jobs enabled")
+	}
+	// Domain events and audit entries are forwarded to a message broker
+	// for downstream analytics
+	if cfg.EventBrokerDriver != "" {
+		driver, err := events.NewDriver(cfg.EventBrokerDriver, strings.Split(cfg.EventBrokerURLs, ","), cfg.EventBrokerTimeout)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid event broker configuration")
+		}
+		events.StartPublisher(driver, events.PublisherConfig{
+			Topic:      cfg.EventBrokerTopic,
+			AuditTopic: cfg.EventBrokerAuditTopic,
+			BufferSize: cfg.EventBrokerBufferSize,
+			Redact:     middleware.MaskData,
+		})
+		log.WithFields(logrus.Fields{
+			"driver": driver.Name(),
+			"topic":  cfg.EventBrokerTopic,
+		}).Info("Event broker publishing enabled")
+	}
+	// Audit entries are also shipped to Central Management, next to the
+	// local audit log and store
+	if cfg.AuditShipEnabled {
+		if cfg.AuditShipOverflow != audit.OverflowDropOldest && cfg.AuditShipOverflow != audit.OverflowDropNewest {
+			log.Fatalf("Invalid AUDIT_SHIP_OVERFLOW %q: use drop_oldest or drop_newest", cfg.AuditShipOverflow)
+		}
+		es := services.New(cfg)
+		audit.StartShipper(audit.ShipperConfig{
+			BatchSize:     cfg.AuditShipBatchSize,
+			FlushInterval: cfg.AuditShipFlushInterval,
+			BufferSize:    cfg.AuditShipBufferSize,
+			MaxAttempts:   cfg.AuditShipMaxAttempts,
+			Overflow:      cfg.AuditShipOverflow,
+			SpillFile:     cfg.AuditShipSpillFile,
+			Send: func(ctx context.Context, batch audit.Batch) error {
+				return es.Deliver(ctx, "central-mgmt", cfg.AuditShipEndpoint, batch)
+			},
+		})
+		log.WithFields(logrus.Fields{
+			"endpoint":   cfg.AuditShipEndpoint,
+			"batch_size": cfg.AuditShipBatchSize,
+			"overflow":   cfg.AuditShipOverflow,
+		}).Info("Audit shipping to Central Management enabled")
+	}
+	// Anchors of the audit hash chain are kept by Central Management, so a
+	// rewritten chain no longer matches them
+	if cfg.AuditAnchorInterval > 0 {
+		es := services.New(cfg)
+		audit.StartAnchoring(cfg.AuditAnchorInterval, func(ctx context.Context, anchor audit.Anchor) error {
+			return es.Deliver(ctx, "central-mgmt", cfg.AuditAnchorEndpoint, anchor)
+		})
+		log.WithField("interval", cfg.AuditAnchorInterval).Info("Audit chain anchoring enabled")
+	}
+	// Spans for requests and backend calls, exported to an OTLP collector
+	if cfg.TracingEnabled {
+		headers, err := tracing.ParseHeaders(cfg.TracingHeaders)
+		if err != nil {
+			log.Fatalf("Invalid OTEL_EXPORTER_OTLP_HEADERS: %v", err)
+		}
+		tracing.Init(tracing.Config{
+			Endpoint:      cfg.TracingEndpoint,
+			Headers:       headers,
+			ServiceName:   cfg.TracingServiceName,
+			SamplePercent: cfg.TracingSamplePercent,
+		})
+		log.WithFields(logrus.Fields{
+			"endpoint":       cfg.TracingEndpoint,
+			"sample_percent": cfg.TracingSamplePercent,
+		}).Info("OpenTelemetry tracing enabled")
+	}
+	events.Subscribe("*", func(event events.Event) error {
+		log.WithFields(logrus.Fields{
+			"event_id":   event.ID,
+			"event_type": event.Type,
+			"source":     event.Source,
+		}).Info("Event published")
+		return nil
+	})
+
+	// Localized labels for enum values in responses
+	if err := labels.Init(cfg.LabelsDir, strings.Split(cfg.SupportedLocales, ",")); err != nil {
+		log.WithError(err).Fatal("Failed to load label catalog")
+	}
+
+	// Role hierarchy for RequireRoles, optionally kept in sync with Central Management
+	hierarchy, err := roles.Parse(cfg.RoleHierarchy)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid ROLE_HIERARCHY")
+	}
+	roles.Set(hierarchy)
+	if cfg.RoleHierarchySource == "central" {
+		roles.StartRefresh(services.New(cfg), cfg.RoleRefreshInterval)
+	}
+
+	// Permission checks against Central Management
+	permissions.Init(services.New(cfg), cfg.PermissionCacheTTL)
+
+	// Business rule validation of album writes
+	if cfg.BusinessRulesEnabled {
+		rules.Init(services.New(cfg))
+	}
+
+	// Forward-compatible proxy for allow-listed API Beheerder endpoints
+	if err := services.InitProxyRules(cfg.BeheerderProxyRules, strings.Split(cfg.BeheerderProxyStripFields, ",")); err != nil {
+		log.WithError(err).Fatal("Invalid BEHEERDER_PROXY_RULES")
+	}
+	if err := services.InitStreamRules(cfg.BeheerderStreamRules); err != nil {
+		log.WithError(err).Fatal("Invalid BEHEERDER_STREAM_RULES")
+	}
+
+	// Other backends can be passed through as they are until they get
+	// handlers of their own
+	if err := services.New(cfg).InitPassthrough(splitSetting(cfg.PassthroughServices), splitSetting(cfg.PassthroughStripHeaders)); err != nil {
+		log.WithError(err).Fatal("Invalid PASSTHROUGH_SERVICES")
+	}
+
+	// Backend API changes, such as renamed paths and fields, are adapted to
+	// by rules instead of handler changes
+	if err := services.New(cfg).InitTransforms(cfg.TransformRulesFile); err != nil {
+		log.WithError(err).Fatal("Invalid TRANSFORM_RULES_FILE")
+	}
+	if rules := services.TransformRules(); len(rules) > 0 {
+		log.WithField("rules", len(rules)).Info("Backend call transformations enabled")
+	}
+
+	// Preload reference data in the background; readiness reports progress
+	datasets := services.ParseReferenceDatasets(cfg.ReferenceDatasets)
+	go func() {
+		warm := services.New(cfg).WarmReferenceData(datasets, cfg.WarmTimeout)
+		log.WithField("datasets", warm.Datasets).Info("Reference data warming complete")
+	}()
+
+	// Runbook actions exposed under /admin/actions
+	actions.RegisterBuiltins(cfg, logFile)
+
+	// TLS termination for the gateway itself
+	serverTLS, err := server.NewTLS(cfg)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to configure TLS")
+	}
+	var hstsMaxAge time.Duration
+	if serverTLS.Enabled() {
+		hstsMaxAge = cfg.HSTSMaxAge
+		log.WithField("mode", cfg.TLSMode).Info("TLS termination enabled")
+	}
+
 	// Set Gin mode
 	gin.SetMode(gin.ReleaseMode)
 
@@ -57,43 +633,134 @@ func main() {
 	router := gin.New()
 	router.Use(gin.Logger(), gin.Recovery())
 
+	// Add per-request middleware tracing (never in production)
+	if cfg.MiddlewareTraceEnabled && cfg.Environment != "production" {
+		router.Use(middleware.RequestTrace(true))
+		log.Info("Middleware tracing enabled for requests with " + middleware.TraceHeader)
+	}
+
 	// Add security middleware
 	if cfg.EnableSecurityHeaders {
-		router.Use(middleware.SecurityHeaders())
+		router.Use(middleware.SecurityHeaders(hstsMaxAge))
 		log.Info("Security headers enabled")
 	}
 
 	// Add request ID tracking
 	router.Use(middleware.RequestID())
 
+	// Server spans; backend calls made with the request context join them
+	if cfg.TracingEnabled {
+		router.Use(middleware.Tracing())
+	}
+
+	// Availability and latency of route groups against their objectives
+	if cfg.SLOObjectives != "" {
+		objectives, err := slo.ParseObjectives(cfg.SLOObjectives)
+		if err != nil {
+			log.Fatalf("Invalid SLO_OBJECTIVES: %v", err)
+		}
+		slo.Init(objectives, slo.Settings{
+			Window:            cfg.SLOWindow,
+			FastBurnThreshold: cfg.SLOFastBurnThreshold,
+			SlowBurnThreshold: cfg.SLOSlowBurnThreshold,
+		})
+		router.Use(middleware.SLOTracking())
+		log.WithField("objectives", len(objectives)).Info("SLO tracking enabled")
+	}
+
+	// Deprecated routes announce their removal in response headers
+	router.Use(middleware.DeprecationHeaders())
+
+	// Responses built from breaker fallbacks are marked as degraded
+	if cfg.CircuitBreakerFallback {
+		router.Use(middleware.DegradedResponses())
+	}
+
+	// Compress responses; registered ahead of the middleware that reads or
+	// rewrites response bodies so they work on the uncompressed body
+	if cfg.CompressionEnabled {
+		if err := middleware.InitCompression(cfg.CompressionMinSize, cfg.CompressionLevel, strings.Split(cfg.CompressionTypes, ","), strings.Split(cfg.CompressionDisabledPaths, ",")); err != nil {
+			log.Fatalf("Failed to configure response compression: %v", err)
+		}
+		router.Use(middleware.Compression())
+		log.WithField("min_size", cfg.CompressionMinSize).Info("Response compression enabled")
+	}
+
 	// Add audit logging
 	if cfg.EnableAuditLogging {
+		recentAudit := audit.NewMemoryStore(cfg.AuditStoreCapacity)
+		auditStore, err := audit.NewStore(cfg.AuditStoreBackend, recentAudit, cfg.RedisURL, cfg.DatabaseURL, cfg.AuditStoreRetention)
+		if err != nil {
+			log.Fatalf("Failed to initialize audit store: %v", err)
+		}
+		audit.Init(recentAudit, auditStore, cfg.AuditStoreRetention)
+		middleware.InitPIIMasking(strings.Split(cfg.AuditPIIFields, ","))
+		if err := middleware.InitRedaction(strings.Split(cfg.AuditRedactFields, ","), cfg.AuditRedactCardNumbers); err != nil {
+			log.Fatalf("Invalid AUDIT_REDACT_FIELDS: %v", err)
+		}
+		if err := middleware.InitAuditCapture(cfg.AuditCapturePolicy, cfg.AuditCaptureRoutes); err != nil {
+			log.Fatalf("Failed to load audit capture policies: %v", err)
+		}
 		router.Use(middleware.AuditLogger())
 		log.Info("Audit logging enabled")
 	}
 
+	// Mask personal data in responses outside production
+	if cfg.DataMaskingMode == "on" || (cfg.DataMaskingMode == "auto" && cfg.Environment != "production") {
+		if err := middleware.InitDataMasking(cfg.DataMaskingFields, cfg.DataMaskingSalt); err != nil {
+			log.Fatalf("Failed to configure data masking: %v", err)
+		}
+		router.Use(middleware.DataMasking())
+		log.WithField("environment", cfg.Environment).Warn("Response data masking enabled")
+	}
+
 	// Add request size limit
 	router.Use(middleware.RequestSizeLimit(cfg.MaxRequestBodySize))
 	log.WithField("max_size_mb", cfg.MaxRequestBodySize/(1024*1024)).Info("Request size limit configured")
 
+	// Validate bodies of documented routes against the embedded OpenAPI document
+	apiSpec, err := openapi.Embedded()
+	if err != nil {
+		log.Fatalf("Failed to load OpenAPI document: %v", err)
+	}
+	if err := middleware.InitSchemaValidation(apiSpec, cfg.SchemaValidation, cfg.SchemaValidationRoutes); err != nil {
+		log.Fatalf("Failed to configure schema validation: %v", err)
+	}
+	router.Use(middleware.SchemaValidation())
+	log.WithField("mode", cfg.SchemaValidation).Info("OpenAPI schema validation configured")
+
 	// Add CORS middleware for User Portal access
-	corsConfig := cors.DefaultConfig()
-	corsConfig.AllowOrigins = []string{
-		"http://localhost:3000",
-		"http://localhost:3001", 
-		"https://hotel-portal.local",
+	corsPolicy := corsPolicyFor(cfg)
+	corsMiddleware, err := middleware.CORS(corsPolicy)
+	if err != nil {
+		log.Fatalf("Invalid CORS configuration: %v", err)
 	}
-	corsConfig.AllowCredentials = true
-	corsConfig.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Internal-API-Key", "X-Request-ID"}
-	router.Use(cors.New(corsConfig))
+	router.Use(corsMiddleware)
 
 	log.WithFields(logrus.Fields{
-		"valid_origins": corsConfig.AllowOrigins,
+		"valid_origins": corsPolicy.Origins,
+		"environment":   cfg.Environment,
 	}).Info("Configured CORS origins for User Portal access")
 
 	// Setup routes with handlers
 	routes.Setup(router, cfg)
 
+	// Rate limits, circuit breakers, CORS and the log level follow changes
+	// to CONFIG_FILE, on SIGHUP or when asked, without a restart
+	reload.Init(cfg, cfg.ConfigFile)
+	registerReloaders()
+	reload.Watch(cfg.ConfigWatchInterval)
+	if secrets != nil && cfg.SecretsRefreshInterval > 0 {
+		reload.WatchSecrets(secrets, cfg.SecretsRefreshInterval)
+	}
+	if remoteConfig != nil && cfg.ConfigSyncInterval > 0 {
+		reload.WatchRemote(remoteConfig, cfg.ConfigSyncPrecedence, cfg.ConfigSyncInterval)
+	}
+	log.WithFields(logrus.Fields{
+		"config_file": cfg.ConfigFile,
+		"interval":    cfg.ConfigWatchInterval.String(),
+	}).Info("Configuration reload enabled")
+
 	// Create HTTP server with timeouts
 	address := cfg.Host + ":" + cfg.Port
 	srv := &http.Server{
@@ -102,17 +769,23 @@ func main() {
 		ReadTimeout:  cfg.ReadTimeout,
 		WriteTimeout: cfg.WriteTimeout,
 		IdleTimeout:  cfg.IdleTimeout,
+		TLSConfig:    serverTLS.Config,
 	}
+	scheme := serverTLS.Scheme()
 
+	build := buildinfo.Get()
 	log.WithFields(logrus.Fields{
 		"address":              address,
+		"version":              build.Version,
+		"commit":               build.Commit,
 		"api_beheerder_url":    cfg.APIBeheerderURL,
 		"central_mgmt_url":     cfg.CentralMgmtURL,
 		"cors_origins":         cfg.AllowedOrigins,
 		"user_portal_url":      cfg.UserPortalURL,
-		"api_endpoint":         "http://" + address + "/api/v1",
-		"health_endpoint":      "http://" + address + "/health",
-		"metrics_endpoint":     "http://" + address + "/metrics",
+		"api_endpoint":         scheme + "://" + address + "/api/v1",
+		"health_endpoint":      scheme + "://" + address + "/health",
+		"metrics_endpoint":     scheme + "://" + address + "/metrics",
+		"tls_mode":             cfg.TLSMode,
 		"read_timeout":         cfg.ReadTimeout,
 		"write_timeout":        cfg.WriteTimeout,
 		"idle_timeout":         cfg.IdleTimeout,
@@ -124,26 +797,57 @@ func main() {
 
 	// Pretty startup messages
 	fmt.Printf("🚀 Internal API starting on %s\n", address)
-	fmt.Printf("   🔗 API Beheerder: %s\n", cfg.APIBeheerderURL)
-	fmt.Printf("   🎛️  Central Management: %s\n", cfg.CentralMgmtURL)
+	fmt.Printf("   🔗 API Beheerder: %s\n", upstreamURL("api-beheerder", cfg.APIBeheerderURL))
+	fmt.Printf("   🎛️  Central Management: %s\n", upstreamURL("central-mgmt", cfg.CentralMgmtURL))
 	fmt.Printf("   👤 User Portal: %s\n", cfg.UserPortalURL)
-	fmt.Printf("   📊 Metrics: http://%s/metrics\n", address)
-	fmt.Printf("   💚 Health: http://%s/health\n", address)
+	fmt.Printf("   📊 Metrics: %s://%s/metrics\n", scheme, address)
+	fmt.Printf("   💚 Health: %s://%s/health\n", scheme, address)
 	fmt.Printf("   🔒 Security: Headers=%v, Audit=%v, RateLimit=%v\n", 
 		cfg.EnableSecurityHeaders, cfg.EnableAuditLogging, cfg.RateLimitEnabled)
 	fmt.Printf("   ⏱️  Timeouts: Read=%v, Write=%v, Idle=%v\n", 
 		cfg.ReadTimeout, cfg.WriteTimeout, cfg.IdleTimeout)
 
 		// Register with broker (non-blocking)
-	broker.RegisterWithBroker(cfg.Host, cfg.Port)
+	broker.RegisterWithBroker(cfg.Host, cfg.Port, broker.HeartbeatSettings{
+		Interval:   cfg.BrokerHeartbeatInterval,
+		MaxBackoff: cfg.BrokerRetryMax,
+		Deregister: cfg.BrokerDeregisterOnShutdown,
+	})
 
 // Start server in a goroutine
+	serverTLS.ServeACMEChallenges()
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if serverTLS.Enabled() {
+			// Certificates come from TLSConfig, so no files are passed here
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}()
 
+	// gRPC callers are transcoded onto the same routes and middleware
+	var grpcSrv *grpcserver.Server
+	if cfg.GRPCEnabled {
+		grpcAddress := cfg.Host + ":" + cfg.GRPCPort
+		grpcSrv, err = grpcserver.New(grpcAddress, router, cfg.MaxRequestBodySize, serverTLS.Config)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to load gRPC contract")
+		}
+		go func() {
+			if err := grpcSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+		log.WithFields(logrus.Fields{
+			"address": grpcAddress,
+			"methods": len(grpcSrv.Methods()),
+		}).Info("gRPC server started")
+	}
+
 	// Wait for interrupt signal for graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -155,21 +859,209 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Stop the broker routing new traffic here before draining connections
+	if err := broker.Stop(ctx); err != nil {
+		log.WithError(err).Warn("Failed to deregister from broker")
+	}
+
 	// Attempt graceful shutdown
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Errorf("Server forced to shutdown: %v", err)
 	}
+	if grpcSrv != nil {
+		if err := grpcSrv.Shutdown(ctx); err != nil {
+			log.Errorf("gRPC server forced to shutdown: %v", err)
+		}
+	}
+	reload.Stop()
+	services.StopDiscovery()
+	health.Stop()
+	slo.Stop()
+	jobs.Stop(ctx)
+	events.StopPublisher(ctx)
+	audit.StopShipper(ctx)
+	audit.StopAnchoring()
+	tracing.Stop(ctx)
 
 	log.Info("Server exited")
 }
 
-// setupLogging configures structured logging
+// setupLogging configures structured logging. The logger is shared with
+// every package, see logging.Logger.
 func setupLogging() {
-	log = logrus.New()
-	log.SetFormatter(&logrus.JSONFormatter{})
-	log.SetLevel(logrus.InfoLevel)
+	log = logging.Logger()
 	
 	log.WithFields(logrus.Fields{
 		"service": "internal-api",
 	}).Info("Logging initialized")
-}
\ No newline at end of file
+}
+// upstreamURL returns the address an upstream is called at: the one found
+// with the broker, or else its configured URL
+func upstreamURL(name, configured string) string {
+	if address, ok := services.DiscoveredURL(name); ok {
+		return address + " (discovered)"
+	}
+	return configured
+}
+
+// splitSetting splits a comma-separated setting, dropping blank entries
+func splitSetting(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// newBreakerSettings returns the circuit breaker settings of each service
+// under a configuration
+func newBreakerSettings(cfg *config.Config) (func(name string) resilience.Settings, error) {
+	policies, err := resilience.ParseTripPolicies(cfg.CircuitBreakerServicePolicies)
+	if err != nil {
+		return nil, fmt.Errorf("CB_SERVICE_POLICIES: %v", err)
+	}
+	defaultPolicy, err := resilience.ParseTripPolicy(cfg.CircuitBreakerPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("CB_POLICY: %v", err)
+	}
+	return func(name string) resilience.Settings {
+		policy, ok := policies[name]
+		if !ok {
+			policy = defaultPolicy
+		}
+		return resilience.Settings{
+			FailureThreshold:   cfg.CircuitBreakerFailureThreshold,
+			Timeout:            cfg.CircuitBreakerTimeout,
+			MaxRetries:         cfg.CircuitBreakerMaxRetries,
+			RetryDelay:         cfg.CircuitBreakerRetryDelay,
+			HalfOpenProbes:     cfg.CircuitBreakerHalfOpenProbes,
+			SuccessThreshold:   cfg.CircuitBreakerSuccessThreshold,
+			Trip:               policy,
+			ErrorRateThreshold: cfg.CircuitBreakerErrorRate,
+			Window:             cfg.CircuitBreakerWindow,
+			MinCalls:           cfg.CircuitBreakerMinCalls,
+		}
+	}, nil
+}
+
+// corsPolicyFor returns the CORS policy of a configuration
+func corsPolicyFor(cfg *config.Config) middleware.CORSPolicy {
+	policy := middleware.CORSPolicy{
+		Origins:          cfg.CORSOrigins(),
+		Methods:          splitSetting(cfg.CORSAllowedMethods),
+		Headers:          splitSetting(cfg.CORSAllowedHeaders),
+		ExposeHeaders:    splitSetting(cfg.CORSExposedHeaders),
+		MaxAge:           cfg.CORSMaxAge,
+		AllowCredentials: cfg.CORSAllowCredentials,
+	}
+	if cfg.PublicAvailabilityEnabled {
+		// The website hosting the availability widget calls /public/v1 directly
+		policy.Origins = append(policy.Origins, splitSetting(cfg.PublicWidgetOrigins)...)
+	}
+	return policy
+}
+
+// registerReloaders registers the settings that take effect on a
+// configuration reload. Everything else needs a restart.
+func registerReloaders() {
+	reload.Register("rate limits", []string{
+		"RateLimitRequests", "RateLimitInterval",
+		"LoginRateLimitRequests", "LoginRateLimitInterval",
+		"AdminRateLimitRequests", "AdminRateLimitInterval",
+		"PublicRateLimitRequests", "PublicRateLimitInterval",
+	}, func(previous, next *config.Config) error {
+		limits := []struct {
+			name     string
+			rate     int
+			interval time.Duration
+		}{
+			{"api", next.RateLimitRequests, next.RateLimitInterval},
+			{"login", next.LoginRateLimitRequests, next.LoginRateLimitInterval},
+			{"admin", next.AdminRateLimitRequests, next.AdminRateLimitInterval},
+			{"public", next.PublicRateLimitRequests, next.PublicRateLimitInterval},
+		}
+		for _, limit := range limits {
+			if limit.rate <= 0 || limit.interval <= 0 {
+				return fmt.Errorf("%s rate limit must allow at least one request per interval", limit.name)
+			}
+		}
+		for _, limit := range limits {
+			middleware.SetRateLimit(limit.name, limit.rate, limit.interval)
+		}
+		return nil
+	})
+
+	reload.Register("circuit breakers", []string{
+		"CircuitBreakerFailureThreshold", "CircuitBreakerTimeout",
+		"CircuitBreakerMaxRetries", "CircuitBreakerRetryDelay",
+		"CircuitBreakerHalfOpenProbes", "CircuitBreakerSuccessThreshold",
+		"CircuitBreakerPolicy", "CircuitBreakerServicePolicies",
+		"CircuitBreakerErrorRate", "CircuitBreakerWindow", "CircuitBreakerMinCalls",
+	}, func(previous, next *config.Config) error {
+		before, err := newBreakerSettings(previous)
+		if err != nil {
+			return err
+		}
+		after, err := newBreakerSettings(next)
+		if err != nil {
+			return err
+		}
+		// Only breakers whose settings changed are touched, so changes made
+		// through /admin/circuit-breakers survive other reloads
+		for _, name := range resilience.BreakerNames() {
+			if settings := after(name); settings != before(name) {
+				resilience.Breaker(name).Configure(settings)
+			}
+		}
+		return nil
+	})
+
+	reload.Register("CORS", []string{
+		"AllowedOrigins", "CORSAllowedMethods", "CORSAllowedHeaders",
+		"CORSExposedHeaders", "CORSMaxAge", "CORSAllowCredentials", "PublicWidgetOrigins",
+	}, func(previous, next *config.Config) error {
+		return middleware.SetCORSPolicy(corsPolicyFor(next))
+	})
+
+	reload.Register("log level", []string{"LogLevel"}, func(previous, next *config.Config) error {
+		return logging.SetLevel(next.LogLevel)
+	})
+
+	// Secrets rotated in the secrets backend
+	reload.Register("JWT secret", []string{"JWTSecret", "JWTPreviousSecrets", "CursorSecret"}, func(previous, next *config.Config) error {
+		if next.JWTSecret != previous.JWTSecret || next.JWTPreviousSecrets != previous.JWTPreviousSecrets {
+			// Access tokens signed with the old secret stay valid until they expire
+			middleware.SetJWTKeys(next.JWTSecret, splitSetting(next.JWTPreviousSecrets), next.AccessTokenTTL)
+		}
+		cursorSecret := next.CursorSecret
+		if cursorSecret == "" {
+			cursorSecret = next.JWTSecret
+		}
+		models.InitCursors(cursorSecret)
+		return nil
+	})
+
+	reload.Register("OIDC state secret", []string{"OIDCStateSecret"}, func(previous, next *config.Config) error {
+		auth.SetOIDCStateSecret(next.OIDCStateSecret)
+		return nil
+	})
+
+	reload.Register("internal API keys", []string{"InternalAPIKeys"}, func(previous, next *config.Config) error {
+		return middleware.InitAPIKeys(next.InternalAPIKeys)
+	})
+
+	reload.Register("transform rules", []string{"TransformRulesFile"}, func(previous, next *config.Config) error {
+		return services.New(next).InitTransforms(next.TransformRulesFile)
+	})
+
+	reload.Register("service keys", []string{"APIBeheerderKey", "CentralMgmtKey", "UpstreamServiceKeys"}, func(previous, next *config.Config) error {
+		if err := services.SetUpstreamKeys(next.UpstreamServiceKeys); err != nil {
+			return err
+		}
+		services.SetUpstreamKey("api-beheerder", next.APIBeheerderKey)
+		services.SetUpstreamKey("central-mgmt", next.CentralMgmtKey)
+		return nil
+	})
+}
@@ -31,11 +31,12 @@ func main() {
 
 	// Business rules endpoints
 	router.GET("/business-rules/albums", getAlbumBusinessRules)
-	router.GET("/user-filters/albums", getUserFilters)
+	router.GET("/user-filters/:resource", getUserFilters)
 
 	// Audit and logging endpoints
 	router.POST("/audit-log", logAuditEvent)
 	router.POST("/access-log", logAccessEvent)
+	router.POST("/audit-anchors", logAuditAnchor)
 
 	// Configuration endpoints
 	router.GET("/config/:service", getServiceConfig)
@@ -170,6 +171,21 @@ func logAuditEvent(c *gin.Context) {
 	})
 }
 
+// Store an anchor of the gateway's audit hash chain
+func logAuditAnchor(c *gin.Context) {
+	var anchor map[string]interface{}
+
+	if err := c.ShouldBindJSON(&anchor); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	// In real system, this would be kept in write-once storage
+	fmt.Printf("⚓ AUDIT ANCHOR: sequence %v hash %v\n", anchor["sequence"], anchor["hash"])
+
+	c.JSON(http.StatusOK, gin.H{"status": "anchored"})
+}
+
 // Log access events
 func logAccessEvent(c *gin.Context) {
 	var accessLog map[string]interface{}